@@ -0,0 +1,77 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// artisanAboutCheck runs 'php artisan about', which boots the application,
+// and reports the versions and environment it prints.
+type artisanAboutCheck struct{}
+
+func newArtisanAboutCheck(config.CheckConfig) (Check, error) {
+	return &artisanAboutCheck{}, nil
+}
+
+func (c *artisanAboutCheck) Name() string { return "artisan.about" }
+
+// about is the part of 'php artisan about --json' the check reads.
+type about struct {
+	Environment struct {
+		LaravelVersion  string `json:"laravel_version"`
+		PHPVersion      string `json:"php_version"`
+		Environment     string `json:"environment"`
+		DebugMode       bool   `json:"debug_mode"`
+		MaintenanceMode bool   `json:"maintenance_mode"`
+	} `json:"environment"`
+}
+
+func (c *artisanAboutCheck) Run(ctx *Context) (string, string) {
+	if _, err := os.Stat(filepath.Join(ctx.WorktreePath, "artisan")); err != nil {
+		return StatusSkip, "no artisan file"
+	}
+
+	output, err := ctx.Executor.RunBinary(ctx.Ctx, ctx.WorktreePath, "php", []string{"artisan", "about", "--json"})
+	if err != nil {
+		if line := firstLine(string(output)); line != "" {
+			return StatusFail, fmt.Sprintf("php artisan about: %v: %s", err, line)
+		}
+		return StatusFail, fmt.Sprintf("php artisan about: %v", err)
+	}
+
+	// Deprecation notices and the like can precede the JSON.
+	text := string(output)
+	if i := strings.Index(text, "{"); i > 0 {
+		text = text[i:]
+	}
+	var info about
+	if err := json.Unmarshal([]byte(text), &info); err != nil {
+		return StatusFail, fmt.Sprintf("parsing php artisan about: %v", err)
+	}
+
+	env := info.Environment
+	debug := "off"
+	if env.DebugMode {
+		debug = "on"
+	}
+	message := fmt.Sprintf("Laravel %s, PHP %s, %s environment, debug %s", env.LaravelVersion, env.PHPVersion, env.Environment, debug)
+	if env.MaintenanceMode {
+		return StatusWarn, message + ", in maintenance mode"
+	}
+	return StatusPass, message
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
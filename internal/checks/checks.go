@@ -0,0 +1,149 @@
+// Package checks verifies that a scaffolded worktree actually works: that
+// its site answers, its database accepts connections and its queue has a
+// worker. Checks are configured under checks: in arbor.yaml and run by
+// 'arbor check', or after each scaffold with scaffold.run_checks.
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+)
+
+// Check statuses.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// DefaultTimeout bounds each check.
+const DefaultTimeout = 10 * time.Second
+
+// Context is what a check needs to know about the worktree it checks.
+type Context struct {
+	Ctx          context.Context
+	WorktreePath string
+	// Env is the worktree's primary env file.
+	Env      map[string]string
+	Executor *arbor_exec.CommandExecutor
+	// Policy is the project's policy; the command check refuses commands
+	// matching policy.deny_commands.
+	Policy config.PolicyConfig
+}
+
+// Check is one health check.
+type Check interface {
+	Name() string
+	// Run checks the worktree, returning a status and a one-line message.
+	Run(ctx *Context) (status, message string)
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Factory creates a check from its configuration.
+type Factory func(cfg config.CheckConfig) (Check, error)
+
+// Registry maps check names to their factories.
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// NewDefaultRegistry returns a registry with the built-in checks.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("http", newHTTPCheck)
+	r.Register("artisan.about", newArtisanAboutCheck)
+	r.Register("db", newDBCheck)
+	r.Register("queue", newQueueCheck)
+	r.Register("laravel.permissions", newPermissionsCheck)
+	r.Register("mail", newMailCheck)
+	r.Register("command", newCommandCheck)
+	r.Register("files", newFilesCheck)
+	return r
+}
+
+// Register adds a check, replacing any with the same name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// Create returns the check cfg configures.
+func (r *Registry) Create(cfg config.CheckConfig) (Check, error) {
+	factory, ok := r.factories[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown check: %s", cfg.Name)
+	}
+	return factory(cfg)
+}
+
+// ListRegistered returns the registered check names, sorted.
+func (r *Registry) ListRegistered() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunAll runs the configured checks in order, each bounded by timeout.
+// A check that cannot be created fails; a failed optional check warns.
+func (r *Registry) RunAll(ctx *Context, cfgs []config.CheckConfig, timeout time.Duration) []Result {
+	parent := ctx.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	results := make([]Result, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		result := Result{Name: cfg.Name}
+		check, err := r.Create(cfg)
+		if err != nil {
+			result.Status, result.Message = StatusFail, err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(parent, timeout)
+		checkCtx := *ctx
+		checkCtx.Ctx = runCtx
+		start := time.Now()
+		result.Status, result.Message = check.Run(&checkCtx)
+		result.DurationMs = time.Since(start).Milliseconds()
+		cancel()
+
+		if result.Status == StatusFail && cfg.Optional {
+			result.Status = StatusWarn
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Failed returns the number of failed results.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
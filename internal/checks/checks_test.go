@@ -0,0 +1,296 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+)
+
+func newTestContext(t *testing.T, env map[string]string) (*Context, *arbor_exec.MockCommander) {
+	commander := arbor_exec.NewMockCommander()
+	return &Context{
+		Ctx:          context.Background(),
+		WorktreePath: t.TempDir(),
+		Env:          env,
+		Executor:     arbor_exec.NewCommandExecutor(commander),
+	}, commander
+}
+
+func TestRegistry_RunAll(t *testing.T) {
+	r := NewRegistry()
+	r.Register("ok", func(config.CheckConfig) (Check, error) { return stubCheck{StatusPass, "fine"}, nil })
+	r.Register("bad", func(config.CheckConfig) (Check, error) { return stubCheck{StatusFail, "broken"}, nil })
+	ctx, _ := newTestContext(t, nil)
+
+	results := r.RunAll(ctx, []config.CheckConfig{
+		{Name: "ok"},
+		{Name: "bad", Optional: true},
+		{Name: "bad"},
+		{Name: "missing"},
+	}, DefaultTimeout)
+
+	require.Len(t, results, 4)
+	assert.Equal(t, StatusPass, results[0].Status)
+	assert.Equal(t, StatusWarn, results[1].Status, "optional failures warn")
+	assert.Equal(t, StatusFail, results[2].Status)
+	assert.Equal(t, Result{Name: "missing", Status: StatusFail, Message: "unknown check: missing"}, results[3])
+	assert.Equal(t, 2, Failed(results))
+}
+
+type stubCheck struct{ status, message string }
+
+func (s stubCheck) Name() string                  { return "stub" }
+func (s stubCheck) Run(*Context) (string, string) { return s.status, s.message }
+
+func TestDefaultRegistry(t *testing.T) {
+	assert.Equal(t, []string{"artisan.about", "command", "db", "files", "http", "laravel.permissions", "mail", "queue"}, NewDefaultRegistry().ListRegistered())
+}
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx, _ := newTestContext(t, map[string]string{"APP_URL": server.URL})
+
+	status, message := (&httpCheck{}).Run(ctx)
+	assert.Equal(t, StatusPass, status)
+	assert.Equal(t, "GET "+server.URL+": 200 OK", message)
+
+	status, _ = (&httpCheck{url: server.URL + "/missing"}).Run(ctx)
+	assert.Equal(t, StatusFail, status)
+
+	status, message = (&httpCheck{url: server.URL + "/missing", status: 404}).Run(ctx)
+	assert.Equal(t, StatusPass, status, message)
+
+	status, message = (&httpCheck{status: 204}).Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, message, "expected 204")
+
+	ctx.Env = nil
+	status, _ = (&httpCheck{}).Run(ctx)
+	assert.Equal(t, StatusSkip, status)
+}
+
+func TestArtisanAboutCheck(t *testing.T) {
+	ctx, commander := newTestContext(t, nil)
+
+	status, _ := (&artisanAboutCheck{}).Run(ctx)
+	assert.Equal(t, StatusSkip, status, "not a Laravel app")
+
+	require.NoError(t, os.WriteFile(filepath.Join(ctx.WorktreePath, "artisan"), nil, 0755))
+	commander.SetResponse("php", []string{"artisan", "about", "--json"}, []byte(`Deprecated: something
+{"environment":{"application_name":"Laravel","laravel_version":"11.9.2","php_version":"8.3.7","environment":"local","debug_mode":true,"maintenance_mode":false},"drivers":{"queue":"database"}}`), nil)
+
+	status, message := (&artisanAboutCheck{}).Run(ctx)
+	assert.Equal(t, StatusPass, status)
+	assert.Equal(t, "Laravel 11.9.2, PHP 8.3.7, local environment, debug on", message)
+
+	commander.SetResponse("php", []string{"artisan", "about", "--json"}, []byte("\nPHP Fatal error: Class not found\n"), errors.New("exit status 255"))
+	status, message = (&artisanAboutCheck{}).Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Equal(t, "php artisan about: exit status 255: PHP Fatal error: Class not found", message)
+}
+
+func TestPermissionsCheck(t *testing.T) {
+	ctx, _ := newTestContext(t, nil)
+
+	status, _ := (&permissionsCheck{}).Run(ctx)
+	assert.Equal(t, StatusSkip, status, "not a Laravel app")
+
+	require.NoError(t, os.WriteFile(filepath.Join(ctx.WorktreePath, "artisan"), nil, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(ctx.WorktreePath, "storage", "logs"), 0755))
+
+	status, message := (&permissionsCheck{}).Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, message, "bootstrap/cache is missing")
+	assert.Contains(t, message, "storage/framework/views is missing")
+	assert.NotContains(t, message, "storage/logs")
+
+	for _, dir := range []string{"storage/app/public", "storage/framework/cache/data", "storage/framework/sessions", "storage/framework/views", "bootstrap/cache"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(ctx.WorktreePath, dir), 0755))
+	}
+	status, message = (&permissionsCheck{}).Run(ctx)
+	assert.Equal(t, StatusPass, status)
+	assert.Equal(t, "storage and bootstrap/cache are writable", message)
+}
+
+func TestMailCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("220 mailpit ESMTP Service ready\r\n"))
+			_ = conn.Close()
+		}
+	}()
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	ctx, _ := newTestContext(t, map[string]string{"MAIL_MAILER": "smtp", "MAIL_HOST": host, "MAIL_PORT": port})
+	status, message := (&mailCheck{}).Run(ctx)
+	assert.Equal(t, StatusPass, status)
+	assert.Equal(t, "SMTP server at "+listener.Addr().String()+": 220 mailpit ESMTP Service ready", message)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, closedPort, _ := net.SplitHostPort(closed.Addr().String())
+	require.NoError(t, closed.Close())
+	ctx.Env["MAIL_PORT"] = closedPort
+	status, message = (&mailCheck{}).Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, message, "no SMTP server at 127.0.0.1:"+closedPort)
+
+	ctx.Env["MAIL_MAILER"] = "log"
+	status, message = (&mailCheck{}).Run(ctx)
+	assert.Equal(t, StatusSkip, status)
+	assert.Equal(t, "log mailer, no SMTP server needed", message)
+}
+
+func TestDBCheck(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		ctx, _ := newTestContext(t, map[string]string{"DB_CONNECTION": "sqlite"})
+
+		status, message := (&dbCheck{}).Run(ctx)
+		assert.Equal(t, StatusFail, status)
+		assert.Equal(t, "sqlite database database/database.sqlite does not exist", message)
+
+		require.NoError(t, os.MkdirAll(filepath.Join(ctx.WorktreePath, "database"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(ctx.WorktreePath, "database", "database.sqlite"), nil, 0644))
+		status, _ = (&dbCheck{}).Run(ctx)
+		assert.Equal(t, StatusPass, status)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		client := steps.NewMockDatabaseClient()
+		check := &dbCheck{clientFactory: steps.MockClientFactory(client)}
+		ctx, _ := newTestContext(t, map[string]string{"DB_CONNECTION": "mysql", "DB_PORT": "3306", "DB_DATABASE": "app_swift_runner"})
+
+		status, message := check.Run(ctx)
+		assert.Equal(t, StatusFail, status)
+		assert.Equal(t, "database app_swift_runner does not exist on 127.0.0.1:3306", message)
+
+		client.AddDatabase("app_swift_runner")
+		status, message = check.Run(ctx)
+		assert.Equal(t, StatusPass, status)
+		assert.Equal(t, "connected to mysql database app_swift_runner at 127.0.0.1:3306", message)
+
+		client.SetPingError(errors.New("connection refused"))
+		status, message = check.Run(ctx)
+		assert.Equal(t, StatusFail, status)
+		assert.Equal(t, "connecting to mysql at 127.0.0.1:3306: connection refused", message)
+	})
+
+	t.Run("no connection", func(t *testing.T) {
+		ctx, _ := newTestContext(t, nil)
+		status, _ := (&dbCheck{}).Run(ctx)
+		assert.Equal(t, StatusSkip, status)
+	})
+}
+
+func TestQueueCheck(t *testing.T) {
+	ctx, _ := newTestContext(t, map[string]string{"QUEUE_CONNECTION": "redis"})
+	dir, err := filepath.EvalSymlinks(ctx.WorktreePath)
+	require.NoError(t, err)
+
+	var processes []process
+	check := &queueCheck{listProcesses: func(*Context) ([]process, error) { return processes, nil }}
+
+	processes = []process{
+		{args: []string{"php", "artisan", "queue:work"}, dir: "/elsewhere"},
+		{args: []string{"php", "artisan", "serve"}, dir: dir},
+	}
+	status, message := check.Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Equal(t, "no queue worker running for the redis queue", message)
+
+	processes = append(processes, process{args: []string{"/usr/bin/php", dir + "/artisan", "horizon"}, dir: dir})
+	status, message = check.Run(ctx)
+	assert.Equal(t, StatusPass, status)
+	assert.Equal(t, "1 worker(s) running for the redis queue", message)
+
+	ctx.Env["QUEUE_CONNECTION"] = "sync"
+	status, _ = check.Run(ctx)
+	assert.Equal(t, StatusPass, status)
+
+	check.listProcesses = func(*Context) ([]process, error) { return nil, errProcessesUnsupported }
+	ctx.Env["QUEUE_CONNECTION"] = "database"
+	status, _ = check.Run(ctx)
+	assert.Equal(t, StatusSkip, status)
+}
+
+func TestListProcProcesses(t *testing.T) {
+	root := t.TempDir()
+	pidDir := filepath.Join(root, "42")
+	require.NoError(t, os.MkdirAll(pidDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "cmdline"), []byte("php\x00artisan\x00queue:work\x00"), 0644))
+	require.NoError(t, os.Symlink("/code/app/feature-x", filepath.Join(pidDir, "cwd")))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "self"), 0755))
+
+	processes, err := listProcProcesses(root)
+	require.NoError(t, err)
+	assert.Equal(t, []process{{args: []string{"php", "artisan", "queue:work"}, dir: "/code/app/feature-x"}}, processes)
+}
+
+func TestCommandCheck(t *testing.T) {
+	_, err := newCommandCheck(config.CheckConfig{Name: "command"})
+	assert.Error(t, err)
+
+	ctx, commander := newTestContext(t, nil)
+	check, err := newCommandCheck(config.CheckConfig{Name: "command", Command: "php artisan migrate:status"})
+	require.NoError(t, err)
+
+	status, _ := check.Run(ctx)
+	assert.Equal(t, StatusPass, status)
+
+	commander.SetResponse("bash", []string{"-c", "php artisan migrate:status"}, []byte("Migration table not found.\n"), errors.New("exit status 1"))
+	status, message := check.Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Equal(t, "php artisan migrate:status: exit status 1: Migration table not found.", message)
+
+	ctx.Policy = config.PolicyConfig{DenyCommands: []string{"php artisan"}}
+	status, message = check.Run(ctx)
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, message, "policy.deny_commands")
+}
+
+func TestFilesCheck(t *testing.T) {
+	dir := t.TempDir()
+	ctx := &Context{WorktreePath: dir}
+	check, err := newFilesCheck(config.CheckConfig{Name: "files"})
+	require.NoError(t, err)
+
+	status, _ := check.Run(ctx)
+	assert.Equal(t, StatusSkip, status)
+
+	envPath := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("A=2\n"), 0644))
+	require.NoError(t, config.RecordFileChanges(dir, []config.FileChange{{Path: ".env", Existed: true, Content: []byte("A=1\n")}}))
+	status, _ = check.Run(ctx)
+	assert.Equal(t, StatusPass, status)
+
+	require.NoError(t, os.WriteFile(envPath, []byte("A=3\n"), 0644))
+	status, message := check.Run(ctx)
+	assert.Equal(t, StatusWarn, status)
+	assert.Contains(t, message, "edited since the scaffold: .env")
+}
@@ -0,0 +1,36 @@
+package checks
+
+import (
+	"fmt"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// commandCheck runs a shell command in the worktree and passes when it
+// exits 0.
+type commandCheck struct {
+	command string
+}
+
+func newCommandCheck(cfg config.CheckConfig) (Check, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("command check requires a command")
+	}
+	return &commandCheck{command: cfg.Command}, nil
+}
+
+func (c *commandCheck) Name() string { return "command" }
+
+func (c *commandCheck) Run(ctx *Context) (string, string) {
+	if pattern, denied := ctx.Policy.DeniedCommand(c.command); denied {
+		return StatusFail, fmt.Sprintf("%s: matches policy.deny_commands pattern %q", c.command, pattern)
+	}
+	output, err := ctx.Executor.RunBash(ctx.Ctx, ctx.WorktreePath, c.command)
+	if err != nil {
+		if line := firstLine(string(output)); line != "" {
+			return StatusFail, fmt.Sprintf("%s: %v: %s", c.command, err, line)
+		}
+		return StatusFail, fmt.Sprintf("%s: %v", c.command, err)
+	}
+	return StatusPass, c.command
+}
@@ -0,0 +1,102 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+)
+
+// dbCheck connects to the database with the credentials in the worktree's
+// env file and checks that DB_DATABASE exists.
+type dbCheck struct {
+	clientFactory steps.DatabaseClientFactory
+}
+
+func newDBCheck(config.CheckConfig) (Check, error) {
+	return &dbCheck{clientFactory: steps.DefaultDatabaseClientFactory}, nil
+}
+
+func (c *dbCheck) Name() string { return "db" }
+
+func (c *dbCheck) Run(ctx *Context) (string, string) {
+	connection := ctx.Env["DB_CONNECTION"]
+	database := ctx.Env["DB_DATABASE"]
+
+	var engine string
+	switch connection {
+	case "":
+		return StatusSkip, "DB_CONNECTION is not set"
+	case "sqlite":
+		return checkSqlite(ctx.WorktreePath, database)
+	case "mysql", "mariadb":
+		engine = "mysql"
+	case "pgsql":
+		engine = "pgsql"
+	default:
+		return StatusSkip, fmt.Sprintf("unsupported connection %s", connection)
+	}
+
+	opts := steps.DatabaseOptions{
+		Host:     ctx.Env["DB_HOST"],
+		Port:     ctx.Env["DB_PORT"],
+		Username: ctx.Env["DB_USERNAME"],
+		Password: ctx.Env["DB_PASSWORD"],
+		Socket:   ctx.Env["DB_SOCKET"],
+		SSLMode:  ctx.Env["DB_SSLMODE"],
+	}
+	if opts.Host == "" {
+		opts.Host = "127.0.0.1"
+	}
+	client, err := c.clientFactory(engine, opts)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("connecting to %s: %v", connection, err)
+	}
+	defer client.Close()
+
+	// Pings take no context, so an unreachable server is bounded here.
+	pinged := make(chan error, 1)
+	go func() { pinged <- client.Ping() }()
+	select {
+	case err = <-pinged:
+	case <-ctx.Ctx.Done():
+		err = ctx.Ctx.Err()
+	}
+	if err != nil {
+		return StatusFail, fmt.Sprintf("connecting to %s at %s: %v", connection, opts.Address(), err)
+	}
+
+	if database == "" {
+		return StatusPass, fmt.Sprintf("connected to %s at %s", connection, opts.Address())
+	}
+	databases, err := client.ListDatabases(database)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("listing databases: %v", err)
+	}
+	if !slices.Contains(databases, database) {
+		return StatusFail, fmt.Sprintf("database %s does not exist on %s", database, opts.Address())
+	}
+	return StatusPass, fmt.Sprintf("connected to %s database %s at %s", connection, database, opts.Address())
+}
+
+// checkSqlite checks that the SQLite database file exists. Laravel's
+// default is database/database.sqlite.
+func checkSqlite(worktreePath, database string) (string, string) {
+	switch database {
+	case ":memory:":
+		return StatusPass, "in-memory sqlite database"
+	case "":
+		database = filepath.Join("database", "database.sqlite")
+	}
+	path := database
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktreePath, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return StatusFail, fmt.Sprintf("sqlite database %s does not exist", database)
+	}
+	return StatusPass, fmt.Sprintf("sqlite database %s", database)
+}
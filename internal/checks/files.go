@@ -0,0 +1,39 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// filesCheck warns about files scaffolds created or changed that were
+// edited since, which the next scaffold may overwrite and
+// 'arbor scaffold --revert-files' would discard.
+type filesCheck struct{}
+
+func newFilesCheck(config.CheckConfig) (Check, error) {
+	return &filesCheck{}, nil
+}
+
+func (c *filesCheck) Name() string { return "files" }
+
+func (c *filesCheck) Run(ctx *Context) (string, string) {
+	files, err := config.JournaledFiles(ctx.WorktreePath)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if len(files) == 0 {
+		return StatusSkip, "no files changed by a scaffold"
+	}
+	var edited []string
+	for _, file := range files {
+		if file.Edited {
+			edited = append(edited, file.Path)
+		}
+	}
+	if len(edited) > 0 {
+		return StatusWarn, fmt.Sprintf("edited since the scaffold: %s ('arbor scaffold --revert-files' restores the originals)", strings.Join(edited, ", "))
+	}
+	return StatusPass, fmt.Sprintf("%d file(s) as the scaffold left them", len(files))
+}
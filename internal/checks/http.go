@@ -0,0 +1,55 @@
+package checks
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// httpCheck requests the site and checks its response status.
+type httpCheck struct {
+	url      string
+	status   int
+	insecure bool
+}
+
+func newHTTPCheck(cfg config.CheckConfig) (Check, error) {
+	return &httpCheck{url: cfg.URL, status: cfg.Status, insecure: cfg.Insecure}, nil
+}
+
+func (c *httpCheck) Name() string { return "http" }
+
+func (c *httpCheck) Run(ctx *Context) (string, string) {
+	url := c.url
+	if url == "" {
+		url = ctx.Env["APP_URL"]
+	}
+	if url == "" {
+		return StatusSkip, "no url configured and APP_URL is not set"
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("invalid url %s: %v", url, err)
+	}
+	client := &http.Client{}
+	if c.insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("GET %s: %v", url, err)
+	}
+	resp.Body.Close()
+
+	message := fmt.Sprintf("GET %s: %s", url, resp.Status)
+	switch {
+	case c.status != 0 && resp.StatusCode != c.status:
+		return StatusFail, fmt.Sprintf("%s, expected %d", message, c.status)
+	case c.status == 0 && resp.StatusCode >= 400:
+		return StatusFail, message
+	}
+	return StatusPass, message
+}
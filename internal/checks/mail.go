@@ -0,0 +1,63 @@
+package checks
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+)
+
+// mailCheck connects to the SMTP server in the worktree's env file, such as
+// the mail catcher mail.catcher points it at, and checks that it greets.
+type mailCheck struct{}
+
+func newMailCheck(config.CheckConfig) (Check, error) {
+	return &mailCheck{}, nil
+}
+
+func (c *mailCheck) Name() string { return "mail" }
+
+func (c *mailCheck) Run(ctx *Context) (string, string) {
+	switch mailer := ctx.Env["MAIL_MAILER"]; mailer {
+	case "":
+		return StatusSkip, "MAIL_MAILER is not set"
+	case "smtp":
+	default:
+		return StatusSkip, fmt.Sprintf("%s mailer, no SMTP server needed", mailer)
+	}
+
+	host := ctx.Env["MAIL_HOST"]
+	if host == "" {
+		host = steps.DefaultMailCatcherHost
+	}
+	port := ctx.Env["MAIL_PORT"]
+	if port == "" {
+		port = strconv.Itoa(steps.DefaultMailCatcherPort)
+	}
+	address := net.JoinHostPort(host, port)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx.Ctx, "tcp", address)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("no SMTP server at %s: %v", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	greeting, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return StatusFail, fmt.Sprintf("reading the greeting of %s: %v", address, err)
+	}
+	greeting = strings.TrimSpace(greeting)
+	if !strings.HasPrefix(greeting, "220") {
+		return StatusFail, fmt.Sprintf("%s is not ready: %s", address, greeting)
+	}
+	_, _ = conn.Write([]byte("QUIT\r\n"))
+	return StatusPass, fmt.Sprintf("SMTP server at %s: %s", address, greeting)
+}
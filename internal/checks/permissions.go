@@ -0,0 +1,31 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+)
+
+// permissionsCheck verifies that a Laravel app's storage and
+// bootstrap/cache directories exist and are writable, without which every
+// request fails with a 500.
+type permissionsCheck struct{}
+
+func newPermissionsCheck(config.CheckConfig) (Check, error) {
+	return &permissionsCheck{}, nil
+}
+
+func (c *permissionsCheck) Name() string { return "laravel.permissions" }
+
+func (c *permissionsCheck) Run(ctx *Context) (string, string) {
+	if _, err := os.Stat(filepath.Join(ctx.WorktreePath, "artisan")); err != nil {
+		return StatusSkip, "no artisan file"
+	}
+	if problems := steps.LaravelPermissionProblems(ctx.WorktreePath, steps.LaravelWritablePaths); len(problems) > 0 {
+		return StatusFail, strings.Join(problems, "; ") + " (run the laravel.permissions step)"
+	}
+	return StatusPass, "storage and bootstrap/cache are writable"
+}
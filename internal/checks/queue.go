@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+)
+
+// queueCheck looks for a queue worker running in the worktree when its
+// queue connection needs one.
+type queueCheck struct {
+	listProcesses func(ctx *Context) ([]process, error)
+}
+
+func newQueueCheck(config.CheckConfig) (Check, error) {
+	return &queueCheck{listProcesses: listProcesses}, nil
+}
+
+func (c *queueCheck) Name() string { return "queue" }
+
+// workerCommands are the artisan commands that process a queue.
+var workerCommands = []string{"queue:work", "queue:listen", "horizon", "horizon:work"}
+
+// errProcessesUnsupported reports that processes cannot be listed here.
+var errProcessesUnsupported = errors.New("listing processes is not supported on " + runtime.GOOS)
+
+func (c *queueCheck) Run(ctx *Context) (string, string) {
+	connection := ctx.Env["QUEUE_CONNECTION"]
+	switch connection {
+	case "":
+		return StatusSkip, "QUEUE_CONNECTION is not set"
+	case "sync", "null":
+		return StatusPass, fmt.Sprintf("%s queue, no worker needed", connection)
+	}
+
+	processes, err := c.listProcesses(ctx)
+	if errors.Is(err, errProcessesUnsupported) {
+		return StatusSkip, err.Error()
+	}
+	if err != nil {
+		return StatusFail, fmt.Sprintf("listing processes: %v", err)
+	}
+
+	dir := ctx.WorktreePath
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	workers := 0
+	for _, p := range processes {
+		if p.dir == dir && isQueueWorker(p.args) {
+			workers++
+		}
+	}
+	if workers == 0 {
+		return StatusFail, fmt.Sprintf("no queue worker running for the %s queue", connection)
+	}
+	return StatusPass, fmt.Sprintf("%d worker(s) running for the %s queue", workers, connection)
+}
+
+// isQueueWorker reports whether a command line runs an artisan queue
+// worker.
+func isQueueWorker(args []string) bool {
+	for i, arg := range args {
+		if filepath.Base(arg) == "artisan" && i+1 < len(args) {
+			for _, command := range workerCommands {
+				if args[i+1] == command {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// process is a running process's command line and working directory.
+type process struct {
+	args []string
+	dir  string
+}
+
+// listProcesses lists the current user's processes: from /proc on Linux,
+// and with ps and lsof on macOS and the BSDs.
+func listProcesses(ctx *Context) ([]process, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return nil, errProcessesUnsupported
+	case "linux":
+		return listProcProcesses("/proc")
+	default:
+		return listPsProcesses(ctx.Ctx, ctx.Executor)
+	}
+}
+
+// listProcProcesses reads processes from a procfs mount. Processes whose
+// working directory cannot be read, such as other users', are left out.
+func listProcProcesses(root string) ([]process, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var processes []process
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(root, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		dir, err := os.Readlink(filepath.Join(root, entry.Name(), "cwd"))
+		if err != nil {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		processes = append(processes, process{args: args, dir: dir})
+	}
+	return processes, nil
+}
+
+// listPsProcesses lists processes with ps, then reads the working
+// directory of the queue workers among them with lsof.
+func listPsProcesses(ctx context.Context, executor *arbor_exec.CommandExecutor) ([]process, error) {
+	output, err := executor.RunBinary(ctx, "", "ps", []string{"-axo", "pid=,command="})
+	if err != nil {
+		return nil, fmt.Errorf("running ps: %w", err)
+	}
+
+	var processes []process
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isQueueWorker(fields[1:]) {
+			continue
+		}
+		out, err := executor.RunBinary(ctx, "", "lsof", []string{"-a", "-p", fields[0], "-d", "cwd", "-Fn"})
+		if err != nil {
+			continue
+		}
+		for _, l := range strings.Split(string(out), "\n") {
+			if dir, ok := strings.CutPrefix(l, "n"); ok {
+				processes = append(processes, process{args: fields[1:], dir: dir})
+				break
+			}
+		}
+	}
+	return processes, nil
+}
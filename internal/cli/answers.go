@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// workAnswers are the scaffold prompt answers 'arbor work' replays from
+// --answers and records to --record-answers.
+type workAnswers struct {
+	answers    *prompts.Answers
+	recordPath string
+}
+
+// workAnswersFromFlags loads the --answers file. answers is nil when
+// neither flag is set, so prompts are asked as usual.
+func workAnswersFromFlags(cmd *cobra.Command) (workAnswers, error) {
+	w := workAnswers{recordPath: mustGetString(cmd, "record-answers")}
+	if path := mustGetString(cmd, "answers"); path != "" {
+		answers, err := prompts.LoadAnswers(path)
+		if err != nil {
+			return w, err
+		}
+		w.answers = answers
+	} else if w.recordPath != "" {
+		w.answers = prompts.NewAnswers()
+	}
+	return w, nil
+}
+
+// save writes the replayed and recorded answers to --record-answers.
+func (w workAnswers) save() error {
+	if w.recordPath == "" {
+		return nil
+	}
+	if err := w.answers.Save(w.recordPath); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Recorded %d scaffold answers to %s", w.answers.Len(), w.recordPath))
+	return nil
+}
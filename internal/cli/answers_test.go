@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
+)
+
+func answersTestCmd(answers, recordAnswers string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("answers", answers, "")
+	cmd.Flags().String("record-answers", recordAnswers, "")
+	return cmd
+}
+
+func TestWorkAnswersFromFlags(t *testing.T) {
+	t.Run("without flags prompts are asked as usual", func(t *testing.T) {
+		w, err := workAnswersFromFlags(answersTestCmd("", ""))
+		require.NoError(t, err)
+		assert.Nil(t, w.answers)
+		assert.NoError(t, w.save())
+	})
+
+	t.Run("records answers without a file to replay", func(t *testing.T) {
+		recordPath := filepath.Join(t.TempDir(), "answers.yaml")
+		w, err := workAnswersFromFlags(answersTestCmd("", recordPath))
+		require.NoError(t, err)
+		require.NotNil(t, w.answers)
+
+		w.answers.Set(prompts.AnswerMigrate, "yes")
+		require.NoError(t, w.save())
+
+		saved, err := prompts.LoadAnswers(recordPath)
+		require.NoError(t, err)
+		value, _ := saved.Get(prompts.AnswerMigrate)
+		assert.Equal(t, "yes", value)
+	})
+
+	t.Run("replays a file and records it with the new answers", func(t *testing.T) {
+		dir := t.TempDir()
+		answersPath := filepath.Join(dir, "answers.yaml")
+		recordPath := filepath.Join(dir, "recorded.yaml")
+		require.NoError(t, os.WriteFile(answersPath, []byte("answers:\n  db.migrate: false\n"), 0644))
+
+		w, err := workAnswersFromFlags(answersTestCmd(answersPath, recordPath))
+		require.NoError(t, err)
+		value, ok := w.answers.Get(prompts.AnswerMigrate)
+		assert.True(t, ok)
+		assert.Equal(t, "no", value)
+
+		w.answers.Set(prompts.AnswerDatabase, prompts.AnswerNewDatabase)
+		require.NoError(t, w.save())
+
+		saved, err := prompts.LoadAnswers(recordPath)
+		require.NoError(t, err)
+		assert.Equal(t, 2, saved.Len())
+	})
+
+	t.Run("fails on a missing answers file", func(t *testing.T) {
+		_, err := workAnswersFromFlags(answersTestCmd(filepath.Join(t.TempDir(), "missing.yaml"), ""))
+		assert.ErrorContains(t, err, "reading answers")
+	})
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/checks"
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [WORKTREE]",
+	Short: i18n.T("cmd.check.short"),
+	Long: `Runs health checks against a scaffolded worktree to verify it actually
+works. Checks are configured under checks: in arbor.yaml; without any, the
+preset's defaults are run (http, artisan.about, db and queue for Laravel).
+
+Built-in checks:
+  http           Requests url (default APP_URL). Passes on status, or on any
+                 status below 400 when none is set. insecure: true skips TLS
+                 verification.
+  artisan.about  Runs 'php artisan about' and reports the Laravel and PHP
+                 versions and environment.
+  db             Connects with the DB_* credentials in the env file and
+                 checks DB_DATABASE exists. For sqlite, checks the file.
+  queue          Looks for a queue:work, queue:listen or horizon process in
+                 the worktree, unless QUEUE_CONNECTION is sync.
+  command        Runs command in the worktree; passes when it exits 0.
+  files          Warns about files scaffolds created or changed that were
+                 edited since; 'arbor scaffold --revert-files' restores them.
+
+A check with optional: true reports failures as warnings. The command
+exits non-zero when any other check fails. Set scaffold.run_checks: true to
+run the checks after each scaffold.
+
+Arguments:
+  WORKTREE  Worktree folder name, branch or path relative to the project
+            root, or part of one when it matches a single worktree
+            (defaults to the current worktree, or the default branch)`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := detailedWorktrees(pc, pc.CWD)
+		if err != nil {
+			return err
+		}
+
+		var arg string
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		wt, err := resolveContextWorktree(pc, worktrees, arg)
+		if err != nil {
+			return err
+		}
+
+		// Command checks run commands from arbor.yaml, so a config copied
+		// from the repository must be trusted first.
+		promptMode := types.PromptMode{Interactive: ui.IsInteractive(), CI: os.Getenv("CI") != ""}
+		if err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode); err != nil {
+			return err
+		}
+
+		results := runChecks(pc, pc.Config, wt.Path)
+		if mustGetBool(cmd, "json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			printCheckResults(os.Stdout, results)
+		}
+
+		if failed := checks.Failed(results); failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// worktreeChecks returns the checks cfg configures, or the preset's
+// defaults when it configures none.
+func worktreeChecks(pc *ProjectContext, cfg *config.Config, path string) []config.CheckConfig {
+	if len(cfg.Checks) > 0 {
+		return cfg.Checks
+	}
+	presetName := cfg.Preset
+	if presetName == "" {
+		presetName = pc.PresetManager().Detect(path)
+	}
+	if preset, ok := pc.PresetManager().Get(presetName); ok {
+		return preset.DefaultChecks()
+	}
+	return nil
+}
+
+// runChecks runs the worktree's health checks.
+func runChecks(pc *ProjectContext, cfg *config.Config, path string) []checks.Result {
+	ctx := &checks.Context{
+		WorktreePath: path,
+		Env:          utils.ReadEnvFile(path, cfg.PrimaryEnvFile()),
+		Executor:     arbor_exec.DefaultExecutor,
+		Policy:       cfg.Policy,
+	}
+	return checks.NewDefaultRegistry().RunAll(ctx, worktreeChecks(pc, cfg, path), checks.DefaultTimeout)
+}
+
+// runPostScaffoldChecks runs the health checks after a scaffold when
+// scaffold.run_checks is set. Failures are warnings: the scaffold itself
+// succeeded.
+func runPostScaffoldChecks(pc *ProjectContext, cfg *config.Config, path string, quiet bool) {
+	if !cfg.Scaffold.RunChecks {
+		return
+	}
+	results := runChecks(pc, cfg, path)
+	if !quiet {
+		printCheckResults(os.Stderr, results)
+	}
+	if failed := checks.Failed(results); failed > 0 {
+		ui.PrintWarning(fmt.Sprintf("%d of %d health checks failed; run 'arbor check' for details", failed, len(results)))
+	}
+}
+
+func printCheckResults(w io.Writer, results []checks.Result) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No checks configured")
+		return
+	}
+
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+	for _, r := range results {
+		symbol := "✓"
+		switch r.Status {
+		case checks.StatusFail:
+			symbol = "✗"
+		case checks.StatusWarn:
+			symbol = "!"
+		case checks.StatusSkip:
+			symbol = "-"
+		}
+		fmt.Fprintf(w, "%s %-*s  %s\n", symbol, width, r.Name, r.Message)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().Bool("json", false, "Output the check results as JSON")
+}
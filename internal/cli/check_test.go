@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artisanexperiences/arbor/internal/checks"
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestWorktreeChecks(t *testing.T) {
+	pc := &ProjectContext{}
+	path := t.TempDir()
+
+	defaults := worktreeChecks(pc, &config.Config{Preset: "laravel"}, path)
+	assert.Equal(t, []config.CheckConfig{
+		{Name: "http"},
+		{Name: "artisan.about"},
+		{Name: "db"},
+		{Name: "laravel.permissions"},
+		{Name: "queue", Optional: true},
+		{Name: "mail", Optional: true},
+		{Name: "files"},
+	}, defaults)
+
+	configured := []config.CheckConfig{{Name: "command", Command: "php artisan migrate:status"}}
+	assert.Equal(t, configured, worktreeChecks(pc, &config.Config{Preset: "laravel", Checks: configured}, path))
+
+	assert.Empty(t, worktreeChecks(pc, &config.Config{}, path), "no preset detected")
+}
+
+func TestPrintCheckResults(t *testing.T) {
+	var out bytes.Buffer
+	printCheckResults(&out, []checks.Result{
+		{Name: "http", Status: checks.StatusPass, Message: "GET https://feature-x.test: 200 OK"},
+		{Name: "artisan.about", Status: checks.StatusSkip, Message: "no artisan file"},
+		{Name: "db", Status: checks.StatusFail, Message: "database app_feature_x does not exist on 127.0.0.1:3306"},
+		{Name: "queue", Status: checks.StatusWarn, Message: "no queue worker running for the redis queue"},
+	})
+
+	assert.Equal(t, `✓ http           GET https://feature-x.test: 200 OK
+- artisan.about  no artisan file
+✗ db             database app_feature_x does not exist on 127.0.0.1:3306
+! queue          no queue worker running for the redis queue
+`, out.String())
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// ciBranchVars are the variables CI providers set to the branch being
+// built, for checkouts with a detached HEAD. GITHUB_HEAD_REF is only set
+// for pull requests, so it comes before GITHUB_REF_NAME.
+var ciBranchVars = []string{
+	"GITHUB_HEAD_REF",
+	"GITHUB_REF_NAME",
+	"CI_COMMIT_REF_NAME",
+	"BITBUCKET_BRANCH",
+	"CIRCLE_BRANCH",
+	"BUILDKITE_BRANCH",
+}
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: i18n.T("cmd.ci.short"),
+	Long: `Commands for running arbor in CI pipelines, on a plain checkout of the
+repository rather than an arbor project.`,
+}
+
+var ciBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: i18n.T("cmd.ci.bootstrap.short"),
+	Long: `Run the project's scaffold steps in a CI checkout, so pipelines reuse the
+steps developers run locally.
+
+The checkout is the git repository containing the current directory, and
+its committed arbor.yaml is used; without one, the detected preset's steps
+run. Steps run without prompts and Herd steps are skipped. The 'ci'
+section of arbor.yaml adjusts the run:
+
+  ci:
+    skip: [db.create]       # steps not run in CI
+    env:                    # values pinned in the primary env file
+      DB_CONNECTION: sqlite
+    steps: [...]            # replace the scaffold steps entirely
+
+Pinned env values replace the value of env.write steps for the same key,
+and keys no step writes are written once the env file has been copied.
+
+The branch is the checked-out one, or the one the CI provider reports
+(GitHub Actions, GitLab, Bitbucket, CircleCI, Buildkite) when HEAD is
+detached. --branch overrides both.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+		root, err := git.TopLevel(cwd)
+		if err != nil {
+			return err
+		}
+
+		pc, err := openCIProject(root)
+		if err != nil {
+			return err
+		}
+
+		branch := mustGetString(cmd, "branch")
+		if branch == "" {
+			branch = ciBranch(root, os.Getenv)
+		}
+
+		cfg, err := ciConfig(pc, root)
+		if err != nil {
+			return err
+		}
+		wt := git.Worktree{Path: root, Branch: branch}
+		if branch == "" {
+			wt.Detached = true
+			wt.Head, _ = git.ResolveCommit(root, "HEAD")
+		}
+
+		promptMode := types.PromptMode{NoInteractive: true, CI: true, Trust: mustGetBool(cmd, "trust")}
+		if err := scaffoldWorktree(pc, cfg, wt, promptMode, mustGetBool(cmd, "dry-run"), mustGetBool(cmd, "verbose"), mustGetBool(cmd, "quiet")); err != nil {
+			return err
+		}
+
+		ui.PrintDone(fmt.Sprintf("CI bootstrap complete: %s", wt.Label()))
+		return nil
+	},
+}
+
+// openCIProject opens a plain checkout as a project with no bare
+// repository. A missing arbor.yaml leaves the config empty, so the
+// detected preset's steps run.
+func openCIProject(root string) (*ProjectContext, error) {
+	configs := config.NewStore()
+	cfg := &config.Config{}
+	if _, err := os.Stat(filepath.Join(root, "arbor.yaml")); err == nil {
+		cfg, err = configs.Project(root)
+		if err != nil {
+			return nil, fmt.Errorf("loading project config: %w", err)
+		}
+	}
+
+	defaultBranch := cfg.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch, _ = git.GetDefaultBranch(root)
+		if defaultBranch == "" {
+			defaultBranch = config.DefaultBranch
+		}
+	}
+
+	return &ProjectContext{
+		CWD:           root,
+		ProjectPath:   root,
+		Config:        cfg,
+		DefaultBranch: defaultBranch,
+		configs:       configs,
+	}, nil
+}
+
+// ciBranch returns the branch checked out in root or, on a detached HEAD,
+// the branch the CI provider reports. It is empty when neither is known.
+func ciBranch(root string, getenv func(string) string) string {
+	if branch, err := git.GetCurrentBranch(root); err == nil && branch != "" {
+		return branch
+	}
+	for _, name := range ciBranchVars {
+		if branch := getenv(name); branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// ciConfig returns the project config with the scaffold steps replaced by
+// the steps to run in CI.
+func ciConfig(pc *ProjectContext, root string) (*config.Config, error) {
+	cfg := *pc.Config
+	var steps []config.StepConfig
+	if len(cfg.CI.Steps) == 0 {
+		steps = pc.ScaffoldManager().StepConfigsForWorktree(pc.Config, root)
+	} else {
+		// Groups are expanded here so that ci.skip and the Herd filter
+		// apply to the steps they include.
+		expanded, errs := cfg.ExpandSteps("ci.steps", cfg.CI.Steps)
+		if len(errs) > 0 {
+			return nil, &scaffold.StepConfigError{Kind: "ci", Errs: errs}
+		}
+		for _, step := range expanded {
+			steps = append(steps, step.Step)
+		}
+	}
+	cfg.Scaffold.Steps = ciSteps(steps, cfg.CI, cfg.PrimaryEnvFile())
+	cfg.Scaffold.Override = true
+	return &cfg, nil
+}
+
+// ciSteps applies the ci section to a list of steps: skipped and Herd
+// steps are dropped, and env values are pinned in envFile.
+func ciSteps(steps []config.StepConfig, ci config.CIConfig, envFile string) []config.StepConfig {
+	pinned := make(map[string]string, len(ci.Env))
+	for key, value := range ci.Env {
+		pinned[strings.ToUpper(key)] = value
+	}
+
+	result := make([]config.StepConfig, 0, len(steps)+len(pinned))
+	written := make(map[string]bool)
+	insertAt := 0
+	for _, step := range steps {
+		if step.Name == "herd" || slices.Contains(ci.Skip, step.Name) {
+			continue
+		}
+		writesEnvFile := step.File == "" || step.File == envFile
+		if step.Name == "env.write" && writesEnvFile {
+			if value, ok := pinned[step.Key]; ok {
+				step.Value = value
+				written[step.Key] = true
+			}
+		}
+		result = append(result, step)
+		if step.Name == "file.copy" && step.To == envFile {
+			insertAt = len(result)
+		}
+	}
+
+	var keys []string
+	for key := range pinned {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	writes := make([]config.StepConfig, 0, len(keys))
+	for _, key := range keys {
+		writes = append(writes, config.StepConfig{Name: "env.write", Key: key, Value: pinned[key], File: envFile})
+	}
+	return slices.Insert(result, insertAt, writes...)
+}
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+	ciCmd.AddCommand(ciBootstrapCmd)
+
+	ciBootstrapCmd.Flags().String("branch", "", "Branch to scaffold as (default: the checked-out or CI branch)")
+}
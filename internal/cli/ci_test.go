@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestCISteps(t *testing.T) {
+	steps := []config.StepConfig{
+		{Name: "php.composer", Args: []string{"install"}},
+		{Name: "file.copy", From: ".env.example", To: ".env"},
+		{Name: "env.write", Key: "DB_DATABASE", Value: "{{ .DbName }}"},
+		{Name: "env.write", Key: "DB_DATABASE", Value: "other", File: ".env.testing"},
+		{Name: "db.create"},
+		{Name: "herd", Args: []string{"link"}},
+	}
+	ci := config.CIConfig{
+		Skip: []string{"db.create"},
+		Env:  map[string]string{"db_database": "testing", "db_connection": "sqlite", "APP_ENV": "ci"},
+	}
+
+	got := ciSteps(steps, ci, ".env")
+
+	assert.Equal(t, []config.StepConfig{
+		{Name: "php.composer", Args: []string{"install"}},
+		{Name: "file.copy", From: ".env.example", To: ".env"},
+		{Name: "env.write", Key: "APP_ENV", Value: "ci", File: ".env"},
+		{Name: "env.write", Key: "DB_CONNECTION", Value: "sqlite", File: ".env"},
+		{Name: "env.write", Key: "DB_DATABASE", Value: "testing"},
+		{Name: "env.write", Key: "DB_DATABASE", Value: "other", File: ".env.testing"},
+	}, got)
+}
+
+func TestCISteps_NoEnvCopy(t *testing.T) {
+	got := ciSteps([]config.StepConfig{{Name: "bash.run", Command: "make"}}, config.CIConfig{Env: map[string]string{"A": "1"}}, ".env")
+
+	assert.Equal(t, []config.StepConfig{
+		{Name: "env.write", Key: "A", Value: "1", File: ".env"},
+		{Name: "bash.run", Command: "make"},
+	}, got)
+}
+
+func TestCIBranch(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{"GITHUB_REF_NAME": "main", "GITHUB_HEAD_REF": "feature/x"}
+
+	assert.Equal(t, "feature/x", ciBranch(dir, func(name string) string { return env[name] }))
+	assert.Equal(t, "", ciBranch(dir, func(string) string { return "" }))
+
+	cmd := exec.Command("git", "init", "-b", "checked-out")
+	cmd.Dir = dir
+	require.NoError(t, cmd.Run())
+	assert.Equal(t, "checked-out", ciBranch(dir, func(name string) string { return env[name] }))
+}
+
+func TestCIConfig(t *testing.T) {
+	root := t.TempDir()
+	arborYAML := `scaffold:
+  override: true
+  steps:
+    - name: bash.run
+      command: make
+    - name: herd
+ci:
+  env:
+    DB_CONNECTION: sqlite
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "arbor.yaml"), []byte(arborYAML), 0644))
+
+	pc, err := openCIProject(root)
+	require.NoError(t, err)
+	assert.Equal(t, root, pc.ProjectPath)
+	assert.Empty(t, pc.BarePath)
+
+	cfg, err := ciConfig(pc, root)
+	require.NoError(t, err)
+	assert.True(t, cfg.Scaffold.Override)
+	assert.Equal(t, []config.StepConfig{
+		{Name: "env.write", Key: "DB_CONNECTION", Value: "sqlite", File: ".env"},
+		{Name: "bash.run", Command: "make"},
+	}, cfg.Scaffold.Steps)
+	assert.Len(t, pc.Config.Scaffold.Steps, 2, "the project config is left unchanged")
+}
+
+func TestOpenCIProject_NoConfig(t *testing.T) {
+	pc, err := openCIProject(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultBranch, pc.DefaultBranch)
+}
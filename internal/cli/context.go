@@ -21,6 +21,10 @@ type ProjectContext struct {
 	Config        *config.Config
 	DefaultBranch string
 
+	// configs memoises config reads for the rest of the command and is
+	// shared with the scaffold manager.
+	configs *config.Store
+
 	presetManager   *presets.Manager
 	scaffoldManager *scaffold.ScaffoldManager
 	managersInit    sync.Once
@@ -38,7 +42,8 @@ func OpenProjectFromCWD() (*ProjectContext, error) {
 	}
 
 	projectPath := filepath.Dir(barePath)
-	cfg, err := config.LoadProject(projectPath)
+	configs := config.NewStore()
+	cfg, err := configs.Project(projectPath)
 	if err != nil {
 		return nil, fmt.Errorf("loading project config: %w", err)
 	}
@@ -57,9 +62,28 @@ func OpenProjectFromCWD() (*ProjectContext, error) {
 		ProjectPath:   projectPath,
 		Config:        cfg,
 		DefaultBranch: defaultBranch,
+		configs:       configs,
 	}, nil
 }
 
+// GlobalConfig returns the global config, read once per command.
+func (pc *ProjectContext) GlobalConfig() (*config.GlobalConfig, error) {
+	return pc.configs.Global()
+}
+
+// ResetConfigCache forgets memoised config reads. Long-running commands
+// call it before each unit of work so they see changes made by other
+// arbor processes.
+func (pc *ProjectContext) ResetConfigCache() {
+	pc.configs.Reset()
+}
+
+// LocalState returns a worktree's .arbor.local, read once per command
+// unless it is written in the meantime.
+func (pc *ProjectContext) LocalState(worktreePath string) (*config.LocalState, error) {
+	return pc.configs.LocalState(worktreePath)
+}
+
 func (pc *ProjectContext) IsInWorktree() bool {
 	// Check if .bare exists in parent hierarchy
 	barePath, err := git.FindBarePath(pc.CWD)
@@ -112,6 +136,12 @@ func (pc *ProjectContext) ScaffoldManager() *scaffold.ScaffoldManager {
 	return pc.scaffoldManager
 }
 
+// RemoteBranches lists the project's remote branches for interactive
+// pickers, from the cache in .arbor/cache unless refresh is set.
+func (pc *ProjectContext) RemoteBranches(refresh bool) ([]string, error) {
+	return git.CachedRemoteBranches(pc.BarePath, config.CacheDir(pc.ProjectPath), git.RemoteBranchCacheTTL, refresh)
+}
+
 func (pc *ProjectContext) initManagers() {
 	// Create explicit step registry with default steps
 	stepRegistry := steps.NewRegistry()
@@ -120,5 +150,6 @@ func (pc *ProjectContext) initManagers() {
 	// Initialize managers with dependency injection
 	pc.presetManager = presets.NewManager()
 	pc.scaffoldManager = scaffold.NewScaffoldManagerWithRegistry(stepRegistry)
+	pc.scaffoldManager.SetConfigStore(pc.configs)
 	presets.RegisterAllWithScaffold(pc.scaffoldManager)
 }
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// copyStateVars are the template variables whose values differ between
+// worktrees. Copied env files have them rewritten for the destination. Of
+// values of the same length, the earlier variable wins, so a worktree
+// folder name, which is safe in URLs and paths, is preferred to its branch.
+var copyStateVars = []string{"WorktreePath", "TestDbName", "DbName", "DbSuffix", "SanitizedSiteName", "SiteName", "Path", "Branch"}
+
+// minCopyStateValue is the shortest value copy-state rewrites; shorter ones,
+// such as a worktree named "x", would match too much.
+const minCopyStateValue = 3
+
+var copyStateCmd = &cobra.Command{
+	Use:   "copy-state FROM [TO]",
+	Short: i18n.T("cmd.copy-state.short"),
+	Long: `Copies gitignored files and directories, such as .env and uploaded files,
+from one worktree to another (the current worktree when TO is omitted).
+
+The paths are copy_state.paths in arbor.yaml, or --path; by default the env
+file, storage/app and public/uploads. Paths that do not exist in FROM or
+are tracked by git are skipped.
+
+Env files (.env and .env.*) are rewritten on the way: values specific to
+FROM, such as its database name and suffix, site name, branch and path, are
+turned into template variables and rendered for TO. So DB_DATABASE=app_swift_runner
+becomes app_calm_river when TO's suffix is calm_river.
+
+Files and directories that exist in TO are left alone unless --force is
+set, which replaces them.
+
+Arguments:
+  FROM, TO  Worktree folder name, branch or path relative to the project
+            root, or part of one when it matches a single worktree`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+
+		from, err := matchWorktree(worktrees, pc.ProjectPath, args[0])
+		if err != nil {
+			return err
+		}
+		var to *git.Worktree
+		if len(args) > 1 {
+			if to, err = matchWorktree(worktrees, pc.ProjectPath, args[1]); err != nil {
+				return err
+			}
+		} else {
+			for i, wt := range worktrees {
+				if wt.IsCurrent {
+					to = &worktrees[i]
+				}
+			}
+			if to == nil {
+				return fmt.Errorf("run from a worktree or name the worktree to copy to")
+			}
+		}
+		if from.Path == to.Path {
+			return fmt.Errorf("cannot copy %s onto itself", filepath.Base(from.Path))
+		}
+
+		paths, _ := cmd.Flags().GetStringArray("path")
+		if len(paths) == 0 {
+			paths = pc.Config.CopyState.Paths
+		}
+		if len(paths) == 0 {
+			paths = []string{pc.Config.PrimaryEnvFile(), "storage/app", "public/uploads"}
+		}
+
+		fromCtx, err := copyStateContext(pc, *from)
+		if err != nil {
+			return err
+		}
+		toCtx, err := copyStateContext(pc, *to)
+		if err != nil {
+			return err
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+		force := mustGetBool(cmd, "force")
+		copied := 0
+		for _, path := range paths {
+			src := filepath.Join(from.Path, path)
+			if _, err := os.Lstat(src); err != nil {
+				if mustGetBool(cmd, "verbose") {
+					ui.PrintInfo(fmt.Sprintf("Skipping %s: not in %s", path, filepath.Base(from.Path)))
+				}
+				continue
+			}
+			ignored, err := git.IsIgnored(from.Path, path)
+			if err != nil {
+				return fmt.Errorf("checking whether %s is ignored: %w", path, err)
+			}
+			if !ignored {
+				ui.PrintWarning(fmt.Sprintf("Skipping %s: it is tracked by git, not local state", path))
+				continue
+			}
+
+			if dryRun {
+				ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would copy %s", path))
+				continue
+			}
+			done, err := copyStatePath(src, filepath.Join(to.Path, path), fromCtx, toCtx, force)
+			if err != nil {
+				return err
+			}
+			if done {
+				ui.PrintSuccess(fmt.Sprintf("Copied %s", path))
+				copied++
+			} else {
+				ui.PrintInfo(fmt.Sprintf("Skipped %s: it exists in %s (use --force to replace it)", path, filepath.Base(to.Path)))
+			}
+		}
+
+		if !dryRun {
+			ui.PrintDone(fmt.Sprintf("Copied %d of %d paths from %s to %s", copied, len(paths), filepath.Base(from.Path), filepath.Base(to.Path)))
+		}
+		return nil
+	},
+}
+
+// copyStateContext returns the scaffold context of a worktree, with its
+// absolute path as WorktreePath.
+func copyStateContext(pc *ProjectContext, wt git.Worktree) (*types.ScaffoldContext, error) {
+	preset := pc.Config.Preset
+	if preset == "" {
+		preset = pc.PresetManager().Detect(wt.Path)
+	}
+	ctx, err := pc.ScaffoldManager().NewContext(wt.Path, scaffoldBranch(wt), filepath.Base(pc.ProjectPath), scaffoldSiteName(pc, pc.Config, wt), preset, pc.BarePath)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EnvFile = pc.Config.EnvFile
+	ctx.DefaultBranch = pc.DefaultBranch
+	ctx.SetVar("WorktreePath", wt.Path)
+	return ctx, nil
+}
+
+// copyStatePath copies a file or directory, rewriting env files for the
+// destination. It reports false when dst exists and force is not set.
+func copyStatePath(src, dst string, fromCtx, toCtx *types.ScaffoldContext, force bool) (bool, error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		if !force {
+			return false, nil
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return false, fmt.Errorf("removing %s: %w", dst, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+
+	if info.IsDir() {
+		return true, utils.CopyDir(src, dst)
+	}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+	if isEnvFileName(filepath.Base(src)) {
+		rewritten, err := rewriteForWorktree(string(content), fromCtx, toCtx)
+		if err != nil {
+			return false, fmt.Errorf("rewriting %s: %w", filepath.Base(src), err)
+		}
+		content = []byte(rewritten)
+	}
+	if err := os.WriteFile(dst, content, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("writing %s: %w", dst, err)
+	}
+	return true, nil
+}
+
+func isEnvFileName(name string) bool {
+	return name == ".env" || strings.HasPrefix(name, ".env.")
+}
+
+// rewriteForWorktree turns the values of copyStateVars in content that
+// belong to from into template variables, then renders them for to. Values
+// to has no counterpart for, such as a database suffix before its first
+// scaffold, are left as they are. Values
+// only match as whole words, so a worktree named "main" leaves "domain"
+// alone; longer values are replaced first, so a database name is not
+// broken up by its suffix.
+func rewriteForWorktree(content string, from, to *types.ScaffoldContext) (string, error) {
+	fromVars := from.SnapshotForTemplate()
+	toVars := to.SnapshotForTemplate()
+	if toVars["DbSuffix"] == "" {
+		// DbName is the site name alone until the suffix is set.
+		toVars["DbName"] = ""
+		toVars["TestDbName"] = ""
+	}
+
+	names := make([]string, 0, len(copyStateVars))
+	for _, name := range copyStateVars {
+		if value := fromVars[name]; len(value) >= minCopyStateValue && toVars[name] != "" && value != toVars[name] {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(fromVars[names[i]]) > len(fromVars[names[j]])
+	})
+
+	// Braces already in the file are kept literally.
+	templated := strings.ReplaceAll(content, "{{", "{{`{{`}}")
+	for _, name := range names {
+		pattern := regexp.MustCompile(`(^|[^A-Za-z0-9])` + regexp.QuoteMeta(fromVars[name]) + `([^A-Za-z0-9]|$)`)
+		templated = pattern.ReplaceAllString(templated, "${1}{{ ."+name+" }}${2}")
+	}
+	return template.ReplaceTemplateVars(templated, to)
+}
+
+func init() {
+	rootCmd.AddCommand(copyStateCmd)
+
+	copyStateCmd.Flags().StringArray("path", nil, "Copy this path instead of copy_state.paths (repeatable)")
+	copyStateCmd.Flags().Bool("force", false, "Replace files and directories that exist in the destination")
+}
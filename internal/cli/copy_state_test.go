@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func copyStateTestContext(dir, branch, suffix string) *types.ScaffoldContext {
+	ctx := &types.ScaffoldContext{
+		WorktreePath: dir,
+		Path:         filepath.Base(dir),
+		Branch:       branch,
+		SiteName:     "shop",
+		DbSuffix:     suffix,
+	}
+	ctx.SetVar("WorktreePath", dir)
+	return ctx
+}
+
+func TestRewriteForWorktree(t *testing.T) {
+	from := copyStateTestContext("/projects/shop/main", "main", "swift_runner")
+	to := copyStateTestContext("/projects/shop/feature-cart", "feature/cart", "calm_river")
+
+	content := `APP_NAME=shop
+APP_URL=http://main.shop.test
+APP_DOMAIN=domain.test
+DB_DATABASE=shop_swift_runner
+CACHE_PREFIX=swift_runner_
+LOG_PATH=/projects/shop/main/storage/logs
+MAIL_FROM={{ not a template }}
+`
+	got, err := rewriteForWorktree(content, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, `APP_NAME=shop
+APP_URL=http://feature-cart.shop.test
+APP_DOMAIN=domain.test
+DB_DATABASE=shop_calm_river
+CACHE_PREFIX=calm_river_
+LOG_PATH=/projects/shop/feature-cart/storage/logs
+MAIL_FROM={{ not a template }}
+`, got)
+}
+
+func TestRewriteForWorktree_KeepsValuesWithoutCounterpart(t *testing.T) {
+	from := copyStateTestContext("/projects/shop/main", "main", "swift_runner")
+	to := copyStateTestContext("/projects/shop/feature-cart", "feature/cart", "")
+
+	got, err := rewriteForWorktree("DB_DATABASE=shop_swift_runner\n", from, to)
+	require.NoError(t, err)
+	assert.Equal(t, "DB_DATABASE=shop_swift_runner\n", got)
+}
+
+func TestCopyStatePath(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	from := copyStateTestContext(src, "main", "swift_runner")
+	to := copyStateTestContext(dst, "feature", "calm_river")
+
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".env"), []byte("DB_DATABASE=shop_swift_runner\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "storage", "app"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "storage", "app", "avatar.png"), []byte("png"), 0644))
+
+	done, err := copyStatePath(filepath.Join(src, ".env"), filepath.Join(dst, ".env"), from, to, false)
+	require.NoError(t, err)
+	assert.True(t, done)
+	env, err := os.ReadFile(filepath.Join(dst, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "DB_DATABASE=shop_calm_river\n", string(env))
+
+	done, err = copyStatePath(filepath.Join(src, "storage", "app"), filepath.Join(dst, "storage", "app"), from, to, false)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.FileExists(t, filepath.Join(dst, "storage", "app", "avatar.png"))
+
+	t.Run("existing destination is kept without force", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dst, ".env"), []byte("KEEP=1\n"), 0600))
+		done, err := copyStatePath(filepath.Join(src, ".env"), filepath.Join(dst, ".env"), from, to, false)
+		require.NoError(t, err)
+		assert.False(t, done)
+		env, _ := os.ReadFile(filepath.Join(dst, ".env"))
+		assert.Equal(t, "KEEP=1\n", string(env))
+	})
+
+	t.Run("force replaces the destination", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dst, "storage", "app", "stale.txt"), []byte("old"), 0644))
+		done, err := copyStatePath(filepath.Join(src, "storage", "app"), filepath.Join(dst, "storage", "app"), from, to, true)
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.NoFileExists(t, filepath.Join(dst, "storage", "app", "stale.txt"))
+		assert.FileExists(t, filepath.Join(dst, "storage", "app", "avatar.png"))
+	})
+}
@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/history"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// Unit formats accepted by --print-unit.
+const (
+	unitSystemd = "systemd"
+	unitLaunchd = "launchd"
+)
+
+// lockfileSteps maps dependency lockfiles to the step that installs from
+// them. When a lockfile changes, the daemon re-runs that step's configured
+// install steps.
+var lockfileSteps = []struct {
+	file string
+	step string
+}{
+	{"composer.lock", "php.composer"},
+	{"package-lock.json", "node.npm"},
+	{"yarn.lock", "node.yarn"},
+	{"pnpm-lock.yaml", "node.pnpm"},
+	{"bun.lock", "node.bun"},
+	{"bun.lockb", "node.bun"},
+}
+
+// installArgs are the first arguments of the steps in lockfileSteps that
+// install dependencies, as opposed to running scripts such as a build.
+var installArgs = map[string]bool{"install": true, "ci": true, "i": true, "update": true}
+
+// installsFrom reports whether a step installs dependencies from one of
+// lockfiles. Package manager steps without arguments, such as a bare yarn,
+// install too.
+func installsFrom(stepConfig config.StepConfig, lockfiles map[string]bool) bool {
+	if len(stepConfig.Args) > 0 && !installArgs[stepConfig.Args[0]] {
+		return false
+	}
+	for _, lf := range lockfileSteps {
+		if lf.step == stepConfig.Name && lockfiles[lf.file] {
+			return true
+		}
+	}
+	return false
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: i18n.T("cmd.daemon.short"),
+	Long: `Watch the project and run scaffold steps in the background.
+
+Every --interval the daemon:
+  - scaffolds worktrees queued with 'arbor work --no-scaffold'
+  - re-runs the install steps of worktrees whose composer.lock,
+    package-lock.json, yarn.lock, pnpm-lock.yaml or bun.lock changed
+    (for example after a pull)
+
+Results are recorded in the project's history ('arbor history').
+
+The daemon is opt-in. Run it from the project root, or generate a service
+definition with --print-unit systemd or --print-unit launchd.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return fmt.Errorf("opening project: %w", err)
+		}
+
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive, got %s", interval)
+		}
+
+		if format := mustGetString(cmd, "print-unit"); format != "" {
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("locating arbor executable: %w", err)
+			}
+			return printDaemonUnit(os.Stdout, format, daemonUnit{
+				Executable:  executable,
+				ProjectPath: pc.ProjectPath,
+				Name:        utils.SanitisePath(filepath.Base(pc.ProjectPath)),
+				Interval:    interval,
+			})
+		}
+
+		watcher := &daemonWatcher{pc: pc}
+		if mustGetBool(cmd, "once") {
+			watcher.tick()
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ui.PrintInfo(fmt.Sprintf("Watching %s every %s", pc.ProjectPath, interval))
+		watcher.run(ctx, interval)
+		return nil
+	},
+}
+
+// daemonWatcher runs the daemon's passes over a project.
+type daemonWatcher struct {
+	pc *ProjectContext
+	// failed holds the attemptKey of each worktree's last failed run, so
+	// the run is not repeated every interval.
+	failed map[string]string
+}
+
+func (d *daemonWatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.tick()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs one pass over the project's worktrees, with arbor.yaml read
+// again so edits to it apply without restarting the daemon. A worktree
+// whose scaffold or install failed is skipped until arbor.yaml, its
+// lockfiles or its commit change.
+func (d *daemonWatcher) tick() {
+	d.pc.ResetConfigCache()
+	cfg, err := d.pc.configs.Project(d.pc.ProjectPath)
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Loading project config: %v", err))
+		return
+	}
+	d.pc.Config = cfg
+
+	worktrees, err := git.ListWorktreesDetailed(d.pc.BarePath, d.pc.ProjectPath, d.pc.DefaultBranch)
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Listing worktrees: %v", err))
+		return
+	}
+
+	for _, wt := range worktrees {
+		state, err := config.ReadLocalState(wt.Path)
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("%s: %v", wt.Label(), err))
+			continue
+		}
+
+		hashes := lockfileHashes(wt.Path)
+		key := attemptKey(d.pc.ProjectPath, wt, hashes)
+		if d.failed[wt.Path] == key {
+			continue
+		}
+		delete(d.failed, wt.Path)
+
+		var runErr error
+		switch {
+		case state.ScaffoldPending:
+			runErr = d.runScaffold(wt, cfg, []string{"daemon", "scaffold", scaffoldBranch(wt)})
+		case len(state.LockfileHashes) > 0:
+			changed := changedLockfiles(state.LockfileHashes, hashes)
+			if len(changed) == 0 {
+				continue
+			}
+			installCfg := lockfileConfig(d.pc.ScaffoldManager().StepConfigsForWorktree(cfg, wt.Path), changed, cfg)
+			if len(installCfg.Scaffold.Steps) > 0 {
+				runErr = d.runScaffold(wt, installCfg, append([]string{"daemon", "install", scaffoldBranch(wt)}, changed...))
+			}
+		}
+		if runErr != nil {
+			// The lockfile hashes are left as they were, so the install
+			// runs again once the worktree is retried.
+			if d.failed == nil {
+				d.failed = make(map[string]string)
+			}
+			d.failed[wt.Path] = key
+			ui.PrintWarning(fmt.Sprintf("%s: %v; retrying once arbor.yaml, its lockfiles or its commit change", wt.Label(), runErr))
+			continue
+		}
+
+		// Worktrees seen for the first time only record their lockfiles:
+		// their dependencies were installed when they were scaffolded.
+		if !maps.Equal(state.LockfileHashes, hashes) {
+			if err := config.SetLockfileHashes(wt.Path, hashes); err != nil {
+				ui.PrintWarning(fmt.Sprintf("%s: %v", wt.Label(), err))
+			}
+		}
+	}
+}
+
+// attemptKey fingerprints what a daemon run in wt depends on: the
+// project's and the worktree's arbor.yaml, its lockfiles and its commit.
+func attemptKey(projectPath string, wt git.Worktree, hashes map[string]string) string {
+	h := sha256.New()
+	for _, path := range []string{filepath.Join(projectPath, "arbor.yaml"), filepath.Join(wt.Path, "arbor.yaml")} {
+		content, _ := os.ReadFile(path)
+		h.Write(content)
+		h.Write([]byte{0})
+	}
+	fmt.Fprintf(h, "%s\x00", wt.Head)
+	for _, lf := range lockfileSteps {
+		fmt.Fprintf(h, "%s=%s\x00", lf.file, hashes[lf.file])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lockfileHashes hashes the dependency lockfiles present in a worktree.
+func lockfileHashes(worktreePath string) map[string]string {
+	hashes := make(map[string]string)
+	for _, lf := range lockfileSteps {
+		content, err := os.ReadFile(filepath.Join(worktreePath, lf.file))
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(content)
+		hashes[lf.file] = hex.EncodeToString(sum[:])
+	}
+	return hashes
+}
+
+// changedLockfiles returns the lockfiles that were added or modified
+// since previous was recorded, in lockfileSteps order.
+func changedLockfiles(previous, current map[string]string) []string {
+	var changed []string
+	for _, lf := range lockfileSteps {
+		if hash, ok := current[lf.file]; ok && previous[lf.file] != hash {
+			changed = append(changed, lf.file)
+		}
+	}
+	return changed
+}
+
+// lockfileConfig returns a copy of cfg that runs only the steps installing
+// from the changed lockfiles, without pre-flight checks. Other steps of the
+// same package manager, such as npm run build, are left out.
+func lockfileConfig(stepConfigs []config.StepConfig, changed []string, cfg *config.Config) *config.Config {
+	lockfiles := make(map[string]bool)
+	for _, file := range changed {
+		lockfiles[file] = true
+	}
+
+	filtered := *cfg
+	filtered.Scaffold = config.ScaffoldConfig{Override: true, Strict: cfg.Scaffold.Strict}
+	for _, stepConfig := range stepConfigs {
+		if installsFrom(stepConfig, lockfiles) {
+			filtered.Scaffold.Steps = append(filtered.Scaffold.Steps, stepConfig)
+		}
+	}
+	return &filtered
+}
+
+// runScaffold scaffolds a worktree with cfg, records the result in the
+// project history and returns the scaffold's error.
+func (d *daemonWatcher) runScaffold(wt git.Worktree, cfg *config.Config, args []string) error {
+	promptMode := types.PromptMode{NoInteractive: true, CI: true}
+
+	start := time.Now()
+	err := scaffoldWorktree(d.pc, cfg, wt, promptMode, false, false, true)
+
+	entry := history.Entry{
+		Time:       start.UTC(),
+		User:       history.CurrentUser(),
+		Command:    "daemon",
+		Args:       args,
+		Dir:        wt.Path,
+		DurationMs: time.Since(start).Milliseconds(),
+		Result:     history.ResultOK,
+	}
+	if err != nil {
+		entry.Result = history.ResultError
+		entry.Error = err.Error()
+	} else {
+		ui.PrintSuccess(fmt.Sprintf("%s: arbor %s", wt.Label(), strings.Join(args, " ")))
+	}
+
+	if err := history.Append(d.pc.ProjectPath, entry); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not record history: %v", err))
+	}
+	return err
+}
+
+// daemonUnit describes the service generated by --print-unit.
+type daemonUnit struct {
+	Executable  string
+	ProjectPath string
+	Name        string
+	Interval    time.Duration
+}
+
+var systemdUnitTemplate = template.Must(template.New("systemd").Parse(`[Unit]
+Description=arbor daemon for {{ .Name }}
+
+[Service]
+Type=simple
+WorkingDirectory={{ .ProjectPath }}
+ExecStart="{{ .Executable }}" daemon --interval {{ .Interval }}
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`))
+
+var launchdUnitTemplate = template.Must(template.New("launchd").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var b strings.Builder
+		err := xml.EscapeText(&b, []byte(s))
+		return b.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>dev.arbor.daemon.{{ xml .Name }}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{ xml .Executable }}</string>
+		<string>daemon</string>
+		<string>--interval</string>
+		<string>{{ .Interval }}</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{ xml .ProjectPath }}</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{ xml .ProjectPath }}/.arbor/daemon.log</string>
+</dict>
+</plist>
+`))
+
+// printDaemonUnit writes a systemd user unit or launchd agent for the
+// daemon, followed by install instructions on stderr.
+func printDaemonUnit(w io.Writer, format string, unit daemonUnit) error {
+	switch format {
+	case unitSystemd:
+		if err := systemdUnitTemplate.Execute(w, unit); err != nil {
+			return fmt.Errorf("rendering systemd unit: %w", err)
+		}
+		ui.PrintInfo(fmt.Sprintf("Save as ~/.config/systemd/user/arbor-%s.service, then run: systemctl --user enable --now arbor-%s", unit.Name, unit.Name))
+	case unitLaunchd:
+		if err := launchdUnitTemplate.Execute(w, unit); err != nil {
+			return fmt.Errorf("rendering launchd agent: %w", err)
+		}
+		ui.PrintInfo(fmt.Sprintf("Save as ~/Library/LaunchAgents/dev.arbor.daemon.%s.plist, then run: launchctl load ~/Library/LaunchAgents/dev.arbor.daemon.%s.plist", unit.Name, unit.Name))
+	default:
+		return fmt.Errorf("unknown unit format %q (expected %s or %s)", format, unitSystemd, unitLaunchd)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().Duration("interval", 30*time.Second, "How often to check worktrees")
+	daemonCmd.Flags().Bool("once", false, "Run a single pass and exit")
+	daemonCmd.Flags().String("print-unit", "", "Print a service definition instead of running: systemd or launchd")
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/history"
+)
+
+func TestPrintDaemonUnit(t *testing.T) {
+	unit := daemonUnit{
+		Executable:  "/usr/local/bin/arbor",
+		ProjectPath: "/home/dev/R&D app",
+		Name:        "r-d-app",
+		Interval:    time.Minute,
+	}
+
+	for _, format := range []string{unitSystemd, unitLaunchd} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, printDaemonUnit(&buf, format, unit))
+			assertGolden(t, "daemon_"+format, buf.String())
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printDaemonUnit(&buf, "upstart", unit)
+		assert.ErrorContains(t, err, `unknown unit format "upstart"`)
+	})
+}
+
+func TestChangedLockfiles(t *testing.T) {
+	previous := map[string]string{"composer.lock": "a", "package-lock.json": "b"}
+	current := map[string]string{"composer.lock": "a", "package-lock.json": "c", "yarn.lock": "d"}
+
+	assert.Equal(t, []string{"package-lock.json", "yarn.lock"}, changedLockfiles(previous, current))
+	assert.Empty(t, changedLockfiles(current, current))
+}
+
+func TestLockfileConfig(t *testing.T) {
+	cfg := &config.Config{
+		Preset: "laravel",
+		Scaffold: config.ScaffoldConfig{
+			PreFlight: &config.PreFlight{},
+			Strict:    true,
+		},
+	}
+	stepConfigs := []config.StepConfig{
+		{Name: "php.composer", Args: []string{"install"}},
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "php.laravel", Args: []string{"migrate"}},
+		{Name: "node.npm", Args: []string{"run", "build"}},
+	}
+
+	filtered := lockfileConfig(stepConfigs, []string{"package-lock.json"}, cfg)
+
+	assert.True(t, filtered.Scaffold.Override)
+	assert.True(t, filtered.Scaffold.Strict)
+	assert.Nil(t, filtered.Scaffold.PreFlight)
+	assert.Equal(t, []config.StepConfig{stepConfigs[1]}, filtered.Scaffold.Steps, "npm run build is not an install")
+	assert.Equal(t, "laravel", filtered.Preset)
+	assert.NotNil(t, cfg.Scaffold.PreFlight, "the project config is left untouched")
+}
+
+func TestDaemonTick(t *testing.T) {
+	worktreePath, barePath := createTestWorktree(t)
+	projectPath := filepath.Dir(barePath)
+	writeDaemonConfig(t, projectPath, "    - name: bash.run\n      command: touch scaffolded\n")
+
+	require.NoError(t, config.SetScaffoldPending(worktreePath, true))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "composer.lock"), []byte("{}"), 0644))
+
+	pc := &ProjectContext{BarePath: barePath, ProjectPath: projectPath, DefaultBranch: "main"}
+
+	watcher := &daemonWatcher{pc: pc}
+	watcher.tick()
+
+	assert.FileExists(t, filepath.Join(worktreePath, "scaffolded"))
+
+	state, err := config.ReadLocalState(worktreePath)
+	require.NoError(t, err)
+	assert.False(t, state.ScaffoldPending)
+	assert.Contains(t, state.LockfileHashes, "composer.lock")
+
+	entries, err := history.Read(projectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "daemon", entries[0].Command)
+	assert.Equal(t, []string{"daemon", "scaffold", "main"}, entries[0].Args)
+	assert.Equal(t, history.ResultOK, entries[0].Result)
+
+	// Nothing is queued or changed, so a second pass does no work.
+	require.NoError(t, os.Remove(filepath.Join(worktreePath, "scaffolded")))
+	watcher.tick()
+	assert.NoFileExists(t, filepath.Join(worktreePath, "scaffolded"))
+
+	t.Run("reads arbor.yaml again on each pass", func(t *testing.T) {
+		writeDaemonConfig(t, projectPath, "    - name: bash.run\n      command: touch reloaded\n")
+		require.NoError(t, config.SetScaffoldPending(worktreePath, true))
+
+		watcher.tick()
+
+		assert.FileExists(t, filepath.Join(worktreePath, "reloaded"))
+	})
+}
+
+func TestDaemonTick_FailedInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stubs composer with a shell script")
+	}
+	worktreePath, barePath := createTestWorktree(t)
+	projectPath := filepath.Dir(barePath)
+	writeDaemonConfig(t, projectPath, "    - name: php.composer\n      args: [install]\n")
+
+	// A composer stub that counts its runs and fails.
+	binDir := t.TempDir()
+	runs := filepath.Join(binDir, "runs")
+	stub := filepath.Join(binDir, "composer")
+	require.NoError(t, os.WriteFile(stub, []byte("#!/bin/sh\necho run >> "+runs+"\nexit 1\n"), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	runCount := func() int {
+		data, _ := os.ReadFile(runs)
+		return bytes.Count(data, []byte("run"))
+	}
+
+	lockfile := filepath.Join(worktreePath, "composer.lock")
+	require.NoError(t, os.WriteFile(lockfile, []byte("{}"), 0644))
+	require.NoError(t, config.SetLockfileHashes(worktreePath, map[string]string{"composer.lock": "old"}))
+
+	watcher := &daemonWatcher{pc: &ProjectContext{BarePath: barePath, ProjectPath: projectPath, DefaultBranch: "main"}}
+	watcher.tick()
+
+	assert.Equal(t, 1, runCount())
+	state, err := config.ReadLocalState(worktreePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"composer.lock": "old"}, state.LockfileHashes, "the install stays pending")
+
+	entries, err := history.Read(projectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, history.ResultError, entries[0].Result)
+
+	// The failed install is not retried while nothing it depends on changes.
+	watcher.tick()
+	assert.Equal(t, 1, runCount())
+
+	// A changed lockfile retries it, and a successful install records it.
+	require.NoError(t, os.WriteFile(stub, []byte("#!/bin/sh\necho run >> "+runs+"\n"), 0755))
+	require.NoError(t, os.WriteFile(lockfile, []byte(`{"packages":[]}`), 0644))
+	watcher.tick()
+
+	assert.Equal(t, 2, runCount())
+	state, err = config.ReadLocalState(worktreePath)
+	require.NoError(t, err)
+	assert.Equal(t, lockfileHashes(worktreePath), state.LockfileHashes)
+}
+
+// writeDaemonConfig writes a project arbor.yaml whose scaffold runs only
+// the given steps.
+func writeDaemonConfig(t *testing.T, projectPath, steps string) {
+	t.Helper()
+	content := "default_branch: main\nscaffold:\n  override: true\n  steps:\n" + steps
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "arbor.yaml"), []byte(content), 0644))
+}
@@ -2,15 +2,18 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/presets"
 	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
@@ -20,13 +23,23 @@ import (
 
 var destroyCmd = &cobra.Command{
 	Use:   "destroy [PROJECT_PATH]",
-	Short: "Completely destroy an arbor project",
+	Short: i18n.T("cmd.destroy.short"),
 	Long: `Destroys an arbor project by:
   1. Finding all worktrees
-  2. Running cleanup for each (features first, then main)
+  2. Running the cleanup steps of each (db.destroy, herd unlink, ...):
+     branches stacked on others first, then the other features, then
+     the default branch
   3. Removing all worktrees and branches
   4. Deleting the project folder
 
+Nothing is removed until every worktree's cleanup has run. When any
+cleanup fails, destroy stops before removing anything, so it can be run
+again once the cause is fixed; --ignore-cleanup-errors destroys the
+project anyway.
+
+--dry-run prints the plan: the cleanup steps of each worktree, in order,
+and the databases and Herd links they would remove.
+
 This operation cannot be undone.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,6 +47,7 @@ This operation cannot be undone.`,
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
 		force := mustGetBool(cmd, "force")
+		ignoreCleanupErrors := mustGetBool(cmd, "ignore-cleanup-errors")
 
 		var projectPath string
 		if len(args) > 0 {
@@ -63,7 +77,8 @@ This operation cannot be undone.`,
 			return fmt.Errorf("cannot destroy project from within it; cd out first")
 		}
 
-		cfg, err := config.LoadProject(absProjectPath)
+		configs := config.NewStore()
+		cfg, err := configs.Project(absProjectPath)
 		if err != nil {
 			return fmt.Errorf("not an arbor project: %w", err)
 		}
@@ -77,7 +92,13 @@ This operation cannot be undone.`,
 		if err != nil {
 			return fmt.Errorf("listing worktrees: %w", err)
 		}
-		worktrees = sortWorktreesForDestroy(worktrees, cfg.DefaultBranch)
+		// Without recorded parents, stacks are not known and only the
+		// default branch is ordered.
+		parents, err := git.BranchParents(barePath)
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("Reading branch parents: %v; stacked branches are not cleaned up before their parents", err))
+		}
+		worktrees = sortWorktreesForDestroy(worktrees, cfg.DefaultBranch, parents)
 
 		projectName := cfg.SiteName
 		if projectName == "" {
@@ -95,73 +116,92 @@ This operation cannot be undone.`,
 			}
 		}
 
-		if dryRun {
-			ui.PrintInfo(fmt.Sprintf("Would destroy project %q with %d worktrees:", projectName, len(worktrees)))
-			for _, wt := range worktrees {
-				ui.PrintInfo(fmt.Sprintf("  - %s", wt.Branch))
-			}
-			return nil
-		}
-
-		preset := cfg.Preset
 		presetManager := presets.NewManager()
 
 		// Create explicit step registry with default steps
 		stepRegistry := steps.NewRegistry()
 		stepRegistry.RegisterDefaults()
 		scaffoldManager := scaffold.NewScaffoldManagerWithRegistry(stepRegistry)
+		scaffoldManager.SetConfigStore(configs)
 		presets.RegisterAllWithScaffold(scaffoldManager)
 
-		allCleanupFailed := true
-		repoName := filepath.Base(absProjectPath)
 		promptMode := types.PromptMode{
 			Interactive:   ui.IsInteractive(),
 			NoInteractive: false,
 			Force:         force,
 			CI:            os.Getenv("CI") != "",
+			Trust:         mustGetBool(cmd, "trust"),
+		}
+		cleanupOptions := func(wt git.Worktree) scaffold.CleanupOptions {
+			preset := cfg.Preset
+			if preset == "" {
+				preset = presetManager.Detect(wt.Path)
+			}
+			siteName := filepath.Base(wt.Path)
+			if wt.Branch == cfg.DefaultBranch && cfg.SiteName != "" {
+				siteName = cfg.SiteName
+			}
+			return scaffold.CleanupOptions{
+				RepoName:   filepath.Base(absProjectPath),
+				SiteName:   siteName,
+				Preset:     preset,
+				BarePath:   barePath,
+				PromptMode: promptMode,
+				Verbose:    verbose,
+				Quiet:      quiet,
+				Sandbox:    sandboxSteps,
+			}
 		}
-		for _, wt := range worktrees {
-			ui.PrintStep("Removing worktree: " + wt.Branch)
 
-			wtPreset := preset
-			if wtPreset == "" {
-				wtPreset = presetManager.Detect(wt.Path)
+		if dryRun {
+			plans := make([]destroyPlan, len(worktrees))
+			for i, wt := range worktrees {
+				plans[i] = planWorktreeDestroy(scaffoldManager, cfg, wt, cleanupOptions(wt))
 			}
+			printDestroyPlan(os.Stdout, projectName, absProjectPath, plans)
+			return nil
+		}
 
-			if wtPreset != "" {
-				siteName := filepath.Base(wt.Path)
-				if wt.Branch == cfg.DefaultBranch && cfg.SiteName != "" {
-					siteName = cfg.SiteName
-				}
-				if err := scaffoldManager.RunCleanup(wt.Path, wt.Branch, repoName, siteName, wtPreset, cfg, barePath, promptMode, false, verbose, quiet); err != nil {
-					ui.PrintWarning(fmt.Sprintf("Cleanup failed for %s: %v", wt.Branch, err))
-				} else {
-					allCleanupFailed = false
-				}
-			} else {
-				allCleanupFailed = false
+		// Phase 1: clean up every worktree, before anything is removed.
+		var failed []string
+		for _, wt := range worktrees {
+			ui.PrintStep("Cleaning up worktree: " + wt.Label())
+			err := ensureConfigTrusted(absProjectPath, cfg, promptMode)
+			if err == nil {
+				_, err = scaffoldManager.CleanupWorktree(wt.Path, scaffoldBranch(wt), cfg, cleanupOptions(wt))
+			}
+			if err != nil {
+				ui.PrintWarning(fmt.Sprintf("Cleanup failed for %s: %v", wt.Label(), err))
+				failed = append(failed, wt.Label())
 			}
+		}
+		if len(failed) > 0 {
+			if !ignoreCleanupErrors {
+				return fmt.Errorf("cleanup failed for %s; nothing was removed - fix the cause and run 'arbor destroy' again, or pass --ignore-cleanup-errors to destroy the project anyway", strings.Join(failed, ", "))
+			}
+			ui.PrintWarning(fmt.Sprintf("Destroying anyway (--ignore-cleanup-errors): resources of %s may be left behind", strings.Join(failed, ", ")))
+		}
 
+		// Phase 2: remove the worktrees and their branches.
+		for _, wt := range worktrees {
 			if err := git.RemoveWorktree(wt.Path, true); err != nil {
-				ui.PrintWarning(fmt.Sprintf("Failed to remove worktree %s: %v", wt.Branch, err))
+				ui.PrintWarning(fmt.Sprintf("Failed to remove worktree %s: %v", wt.Label(), err))
 			}
 
-			if err := git.DeleteBranch(barePath, wt.Branch, true); err != nil {
-				ui.PrintWarning(fmt.Sprintf("Failed to delete branch %s: %v", wt.Branch, err))
+			if !wt.Detached {
+				if err := git.DeleteBranch(barePath, wt.Branch, true); err != nil {
+					ui.PrintWarning(fmt.Sprintf("Failed to delete branch %s: %v", wt.Branch, err))
+				}
 			}
 
-			ui.PrintSuccess(fmt.Sprintf("Removed %s", wt.Branch))
-		}
-
-		if allCleanupFailed && len(worktrees) > 0 {
-			ui.PrintWarning("All cleanup steps failed. This may indicate a serious issue. Aborting.")
-			return fmt.Errorf("all cleanup operations failed")
+			ui.PrintSuccess(fmt.Sprintf("Removed %s", wt.Label()))
 		}
 
 		if err := git.PruneWorktrees(barePath); err != nil {
 			ui.PrintWarning(fmt.Sprintf("Failed to prune worktrees: %v", err))
 		}
 
+		// Phase 3: delete the project folder.
 		ui.PrintStep("Deleting project folder...")
 		if err := os.RemoveAll(absProjectPath); err != nil {
 			return fmt.Errorf("deleting project folder: %w", err)
@@ -172,13 +212,93 @@ This operation cannot be undone.`,
 	},
 }
 
-func sortWorktreesForDestroy(worktrees []git.Worktree, defaultBranch string) []git.Worktree {
+// destroyPlan is what destroying a project does to one of its worktrees:
+// the cleanup steps it runs and the resources they would remove.
+type destroyPlan struct {
+	Worktree  git.Worktree
+	Steps     []string
+	Resources []types.Resource
+	Err       error
+}
+
+// planWorktreeDestroy lists the cleanup steps destroy would run for wt and
+// the resources they would remove, without running them.
+func planWorktreeDestroy(m *scaffold.ScaffoldManager, cfg *config.Config, wt git.Worktree, opts scaffold.CleanupOptions) destroyPlan {
+	plan := destroyPlan{Worktree: wt}
+	cleanupSteps, err := m.GetCleanupSteps(cfg, wt.Path, scaffoldBranch(wt))
+	if err != nil {
+		plan.Err = err
+		return plan
+	}
+	for _, step := range cleanupSteps {
+		plan.Steps = append(plan.Steps, step.Name())
+	}
+	plan.Resources, plan.Err = m.PlanWorktreeCleanup(wt.Path, scaffoldBranch(wt), cfg, opts)
+	return plan
+}
+
+// printDestroyPlan prints what destroying the project would do, in order.
+func printDestroyPlan(w io.Writer, projectName, projectPath string, plans []destroyPlan) {
+	fmt.Fprintf(w, "Would destroy project %q with %d worktrees.\n\n", projectName, len(plans))
+
+	rows := make([][]string, len(plans))
+	for i, plan := range plans {
+		cleanup := strings.Join(plan.Steps, ", ")
+		if cleanup == "" {
+			cleanup = "-"
+		}
+		var removes []string
+		for _, resource := range plan.Resources {
+			switch resource.Kind {
+			case types.ResourceDatabase:
+				removes = append(removes, "database "+resource.Name)
+			case types.ResourceHerdLink:
+				removes = append(removes, "Herd link "+resource.Name)
+			case types.ResourceSchedule:
+				removes = append(removes, "schedule runner "+resource.Name)
+			default:
+				removes = append(removes, resource.Kind+" "+resource.Name)
+			}
+		}
+		if len(removes) == 0 {
+			removes = append(removes, "-")
+		}
+		rows[i] = []string{strconv.Itoa(i + 1), filepath.Base(plan.Worktree.Path), plan.Worktree.Label(), cleanup, strings.Join(removes, "\n")}
+	}
+	fmt.Fprintln(w, "1. Clean up each worktree, in this order:")
+	fmt.Fprintln(w, ui.RenderTable([]string{"#", "Worktree", "Branch", "Cleanup steps", "Removes"}, rows))
+	for _, plan := range plans {
+		if plan.Err != nil {
+			fmt.Fprintf(w, "Could not plan the cleanup of %s: %v\n", plan.Worktree.Label(), plan.Err)
+		}
+	}
+	fmt.Fprintf(w, "2. Remove the %d worktrees and their branches\n", len(plans))
+	fmt.Fprintf(w, "3. Delete %s\n", projectPath)
+}
+
+// sortWorktreesForDestroy orders worktrees for cleanup: features before
+// the default branch, branches stacked on others (per parents) before the
+// branches they are stacked on, then by name.
+func sortWorktreesForDestroy(worktrees []git.Worktree, defaultBranch string, parents map[string]string) []git.Worktree {
+	depth := func(branch string) int {
+		d := 0
+		seen := map[string]bool{branch: true}
+		for parent := parents[branch]; parent != "" && !seen[parent]; parent = parents[parent] {
+			seen[parent] = true
+			d++
+		}
+		return d
+	}
+
 	sort.SliceStable(worktrees, func(i, j int) bool {
 		iIsMain := worktrees[i].Branch == defaultBranch
 		jIsMain := worktrees[j].Branch == defaultBranch
 		if iIsMain != jIsMain {
 			return !iIsMain
 		}
+		if di, dj := depth(worktrees[i].Branch), depth(worktrees[j].Branch); di != dj {
+			return di > dj
+		}
 		return worktrees[i].Branch < worktrees[j].Branch
 	})
 	return worktrees
@@ -187,4 +307,5 @@ func sortWorktreesForDestroy(worktrees []git.Worktree, defaultBranch string) []g
 func init() {
 	rootCmd.AddCommand(destroyCmd)
 	destroyCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	destroyCmd.Flags().Bool("ignore-cleanup-errors", false, "Destroy the project even when a worktree's cleanup fails")
 }
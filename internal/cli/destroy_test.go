@@ -72,7 +72,7 @@ func TestSortWorktreesForDestroy(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := sortWorktreesForDestroy(tt.worktrees, tt.defaultBranch)
+			result := sortWorktreesForDestroy(tt.worktrees, tt.defaultBranch, nil)
 			if len(result) != len(tt.expectedOrder) {
 				t.Errorf("got %d worktrees, want %d", len(result), len(tt.expectedOrder))
 				return
@@ -100,8 +100,8 @@ func TestSortWorktreesForDestroy_Stability(t *testing.T) {
 	}
 
 	defaultBranch := "main"
-	result1 := sortWorktreesForDestroy(worktrees, defaultBranch)
-	result2 := sortWorktreesForDestroy(worktrees, defaultBranch)
+	result1 := sortWorktreesForDestroy(worktrees, defaultBranch, nil)
+	result2 := sortWorktreesForDestroy(worktrees, defaultBranch, nil)
 
 	if len(result1) != len(result2) {
 		t.Error("multiple calls produced different number of worktrees")
@@ -113,3 +113,39 @@ func TestSortWorktreesForDestroy_Stability(t *testing.T) {
 		}
 	}
 }
+
+func TestSortWorktreesForDestroy_Stacks(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Branch: "main"},
+		{Branch: "api"},
+		{Branch: "api-tests"},
+		{Branch: "api-docs"},
+		{Branch: "billing"},
+	}
+	parents := map[string]string{
+		"api":       "main",
+		"api-tests": "api",
+		"api-docs":  "api-tests",
+		"billing":   "main",
+	}
+
+	result := sortWorktreesForDestroy(worktrees, "main", parents)
+
+	expected := []string{"api-docs", "api-tests", "api", "billing", "main"}
+	for i, wt := range result {
+		if wt.Branch != expected[i] {
+			t.Errorf("position %d: expected %s, got %s", i, expected[i], wt.Branch)
+		}
+	}
+}
+
+func TestSortWorktreesForDestroy_ParentCycle(t *testing.T) {
+	worktrees := []git.Worktree{{Branch: "a"}, {Branch: "b"}, {Branch: "main"}}
+	parents := map[string]string{"a": "b", "b": "a"}
+
+	result := sortWorktreesForDestroy(worktrees, "main", parents)
+
+	if result[2].Branch != "main" {
+		t.Errorf("expected main last, got %s", result[2].Branch)
+	}
+}
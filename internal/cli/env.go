@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// localStateFile is the name env diff shows for .arbor.local.
+const localStateFile = ".arbor.local"
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: i18n.T("cmd.env.short"),
+	Long:  `Commands for the env files and local state of worktrees.`,
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff WORKTREE [OTHER]",
+	Short: i18n.T("cmd.env.diff.short"),
+	Long: `Shows how the env files and .arbor.local state of two worktrees differ, to
+find out why one behaves differently from the other. With one worktree, it
+is compared with the current worktree.
+
+Every .env and .env.* file in either worktree is compared, except
+.env.example; --file compares only the files given. Values of
+secret-looking keys (passwords, secrets, tokens, keys) are masked unless
+--show-secrets is set; a changed secret is still reported.
+
+Arguments:
+  WORKTREE, OTHER  Worktree folder name, branch or path relative to the
+                   project root, or part of one when it matches a single
+                   worktree`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+
+		left, err := matchWorktree(worktrees, pc.ProjectPath, args[0])
+		if err != nil {
+			return err
+		}
+		var right *git.Worktree
+		if len(args) > 1 {
+			if right, err = matchWorktree(worktrees, pc.ProjectPath, args[1]); err != nil {
+				return err
+			}
+		} else {
+			for i, wt := range worktrees {
+				if wt.IsCurrent {
+					right = &worktrees[i]
+				}
+			}
+			if right == nil {
+				return fmt.Errorf("run from a worktree or name two worktrees to compare")
+			}
+		}
+
+		files, _ := cmd.Flags().GetStringArray("file")
+		diffs, err := diffWorktreeEnvs(left.Path, right.Path, files)
+		if err != nil {
+			return err
+		}
+		if !mustGetBool(cmd, "show-secrets") {
+			maskEnvDiffs(diffs)
+		}
+
+		if mustGetBool(cmd, "json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(diffs)
+		}
+		printEnvDiffs(os.Stdout, filepath.Base(left.Path), filepath.Base(right.Path), diffs)
+		return nil
+	},
+}
+
+// Statuses of an envChange.
+const (
+	envAdded   = "added"
+	envRemoved = "removed"
+	envChanged = "changed"
+)
+
+// envFileDiff is how one file differs between two worktrees. A file missing
+// from one side has all its keys added or removed.
+type envFileDiff struct {
+	File         string      `json:"file"`
+	MissingLeft  bool        `json:"missingLeft,omitempty"`
+	MissingRight bool        `json:"missingRight,omitempty"`
+	Changes      []envChange `json:"changes"`
+}
+
+// envChange is one key that differs: only on the right (added), only on
+// the left (removed), or with different values.
+type envChange struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	Left   string `json:"left,omitempty"`
+	Right  string `json:"right,omitempty"`
+}
+
+// diffWorktreeEnvs compares the env files and .arbor.local of two
+// worktrees. Files that are the same on both sides are left out.
+func diffWorktreeEnvs(leftPath, rightPath string, files []string) ([]envFileDiff, error) {
+	if len(files) == 0 {
+		files = envFileNames(leftPath, rightPath)
+	}
+
+	var diffs []envFileDiff
+	for _, file := range files {
+		diff := envFileDiff{
+			File:         file,
+			MissingLeft:  !fileExists(filepath.Join(leftPath, file)),
+			MissingRight: !fileExists(filepath.Join(rightPath, file)),
+		}
+		if diff.MissingLeft && diff.MissingRight {
+			continue
+		}
+		diff.Changes = diffValues(utils.ReadEnvFile(leftPath, file), utils.ReadEnvFile(rightPath, file))
+		if len(diff.Changes) > 0 || diff.MissingLeft || diff.MissingRight {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	leftState, err := config.LocalStateValues(leftPath)
+	if err != nil {
+		return nil, err
+	}
+	rightState, err := config.LocalStateValues(rightPath)
+	if err != nil {
+		return nil, err
+	}
+	if changes := diffValues(leftState, rightState); len(changes) > 0 {
+		diffs = append(diffs, envFileDiff{File: localStateFile, Changes: changes})
+	}
+	return diffs, nil
+}
+
+// envFileNames returns the .env and .env.* files in either worktree, sorted,
+// leaving out .env.example, which is committed and so the same in both.
+func envFileNames(paths ...string) []string {
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		entries, _ := os.ReadDir(path)
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || name == ".env.example" {
+				continue
+			}
+			if name == ".env" || strings.HasPrefix(name, ".env.") {
+				seen[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// diffValues returns the keys whose values differ between left and right,
+// sorted by key.
+func diffValues(left, right map[string]string) []envChange {
+	var changes []envChange
+	for key, l := range left {
+		r, ok := right[key]
+		switch {
+		case !ok:
+			changes = append(changes, envChange{Key: key, Status: envRemoved, Left: l})
+		case l != r:
+			changes = append(changes, envChange{Key: key, Status: envChanged, Left: l, Right: r})
+		}
+	}
+	for key, r := range right {
+		if _, ok := left[key]; !ok {
+			changes = append(changes, envChange{Key: key, Status: envAdded, Right: r})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// maskEnvDiffs hides the values of secret-looking keys. Empty values stay
+// visible, since a secret that is set on one side only is worth seeing.
+func maskEnvDiffs(diffs []envFileDiff) {
+	for _, diff := range diffs {
+		for i, change := range diff.Changes {
+			if !isSecretKey(change.Key) {
+				continue
+			}
+			if change.Left != "" {
+				diff.Changes[i].Left = maskedValue
+			}
+			if change.Right != "" {
+				diff.Changes[i].Right = maskedValue
+			}
+		}
+	}
+}
+
+func printEnvDiffs(w io.Writer, leftName, rightName string, diffs []envFileDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintf(w, "No differences between %s and %s\n", leftName, rightName)
+		return
+	}
+
+	fmt.Fprintf(w, "- %s\n+ %s\n", leftName, rightName)
+	for _, diff := range diffs {
+		switch {
+		case diff.MissingLeft:
+			fmt.Fprintf(w, "\n%s (missing in %s)\n", diff.File, leftName)
+		case diff.MissingRight:
+			fmt.Fprintf(w, "\n%s (missing in %s)\n", diff.File, rightName)
+		default:
+			fmt.Fprintf(w, "\n%s\n", diff.File)
+		}
+		for _, change := range diff.Changes {
+			switch change.Status {
+			case envRemoved:
+				fmt.Fprintf(w, "  - %s=%s\n", change.Key, change.Left)
+			case envAdded:
+				fmt.Fprintf(w, "  + %s=%s\n", change.Key, change.Right)
+			default:
+				fmt.Fprintf(w, "  - %s=%s\n  + %s=%s\n", change.Key, change.Left, change.Key, change.Right)
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envDiffCmd)
+
+	envDiffCmd.Flags().StringArray("file", nil, "Compare only this env file (repeatable)")
+	envDiffCmd.Flags().Bool("json", false, "Output the differences as JSON")
+	envDiffCmd.Flags().Bool("show-secrets", false, "Show values of secret-looking keys instead of masking them")
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestDiffWorktreeEnvs(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write(left, ".env", "APP_URL=http://main.test\nDB_PASSWORD=one\nQUEUE_CONNECTION=sync\nSAME=1\n")
+	write(right, ".env", "APP_URL=http://feature.test\nDB_PASSWORD=two\nMAIL_MAILER=log\nSAME=1\n")
+	write(left, ".env.example", "APP_URL=\n")
+	write(right, ".env.example", "APP_URL=http://example.test\n")
+	write(right, ".env.testing", "DB_DATABASE=testing\n")
+	require.NoError(t, config.WriteLocalState(left, config.LocalState{DbSuffix: "swift_runner"}))
+	require.NoError(t, config.WriteLocalState(right, config.LocalState{DbSuffix: "calm_river"}))
+
+	diffs, err := diffWorktreeEnvs(left, right, nil)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3, ".env.example is not compared")
+
+	assert.Equal(t, ".env", diffs[0].File)
+	assert.Equal(t, []envChange{
+		{Key: "APP_URL", Status: envChanged, Left: "http://main.test", Right: "http://feature.test"},
+		{Key: "DB_PASSWORD", Status: envChanged, Left: "one", Right: "two"},
+		{Key: "MAIL_MAILER", Status: envAdded, Right: "log"},
+		{Key: "QUEUE_CONNECTION", Status: envRemoved, Left: "sync"},
+	}, diffs[0].Changes)
+
+	assert.Equal(t, ".env.testing", diffs[1].File)
+	assert.True(t, diffs[1].MissingLeft)
+
+	assert.Equal(t, ".arbor.local", diffs[2].File)
+	assert.Equal(t, []envChange{{Key: "db_suffix", Status: envChanged, Left: "swift_runner", Right: "calm_river"}}, diffs[2].Changes)
+
+	maskEnvDiffs(diffs)
+	assert.Equal(t, maskedValue, diffs[0].Changes[1].Left)
+	assert.Equal(t, maskedValue, diffs[0].Changes[1].Right)
+	assert.Equal(t, "http://main.test", diffs[0].Changes[0].Left)
+
+	var out bytes.Buffer
+	printEnvDiffs(&out, "main", "feature", diffs)
+	assert.Contains(t, out.String(), "  - APP_URL=http://main.test\n  + APP_URL=http://feature.test\n")
+	assert.Contains(t, out.String(), ".env.testing (missing in main)")
+	assert.NotContains(t, out.String(), "one")
+
+	t.Run("only the files given", func(t *testing.T) {
+		diffs, err := diffWorktreeEnvs(left, right, []string{".env.example"})
+		require.NoError(t, err)
+		assert.Equal(t, ".env.example", diffs[0].File)
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		diffs, err := diffWorktreeEnvs(left, left, nil)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+
+		var out bytes.Buffer
+		printEnvDiffs(&out, "main", "main", diffs)
+		assert.Equal(t, "No differences between main and main\n", out.String())
+	})
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// Reasons a worktree is reported by `arbor gc`.
+const (
+	orphanMissingDir   = "directory missing"
+	orphanUnregistered = "no longer a git worktree"
+)
+
+// orphanedWorktree is a worktree removed outside arbor whose cleanup never ran.
+type orphanedWorktree struct {
+	Record config.WorktreeRecord
+	Reason string
+	// Registered is true when git still lists the worktree and needs pruning.
+	Registered bool
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: i18n.T("cmd.gc.short"),
+	Long: `Runs cleanup for worktrees that were removed without arbor.
+
+Deleting a worktree with 'git worktree remove' or 'rm -rf' skips arbor's
+cleanup steps, leaving databases and Herd links behind. gc finds:
+
+  - worktrees git still lists whose directory is missing
+  - worktrees arbor scaffolded that git no longer knows about
+
+For each one it runs the preset and config cleanup steps using the state
+recorded in .arbor/worktrees when the worktree was scaffolded, then prunes
+stale git worktree metadata.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		force := mustGetBool(cmd, "force")
+		dryRun := mustGetBool(cmd, "dry-run")
+		verbose := mustGetBool(cmd, "verbose")
+		quiet := mustGetBool(cmd, "quiet")
+
+		worktrees, err := git.ListWorktrees(pc.BarePath)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+
+		records, err := config.ReadWorktreeRecords(pc.ProjectPath)
+		if err != nil {
+			return err
+		}
+
+		orphans := findOrphanedWorktrees(worktrees, records)
+		if len(orphans) == 0 {
+			ui.PrintInfo("Nothing to clean up.")
+			return nil
+		}
+
+		for _, orphan := range orphans {
+			ui.PrintInfo(fmt.Sprintf("%s (%s): %s", orphan.Record.Branch, orphan.Record.Path, orphan.Reason))
+		}
+
+		if !dryRun && !force {
+			if !ui.IsInteractive() {
+				return fmt.Errorf("gc requires confirmation (use --force to skip)")
+			}
+			confirmed, err := ui.Confirm(fmt.Sprintf("Run cleanup for %d removed worktree(s)?", len(orphans)))
+			if err != nil {
+				return fmt.Errorf("confirmation: %w", err)
+			}
+			if !confirmed {
+				ui.PrintInfo("Cancelled.")
+				return nil
+			}
+		}
+
+		promptMode := types.PromptMode{
+			Interactive:   ui.IsInteractive(),
+			NoInteractive: false,
+			Force:         force,
+			CI:            os.Getenv("CI") != "",
+			Trust:         mustGetBool(cmd, "trust"),
+		}
+
+		prune := false
+		for _, orphan := range orphans {
+			var resources []types.Resource
+			var err error
+			if !dryRun {
+				err = ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode)
+			}
+			if err == nil {
+				resources, err = pc.ScaffoldManager().RunOrphanCleanup(orphan.Record, pc.Config, pc.BarePath, promptMode, dryRun, verbose, quiet)
+			}
+
+			entry := pruneEntry{Branch: orphan.Record.Branch, Path: orphan.Record.Path}
+			entry.addResources(resources)
+			if dryRun {
+				ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would run cleanup for %s", orphan.Record.Branch))
+				printPruneResources("[DRY RUN] Would", entry)
+				if err != nil {
+					ui.PrintWarning(fmt.Sprintf("Could not plan cleanup for %s: %v", orphan.Record.Branch, err))
+				}
+				continue
+			}
+
+			printPruneResources("", entry)
+			if err != nil {
+				// Keep the record so the next gc can retry the cleanup.
+				ui.PrintErrorWithHint(i18n.T("hint.cleanup_failed"), err.Error())
+				continue
+			}
+
+			forgetWorktree(pc, orphan.Record.Path)
+			prune = prune || orphan.Registered
+			ui.PrintSuccess(fmt.Sprintf("Cleaned up %s", orphan.Record.Branch))
+		}
+
+		if prune {
+			if err := git.PruneWorktrees(pc.BarePath); err != nil {
+				return err
+			}
+		}
+
+		ui.PrintDone("Done.")
+		return nil
+	},
+}
+
+// findOrphanedWorktrees compares git's worktree list with arbor's worktree
+// records and returns the worktrees whose directory has gone.
+func findOrphanedWorktrees(worktrees []git.Worktree, records []config.WorktreeRecord) []orphanedWorktree {
+	recordsByPath := make(map[string]config.WorktreeRecord, len(records))
+	for _, r := range records {
+		recordsByPath[filepath.Clean(r.Path)] = r
+	}
+
+	var orphans []orphanedWorktree
+	registered := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		path := filepath.Clean(wt.Path)
+		registered[path] = true
+		if pathExists(path) {
+			continue
+		}
+		record, ok := recordsByPath[path]
+		if !ok {
+			record = config.WorktreeRecord{Path: wt.Path, Branch: wt.Branch}
+		}
+		orphans = append(orphans, orphanedWorktree{Record: record, Reason: orphanMissingDir, Registered: true})
+	}
+
+	for _, r := range records {
+		path := filepath.Clean(r.Path)
+		// A directory that still exists but is not a worktree is left alone:
+		// it may have been replaced by something the user wants to keep.
+		if registered[path] || pathExists(path) {
+			continue
+		}
+		orphans = append(orphans, orphanedWorktree{Record: r, Reason: orphanUnregistered})
+	}
+
+	return orphans
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// forgetWorktree removes the project-level record for a worktree once its
+// cleanup has run.
+func forgetWorktree(pc *ProjectContext, worktreePath string) {
+	if err := config.RemoveWorktreeRecord(pc.ProjectPath, worktreePath); err != nil {
+		ui.PrintWarning(err.Error())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().BoolP("force", "f", false, "Skip confirmation and cleanup prompts")
+}
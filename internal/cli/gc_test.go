@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestFindOrphanedWorktrees(t *testing.T) {
+	projectPath := t.TempDir()
+	live := filepath.Join(projectPath, "main")
+	missing := filepath.Join(projectPath, "feature-missing")
+	unregistered := filepath.Join(projectPath, "feature-gone")
+	replaced := t.TempDir()
+
+	worktrees := []git.Worktree{
+		{Path: live, Branch: "main"},
+		{Path: missing, Branch: "feature/missing"},
+	}
+	records := []config.WorktreeRecord{
+		{Path: live, Branch: "main"},
+		{Path: missing, Branch: "feature/missing", DbSuffix: "swift_runner"},
+		{Path: unregistered, Branch: "feature/gone", DbSuffix: "calm_river"},
+		{Path: replaced, Branch: "feature/replaced"},
+	}
+	require.NoError(t, os.MkdirAll(live, 0755))
+
+	orphans := findOrphanedWorktrees(worktrees, records)
+
+	assert.Equal(t, []orphanedWorktree{
+		{Record: records[1], Reason: orphanMissingDir, Registered: true},
+		{Record: records[2], Reason: orphanUnregistered},
+	}, orphans)
+}
+
+func TestFindOrphanedWorktrees_RegisteredWithoutRecord(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "feature")
+
+	orphans := findOrphanedWorktrees([]git.Worktree{{Path: missing, Branch: "feature"}}, nil)
+
+	assert.Equal(t, []orphanedWorktree{
+		{Record: config.WorktreeRecord{Path: missing, Branch: "feature"}, Reason: orphanMissingDir, Registered: true},
+	}, orphans)
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/ghactions"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// writeGitHubReport adds a scaffold's step table to the job summary and
+// sets the worktree's outputs: worktree, branch, db_suffix, and db_name
+// and site_url from the primary env file's DB_DATABASE and APP_URL.
+// Failures are warnings; they do not fail the scaffold.
+func writeGitHubReport(recorder *ghactions.Recorder, cfg *config.Config, wt git.Worktree, dryRun bool) {
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		title := fmt.Sprintf("arbor scaffold: %s", wt.Label())
+		if dryRun {
+			title += " (dry run)"
+		}
+		if err := ghactions.AppendToFile(path, func(w io.Writer) error { return recorder.WriteSummary(w, title) }); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Could not write job summary: %v", err))
+		}
+	}
+
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return
+	}
+	outputs := map[string]string{
+		"worktree": wt.Path,
+		"branch":   scaffoldBranch(wt),
+	}
+	if state, err := config.ReadLocalState(wt.Path); err == nil && state.DbSuffix != "" {
+		outputs["db_suffix"] = state.DbSuffix
+	}
+	env := utils.ReadEnvFile(wt.Path, cfg.PrimaryEnvFile())
+	if env["DB_DATABASE"] != "" {
+		outputs["db_name"] = env["DB_DATABASE"]
+	}
+	if env["APP_URL"] != "" {
+		outputs["site_url"] = env["APP_URL"]
+	}
+	if err := ghactions.AppendToFile(path, func(w io.Writer) error { return ghactions.WriteOutputs(w, outputs) }); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not set step outputs: %v", err))
+	}
+}
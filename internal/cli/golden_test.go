@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/")
+
+// assertGolden compares got with testdata/<name>.golden. Run
+// `go test ./internal/cli -update` to rewrite the golden files after an
+// intentional output change.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "reading golden file (run with -update to create it)")
+	assert.Equal(t, string(want), got)
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/history"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// auditedCommands change project state and are recorded in the project's
+// history log. init and destroy are not recorded: the project does not
+// exist before the first or after the second.
+var auditedCommands = map[string]bool{
+	"copy-state":     true,
+	"eject":          true,
+	"gc":             true,
+	"mv":             true,
+	"prune":          true,
+	"pull-config":    true,
+	"push":           true,
+	"recycle":        true,
+	"remove":         true,
+	"rename":         true,
+	"repair":         true,
+	"restore-config": true,
+	"scaffold":       true,
+	"sync":           true,
+	"undo":           true,
+	"work":           true,
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: i18n.T("cmd.history.short"),
+	Long: `Shows who ran which state-changing arbor commands in this project and when.
+
+Every run of work, scaffold, sync, push, rename, mv, recycle, remove, prune,
+undo, gc, repair, copy-state, pull-config, restore-config and preset eject
+is appended to .arbor/history.log with the user, arguments, duration and
+result. Dry runs are not recorded.
+
+--filter keeps the entries whose command line, user or result contains the
+given text, such as a branch name. --limit then keeps the most recent
+entries; the table says how many earlier ones it left out.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return err
+		}
+
+		entries, err := history.Read(pc.ProjectPath)
+		if err != nil {
+			return err
+		}
+		entries = filterHistory(entries, mustGetString(cmd, "filter"))
+		hidden := 0
+		if limit > 0 && len(entries) > limit {
+			hidden = len(entries) - limit
+			entries = entries[hidden:]
+		}
+
+		if mustGetBool(cmd, "json") {
+			return printHistoryJSON(os.Stdout, entries)
+		}
+
+		if len(entries) == 0 {
+			ui.PrintInfo("No history recorded yet.")
+			return nil
+		}
+		fmt.Println(ui.RenderTable([]string{"TIME", "USER", "COMMAND", "DURATION", "RESULT"}, historyRows(entries)))
+		if hidden > 0 {
+			ui.PrintInfo(fmt.Sprintf("%d earlier entries not shown - use --limit 0 to show all", hidden))
+		}
+		return nil
+	},
+}
+
+// filterHistory returns the entries whose command line, user, result or
+// error contains query, ignoring case.
+func filterHistory(entries []history.Entry, query string) []history.Entry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return entries
+	}
+	var filtered []history.Entry
+	for _, e := range entries {
+		command := strings.Join(append([]string{"arbor"}, e.Args...), " ")
+		text := strings.ToLower(strings.Join([]string{command, e.User, e.Result, e.Error}, " "))
+		if strings.Contains(text, query) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func historyRows(entries []history.Entry) [][]string {
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		result := e.Result
+		if e.Error != "" {
+			result += ": " + e.Error
+		}
+		command := strings.TrimSpace("arbor " + strings.Join(e.Args, " "))
+		duration := (time.Duration(e.DurationMs) * time.Millisecond).Round(100 * time.Millisecond)
+		rows[i] = []string{e.Time.Local().Format(time.DateTime), e.User, command, duration.String(), result}
+	}
+	return rows
+}
+
+func printHistoryJSON(w io.Writer, entries []history.Entry) error {
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// recordInvocation appends a run of an audited command to the history log
+// of the project containing dir. Failures to record never fail the command.
+func recordInvocation(cmd *cobra.Command, args []string, dir string, start time.Time, runErr error) {
+	if cmd == nil || !auditedCommands[cmd.Name()] {
+		return
+	}
+	if dryRun, err := cmd.Flags().GetBool("dry-run"); err == nil && dryRun {
+		return
+	}
+
+	barePath, err := git.FindBarePath(dir)
+	if err != nil {
+		return
+	}
+
+	entry := history.Entry{
+		Time:       start.UTC(),
+		User:       history.CurrentUser(),
+		Command:    cmd.Name(),
+		Args:       args,
+		Dir:        dir,
+		DurationMs: time.Since(start).Milliseconds(),
+		Result:     history.ResultOK,
+	}
+	switch {
+	case runErr != nil && ui.IsAbort(runErr):
+		entry.Result = history.ResultCancelled
+	case runErr != nil:
+		entry.Result = history.ResultError
+		entry.Error = runErr.Error()
+	}
+
+	if err := history.Append(filepath.Dir(barePath), entry); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not record history: %v", err))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().Int("limit", 20, "Number of most recent entries to show (0 for all)")
+	historyCmd.Flags().String("filter", "", "Only show entries whose command, user or result contains this")
+	historyCmd.Flags().Bool("json", false, "Output history as JSON")
+}
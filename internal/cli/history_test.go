@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/history"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+func historyTestCommand(name string) *cobra.Command {
+	cmd := &cobra.Command{Use: name}
+	cmd.Flags().Bool("dry-run", false, "")
+	return cmd
+}
+
+func TestRecordInvocation(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(projectPath, ".bare"), 0755))
+	worktreePath := filepath.Join(projectPath, "main")
+	require.NoError(t, os.Mkdir(worktreePath, 0755))
+
+	start := time.Now().Add(-2 * time.Second)
+	recordInvocation(historyTestCommand("work"), []string{"work", "feature/auth"}, worktreePath, start, nil)
+	recordInvocation(historyTestCommand("remove"), []string{"remove", "feature-auth"}, worktreePath, start, errors.New("worktree not found"))
+	recordInvocation(historyTestCommand("prune"), []string{"prune"}, worktreePath, start, ui.ErrUserAborted)
+	recordInvocation(historyTestCommand("list"), []string{"list"}, worktreePath, start, nil)
+
+	dryRun := historyTestCommand("scaffold")
+	require.NoError(t, dryRun.Flags().Set("dry-run", "true"))
+	recordInvocation(dryRun, []string{"scaffold", "--dry-run"}, worktreePath, start, nil)
+
+	entries, err := history.Read(projectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "read-only commands and dry runs are not recorded")
+
+	assert.Equal(t, "work", entries[0].Command)
+	assert.Equal(t, []string{"work", "feature/auth"}, entries[0].Args)
+	assert.Equal(t, worktreePath, entries[0].Dir)
+	assert.Equal(t, history.ResultOK, entries[0].Result)
+	assert.GreaterOrEqual(t, entries[0].DurationMs, int64(2000))
+	assert.NotEmpty(t, entries[0].User)
+
+	assert.Equal(t, history.ResultError, entries[1].Result)
+	assert.Equal(t, "worktree not found", entries[1].Error)
+
+	assert.Equal(t, history.ResultCancelled, entries[2].Result)
+}
+
+func TestAuditedCommands(t *testing.T) {
+	names := make(map[string]bool)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			names[sub.Name()] = true
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+
+	for name := range auditedCommands {
+		assert.True(t, names[name], "%s is not an arbor command", name)
+		assert.Contains(t, historyCmd.Long, name, "the help lists %s", name)
+	}
+}
+
+func TestRecordInvocation_OutsideProject(t *testing.T) {
+	dir := t.TempDir()
+	recordInvocation(historyTestCommand("work"), []string{"work"}, dir, time.Now(), nil)
+	assert.NoFileExists(t, history.Path(dir))
+}
+
+func TestPrintHistoryJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printHistoryJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestHistoryRows(t *testing.T) {
+	rows := historyRows([]history.Entry{
+		{Time: time.Now(), User: "taylor", Args: []string{"remove", "feature-auth"}, DurationMs: 1234, Result: history.ResultError, Error: "boom"},
+	})
+	require.Len(t, rows, 1)
+	assert.Equal(t, []string{"taylor", "arbor remove feature-auth", "1.2s", "error: boom"}, rows[0][1:])
+}
+
+func TestFilterHistory(t *testing.T) {
+	entries := []history.Entry{
+		{User: "taylor", Args: []string{"work", "feature/auth"}, Result: history.ResultOK},
+		{User: "jordan", Args: []string{"remove", "feature-billing"}, Result: history.ResultError, Error: "boom"},
+	}
+
+	assert.Len(t, filterHistory(entries, ""), 2)
+	assert.Equal(t, entries[:1], filterHistory(entries, "Feature/Auth"))
+	assert.Equal(t, entries[1:], filterHistory(entries, "jordan"))
+	assert.Equal(t, entries[1:], filterHistory(entries, "boom"))
+	assert.Equal(t, entries[:1], filterHistory(entries, "arbor work"))
+	assert.Empty(t, filterHistory(entries, "sync"))
+}
@@ -0,0 +1,451 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// infoEnvKeys are the env values 'arbor info' shows.
+var infoEnvKeys = []string{"APP_URL", "DB_CONNECTION", "DB_DATABASE"}
+
+// serviceDialTimeout bounds each service probe, so a firewalled port does
+// not stall 'arbor info'.
+const serviceDialTimeout = 300 * time.Millisecond
+
+// worktreeInfo is everything 'arbor info' reports about one worktree.
+type worktreeInfo struct {
+	Path            string `json:"path"`
+	Branch          string `json:"branch"`
+	Detached        bool   `json:"detached"`
+	Head            string `json:"head"`
+	Parent          string `json:"parent,omitempty"`
+	IsMain          bool   `json:"isMain"`
+	IsMerged        bool   `json:"isMerged"`
+	Dirty           bool   `json:"dirty"`
+	ScaffoldPending bool   `json:"scaffoldPending"`
+	// Upstream is the remote branch the branch tracks. UpstreamGone is set
+	// when it no longer exists locally, e.g. after a fetch with --prune.
+	Upstream     string `json:"upstream,omitempty"`
+	UpstreamGone bool   `json:"upstreamGone,omitempty"`
+	Ahead        int    `json:"ahead"`
+	Behind       int    `json:"behind"`
+	DbSuffix     string `json:"dbSuffix,omitempty"`
+	// Databases are the ones the cleanup steps would drop. DatabasesError
+	// is set when they could not be listed, e.g. the server is down.
+	Databases      []string               `json:"databases"`
+	DatabasesError string                 `json:"databasesError,omitempty"`
+	SiteLinks      []siteLinkJSON         `json:"siteLinks"`
+	EnvFile        string                 `json:"envFile"`
+	Env            map[string]string      `json:"env"`
+	LastScaffold   *config.ScaffoldReport `json:"lastScaffold,omitempty"`
+	Preset         *presetInfo            `json:"preset,omitempty"`
+	Services       []serviceStatus        `json:"services"`
+}
+
+// presetInfo is the preset whose steps a worktree's scaffold runs and the
+// one its last scaffold ran, when that was recorded.
+type presetInfo struct {
+	Name              string `json:"name"`
+	Version           int    `json:"version"`
+	ScaffoldedPreset  string `json:"scaffoldedPreset,omitempty"`
+	ScaffoldedVersion int    `json:"scaffoldedVersion,omitempty"`
+	// ChangedSteps counts the preset steps the last scaffold did not run;
+	// 'arbor scaffold --upgrade' runs them.
+	ChangedSteps int  `json:"changedSteps"`
+	Outdated     bool `json:"outdated"`
+}
+
+type siteLinkJSON struct {
+	Tool   string `json:"tool"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Secure bool   `json:"secure"`
+}
+
+// serviceStatus is a local server a worktree uses and whether it accepts
+// connections.
+type serviceStatus struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Running bool   `json:"running"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info [WORKTREE]",
+	Short: i18n.T("cmd.info.short"),
+	Long: `Shows everything about one worktree: its branch and upstream, how far
+it is ahead of and behind the upstream, its database suffix and the
+databases matching it, its Herd or Valet links, the APP_URL, DB_CONNECTION
+and DB_DATABASE env values, the last scaffold's step results, and whether
+its site and Vite dev server are running.
+
+Databases are listed with the cleanup steps' connection settings, so a
+database server that is down is reported rather than failing the command.
+
+Arguments:
+  WORKTREE  Worktree folder name, branch or path relative to the project
+            root, or part of one when it matches a single worktree
+            (defaults to the current worktree, or the default branch)`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := detailedWorktrees(pc, pc.CWD)
+		if err != nil {
+			return err
+		}
+
+		var arg string
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		wt, err := resolveContextWorktree(pc, worktrees, arg)
+		if err != nil {
+			return err
+		}
+
+		info := buildWorktreeInfo(pc, *wt)
+		if mustGetBool(cmd, "json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(info)
+		}
+		printWorktreeInfo(os.Stdout, info)
+		return nil
+	},
+}
+
+// buildWorktreeInfo gathers a worktree's details. Each part is best-effort:
+// what cannot be read is left empty.
+func buildWorktreeInfo(pc *ProjectContext, wt git.Worktree) *worktreeInfo {
+	info := &worktreeInfo{
+		Path:            wt.Path,
+		Branch:          wt.Branch,
+		Detached:        wt.Detached,
+		Head:            wt.Head,
+		Parent:          wt.Parent,
+		IsMain:          wt.IsMain,
+		IsMerged:        wt.IsMerged,
+		ScaffoldPending: wt.ScaffoldPending,
+		Databases:       []string{},
+		SiteLinks:       []siteLinkJSON{},
+		EnvFile:         pc.Config.PrimaryEnvFile(),
+		Env:             map[string]string{},
+		Services:        []serviceStatus{},
+	}
+	if head, err := git.HeadCommitShort(wt.Path); err == nil {
+		info.Head = head
+	}
+	info.Dirty, _ = git.IsWorktreeDirty(wt.Path)
+
+	if !wt.Detached {
+		info.Upstream, _ = git.BranchUpstream(pc.BarePath, wt.Branch)
+	}
+	if info.Upstream != "" {
+		ahead, behind, err := git.AheadBehind(pc.BarePath, "refs/heads/"+wt.Branch, "refs/remotes/"+info.Upstream)
+		if err != nil {
+			info.UpstreamGone = true
+		} else {
+			info.Ahead, info.Behind = ahead, behind
+		}
+	}
+
+	if state, err := config.ReadLocalState(wt.Path); err == nil {
+		info.DbSuffix = state.DbSuffix
+	}
+	if info.DbSuffix != "" {
+		info.Databases, info.DatabasesError = worktreeDatabases(pc, wt)
+	}
+
+	for _, link := range findSiteLinks(wt.Path) {
+		info.SiteLinks = append(info.SiteLinks, siteLinkJSON{Tool: link.Tool, Name: link.Name, URL: link.URL(), Secure: link.Secure})
+	}
+
+	env := utils.ReadEnvFile(wt.Path, info.EnvFile)
+	for _, key := range infoEnvKeys {
+		if value, ok := env[key]; ok {
+			info.Env[key] = value
+		}
+	}
+
+	if record, err := config.ReadWorktreeRecord(pc.ProjectPath, wt.Path); err == nil && record != nil {
+		info.LastScaffold = record.LastScaffold
+	}
+	if status, err := pc.ScaffoldManager().PresetStatus(pc.Config, wt.Path); err == nil && status != nil {
+		info.Preset = &presetInfo{
+			Name:              status.Name,
+			Version:           status.Version,
+			ScaffoldedPreset:  status.ScaffoldedName,
+			ScaffoldedVersion: status.ScaffoldedVersion,
+			Outdated:          status.Outdated(),
+		}
+		if status.Recorded() {
+			info.Preset.ChangedSteps = len(status.Changed)
+		}
+	}
+
+	info.Services = probeServices(wt.Path, info.SiteLinks, env["APP_URL"])
+	return info
+}
+
+// worktreeDatabases lists the databases the worktree's cleanup steps would
+// drop, i.e. those named after its db suffix.
+func worktreeDatabases(pc *ProjectContext, wt git.Worktree) ([]string, string) {
+	preset := pc.Config.Preset
+	if preset == "" {
+		preset = pc.PresetManager().Detect(wt.Path)
+	}
+
+	databases := []string{}
+	planned, err := pc.ScaffoldManager().PlanWorktreeCleanup(wt.Path, scaffoldBranch(wt), pc.Config, scaffold.CleanupOptions{
+		SiteName:   scaffoldSiteName(pc, pc.Config, wt),
+		Preset:     preset,
+		BarePath:   pc.BarePath,
+		PromptMode: types.PromptMode{NoInteractive: true},
+	})
+	for _, resource := range planned {
+		if resource.Kind == types.ResourceDatabase {
+			databases = append(databases, resource.Name)
+		}
+	}
+	if err != nil {
+		return databases, err.Error()
+	}
+	return databases, ""
+}
+
+// probeServices checks the local servers a worktree uses: its site, at the
+// first Herd or Valet link or else APP_URL, and the Vite dev server, whose
+// URL the Laravel Vite plugin writes to public/hot while it runs. Only
+// local hosts are probed.
+func probeServices(worktreePath string, links []siteLinkJSON, appURL string) []serviceStatus {
+	services := []serviceStatus{}
+
+	siteURL := appURL
+	if len(links) > 0 {
+		siteURL = links[0].URL
+	}
+	if addr, ok := localServiceAddr(siteURL); ok {
+		services = append(services, serviceStatus{Name: "site", URL: siteURL, Running: dialService(addr)})
+	}
+
+	if hot, err := os.ReadFile(filepath.Join(worktreePath, "public", "hot")); err == nil {
+		viteURL := strings.TrimSpace(string(hot))
+		if addr, ok := localServiceAddr(viteURL); ok {
+			services = append(services, serviceStatus{Name: "vite", URL: viteURL, Running: dialService(addr)})
+		}
+	}
+	return services
+}
+
+// localServiceAddr returns the host:port to probe for a URL served on this
+// machine: localhost, a loopback address or a .test or .localhost domain.
+func localServiceAddr(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	local := host == "localhost" || strings.HasSuffix(host, ".test") || strings.HasSuffix(host, ".localhost") || (ip != nil && ip.IsLoopback())
+	if !local {
+		return "", false
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		default:
+			return "", false
+		}
+	}
+	return net.JoinHostPort(host, port), true
+}
+
+func dialService(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, serviceDialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+func printWorktreeInfo(w io.Writer, info *worktreeInfo) {
+	branch := info.Branch
+	if info.Detached {
+		branch = fmt.Sprintf("(detached at %s)", info.Head)
+	} else if info.Parent != "" {
+		branch += fmt.Sprintf(" (stacked on %s)", info.Parent)
+	}
+	fmt.Fprintf(w, "Worktree:  %s\n", info.Path)
+	fmt.Fprintf(w, "Branch:    %s\n", branch)
+	fmt.Fprintf(w, "Upstream:  %s\n", formatUpstream(info))
+	fmt.Fprintf(w, "Status:    %s\n", formatInfoStatus(info))
+	if info.Preset != nil {
+		fmt.Fprintf(w, "Preset:    %s\n", formatInfoPreset(info.Preset))
+	}
+
+	fmt.Fprintln(w, "\nDatabase:")
+	if info.DbSuffix == "" {
+		fmt.Fprintln(w, "  no db suffix")
+	} else {
+		fmt.Fprintf(w, "  Suffix:    %s\n", info.DbSuffix)
+		switch {
+		case info.DatabasesError != "":
+			fmt.Fprintf(w, "  Databases: could not list (%s)\n", firstLine(info.DatabasesError))
+		case len(info.Databases) == 0:
+			fmt.Fprintln(w, "  Databases: none")
+		default:
+			fmt.Fprintf(w, "  Databases: %s\n", strings.Join(info.Databases, ", "))
+		}
+	}
+
+	fmt.Fprintln(w, "\nSite links:")
+	if len(info.SiteLinks) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, link := range info.SiteLinks {
+		fmt.Fprintf(w, "  %s  %s\n", link.Tool, link.URL)
+	}
+
+	fmt.Fprintf(w, "\nEnv (%s):\n", info.EnvFile)
+	if len(info.Env) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, key := range infoEnvKeys {
+		if value, ok := info.Env[key]; ok {
+			fmt.Fprintf(w, "  %s=%s\n", key, value)
+		}
+	}
+
+	fmt.Fprintln(w, "\nLast scaffold:")
+	printScaffoldReport(w, info.LastScaffold)
+
+	fmt.Fprintln(w, "\nServices:")
+	if len(info.Services) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, service := range info.Services {
+		state := "stopped"
+		if service.Running {
+			state = "running"
+		}
+		fmt.Fprintf(w, "  %-5s %s  %s\n", service.Name, service.URL, state)
+	}
+}
+
+func formatUpstream(info *worktreeInfo) string {
+	switch {
+	case info.Detached:
+		return "none (detached)"
+	case info.Upstream == "":
+		return "none"
+	case info.UpstreamGone:
+		return info.Upstream + " (gone)"
+	case info.Ahead == 0 && info.Behind == 0:
+		return info.Upstream + " (up to date)"
+	default:
+		return fmt.Sprintf("%s (%d ahead, %d behind)", info.Upstream, info.Ahead, info.Behind)
+	}
+}
+
+func formatInfoStatus(info *worktreeInfo) string {
+	var status []string
+	if info.IsMain {
+		status = append(status, "main")
+	}
+	if info.Dirty {
+		status = append(status, "uncommitted changes")
+	} else {
+		status = append(status, "clean")
+	}
+	if info.IsMerged {
+		status = append(status, "merged")
+	}
+	if info.ScaffoldPending {
+		status = append(status, "scaffold pending")
+	}
+	return strings.Join(status, ", ")
+}
+
+// formatInfoPreset names the preset and, when the worktree's last scaffold
+// did not run all of its steps, says how to run the rest.
+func formatInfoPreset(preset *presetInfo) string {
+	current := fmt.Sprintf("%s v%d", preset.Name, preset.Version)
+	if !preset.Outdated {
+		return current
+	}
+	scaffolded := fmt.Sprintf("v%d", preset.ScaffoldedVersion)
+	if preset.ScaffoldedPreset != preset.Name {
+		scaffolded = fmt.Sprintf("%s v%d", preset.ScaffoldedPreset, preset.ScaffoldedVersion)
+	}
+	return fmt.Sprintf("%s (scaffolded with %s: %d step(s) not run, run 'arbor scaffold --upgrade')", current, scaffolded, preset.ChangedSteps)
+}
+
+func printScaffoldReport(w io.Writer, report *config.ScaffoldReport) {
+	if report == nil {
+		fmt.Fprintln(w, "  never scaffolded")
+		return
+	}
+
+	result := "ok"
+	if report.Error != "" {
+		result = "failed: " + firstLine(report.Error)
+	}
+	fmt.Fprintf(w, "  %s, took %s, %s\n", report.Time.Local().Format("2006-01-02 15:04"), formatMs(report.DurationMs), result)
+
+	width := 0
+	for _, step := range report.Steps {
+		width = max(width, len(step.Name))
+	}
+	for _, step := range report.Steps {
+		switch step.Status {
+		case scaffold.StepSkipped:
+			fmt.Fprintf(w, "  - %-*s  skipped (%s)\n", width, step.Name, step.Reason)
+		case scaffold.StepFailed:
+			fmt.Fprintf(w, "  ✗ %-*s  %s  %s\n", width, step.Name, formatMs(step.DurationMs), firstLine(step.Error))
+		default:
+			fmt.Fprintf(w, "  ✓ %-*s  %s\n", width, step.Name, formatMs(step.DurationMs))
+		}
+	}
+}
+
+func formatMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().Bool("json", false, "Output the worktree's details as JSON")
+}
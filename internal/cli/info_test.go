@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestBuildWorktreeInfo(t *testing.T) {
+	worktreePath, barePath := createTestWorktree(t)
+	projectPath := filepath.Dir(barePath)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".env"), []byte("APP_URL=https://example.com\nDB_DATABASE=app_swift_runner\nAPP_KEY=secret\n"), 0644))
+
+	report := &config.ScaffoldReport{
+		Time:  time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Steps: []config.ScaffoldStepReport{{Name: "env.write", Status: "completed"}},
+	}
+	require.NoError(t, config.WriteWorktreeRecord(projectPath, config.WorktreeRecord{Path: worktreePath, Branch: "main", LastScaffold: report}))
+
+	pc := &ProjectContext{
+		CWD:           worktreePath,
+		BarePath:      barePath,
+		ProjectPath:   projectPath,
+		DefaultBranch: "main",
+		Config:        &config.Config{},
+	}
+
+	info := buildWorktreeInfo(pc, git.Worktree{Path: worktreePath, Branch: "main", IsMain: true})
+
+	assert.Equal(t, map[string]string{"APP_URL": "https://example.com", "DB_DATABASE": "app_swift_runner"}, info.Env)
+	assert.Equal(t, report, info.LastScaffold)
+	assert.Empty(t, info.Upstream)
+	assert.NotEmpty(t, info.Head)
+	assert.Empty(t, info.Services, "remote hosts are not probed")
+}
+
+func TestLocalServiceAddr(t *testing.T) {
+	tests := []struct {
+		url  string
+		addr string
+		ok   bool
+	}{
+		{"https://feature-x.test", "feature-x.test:443", true},
+		{"http://localhost:8000", "localhost:8000", true},
+		{"http://[::1]:5173", "[::1]:5173", true},
+		{"http://app.localhost", "app.localhost:80", true},
+		{"https://example.com", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		addr, ok := localServiceAddr(tt.url)
+		assert.Equal(t, tt.ok, ok, tt.url)
+		assert.Equal(t, tt.addr, addr, tt.url)
+	}
+}
+
+func TestProbeServices(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	worktreePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(worktreePath, "public"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "public", "hot"), []byte("http://"+listener.Addr().String()), 0644))
+
+	services := probeServices(worktreePath, nil, "http://127.0.0.1:1")
+
+	assert.Equal(t, []serviceStatus{
+		{Name: "site", URL: "http://127.0.0.1:1", Running: false},
+		{Name: "vite", URL: "http://" + listener.Addr().String(), Running: true},
+	}, services)
+}
+
+func TestPrintWorktreeInfo(t *testing.T) {
+	info := &worktreeInfo{
+		Path:     "/code/app/feature-x",
+		Branch:   "feature/x",
+		Parent:   "main",
+		Upstream: "origin/feature/x",
+		Ahead:    2,
+		Behind:   1,
+		DbSuffix: "swift_runner",
+		Databases: []string{
+			"app_swift_runner",
+		},
+		EnvFile: ".env",
+		Env:     map[string]string{"DB_DATABASE": "app_swift_runner"},
+		LastScaffold: &config.ScaffoldReport{
+			Time:       time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local),
+			DurationMs: 1500,
+			Steps: []config.ScaffoldStepReport{
+				{Name: "php.composer", Status: "completed", DurationMs: 1400},
+				{Name: "herd", Status: "skipped", Reason: "condition not met"},
+			},
+		},
+		Preset: &presetInfo{Name: "laravel", Version: 2, ScaffoldedPreset: "laravel", ScaffoldedVersion: 1, ChangedSteps: 2, Outdated: true},
+	}
+
+	var out bytes.Buffer
+	printWorktreeInfo(&out, info)
+
+	assert.Equal(t, `Worktree:  /code/app/feature-x
+Branch:    feature/x (stacked on main)
+Upstream:  origin/feature/x (2 ahead, 1 behind)
+Status:    clean
+Preset:    laravel v2 (scaffolded with v1: 2 step(s) not run, run 'arbor scaffold --upgrade')
+
+Database:
+  Suffix:    swift_runner
+  Databases: app_swift_runner
+
+Site links:
+  none
+
+Env (.env):
+  DB_DATABASE=app_swift_runner
+
+Last scaffold:
+  2026-03-01 09:30, took 1.5s, ok
+  ✓ php.composer  1.4s
+  - herd          skipped (condition not met)
+
+Services:
+  none
+`, out.String())
+}
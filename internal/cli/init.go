@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,24 +10,40 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/artisanexperiences/arbor/internal/config"
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/presets"
 	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/templates"
 	"github.com/artisanexperiences/arbor/internal/ui"
 	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init [REPO] [PATH]",
-	Short: "Initialise a new repository with worktree",
+	Short: i18n.T("cmd.init.short"),
 	Long: `Initialises a new repository as a bare git repository with an initial worktree.
 
 Arguments:
   REPO  Repository URL (supports both full URLs and short GH format)
-  PATH  Optional target directory (defaults to repository basename)`,
+  PATH  Optional target directory (defaults to repository basename)
+
+With --template, a brand-new project is generated instead of cloned and the
+only argument is the target directory:
+
+  arbor init --template laravel-new myapp
+
+Built-in templates are laravel-new (Laravel installer) and laravel
+(composer create-project). More can be defined under templates in the
+global config.`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if templateName := mustGetString(cmd, "template"); templateName != "" {
+			return runInitFromTemplate(cmd, templateName, args)
+		}
+
 		var repo string
 
 		if len(args) > 0 {
@@ -92,6 +109,7 @@ Arguments:
 			return fmt.Errorf("creating main worktree: %w", err)
 		}
 		ui.PrintSuccess(fmt.Sprintf("Created main worktree at %s", mainPath))
+		setUpBranchTracking(barePath, defaultBranch)
 
 		repoName := utils.SanitisePath(utils.ExtractRepoName(repo))
 		siteName := utils.SanitisePath(filepath.Base(path))
@@ -137,32 +155,7 @@ Arguments:
 			}
 		}
 
-		verbose := mustGetBool(cmd, "verbose")
-		quiet := mustGetBool(cmd, "quiet")
-		skipScaffold := mustGetBool(cmd, "skip-scaffold")
-
-		if !skipScaffold && cfg.Preset != "" && verbose {
-			ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", cfg.Preset))
-		}
-
-		if !skipScaffold {
-			promptMode := types.PromptMode{
-				Interactive:   ui.IsInteractive(),
-				NoInteractive: false,
-				Force:         false,
-				CI:            os.Getenv("CI") != "",
-			}
-			if err := scaffoldManager.RunScaffold(mainPath, defaultBranch, repoName, cfg.SiteName, cfg.Preset, cfg, barePath, promptMode, false, verbose, quiet); err != nil {
-				ui.PrintErrorWithHint("Scaffold steps failed", err.Error())
-			}
-		} else {
-			ui.PrintInfo("Skipped scaffold (use 'arbor scaffold main' to scaffold manually)")
-		}
-
-		// Check if .arbor.local should be gitignored
-		if !quiet {
-			checkArborLocalGitignore(mainPath)
-		}
+		runInitialScaffold(cmd, scaffoldManager, cfg, mainPath, barePath, defaultBranch, repoName)
 
 		ui.PrintDone("Repository ready!")
 		ui.PrintInfo(fmt.Sprintf("cd %s", absPath))
@@ -172,12 +165,134 @@ Arguments:
 	},
 }
 
+// runInitialScaffold copies the worktree skeleton into a new project's
+// default worktree and runs its scaffold steps unless --skip-scaffold is
+// set.
+func runInitialScaffold(cmd *cobra.Command, scaffoldManager *scaffold.ScaffoldManager, cfg *config.Config, mainPath, barePath, defaultBranch, repoName string) {
+	verbose := mustGetBool(cmd, "verbose")
+	quiet := mustGetBool(cmd, "quiet")
+	skipScaffold := mustGetBool(cmd, "skip-scaffold")
+
+	if err := applyWorktreeSkeleton(cfg, filepath.Dir(barePath), mainPath, verbose); err != nil {
+		ui.PrintWarning(err.Error())
+	}
+
+	if !skipScaffold && cfg.Preset != "" && verbose {
+		ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", cfg.Preset))
+	}
+
+	if !skipScaffold {
+		promptMode := types.PromptMode{
+			Interactive:   ui.IsInteractive(),
+			NoInteractive: false,
+			Force:         false,
+			CI:            os.Getenv("CI") != "",
+			Trust:         mustGetBool(cmd, "trust"),
+		}
+		err := ensureConfigTrusted(filepath.Dir(barePath), cfg, promptMode)
+		if err == nil {
+			err = scaffoldManager.ScaffoldWorktree(mainPath, defaultBranch, cfg, scaffold.RunOptions{
+				RepoName:   repoName,
+				SiteName:   cfg.SiteName,
+				Preset:     cfg.Preset,
+				BarePath:   barePath,
+				PromptMode: promptMode,
+				Verbose:    verbose,
+				Quiet:      quiet,
+				Sandbox:    sandboxSteps,
+			})
+		}
+		if err != nil {
+			ui.PrintErrorWithHint(i18n.T("hint.scaffold_failed"), err.Error())
+		}
+	} else {
+		ui.PrintInfo(fmt.Sprintf("Skipped scaffold (use 'arbor scaffold %s' to scaffold manually)", filepath.Base(mainPath)))
+	}
+
+	// Check if .arbor.local should be gitignored
+	if !quiet {
+		checkArborLocalGitignore(mainPath)
+	}
+}
+
+// runInitFromTemplate generates a brand-new project from a workspace
+// template instead of cloning a repository.
+func runInitFromTemplate(cmd *cobra.Command, templateName string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--template takes a single PATH argument (e.g. arbor init --template %s myapp)", templateName)
+	}
+
+	globalCfg, err := config.LoadGlobal()
+	if err != nil {
+		if !errors.Is(err, arborerrors.ErrConfigNotFound) {
+			return err
+		}
+		globalCfg = &config.GlobalConfig{}
+	}
+
+	tmpl, err := templates.Resolve(templateName, globalCfg.Templates)
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("getting absolute path: %w", err)
+	}
+
+	defaultBranch := globalCfg.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch = config.DefaultBranch
+	}
+	siteName := utils.SanitisePath(filepath.Base(absPath))
+
+	ui.PrintStep(fmt.Sprintf("Generating %s from template %s", siteName, tmpl.Name))
+	mainPath, err := templates.Generate(tmpl, templates.Options{
+		ProjectPath: absPath,
+		Branch:      defaultBranch,
+		SiteName:    siteName,
+		Stdin:       os.Stdin,
+		Stdout:      os.Stderr,
+		Stderr:      os.Stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("generating project: %w", err)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Created main worktree at %s", mainPath))
+
+	cfg := &config.Config{
+		DefaultBranch: defaultBranch,
+		SiteName:      siteName,
+		Preset:        tmpl.Preset,
+	}
+	if preset := mustGetString(cmd, "preset"); preset != "" {
+		cfg.Preset = preset
+	}
+
+	if err := config.SaveProject(absPath, cfg); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	ui.PrintSuccess("Wrote starter arbor.yaml")
+
+	scaffoldManager := scaffold.NewScaffoldManager()
+	presets.RegisterAllWithScaffold(scaffoldManager)
+
+	runInitialScaffold(cmd, scaffoldManager, cfg, mainPath, filepath.Join(absPath, ".bare"), defaultBranch, siteName)
+
+	ui.PrintDone("Project ready!")
+	ui.PrintInfo(fmt.Sprintf("cd %s", absPath))
+	ui.PrintInfo("arbor work feature/my-feature")
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
 
 	initCmd.Flags().String("preset", "", "Project preset (laravel, php)")
 	initCmd.Flags().Bool("skip-scaffold", false, "Skip scaffold steps during init")
 	initCmd.Flags().Bool("use-repo-config", true, "Automatically use repository config (non-interactive, default: true)")
+	initCmd.Flags().String("template", "", "Generate a new project from a workspace template instead of cloning")
 }
 
 // checkAndCopyRepoConfig checks for arbor.yaml in the repository and prompts to copy it.
@@ -235,12 +350,18 @@ func checkAndCopyRepoConfig(cmd *cobra.Command, mainPath, projectPath string, cf
 		return false, fmt.Errorf("marshaling cleaned config: %w", err)
 	}
 
-	if err := os.WriteFile(projectConfigPath, cleanedData, 0644); err != nil {
+	if err := config.WriteConfigFile(projectConfigPath, cleanedData); err != nil {
 		return false, fmt.Errorf("writing project config: %w", err)
 	}
 
 	ui.PrintSuccess("Copied arbor.yaml to project root")
 
+	// The copied config runs commands from the repository, so its steps
+	// wait until the user has reviewed and trusted it.
+	if err := config.WriteTrustRecord(projectPath, config.TrustRecord{}); err != nil {
+		return false, err
+	}
+
 	// Reload config to get scaffold steps
 	reloadedCfg, err := config.LoadProject(projectPath)
 	if err != nil {
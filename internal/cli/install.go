@@ -10,12 +10,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var installCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Setup global configuration",
+	Short: i18n.T("cmd.install.short"),
 	Long: `Sets up global configuration and detects available tools.
 
 Creates the global arbor.yaml configuration file and detects
@@ -104,7 +105,7 @@ func getToolVersion(name, path string) (string, error) {
 		cmd = exec.Command(path, "-v")
 	case "composer":
 		cmd = exec.Command(path, "--version")
-	case "npm":
+	case "npm", "yarn", "pnpm", "bun", "mysql":
 		cmd = exec.Command(path, "--version")
 	case "herd":
 		cmd = exec.Command(path, "version")
@@ -150,12 +151,21 @@ func extractVersion(output, tool string) string {
 				}
 			}
 		}
-	case "npm":
+	case "npm", "yarn", "pnpm", "bun":
 		for _, line := range lines {
 			if strings.Contains(line, ".") {
 				return strings.TrimSpace(line)
 			}
 		}
+	case "mysql":
+		for _, line := range lines {
+			parts := strings.Fields(line)
+			for i, part := range parts {
+				if part == "Ver" && i+1 < len(parts) {
+					return parts[i+1]
+				}
+			}
+		}
 	case "herd":
 		for _, line := range lines {
 			if strings.Contains(line, "version") || strings.Contains(line, "Herd") {
@@ -5,37 +5,79 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
 	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List all worktrees",
+	Short: i18n.T("cmd.list.short"),
 	Long: `List all worktrees in the repository with their status.
 
 Shows worktrees with merge status, current worktree indicator,
-and main branch highlighting.`,
+and main branch highlighting.
+
+--stack shows each branch under the branch it was created from with
+'arbor work --base', so stacks of dependent branches read top to bottom.
+
+--filter keeps the worktrees whose folder or branch name contains the
+given characters in order, so 'usr auth' keeps feature/user-auth. --limit
+shows at most that many worktrees and says how many more there are; it
+applies after sorting and filtering, and to --json and --porcelain too.
+
+--timing reports on stderr how long each phase took (opening the project,
+listing worktrees and their merge status, reading local state, reading
+branch parents and rendering), to find where time goes in large projects.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		timer := newPhaseTimer(mustGetBool(cmd, "timing"))
+		defer timer.Print(os.Stderr)
+
 		pc, err := OpenProjectFromCWD()
 		if err != nil {
 			return err
 		}
+		timer.Mark("open project")
 
 		jsonOutput := mustGetBool(cmd, "json")
 		porcelain := mustGetBool(cmd, "porcelain")
 		sortBy := mustGetString(cmd, "sort-by")
 		reverse := mustGetBool(cmd, "reverse")
+		stack := mustGetBool(cmd, "stack")
+		limit := mustGetInt(cmd, "limit")
+		if limit < 0 {
+			return fmt.Errorf("--limit must not be negative")
+		}
 
 		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
 		if err != nil {
 			return fmt.Errorf("listing worktrees: %w", err)
 		}
+		timer.Mark("list worktrees")
 
 		worktrees = git.SortWorktrees(worktrees, sortBy, reverse)
+		markPendingScaffolds(worktrees)
+		timer.Mark("read local state")
+		markParents(pc.BarePath, worktrees)
+		timer.Mark("read branch parents")
+		worktrees = filterWorktrees(worktrees, mustGetString(cmd, "filter"))
+		mismatched := markDirMismatches(worktrees)
+
+		hidden := 0
+		if limit > 0 && len(worktrees) > limit {
+			hidden = len(worktrees) - limit
+			worktrees = worktrees[:limit]
+		}
+
+		defer timer.Mark("render")
 
 		if jsonOutput {
 			return printJSON(os.Stdout, worktrees)
@@ -45,10 +87,85 @@ and main branch highlighting.`,
 			return printPorcelain(os.Stdout, worktrees)
 		}
 
-		return printTable(os.Stdout, worktrees)
+		if stack {
+			if _, err := fmt.Fprint(os.Stdout, ui.RenderWorktreeStack(worktrees)); err != nil {
+				return err
+			}
+		} else if err := printTable(os.Stdout, worktrees); err != nil {
+			return err
+		}
+		if hidden > 0 {
+			ui.PrintInfo(fmt.Sprintf("%d more worktree(s) not shown - use --limit 0 to show all", hidden))
+		}
+		if mismatched > 0 {
+			ui.PrintInfo(fmt.Sprintf("%d worktree folder(s) no longer match their branch - run 'arbor repair --rename-dirs' to rename them", mismatched))
+		}
+		if collisions, err := steps.FindDbSuffixCollisions(nil, pc.BarePath); err == nil && len(collisions) > 0 {
+			ui.PrintWarning(fmt.Sprintf("%d database suffix(es) are used by more than one worktree - run 'arbor repair --db-suffixes' to give each its own", len(collisions)))
+		}
+		return nil
 	},
 }
 
+// detailedWorktrees lists the project's worktrees with the details
+// 'arbor list' shows, sorted by name. cwd decides which one is current.
+func detailedWorktrees(pc *ProjectContext, cwd string) ([]git.Worktree, error) {
+	worktrees, err := git.ListWorktreesDetailed(pc.BarePath, cwd, pc.DefaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+	worktrees = git.SortWorktrees(worktrees, "name", false)
+	markPendingScaffolds(worktrees)
+	markParents(pc.BarePath, worktrees)
+	markDirMismatches(worktrees)
+	return worktrees, nil
+}
+
+// markPendingScaffolds flags worktrees whose scaffold was deferred with
+// 'arbor work --no-scaffold'.
+func markPendingScaffolds(worktrees []git.Worktree) {
+	for i := range worktrees {
+		if state, err := config.ReadLocalState(worktrees[i].Path); err == nil {
+			worktrees[i].ScaffoldPending = state.ScaffoldPending
+		}
+	}
+}
+
+// markDirMismatches flags worktrees whose folder is not named after their
+// branch, and returns how many there are.
+func markDirMismatches(worktrees []git.Worktree) int {
+	count := 0
+	for i := range worktrees {
+		if !dirMatchesBranch(worktrees[i]) {
+			worktrees[i].DirMismatch = true
+			count++
+		}
+	}
+	return count
+}
+
+// dirMatchesBranch reports whether a worktree's folder is named after its
+// branch the way 'arbor work' names it. Detached worktrees always match.
+func dirMatchesBranch(wt git.Worktree) bool {
+	if wt.Detached || wt.Branch == "" || wt.Branch == "(bare)" {
+		return true
+	}
+	return filepath.Base(wt.Path) == utils.SanitisePath(wt.Branch)
+}
+
+// markParents fills in the branch each worktree's branch is stacked on.
+func markParents(barePath string, worktrees []git.Worktree) {
+	parents, err := git.BranchParents(barePath)
+	if err != nil {
+		return
+	}
+	for i := range worktrees {
+		if !worktrees[i].Detached {
+			worktrees[i].Parent = parents[worktrees[i].Branch]
+		}
+	}
+}
+
 func printTable(w io.Writer, worktrees []git.Worktree) error {
 	if len(worktrees) == 0 {
 		_, err := fmt.Fprintln(w, "No worktrees found.")
@@ -59,51 +176,66 @@ func printTable(w io.Writer, worktrees []git.Worktree) error {
 	return err
 }
 
+// worktreeJSON is a worktree as 'arbor list --json' and 'arbor serve'
+// report it.
+type worktreeJSON struct {
+	Path            string `json:"path"`
+	Branch          string `json:"branch"`
+	IsMain          bool   `json:"isMain"`
+	IsCurrent       bool   `json:"isCurrent"`
+	IsMerged        bool   `json:"isMerged"`
+	ScaffoldPending bool   `json:"scaffoldPending"`
+	Detached        bool   `json:"detached"`
+	Head            string `json:"head"`
+	Parent          string `json:"parent,omitempty"`
+	DirMismatch     bool   `json:"dirMismatch"`
+}
+
 func printJSON(w io.Writer, worktrees []git.Worktree) error {
-	type worktreeJSON struct {
-		Path      string `json:"path"`
-		Branch    string `json:"branch"`
-		IsMain    bool   `json:"isMain"`
-		IsCurrent bool   `json:"isCurrent"`
-		IsMerged  bool   `json:"isMerged"`
-	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(toWorktreeJSON(worktrees))
+}
 
+func toWorktreeJSON(worktrees []git.Worktree) []worktreeJSON {
 	jsonWorktrees := make([]worktreeJSON, len(worktrees))
 	for i, wt := range worktrees {
 		jsonWorktrees[i] = worktreeJSON{
-			Path:      wt.Path,
-			Branch:    wt.Branch,
-			IsMain:    wt.IsMain,
-			IsCurrent: wt.IsCurrent,
-			IsMerged:  wt.IsMerged,
+			Path:            wt.Path,
+			Branch:          wt.Branch,
+			IsMain:          wt.IsMain,
+			IsCurrent:       wt.IsCurrent,
+			IsMerged:        wt.IsMerged,
+			ScaffoldPending: wt.ScaffoldPending,
+			Detached:        wt.Detached,
+			Head:            wt.Head,
+			Parent:          wt.Parent,
+			DirMismatch:     wt.DirMismatch,
 		}
 	}
-
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(jsonWorktrees)
+	return jsonWorktrees
 }
 
+// printPorcelain writes one tab-separated line per worktree:
+// path, branch, main, current, merged, pending, detached. Flag columns hold
+// their own name when set and "-" otherwise; detached worktrees have "-" as
+// their branch. See "Porcelain output" in the README.
 func printPorcelain(w io.Writer, worktrees []git.Worktree) error {
 	for _, wt := range worktrees {
-		current := ""
-		if wt.IsCurrent {
-			current = "current"
-		}
-
-		main := ""
-		if wt.IsMain {
-			main = "main"
+		branch := wt.Branch
+		if wt.Detached {
+			branch = "-"
 		}
-
-		merged := ""
-		if wt.IsMerged {
-			merged = "merged"
-		} else {
-			merged = "-"
+		fields := []string{
+			wt.Path,
+			branch,
+			porcelainFlag(wt.IsMain, "main"),
+			porcelainFlag(wt.IsCurrent, "current"),
+			porcelainFlag(wt.IsMerged, "merged"),
+			porcelainFlag(wt.ScaffoldPending, "pending"),
+			porcelainFlag(wt.Detached, "detached"),
 		}
-
-		if _, err := fmt.Fprintf(w, "%s %s %s %s %s\n", wt.Path, wt.Branch, main, current, merged); err != nil {
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
 			return err
 		}
 	}
@@ -111,11 +243,22 @@ func printPorcelain(w io.Writer, worktrees []git.Worktree) error {
 	return nil
 }
 
+func porcelainFlag(set bool, name string) string {
+	if set {
+		return name
+	}
+	return "-"
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().Bool("json", false, "Output as JSON array")
-	listCmd.Flags().Bool("porcelain", false, "Machine-parseable output")
+	listCmd.Flags().Bool("porcelain", false, "Machine-parseable tab-separated output")
 	listCmd.Flags().String("sort-by", "name", "Sort by: name, branch, created")
 	listCmd.Flags().Bool("reverse", false, "Reverse sort order")
+	listCmd.Flags().Bool("stack", false, "Show stacked branches as a tree under their parents")
+	listCmd.Flags().Int("limit", 0, "Show at most this many worktrees (0 for all)")
+	listCmd.Flags().String("filter", "", "Only show worktrees whose folder or branch fuzzy-matches this")
+	listCmd.Flags().Bool("timing", false, "Report how long each phase took on stderr")
 }
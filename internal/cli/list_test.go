@@ -184,6 +184,9 @@ func TestPrintPorcelain(t *testing.T) {
 	worktrees := []git.Worktree{
 		{Path: "/test/main", Branch: "main", IsMain: true, IsCurrent: true, IsMerged: true},
 		{Path: "/test/feature", Branch: "feature", IsMain: false, IsCurrent: false, IsMerged: false},
+		{Path: "/test/with space", Branch: "fix/merged", IsMain: false, IsCurrent: false, IsMerged: true},
+		{Path: "/test/queued", Branch: "queued", ScaffoldPending: true},
+		{Path: "/test/v2.3.1", Detached: true, Head: "3f2c1ab9d0e4"},
 	}
 
 	var buf bytes.Buffer
@@ -192,16 +195,10 @@ func TestPrintPorcelain(t *testing.T) {
 		t.Fatalf("printPorcelain failed: %v", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
-	if len(lines) != 2 {
-		t.Errorf("expected 2 lines, got %d: %s", len(lines), buf.String())
-	}
+	assertGolden(t, "list_porcelain", buf.String())
 
-	for _, line := range lines {
-		parts := strings.Split(line, " ")
-		if len(parts) < 5 {
-			t.Fatalf("porcelain line should have 5 fields, got %d: %s", len(parts), line)
-		}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		assert.Len(t, strings.Split(line, "\t"), 7, "porcelain line should have 7 tab-separated fields: %q", line)
 	}
 }
 
@@ -383,3 +380,19 @@ func TestListCommand_FolderNameMatchesArborRemove(t *testing.T) {
 		t.Errorf("expected path %s (resolved: %s), got %s (resolved: %s)", featurePath, featurePathEval, myFeatureWorktree.Path, wtPathEval)
 	}
 }
+
+func TestPrintTable_ScaffoldPending(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Path: "/test/queued", Branch: "queued", ScaffoldPending: true},
+	}
+
+	var buf bytes.Buffer
+	err := printTable(&buf, worktrees)
+	if err != nil {
+		t.Fatalf("printTable failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "scaffold pending") {
+		t.Errorf("output should show the pending scaffold, got: %s", buf.String())
+	}
+}
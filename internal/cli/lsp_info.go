@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// lspInfoSchemaVersion is bumped when fields of 'arbor lsp-info' output are
+// removed or change meaning. Added fields do not bump it.
+const lspInfoSchemaVersion = 1
+
+// lspInfo is the project model printed by 'arbor lsp-info'.
+type lspInfo struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Version       string           `json:"version"`
+	Project       projectInfoJSON  `json:"project"`
+	ConfigFiles   []configFileJSON `json:"configFiles"`
+	Worktrees     []worktreeJSON   `json:"worktrees"`
+	Steps         []stepInfoJSON   `json:"steps"`
+	StepKeys      []string         `json:"stepKeys"`
+	Conditions    []string         `json:"conditions"`
+	Presets       []presetInfoJSON `json:"presets"`
+	// TemplateVariables are the names step templates can use in the
+	// current worktree, or the default branch's when run elsewhere.
+	TemplateVariables []templateVarJSON `json:"templateVariables"`
+}
+
+// configFileJSON is a config file arbor reads. Local files are listed per
+// worktree.
+type configFileJSON struct {
+	Scope    string `json:"scope"`
+	Path     string `json:"path"`
+	Worktree string `json:"worktree,omitempty"`
+	Exists   bool   `json:"exists"`
+}
+
+type stepInfoJSON struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type presetInfoJSON struct {
+	Name     string   `json:"name"`
+	Detected bool     `json:"detected"`
+	Steps    []string `json:"steps"`
+}
+
+// templateVarJSON is a template variable. Step names the step whose
+// store_as sets it; variables known before scaffolding have none.
+type templateVarJSON struct {
+	Name string `json:"name"`
+	Step string `json:"step,omitempty"`
+}
+
+var lspInfoCmd = &cobra.Command{
+	Use:   "lsp-info",
+	Short: i18n.T("cmd.lsp-info.short"),
+	Long: `Print the project as JSON for editor extensions: its worktrees, the
+scaffold steps, step keys and condition keys arbor.yaml may use, the
+presets, the config files arbor reads, and the template variables steps
+can use.
+
+Template variables are listed by name only, for the current worktree or,
+outside one, the default branch's. schemaVersion changes only when fields
+are removed or change meaning.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return fmt.Errorf("opening project: %w", err)
+		}
+
+		info, err := buildLSPInfo(pc)
+		if err != nil {
+			return err
+		}
+		return printLSPInfo(os.Stdout, info)
+	},
+}
+
+func buildLSPInfo(pc *ProjectContext) (*lspInfo, error) {
+	worktrees, err := detailedWorktrees(pc, pc.CWD)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &lspInfo{
+		SchemaVersion: lspInfoSchemaVersion,
+		Version:       Version,
+		Project: projectInfoJSON{
+			ProjectPath:   pc.ProjectPath,
+			BarePath:      pc.BarePath,
+			DefaultBranch: pc.DefaultBranch,
+			Preset:        pc.Config.Preset,
+			Version:       Version,
+		},
+		ConfigFiles:       lspConfigFiles(pc, worktrees),
+		Worktrees:         toWorktreeJSON(worktrees),
+		StepKeys:          config.StepConfigKeys(),
+		Conditions:        types.ConditionKeys,
+		TemplateVariables: []templateVarJSON{},
+	}
+
+	for _, name := range pc.ScaffoldManager().StepNames() {
+		info.Steps = append(info.Steps, stepInfoJSON{Name: name, Description: scaffold.StepDescription(name)})
+	}
+
+	// Presets are detected, and template variables resolved, in the
+	// worktree 'arbor context' would show.
+	wt, err := resolveContextWorktree(pc, worktrees, "")
+	var detected string
+	if err == nil {
+		detected = pc.PresetManager().Detect(wt.Path)
+		snapshot, err := buildContextSnapshot(pc, *wt)
+		if err != nil {
+			return nil, err
+		}
+		info.TemplateVariables = lspTemplateVars(snapshot)
+	}
+
+	names := pc.PresetManager().Available()
+	sort.Strings(names)
+	for _, name := range names {
+		preset, _ := pc.PresetManager().Get(name)
+		presetInfo := presetInfoJSON{Name: name, Detected: name == detected, Steps: []string{}}
+		for _, step := range preset.DefaultSteps() {
+			presetInfo.Steps = append(presetInfo.Steps, step.Name)
+		}
+		info.Presets = append(info.Presets, presetInfo)
+	}
+
+	return info, nil
+}
+
+// lspConfigFiles lists the project and global config files and each
+// worktree's .arbor.local.
+func lspConfigFiles(pc *ProjectContext, worktrees []git.Worktree) []configFileJSON {
+	files := []configFileJSON{{Scope: "project", Path: filepath.Join(pc.ProjectPath, "arbor.yaml")}}
+	if dir, err := config.GetGlobalConfigDir(); err == nil {
+		files = append(files, configFileJSON{Scope: "global", Path: filepath.Join(dir, "arbor.yaml")})
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == "(bare)" {
+			continue
+		}
+		files = append(files, configFileJSON{Scope: "local", Path: filepath.Join(wt.Path, ".arbor.local"), Worktree: wt.Path})
+	}
+
+	for i := range files {
+		_, err := os.Stat(files[i].Path)
+		files[i].Exists = err == nil
+	}
+	return files
+}
+
+// lspTemplateVars returns the names of a context snapshot's variables,
+// sorted, without their values.
+func lspTemplateVars(snapshot *contextSnapshot) []templateVarJSON {
+	vars := make([]templateVarJSON, 0, len(snapshot.Template)+len(snapshot.Runtime))
+	for name := range snapshot.Template {
+		vars = append(vars, templateVarJSON{Name: name})
+	}
+	for _, v := range snapshot.Runtime {
+		vars = append(vars, templateVarJSON{Name: v.Name, Step: v.Step})
+	}
+	sort.SliceStable(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+func printLSPInfo(w io.Writer, info *lspInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
+
+func init() {
+	rootCmd.AddCommand(lspInfoCmd)
+}
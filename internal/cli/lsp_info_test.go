@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestBuildLSPInfo(t *testing.T) {
+	worktreePath, barePath := createTestWorktree(t)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".env"), []byte("DB_PASSWORD=hunter2\n"), 0644))
+
+	pc := &ProjectContext{
+		CWD:           worktreePath,
+		BarePath:      barePath,
+		ProjectPath:   filepath.Dir(barePath),
+		DefaultBranch: "main",
+		Config: &config.Config{
+			DefaultBranch: "main",
+			Scaffold: config.ScaffoldConfig{
+				Override: true,
+				Steps: []config.StepConfig{
+					{Name: "env.read", Key: "DB_PASSWORD"},
+					{Name: "bash.run", Command: "echo hi", StoreAs: "Greeting"},
+				},
+			},
+		},
+	}
+
+	info, err := buildLSPInfo(pc)
+	require.NoError(t, err)
+
+	require.Len(t, info.Worktrees, 1)
+	assert.True(t, info.Worktrees[0].IsCurrent)
+
+	var stepNames []string
+	for _, step := range info.Steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	assert.Contains(t, stepNames, "bash.run")
+	assert.Contains(t, stepNames, "db.create")
+	assert.Contains(t, info.StepKeys, "store_as")
+	assert.Contains(t, info.Conditions, "file_exists")
+
+	var presetNames []string
+	for _, preset := range info.Presets {
+		presetNames = append(presetNames, preset.Name)
+	}
+	assert.Contains(t, presetNames, "laravel")
+
+	assert.Contains(t, info.TemplateVariables, templateVarJSON{Name: "Branch"})
+	assert.Contains(t, info.TemplateVariables, templateVarJSON{Name: "DB_PASSWORD"})
+	assert.Contains(t, info.TemplateVariables, templateVarJSON{Name: "Greeting", Step: "bash.run"})
+
+	assert.Equal(t, "project", info.ConfigFiles[0].Scope)
+	assert.True(t, info.ConfigFiles[0].Exists)
+
+	var buf bytes.Buffer
+	require.NoError(t, printLSPInfo(&buf, info))
+	assert.NotContains(t, buf.String(), "hunter2")
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, float64(lspInfoSchemaVersion), decoded["schemaVersion"])
+}
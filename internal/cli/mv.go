@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var mvCmd = &cobra.Command{
+	Use:   "mv WORKTREE DESTINATION",
+	Short: i18n.T("cmd.mv.short"),
+	Long: `Moves a worktree folder to another path, e.g. onto a faster disk.
+
+Arguments:
+  WORKTREE     Folder name or branch of the worktree to move, or part of
+               one when it matches a single worktree
+  DESTINATION  New path for the worktree; an existing directory receives
+               the worktree under its current folder name
+
+The command will:
+1. Move the folder with git worktree move, or copy it and run
+   git worktree repair when the destination is on another filesystem
+2. Relink Herd or Valet sites that point at the folder
+3. Rewrite paths into the old folder in .env and .arbor.local
+4. Update the project's record of the worktree used by 'arbor gc'
+
+Worktrees outside the project folder keep working with arbor, which finds
+the project through their .git file. The default branch's worktree stays
+in the project folder.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+
+		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+
+		target, err := matchWorktree(worktrees, pc.ProjectPath, args[0])
+		if err != nil {
+			return err
+		}
+		if target.IsMain {
+			return fmt.Errorf("cannot move the default branch's worktree: arbor expects it in the project folder")
+		}
+
+		newPath, err := mvDestination(target.Path, args[1])
+		if err != nil {
+			return err
+		}
+		if newPath == target.Path {
+			return fmt.Errorf("worktree is already at %s", newPath)
+		}
+		if isWithinDir(newPath, target.Path) {
+			return fmt.Errorf("cannot move a worktree into itself")
+		}
+		if _, err := os.Lstat(newPath); err == nil {
+			return fmt.Errorf("cannot move worktree: %s already exists", newPath)
+		}
+
+		ui.PrintStep(fmt.Sprintf("Moving %s", target.Label()))
+
+		if err := relocateWorktree(pc, target.Path, newPath, dryRun); err != nil {
+			return err
+		}
+		if dryRun {
+			ui.PrintDone("Dry run complete")
+			return nil
+		}
+
+		if isWithinDir(pc.CWD, target.Path) {
+			ui.PrintInfo(fmt.Sprintf("Your shell is still in the old folder; run: cd %s", newPath))
+		}
+		ui.PrintDone(fmt.Sprintf("Moved %s to %s", target.Label(), newPath))
+		return nil
+	},
+}
+
+// mvDestination resolves the DESTINATION argument of 'arbor mv' to the
+// worktree's new absolute path. Like mv, an existing directory receives the
+// worktree under its current folder name.
+func mvDestination(worktreePath, destination string) (string, error) {
+	newPath, err := filepath.Abs(destination)
+	if err != nil {
+		return "", fmt.Errorf("resolving destination: %w", err)
+	}
+	if info, err := os.Stat(newPath); err == nil && info.IsDir() {
+		newPath = filepath.Join(newPath, filepath.Base(worktreePath))
+	}
+	return newPath, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMvDestination(t *testing.T) {
+	existing := t.TempDir()
+
+	got, err := mvDestination("/projects/app/feature-login", existing)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(existing, "feature-login"), got)
+
+	missing := filepath.Join(existing, "login")
+	got, err = mvDestination("/projects/app/feature-login", missing)
+	require.NoError(t, err)
+	assert.Equal(t, missing, got)
+}
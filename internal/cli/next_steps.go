@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// nextSteps is what to do with a worktree 'arbor work' has just set up:
+// where it is, where the site is served, which database it uses and how
+// to start its development servers.
+type nextSteps struct {
+	Path            string   `json:"path"`
+	Branch          string   `json:"branch"`
+	SiteURL         string   `json:"siteUrl,omitempty"`
+	Database        string   `json:"database,omitempty"`
+	Commands        []string `json:"commands"`
+	ScaffoldPending bool     `json:"scaffoldPending"`
+	DryRun          bool     `json:"dryRun,omitempty"`
+}
+
+// buildNextSteps reads a worktree's site, database and dev commands. A
+// Herd or Valet link gives the site URL, falling back to APP_URL in the
+// primary env file. Commands start with a cd from the current directory.
+func buildNextSteps(pc *ProjectContext, path, branch string, pending bool) nextSteps {
+	steps := nextSteps{Path: path, Branch: branch, Commands: []string{}, ScaffoldPending: pending}
+
+	// A sibling of the current worktree is one level up; anything further
+	// away reads better as an absolute path.
+	if rel, err := filepath.Rel(pc.CWD, path); err == nil && rel != "." {
+		if strings.Count(rel, "..") > 1 {
+			rel = path
+		}
+		steps.Commands = append(steps.Commands, "cd "+utils.ShellQuote(rel))
+	}
+
+	if pending {
+		steps.Commands = append(steps.Commands, "arbor scaffold")
+		return steps
+	}
+
+	links := findSiteLinks(path)
+	env := utils.ReadEnvFile(path, pc.Config.PrimaryEnvFile())
+	if len(links) > 0 {
+		steps.SiteURL = links[0].URL()
+	} else {
+		steps.SiteURL = env["APP_URL"]
+	}
+	steps.Database = env["DB_DATABASE"]
+
+	presetName := pc.Config.Preset
+	if presetName == "" {
+		presetName = pc.PresetManager().Detect(path)
+	}
+	if preset, ok := pc.PresetManager().Get(presetName); ok {
+		steps.Commands = append(steps.Commands, preset.DevCommands(path, len(links) > 0)...)
+	}
+	return steps
+}
+
+// printNextSteps prints the block shown after 'arbor work'.
+func printNextSteps(w io.Writer, steps nextSteps) {
+	fmt.Fprintln(w, "\nNext steps:")
+	for _, command := range steps.Commands {
+		fmt.Fprintf(w, "  %s\n", command)
+	}
+	if steps.SiteURL != "" {
+		fmt.Fprintf(w, "\n  Site:      %s\n", steps.SiteURL)
+	}
+	if steps.Database != "" {
+		if steps.SiteURL == "" {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "  Database:  %s\n", steps.Database)
+	}
+}
+
+func printNextStepsJSON(w io.Writer, steps nextSteps) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(steps)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+func TestBuildNextSteps(t *testing.T) {
+	projectPath := t.TempDir()
+	worktreePath := filepath.Join(projectPath, "feature-x")
+	require.NoError(t, os.MkdirAll(worktreePath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".env"), []byte("APP_URL=http://feature-x.localhost\nDB_DATABASE=app_feature_x\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "composer.json"), []byte(`{"scripts": {"dev": ["npx concurrently"]}}`), 0644))
+
+	pc := &ProjectContext{
+		CWD:         filepath.Join(projectPath, "main"),
+		ProjectPath: projectPath,
+		Config:      &config.Config{Preset: "laravel"},
+	}
+
+	t.Run("scaffolded worktree", func(t *testing.T) {
+		steps := buildNextSteps(pc, worktreePath, "feature-x", false)
+
+		assert.Equal(t, "http://feature-x.localhost", steps.SiteURL)
+		assert.Equal(t, "app_feature_x", steps.Database)
+		assert.Equal(t, []string{"cd ../feature-x", "composer run dev"}, steps.Commands)
+
+		var out bytes.Buffer
+		printNextSteps(&out, steps)
+		assert.Equal(t, "\nNext steps:\n  cd ../feature-x\n  composer run dev\n\n  Site:      http://feature-x.localhost\n  Database:  app_feature_x\n", out.String())
+	})
+
+	t.Run("pending scaffold", func(t *testing.T) {
+		steps := buildNextSteps(pc, worktreePath, "feature-x", true)
+
+		assert.True(t, steps.ScaffoldPending)
+		assert.Empty(t, steps.Database)
+		assert.Equal(t, []string{"cd ../feature-x", "arbor scaffold"}, steps.Commands)
+	})
+
+	t.Run("distant worktree uses an absolute path", func(t *testing.T) {
+		distant := &ProjectContext{
+			CWD:         filepath.Join(projectPath, "main", "app", "Models"),
+			ProjectPath: projectPath,
+			Config:      pc.Config,
+		}
+
+		steps := buildNextSteps(distant, worktreePath, "feature-x", true)
+		assert.Equal(t, "cd "+utils.ShellQuote(worktreePath), steps.Commands[0])
+	})
+}
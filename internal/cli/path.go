@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/i18n"
+)
+
+var pathCmd = &cobra.Command{
+	Use:   "path [WORKTREE]",
+	Short: i18n.T("cmd.path.short"),
+	Long: `Prints the path of a worktree, for scripts and the 'arbor cd' helper.
+
+WORKTREE is a folder name, branch or path. A partial name is accepted when
+it matches one worktree: 'arbor path auth' prints the path of
+feature/user-auth, by substring or, failing that, by fuzzy match. When
+several worktrees match, they are listed and nothing is printed.
+
+Arguments:
+  WORKTREE  Worktree folder name, branch or path, or part of one
+            (defaults to the default branch's worktree)`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := detailedWorktrees(pc, pc.CWD)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			for _, wt := range worktrees {
+				if wt.IsMain {
+					fmt.Fprintln(cmd.OutOrStdout(), wt.Path)
+					return nil
+				}
+			}
+			return fmt.Errorf("no worktree for the default branch '%s'", pc.DefaultBranch)
+		}
+
+		wt, err := matchWorktree(worktrees, pc.ProjectPath, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), wt.Path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pathCmd)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func runPath(t *testing.T, dir string, args []string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(dir))
+
+	err = pathCmd.RunE(cmd, args)
+	return out.String(), err
+}
+
+func TestPathCmd(t *testing.T) {
+	projectDir, featurePath := setupRecycleProject(t, "default_branch: main\n")
+	barePath := filepath.Join(projectDir, ".bare")
+	authPath := filepath.Join(projectDir, "user-auth")
+	require.NoError(t, git.CreateWorktree(barePath, authPath, "team/user-auth", "main"))
+
+	resolve := func(path string) string {
+		resolved, err := filepath.EvalSymlinks(path)
+		require.NoError(t, err)
+		return resolved
+	}
+	assertPrints := func(t *testing.T, want string, args ...string) {
+		t.Helper()
+		out, err := runPath(t, projectDir, args)
+		require.NoError(t, err)
+		require.Equal(t, 1, bytes.Count([]byte(out), []byte("\n")), "prints one line")
+		assert.Equal(t, resolve(want), resolve(out[:len(out)-1]))
+	}
+
+	t.Run("defaults to the default branch's worktree", func(t *testing.T) {
+		assertPrints(t, filepath.Join(projectDir, "main"))
+	})
+
+	t.Run("matches a folder name or branch", func(t *testing.T) {
+		assertPrints(t, featurePath, "feature")
+		assertPrints(t, authPath, "team/user-auth")
+	})
+
+	t.Run("matches part of a name", func(t *testing.T) {
+		assertPrints(t, authPath, "auth")
+	})
+
+	t.Run("prints nothing when no worktree matches", func(t *testing.T) {
+		out, err := runPath(t, projectDir, []string{"billing"})
+		assert.Error(t, err)
+		assert.Empty(t, out)
+	})
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/presets"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: i18n.T("cmd.preset.short"),
+	Long:  `Commands for the presets built into arbor, such as laravel and php.`,
+}
+
+var presetShowCmd = &cobra.Command{
+	Use:     "show NAME",
+	Aliases: []string{"export"},
+	Short:   i18n.T("cmd.preset.show.short"),
+	Long: `Prints a built-in preset's scaffold steps, cleanup steps and health checks
+as arbor.yaml, so you can see exactly what the preset does.
+
+--output writes the YAML to a file instead, e.g. to start a config from
+it. 'arbor preset eject' copies the scaffold steps into the project's
+arbor.yaml instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preset, err := builtInPreset(presets.NewManager(), args[0])
+		if err != nil {
+			return err
+		}
+
+		out, err := presetYAML(preset)
+		if err != nil {
+			return err
+		}
+		if output := mustGetString(cmd, "output"); output != "" {
+			if err := os.WriteFile(output, out, 0644); err != nil {
+				return fmt.Errorf("writing %s: %w", output, err)
+			}
+			ui.PrintSuccess(fmt.Sprintf("Wrote the %s preset to %s", preset.Name(), output))
+			return nil
+		}
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	},
+}
+
+var presetEjectCmd = &cobra.Command{
+	Use:   "eject [NAME]",
+	Short: i18n.T("cmd.preset.eject.short"),
+	Long: `Copies a preset's scaffold steps into the project's arbor.yaml and sets
+scaffold.override, so you can edit them rather than guess what the preset
+does. The copies go before the steps arbor.yaml already lists, where the
+preset's steps ran, and scaffold.preset_overrides is applied to them and
+removed, so scaffolds run the same steps as before.
+
+NAME defaults to the project's preset, or the one detected in the default
+branch's worktree. The preset still provides the cleanup steps, checks and
+dev commands; 'arbor preset show' prints those.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+		if pc.Config.Scaffold.Override {
+			return fmt.Errorf("scaffold.override is already set in arbor.yaml, so no preset steps run")
+		}
+
+		name := pc.Config.Preset
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+			if err != nil {
+				return fmt.Errorf("listing worktrees: %w", err)
+			}
+			for _, wt := range worktrees {
+				if wt.IsMain {
+					name = pc.PresetManager().Detect(wt.Path)
+				}
+			}
+			if name == "" {
+				return fmt.Errorf("no preset set in arbor.yaml or detected; name one, e.g. 'arbor preset eject laravel'")
+			}
+		}
+		preset, err := builtInPreset(pc.PresetManager(), name)
+		if err != nil {
+			return err
+		}
+
+		steps := ejectedSteps(preset.DefaultSteps(), pc.Config.Scaffold.PresetOverrides)
+		if mustGetBool(cmd, "dry-run") {
+			node, err := config.EncodeNode(steps)
+			if err != nil {
+				return err
+			}
+			out, err := config.MarshalYAML(node)
+			if err != nil {
+				return err
+			}
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would add these steps to the start of scaffold.steps and set scaffold.override:\n%s", out))
+			return nil
+		}
+
+		configPath := filepath.Join(pc.ProjectPath, "arbor.yaml")
+		before, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("reading project config: %w", err)
+		}
+		if err := config.EjectPresetSteps(pc.ProjectPath, steps); err != nil {
+			return err
+		}
+		// The added steps are arbor's own, so a config trusted before
+		// stays trusted.
+		if record, err := config.ReadTrustRecord(pc.ProjectPath); err == nil && record != nil && record.Trusts(before) {
+			after, err := os.ReadFile(configPath)
+			if err != nil {
+				return fmt.Errorf("reading project config: %w", err)
+			}
+			if err := config.WriteTrustRecord(pc.ProjectPath, config.TrustRecord{ConfigHash: config.HashConfig(after), TrustedAt: time.Now().UTC()}); err != nil {
+				return err
+			}
+		}
+
+		ui.PrintSuccess(fmt.Sprintf("Copied %d steps of the %s preset into arbor.yaml", len(steps), preset.Name()))
+		return nil
+	},
+}
+
+// builtInPreset returns the preset called name, or an error listing the
+// available ones.
+func builtInPreset(m *presets.Manager, name string) (presets.Preset, error) {
+	if preset, ok := m.Get(name); ok {
+		return preset, nil
+	}
+	available := m.Available()
+	slices.Sort(available)
+	return nil, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(available, ", "))
+}
+
+// ejectedSteps returns copies of a preset's steps with the overrides
+// applied, in order, as the scaffold would run them.
+func ejectedSteps(presetSteps []config.StepConfig, overrides config.PresetOverrides) []config.StepConfig {
+	steps := slices.Clone(presetSteps)
+	for _, selector := range overrides.Selectors() {
+		for i, step := range steps {
+			if config.SelectorMatches(selector, step) {
+				steps[i] = overrides[selector].Apply(step)
+			}
+		}
+	}
+	return steps
+}
+
+// presetYAML returns a preset's steps and checks as the arbor.yaml
+// sections they correspond to.
+func presetYAML(preset presets.Preset) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: fmt.Sprintf(
+		"Built-in %s preset. Its scaffold steps run before scaffold.steps unless\n"+
+			"scaffold.override is set, its cleanup steps before cleanup.steps, and its\n"+
+			"checks when arbor.yaml sets none.", preset.Name())}
+	sections := []struct {
+		key, list string
+		value     interface{}
+	}{
+		{"scaffold", "steps", preset.DefaultSteps()},
+		{"cleanup", "steps", preset.CleanupSteps()},
+		{"checks", "", preset.DefaultChecks()},
+	}
+	for _, section := range sections {
+		value, err := config.EncodeNode(section.value)
+		if err != nil {
+			return nil, err
+		}
+		if section.list != "" {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: section.list}, value,
+			}}
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: section.key}, value)
+	}
+	return config.MarshalYAML(root)
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetShowCmd)
+	presetCmd.AddCommand(presetEjectCmd)
+
+	presetShowCmd.Flags().StringP("output", "o", "", "Write the YAML to a file instead of printing it")
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/presets"
+)
+
+func TestPresetYAML_LoadsBackAsTheSameSteps(t *testing.T) {
+	preset := presets.NewLaravel()
+	out, err := presetYAML(preset)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "arbor.yaml"), out, 0644))
+	cfg, err := config.LoadProject(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, preset.DefaultSteps(), cfg.Scaffold.Steps)
+	assert.Equal(t, preset.DefaultChecks(), cfg.Checks)
+	require.Len(t, cfg.Cleanup.Steps, len(preset.CleanupSteps()))
+	assert.Equal(t, "db.destroy", cfg.Cleanup.Steps[1].Name)
+}
+
+func TestEjectedSteps(t *testing.T) {
+	disabled := false
+	presetSteps := []config.StepConfig{
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "node.npm", Args: []string{"run", "build"}},
+	}
+
+	steps := ejectedSteps(presetSteps, config.PresetOverrides{"node.npm ci": {Enabled: &disabled}})
+
+	assert.Equal(t, &disabled, steps[0].Enabled)
+	assert.Nil(t, steps[1].Enabled)
+	assert.Nil(t, presetSteps[0].Enabled, "the preset's steps are left unchanged")
+}
+
+func TestBuiltInPreset_Unknown(t *testing.T) {
+	_, err := builtInPreset(presets.NewManager(), "rails")
+
+	assert.EqualError(t, err, `unknown preset "rails" (available: laravel, php)`)
+}
@@ -1,20 +1,28 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/concurrency"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/trash"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
-	Short: "Remove merged worktrees",
+	Short: i18n.T("cmd.prune.short"),
 	Long: `Removes merged worktrees automatically.
 
 Lists all worktrees, identifies merged ones, and provides an
@@ -29,6 +37,24 @@ interactive review before removal.`,
 		dryRun := mustGetBool(cmd, "dry-run")
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
+		jsonOutput := mustGetBool(cmd, "json")
+		porcelain := mustGetBool(cmd, "porcelain")
+		useTrash := trashEnabled(cmd, pc)
+
+		if jsonOutput && porcelain {
+			return fmt.Errorf("--json and --porcelain cannot be used together")
+		}
+		// machine is set when stdout carries structured output, so the
+		// human-readable messages are suppressed.
+		machine := jsonOutput || porcelain
+		if machine && !force && !dryRun {
+			return fmt.Errorf("--json and --porcelain require --force or --dry-run")
+		}
+		if machine {
+			quiet = true
+		}
+
+		purgeExpiredTrash(pc)
 
 		worktrees, err := git.ListWorktrees(pc.BarePath)
 		if err != nil {
@@ -36,36 +62,66 @@ interactive review before removal.`,
 		}
 
 		var removable []git.Worktree
+		var entries []pruneEntry
+
+		// Merge checks are independent git commands, so they run in
+		// parallel; results are reported in worktree order below.
+		batch := git.NewBatch(pc.BarePath)
+		checks, _ := concurrency.Map(worktrees, 0, func(wt git.Worktree) (pruneMergeCheck, error) {
+			if wt.Branch == pc.DefaultBranch || wt.Branch == "(bare)" || wt.Detached {
+				return pruneMergeCheck{}, nil
+			}
+			merged, err := batch.IsAncestor(wt.Branch, pc.DefaultBranch)
+			return pruneMergeCheck{merged: merged, err: err}, nil
+		})
+		_ = batch.Close()
 
-		for _, wt := range worktrees {
-			if wt.Branch == pc.DefaultBranch || wt.Branch == "(bare)" {
-				ui.PrintInfo(fmt.Sprintf("%s at %s", wt.Branch, wt.Path))
+		for i, wt := range worktrees {
+			// Detached worktrees have no branch to be merged.
+			if wt.Branch == pc.DefaultBranch || wt.Branch == "(bare)" || wt.Detached {
+				entries = append(entries, pruneEntry{Status: pruneStatusKept, Branch: wt.Label(), Path: wt.Path})
+				if !machine {
+					ui.PrintInfo(fmt.Sprintf("%s at %s", wt.Label(), wt.Path))
+				}
 				continue
 			}
 
-			merged, err := git.IsMerged(pc.BarePath, wt.Branch, pc.DefaultBranch)
+			merged, err := checks[i].merged, checks[i].err
 			if err != nil {
-				ui.PrintErrorWithHint(fmt.Sprintf("Error checking %s", wt.Branch), err.Error())
+				entries = append(entries, pruneEntry{Status: pruneStatusError, Branch: wt.Branch, Path: wt.Path})
+				if !machine {
+					ui.PrintErrorWithHint(i18n.T("hint.check_failed", wt.Branch), err.Error())
+				}
 				continue
 			}
 
 			if merged {
 				removable = append(removable, wt)
-				ui.PrintSuccess(fmt.Sprintf("%s is merged", wt.Branch))
+				if !machine {
+					ui.PrintSuccess(fmt.Sprintf("%s is merged", wt.Branch))
+				}
 			} else {
-				ui.PrintInfo(fmt.Sprintf("%s is not merged", wt.Branch))
+				entries = append(entries, pruneEntry{Status: pruneStatusUnmerged, Branch: wt.Branch, Path: wt.Path})
+				if !machine {
+					ui.PrintInfo(fmt.Sprintf("%s is not merged", wt.Branch))
+				}
 			}
 		}
 
 		if len(removable) == 0 {
+			if machine {
+				return printPruneResult(os.Stdout, entries, jsonOutput)
+			}
 			ui.PrintDone("No merged worktrees to remove.")
 			return nil
 		}
 
-		ui.PrintInfo(fmt.Sprintf("%d merged worktree(s) found.", len(removable)))
+		if !machine {
+			ui.PrintInfo(fmt.Sprintf("%d merged worktree(s) found.", len(removable)))
+		}
 
 		var toRemove []git.Worktree
-		if force {
+		if force || machine {
 			toRemove = removable
 		} else {
 			selected, err := ui.SelectWorktreesToPrune(removable)
@@ -89,46 +145,212 @@ interactive review before removal.`,
 			}
 		}
 
-		ui.PrintInfo(fmt.Sprintf("Removing %d worktree(s):", len(toRemove)))
-		for _, wt := range toRemove {
-			ui.PrintSuccessPath("Removed", wt.Path)
+		if !machine {
+			ui.PrintInfo(fmt.Sprintf("Removing %d worktree(s):", len(toRemove)))
+			for _, wt := range toRemove {
+				ui.PrintSuccessPath("Removed", wt.Path)
+			}
 		}
 
 		for _, wt := range toRemove {
-			ui.PrintStep(fmt.Sprintf("Removing %s...", wt.Branch))
+			preset := pc.Config.Preset
+			if preset == "" {
+				preset = pc.PresetManager().Detect(wt.Path)
+			}
 
-			if !dryRun {
-				preset := pc.Config.Preset
-				if preset == "" {
-					preset = pc.PresetManager().Detect(wt.Path)
-				}
+			siteName := filepath.Base(wt.Path)
+			promptMode := types.PromptMode{
+				Interactive:   ui.IsInteractive() && !machine,
+				NoInteractive: machine,
+				Force:         false,
+				CI:            os.Getenv("CI") != "",
+				Trust:         mustGetBool(cmd, "trust"),
+			}
+
+			if !machine {
+				ui.PrintStep(fmt.Sprintf("Removing %s...", wt.Branch))
+			}
 
-				siteName := filepath.Base(wt.Path)
-				promptMode := types.PromptMode{
-					Interactive:   ui.IsInteractive(),
-					NoInteractive: false,
-					Force:         false,
-					CI:            os.Getenv("CI") != "",
+			if dryRun {
+				entry := pruneEntry{Status: pruneStatusWouldRemove, Branch: wt.Branch, Path: wt.Path}
+				planned, err := pc.ScaffoldManager().PlanWorktreeCleanup(wt.Path, wt.Branch, pc.Config, scaffold.CleanupOptions{
+					SiteName:   siteName,
+					Preset:     preset,
+					BarePath:   pc.BarePath,
+					PromptMode: promptMode,
+				})
+				if err != nil {
+					ui.PrintWarning(fmt.Sprintf("Could not plan cleanup for %s: %v", wt.Branch, err))
 				}
-				if err := pc.ScaffoldManager().RunCleanup(wt.Path, wt.Branch, "", siteName, preset, pc.Config, pc.BarePath, promptMode, false, verbose, quiet); err != nil {
-					ui.PrintErrorWithHint("Cleanup failed", err.Error())
+				entry.addResources(planned)
+				entries = append(entries, entry)
+				if !machine {
+					ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would remove %s and run cleanup", wt.Branch))
+					printPruneResources("[DRY RUN] Would", entry)
 				}
+				continue
+			}
 
-				if err := git.RemoveWorktree(wt.Path, true); err != nil {
-					ui.PrintErrorWithHint(fmt.Sprintf("Error removing %s", wt.Branch), err.Error())
+			entry := pruneEntry{Status: pruneStatusRemoved, Branch: wt.Branch, Path: wt.Path}
+			var removed []types.Resource
+			err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode)
+			if err == nil {
+				removed, err = pc.ScaffoldManager().CleanupWorktree(wt.Path, wt.Branch, pc.Config, scaffold.CleanupOptions{
+					SiteName:   siteName,
+					Preset:     preset,
+					BarePath:   pc.BarePath,
+					PromptMode: promptMode,
+					Verbose:    verbose,
+					Quiet:      quiet,
+					Sandbox:    sandboxSteps,
+				})
+			}
+			entry.addResources(removed)
+			if err != nil && !machine {
+				ui.PrintErrorWithHint(i18n.T("hint.cleanup_failed"), err.Error())
+			}
+
+			if useTrash {
+				entry.Status = pruneStatusTrashed
+				if _, err := trash.Move(pc.ProjectPath, pc.BarePath, wt, time.Now()); err != nil {
+					entry.Status = pruneStatusError
+					entries = append(entries, entry)
+					if !machine {
+						ui.PrintErrorWithHint(i18n.T("hint.remove_failed", wt.Branch), err.Error())
+					}
+					continue
 				}
-			} else {
-				ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would remove %s and run cleanup", wt.Branch))
+			} else if err := git.RemoveWorktree(wt.Path, true); err != nil {
+				entry.Status = pruneStatusError
+				entries = append(entries, entry)
+				if !machine {
+					ui.PrintErrorWithHint(i18n.T("hint.remove_failed", wt.Branch), err.Error())
+				}
+				continue
+			}
+			forgetWorktree(pc, wt.Path)
+			entries = append(entries, entry)
+			if !machine {
+				printPruneResources("", entry)
 			}
 		}
 
+		if machine {
+			return printPruneResult(os.Stdout, entries, jsonOutput)
+		}
+
 		ui.PrintDone("Done.")
 		return nil
 	},
 }
 
+// Prune porcelain statuses, the first column of `arbor prune --porcelain`.
+const (
+	pruneStatusKept        = "kept"
+	pruneStatusUnmerged    = "unmerged"
+	pruneStatusRemoved     = "removed"
+	pruneStatusTrashed     = "trashed"
+	pruneStatusWouldRemove = "would-remove"
+	pruneStatusError       = "error"
+)
+
+// pruneEntry records what prune did, or would do, with a single worktree.
+type pruneEntry struct {
+	Status    string   `json:"status"`
+	Branch    string   `json:"branch"`
+	Path      string   `json:"path"`
+	Databases []string `json:"databases"`
+	HerdLinks []string `json:"herdLinks"`
+}
+
+func (e *pruneEntry) addResources(resources []types.Resource) {
+	for _, r := range resources {
+		switch r.Kind {
+		case types.ResourceDatabase:
+			e.Databases = append(e.Databases, r.Name)
+		case types.ResourceHerdLink:
+			e.HerdLinks = append(e.HerdLinks, r.Name)
+		}
+	}
+}
+
+// printPruneResources lists the databases and Herd links removed with a
+// worktree. prefix is prepended for dry runs.
+func printPruneResources(prefix string, e pruneEntry) {
+	for _, db := range e.Databases {
+		if prefix != "" {
+			ui.PrintInfo(fmt.Sprintf("%s drop database %s", prefix, db))
+		} else {
+			ui.PrintSuccess(fmt.Sprintf("Dropped database %s", db))
+		}
+	}
+	for _, link := range e.HerdLinks {
+		if prefix != "" {
+			ui.PrintInfo(fmt.Sprintf("%s unlink Herd site %s", prefix, link))
+		} else {
+			ui.PrintSuccess(fmt.Sprintf("Unlinked Herd site %s", link))
+		}
+	}
+}
+
+func printPruneResult(w io.Writer, entries []pruneEntry, jsonOutput bool) error {
+	if jsonOutput {
+		return printPruneJSON(w, entries)
+	}
+	return printPrunePorcelain(w, entries)
+}
+
+// printPruneJSON writes entries as a JSON array. Resource lists are always
+// arrays, never null, so consumers need no special-casing.
+func printPruneJSON(w io.Writer, entries []pruneEntry) error {
+	out := make([]pruneEntry, len(entries))
+	for i, e := range entries {
+		if e.Databases == nil {
+			e.Databases = []string{}
+		}
+		if e.HerdLinks == nil {
+			e.HerdLinks = []string{}
+		}
+		out[i] = e
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// printPrunePorcelain writes one tab-separated line per worktree: status,
+// branch, path, databases, Herd links. List columns are comma-separated
+// and "-" when empty. See "Porcelain output" in the README.
+func printPrunePorcelain(w io.Writer, entries []pruneEntry) error {
+	for _, e := range entries {
+		fields := []string{e.Status, e.Branch, e.Path, porcelainList(e.Databases), porcelainList(e.HerdLinks)}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func porcelainList(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
+
+// pruneMergeCheck is whether a worktree's branch is merged, or why that
+// could not be checked.
+type pruneMergeCheck struct {
+	merged bool
+	err    error
+}
+
 func init() {
 	rootCmd.AddCommand(pruneCmd)
 
 	pruneCmd.Flags().BoolP("force", "f", false, "Skip interactive confirmation")
+	pruneCmd.Flags().Bool("trash", false, "Move worktrees to .arbor/trash so 'arbor undo' can restore them")
+	pruneCmd.Flags().Bool("json", false, "Output removed worktrees and resources as JSON (requires --force or --dry-run)")
+	pruneCmd.Flags().Bool("porcelain", false, "Machine-parseable tab-separated output (requires --force or --dry-run)")
 }
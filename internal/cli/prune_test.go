@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestPrintPrunePorcelain(t *testing.T) {
+	entries := []pruneEntry{
+		{Status: pruneStatusKept, Branch: "main", Path: "/test/main"},
+		{Status: pruneStatusUnmerged, Branch: "feature/wip", Path: "/test/feature-wip"},
+		{Status: pruneStatusError, Branch: "broken", Path: "/test/broken"},
+		{Status: pruneStatusRemoved, Branch: "feature/done", Path: "/test/feature-done", Databases: []string{"app_swift_runner", "app_testing_swift_runner"}, HerdLinks: []string{"feature-done"}},
+		{Status: pruneStatusWouldRemove, Branch: "fix", Path: "/test/with space/fix", Databases: []string{"app_calm_river"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, printPrunePorcelain(&buf, entries))
+	assertGolden(t, "prune_porcelain", buf.String())
+}
+
+func TestPrintPruneJSON(t *testing.T) {
+	entries := []pruneEntry{
+		{Status: pruneStatusKept, Branch: "main", Path: "/test/main"},
+		{Status: pruneStatusRemoved, Branch: "feature/done", Path: "/test/feature-done", Databases: []string{"app_swift_runner"}, HerdLinks: []string{"feature-done"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, printPruneJSON(&buf, entries))
+	assertGolden(t, "prune_json", buf.String())
+}
+
+func TestPrintPruneJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printPruneJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestPruneEntry_AddResources(t *testing.T) {
+	var entry pruneEntry
+	entry.addResources([]types.Resource{
+		{Kind: types.ResourceDatabase, Name: "app_swift_runner"},
+		{Kind: types.ResourceHerdLink, Name: "feature"},
+		{Kind: types.ResourceDatabase, Name: "app_testing_swift_runner"},
+	})
+
+	assert.Equal(t, []string{"app_swift_runner", "app_testing_swift_runner"}, entry.Databases)
+	assert.Equal(t, []string{"feature"}, entry.HerdLinks)
+}
@@ -5,21 +5,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var pullConfigCmd = &cobra.Command{
 	Use:   "pull-config",
-	Short: "Update project config from the default branch worktree",
+	Short: i18n.T("cmd.pull-config.short"),
 	Long: `Copies arbor.yaml from the default branch worktree to the project root.
 
 Use this command when the repository arbor.yaml (committed in the default branch)
 has been updated and you want to pull those changes into the project-level config.
 
-This replaces the project arbor.yaml entirely with the one from the default branch worktree.`,
+This replaces the project arbor.yaml entirely with the one from the default branch worktree.
+The confirmation lists the commands the new config runs, and confirming it trusts
+the config. With --force the config is copied untrusted, so the next scaffold or
+cleanup asks first unless --trust-repo-config is passed.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pc, err := OpenProjectFromCWD()
 		if err != nil {
@@ -61,11 +68,20 @@ This replaces the project arbor.yaml entirely with the one from the default bran
 			return nil
 		}
 
+		trusted := trustRepoConfig
 		if !force {
 			if verbose && !quiet {
 				ui.PrintStep(fmt.Sprintf("Pull config from %s/arbor.yaml to project arbor.yaml?", pc.DefaultBranch))
 			}
-			confirmed, err := ui.Confirm(fmt.Sprintf("Pull config from %s/arbor.yaml to project arbor.yaml?", pc.DefaultBranch))
+			sourceCfg, err := config.LoadProject(filepath.Dir(sourcePath))
+			if err != nil {
+				return fmt.Errorf("loading %s: %w", sourcePath, err)
+			}
+			description := "It runs no commands."
+			if commands := configCommands(sourceCfg); len(commands) > 0 {
+				description = "It runs:\n  " + strings.Join(ui.TruncateList(commands, trustListLimit), "\n  ")
+			}
+			confirmed, err := ui.ConfirmWithDefault(fmt.Sprintf("Pull config from %s/arbor.yaml to project arbor.yaml?", pc.DefaultBranch), description, false)
 			if err != nil {
 				return fmt.Errorf("confirmation prompt: %w", err)
 			}
@@ -75,16 +91,25 @@ This replaces the project arbor.yaml entirely with the one from the default bran
 				}
 				return nil
 			}
+			trusted = true
 		}
 
 		if verbose && !quiet {
 			ui.PrintStep(fmt.Sprintf("Copying config from %s worktree to project root", pc.DefaultBranch))
 		}
 
-		if err := os.WriteFile(destPath, sourceBytes, 0644); err != nil {
+		if err := config.WriteConfigFile(destPath, sourceBytes); err != nil {
 			return fmt.Errorf("writing project config: %w", err)
 		}
 
+		record := config.TrustRecord{}
+		if trusted {
+			record = config.TrustRecord{ConfigHash: config.HashConfig(sourceBytes), TrustedAt: time.Now().UTC()}
+		}
+		if err := config.WriteTrustRecord(pc.ProjectPath, record); err != nil {
+			return err
+		}
+
 		if !quiet {
 			ui.PrintSuccess("Project config updated")
 		}
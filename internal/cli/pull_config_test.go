@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
 )
 
@@ -108,6 +109,12 @@ func TestPullConfig_Success(t *testing.T) {
 	projectConfigBytes, err := os.ReadFile(filepath.Join(projectDir, "arbor.yaml"))
 	assert.NoError(t, err)
 	assert.Equal(t, worktreeConfig, string(projectConfigBytes))
+
+	record, err := config.ReadTrustRecord(projectDir)
+	assert.NoError(t, err)
+	if assert.NotNil(t, record, "a pulled config is marked as coming from the repository") {
+		assert.False(t, record.Trusts(projectConfigBytes), "--force copies the config untrusted")
+	}
 }
 
 func TestPullConfig_NoWorktreeConfig(t *testing.T) {
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: i18n.T("cmd.push.short"),
+	Long: `Pushes the current worktree branch to the branch of the same name on
+origin and sets it as the upstream (git push -u origin HEAD).
+
+After 'arbor sync' rebases a branch that was already pushed, push it with
+--force-with-lease, which only overwrites the remote branch if nobody else
+pushed to it since the last fetch.
+
+Pushing the default branch, or a branch matching push.protected in
+arbor.yaml, asks for confirmation first; use --yes to skip it.
+
+--open opens the page for creating a pull or merge request afterwards.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		if err := pc.MustBeInWorktree(); err != nil {
+			return fmt.Errorf("push must be run from within a worktree: %w", err)
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+		remote := mustGetString(cmd, "remote")
+		forceWithLease := mustGetBool(cmd, "force-with-lease")
+		yes := mustGetBool(cmd, "yes")
+		open := mustGetBool(cmd, "open")
+
+		branch, err := git.GetCurrentBranch(pc.CWD)
+		if err != nil {
+			return fmt.Errorf("getting current branch: %w", err)
+		}
+		if branch == "" {
+			return fmt.Errorf("cannot push: worktree is on detached HEAD - please checkout a branch first")
+		}
+
+		if isProtectedBranch(branch, pc.DefaultBranch, pc.Config.Push.Protected) && !yes {
+			if !ui.IsInteractive() {
+				return fmt.Errorf("'%s' is a protected branch - use --yes to push it anyway", branch)
+			}
+			message := fmt.Sprintf("'%s' is a protected branch. Push it to %s?", branch, remote)
+			if forceWithLease {
+				message = fmt.Sprintf("'%s' is a protected branch. Force push it to %s?", branch, remote)
+			}
+			confirmed, err := ui.Confirm(message)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return fmt.Errorf("push aborted")
+			}
+		}
+
+		pushDesc := fmt.Sprintf("%s to %s", branch, remote)
+		if forceWithLease {
+			pushDesc += " (force with lease)"
+		}
+
+		if dryRun {
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would push %s", pushDesc))
+		} else {
+			err := ui.RunWithSpinner(fmt.Sprintf("Pushing %s...", pushDesc), func() error {
+				return git.Push(pc.CWD, remote, forceWithLease)
+			})
+			var rejected *git.PushRejectedError
+			if errors.As(err, &rejected) {
+				ui.PrintErrorWithHint(rejected.Error(), "If you rebased with 'arbor sync', push again with --force-with-lease")
+				return fmt.Errorf("pushing %s: rejected by %s", branch, remote)
+			}
+			if err != nil {
+				return fmt.Errorf("pushing %s: %w", branch, err)
+			}
+			ui.PrintSuccess(fmt.Sprintf("Pushed %s", pushDesc))
+		}
+
+		if open {
+			if err := openCompareURL(pc, remote, branch, dryRun); err != nil {
+				ui.PrintWarning(err.Error())
+			}
+		}
+
+		ui.PrintDone(fmt.Sprintf("Branch '%s' is on %s", branch, remote))
+		return nil
+	},
+}
+
+// isProtectedBranch reports whether branch is the default branch or matches
+// one of the configured patterns.
+func isProtectedBranch(branch, defaultBranch string, patterns []string) bool {
+	if branch == defaultBranch {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// openCompareURL opens the pull or merge request page for branch.
+func openCompareURL(pc *ProjectContext, remote, branch string, dryRun bool) error {
+	remoteURL, err := git.GetRemoteURL(pc.BarePath, remote)
+	if err != nil {
+		return err
+	}
+	compareURL, ok := git.CompareURL(remoteURL, pc.DefaultBranch, branch)
+	if !ok {
+		return fmt.Errorf("no web page known for remote %s (%s)", remote, remoteURL)
+	}
+
+	if dryRun {
+		ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would open %s", compareURL))
+		return nil
+	}
+
+	ui.PrintInfo(fmt.Sprintf("Opening %s", compareURL))
+	return utils.OpenURL(compareURL)
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+
+	pushCmd.Flags().StringP("remote", "r", "origin", "Remote to push to")
+	pushCmd.Flags().Bool("force-with-lease", false, "Overwrite the remote branch if it is where the last fetch saw it (after a rebase)")
+	pushCmd.Flags().BoolP("yes", "y", false, "Push protected branches without confirmation")
+	pushCmd.Flags().Bool("open", false, "Open the pull/merge request page after pushing")
+}
@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProtectedBranch(t *testing.T) {
+	patterns := []string{"release/*", "production"}
+
+	assert.True(t, isProtectedBranch("main", "main", nil))
+	assert.True(t, isProtectedBranch("release/1.2", "main", patterns))
+	assert.True(t, isProtectedBranch("production", "main", patterns))
+	assert.False(t, isProtectedBranch("feature/login", "main", patterns))
+	assert.False(t, isProtectedBranch("release/1.2/hotfix", "main", patterns), "* does not cross slashes")
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+var recycleCmd = &cobra.Command{
+	Use:   "recycle WORKTREE NEW_BRANCH",
+	Short: i18n.T("cmd.recycle.short"),
+	Long: `Reuses an existing worktree, such as one whose branch was merged, for a new
+branch. On large projects this is much faster than removing the worktree
+and creating another, since vendor, node_modules and other ignored files
+are kept.
+
+The command will:
+1. Run the worktree's cleanup steps, e.g. dropping its databases and
+   unlinking its Herd site
+2. Check out NEW_BRANCH, created from --base (the default branch unless
+   set), in the worktree
+3. Delete the old branch when it is merged into the base; other branches
+   are kept
+4. Rename the worktree folder after NEW_BRANCH, if it was named after the
+   old branch
+5. Scaffold the worktree, skipping the dependency installs (composer
+   install, npm ci, ...) whose lockfile is the same on the new branch;
+   --full runs every step
+
+The worktree must have no uncommitted changes. The default branch's
+worktree cannot be recycled.
+
+Arguments:
+  WORKTREE    Folder name or branch of the worktree to recycle, or part of
+              one when it matches a single worktree
+  NEW_BRANCH  Name of the branch to create`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+		verbose := mustGetBool(cmd, "verbose")
+		quiet := mustGetBool(cmd, "quiet")
+		force := mustGetBool(cmd, "force")
+
+		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+		target, err := matchWorktree(worktrees, pc.ProjectPath, args[0])
+		if err != nil {
+			return err
+		}
+		if target.IsMain {
+			return fmt.Errorf("cannot recycle the default branch's worktree")
+		}
+
+		newBranch := args[1]
+		if err := git.ValidateBranchName(newBranch); err != nil {
+			return err
+		}
+		if git.BranchExists(pc.BarePath, newBranch) {
+			return fmt.Errorf("branch '%s' already exists", newBranch)
+		}
+		base := mustGetString(cmd, "base")
+		if base == "" {
+			base = pc.DefaultBranch
+		}
+		if _, err := git.ResolveCommit(pc.BarePath, base); err != nil {
+			return fmt.Errorf("base %q not found (expected a branch, remote branch, tag or commit)", base)
+		}
+
+		dirty, err := git.IsWorktreeDirty(target.Path)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%s has uncommitted changes; commit, stash or discard them before recycling it", filepath.Base(target.Path))
+		}
+
+		newPath := target.Path
+		if dirMatchesBranch(*target) {
+			newPath = filepath.Join(filepath.Dir(target.Path), utils.SanitisePath(newBranch))
+		}
+		if newPath != target.Path {
+			if _, err := os.Lstat(newPath); err == nil {
+				return fmt.Errorf("cannot rename worktree folder: %s already exists", newPath)
+			}
+		}
+
+		deleteOld := false
+		if !target.Detached {
+			if deleteOld, err = git.IsMerged(pc.BarePath, target.Branch, base); err != nil {
+				return err
+			}
+		}
+
+		if !force && !dryRun {
+			if !ui.IsInteractive() {
+				return fmt.Errorf("recycling a worktree requires confirmation (use --force to skip)")
+			}
+			ui.PrintInfo("This will run the worktree's cleanup steps.")
+			confirmed, err := ui.Confirm(fmt.Sprintf("Recycle '%s' for '%s'?", target.Label(), newBranch))
+			if err != nil {
+				return fmt.Errorf("confirmation: %w", err)
+			}
+			if !confirmed {
+				ui.PrintInfo("Cancelled.")
+				return nil
+			}
+		}
+
+		promptMode := types.PromptMode{
+			Interactive:   ui.IsInteractive(),
+			NoInteractive: mustGetBool(cmd, "no-interactive"),
+			Force:         force,
+			CI:            os.Getenv("CI") != "",
+			Trust:         mustGetBool(cmd, "trust"),
+		}
+		oldHashes := lockfileHashes(target.Path)
+
+		ui.PrintStep(fmt.Sprintf("Recycling %s for '%s'", target.Label(), newBranch))
+
+		if dryRun {
+			ui.PrintInfo("[DRY RUN] Would run cleanup")
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would check out '%s' from '%s'", newBranch, base))
+			if deleteOld {
+				ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would delete merged branch '%s'", target.Branch))
+			}
+			if newPath != target.Path {
+				ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would move %s to %s", target.Path, newPath))
+			}
+			ui.PrintInfo("[DRY RUN] Would scaffold the worktree")
+			ui.PrintDone("Dry run complete")
+			return nil
+		}
+
+		// Cleanup runs against the old branch, before anything changes, so
+		// a failed cleanup leaves the worktree as it was. The checkout runs
+		// after it and can still fail, for example when a cleanup step
+		// edited a tracked file; the worktree is then on its old branch
+		// without the resources the cleanup removed.
+		if err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode); err != nil {
+			return err
+		}
+		opts := scaffold.CleanupOptions(scaffoldRunOptions(pc, pc.Config, *target))
+		opts.PromptMode = promptMode
+		opts.Verbose = verbose
+		opts.Quiet = quiet
+		if _, err := pc.ScaffoldManager().CleanupWorktree(target.Path, scaffoldBranch(*target), pc.Config, opts); err != nil {
+			ui.PrintErrorWithHint(i18n.T("hint.cleanup_failed"), err.Error())
+			return fmt.Errorf("cleaning up %s: %w", target.Label(), err)
+		}
+		if err := config.ClearDbState(target.Path); err != nil {
+			return err
+		}
+
+		if err := git.SwitchToNewBranch(target.Path, newBranch, base); err != nil {
+			return fmt.Errorf("checking out '%s' failed after the cleanup ran, so %s is on its old branch without its databases and links; fix the cause and run 'arbor recycle' again, or 'arbor scaffold' to restore them: %w", newBranch, target.Label(), err)
+		}
+		ui.PrintSuccess(fmt.Sprintf("Checked out '%s' from '%s'", newBranch, base))
+		recordBranchParent(pc.BarePath, newBranch, base)
+		if !mustGetBool(cmd, "no-track") {
+			setUpBranchTracking(pc.BarePath, newBranch)
+		}
+
+		if deleteOld {
+			if err := git.DeleteBranch(pc.BarePath, target.Branch, false); err != nil {
+				ui.PrintErrorWithHint(i18n.T("hint.delete_branch_failed"), err.Error())
+			} else {
+				ui.PrintSuccess(fmt.Sprintf("Deleted merged branch '%s'", target.Branch))
+			}
+		} else if !target.Detached {
+			ui.PrintInfo(fmt.Sprintf("Kept branch '%s': it is not merged into '%s'", target.Branch, base))
+		}
+
+		if newPath != target.Path {
+			if err := relocateWorktree(pc, target.Path, newPath, false); err != nil {
+				ui.PrintWarning(fmt.Sprintf("Worktree recycled, but its folder was not renamed: %v", err))
+				newPath = target.Path
+			} else if isWithinDir(pc.CWD, target.Path) {
+				ui.PrintInfo(fmt.Sprintf("Your shell is still in the old folder; run: cd %s", newPath))
+			}
+		}
+
+		recycled := git.Worktree{Path: newPath, Branch: newBranch}
+		cfg := pc.Config
+		if !mustGetBool(cmd, "full") {
+			var skipped []string
+			cfg, skipped = recycleConfig(pc.ScaffoldManager().StepConfigsForWorktree(pc.Config, newPath), unchangedLockfiles(oldHashes, lockfileHashes(newPath)), pc.Config)
+			if len(skipped) > 0 && !quiet {
+				ui.PrintInfo(fmt.Sprintf("Skipping %s: lockfiles unchanged (use --full to run them)", strings.Join(skipped, ", ")))
+			}
+		}
+		if err := scaffoldWorktree(pc, cfg, recycled, promptMode, false, verbose, quiet); err != nil {
+			return err
+		}
+
+		if state, err := config.ReadLocalState(newPath); err == nil && len(state.LockfileHashes) > 0 {
+			// The installs above are done, so the daemon need not repeat them.
+			if err := config.SetLockfileHashes(newPath, lockfileHashes(newPath)); err != nil {
+				ui.PrintWarning(err.Error())
+			}
+		}
+
+		ui.PrintDone(fmt.Sprintf("Worktree ready at %s", newPath))
+		return nil
+	},
+}
+
+// unchangedLockfiles returns the lockfiles present with the same content
+// before and after the worktree switched branches.
+func unchangedLockfiles(before, after map[string]string) map[string]bool {
+	unchanged := make(map[string]bool)
+	for file, hash := range after {
+		if before[file] == hash {
+			unchanged[file] = true
+		}
+	}
+	return unchanged
+}
+
+// recycleConfig returns a copy of cfg that runs stepConfigs except the
+// dependency installs whose lockfile is unchanged, since the dependencies
+// they install are already in place. It also returns the skipped steps.
+func recycleConfig(stepConfigs []config.StepConfig, unchanged map[string]bool, cfg *config.Config) (*config.Config, []string) {
+	filtered := *cfg
+	filtered.Scaffold.Override = true
+	filtered.Scaffold.Steps = nil
+
+	var skipped []string
+	for _, stepConfig := range stepConfigs {
+		if installsFrom(stepConfig, unchanged) {
+			skipped = append(skipped, strings.TrimSpace(stepConfig.Name+" "+strings.Join(stepConfig.Args, " ")))
+			continue
+		}
+		filtered.Scaffold.Steps = append(filtered.Scaffold.Steps, stepConfig)
+	}
+	return &filtered, skipped
+}
+
+func init() {
+	rootCmd.AddCommand(recycleCmd)
+
+	recycleCmd.Flags().StringP("base", "b", "", "Base for the new branch: a branch, remote branch, tag or commit (default: the default branch)")
+	recycleCmd.Flags().BoolP("force", "f", false, "Skip the confirmation prompt")
+	recycleCmd.Flags().Bool("full", false, "Run every scaffold step, including dependency installs whose lockfile is unchanged")
+	recycleCmd.Flags().Bool("no-track", false, "Skip setting up remote tracking for the new branch")
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+// setupRecycleProject creates a project with a main and a feature
+// worktree, and an "other" branch whose README differs from main's.
+func setupRecycleProject(t *testing.T, projectConfig string) (string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	projectDir := filepath.Join(tmpDir, "project")
+	barePath := filepath.Join(projectDir, ".bare")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+
+	runGitCmd(t, repoDir, "init", "-b", "main")
+	runGitCmd(t, repoDir, "config", "user.email", "test@example.com")
+	runGitCmd(t, repoDir, "config", "user.name", "Test User")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("main\n"), 0644))
+	runGitCmd(t, repoDir, "add", ".")
+	runGitCmd(t, repoDir, "commit", "-m", "Initial commit")
+	runGitCmd(t, repoDir, "checkout", "-b", "other")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("other\n"), 0644))
+	runGitCmd(t, repoDir, "commit", "-am", "Change README")
+	runGitCmd(t, repoDir, "checkout", "main")
+	runGitCmd(t, repoDir, "clone", "--bare", repoDir, barePath)
+
+	require.NoError(t, git.CreateWorktree(barePath, filepath.Join(projectDir, "main"), "main", ""))
+	featurePath := filepath.Join(projectDir, "feature")
+	require.NoError(t, git.CreateWorktree(barePath, featurePath, "feature", "main"))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "arbor.yaml"), []byte(projectConfig), 0644))
+	return projectDir, featurePath
+}
+
+func runRecycle(t *testing.T, dir string, args []string, base string) error {
+	t.Helper()
+	cmd := &cobra.Command{}
+	for _, name := range []string{"dry-run", "verbose", "quiet", "no-interactive", "trust", "full"} {
+		cmd.Flags().Bool(name, false, "")
+	}
+	cmd.Flags().Bool("force", true, "")
+	cmd.Flags().Bool("no-track", true, "")
+	cmd.Flags().String("base", base, "")
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(dir))
+
+	return recycleCmd.RunE(cmd, args)
+}
+
+func TestRecycleCmd(t *testing.T) {
+	projectDir, featurePath := setupRecycleProject(t, "default_branch: main\npreset: \"\"\n")
+
+	require.NoError(t, runRecycle(t, projectDir, []string{"feature", "billing"}, ""))
+
+	newPath := filepath.Join(projectDir, "billing")
+	assert.NoDirExists(t, featurePath, "the folder named after the old branch is renamed")
+	branch, err := git.GetCurrentBranch(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", branch)
+	assert.False(t, git.BranchExists(filepath.Join(projectDir, ".bare"), "feature"), "the merged old branch is deleted")
+}
+
+func TestRecycleCmd_CheckoutFailsAfterCleanup(t *testing.T) {
+	// The cleanup step edits a tracked file the new base changes too, so
+	// git refuses the checkout after the cleanup already ran.
+	projectDir, featurePath := setupRecycleProject(t, `default_branch: main
+preset: ""
+cleanup:
+  steps:
+    - name: bash.run
+      condition:
+        command: echo cleaned > README.md
+`)
+
+	err := runRecycle(t, projectDir, []string{"feature", "billing"}, "other")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed after the cleanup ran")
+	assert.Contains(t, err.Error(), "run 'arbor recycle' again")
+	branch, err := git.GetCurrentBranch(featurePath)
+	require.NoError(t, err)
+	assert.Equal(t, "feature", branch, "the worktree stays on its old branch")
+	assert.False(t, git.BranchExists(filepath.Join(projectDir, ".bare"), "billing"))
+}
+
+func TestUnchangedLockfiles(t *testing.T) {
+	before := map[string]string{"composer.lock": "a", "package-lock.json": "b", "yarn.lock": "c"}
+	after := map[string]string{"composer.lock": "a", "package-lock.json": "changed", "bun.lock": "d"}
+
+	assert.Equal(t, map[string]bool{"composer.lock": true}, unchangedLockfiles(before, after))
+}
+
+func TestRecycleConfig(t *testing.T) {
+	stepConfigs := []config.StepConfig{
+		{Name: "php.composer", Args: []string{"install"}},
+		{Name: "php.composer", Args: []string{"dump-autoload"}},
+		{Name: "db.create"},
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "node.npm", Args: []string{"run", "build"}},
+		{Name: "node.yarn"},
+	}
+	cfg := &config.Config{Scaffold: config.ScaffoldConfig{Strict: true, Steps: []config.StepConfig{{Name: "db.create"}}}}
+
+	filtered, skipped := recycleConfig(stepConfigs, map[string]bool{"composer.lock": true, "yarn.lock": true}, cfg)
+
+	assert.Equal(t, []config.StepConfig{
+		{Name: "php.composer", Args: []string{"dump-autoload"}},
+		{Name: "db.create"},
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "node.npm", Args: []string{"run", "build"}},
+	}, filtered.Scaffold.Steps)
+	assert.Equal(t, []string{"php.composer install", "node.yarn"}, skipped)
+	assert.True(t, filtered.Scaffold.Override, "preset steps are already in stepConfigs")
+	assert.True(t, filtered.Scaffold.Strict)
+	assert.Len(t, cfg.Scaffold.Steps, 1, "cfg is left alone")
+}
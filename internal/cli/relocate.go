@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// siteLink is a Herd or Valet site linked to a worktree folder.
+type siteLink struct {
+	Tool   string
+	Name   string
+	Secure bool
+}
+
+// URL returns the address the site is served at.
+func (l siteLink) URL() string {
+	if l.Secure {
+		return "https://" + l.Name + ".test"
+	}
+	return "http://" + l.Name + ".test"
+}
+
+// siteLinkTool is a tool that serves linked folders as <name>.test sites,
+// and the config directories it keeps its Sites and Certificates in.
+type siteLinkTool struct {
+	Binary     string
+	ConfigDirs func(home string) []string
+}
+
+var siteLinkTools = []siteLinkTool{
+	{Binary: "herd", ConfigDirs: func(home string) []string {
+		if runtime.GOOS == "windows" {
+			return []string{filepath.Join(home, ".config", "herd", "config", "valet")}
+		}
+		return []string{filepath.Join(home, "Library", "Application Support", "Herd", "config", "valet")}
+	}},
+	{Binary: "valet", ConfigDirs: func(home string) []string {
+		return []string{filepath.Join(home, ".config", "valet"), filepath.Join(home, ".valet")}
+	}},
+}
+
+// findSiteLinks returns the Herd and Valet sites whose link points at
+// worktreePath. Tools that are not installed are skipped.
+func findSiteLinks(worktreePath string) []siteLink {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	target, err := filepath.EvalSymlinks(worktreePath)
+	if err != nil {
+		target = worktreePath
+	}
+
+	var links []siteLink
+	for _, tool := range siteLinkTools {
+		if _, err := exec.LookPath(tool.Binary); err != nil {
+			continue
+		}
+		for _, dir := range tool.ConfigDirs(home) {
+			entries, err := os.ReadDir(filepath.Join(dir, "Sites"))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				linked, err := filepath.EvalSymlinks(filepath.Join(dir, "Sites", entry.Name()))
+				if err != nil || linked != target {
+					continue
+				}
+				certs, _ := filepath.Glob(filepath.Join(dir, "Certificates", entry.Name()+".*.crt"))
+				links = append(links, siteLink{Tool: tool.Binary, Name: entry.Name(), Secure: len(certs) > 0})
+			}
+		}
+	}
+	return links
+}
+
+// relinkedSiteName returns a site's name after its folder moves: sites
+// named after the old folder follow the folder's new name, others keep
+// theirs.
+func relinkedSiteName(link siteLink, oldPath, newPath string) string {
+	if link.Name == filepath.Base(oldPath) {
+		return filepath.Base(newPath)
+	}
+	return link.Name
+}
+
+// relinkSite unlinks a site and links the worktree's new path in its place.
+func relinkSite(link siteLink, newName, newPath string) error {
+	if output, err := exec.Command(link.Tool, "unlink", link.Name).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s unlink %s failed: %w\n%s", link.Tool, link.Name, err, string(output))
+	}
+
+	args := []string{"link"}
+	if link.Secure {
+		args = append(args, "--secure")
+	}
+	args = append(args, newName)
+	cmd := exec.Command(link.Tool, args...)
+	cmd.Dir = newPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s link %s failed: %w\n%s", link.Tool, newName, err, string(output))
+	}
+	return nil
+}
+
+// updateAppURL points APP_URL in the worktree's env file at newSite when
+// its host is oldSite on a local TLD, e.g. https://old.test to
+// https://new.test. It reports whether the file changed.
+func updateAppURL(worktreePath, envFile, oldSite, newSite string) (bool, error) {
+	envPath := filepath.Join(worktreePath, envFile)
+	content, err := os.ReadFile(envPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", envFile, err)
+	}
+
+	appURL := utils.ParseEnv(content)["APP_URL"]
+	for _, scheme := range []string{"https://", "http://"} {
+		rest, ok := strings.CutPrefix(appURL, scheme+oldSite+".")
+		if !ok {
+			continue
+		}
+
+		updated := utils.SetEnvValue(content, "APP_URL", scheme+newSite+"."+rest)
+		if err := config.WriteEnvFile(envPath, updated); err != nil {
+			return false, fmt.Errorf("writing %s: %w", envFile, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// updateEnvPaths rewrites absolute paths inside oldPath in the worktree's
+// env file, such as an SQLite DB_DATABASE, to the same place under newPath.
+// It reports whether the file changed.
+func updateEnvPaths(worktreePath, envFile, oldPath, newPath string) (bool, error) {
+	envPath := filepath.Join(worktreePath, envFile)
+	content, err := os.ReadFile(envPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", envFile, err)
+	}
+
+	var b strings.Builder
+	rest := string(content)
+	for {
+		i := strings.Index(rest, oldPath)
+		if i < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := i + len(oldPath)
+		b.WriteString(rest[:i])
+		// Only whole paths: /p/feature must not match /p/feature-2
+		if end == len(rest) || strings.ContainsRune("/\\\"'\r\n", rune(rest[end])) {
+			b.WriteString(newPath)
+		} else {
+			b.WriteString(oldPath)
+		}
+		rest = rest[end:]
+	}
+	if b.String() == string(content) {
+		return false, nil
+	}
+
+	if err := config.WriteEnvFile(envPath, []byte(b.String())); err != nil {
+		return false, fmt.Errorf("writing %s: %w", envFile, err)
+	}
+	return true, nil
+}
+
+// moveWorktreeDir moves a worktree with git worktree move, falling back to
+// copying it and repairing git's metadata when newPath is on another
+// filesystem.
+func moveWorktreeDir(barePath, oldPath, newPath string) error {
+	err := git.MoveWorktree(barePath, oldPath, newPath)
+	var crossDevice *git.CrossDeviceMoveError
+	if !errors.As(err, &crossDevice) {
+		return err
+	}
+
+	ui.PrintInfo(fmt.Sprintf("%s is on another filesystem; copying the worktree", filepath.Dir(newPath)))
+	if err := utils.CopyDir(oldPath, newPath); err != nil {
+		_ = os.RemoveAll(newPath)
+		return err
+	}
+	if err := git.RepairWorktree(barePath, newPath); err != nil {
+		_ = os.RemoveAll(newPath)
+		return err
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Worktree copied, but %s could not be removed: %v", oldPath, err))
+	}
+	return nil
+}
+
+// relocateWorktree moves a worktree folder to newPath and carries its
+// Herd/Valet links, paths in its env file and .arbor.local, its project record and,
+// when the folder is renamed, its APP_URL along. Failures after the move are
+// warnings: the worktree itself is in place.
+func relocateWorktree(pc *ProjectContext, oldPath, newPath string, dryRun bool) error {
+	links := findSiteLinks(oldPath)
+
+	if dryRun {
+		ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would move %s to %s", oldPath, newPath))
+		for _, link := range links {
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would relink %s site '%s' as '%s'", link.Tool, link.Name, relinkedSiteName(link, oldPath, newPath)))
+		}
+		return nil
+	}
+
+	if err := moveWorktreeDir(pc.BarePath, oldPath, newPath); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Moved %s to %s", oldPath, newPath))
+
+	for _, link := range links {
+		newName := relinkedSiteName(link, oldPath, newPath)
+		if err := relinkSite(link, newName, newPath); err != nil {
+			ui.PrintWarning(err.Error())
+			continue
+		}
+		ui.PrintSuccess(fmt.Sprintf("Relinked %s site '%s' as '%s'", link.Tool, link.Name, newName))
+	}
+
+	// Sites are named after the folder, so APP_URL follows the new name
+	oldSite, newSite := filepath.Base(oldPath), filepath.Base(newPath)
+	if oldSite != newSite {
+		updated, err := updateAppURL(newPath, pc.Config.PrimaryEnvFile(), oldSite, newSite)
+		if err != nil {
+			ui.PrintWarning(err.Error())
+		} else if updated {
+			ui.PrintSuccess(fmt.Sprintf("Updated APP_URL to the '%s' site", newSite))
+		}
+	}
+
+	envFile := pc.Config.PrimaryEnvFile()
+	if updated, err := updateEnvPaths(newPath, envFile, oldPath, newPath); err != nil {
+		ui.PrintWarning(err.Error())
+	} else if updated {
+		ui.PrintSuccess(fmt.Sprintf("Updated paths in %s", envFile))
+	}
+	if updated, err := config.RelocateLocalState(newPath, oldPath, newPath); err != nil {
+		ui.PrintWarning(err.Error())
+	} else if updated {
+		ui.PrintSuccess("Updated paths in .arbor.local")
+	}
+	if err := config.MoveWorktreeRecord(pc.ProjectPath, oldPath, newPath); err != nil {
+		ui.PrintWarning(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAppURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    string
+		changed bool
+	}{
+		{"https", "APP_NAME=App\nAPP_URL=https://old-name.test\n", "APP_NAME=App\nAPP_URL=https://new-name.test\n", true},
+		{"http with path", "APP_URL=http://old-name.test/admin\n", "APP_URL=http://new-name.test/admin\n", true},
+		{"quoted", "APP_URL=\"https://old-name.test\"\n", "APP_URL=\"https://new-name.test\"\n", true},
+		{"export", "export APP_URL=https://old-name.test # local\n", "export APP_URL=https://new-name.test # local\n", true},
+		{"other host", "APP_URL=https://old-name-2.test\n", "APP_URL=https://old-name-2.test\n", false},
+		{"no app url", "APP_NAME=old-name\n", "APP_NAME=old-name\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			envPath := filepath.Join(dir, ".env")
+			require.NoError(t, os.WriteFile(envPath, []byte(tt.env), 0600))
+
+			changed, err := updateAppURL(dir, ".env", "old-name", "new-name")
+			require.NoError(t, err)
+			assert.Equal(t, tt.changed, changed)
+
+			content, err := os.ReadFile(envPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestUpdateAppURL_NoEnvFile(t *testing.T) {
+	changed, err := updateAppURL(t.TempDir(), ".env", "old", "new")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestFindSiteLinks(t *testing.T) {
+	configDir := t.TempDir()
+	worktreePath := filepath.Join(t.TempDir(), "feature-login")
+	require.NoError(t, os.MkdirAll(worktreePath, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "Sites"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "Certificates"), 0755))
+
+	if err := os.Symlink(worktreePath, filepath.Join(configDir, "Sites", "feature-login")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	require.NoError(t, os.Symlink(t.TempDir(), filepath.Join(configDir, "Sites", "other")))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "Certificates", "feature-login.test.crt"), nil, 0644))
+
+	// git stands in for herd: any installed binary will do
+	original := siteLinkTools
+	siteLinkTools = []siteLinkTool{{Binary: "git", ConfigDirs: func(string) []string { return []string{configDir} }}}
+	t.Cleanup(func() { siteLinkTools = original })
+
+	links := findSiteLinks(worktreePath)
+	assert.Equal(t, []siteLink{{Tool: "git", Name: "feature-login", Secure: true}}, links)
+}
+
+func TestRelinkedSiteName(t *testing.T) {
+	link := siteLink{Tool: "herd", Name: "feature-old"}
+	assert.Equal(t, "feature-new", relinkedSiteName(link, "/p/feature-old", "/p/feature-new"))
+
+	custom := siteLink{Tool: "herd", Name: "myapp"}
+	assert.Equal(t, "myapp", relinkedSiteName(custom, "/p/feature-old", "/p/feature-new"))
+}
+
+func TestUpdateEnvPaths(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(string(filepath.Separator)+"projects", "app", "feature")
+	newPath := filepath.Join(string(filepath.Separator)+"fast", "feature")
+	env := "DB_CONNECTION=sqlite\n" +
+		"DB_DATABASE=" + filepath.Join(oldPath, "database", "app.sqlite") + "\n" +
+		"STORAGE=\"" + oldPath + "\"\n" +
+		"OTHER=" + oldPath + "-2/storage\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(env), 0600))
+
+	changed, err := updateEnvPaths(dir, ".env", oldPath, newPath)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	content, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "DB_CONNECTION=sqlite\n"+
+		"DB_DATABASE="+filepath.Join(newPath, "database", "app.sqlite")+"\n"+
+		"STORAGE=\""+newPath+"\"\n"+
+		"OTHER="+oldPath+"-2/storage\n", string(content))
+}
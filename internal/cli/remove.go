@@ -4,22 +4,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/trash"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var removeCmd = &cobra.Command{
 	Use:   "remove [FOLDER]",
-	Short: "Remove a worktree with cleanup",
+	Short: i18n.T("cmd.remove.short"),
 	Long: `Removes a worktree and runs preset-defined cleanup steps.
 
 Arguments:
-  FOLDER  Name of the worktree folder to remove (e.g., feature-test-change)
+  FOLDER  Name of the worktree folder to remove (e.g., feature-test-change),
+          or its branch. Without --force, part of a name is accepted when
+          it matches a single worktree.
 
 Cleanup steps may include:
   - Removing Herd site links
@@ -35,6 +41,7 @@ Cleanup steps may include:
 		dryRun := mustGetBool(cmd, "dry-run")
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
+		purgeExpiredTrash(pc)
 
 		currentWorktreePath, err := os.Getwd()
 		if err != nil {
@@ -54,15 +61,18 @@ Cleanup steps may include:
 		var targetWorktree *git.Worktree
 
 		if len(args) > 0 {
-			folderName := args[0]
-			for _, wt := range worktrees {
-				if filepath.Base(wt.Path) == folderName {
-					targetWorktree = &wt
-					break
+			// With --force nothing confirms which worktree a partial name
+			// matched, so only exact names are accepted.
+			if force {
+				targetWorktree = findWorktree(worktrees, pc.ProjectPath, args[0])
+				if targetWorktree == nil {
+					return fmt.Errorf("worktree '%s' not found (--force needs the full folder or branch name): %w", args[0], arborerrors.ErrWorktreeNotFound)
+				}
+			} else {
+				targetWorktree, err = matchWorktree(worktrees, pc.ProjectPath, args[0])
+				if err != nil {
+					return err
 				}
-			}
-			if targetWorktree == nil {
-				return fmt.Errorf("worktree '%s' not found: %w", folderName, arborerrors.ErrWorktreeNotFound)
 			}
 		} else if ui.IsInteractive() {
 			selected, err := ui.SelectWorktreeToRemove(worktrees)
@@ -78,7 +88,8 @@ Cleanup steps may include:
 			return fmt.Errorf("cannot remove main worktree")
 		}
 
-		ui.PrintInfo(fmt.Sprintf("Removing %s at %s", targetWorktree.Branch, targetWorktree.Path))
+		ui.PrintInfo(fmt.Sprintf("Removing %s at %s", targetWorktree.Label(), targetWorktree.Path))
+		useTrash := trashEnabled(cmd, pc)
 
 		deleteBranch := false
 		if !force {
@@ -87,7 +98,7 @@ Cleanup steps may include:
 			}
 
 			ui.PrintInfo("This will run cleanup steps.")
-			confirmed, err := ui.Confirm(fmt.Sprintf("Remove worktree '%s'?", targetWorktree.Branch))
+			confirmed, err := ui.Confirm(fmt.Sprintf("Remove worktree '%s'?", targetWorktree.Label()))
 			if err != nil {
 				return fmt.Errorf("confirmation: %w", err)
 			}
@@ -96,14 +107,14 @@ Cleanup steps may include:
 				return nil
 			}
 
-			if git.BranchExists(pc.BarePath, targetWorktree.Branch) {
+			if !targetWorktree.Detached && git.BranchExists(pc.BarePath, targetWorktree.Branch) {
 				deleteBranch, err = ui.Confirm(fmt.Sprintf("Also delete branch '%s'?", targetWorktree.Branch))
 				if err != nil {
 					return fmt.Errorf("branch deletion confirmation: %w", err)
 				}
 			}
 		} else {
-			deleteBranch = mustGetBool(cmd, "delete-branch")
+			deleteBranch = mustGetBool(cmd, "delete-branch") && !targetWorktree.Detached
 		}
 
 		ui.PrintStep("Removing worktree")
@@ -125,20 +136,43 @@ Cleanup steps may include:
 					NoInteractive: false,
 					Force:         force,
 					CI:            os.Getenv("CI") != "",
+					Trust:         mustGetBool(cmd, "trust"),
 				}
-				if err := pc.ScaffoldManager().RunCleanup(targetWorktree.Path, targetWorktree.Branch, "", siteName, preset, pc.Config, pc.BarePath, promptMode, false, verbose, quiet); err != nil {
-					ui.PrintErrorWithHint("Cleanup failed", err.Error())
+				err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode)
+				if err == nil {
+					_, err = pc.ScaffoldManager().CleanupWorktree(targetWorktree.Path, scaffoldBranch(*targetWorktree), pc.Config, scaffold.CleanupOptions{
+						SiteName:   siteName,
+						Preset:     preset,
+						BarePath:   pc.BarePath,
+						PromptMode: promptMode,
+						Verbose:    verbose,
+						Quiet:      quiet,
+						Sandbox:    sandboxSteps,
+					})
+				}
+				if err != nil {
+					ui.PrintErrorWithHint(i18n.T("hint.cleanup_failed"), err.Error())
 				}
 			}
 
-			if err := git.RemoveWorktree(targetWorktree.Path, true); err != nil {
-				return fmt.Errorf("removing worktree: %w", err)
+			if useTrash {
+				entry, err := trash.Move(pc.ProjectPath, pc.BarePath, *targetWorktree, time.Now())
+				if err != nil {
+					return fmt.Errorf("trashing worktree: %w", err)
+				}
+				ui.PrintSuccessPath("Moved to trash", filepath.Join(trash.Dir(pc.ProjectPath), entry.ID))
+				ui.PrintInfo("Run 'arbor undo' to restore it")
+			} else {
+				if err := git.RemoveWorktree(targetWorktree.Path, true); err != nil {
+					return fmt.Errorf("removing worktree: %w", err)
+				}
+				ui.PrintSuccessPath("Removed", targetWorktree.Path)
 			}
-			ui.PrintSuccessPath("Removed", targetWorktree.Path)
+			forgetWorktree(pc, targetWorktree.Path)
 
 			if deleteBranch && git.BranchExists(pc.BarePath, targetWorktree.Branch) {
 				if err := git.DeleteBranch(pc.BarePath, targetWorktree.Branch, true); err != nil {
-					ui.PrintErrorWithHint("Failed to delete branch", err.Error())
+					ui.PrintErrorWithHint(i18n.T("hint.delete_branch_failed"), err.Error())
 				} else {
 					ui.PrintSuccess(fmt.Sprintf("Deleted branch '%s'", targetWorktree.Branch))
 				}
@@ -148,11 +182,15 @@ Cleanup steps may include:
 			entries, err := os.ReadDir(parentDir)
 			if err == nil && len(entries) == 0 {
 				if err := os.Remove(parentDir); err != nil {
-					ui.PrintErrorWithHint(fmt.Sprintf("Could not remove empty directory %s", parentDir), err.Error())
+					ui.PrintErrorWithHint(i18n.T("hint.remove_dir_failed", parentDir), err.Error())
 				}
 			}
 		} else {
-			ui.PrintInfo("[DRY RUN] Would run cleanup and remove worktree")
+			if useTrash {
+				ui.PrintInfo("[DRY RUN] Would run cleanup and move worktree to trash")
+			} else {
+				ui.PrintInfo("[DRY RUN] Would run cleanup and remove worktree")
+			}
 			if deleteBranch {
 				ui.PrintInfo("[DRY RUN] Would delete branch")
 			}
@@ -168,4 +206,5 @@ func init() {
 
 	removeCmd.Flags().BoolP("force", "f", false, "Skip confirmation and cleanup prompts")
 	removeCmd.Flags().Bool("delete-branch", false, "Also delete the branch after removing worktree")
+	removeCmd.Flags().Bool("trash", false, "Move the worktree to .arbor/trash so 'arbor undo' can restore it")
 }
@@ -92,6 +92,7 @@ preset: ""
 		cmd.Flags().Bool("verbose", false, "")
 		cmd.Flags().Bool("quiet", false, "")
 		cmd.Flags().Bool("delete-branch", false, "")
+		cmd.Flags().Bool("trash", false, "")
 
 		originalDir, err := os.Getwd()
 		require.NoError(t, err)
@@ -109,6 +110,37 @@ preset: ""
 		_, err = os.Stat(featurePath)
 		assert.True(t, os.IsNotExist(err), "feature worktree should not exist after removal")
 	})
+
+	t.Run("remove with trash can be undone", func(t *testing.T) {
+		trashedPath := filepath.Join(tmpDir, "trashed")
+		require.NoError(t, git.CreateWorktree(barePath, trashedPath, "trashed", "main"))
+		require.NoError(t, os.WriteFile(filepath.Join(trashedPath, "notes.txt"), []byte("wip"), 0644))
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("force", true, "")
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.Flags().Bool("verbose", false, "")
+		cmd.Flags().Bool("quiet", false, "")
+		cmd.Flags().Bool("delete-branch", true, "")
+		cmd.Flags().Bool("trash", true, "")
+
+		originalDir, err := os.Getwd()
+		require.NoError(t, err)
+		defer os.Chdir(originalDir)
+		require.NoError(t, os.Chdir(mainPath))
+
+		require.NoError(t, removeCmd.RunE(cmd, []string{"trashed"}))
+		assert.NoDirExists(t, trashedPath)
+		assert.False(t, git.BranchExists(barePath, "trashed"))
+
+		undo := &cobra.Command{}
+		undo.Flags().Bool("dry-run", false, "")
+		undo.Flags().Bool("list", false, "")
+		require.NoError(t, undoCmd.RunE(undo, nil))
+
+		assert.FileExists(t, filepath.Join(trashedPath, "notes.txt"))
+		assert.True(t, git.BranchExists(barePath, "trashed"))
+	})
 }
 
 func TestRemoveCmd_EmptyInputBehavior(t *testing.T) {
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename [OLD] NEW",
+	Short: i18n.T("cmd.rename.short"),
+	Long: `Renames a branch and keeps its worktree consistent with it.
+
+The command will:
+1. Rename the branch (git branch -m)
+2. Rename the worktree folder to match, if it was named after the old branch
+3. Relink Herd or Valet sites named after the folder, and update APP_URL
+4. Point the branch's upstream at the new name on the same remote
+5. Restack branches created from the old branch onto the new one
+
+With one argument, the current worktree's branch is renamed. The default
+branch cannot be renamed. The old branch stays on the remote until you
+delete it.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+
+		oldName, newName := "", args[len(args)-1]
+		if len(args) == 2 {
+			oldName = args[0]
+		} else {
+			if err := pc.MustBeInWorktree(); err != nil {
+				return fmt.Errorf("rename with one argument must be run from within a worktree: %w", err)
+			}
+			oldName, err = git.GetCurrentBranch(pc.CWD)
+			if err != nil {
+				return fmt.Errorf("getting current branch: %w", err)
+			}
+			if oldName == "" {
+				return fmt.Errorf("cannot rename: worktree is on detached HEAD")
+			}
+		}
+
+		if oldName == pc.DefaultBranch {
+			return fmt.Errorf("cannot rename the default branch '%s'", oldName)
+		}
+		if !git.BranchExists(pc.BarePath, oldName) {
+			return fmt.Errorf("branch '%s' not found", oldName)
+		}
+		if err := git.ValidateBranchName(newName); err != nil {
+			return err
+		}
+		if git.BranchExists(pc.BarePath, newName) {
+			return fmt.Errorf("branch '%s' already exists", newName)
+		}
+
+		worktrees, err := git.ListWorktrees(pc.BarePath)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+		var oldPath, newPath string
+		for _, wt := range worktrees {
+			if !wt.Detached && wt.Branch == oldName {
+				oldPath = wt.Path
+				// Folders chosen by hand keep their name
+				if dirMatchesBranch(wt) {
+					newPath = filepath.Join(filepath.Dir(wt.Path), utils.SanitisePath(newName))
+				}
+				break
+			}
+		}
+		if newPath != "" && newPath != oldPath {
+			if _, err := os.Stat(newPath); err == nil {
+				return fmt.Errorf("cannot rename worktree folder: %s already exists", newPath)
+			}
+		}
+
+		ui.PrintStep(fmt.Sprintf("Renaming branch '%s' to '%s'", oldName, newName))
+
+		if dryRun {
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would rename branch '%s' to '%s'", oldName, newName))
+			if newPath != "" && newPath != oldPath {
+				if err := relocateWorktree(pc, oldPath, newPath, true); err != nil {
+					return err
+				}
+			}
+			ui.PrintDone("Dry run complete")
+			return nil
+		}
+
+		if err := git.RenameBranch(pc.BarePath, oldName, newName); err != nil {
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Renamed branch '%s' to '%s'", oldName, newName))
+
+		remote := retargetUpstream(pc.BarePath, newName)
+		restackChildren(pc.BarePath, oldName, newName)
+
+		if newPath != "" && newPath != oldPath {
+			if err := relocateWorktree(pc, oldPath, newPath, false); err != nil {
+				ui.PrintWarning(fmt.Sprintf("Branch renamed, but the worktree folder was not: %v", err))
+				ui.PrintInfo("Run 'arbor repair --rename-dirs' to rename it")
+			} else if isWithinDir(pc.CWD, oldPath) {
+				ui.PrintInfo(fmt.Sprintf("Your shell is still in the old folder; run: cd %s", newPath))
+			}
+		}
+
+		if _, err := git.ResolveCommit(pc.BarePath, "refs/remotes/"+remote+"/"+oldName); remote != "" && err == nil {
+			ui.PrintInfo(fmt.Sprintf("'%s/%s' is left on the remote. Push the new branch with 'arbor push' and delete the old one with 'git push %s --delete %s'", remote, oldName, remote, oldName))
+		}
+
+		ui.PrintDone(fmt.Sprintf("Branch '%s' renamed to '%s'", oldName, newName))
+		return nil
+	},
+}
+
+// retargetUpstream points a renamed branch's upstream at its new name on the
+// same remote, so the next push creates it there instead of updating the
+// old branch. It returns the remote, or "" if the branch tracks none.
+func retargetUpstream(barePath, branch string) string {
+	remote, err := git.GetBranchRemote(barePath, branch)
+	if err != nil || remote == "" {
+		return ""
+	}
+	if err := git.SetBranchUpstream(barePath, branch, remote); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not update tracking for '%s': %v", branch, err))
+		return remote
+	}
+	ui.PrintSuccess(fmt.Sprintf("Branch '%s' now tracks %s/%s", branch, remote, branch))
+	return remote
+}
+
+// restackChildren records newName as the parent of branches that were
+// stacked on oldName.
+func restackChildren(barePath, oldName, newName string) {
+	parents, err := git.BranchParents(barePath)
+	if err != nil {
+		ui.PrintWarning(err.Error())
+		return
+	}
+	for branch, parent := range parents {
+		if parent != oldName {
+			continue
+		}
+		if err := git.SetBranchParent(barePath, branch, newName); err != nil {
+			ui.PrintWarning(err.Error())
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestRetargetUpstreamAndRestack(t *testing.T) {
+	barePath := filepath.Join(t.TempDir(), ".bare")
+	require.NoError(t, exec.Command("git", "init", "--bare", "-b", "main", barePath).Run())
+	require.NoError(t, exec.Command("git", "-C", barePath, "remote", "add", "origin", "https://example.com/repo.git").Run())
+	require.NoError(t, git.SetBranchUpstream(barePath, "old", "origin"))
+	require.NoError(t, git.SetBranchParent(barePath, "child", "old"))
+	require.NoError(t, git.SetBranchParent(barePath, "unrelated", "main"))
+
+	// git branch -m moves the branch's config section to the new name
+	require.NoError(t, exec.Command("git", "-C", barePath, "config", "--rename-section", "branch.old", "branch.new").Run())
+
+	assert.Equal(t, "origin", retargetUpstream(barePath, "new"))
+	merge, err := exec.Command("git", "-C", barePath, "config", "--get", "branch.new.merge").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/new", strings.TrimSpace(string(merge)))
+
+	restackChildren(barePath, "old", "new")
+	parents, err := git.BranchParents(barePath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", parents["child"])
+	assert.Equal(t, "main", parents["unrelated"])
+}
+
+func TestRetargetUpstream_Untracked(t *testing.T) {
+	barePath := filepath.Join(t.TempDir(), ".bare")
+	require.NoError(t, exec.Command("git", "init", "--bare", "-b", "main", barePath).Run())
+
+	assert.Equal(t, "", retargetUpstream(barePath, "feature"))
+}
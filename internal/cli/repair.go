@@ -2,28 +2,49 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 var repairCmd = &cobra.Command{
 	Use:   "repair",
-	Short: "Repair git configuration for existing arbor project",
-	Long: `Fixes fetch refspec and branch tracking configuration for an existing arbor project.
+	Short: i18n.T("cmd.repair.short"),
+	Long: `Fixes fetch refspec, branch tracking and worktree folder names for an existing arbor project.
 
 Use this command if:
 - Fetch refspec was not configured during init (older arbor versions)
 - You need to reset remote configuration
 - Branch tracking needs to be fixed
+- A branch was renamed with 'git branch -m' and its worktree folder still
+  has the old name
+- Two worktrees use the same database suffix, e.g. after .arbor.local was
+  copied, so destroying one would drop the other's databases
 
 This will:
 1. Configure fetch refspec in the .bare directory (unless --tracking-only)
 2. Set up tracking for all local branches that don't have it (unless --refspec-only)
+3. Report worktree folders that no longer match their branch, and rename them
+   with --rename-dirs (git worktree move), relinking Herd or Valet sites and
+   updating APP_URL. The current worktree and folders whose new name is
+   taken are skipped.
+4. Report worktrees that share a database suffix without having chosen to
+   reuse each other's database, and with --db-suffixes give all but one a
+   new suffix: the default branch's worktree, or else the first by branch,
+   keeps it. Each worktree given a new suffix has the old database names
+   in its env files rewritten, and is scaffolded again to create its
+   databases.
 
 This command is idempotent and safe to run multiple times.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -36,10 +57,18 @@ This command is idempotent and safe to run multiple times.`,
 		verbose := mustGetBool(cmd, "verbose")
 		refspecOnly := mustGetBool(cmd, "refspec-only")
 		trackingOnly := mustGetBool(cmd, "tracking-only")
+		renameDirs := mustGetBool(cmd, "rename-dirs")
+		dbSuffixes := mustGetBool(cmd, "db-suffixes")
 
 		if refspecOnly && trackingOnly {
 			return fmt.Errorf("cannot use --refspec-only and --tracking-only together")
 		}
+		if renameDirs && (refspecOnly || trackingOnly) {
+			return fmt.Errorf("cannot use --rename-dirs with --refspec-only or --tracking-only")
+		}
+		if dbSuffixes && (refspecOnly || trackingOnly) {
+			return fmt.Errorf("cannot use --db-suffixes with --refspec-only or --tracking-only")
+		}
 
 		// Phase 1: Fix fetch refspec
 		if !trackingOnly {
@@ -55,6 +84,20 @@ This command is idempotent and safe to run multiple times.`,
 			}
 		}
 
+		// Phase 3: Check worktree folder names
+		if !refspecOnly && !trackingOnly {
+			if err := repairWorktreeDirs(pc, renameDirs, dryRun, verbose); err != nil {
+				return err
+			}
+		}
+
+		// Phase 4: Check database suffixes
+		if !refspecOnly && !trackingOnly {
+			if err := repairDbSuffixes(pc, dbSuffixes, dryRun, verbose); err != nil {
+				return err
+			}
+		}
+
 		ui.PrintDone("Repair complete")
 		return nil
 	},
@@ -110,7 +153,7 @@ func repairFetchRefspec(pc *ProjectContext, dryRun, verbose bool) error {
 						Placeholder("git@github.com:user/repo.git").
 						Value(&promptedURL),
 				),
-			).WithTheme(huh.ThemeCatppuccin())
+			).WithTheme(ui.FormTheme())
 
 			if err := form.Run(); err != nil {
 				return fmt.Errorf("prompting for remote URL: %w", ui.NormalizeAbort(err))
@@ -168,7 +211,7 @@ func confirmOrEditURL(message, currentValue string) (bool, string, error) {
 				Options(options...).
 				Value(&action),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(ui.FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, "", ui.NormalizeAbort(err)
@@ -188,7 +231,7 @@ func confirmOrEditURL(message, currentValue string) (bool, string, error) {
 					Placeholder(currentValue).
 					Value(&newURL),
 			),
-		).WithTheme(huh.ThemeCatppuccin())
+		).WithTheme(ui.FormTheme())
 
 		if err := editForm.Run(); err != nil {
 			return false, "", ui.NormalizeAbort(err)
@@ -269,10 +312,195 @@ func repairBranchTracking(pc *ProjectContext, dryRun, verbose bool) error {
 	return nil
 }
 
+// repairWorktreeDirs reports worktree folders not named after their branch
+// and, with rename, moves each to the name 'arbor work' would give it.
+func repairWorktreeDirs(pc *ProjectContext, rename, dryRun, verbose bool) error {
+	worktrees, err := git.ListWorktrees(pc.BarePath)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	mismatched := 0
+	for _, wt := range worktrees {
+		if dirMatchesBranch(wt) {
+			continue
+		}
+		mismatched++
+
+		newPath := filepath.Join(filepath.Dir(wt.Path), utils.SanitisePath(wt.Branch))
+		if !rename {
+			ui.PrintWarning(fmt.Sprintf("Worktree folder '%s' does not match its branch '%s'", filepath.Base(wt.Path), wt.Branch))
+			continue
+		}
+
+		if isWithinDir(pc.CWD, wt.Path) {
+			ui.PrintWarning(fmt.Sprintf("Skipping %s: it is the current worktree - run 'arbor repair --rename-dirs' from outside it", wt.Path))
+			continue
+		}
+		if _, err := os.Stat(newPath); err == nil {
+			ui.PrintWarning(fmt.Sprintf("Skipping %s: %s already exists", wt.Path, newPath))
+			continue
+		}
+
+		if err := relocateWorktree(pc, wt.Path, newPath, dryRun); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Could not rename %s: %v", wt.Path, err))
+		}
+	}
+
+	if mismatched == 0 {
+		if verbose {
+			ui.PrintInfo("All worktree folders match their branches")
+		}
+	} else if !rename {
+		ui.PrintInfo("Run 'arbor repair --rename-dirs' to rename them to match their branches")
+	}
+
+	return nil
+}
+
+// repairDbSuffixes reports database suffixes used by more than one worktree
+// and, with fix, gives every worktree but the one dbSuffixOwner picks a new
+// suffix.
+func repairDbSuffixes(pc *ProjectContext, fix, dryRun, verbose bool) error {
+	collisions, err := steps.FindDbSuffixCollisions(nil, pc.BarePath)
+	if err != nil {
+		return fmt.Errorf("finding shared database suffixes: %w", err)
+	}
+	if len(collisions) == 0 {
+		if verbose {
+			ui.PrintInfo("Every worktree has its own database suffix")
+		}
+		return nil
+	}
+
+	worktrees, err := git.ListWorktrees(pc.BarePath)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	for _, collision := range collisions {
+		names := make([]string, len(collision.Worktrees))
+		for i, db := range collision.Worktrees {
+			names[i] = filepath.Base(db.WorktreePath)
+		}
+		ui.PrintWarning(fmt.Sprintf("Worktrees %s share database suffix '%s'", strings.Join(names, ", "), collision.DbSuffix))
+		if !fix {
+			continue
+		}
+
+		owner := dbSuffixOwner(collision, pc.DefaultBranch)
+		for i, db := range collision.Worktrees {
+			if i == owner {
+				continue
+			}
+			wt := git.Worktree{Path: db.WorktreePath, Branch: db.Branch}
+			for _, candidate := range worktrees {
+				if candidate.Path == db.WorktreePath {
+					wt = candidate
+				}
+			}
+			if err := reassignDbSuffix(pc, wt, collision.DbSuffix, dryRun, verbose); err != nil {
+				ui.PrintWarning(fmt.Sprintf("Could not give %s a new database suffix: %v", filepath.Base(wt.Path), err))
+			}
+		}
+	}
+
+	if !fix {
+		ui.PrintInfo("Run 'arbor repair --db-suffixes' to give each worktree its own database suffix")
+	}
+	return nil
+}
+
+// dbSuffixOwner returns the index of the worktree that keeps a shared
+// suffix: the default branch's worktree, or else the first.
+func dbSuffixOwner(collision steps.DbSuffixCollision, defaultBranch string) int {
+	for i, db := range collision.Worktrees {
+		if db.Branch == defaultBranch {
+			return i
+		}
+	}
+	return 0
+}
+
+// reassignDbSuffix gives wt a new database suffix, rewrites the database
+// names in its env files to match, and scaffolds it so its databases are
+// created.
+func reassignDbSuffix(pc *ProjectContext, wt git.Worktree, oldSuffix string, dryRun, verbose bool) error {
+	newSuffix, err := pc.ScaffoldManager().NewDbSuffix(pc.Config, wt.Path, scaffoldBranch(wt), pc.BarePath)
+	if err != nil {
+		return fmt.Errorf("generating db_suffix: %w", err)
+	}
+	if newSuffix == oldSuffix {
+		return fmt.Errorf("the suffix generated for it is '%s' too", oldSuffix)
+	}
+
+	if dryRun {
+		ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would change the database suffix of %s from '%s' to '%s' and scaffold it", filepath.Base(wt.Path), oldSuffix, newSuffix))
+		return nil
+	}
+
+	oldCtx, err := copyStateContext(pc, wt)
+	if err != nil {
+		return err
+	}
+	newCtx, err := copyStateContext(pc, wt)
+	if err != nil {
+		return err
+	}
+	newCtx.SetDbSuffix(newSuffix)
+	for _, name := range envFileNames(wt.Path) {
+		path := filepath.Join(wt.Path, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rewritten, err := rewriteForWorktree(string(content), oldCtx, newCtx)
+		if err != nil {
+			return fmt.Errorf("rewriting %s: %w", name, err)
+		}
+		if rewritten == string(content) {
+			continue
+		}
+		if err := config.WriteEnvFile(path, []byte(rewritten)); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if verbose {
+			ui.PrintInfo(fmt.Sprintf("Updated database names in %s", path))
+		}
+	}
+
+	if err := config.WriteLocalState(wt.Path, config.LocalState{DbSuffix: newSuffix}); err != nil {
+		return fmt.Errorf("writing db_suffix to local state: %w", err)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Changed the database suffix of %s from '%s' to '%s'", filepath.Base(wt.Path), oldSuffix, newSuffix))
+
+	// Prompts are off so db.create creates a database rather than offering
+	// to reuse the one this worktree just stopped sharing.
+	promptMode := types.PromptMode{NoInteractive: true, CI: os.Getenv("CI") != ""}
+	if err := scaffoldWorktree(pc, pc.Config, wt, promptMode, false, verbose, false); err != nil {
+		return fmt.Errorf("scaffolding (run 'arbor scaffold %s' to create its databases): %w", filepath.Base(wt.Path), err)
+	}
+	return nil
+}
+
+// isWithinDir reports whether path is dir or inside it.
+func isWithinDir(path, dir string) bool {
+	if evaluated, err := filepath.EvalSymlinks(path); err == nil {
+		path = evaluated
+	}
+	if evaluated, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = evaluated
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func init() {
 	rootCmd.AddCommand(repairCmd)
 
 	repairCmd.Flags().Bool("dry-run", false, "Show what would be done without making changes")
 	repairCmd.Flags().Bool("refspec-only", false, "Only repair fetch refspec, skip branch tracking")
 	repairCmd.Flags().Bool("tracking-only", false, "Only repair branch tracking, skip fetch refspec")
+	repairCmd.Flags().Bool("rename-dirs", false, "Rename worktree folders to match their current branch names")
+	repairCmd.Flags().Bool("db-suffixes", false, "Give worktrees that share a database suffix their own")
 }
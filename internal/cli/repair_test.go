@@ -499,3 +499,151 @@ func TestRepairCommand_ConflictingFlags(t *testing.T) {
 	// Integration testing of conflicting cobra flags would require
 	// executing the binary, which is out of scope for unit tests.
 }
+
+func TestDirMatchesBranch(t *testing.T) {
+	assert.True(t, dirMatchesBranch(git.Worktree{Path: "/p/feature-login", Branch: "feature/login"}))
+	assert.True(t, dirMatchesBranch(git.Worktree{Path: "/p/main", Branch: "main"}))
+	assert.True(t, dirMatchesBranch(git.Worktree{Path: "/p/v1.2", Detached: true}))
+	assert.False(t, dirMatchesBranch(git.Worktree{Path: "/p/old-name", Branch: "new-name"}))
+}
+
+func TestRepairWorktreeDirs(t *testing.T) {
+	sourceDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = sourceDir
+		requireNoError(t, cmd.Run())
+	}
+
+	projectDir := t.TempDir()
+	barePath := filepath.Join(projectDir, ".bare")
+	requireNoError(t, exec.Command("git", "clone", "--bare", sourceDir, barePath).Run())
+
+	mainPath := filepath.Join(projectDir, "main")
+	requireNoError(t, git.CreateWorktree(barePath, mainPath, "main", ""))
+	oldPath := filepath.Join(projectDir, "old-name")
+	requireNoError(t, git.CreateWorktree(barePath, oldPath, "old-name", "main"))
+
+	// Rename the branch behind arbor's back
+	requireNoError(t, exec.Command("git", "-C", barePath, "branch", "-m", "old-name", "feature/new-name").Run())
+
+	pc := &ProjectContext{
+		CWD:           mainPath,
+		BarePath:      barePath,
+		ProjectPath:   projectDir,
+		DefaultBranch: "main",
+		Config:        &config.Config{DefaultBranch: "main"},
+	}
+
+	// Without rename, mismatches are only reported
+	assert.NoError(t, repairWorktreeDirs(pc, false, false, true))
+	assert.DirExists(t, oldPath)
+
+	// Dry run leaves the folder alone
+	assert.NoError(t, repairWorktreeDirs(pc, true, true, true))
+	assert.DirExists(t, oldPath)
+
+	assert.NoError(t, repairWorktreeDirs(pc, true, false, true))
+	assert.NoDirExists(t, oldPath)
+	assert.DirExists(t, filepath.Join(projectDir, "feature-new-name"))
+
+	worktrees, err := git.ListWorktrees(barePath)
+	assert.NoError(t, err)
+	for _, wt := range worktrees {
+		assert.True(t, dirMatchesBranch(wt), "worktree %s should match branch %s", wt.Path, wt.Branch)
+	}
+}
+
+func TestRepairWorktreeDirs_SkipsCurrentWorktree(t *testing.T) {
+	sourceDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = sourceDir
+		requireNoError(t, cmd.Run())
+	}
+
+	projectDir := t.TempDir()
+	barePath := filepath.Join(projectDir, ".bare")
+	requireNoError(t, exec.Command("git", "clone", "--bare", sourceDir, barePath).Run())
+
+	oldPath := filepath.Join(projectDir, "old-name")
+	requireNoError(t, git.CreateWorktree(barePath, oldPath, "old-name", "main"))
+	requireNoError(t, exec.Command("git", "-C", barePath, "branch", "-m", "old-name", "new-name").Run())
+
+	pc := &ProjectContext{
+		CWD:           oldPath,
+		BarePath:      barePath,
+		ProjectPath:   projectDir,
+		DefaultBranch: "main",
+		Config:        &config.Config{DefaultBranch: "main"},
+	}
+
+	assert.NoError(t, repairWorktreeDirs(pc, true, false, true))
+	assert.DirExists(t, oldPath)
+}
+
+func TestRepairDbSuffixes(t *testing.T) {
+	sourceDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = sourceDir
+		requireNoError(t, cmd.Run())
+	}
+
+	projectDir := t.TempDir()
+	barePath := filepath.Join(projectDir, ".bare")
+	requireNoError(t, exec.Command("git", "clone", "--bare", sourceDir, barePath).Run())
+
+	mainPath := filepath.Join(projectDir, "main")
+	requireNoError(t, git.CreateWorktree(barePath, mainPath, "main", ""))
+	featurePath := filepath.Join(projectDir, "feature")
+	requireNoError(t, git.CreateWorktree(barePath, featurePath, "feature", "main"))
+
+	// .arbor.local and .env copied from main
+	for _, path := range []string{mainPath, featurePath} {
+		requireNoError(t, config.WriteLocalState(path, config.LocalState{DbSuffix: "swift_runner"}))
+		requireNoError(t, os.WriteFile(filepath.Join(path, ".env"), []byte("DB_DATABASE=shop_swift_runner\n"), 0644))
+	}
+
+	pc := &ProjectContext{
+		CWD:           mainPath,
+		BarePath:      barePath,
+		ProjectPath:   projectDir,
+		DefaultBranch: "main",
+		Config:        &config.Config{DefaultBranch: "main"},
+	}
+
+	// Without fix, the shared suffix is only reported
+	assert.NoError(t, repairDbSuffixes(pc, false, false, true))
+	state, err := config.ReadLocalState(featurePath)
+	requireNoError(t, err)
+	assert.Equal(t, "swift_runner", state.DbSuffix)
+
+	assert.NoError(t, repairDbSuffixes(pc, true, false, true))
+
+	state, err = config.ReadLocalState(mainPath)
+	requireNoError(t, err)
+	assert.Equal(t, "swift_runner", state.DbSuffix, "the default branch keeps its suffix")
+
+	state, err = config.ReadLocalState(featurePath)
+	requireNoError(t, err)
+	assert.NotEqual(t, "swift_runner", state.DbSuffix)
+	env, err := os.ReadFile(filepath.Join(featurePath, ".env"))
+	requireNoError(t, err)
+	assert.Equal(t, "DB_DATABASE=shop_"+state.DbSuffix+"\n", string(env))
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var restoreConfigCmd = &cobra.Command{
+	Use:   "restore-config",
+	Short: i18n.T("cmd.restore-config.short"),
+	Long: `Restores a config or env file to the version its last change replaced.
+
+arbor writes arbor.yaml, .arbor.local and env files in a single step, so an
+interrupted write never leaves them half written, and keeps the version each
+write replaced: arbor.yaml.bak next to arbor.yaml, and arbor.local.bak in the
+worktree's git directory for .arbor.local. For env files the backup, such as
+arbor.env.bak, is also in the worktree's git directory and holds the file as
+it was before the last scaffold or command changed it.
+
+By default the project arbor.yaml is restored. --local restores the current
+worktree's .arbor.local, --env FILE one of its env files and --global the
+global arbor.yaml. The version being
+replaced becomes the new backup, so running the command again undoes the
+restore. A restored project config copied from the repository is checked for
+trust like any other change to it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun := mustGetBool(cmd, "dry-run")
+		force := mustGetBool(cmd, "force")
+		quiet := mustGetBool(cmd, "quiet")
+		local := mustGetBool(cmd, "local")
+		global := mustGetBool(cmd, "global")
+		envFile := mustGetString(cmd, "env")
+		if (local && global) || (envFile != "" && (local || global)) {
+			return fmt.Errorf("--local, --env and --global cannot be used together")
+		}
+
+		path, err := restoreConfigPath(local, global, envFile)
+		if err != nil {
+			return err
+		}
+		backupPath := config.BackupPath(path)
+
+		backup, err := os.ReadFile(backupPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no backup of %s found", path)
+			}
+			return fmt.Errorf("reading backup: %w", err)
+		}
+		current, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if bytes.Equal(backup, current) {
+			if !quiet {
+				ui.PrintInfo("Already matches the backup")
+			}
+			return nil
+		}
+
+		info, err := os.Stat(backupPath)
+		if err != nil {
+			return fmt.Errorf("reading backup: %w", err)
+		}
+		saved := info.ModTime().Format("2006-01-02 15:04:05")
+
+		if dryRun {
+			ui.PrintStep(fmt.Sprintf("Would restore %s from the backup saved %s", path, saved))
+			return nil
+		}
+
+		if !force {
+			confirmed, err := ui.ConfirmWithDefault(
+				fmt.Sprintf("Restore %s from the backup saved %s?", path, saved),
+				"The current version becomes the backup.",
+				false,
+			)
+			if err != nil {
+				return fmt.Errorf("confirmation prompt: %w", err)
+			}
+			if !confirmed {
+				if !quiet {
+					ui.PrintInfo("Aborted")
+				}
+				return nil
+			}
+		}
+
+		write := config.WriteConfigFile
+		if envFile != "" {
+			write = config.WriteEnvFile
+		}
+		if err := write(path, backup); err != nil {
+			return fmt.Errorf("restoring %s: %w", path, err)
+		}
+		if !quiet {
+			ui.PrintSuccess(fmt.Sprintf("Restored %s", path))
+		}
+		return nil
+	},
+}
+
+// restoreConfigPath returns the config file restore-config restores. The
+// project is found without loading its arbor.yaml, which may be the broken
+// file being restored.
+func restoreConfigPath(local, global bool, envFile string) (string, error) {
+	if global {
+		configDir, err := config.GetGlobalConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "arbor.yaml"), nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting current directory: %w", err)
+	}
+	if local || envFile != "" {
+		worktreePath, err := git.TopLevel(cwd)
+		if err != nil {
+			return "", fmt.Errorf("--local and --env must be run inside a worktree: %w", err)
+		}
+		if envFile != "" {
+			return filepath.Join(worktreePath, envFile), nil
+		}
+		return filepath.Join(worktreePath, ".arbor.local"), nil
+	}
+
+	barePath, err := git.FindBarePath(cwd)
+	if err != nil {
+		return "", fmt.Errorf("finding bare repository: %w", err)
+	}
+	return filepath.Join(filepath.Dir(barePath), "arbor.yaml"), nil
+}
+
+func init() {
+	rootCmd.AddCommand(restoreConfigCmd)
+
+	restoreConfigCmd.Flags().Bool("local", false, "Restore the current worktree's .arbor.local")
+	restoreConfigCmd.Flags().Bool("global", false, "Restore the global arbor.yaml")
+	restoreConfigCmd.Flags().String("env", "", "Restore the current worktree's env file FILE, such as .env")
+	restoreConfigCmd.Flags().Bool("dry-run", false, "Show what would be restored without making changes")
+	restoreConfigCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	restoreConfigCmd.Flags().BoolP("quiet", "q", false, "Suppress non-essential output")
+}
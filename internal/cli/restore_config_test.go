@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func runRestoreConfig(t *testing.T, dir string, flags ...string) error {
+	t.Helper()
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(dir))
+
+	require.NoError(t, restoreConfigCmd.Flags().Set("force", "true"))
+	for _, flag := range flags {
+		require.NoError(t, restoreConfigCmd.Flags().Set(flag, "true"))
+	}
+	defer func() {
+		for _, flag := range append(flags, "force") {
+			require.NoError(t, restoreConfigCmd.Flags().Set(flag, "false"))
+		}
+	}()
+
+	return restoreConfigCmd.RunE(restoreConfigCmd, nil)
+}
+
+func TestRestoreConfig_Project(t *testing.T) {
+	original := "site_name: my-site\ndefault_branch: main\n"
+	projectDir, _ := setupPullConfigProject(t, "", original)
+	configPath := filepath.Join(projectDir, "arbor.yaml")
+
+	// A broken write, such as a bad hand edit, leaves a config that cannot
+	// be loaded
+	require.NoError(t, config.WriteConfigFile(configPath, []byte("site_name: [\n")))
+	_, err := config.LoadProject(projectDir)
+	require.Error(t, err)
+
+	require.NoError(t, runRestoreConfig(t, projectDir))
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	backup, err := os.ReadFile(config.BackupPath(configPath))
+	require.NoError(t, err)
+	assert.Equal(t, "site_name: [\n", string(backup), "the replaced version becomes the backup")
+}
+
+func TestRestoreConfig_Local(t *testing.T) {
+	_, mainPath := setupPullConfigProject(t, "", "site_name: my-site\n")
+
+	require.NoError(t, config.WriteLocalState(mainPath, config.LocalState{DbSuffix: "swift_runner"}))
+	require.NoError(t, config.WriteLocalState(mainPath, config.LocalState{DbSuffix: "calm_river"}))
+
+	statePath := filepath.Join(mainPath, ".arbor.local")
+	assert.NoFileExists(t, statePath+".bak", "the backup is kept out of the worktree")
+
+	require.NoError(t, runRestoreConfig(t, mainPath, "local"))
+	state, err := config.ReadLocalState(mainPath)
+	require.NoError(t, err)
+	assert.Equal(t, "swift_runner", state.DbSuffix)
+}
+
+func TestRestoreConfig_Env(t *testing.T) {
+	_, mainPath := setupPullConfigProject(t, "", "site_name: my-site\n")
+
+	envPath := filepath.Join(mainPath, ".env")
+	require.NoError(t, config.WriteEnvFile(envPath, []byte("DB_DATABASE=shop\n")))
+	require.NoError(t, config.WriteEnvFile(envPath, []byte("DB_DATABASE=shop_broken\n")))
+	assert.NoFileExists(t, envPath+".bak", "the backup is kept out of the worktree")
+
+	require.NoError(t, restoreConfigCmd.Flags().Set("env", ".env"))
+	defer restoreConfigCmd.Flags().Set("env", "")
+	require.NoError(t, runRestoreConfig(t, mainPath))
+
+	content, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "DB_DATABASE=shop\n", string(content))
+
+	err = runRestoreConfig(t, mainPath, "local")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be used together")
+}
+
+func TestRestoreConfig_NoBackup(t *testing.T) {
+	projectDir, _ := setupPullConfigProject(t, "", "site_name: my-site\n")
+
+	err := runRestoreConfig(t, projectDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no backup of")
+}
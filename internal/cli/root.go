@@ -1,31 +1,49 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "arbor",
-	Short: "Git worktree manager for agentic development",
+	Short: i18n.T("cmd.arbor.short"),
 	Long: `Arbor is a self-contained binary for managing git worktrees
 to assist with agentic development of applications.
 It is cross-project, cross-language, and cross-environment compatible.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if noColor || !ui.IsInteractive() {
+		if noColor || !ui.IsColorEnabled() || !ui.IsInteractive() {
 			return cmd.Help()
 		}
 		printBanner()
 		return nil
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyUIPreferences(); err != nil {
+			return err
+		}
+		return ui.SetProgressMode(mustGetString(cmd, "progress"))
+	},
 }
 
 var noColor bool
 
+// sandboxSteps runs command steps in the sandbox for this command, as if
+// arbor.yaml set sandbox.enabled (--sandbox).
+var sandboxSteps bool
+
 func printBanner() {
 	// Big block letters for "ARBOR" with gradient colors
 	blockLetters := [][]string{
@@ -113,13 +131,31 @@ Commands:
   init      Initialize a new repository
   work      Create or checkout a worktree
   list      List all worktrees
+  info      Show details of a worktree
+  path      Print the path of a worktree
   sync      Sync current worktree with upstream branch
+  push      Push the current worktree branch
+  rename    Rename a branch and its worktree
+  mv        Move a worktree to another path
   remove    Remove a worktree
   prune     Remove merged worktrees
+  undo      Restore the most recently removed worktree
+  gc        Clean up after worktrees removed outside arbor
+  history   Show state-changing commands run in this project
   scaffold  Run scaffold steps for a worktree
+  check     Run health checks against a worktree
+  step      List the built-in scaffold steps
+  daemon    Run queued scaffolds and installs in the background
+  serve     Serve arbor to editors and GUIs over a local socket
+  ci        Run the scaffold steps in a CI checkout
+  context   Show the scaffold context for a worktree
+  lsp-info  Print the project model as JSON for editors
+  schema    Print a JSON Schema for arbor.yaml
   repair      Repair git configuration for existing project
   pull-config Update project config from the default branch worktree
+  restore-config Restore a config file from its last backup
   destroy     Completely destroy an arbor project
+  setup     First-run setup wizard
   install   Setup global configuration
   version   Show arbor version
 
@@ -131,9 +167,89 @@ Run 'arbor <command> --help' for more information.`
 	fmt.Println(commandsStyle.Render(commands))
 }
 
+// uiConfig holds the ui section of the global config, loaded once by Execute.
+var uiConfig config.GlobalUIConfig
+
+// loadGlobalUIConfig reads the ui section of the global config. A missing
+// global config is not an error; other failures are reported as a warning.
+func loadGlobalUIConfig() config.GlobalUIConfig {
+	globalCfg, err := config.LoadGlobal()
+	if err != nil {
+		if !errors.Is(err, arborerrors.ErrConfigNotFound) {
+			ui.PrintWarning(fmt.Sprintf("Could not load global config: %v", err))
+		}
+		return config.GlobalUIConfig{}
+	}
+	return globalCfg.UI
+}
+
+// applyUIPreferences configures colour output from the environment and
+// --no-color, then applies ui.theme from the global config.
+func applyUIPreferences() error {
+	ui.ConfigureColor(noColor)
+
+	if err := ui.SetTheme(uiConfig.Theme); err != nil {
+		return fmt.Errorf("global config ui.theme: %w", err)
+	}
+	return nil
+}
+
+// applyLocale selects the message catalog from ui.locale, falling back to
+// LC_ALL/LC_MESSAGES/LANG, and re-localises command help. User catalogs in
+// <global-config-dir>/locales take precedence over the built-in ones.
+func applyLocale(locale string) error {
+	if locale == "" {
+		locale = i18n.DetectLocale(os.Getenv)
+	}
+
+	var localesDir string
+	if dir, err := config.GetGlobalConfigDir(); err == nil {
+		localesDir = filepath.Join(dir, "locales")
+	}
+
+	if err := i18n.SetLocale(locale, localesDir); err != nil {
+		return fmt.Errorf("global config ui.locale: %w", err)
+	}
+	localizeCommands(rootCmd)
+	return nil
+}
+
+// localizeCommands replaces each command's short help with the catalog's
+// cmd.<path>.short message, where path is the command's path below the
+// root joined with dots, such as cmd.step.list.short, so subcommands
+// sharing a name with a top-level command keep their own text.
+func localizeCommands(cmd *cobra.Command) {
+	if short, ok := i18n.Current().Lookup(commandMessageKey(cmd)); ok {
+		cmd.Short = short
+	}
+	for _, sub := range cmd.Commands() {
+		localizeCommands(sub)
+	}
+}
+
+// commandMessageKey returns the catalog key of cmd's short help. The root
+// command's key is cmd.arbor.short.
+func commandMessageKey(cmd *cobra.Command) string {
+	path := strings.Fields(cmd.CommandPath())
+	if len(path) > 1 {
+		path = path[1:]
+	}
+	return "cmd." + strings.Join(path, ".") + ".short"
+}
+
 func Execute() error {
 	rootCmd.SilenceUsage = true
-	if err := rootCmd.Execute(); err != nil {
+	uiConfig = loadGlobalUIConfig()
+	if err := applyLocale(uiConfig.Locale); err != nil {
+		return err
+	}
+	// Capture the working directory first: commands such as remove may
+	// delete the directory arbor was started from.
+	dir, _ := os.Getwd()
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	recordInvocation(cmd, os.Args[1:], dir, start, err)
+	if err != nil {
 		if ui.IsAbort(err) {
 			return nil
 		}
@@ -148,6 +264,10 @@ func init() {
 	rootCmd.PersistentFlags().Bool("quiet", false, "Suppress all output except errors")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
 	rootCmd.PersistentFlags().Bool("no-interactive", false, "Disable interactive prompts")
+	rootCmd.PersistentFlags().Bool("trust", false, "Run the steps policy.require_confirmation lists without asking")
+	rootCmd.PersistentFlags().BoolVar(&trustRepoConfig, "trust-repo-config", false, "Trust an arbor.yaml copied from the repository without asking")
+	rootCmd.PersistentFlags().BoolVar(&sandboxSteps, "sandbox", false, "Run command steps with a filtered environment, no network and writes confined to the worktree")
+	rootCmd.PersistentFlags().String("progress", ui.ProgressAuto, "Progress output: auto, fancy, plain, github, none")
 }
 
 func mustGetString(cmd *cobra.Command, name string) string {
@@ -165,3 +285,11 @@ func mustGetBool(cmd *cobra.Command, name string) bool {
 	}
 	return value
 }
+
+func mustGetInt(cmd *cobra.Command, name string) int {
+	value, err := cmd.Flags().GetInt(name)
+	if err != nil {
+		panic(fmt.Sprintf("programming error: flag %q not defined: %v", name, err))
+	}
+	return value
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/i18n"
+)
+
+func TestLocalizeCommands(t *testing.T) {
+	require.NoError(t, applyLocale("en"))
+
+	stepList, _, err := rootCmd.Find([]string{"step", "list"})
+	require.NoError(t, err)
+	assert.Equal(t, "cmd.step.list.short", commandMessageKey(stepList))
+	assert.Equal(t, "List the built-in scaffold steps", stepList.Short, "subcommands do not take the text of a top-level command with the same name")
+	assert.Equal(t, "cmd.arbor.short", commandMessageKey(rootCmd))
+
+	var check func(cmd *cobra.Command)
+	check = func(cmd *cobra.Command) {
+		if !cmd.Hidden && cmd.Name() != "help" && cmd.Name() != "completion" {
+			_, ok := i18n.Current().Lookup(commandMessageKey(cmd))
+			assert.True(t, ok, "no %s in the English catalog", commandMessageKey(cmd))
+		}
+		for _, sub := range cmd.Commands() {
+			check(sub)
+		}
+	}
+	check(rootCmd)
+}
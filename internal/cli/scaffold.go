@@ -2,29 +2,62 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/ghactions"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var scaffoldCmd = &cobra.Command{
 	Use:   "scaffold [PATH]",
-	Short: "Run scaffold steps for a worktree",
+	Short: i18n.T("cmd.scaffold.short"),
 	Long: `Run scaffold steps for an existing worktree.
 
 When run from the project root (where .bare is located), you can specify a worktree
-path relative to the project root (e.g., 'main', 'feature/my-feature').
+path relative to the project root (e.g., 'main', 'feature/my-feature'), a
+branch, or part of a folder or branch name that matches a single worktree.
 
 When run from inside a worktree without arguments, you'll be prompted to confirm
 scaffolding the current worktree.
 
 If no path is provided and not inside a worktree, you can interactively select
-a worktree to scaffold.`,
+a worktree to scaffold.
+
+With --pending, scaffolds every worktree whose scaffold was deferred with
+'arbor work --no-scaffold'.
+
+With --plan, lists the steps the worktree's scaffold would run, in order,
+without running them. --graph prints the plan as a Mermaid flowchart, or
+a Graphviz graph with --graph=dot, showing step groups and the variables
+steps take from earlier ones. The table notes the steps a dry run would
+fail, such as an env.write of a database name too long for the engine,
+and the command then exits with an error.
+
+With --upgrade, runs only the preset steps added or changed since the
+worktree was last scaffolded, e.g. after upgrading arbor. 'arbor info'
+shows when a worktree has such steps.
+
+Scaffolds journal the files their steps create or change, such as .env, in
+.arbor.local. With --revert-files, puts those files back as they were before
+the first scaffold changed them and removes the ones scaffolds created.
+'arbor check' warns about journaled files edited since the last scaffold.
+
+--profile enables the steps a profile condition gates, on top of
+scaffold.profiles in arbor.yaml, e.g. the laravel preset's testing steps:
+
+  arbor scaffold feature/auth --profile testing`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pc, err := OpenProjectFromCWD()
@@ -32,17 +65,30 @@ a worktree to scaffold.`,
 			return fmt.Errorf("opening project: %w", err)
 		}
 
+		applyProfileFlag(cmd, pc)
+
 		dryRun := mustGetBool(cmd, "dry-run")
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
 		noInteractive := mustGetBool(cmd, "no-interactive")
 		force := mustGetBool(cmd, "force")
+		graph := mustGetString(cmd, "graph")
+		plan := mustGetBool(cmd, "plan") || graph != ""
+		upgrade := mustGetBool(cmd, "upgrade")
+		revertFiles := mustGetBool(cmd, "revert-files")
+		if plan && upgrade {
+			return fmt.Errorf("--plan cannot be combined with --upgrade")
+		}
+		if revertFiles && (plan || upgrade) {
+			return fmt.Errorf("--revert-files cannot be combined with --plan or --upgrade")
+		}
 
 		promptMode := types.PromptMode{
 			Interactive:   ui.IsInteractive(),
 			NoInteractive: noInteractive,
 			Force:         force,
 			CI:            os.Getenv("CI") != "",
+			Trust:         mustGetBool(cmd, "trust"),
 		}
 
 		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
@@ -54,33 +100,22 @@ a worktree to scaffold.`,
 			return fmt.Errorf("no worktrees found in project")
 		}
 
-		var selectedWorktree *git.Worktree
-
-		if len(args) > 0 {
-			worktreePath := args[0]
-
-			if !filepath.IsAbs(worktreePath) {
-				worktreePath = filepath.Join(pc.ProjectPath, worktreePath)
+		if mustGetBool(cmd, "pending") {
+			if plan || upgrade || revertFiles {
+				return fmt.Errorf("--plan, --upgrade and --revert-files cannot be combined with --pending")
 			}
-
-			absWorktreePath, err := filepath.Abs(worktreePath)
-			if err != nil {
-				return fmt.Errorf("getting absolute path: %w", err)
+			if len(args) > 0 {
+				return fmt.Errorf("--pending cannot be combined with a worktree path")
 			}
+			return scaffoldPending(pc, worktrees, promptMode, dryRun, verbose, quiet)
+		}
 
-			for _, wt := range worktrees {
-				wtAbsPath, err := filepath.Abs(wt.Path)
-				if err != nil {
-					continue
-				}
-				if wtAbsPath == absWorktreePath {
-					selectedWorktree = &wt
-					break
-				}
-			}
+		var selectedWorktree *git.Worktree
 
-			if selectedWorktree == nil {
-				return fmt.Errorf("worktree not found: %s", worktreePath)
+		if len(args) > 0 {
+			selectedWorktree, err = matchWorktree(worktrees, pc.ProjectPath, args[0])
+			if err != nil {
+				return err
 			}
 		} else if pc.IsInWorktree() {
 			for _, wt := range worktrees {
@@ -99,8 +134,8 @@ a worktree to scaffold.`,
 				return fmt.Errorf("current worktree not found")
 			}
 
-			if promptMode.Allow() {
-				confirmed, err := ui.ConfirmScaffold(selectedWorktree.Branch)
+			if promptMode.Allow() && !plan && !revertFiles {
+				confirmed, err := ui.ConfirmScaffold(selectedWorktree.Label())
 				if err != nil {
 					return err
 				}
@@ -125,40 +160,289 @@ a worktree to scaffold.`,
 			return fmt.Errorf("no worktree selected")
 		}
 
-		ui.PrintStep(fmt.Sprintf("Scaffolding worktree: %s", selectedWorktree.Branch))
-		ui.PrintInfo(fmt.Sprintf("Path: %s", selectedWorktree.Path))
+		if plan {
+			return printScaffoldPlan(cmd.OutOrStdout(), pc, *selectedWorktree, graph)
+		}
+		if upgrade {
+			return upgradePresetSteps(pc, *selectedWorktree, promptMode, dryRun, verbose, quiet)
+		}
+		if revertFiles {
+			return revertScaffoldFiles(*selectedWorktree, promptMode, dryRun, quiet)
+		}
 
-		preset := pc.Config.Preset
-		if preset == "" {
-			preset = pc.PresetManager().Detect(selectedWorktree.Path)
+		if err := scaffoldWorktree(pc, pc.Config, *selectedWorktree, promptMode, dryRun, verbose, quiet); err != nil {
+			return err
 		}
 
-		if verbose && preset != "" {
-			ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", preset))
+		ui.PrintDone(fmt.Sprintf("Scaffold complete: %s", selectedWorktree.Label()))
+		return nil
+	},
+}
+
+// applyProfileFlag adds the profiles given with --profile to the project's
+// scaffold.profiles for the rest of the command.
+func applyProfileFlag(cmd *cobra.Command, pc *ProjectContext) {
+	profiles, _ := cmd.Flags().GetStringArray("profile")
+	if len(profiles) == 0 {
+		return
+	}
+	cfg := *pc.Config
+	cfg.Scaffold.Profiles = append(slices.Clone(cfg.Scaffold.Profiles), profiles...)
+	pc.Config = &cfg
+}
+
+// scaffoldWorktree runs the scaffold steps cfg defines for one worktree.
+func scaffoldWorktree(pc *ProjectContext, cfg *config.Config, wt git.Worktree, promptMode types.PromptMode, dryRun, verbose, quiet bool) error {
+	ui.PrintStep(fmt.Sprintf("Scaffolding worktree: %s", wt.Label()))
+	ui.PrintInfo(fmt.Sprintf("Path: %s", wt.Path))
+
+	if !dryRun {
+		if err := ensureConfigTrusted(pc.ProjectPath, cfg, promptMode); err != nil {
+			ui.PrintErrorWithHint(i18n.T("hint.scaffold_failed"), err.Error())
+			return err
 		}
+	}
+
+	opts := scaffoldRunOptions(pc, cfg, wt)
+	opts.PromptMode = promptMode
+	opts.DryRun = dryRun
+	opts.Verbose = verbose
+	opts.Quiet = quiet
+
+	if verbose && opts.Preset != "" {
+		ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", opts.Preset))
+	}
 
-		repoName := filepath.Base(pc.ProjectPath)
-		worktreeName := filepath.Base(selectedWorktree.Path)
+	var github *ghactions.Recorder
+	if ghactions.Enabled(os.Getenv) {
+		github = ghactions.NewRecorder()
+		opts.Progress = github.Progress
+	}
 
-		// For the default branch, use the saved SiteName from project config
-		// For feature branches, use the worktree folder name
-		siteName := worktreeName
-		if selectedWorktree.Branch == pc.DefaultBranch && pc.Config.SiteName != "" {
-			siteName = pc.Config.SiteName
+	err := pc.ScaffoldManager().ScaffoldWorktree(wt.Path, scaffoldBranch(wt), cfg, opts)
+	if github != nil {
+		writeGitHubReport(github, cfg, wt, dryRun)
+	}
+	if err != nil {
+		ui.PrintErrorWithHint(i18n.T("hint.scaffold_failed"), err.Error())
+		return err
+	}
+
+	if !dryRun {
+		runPostScaffoldChecks(pc, cfg, wt.Path, quiet)
+	}
+	return nil
+}
+
+// revertScaffoldFiles puts the files scaffolds created or changed in wt
+// back as they were before, after confirming. Files edited since the last
+// scaffold lose those edits, so without a prompt they need --force.
+func revertScaffoldFiles(wt git.Worktree, promptMode types.PromptMode, dryRun, quiet bool) error {
+	files, err := config.JournaledFiles(wt.Path)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No files changed by a scaffold in %s", wt.Label()))
+		return nil
+	}
+
+	var lines []string
+	edited := 0
+	for _, file := range files {
+		action := "restore"
+		if file.Before == "" {
+			action = "remove"
 		}
+		line := fmt.Sprintf("%s %s", action, file.Path)
+		if file.Edited {
+			line += " (edited since the scaffold)"
+			edited++
+		}
+		lines = append(lines, line)
+	}
 
-		if err := pc.ScaffoldManager().RunScaffold(selectedWorktree.Path, selectedWorktree.Branch, repoName, siteName, preset, pc.Config, pc.BarePath, promptMode, dryRun, verbose, quiet); err != nil {
-			ui.PrintErrorWithHint("Scaffold steps failed", err.Error())
+	if dryRun {
+		for _, line := range lines {
+			ui.PrintInfo("Would " + line)
+		}
+		return nil
+	}
+
+	switch {
+	case promptMode.Allow():
+		description := strings.Join(lines, "\n")
+		if edited > 0 {
+			description += fmt.Sprintf("\n\n%d file(s) were edited since the scaffold; those edits will be lost.", edited)
+		}
+		confirmed, err := ui.ConfirmWithDefault(fmt.Sprintf("Revert %d file(s) in %s?", len(files), wt.Label()), description, false)
+		if err != nil {
+			return fmt.Errorf("confirmation prompt: %w", err)
+		}
+		if !confirmed {
+			ui.PrintInfo("Revert cancelled")
+			return nil
+		}
+	case edited > 0 && !promptMode.Force:
+		return fmt.Errorf("%d file(s) were edited since the scaffold; use --force to discard those edits", edited)
+	}
+
+	reverted, err := config.RevertFiles(wt.Path)
+	if err != nil {
+		return fmt.Errorf("reverting files: %w", err)
+	}
+	if !quiet {
+		ui.PrintSuccess(fmt.Sprintf("Reverted %d file(s) in %s", len(reverted), wt.Label()))
+	}
+	return nil
+}
+
+// upgradePresetSteps runs the preset steps wt's last scaffold did not run,
+// because they were added or changed since, and records them as run.
+func upgradePresetSteps(pc *ProjectContext, wt git.Worktree, promptMode types.PromptMode, dryRun, verbose, quiet bool) error {
+	manager := pc.ScaffoldManager()
+	status, err := manager.PresetStatus(pc.Config, wt.Path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == nil:
+		return fmt.Errorf("%s runs no preset steps: no preset applies, or scaffold.override is set", wt.Label())
+	case !status.Recorded():
+		return fmt.Errorf("%s has no record of the preset steps it ran; run 'arbor scaffold' to scaffold it fully", wt.Label())
+	case len(status.Changed) == 0:
+		ui.PrintInfo(fmt.Sprintf("%s already ran every step of the %s preset (v%d)", wt.Label(), status.Name, status.Version))
+		return nil
+	}
+
+	cfg, err := manager.PresetUpgradeConfig(pc.Config, wt.Path, status)
+	if err != nil {
+		return err
+	}
+	// The steps run are the preset's, but trust covers arbor.yaml as a
+	// whole, so it is checked against the project's own config.
+	if !dryRun {
+		if err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode); err != nil {
 			return err
 		}
+	}
+
+	ui.PrintInfo(fmt.Sprintf("Upgrading from %s v%d to %s v%d: %d step(s) to run", status.ScaffoldedName, status.ScaffoldedVersion, status.Name, status.Version, len(cfg.Scaffold.Steps)))
+	if err := scaffoldWorktree(pc, cfg, wt, promptMode, dryRun, verbose, quiet); err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := manager.RecordPresetSteps(pc.Config, wt.Path); err != nil {
+			return err
+		}
+	}
+
+	ui.PrintDone(fmt.Sprintf("Preset steps upgraded: %s", wt.Label()))
+	return nil
+}
 
-		ui.PrintDone(fmt.Sprintf("Scaffold complete: %s", selectedWorktree.Branch))
+// printScaffoldPlan writes the steps a scaffold of wt would run, as a table,
+// or as a graph in the given format when graph is set.
+func printScaffoldPlan(w io.Writer, pc *ProjectContext, wt git.Worktree, graph string) error {
+	steps, err := pc.ScaffoldManager().PlanScaffold(pc.Config, wt.Path)
+	if err != nil {
+		return err
+	}
+	if graph != "" {
+		out, err := scaffold.RenderPlanGraph(steps, graph)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, out)
 		return nil
-	},
+	}
+
+	if len(steps) == 0 {
+		fmt.Fprintf(w, "No scaffold steps for %s\n", wt.Label())
+		return nil
+	}
+	problems, err := pc.ScaffoldManager().ValidateScaffoldPlan(wt.Path, scaffoldBranch(wt), pc.Config, scaffoldRunOptions(pc, pc.Config, wt))
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(steps))
+	for i, step := range steps {
+		var needs []string
+		for _, need := range step.Needs {
+			needs = append(needs, fmt.Sprintf("%s (from %d)", need.Var, need.Step+1))
+		}
+		notes := step.Notes()
+		if problem := problems[i]; problem != nil {
+			notes = append(notes, "would fail: "+problem.Error())
+		}
+		rows[i] = []string{strconv.Itoa(i + 1), step.Label(), step.Location, strings.Join(needs, "\n"), strings.Join(notes, "\n")}
+	}
+	fmt.Fprintf(w, "Scaffold plan for %s:\n", wt.Label())
+	fmt.Fprintln(w, ui.RenderTable([]string{"#", "Step", "Defined in", "Needs", "Notes"}, rows))
+	if len(problems) > 0 {
+		return fmt.Errorf("%d planned step(s) would fail", len(problems))
+	}
+	return nil
+}
+
+// scaffoldRunOptions returns the names and paths a scaffold of wt runs
+// with. The preset is detected from the worktree when cfg sets none.
+func scaffoldRunOptions(pc *ProjectContext, cfg *config.Config, wt git.Worktree) scaffold.RunOptions {
+	preset := cfg.Preset
+	if preset == "" {
+		preset = pc.PresetManager().Detect(wt.Path)
+	}
+	return scaffold.RunOptions{
+		RepoName: filepath.Base(pc.ProjectPath),
+		SiteName: scaffoldSiteName(pc, cfg, wt),
+		Preset:   preset,
+		BarePath: pc.BarePath,
+		Sandbox:  sandboxSteps,
+	}
+}
+
+// scaffoldPending runs the scaffolds queued by 'arbor work --no-scaffold',
+// one worktree at a time. A failed scaffold stays queued and does not stop
+// the others.
+func scaffoldPending(pc *ProjectContext, worktrees []git.Worktree, promptMode types.PromptMode, dryRun, verbose, quiet bool) error {
+	markPendingScaffolds(worktrees)
+
+	var pending []git.Worktree
+	for _, wt := range worktrees {
+		if wt.ScaffoldPending {
+			pending = append(pending, wt)
+		}
+	}
+
+	if len(pending) == 0 {
+		ui.PrintInfo("No pending scaffolds")
+		return nil
+	}
+
+	var failed []string
+	for _, wt := range pending {
+		if err := scaffoldWorktree(pc, pc.Config, wt, promptMode, dryRun, verbose, quiet); err != nil {
+			failed = append(failed, wt.Label())
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d pending scaffolds failed: %s", len(failed), len(pending), strings.Join(failed, ", "))
+	}
+
+	ui.PrintDone(fmt.Sprintf("Scaffolded %d pending worktree(s)", len(pending)))
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(scaffoldCmd)
 
 	scaffoldCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompts")
+	scaffoldCmd.Flags().Bool("pending", false, "Scaffold every worktree created with 'arbor work --no-scaffold'")
+	scaffoldCmd.Flags().Bool("plan", false, "List the steps the scaffold would run without running them")
+	scaffoldCmd.Flags().Bool("upgrade", false, "Run only the preset steps added or changed since the worktree was scaffolded")
+	scaffoldCmd.Flags().Bool("revert-files", false, "Restore the files scaffolds created or changed to how they were before")
+	scaffoldCmd.Flags().StringArray("profile", nil, "Enable the steps gated on a scaffold profile, e.g. testing (repeatable)")
+	scaffoldCmd.Flags().String("graph", "", "Print the plan as a graph: mermaid (default) or dot")
+	scaffoldCmd.Flags().Lookup("graph").NoOptDefVal = scaffold.GraphMermaid
 }
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+const maskedValue = "********"
+
+// contextSnapshot is the resolved scaffold context printed by `arbor context`.
+type contextSnapshot struct {
+	WorktreePath string `json:"worktreePath"`
+	BarePath     string `json:"barePath"`
+	Preset       string `json:"preset"`
+	// Template holds the values available as {{ .Name }} in step args,
+	// including variables env.read steps would set.
+	Template map[string]string `json:"template"`
+	// Runtime lists variables only known once a step runs.
+	Runtime  []runtimeVar `json:"runtime"`
+	EnvFiles []envFile    `json:"envFiles"`
+}
+
+// runtimeVar is a variable set by a step's store_as when it runs.
+type runtimeVar struct {
+	Name string `json:"name"`
+	Step string `json:"step"`
+}
+
+// envFile is an env file scaffold steps read from the worktree.
+type envFile struct {
+	File   string            `json:"file"`
+	Exists bool              `json:"exists"`
+	Values map[string]string `json:"values"`
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context [WORKTREE]",
+	Short: i18n.T("cmd.context.short"),
+	Long: `Prints the scaffold context for a worktree: the values step templates can
+use ({{ .SiteName }}, {{ .DbSuffix }}, ...), variables steps set while
+running, and the env files steps read.
+
+Nothing is run or written. Variables from env.read steps are resolved by
+reading their files; variables from command steps' store_as are only listed.
+Values of secret-looking keys (passwords, secrets, tokens, keys) are masked
+unless --show-secrets is set.
+
+Arguments:
+  WORKTREE  Worktree folder name, branch or path relative to the project
+            root, or part of one when it matches a single worktree
+            (defaults to the current worktree, or the default branch)`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+		if err != nil {
+			return fmt.Errorf("listing worktrees: %w", err)
+		}
+
+		var arg string
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		wt, err := resolveContextWorktree(pc, worktrees, arg)
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := buildContextSnapshot(pc, *wt)
+		if err != nil {
+			return err
+		}
+		if !mustGetBool(cmd, "show-secrets") {
+			snapshot.maskSecrets()
+		}
+
+		if mustGetBool(cmd, "json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(snapshot)
+		}
+		printContextSnapshot(os.Stdout, snapshot)
+		return nil
+	},
+}
+
+// resolveContextWorktree finds the worktree named by arg, see
+// matchWorktree, falling back to the current worktree and then the
+// default branch's worktree.
+func resolveContextWorktree(pc *ProjectContext, worktrees []git.Worktree, arg string) (*git.Worktree, error) {
+	if arg != "" {
+		return matchWorktree(worktrees, pc.ProjectPath, arg)
+	}
+
+	for i, wt := range worktrees {
+		if wt.IsCurrent {
+			return &worktrees[i], nil
+		}
+	}
+	for i, wt := range worktrees {
+		if wt.Branch == pc.DefaultBranch {
+			return &worktrees[i], nil
+		}
+	}
+	return nil, fmt.Errorf("worktree required (no current or default branch worktree found)")
+}
+
+// scaffoldSiteName returns the site name scaffold uses for a worktree: the
+// site_name in cfg for the default branch, otherwise the folder name.
+func scaffoldSiteName(pc *ProjectContext, cfg *config.Config, wt git.Worktree) string {
+	if wt.Branch == pc.DefaultBranch && cfg.SiteName != "" {
+		return cfg.SiteName
+	}
+	return filepath.Base(wt.Path)
+}
+
+// scaffoldBranch returns the branch name scaffold steps see for a worktree.
+// Detached worktrees have no branch, so they use their folder name, which
+// 'arbor work --detach' derives from the tag or commit.
+func scaffoldBranch(wt git.Worktree) string {
+	if wt.Detached {
+		return filepath.Base(wt.Path)
+	}
+	return wt.Branch
+}
+
+func buildContextSnapshot(pc *ProjectContext, wt git.Worktree) (*contextSnapshot, error) {
+	preset := pc.Config.Preset
+	if preset == "" {
+		preset = pc.PresetManager().Detect(wt.Path)
+	}
+
+	manager := pc.ScaffoldManager()
+	ctx, err := manager.NewContext(wt.Path, scaffoldBranch(wt), filepath.Base(pc.ProjectPath), scaffoldSiteName(pc, pc.Config, wt), preset, pc.BarePath)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EnvFile = pc.Config.EnvFile
+	ctx.DefaultBranch = pc.DefaultBranch
+
+	snapshot := &contextSnapshot{
+		WorktreePath: wt.Path,
+		BarePath:     pc.BarePath,
+		Preset:       preset,
+	}
+
+	files := map[string]bool{ctx.PrimaryEnvFile(): true}
+	for _, step := range manager.StepConfigsForWorktree(pc.Config, wt.Path) {
+		switch {
+		case step.Name == "env.read":
+			file := step.File
+			if file == "" {
+				file = ctx.PrimaryEnvFile()
+			}
+			files[file] = true
+			name := step.StoreAs
+			if name == "" {
+				name = step.Key
+			}
+			if value, ok := utils.ReadEnvFile(wt.Path, file)[step.Key]; ok {
+				ctx.SetVar(name, value)
+			}
+		case step.StoreAs != "":
+			snapshot.Runtime = append(snapshot.Runtime, runtimeVar{Name: step.StoreAs, Step: step.Name})
+		}
+	}
+
+	snapshot.Template = ctx.SnapshotForTemplate()
+
+	names := make([]string, 0, len(files))
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		_, statErr := os.Stat(filepath.Join(wt.Path, file))
+		snapshot.EnvFiles = append(snapshot.EnvFiles, envFile{
+			File:   file,
+			Exists: statErr == nil,
+			Values: utils.ReadEnvFile(wt.Path, file),
+		})
+	}
+
+	return snapshot, nil
+}
+
+// isSecretKey reports whether a variable name looks like it holds a secret.
+func isSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range []string{"PASSWORD", "SECRET", "TOKEN"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return upper == "KEY" || strings.HasSuffix(upper, "_KEY")
+}
+
+// maskSecrets hides secret-looking env values, and template variables that
+// hold the same values.
+func (s *contextSnapshot) maskSecrets() {
+	secrets := make(map[string]bool)
+	for _, file := range s.EnvFiles {
+		for key, value := range file.Values {
+			if isSecretKey(key) && value != "" {
+				secrets[value] = true
+				file.Values[key] = maskedValue
+			}
+		}
+	}
+	for key, value := range s.Template {
+		if isSecretKey(key) || secrets[value] {
+			s.Template[key] = maskedValue
+		}
+	}
+}
+
+func printContextSnapshot(w io.Writer, s *contextSnapshot) {
+	preset := s.Preset
+	if preset == "" {
+		preset = "(none)"
+	}
+	fmt.Fprintf(w, "Worktree:  %s\n", s.WorktreePath)
+	fmt.Fprintf(w, "Bare repo: %s\n", s.BarePath)
+	fmt.Fprintf(w, "Preset:    %s\n", preset)
+
+	fmt.Fprintln(w, "\nTemplate variables:")
+	printSortedValues(w, s.Template, func(key, value string) string {
+		return fmt.Sprintf("  %-28s %s", "{{ ."+key+" }}", value)
+	})
+
+	if len(s.Runtime) > 0 {
+		fmt.Fprintln(w, "\nSet while scaffolding:")
+		for _, v := range s.Runtime {
+			fmt.Fprintf(w, "  %-28s by %s\n", "{{ ."+v.Name+" }}", v.Step)
+		}
+	}
+
+	fmt.Fprintln(w, "\nEnv files:")
+	for _, file := range s.EnvFiles {
+		if !file.Exists {
+			fmt.Fprintf(w, "  %s (missing)\n", file.File)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\n", file.File)
+		printSortedValues(w, file.Values, func(key, value string) string {
+			return fmt.Sprintf("    %s=%s", key, value)
+		})
+	}
+}
+
+func printSortedValues(w io.Writer, values map[string]string, format func(key, value string) string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintln(w, format(key, values[key]))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+
+	contextCmd.Flags().Bool("json", false, "Output the context as JSON")
+	contextCmd.Flags().Bool("show-secrets", false, "Show values of secret-looking keys instead of masking them")
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestBuildContextSnapshot(t *testing.T) {
+	projectPath := t.TempDir()
+	wtPath := filepath.Join(projectPath, "feature-auth")
+	require.NoError(t, os.MkdirAll(wtPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(wtPath, ".env"), []byte("APP_NAME=Shop\nDB_PASSWORD=hunter2\n"), 0644))
+	require.NoError(t, config.WriteLocalState(wtPath, config.LocalState{DbSuffix: "swift_runner"}))
+
+	pc := &ProjectContext{
+		ProjectPath:   projectPath,
+		BarePath:      filepath.Join(projectPath, ".bare"),
+		DefaultBranch: "main",
+		Config: &config.Config{
+			SiteName: "shop",
+			Scaffold: config.ScaffoldConfig{
+				Override: true,
+				Steps: []config.StepConfig{
+					{Name: "env.read", Key: "APP_NAME", StoreAs: "AppName"},
+					{Name: "env.read", Key: "DB_PASSWORD", StoreAs: "DbPassword"},
+					{Name: "env.read", Key: "MAIL_HOST", File: ".env.testing"},
+					{Name: "bash.run", Command: "git describe", StoreAs: "Version"},
+				},
+			},
+		},
+	}
+
+	snapshot, err := buildContextSnapshot(pc, git.Worktree{Path: wtPath, Branch: "feature/auth"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "feature/auth", snapshot.Template["Branch"])
+	assert.Equal(t, "feature-auth", snapshot.Template["SiteName"], "feature branches use the folder name")
+	assert.Equal(t, "feature_auth", snapshot.Template["SanitizedSiteName"])
+	assert.Equal(t, "swift_runner", snapshot.Template["DbSuffix"])
+	assert.Equal(t, "Shop", snapshot.Template["AppName"], "env.read variables are resolved")
+	assert.NotContains(t, snapshot.Template, "MAIL_HOST", "missing keys are not set")
+	assert.Equal(t, []runtimeVar{{Name: "Version", Step: "bash.run"}}, snapshot.Runtime)
+
+	require.Len(t, snapshot.EnvFiles, 2)
+	assert.Equal(t, envFile{File: ".env", Exists: true, Values: map[string]string{"APP_NAME": "Shop", "DB_PASSWORD": "hunter2"}}, snapshot.EnvFiles[0])
+	assert.Equal(t, ".env.testing", snapshot.EnvFiles[1].File)
+	assert.False(t, snapshot.EnvFiles[1].Exists)
+
+	snapshot.maskSecrets()
+	assert.Equal(t, maskedValue, snapshot.EnvFiles[0].Values["DB_PASSWORD"])
+	assert.Equal(t, maskedValue, snapshot.Template["DbPassword"], "variables holding secrets are masked")
+	assert.Equal(t, "Shop", snapshot.Template["AppName"])
+}
+
+func TestIsSecretKey(t *testing.T) {
+	for _, key := range []string{"DB_PASSWORD", "APP_KEY", "AWS_SECRET_ACCESS_KEY", "GITHUB_TOKEN", "key"} {
+		assert.True(t, isSecretKey(key), key)
+	}
+	for _, key := range []string{"APP_NAME", "DB_CONNECTION", "KEYBOARD_LAYOUT", "MAIL_HOST"} {
+		assert.False(t, isSecretKey(key), key)
+	}
+}
+
+func TestResolveContextWorktree(t *testing.T) {
+	pc := &ProjectContext{ProjectPath: "/code/app", DefaultBranch: "main"}
+	worktrees := []git.Worktree{
+		{Path: "/code/app/main", Branch: "main", IsMain: true},
+		{Path: "/code/app/feature-auth", Branch: "feature/auth"},
+	}
+
+	wt, err := resolveContextWorktree(pc, worktrees, "feature-auth")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/auth", wt.Branch)
+
+	wt, err = resolveContextWorktree(pc, worktrees, "")
+	require.NoError(t, err)
+	assert.Equal(t, "main", wt.Branch, "defaults to the default branch outside a worktree")
+
+	worktrees[1].IsCurrent = true
+	wt, err = resolveContextWorktree(pc, worktrees, "")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/auth", wt.Branch, "prefers the current worktree")
+
+	_, err = resolveContextWorktree(pc, worktrees, "missing")
+	assert.Error(t, err)
+}
+
+func TestPrintContextSnapshot(t *testing.T) {
+	snapshot := &contextSnapshot{
+		WorktreePath: "/code/app/feature-auth",
+		BarePath:     "/code/app/.bare",
+		Preset:       "laravel",
+		Template: map[string]string{
+			"Branch":            "feature/auth",
+			"DbSuffix":          "swift_runner",
+			"SanitizedSiteName": "feature_auth",
+			"SiteName":          "feature-auth",
+		},
+		Runtime: []runtimeVar{{Name: "Version", Step: "bash.run"}},
+		EnvFiles: []envFile{
+			{File: ".env", Exists: true, Values: map[string]string{"DB_CONNECTION": "mysql", "APP_KEY": maskedValue}},
+			{File: ".env.testing"},
+		},
+	}
+
+	var buf bytes.Buffer
+	printContextSnapshot(&buf, snapshot)
+	assertGolden(t, "context", buf.String())
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestScaffoldPending(t *testing.T) {
+	projectDir := t.TempDir()
+	queued := git.Worktree{Path: filepath.Join(projectDir, "queued"), Branch: "queued"}
+	ready := git.Worktree{Path: filepath.Join(projectDir, "ready"), Branch: "ready"}
+	for _, wt := range []git.Worktree{queued, ready} {
+		require.NoError(t, os.MkdirAll(wt.Path, 0755))
+		require.NoError(t, config.WriteLocalState(wt.Path, config.LocalState{DbSuffix: "swift_runner"}))
+	}
+	require.NoError(t, config.SetScaffoldPending(queued.Path, true))
+
+	pc := &ProjectContext{
+		ProjectPath:   projectDir,
+		DefaultBranch: "main",
+		Config: &config.Config{
+			DefaultBranch: "main",
+			Scaffold: config.ScaffoldConfig{
+				Override: true,
+				Steps:    []config.StepConfig{{Name: "bash.run", Command: "touch scaffolded"}},
+			},
+		},
+	}
+
+	err := scaffoldPending(pc, []git.Worktree{queued, ready}, types.PromptMode{NoInteractive: true}, false, false, true)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(queued.Path, "scaffolded"))
+	assert.NoFileExists(t, filepath.Join(ready.Path, "scaffolded"), "worktrees without a pending scaffold are left alone")
+
+	state, err := config.ReadLocalState(queued.Path)
+	require.NoError(t, err)
+	assert.False(t, state.ScaffoldPending, "a successful scaffold clears the queue entry")
+	assert.Equal(t, "swift_runner", state.DbSuffix)
+}
+
+func TestScaffoldPending_FailureStaysQueued(t *testing.T) {
+	projectDir := t.TempDir()
+	queued := git.Worktree{Path: filepath.Join(projectDir, "queued"), Branch: "queued"}
+	require.NoError(t, os.MkdirAll(queued.Path, 0755))
+	require.NoError(t, config.WriteLocalState(queued.Path, config.LocalState{DbSuffix: "swift_runner"}))
+	require.NoError(t, config.SetScaffoldPending(queued.Path, true))
+
+	pc := &ProjectContext{
+		ProjectPath:   projectDir,
+		DefaultBranch: "main",
+		Config: &config.Config{
+			DefaultBranch: "main",
+			Scaffold: config.ScaffoldConfig{
+				Override: true,
+				Steps:    []config.StepConfig{{Name: "bash.run", Command: "exit 1"}},
+			},
+		},
+	}
+
+	err := scaffoldPending(pc, []git.Worktree{queued}, types.PromptMode{NoInteractive: true}, false, false, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 1 pending scaffolds failed: queued")
+
+	state, err := config.ReadLocalState(queued.Path)
+	require.NoError(t, err)
+	assert.True(t, state.ScaffoldPending)
+}
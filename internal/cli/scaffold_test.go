@@ -1,12 +1,17 @@
 package cli
 
 import (
+	"bytes"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
 )
 
 func getArborBinary(t *testing.T) string {
@@ -77,3 +82,39 @@ func TestScaffoldNoWorktreesInProject(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, string(output), "no worktrees found")
 }
+
+func TestPrintScaffoldPlan_NotesStepsThatWouldFail(t *testing.T) {
+	projectDir, featurePath := setupRecycleProject(t, `default_branch: main
+preset: ""
+scaffold:
+  steps:
+    - name: db.create
+      args: ["--prefix", "billing"]
+    - name: env.write
+      key: DB_DATABASE
+      value: "{{ .DbName }}"
+    - name: env.write
+      key: DB_DATABASE
+      value: `+strings.Repeat("a", 70)+`
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(featurePath, ".env"), []byte("DB_CONNECTION=pgsql\n"), 0644))
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(projectDir))
+	pc, err := OpenProjectFromCWD()
+	require.NoError(t, err)
+	worktrees, err := git.ListWorktreesDetailed(pc.BarePath, pc.CWD, pc.DefaultBranch)
+	require.NoError(t, err)
+	wt, err := matchWorktree(worktrees, pc.ProjectPath, "feature")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = printScaffoldPlan(&out, pc, *wt, "")
+
+	require.EqualError(t, err, "1 planned step(s) would fail")
+	assert.Equal(t, 1, strings.Count(out.String(), "would fail"), "the prefixed DbName fits")
+	assert.Contains(t, out.String(), "pgsql database names are limited to 63")
+	assert.NoFileExists(t, filepath.Join(featurePath, ".arbor.local"))
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/checks"
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/presets"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/scaffold/words"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: i18n.T("cmd.schema.short"),
+	Long: `Print a JSON Schema for arbor.yaml, for in-editor validation and
+completion with yaml-language-server (used by the VS Code YAML extension,
+Neovim, Helix and others).
+
+Step names, presets and condition keys come from this arbor binary, so
+regenerate the schema after upgrading. --global prints the schema of the
+global config instead. --output writes the schema to a file.
+
+Point yaml-language-server at the file with a comment at the top of
+arbor.yaml:
+
+  # yaml-language-server: $schema=.arbor/arbor.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema := projectSchema()
+		if mustGetBool(cmd, "global") {
+			schema = globalSchema()
+		}
+
+		data, err := marshalSchema(schema)
+		if err != nil {
+			return err
+		}
+
+		output := mustGetString(cmd, "output")
+		if output == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return fmt.Errorf("creating schema directory: %w", err)
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("writing schema: %w", err)
+		}
+		ui.PrintSuccess(fmt.Sprintf("Wrote %s", output))
+		return nil
+	},
+}
+
+// projectSchema returns the arbor.yaml schema for the steps and presets
+// built into this binary.
+func projectSchema() map[string]any {
+	registry := steps.NewRegistry()
+	registry.RegisterDefaults()
+	stepNames := registry.ListRegistered()
+	stepDescriptions := make([]string, len(stepNames))
+	stepRequired := make(map[string][]string)
+	for i, info := range registry.Infos() {
+		stepDescriptions[i] = info.Description
+		if len(info.RequiredFields) > 0 {
+			stepRequired[info.Name] = info.RequiredFields
+		}
+	}
+
+	presetNames := presets.NewManager().Available()
+	slices.Sort(presetNames)
+
+	enums := map[string][]string{
+		"preset":                      presetNames,
+		"cleanup.steps.name":          stepNames,
+		"ci.skip":                     stepNames,
+		"policy.require_confirmation": stepNames,
+		"sync.strategy":               syncStrategies,
+		"naming.style":                {words.StyleWords, words.StyleNumeric, words.StyleHash},
+		"checks.name":                 checks.NewDefaultRegistry().ListRegistered(),
+	}
+	descriptions := map[string][]string{
+		"cleanup.steps.name": stepDescriptions,
+	}
+	for _, path := range config.SchemaStepPaths {
+		enums[path+".name"] = stepNames
+		descriptions[path+".name"] = stepDescriptions
+	}
+
+	return config.ProjectSchema(config.SchemaOptions{
+		Enums:            enums,
+		EnumDescriptions: descriptions,
+		StepRequired:     stepRequired,
+		ConditionKeys:    types.ConditionKeys,
+	})
+}
+
+// globalSchema returns the schema of the global config.
+func globalSchema() map[string]any {
+	presetNames := presets.NewManager().Available()
+	slices.Sort(presetNames)
+
+	return config.GlobalSchema(config.SchemaOptions{
+		Enums: map[string][]string{
+			"ui.theme":           ui.Themes,
+			"templates.*.preset": presetNames,
+		},
+	})
+}
+
+func marshalSchema(schema map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().Bool("global", false, "Print the schema of the global config")
+	schemaCmd.Flags().StringP("output", "o", "", "Write the schema to a file instead of stdout")
+}
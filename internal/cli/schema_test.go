@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShippedSchemas checks the schemas in schema/ match what 'arbor
+// schema' generates. Run `go test ./internal/cli -update` to regenerate
+// them after changing the config, steps or presets.
+func TestShippedSchemas(t *testing.T) {
+	for file, schema := range map[string]map[string]any{
+		"arbor.schema.json":        projectSchema(),
+		"arbor-global.schema.json": globalSchema(),
+	} {
+		t.Run(file, func(t *testing.T) {
+			got, err := marshalSchema(schema)
+			require.NoError(t, err)
+
+			path := filepath.Join("..", "..", "schema", file)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(path, got, 0644))
+			}
+
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "reading shipped schema (run with -update to create it)")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestProjectSchema_StepNames(t *testing.T) {
+	schema := projectSchema()
+
+	steps := schema["properties"].(map[string]any)["scaffold"].(map[string]any)["properties"].(map[string]any)["steps"].(map[string]any)
+	step := steps["items"].(map[string]any)
+	assert.Equal(t, []string{"name"}, step["required"])
+
+	names := step["properties"].(map[string]any)["name"].(map[string]any)["enum"].([]string)
+	assert.Contains(t, names, "bash.run")
+	assert.Contains(t, names, "php.laravel")
+}
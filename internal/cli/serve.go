@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/history"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/rpc"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: i18n.T("cmd.serve.short"),
+	Long: `Serve project, worktree and scaffold operations on a local socket, so
+editor extensions and GUIs can drive arbor without parsing its output.
+
+The server speaks JSON-RPC 2.0 on a Unix socket, one JSON message per line
+in each direction. The socket is <project>/.arbor/serve.sock unless
+--socket is given. Methods:
+
+  project.info    project and bare repository paths, default branch, preset
+  worktree.list   the worktrees, as 'arbor list --json' reports them
+  scaffold.run    scaffold a worktree; params {"worktree": path or branch,
+                  "dryRun": bool}. A scaffold.progress notification is sent
+                  as each step starts and finishes.
+  server.methods  the methods the server supports
+
+Config files are re-read for every request. Scaffolds run one at a time
+and without prompts, and are recorded in the project history. Stop the
+server with Ctrl-C.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return fmt.Errorf("opening project: %w", err)
+		}
+
+		socket := mustGetString(cmd, "socket")
+		if socket == "" {
+			socket = filepath.Join(pc.ProjectPath, ".arbor", "serve.sock")
+		}
+
+		listener, err := listenSocket(socket)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ui.PrintInfo(fmt.Sprintf("Listening on %s", socket))
+		return newProjectServer(pc).Serve(ctx, listener)
+	},
+}
+
+// listenSocket listens on a Unix socket at path. A socket file left behind
+// by a server that is no longer running is replaced; one with a live
+// server behind it is an error.
+func listenSocket(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("another arbor serve is listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// projectServer answers requests about one project.
+type projectServer struct {
+	pc *ProjectContext
+	// scaffoldMu runs one scaffold at a time: steps in different worktrees
+	// may share databases, Herd or package caches.
+	scaffoldMu sync.Mutex
+}
+
+// newProjectServer returns an RPC server for the project pc opened.
+func newProjectServer(pc *ProjectContext) *rpc.Server {
+	ps := &projectServer{pc: pc}
+
+	server := rpc.NewServer()
+	server.Handle("project.info", ps.projectInfo)
+	server.Handle("worktree.list", ps.listWorktrees)
+	server.Handle("scaffold.run", ps.runScaffold)
+	server.Handle("server.methods", func(ctx context.Context, call *rpc.Call) (any, error) {
+		return server.Methods(), nil
+	})
+	return server
+}
+
+// config re-reads the project config, so each request sees the current
+// arbor.yaml.
+func (ps *projectServer) config() (*config.Config, error) {
+	ps.pc.ResetConfigCache()
+	cfg, err := ps.pc.configs.Project(ps.pc.ProjectPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	return cfg, nil
+}
+
+type projectInfoJSON struct {
+	ProjectPath   string `json:"projectPath"`
+	BarePath      string `json:"barePath"`
+	DefaultBranch string `json:"defaultBranch"`
+	Preset        string `json:"preset,omitempty"`
+	Version       string `json:"version"`
+}
+
+func (ps *projectServer) projectInfo(ctx context.Context, call *rpc.Call) (any, error) {
+	cfg, err := ps.config()
+	if err != nil {
+		return nil, err
+	}
+	return projectInfoJSON{
+		ProjectPath:   ps.pc.ProjectPath,
+		BarePath:      ps.pc.BarePath,
+		DefaultBranch: ps.pc.DefaultBranch,
+		Preset:        cfg.Preset,
+		Version:       Version,
+	}, nil
+}
+
+func (ps *projectServer) listWorktrees(ctx context.Context, call *rpc.Call) (any, error) {
+	worktrees, err := ps.worktrees()
+	if err != nil {
+		return nil, err
+	}
+	return toWorktreeJSON(worktrees), nil
+}
+
+func (ps *projectServer) worktrees() ([]git.Worktree, error) {
+	return detailedWorktrees(ps.pc, ps.pc.ProjectPath)
+}
+
+type scaffoldParams struct {
+	Worktree string `json:"worktree"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+// stepResultJSON is a step's outcome in scaffold.progress notifications
+// and the scaffold.run result.
+type stepResultJSON struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Current int    `json:"current,omitempty"`
+	Total   int    `json:"total"`
+	Reason  string `json:"reason,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type scaffoldResultJSON struct {
+	Worktree string           `json:"worktree"`
+	Branch   string           `json:"branch"`
+	DryRun   bool             `json:"dryRun"`
+	Steps    []stepResultJSON `json:"steps"`
+}
+
+func (ps *projectServer) runScaffold(ctx context.Context, call *rpc.Call) (any, error) {
+	var params scaffoldParams
+	if err := call.Decode(&params); err != nil {
+		return nil, err
+	}
+	if params.Worktree == "" {
+		return nil, rpc.InvalidParams("scaffold.run: 'worktree' is required")
+	}
+
+	ps.scaffoldMu.Lock()
+	defer ps.scaffoldMu.Unlock()
+
+	cfg, err := ps.config()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := ps.worktrees()
+	if err != nil {
+		return nil, err
+	}
+	wt, ok := findServedWorktree(ps.pc.ProjectPath, worktrees, params.Worktree)
+	if !ok {
+		return nil, rpc.InvalidParams("scaffold.run: worktree not found: %s", params.Worktree)
+	}
+
+	result := scaffoldResultJSON{Worktree: wt.Path, Branch: scaffoldBranch(wt), DryRun: params.DryRun, Steps: []stepResultJSON{}}
+
+	opts := scaffoldRunOptions(ps.pc, cfg, wt)
+	opts.PromptMode = types.PromptMode{NoInteractive: true, CI: true}
+	opts.DryRun = params.DryRun
+	opts.Quiet = true
+	opts.Progress = func(ev scaffold.StepEvent) {
+		step := stepResultJSON{
+			ID:      ev.ID,
+			Name:    ev.Step.Name(),
+			Status:  ev.Status,
+			Current: ev.Current,
+			Total:   ev.Total,
+			Reason:  ev.Reason,
+		}
+		if ev.Error != nil {
+			step.Error = ev.Error.Error()
+		}
+		_ = call.Notify("scaffold.progress", map[string]any{"worktree": wt.Path, "step": step})
+		if ev.Status != scaffold.StepStarted {
+			result.Steps = append(result.Steps, step)
+		}
+	}
+
+	if !params.DryRun {
+		if err := ensureConfigTrusted(ps.pc.ProjectPath, cfg, opts.PromptMode); err != nil {
+			return nil, &rpc.Error{Code: rpc.CodeServerError, Message: err.Error(), Data: result}
+		}
+	}
+
+	start := time.Now()
+	runErr := ps.pc.ScaffoldManager().ScaffoldWorktree(wt.Path, scaffoldBranch(wt), cfg, opts)
+	if !params.DryRun {
+		ps.recordScaffold(wt, start, runErr)
+	}
+
+	if runErr != nil {
+		return nil, &rpc.Error{Code: rpc.CodeServerError, Message: runErr.Error(), Data: result}
+	}
+	return result, nil
+}
+
+// recordScaffold appends a scaffold run to the project history.
+func (ps *projectServer) recordScaffold(wt git.Worktree, start time.Time, runErr error) {
+	entry := history.Entry{
+		Time:       start.UTC(),
+		User:       history.CurrentUser(),
+		Command:    "serve",
+		Args:       []string{"serve", "scaffold", scaffoldBranch(wt)},
+		Dir:        wt.Path,
+		DurationMs: time.Since(start).Milliseconds(),
+		Result:     history.ResultOK,
+	}
+	if runErr != nil {
+		entry.Result = history.ResultError
+		entry.Error = runErr.Error()
+	}
+	if err := history.Append(ps.pc.ProjectPath, entry); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not record history: %v", err))
+	}
+}
+
+// findServedWorktree finds the worktree a client named, by path (absolute
+// or relative to the project) or by branch.
+func findServedWorktree(projectPath string, worktrees []git.Worktree, name string) (git.Worktree, bool) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	// EvalSymlinks fails, leaving pathEval empty, when name is a branch.
+	pathEval, _ := filepath.EvalSymlinks(path)
+
+	for _, wt := range worktrees {
+		if wt.Branch == "(bare)" {
+			continue
+		}
+		wtEval, _ := filepath.EvalSymlinks(wt.Path)
+		if (pathEval != "" && wtEval == pathEval) || (!wt.Detached && wt.Branch == name) {
+			return wt, true
+		}
+	}
+	return git.Worktree{}, false
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("socket", "", "Unix socket to listen on (default <project>/.arbor/serve.sock)")
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/history"
+	"github.com/artisanexperiences/arbor/internal/rpc"
+)
+
+// serveClient sends requests to a project server over an in-memory pipe.
+type serveClient struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func newServeClient(t *testing.T, pc *ProjectContext) *serveClient {
+	serverConn, clientConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = newProjectServer(pc).ServeConn(ctx, serverConn)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		_ = clientConn.Close()
+		<-done
+	})
+	return &serveClient{t: t, conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+}
+
+// call sends a request and returns the notifications received before its
+// response, and the response.
+func (c *serveClient) call(method string, params any) ([]map[string]any, map[string]any) {
+	req := map[string]any{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		req["params"] = params
+	}
+	data, err := json.Marshal(req)
+	require.NoError(c.t, err)
+	_, err = c.conn.Write(append(data, '\n'))
+	require.NoError(c.t, err)
+
+	var notifications []map[string]any
+	for c.scanner.Scan() {
+		var msg map[string]any
+		require.NoError(c.t, json.Unmarshal(c.scanner.Bytes(), &msg))
+		if _, ok := msg["id"]; ok {
+			return notifications, msg
+		}
+		notifications = append(notifications, msg)
+	}
+	c.t.Fatalf("connection closed before response: %v", c.scanner.Err())
+	return nil, nil
+}
+
+// newServeTestProject returns a project whose arbor.yaml runs command as
+// its only scaffold step.
+func newServeTestProject(t *testing.T, command string) (*ProjectContext, string) {
+	worktreePath, barePath := createTestWorktree(t)
+	projectPath := filepath.Dir(barePath)
+	arborYAML := "default_branch: main\nscaffold:\n  override: true\n  steps:\n    - name: bash.run\n      command: " + command + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "arbor.yaml"), []byte(arborYAML), 0644))
+
+	pc := &ProjectContext{
+		BarePath:      barePath,
+		ProjectPath:   projectPath,
+		DefaultBranch: "main",
+		configs:       config.NewStore(),
+	}
+	return pc, worktreePath
+}
+
+func TestServe_ProjectInfoAndWorktreeList(t *testing.T) {
+	pc, worktreePath := newServeTestProject(t, "true")
+	client := newServeClient(t, pc)
+
+	_, resp := client.call("project.info", nil)
+	require.Nil(t, resp["error"])
+	info := resp["result"].(map[string]any)
+	assert.Equal(t, pc.BarePath, info["barePath"])
+	assert.Equal(t, "main", info["defaultBranch"])
+
+	_, resp = client.call("worktree.list", nil)
+	require.Nil(t, resp["error"])
+	worktrees := resp["result"].([]any)
+	require.Len(t, worktrees, 1)
+	wt := worktrees[0].(map[string]any)
+	assert.Equal(t, "main", wt["branch"])
+	assert.Equal(t, evalSymlinks(worktreePath), evalSymlinks(wt["path"].(string)))
+
+	_, resp = client.call("server.methods", nil)
+	assert.Equal(t, []any{"project.info", "scaffold.run", "server.methods", "worktree.list"}, resp["result"])
+}
+
+func TestServe_ScaffoldRun(t *testing.T) {
+	pc, worktreePath := newServeTestProject(t, "touch served")
+	client := newServeClient(t, pc)
+
+	notifications, resp := client.call("scaffold.run", map[string]any{"worktree": "main"})
+	require.Nil(t, resp["error"])
+	assert.FileExists(t, filepath.Join(worktreePath, "served"))
+
+	require.Len(t, notifications, 2)
+	var statuses []any
+	for _, n := range notifications {
+		assert.Equal(t, "scaffold.progress", n["method"])
+		params := n["params"].(map[string]any)
+		assert.Equal(t, float64(1), params["id"])
+		statuses = append(statuses, params["step"].(map[string]any)["status"])
+	}
+	assert.Equal(t, []any{"started", "completed"}, statuses)
+
+	result := resp["result"].(map[string]any)
+	assert.Equal(t, "main", result["branch"])
+	assert.Len(t, result["steps"], 1)
+
+	entries, err := history.Read(pc.ProjectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"serve", "scaffold", "main"}, entries[0].Args)
+	assert.Equal(t, history.ResultOK, entries[0].Result)
+}
+
+func TestServe_ScaffoldRunErrors(t *testing.T) {
+	pc, _ := newServeTestProject(t, "exit 3")
+	client := newServeClient(t, pc)
+
+	_, resp := client.call("scaffold.run", map[string]any{"worktree": "missing"})
+	assert.Equal(t, float64(rpc.CodeInvalidParams), resp["error"].(map[string]any)["code"])
+
+	_, resp = client.call("scaffold.run", map[string]any{"worktree": "main", "bogus": true})
+	assert.Equal(t, float64(rpc.CodeInvalidParams), resp["error"].(map[string]any)["code"])
+
+	_, resp = client.call("scaffold.run", map[string]any{"worktree": "main"})
+	rpcErr := resp["error"].(map[string]any)
+	assert.Equal(t, float64(rpc.CodeServerError), rpcErr["code"])
+	steps := rpcErr["data"].(map[string]any)["steps"].([]any)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "failed", steps[0].(map[string]any)["status"])
+}
+
+func TestFindServedWorktree(t *testing.T) {
+	projectPath := t.TempDir()
+	worktrees := []git.Worktree{
+		{Path: filepath.Join(projectPath, ".bare"), Branch: "(bare)"},
+		{Path: filepath.Join(projectPath, "main"), Branch: "main"},
+		{Path: filepath.Join(projectPath, "feature-x"), Branch: "feature/x"},
+	}
+
+	wt, ok := findServedWorktree(projectPath, worktrees, "feature/x")
+	require.True(t, ok)
+	assert.Equal(t, "feature/x", wt.Branch)
+
+	_, ok = findServedWorktree(projectPath, worktrees, "(bare)")
+	assert.False(t, ok)
+
+	_, ok = findServedWorktree(projectPath, worktrees, "nope")
+	assert.False(t, ok)
+}
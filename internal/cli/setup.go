@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// setupTools are the tools `arbor setup` looks for.
+var setupTools = []string{"php", "composer", "npm", "yarn", "pnpm", "bun", "mysql", "herd", "gh"}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: i18n.T("cmd.setup.short"),
+	Long: `Walks through first-run configuration.
+
+Detects installed tools (php, composer, node package managers, mysql, herd,
+gh), asks for scaffold defaults and writes the global arbor.yaml. It then
+offers to install shell completion and the 'arbor cd' helper for bash, zsh
+or fish.
+
+Setup can be re-run at any time: existing settings are used as defaults,
+and the shell integration is replaced rather than added twice.
+
+Use --yes to accept the defaults without prompting.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes := mustGetBool(cmd, "yes")
+		shell := mustGetString(cmd, "shell")
+		interactive := !yes && ui.ShouldPrompt(cmd, false)
+
+		configDir, err := config.GetGlobalConfigDir()
+		if err != nil {
+			return fmt.Errorf("getting config directory: %w", err)
+		}
+
+		globalCfg, existing, err := loadGlobalConfigForSetup()
+		if err != nil {
+			return err
+		}
+
+		ui.PrintStep("Detecting tools")
+		detectedTools := make(map[string]bool)
+		toolsInfo := make(map[string]config.ToolInfo)
+		var toolRows [][]string
+		for _, tool := range setupTools {
+			path, version, err := detectTool(tool)
+			if err == nil && path != "" {
+				detectedTools[tool] = true
+				toolsInfo[tool] = config.ToolInfo{Path: path, Version: version}
+				toolRows = append(toolRows, []string{tool, "✓ found", version})
+			} else {
+				detectedTools[tool] = false
+				toolRows = append(toolRows, []string{tool, "✗ not found", "-"})
+			}
+		}
+		fmt.Println(ui.RenderStatusTable(toolRows))
+
+		globalCfg.DetectedTools = detectedTools
+		globalCfg.Tools = toolsInfo
+		if globalCfg.DefaultBranch == "" {
+			globalCfg.DefaultBranch = config.DefaultBranch
+		}
+		if !existing {
+			globalCfg.Scaffold = config.GlobalScaffoldConfig{ParallelDependencies: true}
+		}
+
+		if interactive {
+			globalCfg.Scaffold.ParallelDependencies, err = ui.ConfirmWithDefault(
+				"Install dependencies in parallel?",
+				"Run composer and node installs at the same time during scaffold",
+				globalCfg.Scaffold.ParallelDependencies)
+			if err != nil {
+				return fmt.Errorf("prompting for scaffold defaults: %w", err)
+			}
+			globalCfg.Scaffold.Interactive, err = ui.ConfirmWithDefault(
+				"Prompt during scaffold?",
+				"Ask before steps such as database selection and migrations",
+				globalCfg.Scaffold.Interactive)
+			if err != nil {
+				return fmt.Errorf("prompting for scaffold defaults: %w", err)
+			}
+		}
+
+		if err := config.CreateGlobalConfig(globalCfg); err != nil {
+			return fmt.Errorf("saving global config: %w", err)
+		}
+		ui.PrintSuccessPath("Saved global config", configDir)
+
+		if shell == "" {
+			shell = detectShell(os.Getenv)
+		}
+		if shell == "" {
+			ui.PrintWarning("Could not detect a supported shell; use --shell to set up completion and 'arbor cd'")
+		} else if err := setupShellIntegration(shell, configDir, interactive, yes); err != nil {
+			return err
+		}
+
+		ui.PrintDone("Setup complete")
+		ui.PrintInfo("Run `arbor init <repo>` to get started")
+		return nil
+	},
+}
+
+// loadGlobalConfigForSetup returns the current global config, or an empty
+// one when none exists yet. The bool reports whether a config was found.
+func loadGlobalConfigForSetup() (*config.GlobalConfig, bool, error) {
+	globalCfg, err := config.LoadGlobal()
+	if err != nil {
+		if errors.Is(err, arborerrors.ErrConfigNotFound) {
+			return &config.GlobalConfig{}, false, nil
+		}
+		return nil, false, err
+	}
+	return globalCfg, true, nil
+}
+
+// setupShellIntegration offers shell completion and the `arbor cd` helper.
+// Without prompts it installs both when --yes is set and otherwise leaves
+// the shell untouched.
+func setupShellIntegration(shell, configDir string, interactive, yes bool) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+
+	integration, err := newShellIntegration(shell, home, configDir, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	completion, cdHelper := true, true
+	if interactive {
+		hadCompletion, hadCdHelper := integration.installed()
+		_, statErr := os.Stat(integration.Script)
+		firstRun := os.IsNotExist(statErr)
+
+		completion, err = ui.ConfirmWithDefault(
+			fmt.Sprintf("Install %s completion?", shell),
+			fmt.Sprintf("Adds tab completion for arbor commands via %s", integration.RCFile),
+			firstRun || hadCompletion)
+		if err != nil {
+			return fmt.Errorf("prompting for shell completion: %w", err)
+		}
+		cdHelper, err = ui.ConfirmWithDefault(
+			"Install the 'arbor cd' helper?",
+			"Adds an 'arbor cd <worktree>' shell function that changes to a worktree",
+			firstRun || hadCdHelper)
+		if err != nil {
+			return fmt.Errorf("prompting for arbor cd helper: %w", err)
+		}
+	} else if !yes {
+		ui.PrintInfo("Skipped shell integration (re-run interactively or with --yes to install it)")
+		return nil
+	}
+
+	if err := integration.install(completion, cdHelper); err != nil {
+		return err
+	}
+
+	if !completion && !cdHelper {
+		ui.PrintInfo(fmt.Sprintf("Removed arbor shell integration from %s", integration.RCFile))
+		return nil
+	}
+
+	if completion {
+		ui.PrintSuccess(fmt.Sprintf("Installed %s completion", shell))
+	}
+	if cdHelper {
+		ui.PrintSuccess("Installed 'arbor cd' helper")
+	}
+	ui.PrintInfo(fmt.Sprintf("Restart your shell or run: source %s", integration.RCFile))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+
+	setupCmd.Flags().BoolP("yes", "y", false, "Accept defaults without prompting, including shell integration")
+	setupCmd.Flags().String("shell", "", "Shell to integrate with (bash, zsh, fish); defaults to $SHELL")
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// setupTestHome points the home and config directories at a temporary
+// home with no tools on PATH, and returns it.
+func setupTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("ZDOTDIR", "")
+	return home
+}
+
+func runSetup(t *testing.T, yes bool, shell string) error {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("yes", yes, "")
+	cmd.Flags().String("shell", shell, "")
+	cmd.Flags().Bool("no-interactive", true, "")
+	return setupCmd.RunE(cmd, nil)
+}
+
+func TestSetupCmd_Yes(t *testing.T) {
+	home := setupTestHome(t)
+
+	require.NoError(t, runSetup(t, true, "bash"))
+
+	globalCfg, err := config.LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultBranch, globalCfg.DefaultBranch)
+	assert.True(t, globalCfg.Scaffold.ParallelDependencies, "a new config installs dependencies in parallel")
+	assert.False(t, globalCfg.DetectedTools["composer"])
+
+	script := filepath.Join(home, ".config", "arbor", "shell", "arbor.bash")
+	assert.FileExists(t, script)
+	rc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+	require.NoError(t, err)
+	assert.Contains(t, string(rc), sourceLine("bash", script))
+
+	t.Run("re-running replaces the shell integration", func(t *testing.T) {
+		require.NoError(t, runSetup(t, true, "bash"))
+
+		rc, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(string(rc), sourceLine("bash", script)))
+	})
+}
+
+func TestSetupCmd_KeepsExistingSettings(t *testing.T) {
+	setupTestHome(t)
+	require.NoError(t, config.CreateGlobalConfig(&config.GlobalConfig{
+		DefaultBranch: "develop",
+		Scaffold:      config.GlobalScaffoldConfig{Interactive: true},
+	}))
+
+	require.NoError(t, runSetup(t, true, "fish"))
+
+	globalCfg, err := config.LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, "develop", globalCfg.DefaultBranch)
+	assert.Equal(t, config.GlobalScaffoldConfig{Interactive: true}, globalCfg.Scaffold)
+}
+
+func TestSetupCmd_WithoutPromptsLeavesTheShellAlone(t *testing.T) {
+	home := setupTestHome(t)
+
+	require.NoError(t, runSetup(t, false, "zsh"))
+
+	_, err := config.LoadGlobal()
+	assert.NoError(t, err, "the global config is still written")
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+	assert.NoDirExists(t, filepath.Join(home, ".config", "arbor", "shell"))
+}
+
+func TestSetupCmd_UnsupportedShell(t *testing.T) {
+	setupTestHome(t)
+
+	err := runSetup(t, true, "tcsh")
+
+	assert.Error(t, err)
+}
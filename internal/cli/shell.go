@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers around the block `arbor setup` manages in a shell rc file.
+const (
+	shellBlockStart = "# >>> arbor >>>"
+	shellBlockEnd   = "# <<< arbor <<<"
+)
+
+// supportedShells are the shells `arbor setup` can integrate with.
+var supportedShells = []string{"bash", "zsh", "fish"}
+
+// shellIntegration describes the files arbor writes for a shell.
+type shellIntegration struct {
+	Shell string
+	// RCFile is the startup file that sources Script.
+	RCFile string
+	// Script holds the completion and `arbor cd` helper.
+	Script string
+}
+
+// detectShell returns the user's shell from $SHELL when arbor supports it.
+func detectShell(getenv func(string) string) string {
+	shell := filepath.Base(getenv("SHELL"))
+	for _, s := range supportedShells {
+		if shell == s {
+			return s
+		}
+	}
+	return ""
+}
+
+// newShellIntegration returns where the integration for shell is written.
+func newShellIntegration(shell, home, configDir string, getenv func(string) string) (shellIntegration, error) {
+	integration := shellIntegration{
+		Shell:  shell,
+		Script: filepath.Join(configDir, "shell", "arbor."+shell),
+	}
+
+	switch shell {
+	case "bash":
+		integration.RCFile = filepath.Join(home, ".bashrc")
+	case "zsh":
+		zdotdir := getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir = home
+		}
+		integration.RCFile = filepath.Join(zdotdir, ".zshrc")
+	case "fish":
+		configHome := getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		integration.RCFile = filepath.Join(configHome, "fish", "config.fish")
+	default:
+		return shellIntegration{}, fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(supportedShells, ", "))
+	}
+
+	return integration, nil
+}
+
+// shellScript renders the integration script for a shell.
+func shellScript(shell string, completion, cdHelper bool) string {
+	var b strings.Builder
+	b.WriteString("# Generated by 'arbor setup'; re-run it to update this file.\n")
+
+	if completion {
+		b.WriteString("\n# Shell completion\n")
+		switch shell {
+		case "bash":
+			b.WriteString("source <(command arbor completion bash)\n")
+		case "zsh":
+			b.WriteString("(( $+functions[compdef] )) || { autoload -Uz compinit && compinit; }\n")
+			b.WriteString("source <(command arbor completion zsh)\n")
+		case "fish":
+			b.WriteString("command arbor completion fish | source\n")
+		}
+	}
+
+	if cdHelper {
+		b.WriteString("\n# arbor cd [WORKTREE]: change to a worktree by folder or branch name, or\n")
+		b.WriteString("# part of one, or to the main worktree when no name is given\n")
+		if shell == "fish" {
+			b.WriteString(`function arbor
+    if test "$argv[1]" = cd
+        set -l target (command arbor path $argv[2]); or return 1
+        cd $target
+    else
+        command arbor $argv
+    end
+end
+`)
+		} else {
+			b.WriteString(`arbor() {
+  if [ "$1" = "cd" ]; then
+    local target
+    shift
+    target="$(command arbor path "$@")" || return 1
+    cd "$target"
+  else
+    command arbor "$@"
+  fi
+}
+`)
+		}
+	}
+
+	return b.String()
+}
+
+// sourceLine returns the rc file line that loads script.
+func sourceLine(shell, script string) string {
+	if shell == "fish" {
+		return fmt.Sprintf("test -f %q; and source %q", script, script)
+	}
+	return fmt.Sprintf("[ -f %q ] && source %q", script, script)
+}
+
+// upsertShellBlock replaces arbor's managed block in content with body, or
+// appends it when there is none. An empty body removes the block.
+func upsertShellBlock(content, body string) string {
+	var block string
+	if body != "" {
+		block = shellBlockStart + "\n" + strings.TrimRight(body, "\n") + "\n" + shellBlockEnd + "\n"
+	}
+
+	start := strings.Index(content, shellBlockStart)
+	end := strings.Index(content, shellBlockEnd)
+	if start >= 0 && end > start {
+		end += len(shellBlockEnd)
+		if end < len(content) && content[end] == '\n' {
+			end++
+		}
+		return content[:start] + block + content[end:]
+	}
+
+	if block == "" {
+		return content
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// install writes the integration script and the rc file block that sources
+// it. With neither completion nor the cd helper selected, both are removed.
+// Re-running replaces the previous installation.
+func (s shellIntegration) install(completion, cdHelper bool) error {
+	rc, err := os.ReadFile(s.RCFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", s.RCFile, err)
+	}
+
+	var body string
+	if completion || cdHelper {
+		if err := os.MkdirAll(filepath.Dir(s.Script), 0755); err != nil {
+			return fmt.Errorf("creating shell script directory: %w", err)
+		}
+		if err := os.WriteFile(s.Script, []byte(shellScript(s.Shell, completion, cdHelper)), 0644); err != nil {
+			return fmt.Errorf("writing shell script: %w", err)
+		}
+		body = sourceLine(s.Shell, s.Script)
+	} else if err := os.Remove(s.Script); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing shell script: %w", err)
+	}
+
+	updated := upsertShellBlock(string(rc), body)
+	if updated == string(rc) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.RCFile), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(s.RCFile), err)
+	}
+	if err := os.WriteFile(s.RCFile, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", s.RCFile, err)
+	}
+	return nil
+}
+
+// installed reports whether the script currently provides completion and
+// the cd helper, so re-running setup can default to the previous choices.
+func (s shellIntegration) installed() (completion, cdHelper bool) {
+	content, err := os.ReadFile(s.Script)
+	if err != nil {
+		return false, false
+	}
+	return strings.Contains(string(content), "arbor completion"), strings.Contains(string(content), "arbor cd")
+}
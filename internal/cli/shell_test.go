@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectShell(t *testing.T) {
+	tests := map[string]string{
+		"/bin/zsh":               "zsh",
+		"/usr/local/bin/bash":    "bash",
+		"/opt/homebrew/bin/fish": "fish",
+		"/bin/tcsh":              "",
+		"":                       "",
+	}
+	for shellPath, expected := range tests {
+		getenv := func(string) string { return shellPath }
+		assert.Equal(t, expected, detectShell(getenv), shellPath)
+	}
+}
+
+func TestNewShellIntegration(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	bash, err := newShellIntegration("bash", "/home/u", "/home/u/.config/arbor", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/u/.bashrc"), bash.RCFile)
+	assert.Equal(t, filepath.FromSlash("/home/u/.config/arbor/shell/arbor.bash"), bash.Script)
+
+	env["ZDOTDIR"] = "/home/u/.zsh"
+	zsh, err := newShellIntegration("zsh", "/home/u", "/home/u/.config/arbor", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/u/.zsh/.zshrc"), zsh.RCFile)
+
+	fish, err := newShellIntegration("fish", "/home/u", "/home/u/.config/arbor", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/u/.config/fish/config.fish"), fish.RCFile)
+
+	_, err = newShellIntegration("tcsh", "/home/u", "/home/u/.config/arbor", getenv)
+	assert.Error(t, err)
+}
+
+func TestShellScript(t *testing.T) {
+	assertGolden(t, "setup_bash", shellScript("bash", true, true))
+	assertGolden(t, "setup_fish", shellScript("fish", true, true))
+
+	zsh := shellScript("zsh", true, false)
+	assert.Contains(t, zsh, "arbor completion zsh")
+	assert.NotContains(t, zsh, "arbor cd")
+}
+
+func TestShellScript_BashSyntax(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	script := filepath.Join(t.TempDir(), "arbor.bash")
+	require.NoError(t, os.WriteFile(script, []byte(shellScript("bash", true, true)), 0644))
+
+	output, err := exec.Command("bash", "-n", script).CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+func TestUpsertShellBlock(t *testing.T) {
+	existing := "export PATH=$HOME/bin:$PATH"
+
+	added := upsertShellBlock(existing, "source a")
+	assert.Equal(t, "export PATH=$HOME/bin:$PATH\n\n# >>> arbor >>>\nsource a\n# <<< arbor <<<\n", added)
+
+	replaced := upsertShellBlock(added+"alias ll='ls -l'\n", "source b")
+	assert.Equal(t, "export PATH=$HOME/bin:$PATH\n\n# >>> arbor >>>\nsource b\n# <<< arbor <<<\nalias ll='ls -l'\n", replaced)
+
+	assert.Equal(t, replaced, upsertShellBlock(replaced, "source b"), "re-running is a no-op")
+
+	removed := upsertShellBlock(replaced, "")
+	assert.Equal(t, "export PATH=$HOME/bin:$PATH\n\nalias ll='ls -l'\n", removed)
+
+	assert.Equal(t, "", upsertShellBlock("", ""))
+}
+
+func TestShellIntegration_Install(t *testing.T) {
+	dir := t.TempDir()
+	integration := shellIntegration{
+		Shell:  "bash",
+		RCFile: filepath.Join(dir, ".bashrc"),
+		Script: filepath.Join(dir, "arbor", "shell", "arbor.bash"),
+	}
+	require.NoError(t, os.WriteFile(integration.RCFile, []byte("# my rc\n"), 0600))
+
+	require.NoError(t, integration.install(true, true))
+	require.NoError(t, integration.install(true, true))
+
+	rc, err := os.ReadFile(integration.RCFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(rc), shellBlockStart), "block is not duplicated")
+	assert.Contains(t, string(rc), integration.Script)
+
+	completion, cdHelper := integration.installed()
+	assert.True(t, completion)
+	assert.True(t, cdHelper)
+
+	info, err := os.Stat(integration.RCFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "rc file permissions are kept")
+
+	require.NoError(t, integration.install(false, true))
+	completion, cdHelper = integration.installed()
+	assert.False(t, completion)
+	assert.True(t, cdHelper)
+
+	require.NoError(t, integration.install(false, false))
+	rc, err = os.ReadFile(integration.RCFile)
+	require.NoError(t, err)
+	assert.Equal(t, "# my rc\n\n", string(rc))
+	assert.NoFileExists(t, integration.Script)
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// applyWorktreeSkeleton copies the project's worktree_skeleton into a new
+// worktree. Files the worktree already has, such as tracked files, are
+// kept.
+func applyWorktreeSkeleton(cfg *config.Config, projectPath, worktreePath string, verbose bool) error {
+	if cfg.WorktreeSkeleton == "" {
+		return nil
+	}
+
+	skeleton := cfg.WorktreeSkeleton
+	if !filepath.IsAbs(skeleton) {
+		skeleton = filepath.Join(projectPath, skeleton)
+	}
+
+	info, err := os.Stat(skeleton)
+	if err != nil {
+		return fmt.Errorf("reading worktree_skeleton: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("worktree_skeleton %s is not a directory", skeleton)
+	}
+
+	skipped, err := utils.CopyTree(skeleton, worktreePath)
+	if err != nil {
+		return fmt.Errorf("copying worktree skeleton: %w", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Copied worktree skeleton from %s", cfg.WorktreeSkeleton))
+	if verbose {
+		for _, rel := range skipped {
+			ui.PrintInfo(fmt.Sprintf("Kept existing %s", rel))
+		}
+	}
+	return nil
+}
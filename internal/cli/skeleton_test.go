@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestApplyWorktreeSkeleton(t *testing.T) {
+	projectPath := t.TempDir()
+	worktreePath := filepath.Join(projectPath, "feature-auth")
+	require.NoError(t, os.MkdirAll(worktreePath, 0755))
+
+	skeleton := filepath.Join(projectPath, "skeleton")
+	require.NoError(t, os.MkdirAll(filepath.Join(skeleton, ".idea"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skeleton, ".idea", "workspace.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skeleton, "docker-compose.override.yml"), []byte("services: {}\n"), 0644))
+
+	cfg := &config.Config{WorktreeSkeleton: "skeleton"}
+	require.NoError(t, applyWorktreeSkeleton(cfg, projectPath, worktreePath, true))
+
+	assert.FileExists(t, filepath.Join(worktreePath, ".idea", "workspace.xml"))
+	assert.FileExists(t, filepath.Join(worktreePath, "docker-compose.override.yml"))
+}
+
+func TestApplyWorktreeSkeleton_Unset(t *testing.T) {
+	worktreePath := t.TempDir()
+
+	require.NoError(t, applyWorktreeSkeleton(&config.Config{}, t.TempDir(), worktreePath, false))
+
+	entries, err := os.ReadDir(worktreePath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestApplyWorktreeSkeleton_Invalid(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "skeleton"), []byte("not a directory"), 0644))
+
+	err := applyWorktreeSkeleton(&config.Config{WorktreeSkeleton: "missing"}, projectPath, t.TempDir(), false)
+	assert.ErrorContains(t, err, "reading worktree_skeleton")
+
+	err = applyWorktreeSkeleton(&config.Config{WorktreeSkeleton: "skeleton"}, projectPath, t.TempDir(), false)
+	assert.ErrorContains(t, err, "is not a directory")
+}
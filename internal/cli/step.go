@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var stepCmd = &cobra.Command{
+	Use:   "step",
+	Short: i18n.T("cmd.step.short"),
+	Long:  `Commands for the scaffold and cleanup steps built into arbor.`,
+}
+
+var stepListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("cmd.step.list.short"),
+	Long: `Lists the steps arbor.yaml can use in scaffold.steps, cleanup.steps and
+ci.steps, with what each does and the keys it requires besides name.
+
+--long adds an example arbor.yaml entry for each step. --json prints the
+same details as a JSON array.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry := steps.NewRegistry()
+		registry.RegisterDefaults()
+		infos := registry.Infos()
+		out := cmd.OutOrStdout()
+
+		if mustGetBool(cmd, "json") {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(infos)
+		}
+		if mustGetBool(cmd, "long") {
+			printStepInfos(out, infos)
+			return nil
+		}
+
+		rows := make([][]string, len(infos))
+		for i, info := range infos {
+			rows[i] = []string{info.Name, info.Description, strings.Join(info.RequiredFields, ", ")}
+		}
+		fmt.Fprintln(out, ui.RenderTable([]string{"STEP", "DESCRIPTION", "REQUIRES"}, rows))
+		return nil
+	},
+}
+
+// printStepInfos writes each step's description, required keys and
+// examples, the examples as step list entries.
+func printStepInfos(w io.Writer, infos []steps.StepInfo) {
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, info.Name)
+		fmt.Fprintf(w, "  %s\n", info.Description)
+		if len(info.RequiredFields) > 0 {
+			fmt.Fprintf(w, "  Requires: %s\n", strings.Join(info.RequiredFields, ", "))
+		}
+		for _, example := range info.Examples {
+			fmt.Fprintln(w, "  Example:")
+			for j, line := range strings.Split(example, "\n") {
+				prefix := "      "
+				if j == 0 {
+					prefix = "    - "
+				}
+				fmt.Fprintf(w, "%s%s\n", prefix, line)
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(stepCmd)
+	stepCmd.AddCommand(stepListCmd)
+
+	stepListCmd.Flags().Bool("long", false, "Show an example arbor.yaml entry for each step")
+	stepListCmd.Flags().Bool("json", false, "Output the steps as JSON")
+}
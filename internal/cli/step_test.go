@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+)
+
+func runStepList(t *testing.T, long, asJSON bool) string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.Flags().Bool("long", long, "")
+	cmd.Flags().Bool("json", asJSON, "")
+	require.NoError(t, stepListCmd.RunE(cmd, nil))
+	return out.String()
+}
+
+func TestPrintStepInfos(t *testing.T) {
+	var out bytes.Buffer
+	printStepInfos(&out, []steps.StepInfo{
+		{
+			Name:           "env.write",
+			Description:    "Writing environment variable",
+			RequiredFields: []string{"key"},
+			Examples:       []string{"name: env.write\nkey: APP_ENV\nvalue: local"},
+		},
+		{Name: "herd.link", Description: "Linking Herd site"},
+	})
+
+	assertGolden(t, "step_list_long", out.String())
+}
+
+func TestStepListCmd(t *testing.T) {
+	registry := steps.NewRegistry()
+	registry.RegisterDefaults()
+	infos := registry.Infos()
+
+	t.Run("lists every step in a table", func(t *testing.T) {
+		out := runStepList(t, false, false)
+		for _, info := range infos {
+			assert.Contains(t, out, info.Name)
+		}
+	})
+
+	t.Run("--long shows examples", func(t *testing.T) {
+		out := runStepList(t, true, false)
+		assert.Contains(t, out, "Example:\n    - name: db.create")
+	})
+
+	t.Run("--json prints the registry's step infos", func(t *testing.T) {
+		var got []steps.StepInfo
+		require.NoError(t, json.Unmarshal([]byte(runStepList(t, false, true)), &got))
+		assert.Equal(t, infos, got)
+		for _, info := range got {
+			assert.NotEmpty(t, strings.TrimSpace(info.Description), info.Name)
+		}
+	})
+}
@@ -2,32 +2,55 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
-	Short: "Sync current worktree with upstream branch",
+	Short: i18n.T("cmd.sync.short"),
 	Long: `Synchronizes the current worktree branch with an upstream branch by
 fetching the latest changes and rebasing or merging.
 
 The command will:
 1. Auto-stash changes (tracked modifications and untracked files) by default
 2. Fetch updates from the remote
-3. Rebase (default) or merge the current branch with upstream changes
+3. Rebase (default), merge or fast-forward the current branch to upstream changes
 4. Restore stashed changes after successful sync
 
 Note: Ignored files (node_modules, vendor, etc.) are not stashed for performance,
 as they are not modified by git during sync anyway.
 
+The ff-only strategy (git merge --ff-only) never creates a merge commit and
+fails if the branch has commits the upstream does not, for teams whose
+policy forbids local merge commits.
+
+--stack rebases a stack of dependent branches: the bottom of the current
+branch's stack onto the upstream, then each branch created from it with
+'arbor work --base' onto its parent, in order, in each branch's worktree.
+
+Extra git rebase options can be set with sync.rebase_options in arbor.yaml;
+--autosquash, --update-refs and --rebase-merges (and their --no- forms) are
+allowed.
+
 Auto-stashing can be disabled with --no-auto-stash flag or by setting
 sync.auto_stash: false in arbor.yaml.
 
+If the rebase or merge stops with conflicts, sync lists the conflicted files
+and offers to open them in $EDITOR or git mergetool. Once they are resolved,
+'arbor sync --continue' stages them, resumes the rebase or merge and restores
+the auto-stash; 'arbor sync --abort' cancels it and restores the auto-stash.
+
 Configuration can be set via flags, project config (arbor.yaml), or interactively.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pc, err := OpenProjectFromCWD()
@@ -40,6 +63,18 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 			return fmt.Errorf("sync must be run from within a worktree: %w", err)
 		}
 
+		continueFlag := mustGetBool(cmd, "continue")
+		abortFlag := mustGetBool(cmd, "abort")
+		if continueFlag && abortFlag {
+			return fmt.Errorf("--continue and --abort cannot be used together")
+		}
+		if continueFlag {
+			return continueSync(pc, mustGetBool(cmd, "yes"), mustGetBool(cmd, "quiet"))
+		}
+		if abortFlag {
+			return abortSync(pc, mustGetBool(cmd, "quiet"))
+		}
+
 		dryRun := mustGetBool(cmd, "dry-run")
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
@@ -67,10 +102,10 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 
 		// Check for rebase/merge in progress
 		if git.IsRebaseInProgress(pc.CWD) {
-			return fmt.Errorf("rebase in progress - resolve conflicts and run 'git rebase --continue', or run 'git rebase --abort' to cancel")
+			return fmt.Errorf("rebase in progress - resolve conflicts and run 'arbor sync --continue', or run 'arbor sync --abort' to cancel")
 		}
 		if git.IsMergeInProgress(pc.CWD) {
-			return fmt.Errorf("merge in progress - resolve conflicts, stage changes, and commit, or run 'git merge --abort' to cancel")
+			return fmt.Errorf("merge in progress - resolve conflicts and run 'arbor sync --continue', or run 'arbor sync --abort' to cancel")
 		}
 
 		// Determine if auto-stash should be used
@@ -88,8 +123,9 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 			return fmt.Errorf("checking for changes: %w", err)
 		}
 
-		// Track whether we created a stash so we can pop it later
+		// Track the stash we created so we can pop it later
 		var stashCreated bool
+		var stashCommit string
 
 		if hasChanges && !autoStash {
 			// Auto-stash disabled but there are changes - warn the user
@@ -136,8 +172,11 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 		}
 
 		// Validate strategy
-		if strategy != "rebase" && strategy != "merge" {
-			return fmt.Errorf("invalid strategy %q: must be 'rebase' or 'merge'", strategy)
+		if !isValidSyncStrategy(strategy) {
+			return fmt.Errorf("invalid strategy %q: must be 'rebase', 'merge' or 'ff-only'", strategy)
+		}
+		if err := git.ValidateRebaseOptions(pc.Config.Sync.RebaseOptions); err != nil {
+			return fmt.Errorf("invalid sync.rebase_options in arbor.yaml: %w", err)
 		}
 
 		// Interactive prompts if needed and allowed
@@ -150,7 +189,7 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 					return fmt.Errorf("listing local branches: %w", err)
 				}
 
-				remoteBranches, _ := git.ListRemoteBranches(pc.BarePath)
+				remoteBranches, _ := pc.RemoteBranches(mustGetBool(cmd, "refresh"))
 
 				selected, err := ui.SelectUpstreamBranch(localBranches, remoteBranches, pc.DefaultBranch)
 				if err != nil {
@@ -192,6 +231,21 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 			return fmt.Errorf("remote %q not configured - add it with 'git remote add %s <url>'", remote, remote)
 		}
 
+		if mustGetBool(cmd, "stack") {
+			if strategy != "rebase" {
+				return fmt.Errorf("--stack requires the rebase strategy")
+			}
+			return syncStack(pc, currentBranch, stackSyncOptions{
+				Remote:        remote,
+				Upstream:      upstream,
+				RebaseOptions: pc.Config.Sync.RebaseOptions,
+				AutoStash:     autoStash,
+				DryRun:        dryRun,
+				Verbose:       verbose,
+				Quiet:         quiet,
+			})
+		}
+
 		if hasChanges && autoStash {
 			if !quiet {
 				ui.PrintInfo("Auto-stashing changes (tracked modifications and untracked files)...")
@@ -202,6 +256,10 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 					return fmt.Errorf("failed to stash changes: %w", err)
 				}
 				stashCreated = true
+				stashCommit, err = git.LatestStash(pc.CWD)
+				if err != nil {
+					return err
+				}
 				if !quiet {
 					ui.PrintSuccess("Changes stashed successfully")
 				}
@@ -218,6 +276,9 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 		if dryRun {
 			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would fetch from %s", remote))
 			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would %s %s/%s into %s", strategy, remote, upstream, currentBranch))
+			if strategy == "rebase" && len(pc.Config.Sync.RebaseOptions) > 0 {
+				ui.PrintInfo(fmt.Sprintf("[DRY RUN] With rebase options: %s", strings.Join(pc.Config.Sync.RebaseOptions, " ")))
+			}
 			ui.PrintDone("Dry run complete")
 			return nil
 		}
@@ -239,12 +300,23 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 		}
 
 		var syncErr error
-		if strategy == "rebase" {
-			syncErr = git.RebaseOnto(pc.CWD, remote, upstream)
-		} else {
+		switch strategy {
+		case "rebase":
+			syncErr = git.RebaseOnto(pc.CWD, remote, upstream, pc.Config.Sync.RebaseOptions...)
+		case "ff-only":
+			syncErr = git.FastForwardTo(pc.CWD, remote, upstream)
+		default:
 			syncErr = git.MergeInto(pc.CWD, remote, upstream)
 		}
 
+		if isSyncConflict(syncErr) {
+			if stashCreated {
+				if err := recordSyncStash(pc.CWD, stashCommit); err != nil {
+					return err
+				}
+			}
+			return handleSyncConflict(pc, yesFlag, quiet)
+		}
 		if syncErr != nil {
 			// Leave stash intact on sync failure
 			if stashCreated && !quiet {
@@ -264,20 +336,9 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 				ui.PrintInfo("Restoring stashed changes...")
 			}
 
-			popErr := git.PopStash(pc.CWD)
+			popErr := git.PopStashCommit(pc.CWD, stashCommit)
 			if popErr != nil {
-				// Check if it's a conflict error
-				if _, isConflict := popErr.(*git.StashConflictError); isConflict {
-					ui.PrintWarning("\nWarning: Could not automatically restore stashed changes due to conflicts")
-					ui.PrintInfo("\nYour changes have been safely preserved in the stash.")
-					ui.PrintInfo("To restore them, resolve conflicts and run:")
-					ui.PrintInfo("  git stash pop")
-					ui.PrintInfo("\nTo discard the stash:")
-					ui.PrintInfo("  git stash drop")
-				} else {
-					ui.PrintWarning(fmt.Sprintf("\nWarning: Failed to restore stashed changes: %v", popErr))
-					ui.PrintInfo("Your changes are still in the stash. Run 'git stash pop' to restore them manually.")
-				}
+				reportStashPopError(popErr)
 			} else {
 				if !quiet {
 					ui.PrintSuccess("Stashed changes restored successfully")
@@ -300,12 +361,13 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 
 		if shouldSave {
 			pc.Config.Sync = config.SyncConfig{
-				Upstream:  upstream,
-				Strategy:  strategy,
-				Remote:    remote,
-				AutoStash: &autoStash,
+				Upstream:      upstream,
+				Strategy:      strategy,
+				Remote:        remote,
+				AutoStash:     &autoStash,
+				RebaseOptions: pc.Config.Sync.RebaseOptions,
 			}
-			if err := config.SaveProject(pc.ProjectPath, pc.Config); err != nil {
+			if err := saveSyncConfig(pc); err != nil {
 				ui.PrintError(fmt.Sprintf("Failed to save sync config: %v", err))
 			} else {
 				ui.PrintSuccess("Saved sync settings to arbor.yaml")
@@ -317,13 +379,49 @@ Configuration can be set via flags, project config (arbor.yaml), or interactivel
 	},
 }
 
+// saveSyncConfig saves pc.Config with its sync settings to arbor.yaml. The
+// settings only pick what sync runs, so a config trusted before stays
+// trusted.
+func saveSyncConfig(pc *ProjectContext) error {
+	configPath := filepath.Join(pc.ProjectPath, "arbor.yaml")
+	before, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading project config: %w", err)
+	}
+	if err := config.SaveProject(pc.ProjectPath, pc.Config); err != nil {
+		return err
+	}
+	if record, err := config.ReadTrustRecord(pc.ProjectPath); err == nil && record != nil && before != nil && record.Trusts(before) {
+		after, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("reading project config: %w", err)
+		}
+		if err := config.WriteTrustRecord(pc.ProjectPath, config.TrustRecord{ConfigHash: config.HashConfig(after), TrustedAt: time.Now().UTC()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncStrategies are the strategies sync supports.
+var syncStrategies = []string{"rebase", "merge", "ff-only"}
+
+// isValidSyncStrategy reports whether strategy is one sync supports.
+func isValidSyncStrategy(strategy string) bool {
+	return slices.Contains(syncStrategies, strategy)
+}
+
 func init() {
 	rootCmd.AddCommand(syncCmd)
 
 	syncCmd.Flags().StringP("upstream", "u", "", "Upstream branch to sync against (e.g., main)")
-	syncCmd.Flags().StringP("strategy", "s", "", "Sync strategy: rebase or merge (default: rebase)")
+	syncCmd.Flags().StringP("strategy", "s", "", "Sync strategy: rebase, merge or ff-only (default: rebase)")
 	syncCmd.Flags().StringP("remote", "r", "", "Remote name to fetch from (default: origin)")
 	syncCmd.Flags().Bool("save", false, "Persist sync settings to arbor.yaml")
 	syncCmd.Flags().BoolP("yes", "y", false, "Skip confirmations and run with chosen values")
 	syncCmd.Flags().Bool("no-auto-stash", false, "Disable automatic stashing of all changes before sync")
+	syncCmd.Flags().Bool("continue", false, "Resume a sync stopped by conflicts once they are resolved")
+	syncCmd.Flags().Bool("abort", false, "Cancel a sync stopped by conflicts and restore stashed changes")
+	syncCmd.Flags().Bool("refresh", false, "Refresh the cached remote branch list shown by the upstream picker")
+	syncCmd.Flags().Bool("stack", false, "Rebase the current branch's stack of dependent branches in order")
 }
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// syncStashFile records, in the worktree's git directory, the auto-stash of
+// a sync stopped by conflicts so --continue and --abort can restore it. It
+// cannot live in .arbor.local: that file may itself be in the stash.
+const syncStashFile = "arbor-sync-stash"
+
+func recordSyncStash(worktreePath, commit string) error {
+	path, err := git.GitPath(worktreePath, syncStashFile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(commit+"\n"), 0644); err != nil {
+		return fmt.Errorf("recording sync stash: %w", err)
+	}
+	return nil
+}
+
+// takeSyncStash returns and forgets the recorded auto-stash, or "" if
+// there is none.
+func takeSyncStash(worktreePath string) (string, error) {
+	path, err := git.GitPath(worktreePath, syncStashFile)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading sync stash: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("clearing sync stash: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// restoreSyncStash pops the auto-stash of a sync stopped by conflicts.
+func restoreSyncStash(worktreePath string, quiet bool) error {
+	commit, err := takeSyncStash(worktreePath)
+	if err != nil || commit == "" {
+		return err
+	}
+
+	if err := git.PopStashCommit(worktreePath, commit); err != nil {
+		reportStashPopError(err)
+		return nil
+	}
+	if !quiet {
+		ui.PrintSuccess("Stashed changes restored successfully")
+	}
+	return nil
+}
+
+// reportStashPopError explains how to recover changes left in the stash.
+func reportStashPopError(err error) {
+	if _, isConflict := err.(*git.StashConflictError); isConflict {
+		ui.PrintWarning("\nWarning: Could not automatically restore stashed changes due to conflicts")
+		ui.PrintInfo("\nYour changes have been safely preserved in the stash.")
+		ui.PrintInfo("To restore them, resolve conflicts and run:")
+		ui.PrintInfo("  git stash pop")
+		ui.PrintInfo("\nTo discard the stash:")
+		ui.PrintInfo("  git stash drop")
+		return
+	}
+	ui.PrintWarning(fmt.Sprintf("\nWarning: Failed to restore stashed changes: %v", err))
+	ui.PrintInfo("Your changes are still in the stash. Run 'git stash pop' to restore them manually.")
+}
+
+// isSyncConflict reports whether err is a rebase or merge stopped by
+// conflicts.
+func isSyncConflict(err error) bool {
+	var rebaseConflict *git.RebaseConflictError
+	var mergeConflict *git.MergeConflictError
+	return errors.As(err, &rebaseConflict) || errors.As(err, &mergeConflict)
+}
+
+// handleSyncConflict lists the conflicted files and, in a terminal, offers
+// to open them, run mergetool, continue or abort. Otherwise it explains
+// how to resume with 'arbor sync --continue' or '--abort'.
+func handleSyncConflict(pc *ProjectContext, yes, quiet bool) error {
+	for {
+		files, err := git.ConflictedFiles(pc.CWD)
+		if err != nil {
+			return err
+		}
+
+		if len(files) > 0 {
+			ui.PrintWarning(fmt.Sprintf("Conflicts in %d file(s):", len(files)))
+			for _, file := range files {
+				ui.PrintInfo("  " + file)
+			}
+		}
+
+		if yes || !ui.IsInteractive() {
+			return syncConflictError(len(files))
+		}
+
+		action, err := ui.SelectConflictAction(len(files))
+		if err != nil {
+			return err
+		}
+
+		switch action {
+		case ui.ConflictActionContinue:
+			return continueSync(pc, yes, quiet)
+		case ui.ConflictActionAbort:
+			return abortSync(pc, quiet)
+		case ui.ConflictActionEditor:
+			if err := utils.OpenInEditor(pc.CWD, files...); err != nil {
+				ui.PrintWarning(err.Error())
+			}
+			if _, err := git.StageResolved(pc.CWD); err != nil {
+				return err
+			}
+		case ui.ConflictActionMergetool:
+			if err := git.RunMergetool(pc.CWD); err != nil {
+				ui.PrintWarning(err.Error())
+			}
+		default:
+			return syncConflictError(len(files))
+		}
+	}
+}
+
+func syncConflictError(conflicts int) error {
+	ui.PrintInfo("Resolve the conflicts, then run 'arbor sync --continue', or run 'arbor sync --abort' to cancel.")
+	ui.PrintInfo("Auto-stashed changes are restored when the sync completes or is aborted.")
+	return fmt.Errorf("sync stopped with conflicts in %d file(s)", conflicts)
+}
+
+// continueSync stages resolved files and resumes the rebase or merge a
+// conflicted sync stopped in, then restores the auto-stash.
+func continueSync(pc *ProjectContext, yes, quiet bool) error {
+	rebasing := git.IsRebaseInProgress(pc.CWD)
+	merging := git.IsMergeInProgress(pc.CWD)
+
+	if rebasing || merging {
+		remaining, err := git.StageResolved(pc.CWD)
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 {
+			ui.PrintWarning(fmt.Sprintf("Conflict markers remain in %d file(s):", len(remaining)))
+			for _, file := range remaining {
+				ui.PrintInfo("  " + file)
+			}
+			return fmt.Errorf("resolve the remaining conflicts before continuing")
+		}
+
+		if rebasing {
+			err = git.ContinueRebase(pc.CWD)
+		} else {
+			err = git.ContinueMerge(pc.CWD)
+		}
+		if isSyncConflict(err) {
+			// The rebase stopped again on a later commit.
+			return handleSyncConflict(pc, yes, quiet)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := restoreSyncStash(pc.CWD, quiet); err != nil {
+		return err
+	}
+
+	if !rebasing && !merging {
+		ui.PrintDone("No rebase or merge in progress")
+		return nil
+	}
+	ui.PrintDone("Sync complete")
+	return nil
+}
+
+// abortSync aborts the rebase or merge a conflicted sync stopped in, which
+// restores the branch, then restores the auto-stash.
+func abortSync(pc *ProjectContext, quiet bool) error {
+	switch {
+	case git.IsRebaseInProgress(pc.CWD):
+		if err := git.AbortRebase(pc.CWD); err != nil {
+			return err
+		}
+	case git.IsMergeInProgress(pc.CWD):
+		if err := git.AbortMerge(pc.CWD); err != nil {
+			return err
+		}
+	default:
+		ui.PrintInfo("No rebase or merge in progress")
+	}
+
+	if err := restoreSyncStash(pc.CWD, quiet); err != nil {
+		return err
+	}
+
+	ui.PrintDone("Sync aborted")
+	return nil
+}
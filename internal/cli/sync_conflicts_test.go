@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+// setupConflictedSync stops a rebase or merge ("rebase" or "merge") of the
+// feature worktree onto the other branch with a conflict in README.md,
+// after auto-stashing an edit to notes.txt as a sync does.
+func setupConflictedSync(t *testing.T, strategy string) *ProjectContext {
+	t.Helper()
+	_, featurePath := setupRecycleProject(t, "default_branch: main\n")
+	runGitCmd(t, featurePath, "config", "user.email", "test@example.com")
+	runGitCmd(t, featurePath, "config", "user.name", "Test User")
+	require.NoError(t, os.WriteFile(filepath.Join(featurePath, "README.md"), []byte("feature\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(featurePath, "notes.txt"), []byte("committed\n"), 0644))
+	runGitCmd(t, featurePath, "add", ".")
+	runGitCmd(t, featurePath, "commit", "-m", "Change README on feature")
+
+	require.NoError(t, os.WriteFile(filepath.Join(featurePath, "notes.txt"), []byte("uncommitted\n"), 0644))
+	require.NoError(t, git.StashAll(featurePath, "arbor sync"))
+	stash, err := git.LatestStash(featurePath)
+	require.NoError(t, err)
+	require.NoError(t, recordSyncStash(featurePath, stash))
+
+	cmd := exec.Command("git", "-C", featurePath, strategy, "other")
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err, "the sync should stop with a conflict")
+	require.Contains(t, string(output), "CONFLICT")
+	return &ProjectContext{CWD: featurePath}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSyncStash_RecordAndTake(t *testing.T) {
+	_, featurePath := setupRecycleProject(t, "default_branch: main\n")
+
+	commit, err := takeSyncStash(featurePath)
+	require.NoError(t, err)
+	assert.Empty(t, commit, "no stash recorded")
+
+	require.NoError(t, recordSyncStash(featurePath, "abc123"))
+	commit, err = takeSyncStash(featurePath)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", commit)
+
+	commit, err = takeSyncStash(featurePath)
+	require.NoError(t, err)
+	assert.Empty(t, commit, "taking the stash forgets it")
+}
+
+func TestIsSyncConflict(t *testing.T) {
+	assert.True(t, isSyncConflict(&git.RebaseConflictError{}))
+	assert.True(t, isSyncConflict(&git.MergeConflictError{}))
+	assert.False(t, isSyncConflict(errors.New("git rebase failed")))
+	assert.False(t, isSyncConflict(nil))
+}
+
+func TestHandleSyncConflict_WithoutPrompting(t *testing.T) {
+	pc := setupConflictedSync(t, "rebase")
+
+	err := handleSyncConflict(pc, true, true)
+
+	assert.EqualError(t, err, "sync stopped with conflicts in 1 file(s)")
+	assert.True(t, git.IsRebaseInProgress(pc.CWD), "the rebase is left for --continue or --abort")
+}
+
+func TestContinueSync_Rebase(t *testing.T) {
+	pc := setupConflictedSync(t, "rebase")
+
+	err := continueSync(pc, true, true)
+	assert.EqualError(t, err, "resolve the remaining conflicts before continuing")
+	assert.True(t, git.IsRebaseInProgress(pc.CWD))
+
+	require.NoError(t, os.WriteFile(filepath.Join(pc.CWD, "README.md"), []byte("resolved\n"), 0644))
+	require.NoError(t, continueSync(pc, true, true))
+
+	assert.False(t, git.IsRebaseInProgress(pc.CWD))
+	assert.Equal(t, "resolved\n", readTestFile(t, filepath.Join(pc.CWD, "README.md")))
+	assert.Equal(t, "uncommitted\n", readTestFile(t, filepath.Join(pc.CWD, "notes.txt")), "the auto-stash is restored")
+}
+
+func TestContinueSync_Merge(t *testing.T) {
+	pc := setupConflictedSync(t, "merge")
+	require.NoError(t, os.WriteFile(filepath.Join(pc.CWD, "README.md"), []byte("resolved\n"), 0644))
+
+	require.NoError(t, continueSync(pc, true, true))
+
+	assert.False(t, git.IsMergeInProgress(pc.CWD))
+	assert.Equal(t, "uncommitted\n", readTestFile(t, filepath.Join(pc.CWD, "notes.txt")))
+}
+
+func TestAbortSync(t *testing.T) {
+	for _, strategy := range []string{"rebase", "merge"} {
+		t.Run(strategy, func(t *testing.T) {
+			pc := setupConflictedSync(t, strategy)
+
+			require.NoError(t, abortSync(pc, true))
+
+			assert.False(t, git.IsRebaseInProgress(pc.CWD))
+			assert.False(t, git.IsMergeInProgress(pc.CWD))
+			assert.Equal(t, "feature\n", readTestFile(t, filepath.Join(pc.CWD, "README.md")), "the branch is as before the sync")
+			assert.Equal(t, "uncommitted\n", readTestFile(t, filepath.Join(pc.CWD, "notes.txt")), "the auto-stash is restored")
+		})
+	}
+}
+
+func TestContinueSync_NothingInProgress(t *testing.T) {
+	_, featurePath := setupRecycleProject(t, "default_branch: main\n")
+
+	assert.NoError(t, continueSync(&ProjectContext{CWD: featurePath}, true, true))
+}
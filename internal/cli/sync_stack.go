@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// stackSyncOptions holds the resolved sync settings for 'arbor sync --stack'.
+type stackSyncOptions struct {
+	Remote        string
+	Upstream      string
+	RebaseOptions []string
+	AutoStash     bool
+	DryRun        bool
+	Verbose       bool
+	Quiet         bool
+}
+
+// stackEntry is a branch of a stack and the worktree it is checked out in.
+type stackEntry struct {
+	Branch string
+	Parent string
+	Path   string
+}
+
+// syncStack rebases the bottom of the current branch's stack onto
+// remote/upstream, then each branch stacked on it onto its parent, in
+// order, in each branch's own worktree.
+func syncStack(pc *ProjectContext, currentBranch string, opts stackSyncOptions) error {
+	entries, err := planStackSync(pc, currentBranch, opts.Upstream)
+	if err != nil {
+		return err
+	}
+
+	if !opts.Quiet {
+		ui.PrintStep(fmt.Sprintf("Syncing stack of %d branch(es) with '%s/%s'", len(entries), opts.Remote, opts.Upstream))
+		for _, entry := range entries {
+			parent := entry.Parent
+			if parent == "" {
+				parent = opts.Remote + "/" + opts.Upstream
+			}
+			ui.PrintInfo(fmt.Sprintf("  %s onto %s", entry.Branch, parent))
+		}
+	}
+
+	if opts.DryRun {
+		ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would fetch from %s", opts.Remote))
+		ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would rebase %d branch(es) in order", len(entries)))
+		ui.PrintDone("Dry run complete")
+		return nil
+	}
+
+	for _, entry := range entries {
+		if git.IsRebaseInProgress(entry.Path) || git.IsMergeInProgress(entry.Path) {
+			return fmt.Errorf("rebase or merge in progress in %s - finish it with 'arbor sync --continue' there first", entry.Path)
+		}
+	}
+
+	if err := git.FetchRemote(pc.BarePath, opts.Remote); err != nil {
+		return fmt.Errorf("fetch failed: %w", err)
+	}
+	if !opts.Quiet {
+		ui.PrintSuccess(fmt.Sprintf("Fetched from %s", opts.Remote))
+	}
+
+	// Each branch's tip before it is rebased, so its children replay only
+	// their own commits onto the rewritten parent.
+	oldTips := make(map[string]string)
+	for _, entry := range entries {
+		tip, err := git.ResolveCommit(pc.BarePath, "refs/heads/"+entry.Branch)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", entry.Branch, err)
+		}
+		oldTips[entry.Branch] = tip
+	}
+
+	for _, entry := range entries {
+		if err := rebaseStackEntry(entry, oldTips, opts); err != nil {
+			return err
+		}
+	}
+
+	ui.PrintDone(fmt.Sprintf("Stack of %d branch(es) is now in sync with '%s/%s'", len(entries), opts.Remote, opts.Upstream))
+	return nil
+}
+
+// planStackSync returns the branches of currentBranch's stack, each after
+// its parent. The bottom of the stack has no Parent: it is rebased onto
+// upstream. Branches without a worktree, and branches stacked on them, are
+// skipped.
+func planStackSync(pc *ProjectContext, currentBranch, upstream string) ([]stackEntry, error) {
+	parents, err := git.BranchParents(pc.BarePath)
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := git.ListWorktrees(pc.BarePath)
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+	paths := make(map[string]string)
+	for _, wt := range worktrees {
+		if !wt.Detached {
+			paths[wt.Branch] = wt.Path
+		}
+	}
+
+	root := git.StackRoot(parents, currentBranch, upstream)
+	if _, ok := paths[root]; !ok {
+		return nil, fmt.Errorf("'%s', the bottom of the stack, has no worktree - create one with 'arbor work %s'", root, root)
+	}
+	entries := []stackEntry{{Branch: root, Path: paths[root]}}
+	included := map[string]bool{root: true}
+	for _, branch := range git.StackBranches(parents, root) {
+		parent := parents[branch]
+		path, ok := paths[branch]
+		if !ok || !included[parent] {
+			ui.PrintWarning(fmt.Sprintf("Skipping '%s': it has no worktree or its parent was skipped", branch))
+			continue
+		}
+		included[branch] = true
+		entries = append(entries, stackEntry{Branch: branch, Parent: parent, Path: path})
+	}
+
+	if len(entries) == 1 && root == currentBranch {
+		ui.PrintInfo(fmt.Sprintf("No branches are stacked on '%s'", currentBranch))
+	}
+	return entries, nil
+}
+
+// rebaseStackEntry rebases one branch of a stack in its worktree,
+// auto-stashing and restoring its changes around the rebase.
+func rebaseStackEntry(entry stackEntry, oldTips map[string]string, opts stackSyncOptions) error {
+	var stashCommit string
+	if opts.AutoStash {
+		hasChanges, err := git.HasChanges(entry.Path)
+		if err != nil {
+			return fmt.Errorf("checking for changes in %s: %w", entry.Path, err)
+		}
+		if hasChanges {
+			if err := git.StashAll(entry.Path, "arbor sync auto-stash"); err != nil {
+				return fmt.Errorf("failed to stash changes in %s: %w", entry.Path, err)
+			}
+			if stashCommit, err = git.LatestStash(entry.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	var err error
+	onto := entry.Parent
+	if entry.Parent == "" {
+		onto = opts.Remote + "/" + opts.Upstream
+		err = git.RebaseOnto(entry.Path, opts.Remote, opts.Upstream, opts.RebaseOptions...)
+	} else {
+		err = git.RebaseBranchOnto(entry.Path, entry.Parent, oldTips[entry.Parent], opts.RebaseOptions...)
+	}
+
+	if isSyncConflict(err) {
+		if stashCommit != "" {
+			if err := recordSyncStash(entry.Path, stashCommit); err != nil {
+				return err
+			}
+		}
+		ui.PrintWarning(fmt.Sprintf("Rebasing '%s' onto '%s' stopped with conflicts", entry.Branch, onto))
+		ui.PrintInfo(fmt.Sprintf("Resolve them in %s and run 'arbor sync --continue' there,", entry.Path))
+		ui.PrintInfo("then run 'arbor sync --stack' again to rebase the rest of the stack.")
+		return fmt.Errorf("stack sync stopped with conflicts in '%s'", entry.Branch)
+	}
+	if err != nil {
+		if stashCommit != "" {
+			ui.PrintInfo(fmt.Sprintf("\nChanges in %s are preserved in the stash.", entry.Path))
+		}
+		return fmt.Errorf("rebasing '%s': %w", entry.Branch, err)
+	}
+
+	if !opts.Quiet {
+		ui.PrintSuccess(fmt.Sprintf("Rebased '%s' onto '%s'", entry.Branch, onto))
+	}
+
+	if stashCommit != "" {
+		if err := git.PopStashCommit(entry.Path, stashCommit); err != nil {
+			reportStashPopError(err)
+		} else if opts.Verbose && !opts.Quiet {
+			ui.PrintInfo(fmt.Sprintf("Restored stashed changes in %s", entry.Path))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+// setupStackProject stacks child on feature on main, each with a commit of
+// its own, and adds a commit to main upstream that the stack lacks.
+func setupStackProject(t *testing.T) *ProjectContext {
+	t.Helper()
+	projectDir, featurePath := setupRecycleProject(t, "default_branch: main\n")
+	repoDir := filepath.Join(filepath.Dir(projectDir), "repo")
+	barePath := filepath.Join(projectDir, ".bare")
+	runGitCmd(t, barePath, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+	runGitCmd(t, barePath, "config", "user.email", "test@example.com")
+	runGitCmd(t, barePath, "config", "user.name", "Test User")
+	runGitCmd(t, barePath, "fetch", "origin")
+
+	commitFile := func(dir, name string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644))
+		runGitCmd(t, dir, "add", name)
+		runGitCmd(t, dir, "commit", "-m", "Add "+name)
+	}
+	commitFile(featurePath, "feature.txt")
+	require.NoError(t, git.SetBranchParent(barePath, "feature", "main"))
+
+	childPath := filepath.Join(projectDir, "child")
+	require.NoError(t, git.CreateWorktree(barePath, childPath, "child", "feature"))
+	require.NoError(t, git.SetBranchParent(barePath, "child", "feature"))
+	commitFile(childPath, "child.txt")
+
+	commitFile(repoDir, "upstream.txt")
+	return &ProjectContext{ProjectPath: projectDir, BarePath: barePath, CWD: childPath, DefaultBranch: "main"}
+}
+
+func stackTestOptions() stackSyncOptions {
+	return stackSyncOptions{Remote: "origin", Upstream: "main", AutoStash: true, Quiet: true}
+}
+
+// commitSubjects returns the subjects of the commits on branch, newest
+// first.
+func commitSubjects(t *testing.T, barePath, branch string) []string {
+	t.Helper()
+	output, err := exec.Command("git", "-C", barePath, "log", "--format=%s", branch).Output()
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+func TestPlanStackSync(t *testing.T) {
+	pc := setupStackProject(t)
+	// Branches without a worktree, and those stacked on them, are skipped.
+	require.NoError(t, git.SetBranchParent(pc.BarePath, "orphan", "child"))
+	require.NoError(t, git.SetBranchParent(pc.BarePath, "orphan-child", "orphan"))
+
+	entries, err := planStackSync(pc, "child", "main")
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "feature", entries[0].Branch)
+	assert.Empty(t, entries[0].Parent, "the bottom of the stack is rebased onto upstream")
+	assert.Equal(t, stackEntry{Branch: "child", Parent: "feature", Path: pc.CWD}, entries[1])
+}
+
+func TestPlanStackSync_RootWithoutWorktree(t *testing.T) {
+	pc := setupStackProject(t)
+	require.NoError(t, git.SetBranchParent(pc.BarePath, "feature", "base"))
+
+	_, err := planStackSync(pc, "child", "main")
+
+	assert.ErrorContains(t, err, "'base', the bottom of the stack, has no worktree")
+}
+
+func TestSyncStack(t *testing.T) {
+	pc := setupStackProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(pc.CWD, "child.txt"), []byte("uncommitted\n"), 0644))
+
+	require.NoError(t, syncStack(pc, "child", stackTestOptions()))
+
+	assert.Equal(t, []string{"Add feature.txt", "Add upstream.txt", "Initial commit"}, commitSubjects(t, pc.BarePath, "feature"), "feature is rebased onto origin/main")
+	assert.Equal(t, []string{"Add child.txt", "Add feature.txt", "Add upstream.txt", "Initial commit"}, commitSubjects(t, pc.BarePath, "child"), "child replays only its own commit onto feature")
+	assert.FileExists(t, filepath.Join(pc.CWD, "upstream.txt"))
+	assert.Equal(t, "uncommitted\n", readTestFile(t, filepath.Join(pc.CWD, "child.txt")), "auto-stashed changes are restored")
+}
+
+func TestSyncStack_DryRun(t *testing.T) {
+	pc := setupStackProject(t)
+	before := commitSubjects(t, pc.BarePath, "child")
+
+	opts := stackTestOptions()
+	opts.DryRun = true
+	require.NoError(t, syncStack(pc, "child", opts))
+
+	assert.Equal(t, before, commitSubjects(t, pc.BarePath, "child"))
+	assert.NoFileExists(t, filepath.Join(pc.CWD, "upstream.txt"))
+}
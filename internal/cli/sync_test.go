@@ -5,8 +5,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
@@ -155,17 +157,15 @@ default_branch: main
 
 func TestSyncCommand_ValidatesStrategy(t *testing.T) {
 	// Test that invalid strategies are rejected
-	validStrategies := []string{"rebase", "merge"}
-	invalidStrategies := []string{"squash", "fast-forward", ""}
+	validStrategies := []string{"rebase", "merge", "ff-only"}
+	invalidStrategies := []string{"squash", "fast-forward", "ff", ""}
 
 	for _, strategy := range validStrategies {
-		assert.True(t, strategy == "rebase" || strategy == "merge", "strategy %q should be valid", strategy)
+		assert.True(t, isValidSyncStrategy(strategy), "strategy %q should be valid", strategy)
 	}
 
 	for _, strategy := range invalidStrategies {
-		if strategy != "" {
-			assert.False(t, strategy == "rebase" || strategy == "merge", "strategy %q should be invalid", strategy)
-		}
+		assert.False(t, isValidSyncStrategy(strategy), "strategy %q should be invalid", strategy)
 	}
 }
 
@@ -253,6 +253,25 @@ func TestSyncCommand_SaveConfig(t *testing.T) {
 	assert.Equal(t, "origin", loadedConfig.Sync.Remote)
 }
 
+func TestSaveSyncConfig_KeepsTrust(t *testing.T) {
+	projectDir := t.TempDir()
+	configPath := filepath.Join(projectDir, "arbor.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("default_branch: main\n"), 0644))
+	require.NoError(t, config.WriteTrustRecord(projectDir, config.TrustRecord{ConfigHash: config.HashConfig([]byte("default_branch: main\n")), TrustedAt: time.Now().UTC()}))
+
+	cfg, err := config.LoadProject(projectDir)
+	require.NoError(t, err)
+	cfg.Sync = config.SyncConfig{Upstream: "develop", Strategy: "rebase", Remote: "origin"}
+	require.NoError(t, saveSyncConfig(&ProjectContext{ProjectPath: projectDir, Config: cfg}))
+
+	data := readTestFile(t, configPath)
+	assert.Contains(t, data, "develop")
+	record, err := config.ReadTrustRecord(projectDir)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.True(t, record.Trusts([]byte(data)), "the saved config is still trusted")
+}
+
 func TestSyncCommand_DoesNotStashWhenRemoteMissing(t *testing.T) {
 	ensureSyncTestFlags(t)
 
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/concurrency"
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// testOutputTail is how many lines of a failed run's output are shown
+// when several worktrees are tested.
+const testOutputTail = 20
+
+var testCmd = &cobra.Command{
+	Use:   "test [WORKTREE...]",
+	Short: i18n.T("cmd.test.short"),
+	Long: `Runs the project's tests in one or more worktrees, e.g. to check several
+branches before a release.
+
+The test command is test.command in arbor.yaml. Without it, arbor uses the
+test script of composer.json, Pest, 'php artisan test' or PHPUnit for PHP
+projects, and the test script of package.json, run with the package manager
+whose lockfile is present, for node projects.
+
+With one worktree the output is streamed as the tests run. With several, or
+--all, up to --jobs worktrees are tested at once, their output is captured,
+and a results table is printed, followed by the end of the output of each
+failed run (all output with --verbose). The command exits non-zero when any
+worktree's tests fail.
+
+Arguments:
+  WORKTREE  Worktree folder name, branch or path relative to the project
+            root, or part of one when it matches a single worktree
+            (defaults to the current worktree, or the default branch)`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := detailedWorktrees(pc, pc.CWD)
+		if err != nil {
+			return err
+		}
+
+		var targets []git.Worktree
+		switch {
+		case mustGetBool(cmd, "all"):
+			if len(args) > 0 {
+				return fmt.Errorf("--all and WORKTREE cannot be used together")
+			}
+			targets = worktrees
+		case len(args) == 0:
+			wt, err := resolveContextWorktree(pc, worktrees, "")
+			if err != nil {
+				return err
+			}
+			targets = []git.Worktree{*wt}
+		default:
+			for _, arg := range args {
+				wt, err := matchWorktree(worktrees, pc.ProjectPath, arg)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, *wt)
+			}
+		}
+
+		// test.command comes from arbor.yaml, so a config copied from the
+		// repository must be trusted first.
+		promptMode := types.PromptMode{Interactive: ui.IsInteractive(), CI: os.Getenv("CI") != ""}
+		if err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode); err != nil {
+			return err
+		}
+
+		shell := pc.Config.Shell
+		if shell == "" {
+			shell = config.DefaultShell
+		}
+		policy := pc.Config.Policy
+
+		if len(targets) == 1 {
+			result := runWorktreeTests(targets[0], pc.Config.Test.Command, shell, policy, os.Stdout)
+			if result.Command == "" {
+				return fmt.Errorf("no test command found for %s (set test.command in arbor.yaml)", filepath.Base(targets[0].Path))
+			}
+			if result.Err != nil {
+				return fmt.Errorf("tests failed in %s: %w", filepath.Base(targets[0].Path), result.Err)
+			}
+			ui.PrintDone(fmt.Sprintf("Tests passed in %s (%s)", filepath.Base(targets[0].Path), formatTestDuration(result.Duration)))
+			return nil
+		}
+
+		jobs := mustGetInt(cmd, "jobs")
+		if jobs <= 0 {
+			jobs = concurrency.DefaultLimit()
+		}
+		ui.PrintStep(fmt.Sprintf("Testing %d worktrees, %d at a time", len(targets), min(jobs, len(targets))))
+
+		results, _ := concurrency.Map(targets, jobs, func(wt git.Worktree) (testResult, error) {
+			var output bytes.Buffer
+			result := runWorktreeTests(wt, pc.Config.Test.Command, shell, policy, &output)
+			result.Output = output.String()
+			return result, nil
+		})
+
+		printTestResults(os.Stdout, results, mustGetBool(cmd, "verbose"))
+
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d worktrees failed their tests", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// testResult is the outcome of running the tests in one worktree. Command
+// is empty when no test command was found, and the worktree was skipped.
+type testResult struct {
+	Worktree git.Worktree
+	Command  string
+	Duration time.Duration
+	Output   string
+	Err      error
+}
+
+// runTestCommand runs command through shell in dir, writing its output to
+// out. Tests replace it to avoid running real test suites.
+var runTestCommand = func(dir, shell, command string, out io.Writer) error {
+	args, err := arbor_exec.ShellArgs(shell, command)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(shell, args...)
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// runWorktreeTests runs the configured test command, or the one detected
+// for the worktree, writing the output to out. Commands matching
+// policy.deny_commands fail without running.
+func runWorktreeTests(wt git.Worktree, command, shell string, policy config.PolicyConfig, out io.Writer) testResult {
+	result := testResult{Worktree: wt, Command: command}
+	if result.Command == "" {
+		result.Command = detectTestCommand(wt.Path)
+	}
+	if result.Command == "" {
+		return result
+	}
+	if pattern, denied := policy.DeniedCommand(result.Command); denied {
+		result.Err = fmt.Errorf("command matches policy.deny_commands pattern %q", pattern)
+		return result
+	}
+
+	started := time.Now()
+	result.Err = runTestCommand(wt.Path, shell, result.Command, out)
+	result.Duration = time.Since(started)
+	return result
+}
+
+// detectTestCommand returns the command that runs the tests of the project
+// in path, or "" when none is found. PHP projects are checked first: a
+// Laravel app's package.json rarely has tests of its own.
+func detectTestCommand(path string) string {
+	var composer struct {
+		Scripts    map[string]any    `json:"scripts"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "composer.json")); err == nil && json.Unmarshal(data, &composer) == nil {
+		switch {
+		case composer.Scripts["test"] != nil:
+			return "composer test"
+		case composer.RequireDev["pestphp/pest"] != "":
+			return "vendor/bin/pest"
+		case fileExists(filepath.Join(path, "artisan")):
+			return "php artisan test"
+		case composer.RequireDev["phpunit/phpunit"] != "":
+			return "vendor/bin/phpunit"
+		}
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "package.json")); err == nil && json.Unmarshal(data, &pkg) == nil {
+		// npm init writes a test script that only fails.
+		if script := pkg.Scripts["test"]; script != "" && !strings.Contains(script, "no test specified") {
+			switch {
+			case fileExists(filepath.Join(path, "pnpm-lock.yaml")):
+				return "pnpm test"
+			case fileExists(filepath.Join(path, "yarn.lock")):
+				return "yarn test"
+			case fileExists(filepath.Join(path, "bun.lock")), fileExists(filepath.Join(path, "bun.lockb")):
+				return "bun run test"
+			}
+			return "npm test"
+		}
+	}
+
+	return ""
+}
+
+// printTestResults prints a table of the results, then the output of each
+// failed run: its last testOutputTail lines, or all of it when verbose.
+func printTestResults(w io.Writer, results []testResult, verbose bool) {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		status, duration := "✓ passed", formatTestDuration(result.Duration)
+		switch {
+		case result.Command == "":
+			status, duration = "- no test command", ""
+		case result.Err != nil:
+			status = "✗ failed"
+			var exitErr *exec.ExitError
+			if errors.As(result.Err, &exitErr) {
+				status = fmt.Sprintf("✗ failed (exit %d)", exitErr.ExitCode())
+			}
+		}
+		rows = append(rows, []string{filepath.Base(result.Worktree.Path), result.Worktree.Label(), result.Command, status, duration})
+	}
+	fmt.Fprintln(w, ui.RenderTable([]string{"Worktree", "Branch", "Command", "Result", "Duration"}, rows))
+
+	for _, result := range results {
+		if result.Output == "" || (result.Err == nil && !verbose) {
+			continue
+		}
+		output := strings.TrimRight(result.Output, "\n")
+		lines := strings.Split(output, "\n")
+		header := fmt.Sprintf("── %s ", filepath.Base(result.Worktree.Path))
+		if !verbose && len(lines) > testOutputTail {
+			lines = lines[len(lines)-testOutputTail:]
+			header += fmt.Sprintf("(last %d lines) ", testOutputTail)
+		}
+		fmt.Fprintf(w, "\n%s\n%s\n", header, strings.Join(lines, "\n"))
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func formatTestDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().Bool("all", false, "Test every worktree")
+	testCmd.Flags().IntP("jobs", "j", 0, "Worktrees to test at once (default: number of CPUs)")
+}
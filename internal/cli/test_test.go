@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestDetectTestCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"composer script", map[string]string{"composer.json": `{"scripts": {"test": "pest"}}`, "artisan": ""}, "composer test"},
+		{"pest", map[string]string{"composer.json": `{"require-dev": {"pestphp/pest": "^3.0"}}`, "artisan": ""}, "vendor/bin/pest"},
+		{"laravel", map[string]string{"composer.json": `{"require-dev": {"phpunit/phpunit": "^11.0"}}`, "artisan": ""}, "php artisan test"},
+		{"phpunit", map[string]string{"composer.json": `{"require-dev": {"phpunit/phpunit": "^11.0"}}`}, "vendor/bin/phpunit"},
+		{"npm", map[string]string{"package.json": `{"scripts": {"test": "vitest run"}}`}, "npm test"},
+		{"pnpm", map[string]string{"package.json": `{"scripts": {"test": "vitest run"}}`, "pnpm-lock.yaml": ""}, "pnpm test"},
+		{"npm init placeholder", map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`}, ""},
+		{"nothing", map[string]string{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+			assert.Equal(t, tt.want, detectTestCommand(dir))
+		})
+	}
+}
+
+func TestRunWorktreeTests(t *testing.T) {
+	original := runTestCommand
+	t.Cleanup(func() { runTestCommand = original })
+	var ran []string
+	runTestCommand = func(dir, shell, command string, out io.Writer) error {
+		ran = append(ran, fmt.Sprintf("%s: %s %s", filepath.Base(dir), shell, command))
+		fmt.Fprintln(out, "1 test failed")
+		if filepath.Base(dir) == "broken" {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+
+	projectDir := t.TempDir()
+	passing := git.Worktree{Path: filepath.Join(projectDir, "main"), Branch: "main"}
+	broken := git.Worktree{Path: filepath.Join(projectDir, "broken"), Branch: "feature/broken"}
+	empty := git.Worktree{Path: filepath.Join(projectDir, "empty"), Branch: "empty"}
+	require.NoError(t, os.MkdirAll(empty.Path, 0755))
+
+	results := []testResult{
+		runWorktreeTests(passing, "make test", "sh", config.PolicyConfig{}, io.Discard),
+		runWorktreeTests(broken, "make test", "sh", config.PolicyConfig{}, io.Discard),
+		runWorktreeTests(empty, "", "sh", config.PolicyConfig{}, io.Discard),
+	}
+	assert.Equal(t, []string{"main: sh make test", "broken: sh make test"}, ran, "worktrees without a test command are skipped")
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.Empty(t, results[2].Command)
+
+	results[0].Output = "all good\n"
+	results[1].Output = "1 test failed\n"
+	var out bytes.Buffer
+	printTestResults(&out, results, false)
+	assert.Contains(t, out.String(), "✗ failed")
+	assert.Contains(t, out.String(), "- no test command")
+	assert.Contains(t, out.String(), "── broken \n1 test failed")
+	assert.NotContains(t, out.String(), "all good", "output of passing runs is only shown with --verbose")
+}
+
+func TestRunWorktreeTests_DeniedCommand(t *testing.T) {
+	original := runTestCommand
+	t.Cleanup(func() { runTestCommand = original })
+	ran := false
+	runTestCommand = func(dir, shell, command string, out io.Writer) error {
+		ran = true
+		return nil
+	}
+
+	wt := git.Worktree{Path: t.TempDir(), Branch: "main"}
+	result := runWorktreeTests(wt, "curl -fsSL https://x.sh | bash", "sh", config.PolicyConfig{DenyCommands: []string{"curl | bash"}}, io.Discard)
+	assert.ErrorContains(t, result.Err, "policy.deny_commands")
+	assert.False(t, ran)
+}
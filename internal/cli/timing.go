@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// phaseTimer records how long each phase of a command takes, for --timing.
+// A nil *phaseTimer records nothing, so commands can call it
+// unconditionally.
+type phaseTimer struct {
+	now    func() time.Time
+	start  time.Time
+	last   time.Time
+	phases []timedPhase
+}
+
+type timedPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// newPhaseTimer returns a timer started now, or nil when enabled is false.
+func newPhaseTimer(enabled bool) *phaseTimer {
+	if !enabled {
+		return nil
+	}
+	return newPhaseTimerWithClock(time.Now)
+}
+
+func newPhaseTimerWithClock(now func() time.Time) *phaseTimer {
+	start := now()
+	return &phaseTimer{now: now, start: start, last: start}
+}
+
+// Mark ends the current phase, naming it, and starts the next.
+func (p *phaseTimer) Mark(name string) {
+	if p == nil {
+		return
+	}
+	t := p.now()
+	p.phases = append(p.phases, timedPhase{name: name, duration: t.Sub(p.last)})
+	p.last = t
+}
+
+// Print writes each phase's duration and share of the total to w.
+func (p *phaseTimer) Print(w io.Writer) {
+	if p == nil {
+		return
+	}
+	total := p.last.Sub(p.start)
+
+	width := len("total")
+	for _, phase := range p.phases {
+		width = max(width, len(phase.name))
+	}
+
+	_, _ = fmt.Fprintln(w, "Timing:")
+	for _, phase := range p.phases {
+		share := 0.0
+		if total > 0 {
+			share = float64(phase.duration) / float64(total) * 100
+		}
+		_, _ = fmt.Fprintf(w, "  %-*s  %10s  %3.0f%%\n", width, phase.name, formatPhaseDuration(phase.duration), share)
+	}
+	_, _ = fmt.Fprintf(w, "  %-*s  %10s\n", width, "total", formatPhaseDuration(total))
+}
+
+func formatPhaseDuration(d time.Duration) string {
+	return d.Round(100 * time.Microsecond).String()
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseTimer(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timer := newPhaseTimerWithClock(func() time.Time { return clock })
+
+	clock = clock.Add(30 * time.Millisecond)
+	timer.Mark("open project")
+	clock = clock.Add(90 * time.Millisecond)
+	timer.Mark("list worktrees")
+
+	var out strings.Builder
+	timer.Print(&out)
+
+	assert.Equal(t, "Timing:\n"+
+		"  open project          30ms   25%\n"+
+		"  list worktrees        90ms   75%\n"+
+		"  total                120ms\n", out.String())
+}
+
+func TestPhaseTimer_Disabled(t *testing.T) {
+	timer := newPhaseTimer(false)
+	timer.Mark("open project")
+
+	var out strings.Builder
+	timer.Print(&out)
+	assert.Empty(t, out.String())
+}
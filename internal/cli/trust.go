@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+// trustRepoConfig is set by --trust-repo-config.
+var trustRepoConfig bool
+
+// trustListLimit caps the commands listed in the trust prompt.
+const trustListLimit = 15
+
+// confirmTrust asks whether to trust a repository config; tests replace it.
+var confirmTrust = func(title, description string) (bool, error) {
+	return ui.ConfirmWithDefault(title, description, false)
+}
+
+// ensureConfigTrusted stops steps from running when the project's
+// arbor.yaml came from the repository and its content has not been
+// trusted: the user is shown the commands it runs and asked to trust it,
+// and the answer is recorded so the same content is not asked about
+// again. --trust-repo-config trusts it without asking. Projects whose
+// config the user wrote have no trust record and are never asked.
+func ensureConfigTrusted(projectPath string, cfg *config.Config, promptMode types.PromptMode) error {
+	record, err := config.ReadTrustRecord(projectPath)
+	if err != nil || record == nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "arbor.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading project config: %w", err)
+	}
+	if record.Trusts(data) {
+		return nil
+	}
+
+	if !trustRepoConfig {
+		state := "has not been trusted yet"
+		if record.ConfigHash != "" {
+			state = "has changed since you trusted it"
+		}
+		if !promptMode.Allow() {
+			return fmt.Errorf("arbor.yaml comes from the repository and %s; review it, then run again with --trust-repo-config", state)
+		}
+
+		description := "It runs no commands."
+		if commands := configCommands(cfg); len(commands) > 0 {
+			description = "It runs:\n  " + strings.Join(ui.TruncateList(commands, trustListLimit), "\n  ")
+		}
+		confirmed, err := confirmTrust(fmt.Sprintf("arbor.yaml comes from the repository and %s. Trust it?", state), description)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return errors.New("arbor.yaml is not trusted; no steps were run")
+		}
+	}
+
+	return config.WriteTrustRecord(projectPath, config.TrustRecord{ConfigHash: config.HashConfig(data), TrustedAt: time.Now().UTC()})
+}
+
+// configCommands lists the commands a project config's steps, checks and
+// tests run, each with its YAML path, such as
+// "scaffold.steps[0] (bash.run): make setup".
+func configCommands(cfg *config.Config) []string {
+	var commands []string
+	addSteps := func(located []config.LocatedStep) {
+		for _, step := range located {
+			if command := stepCommand(step.Step); command != "" {
+				commands = append(commands, fmt.Sprintf("%s (%s): %s", step.Location, step.Step.Name, command))
+			}
+		}
+	}
+
+	// Invalid group references are left out: arbor refuses to run them.
+	scaffoldSteps, _ := cfg.ExpandSteps("scaffold.steps", cfg.Scaffold.Steps)
+	addSteps(scaffoldSteps)
+	for i, step := range cfg.Cleanup.Steps {
+		location := fmt.Sprintf("cleanup.steps[%d]", i)
+		if step.Group != "" {
+			groupSteps, _ := cfg.ExpandCleanupGroup(step, location)
+			addSteps(groupSteps)
+			for _, groupStep := range groupSteps {
+				if command, ok := groupStep.Step.Condition["command"].(string); ok && command != "" {
+					commands = append(commands, fmt.Sprintf("%s (%s): %s", groupStep.Location, groupStep.Step.Name, command))
+				}
+			}
+			continue
+		}
+		if command, ok := step.Condition["command"].(string); ok && command != "" {
+			commands = append(commands, fmt.Sprintf("%s (%s): %s", location, step.Name, command))
+		}
+	}
+	ciStepConfigs, _ := cfg.ExpandSteps("ci.steps", cfg.CI.Steps)
+	addSteps(ciStepConfigs)
+	for i, check := range cfg.Checks {
+		if check.Command != "" {
+			commands = append(commands, fmt.Sprintf("checks[%d] (%s): %s", i, check.Name, check.Command))
+		}
+	}
+	if cfg.Test.Command != "" {
+		commands = append(commands, "test.command: "+cfg.Test.Command)
+	}
+	return commands
+}
+
+// stepCommand returns the command line a step runs, or "" for steps that
+// run none.
+func stepCommand(step config.StepConfig) string {
+	if step.Name == "bash.run" || step.Name == "command.run" {
+		return step.Command
+	}
+	if binary, ok := steps.BinaryFor(step.Name); ok {
+		return strings.Join(append([]string{binary}, step.Args...), " ")
+	}
+	return ""
+}
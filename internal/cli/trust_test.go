@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+const trustTestConfig = `scaffold:
+  steps:
+    - name: bash.run
+      command: make setup
+    - name: file.copy
+      from: .env.example
+      to: .env
+    - name: php.composer
+      args: [install]
+checks:
+  - name: command
+    command: php artisan test
+test:
+  command: vendor/bin/pest
+`
+
+func TestEnsureConfigTrusted(t *testing.T) {
+	interactive := types.PromptMode{Interactive: true}
+
+	setup := func(t *testing.T, record *config.TrustRecord) (string, *config.Config) {
+		t.Helper()
+		projectPath := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectPath, "arbor.yaml"), []byte(trustTestConfig), 0644))
+		if record != nil {
+			require.NoError(t, config.WriteTrustRecord(projectPath, *record))
+		}
+		cfg, err := config.LoadProject(projectPath)
+		require.NoError(t, err)
+		return projectPath, cfg
+	}
+	stubConfirm := func(t *testing.T, answer bool) *string {
+		t.Helper()
+		var asked string
+		original := confirmTrust
+		confirmTrust = func(title, description string) (bool, error) {
+			asked = title + "\n" + description
+			return answer, nil
+		}
+		t.Cleanup(func() { confirmTrust = original })
+		return &asked
+	}
+	trusted := func(t *testing.T, projectPath string) bool {
+		t.Helper()
+		record, err := config.ReadTrustRecord(projectPath)
+		require.NoError(t, err)
+		return record != nil && record.Trusts([]byte(trustTestConfig))
+	}
+
+	t.Run("user-written configs are not gated", func(t *testing.T) {
+		projectPath, cfg := setup(t, nil)
+		asked := stubConfirm(t, false)
+
+		require.NoError(t, ensureConfigTrusted(projectPath, cfg, interactive))
+		assert.Empty(t, *asked)
+	})
+
+	t.Run("asks with the commands the config runs and records the answer", func(t *testing.T) {
+		projectPath, cfg := setup(t, &config.TrustRecord{})
+		asked := stubConfirm(t, true)
+
+		require.NoError(t, ensureConfigTrusted(projectPath, cfg, interactive))
+		assert.Contains(t, *asked, "has not been trusted yet")
+		assert.Contains(t, *asked, "scaffold.steps[0] (bash.run): make setup")
+		assert.Contains(t, *asked, "scaffold.steps[2] (php.composer): composer install")
+		assert.Contains(t, *asked, "checks[0] (command): php artisan test")
+		assert.Contains(t, *asked, "test.command: vendor/bin/pest")
+		assert.NotContains(t, *asked, "file.copy")
+		assert.True(t, trusted(t, projectPath))
+
+		*asked = ""
+		require.NoError(t, ensureConfigTrusted(projectPath, cfg, interactive))
+		assert.Empty(t, *asked, "trusted content is not asked about again")
+	})
+
+	t.Run("changed configs ask again", func(t *testing.T) {
+		projectPath, cfg := setup(t, &config.TrustRecord{ConfigHash: config.HashConfig([]byte("preset: laravel\n"))})
+		asked := stubConfirm(t, false)
+
+		err := ensureConfigTrusted(projectPath, cfg, interactive)
+		assert.ErrorContains(t, err, "not trusted")
+		assert.Contains(t, *asked, "has changed since you trusted it")
+		assert.False(t, trusted(t, projectPath))
+	})
+
+	t.Run("fails when it cannot ask", func(t *testing.T) {
+		projectPath, cfg := setup(t, &config.TrustRecord{})
+		asked := stubConfirm(t, true)
+
+		err := ensureConfigTrusted(projectPath, cfg, types.PromptMode{NoInteractive: true})
+		assert.ErrorContains(t, err, "--trust-repo-config")
+		assert.Empty(t, *asked)
+	})
+
+	t.Run("--trust-repo-config trusts without asking", func(t *testing.T) {
+		projectPath, cfg := setup(t, &config.TrustRecord{})
+		asked := stubConfirm(t, false)
+		trustRepoConfig = true
+		t.Cleanup(func() { trustRepoConfig = false })
+
+		require.NoError(t, ensureConfigTrusted(projectPath, cfg, types.PromptMode{NoInteractive: true}))
+		assert.Empty(t, *asked)
+		assert.True(t, trusted(t, projectPath))
+	})
+}
+
+func TestConfigCommands_StepGroups(t *testing.T) {
+	enabled := false
+	cfg := &config.Config{
+		StepGroups: map[string][]config.StepConfig{
+			"build": {{Name: "bash.run", Command: "make build"}, {Name: "file.copy", From: "a", To: "b"}},
+		},
+		Scaffold: config.ScaffoldConfig{Steps: []config.StepConfig{{Group: "build", Enabled: &enabled}, {Group: "missing"}}},
+		Cleanup:  config.CleanupConfig{Steps: []config.CleanupStep{{Group: "build"}}},
+	}
+
+	assert.Equal(t, []string{
+		"scaffold.steps[0] > step_groups.build[0] (bash.run): make build",
+		"cleanup.steps[0] > step_groups.build[0] (bash.run): make build",
+	}, configCommands(cfg), "disabled references are listed, since they can be enabled again")
+}
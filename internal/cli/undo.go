@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/trash"
+	"github.com/artisanexperiences/arbor/internal/ui"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [ID]",
+	Short: i18n.T("cmd.undo.short"),
+	Long: `Restores a worktree removed with the trash enabled.
+
+Worktrees removed by 'arbor remove --trash' or 'arbor prune --trash'
+(or with trash.enabled in arbor.yaml) are kept in .arbor/trash until they
+are purged after trash.retention_days (default 7).
+
+Without an ID the most recent removal is restored. The worktree is moved
+back to its original path and linked to git again; the branch is recreated
+if it was deleted. Cleanup steps already ran on removal, so run
+'arbor scaffold' afterwards to recreate databases and site links.
+
+Arguments:
+  ID  Trash entry to restore (see --list)`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pc, err := OpenProjectFromCWD()
+		if err != nil {
+			return err
+		}
+
+		dryRun := mustGetBool(cmd, "dry-run")
+		list := mustGetBool(cmd, "list")
+
+		purgeExpiredTrash(pc)
+
+		if list {
+			return printTrashEntries(pc.ProjectPath)
+		}
+
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+
+		entry, err := trash.Find(pc.ProjectPath, id)
+		if err != nil {
+			if errors.Is(err, trash.ErrEmpty) {
+				ui.PrintInfo("Nothing to undo: the trash is empty.")
+				return nil
+			}
+			return err
+		}
+
+		if dryRun {
+			ui.PrintInfo(fmt.Sprintf("[DRY RUN] Would restore %s to %s", entry.Label(), entry.Path))
+			return nil
+		}
+
+		if err := trash.Restore(pc.ProjectPath, pc.BarePath, *entry); err != nil {
+			return fmt.Errorf("restoring %s: %w", entry.Label(), err)
+		}
+
+		ui.PrintSuccessPath(fmt.Sprintf("Restored %s", entry.Label()), entry.Path)
+		ui.PrintInfo(fmt.Sprintf("Run 'arbor scaffold %s' to recreate databases and site links", filepath.Base(entry.Path)))
+		return nil
+	},
+}
+
+func printTrashEntries(projectPath string) error {
+	entries, err := trash.List(projectPath)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		ui.PrintInfo("The trash is empty.")
+		return nil
+	}
+
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		rows[i] = []string{e.ID, e.Label(), e.Path, e.RemovedAt.Local().Format(time.DateTime)}
+	}
+	fmt.Println(ui.RenderTable([]string{"ID", "BRANCH", "PATH", "REMOVED"}, rows))
+	return nil
+}
+
+// purgeExpiredTrash deletes trash entries older than trash.retention_days.
+// Failures are reported but never block the command.
+func purgeExpiredTrash(pc *ProjectContext) {
+	purged, err := trash.Purge(pc.ProjectPath, trash.Retention(pc.Config.Trash.RetentionDays), time.Now())
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not purge trash: %v", err))
+	}
+	for _, e := range purged {
+		ui.PrintInfo(fmt.Sprintf("Purged %s from trash", e.ID))
+	}
+}
+
+// trashEnabled reports whether removals should go to the trash, either
+// because --trash was passed or trash.enabled is set in arbor.yaml.
+func trashEnabled(cmd *cobra.Command, pc *ProjectContext) bool {
+	return mustGetBool(cmd, "trash") || pc.Config.Trash.Enabled
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().Bool("list", false, "List trash entries instead of restoring")
+}
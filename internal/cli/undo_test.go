@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/trash"
+)
+
+func runUndo(t *testing.T, dir string, args []string, dryRun, list bool) error {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("dry-run", dryRun, "")
+	cmd.Flags().Bool("list", list, "")
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(dir))
+
+	return undoCmd.RunE(cmd, args)
+}
+
+// trashWorktree creates a worktree for branch and moves it to the trash as
+// if it was removed at removedAt.
+func trashWorktree(t *testing.T, projectDir, branch string, removedAt time.Time) *trash.Entry {
+	t.Helper()
+	barePath := filepath.Join(projectDir, ".bare")
+	path := filepath.Join(projectDir, branch)
+	require.NoError(t, git.CreateWorktree(barePath, path, branch, "main"))
+	entry, err := trash.Move(projectDir, barePath, git.Worktree{Path: path, Branch: branch}, removedAt)
+	require.NoError(t, err)
+	return entry
+}
+
+func TestUndoCmd_EmptyTrash(t *testing.T) {
+	projectDir, _ := setupRecycleProject(t, "default_branch: main\n")
+
+	assert.NoError(t, runUndo(t, projectDir, nil, false, false))
+}
+
+func TestUndoCmd_RestoresTheGivenEntry(t *testing.T) {
+	projectDir, _ := setupRecycleProject(t, "default_branch: main\n")
+	older := trashWorktree(t, projectDir, "older", time.Now().Add(-time.Hour))
+	newer := trashWorktree(t, projectDir, "newer", time.Now())
+
+	require.NoError(t, runUndo(t, projectDir, []string{older.ID}, true, false))
+	assert.NoDirExists(t, older.Path, "a dry run restores nothing")
+
+	require.NoError(t, runUndo(t, projectDir, []string{older.ID}, false, false))
+	assert.DirExists(t, older.Path)
+	assert.NoDirExists(t, newer.Path, "only the given entry is restored")
+
+	err := runUndo(t, projectDir, []string{"missing"}, false, false)
+	assert.ErrorContains(t, err, `trash entry "missing" not found`)
+}
+
+func TestUndoCmd_PurgesExpiredEntries(t *testing.T) {
+	projectDir, _ := setupRecycleProject(t, "default_branch: main\ntrash:\n  retention_days: 1\n")
+	expired := trashWorktree(t, projectDir, "expired", time.Now().Add(-48*time.Hour))
+	kept := trashWorktree(t, projectDir, "kept", time.Now())
+
+	require.NoError(t, runUndo(t, projectDir, nil, false, true))
+
+	entries, err := trash.List(projectDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, kept.ID, entries[0].ID)
+	assert.NoDirExists(t, expired.Path, "--list restores nothing")
+}
@@ -1,9 +1,19 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 )
 
 // These variables are set at build time via -ldflags
@@ -13,15 +23,126 @@ var (
 	BuildDate = "unknown"
 )
 
+// versionInfo is the environment report printed by `arbor version`.
+type versionInfo struct {
+	Version   string          `json:"version"`
+	Commit    string          `json:"commit"`
+	BuildDate string          `json:"buildDate"`
+	GoVersion string          `json:"goVersion"`
+	OS        string          `json:"os"`
+	Arch      string          `json:"arch"`
+	Features  map[string]bool `json:"features"`
+	Paths     versionPaths    `json:"paths"`
+}
+
+// versionPaths lists the config locations arbor reads. Project is empty
+// outside an arbor project.
+type versionPaths struct {
+	GlobalConfig       string `json:"globalConfig"`
+	GlobalConfigExists bool   `json:"globalConfigExists"`
+	Locales            string `json:"locales"`
+	Project            string `json:"project,omitempty"`
+}
+
+// versionFeatures are the optional integrations reported by `arbor version`.
+var versionFeatures = []string{"gh", "herd"}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Print version information",
-	Long:  `Display the current version of Arbor.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("arbor version %s (commit: %s, built: %s)\n", Version, Commit, BuildDate)
+	Short: i18n.T("cmd.version.short"),
+	Long: `Display the current version of Arbor along with the environment
+details useful in bug reports: Go version, OS/architecture, available
+integrations (gh, herd) and the config paths in use.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := collectVersionInfo()
+		if mustGetBool(cmd, "json") {
+			return printVersionJSON(os.Stdout, info)
+		}
+		printVersion(os.Stdout, info)
+		return nil
 	},
 }
 
+func collectVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Features:  make(map[string]bool),
+	}
+
+	// Builds without -ldflags (go install, go build) still carry module and
+	// VCS details in the binary.
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "unknown":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "unknown":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+
+	for _, feature := range versionFeatures {
+		info.Features[feature] = isCommandAvailable(feature)
+	}
+
+	if configDir, err := config.GetGlobalConfigDir(); err == nil {
+		info.Paths.GlobalConfig = filepath.Join(configDir, "arbor.yaml")
+		info.Paths.Locales = filepath.Join(configDir, "locales")
+		if _, err := os.Stat(info.Paths.GlobalConfig); err == nil {
+			info.Paths.GlobalConfigExists = true
+		}
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if barePath, err := git.FindBarePath(cwd); err == nil {
+			info.Paths.Project = filepath.Join(filepath.Dir(barePath), "arbor.yaml")
+		}
+	}
+
+	return info
+}
+
+func printVersion(w io.Writer, info versionInfo) {
+	fmt.Fprintf(w, "arbor version %s (commit: %s, built: %s)\n\n", info.Version, info.Commit, info.BuildDate)
+	fmt.Fprintf(w, "Go:             %s\n", info.GoVersion)
+	fmt.Fprintf(w, "Platform:       %s/%s\n", info.OS, info.Arch)
+
+	globalConfig := info.Paths.GlobalConfig
+	if !info.Paths.GlobalConfigExists {
+		globalConfig += " (not found)"
+	}
+	fmt.Fprintf(w, "Global config:  %s\n", globalConfig)
+	fmt.Fprintf(w, "Locales:        %s\n", info.Paths.Locales)
+	if info.Paths.Project != "" {
+		fmt.Fprintf(w, "Project config: %s\n", info.Paths.Project)
+	}
+
+	for _, feature := range versionFeatures {
+		status := "not found"
+		if info.Features[feature] {
+			status = "available"
+		}
+		fmt.Fprintf(w, "%-15s %s\n", feature+":", status)
+	}
+}
+
+func printVersionJSON(w io.Writer, info versionInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().Bool("json", false, "Output version and environment details as JSON")
 }
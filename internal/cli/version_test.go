@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionInfo() versionInfo {
+	return versionInfo{
+		Version:   "1.2.3",
+		Commit:    "abc1234",
+		BuildDate: "2026-10-17T09:30:00Z",
+		GoVersion: "go1.24.0",
+		OS:        "darwin",
+		Arch:      "arm64",
+		Features:  map[string]bool{"gh": true, "herd": false},
+		Paths: versionPaths{
+			GlobalConfig: "/home/user/.config/arbor/arbor.yaml",
+			Locales:      "/home/user/.config/arbor/locales",
+			Project:      "/code/app/arbor.yaml",
+		},
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	var buf bytes.Buffer
+	printVersion(&buf, testVersionInfo())
+	assertGolden(t, "version", buf.String())
+}
+
+func TestPrintVersionJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printVersionJSON(&buf, testVersionInfo()))
+	assertGolden(t, "version_json", buf.String())
+}
@@ -9,15 +9,24 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/scaffold"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/ui"
 	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
+// maxBaseTags caps the tags offered when picking a base interactively.
+const maxBaseTags = 10
+
+// workRemote is the remote work fetches from and sets up tracking on.
+const workRemote = "origin"
+
 var workCmd = &cobra.Command{
 	Use:   "work [BRANCH] [PATH]",
-	Short: "Create or checkout a feature worktree",
+	Short: i18n.T("cmd.work.short"),
 	Long: `Creates or checks out a new worktree for a feature branch.
 
 Arguments:
@@ -25,7 +34,37 @@ Arguments:
   PATH    Optional custom path (defaults to sanitised branch name)
 
 If no branch is provided, interactive mode allows selection from
-available branches or entering a new branch name.`,
+available branches or entering a new branch name, then choosing what a
+new branch is based on.
+
+New branches are based on the default branch unless --base names another
+branch, a remote branch, a tag or a commit:
+
+  arbor work hotfix/login --base origin/release/1.2
+  arbor work audit --base v2.3.0
+
+--detach creates a worktree on a detached HEAD at a tag or commit, for
+example to reproduce a bug against a release:
+
+  arbor work --detach v2.3.1
+
+--matrix creates a worktree per combination of values, for testing a
+branch against several tool versions. Each is named after the branch and
+its values, shares the branch's code on a detached HEAD, and sees the
+values as template variables:
+
+  arbor work feature/x --matrix php=8.2,8.3
+  # feature-x-php82 and feature-x-php83, with {{ .php }} set to 8.2 and 8.3
+
+--record-answers writes the answers given to scaffold prompts, such as
+whether to reuse another worktree's database or run migrations, to a file
+the team can share. --answers replays them without prompting:
+
+  arbor work feature/auth --record-answers team-defaults.yaml
+  arbor work feature/billing --answers team-defaults.yaml
+
+--profile enables the scaffold steps a profile condition gates, e.g. the
+laravel preset's testing steps, as 'arbor scaffold --profile' does.`,
 	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pc, err := OpenProjectFromCWD()
@@ -37,7 +76,55 @@ available branches or entering a new branch name.`,
 		dryRun := mustGetBool(cmd, "dry-run")
 		verbose := mustGetBool(cmd, "verbose")
 		quiet := mustGetBool(cmd, "quiet")
-		skipScaffold := mustGetBool(cmd, "skip-scaffold")
+		skipScaffold := mustGetBool(cmd, "skip-scaffold") || mustGetBool(cmd, "no-scaffold")
+		trust := mustGetBool(cmd, "trust")
+		jsonOutput := mustGetBool(cmd, "json")
+		if profiles, _ := cmd.Flags().GetStringArray("profile"); len(profiles) > 0 && skipScaffold {
+			return fmt.Errorf("--profile cannot be combined with --skip-scaffold or --no-scaffold")
+		}
+		applyProfileFlag(cmd, pc)
+		if jsonOutput {
+			quiet = true
+		}
+		answers, err := workAnswersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		matrixSpecs, _ := cmd.Flags().GetStringArray("matrix")
+		var matrix []matrixCell
+		if len(matrixSpecs) > 0 {
+			if matrix, err = parseMatrix(matrixSpecs); err != nil {
+				return err
+			}
+			switch {
+			case mustGetBool(cmd, "detach"):
+				return fmt.Errorf("--matrix and --detach cannot be used together")
+			case len(args) > 1:
+				return fmt.Errorf("--matrix names each worktree's path itself, so PATH cannot be given")
+			case jsonOutput:
+				return fmt.Errorf("--matrix and --json cannot be used together")
+			}
+		}
+
+		fetched := false
+		if pc.Config.Work.FetchFirst && !mustGetBool(cmd, "no-fetch") && !dryRun {
+			ui.PrintStep(fmt.Sprintf("Fetching %s", workRemote))
+			if err := git.FetchRemote(pc.BarePath, workRemote); err != nil {
+				ui.PrintWarning(fmt.Sprintf("Could not fetch %s, using local refs: %v", workRemote, err))
+			} else {
+				fetched = true
+			}
+		}
+
+		if mustGetBool(cmd, "detach") {
+			if len(args) == 0 {
+				return fmt.Errorf("--detach requires a tag or commit (e.g. arbor work --detach v2.3.1)")
+			}
+			if baseBranch != "" {
+				return fmt.Errorf("--detach and --base cannot be used together")
+			}
+			return workDetached(pc, args, answers, dryRun, verbose, quiet, skipScaffold, trust, jsonOutput)
+		}
 
 		var branch string
 		if len(args) > 0 {
@@ -48,7 +135,7 @@ available branches or entering a new branch name.`,
 				return fmt.Errorf("listing local branches: %w", err)
 			}
 
-			remoteBranches, _ := git.ListRemoteBranches(pc.BarePath)
+			remoteBranches, _ := pc.RemoteBranches(mustGetBool(cmd, "refresh"))
 
 			selected, err := ui.SelectBranchInteractive(pc.BarePath, localBranches, remoteBranches)
 			if err != nil {
@@ -81,10 +168,56 @@ available branches or entering a new branch name.`,
 			}
 		}
 
+		exists := git.BranchExists(pc.BarePath, branch)
+
+		// After a fetch, a branch that exists on the remote is created from
+		// the remote tip rather than branched off a stale default branch.
+		remoteRef := workRemote + "/" + branch
+		onRemote := false
+		if fetched {
+			_, err := git.ResolveCommit(pc.BarePath, "refs/remotes/"+remoteRef)
+			onRemote = err == nil
+		}
+		if onRemote && !exists && baseBranch == "" {
+			baseBranch = remoteRef
+		}
+
+		// Branches picked interactively get their base picked interactively
+		// too, unless --base was given or the branch already exists.
+		if baseBranch == "" && !exists && len(args) == 0 && ui.IsInteractive() {
+			localBranches, _ := git.ListAllBranches(pc.BarePath)
+			remoteBranches, _ := pc.RemoteBranches(mustGetBool(cmd, "refresh"))
+			tags, _ := git.ListTags(pc.BarePath, maxBaseTags)
+
+			selected, err := ui.SelectBaseBranch(branch, pc.DefaultBranch, localBranches, remoteBranches, tags)
+			if err != nil {
+				return fmt.Errorf("selecting base: %w", err)
+			}
+			baseBranch = selected
+		}
+
 		if baseBranch == "" {
 			baseBranch = pc.DefaultBranch
 		}
 
+		if !exists {
+			if _, err := git.ResolveCommit(pc.BarePath, baseBranch); err != nil {
+				return fmt.Errorf("base %q not found (expected a branch, remote branch, tag or commit)", baseBranch)
+			}
+		}
+
+		if len(matrix) > 0 {
+			return workMatrix(pc, branch, baseBranch, exists, matrix, matrixOptions{
+				Answers:      answers,
+				DryRun:       dryRun,
+				Verbose:      verbose,
+				Quiet:        quiet,
+				SkipScaffold: skipScaffold,
+				Trust:        trust,
+				Track:        !mustGetBool(cmd, "no-track"),
+			})
+		}
+
 		worktreePath := ""
 		if len(args) > 1 {
 			worktreePath = args[1]
@@ -97,7 +230,6 @@ available branches or entering a new branch name.`,
 			return fmt.Errorf("getting absolute path: %w", err)
 		}
 
-		exists := git.BranchExists(pc.BarePath, branch)
 		if exists {
 			worktrees, err := git.ListWorktrees(pc.BarePath)
 			if err != nil {
@@ -105,81 +237,213 @@ available branches or entering a new branch name.`,
 			}
 			for _, wt := range worktrees {
 				if wt.Branch == branch {
+					if jsonOutput {
+						state, _ := config.ReadLocalState(wt.Path)
+						pending := state != nil && state.ScaffoldPending
+						return printNextStepsJSON(os.Stdout, buildNextSteps(pc, wt.Path, branch, pending))
+					}
 					ui.PrintInfo(fmt.Sprintf("Worktree already exists at %s", wt.Path))
 					return nil
 				}
 			}
+
+			if onRemote {
+				moved, err := git.FastForwardBranch(pc.BarePath, branch, remoteRef)
+				if err != nil {
+					ui.PrintWarning(err.Error())
+				} else if moved {
+					ui.PrintSuccess(fmt.Sprintf("Fast-forwarded '%s' to %s", branch, remoteRef))
+				}
+			}
 		}
 
-		ui.PrintStep(fmt.Sprintf("Creating worktree for branch '%s' from '%s'", branch, baseBranch))
+		if exists {
+			ui.PrintStep(fmt.Sprintf("Creating worktree for existing branch '%s'", branch))
+		} else {
+			ui.PrintStep(fmt.Sprintf("Creating worktree for branch '%s' from '%s'", branch, baseBranch))
+		}
 		ui.PrintInfo(fmt.Sprintf("Path: %s", absWorktreePath))
 
 		if !dryRun {
 			if err := git.CreateWorktree(pc.BarePath, absWorktreePath, branch, baseBranch); err != nil {
 				return fmt.Errorf("creating worktree: %w", err)
 			}
+			if !exists {
+				recordBranchParent(pc.BarePath, branch, baseBranch)
+			}
+			if err := applyWorktreeSkeleton(pc.Config, pc.ProjectPath, absWorktreePath, verbose); err != nil {
+				ui.PrintWarning(err.Error())
+			}
 		} else {
 			ui.PrintInfo("[DRY RUN] Would create worktree")
 		}
 
-		// Set up branch tracking unless --no-track is specified
-		noTrack := mustGetBool(cmd, "no-track")
-		if !dryRun && !noTrack {
-			if err := git.SetBranchUpstream(pc.BarePath, branch, "origin"); err != nil {
-				// Non-fatal - just inform user if verbose
-				if verbose {
-					ui.PrintInfo(fmt.Sprintf("Could not set up tracking for branch '%s': %v", branch, err))
-				}
-			} else {
-				ui.PrintSuccess(fmt.Sprintf("Set up tracking for branch '%s' on origin", branch))
-			}
+		// Set up branch tracking unless --no-track is specified, so the first
+		// git push and arbor sync work without repair
+		if !dryRun && !mustGetBool(cmd, "no-track") {
+			setUpBranchTracking(pc.BarePath, branch)
 		}
 
-		if !dryRun {
-			if !skipScaffold {
-				preset := pc.Config.Preset
-				if preset == "" {
-					preset = pc.PresetManager().Detect(absWorktreePath)
-				}
+		if err := scaffoldNewWorktree(pc, absWorktreePath, branch, answers, dryRun, verbose, quiet, skipScaffold, trust); err != nil {
+			return err
+		}
 
-				if verbose && preset != "" {
-					ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", preset))
-				}
+		return finishWork(pc, absWorktreePath, branch, fmt.Sprintf("Worktree ready at %s", absWorktreePath), dryRun, quiet, skipScaffold, jsonOutput)
+	},
+}
 
-				repoName := filepath.Base(filepath.Dir(absWorktreePath))
-				folderName := filepath.Base(absWorktreePath)
+// setUpBranchTracking points a branch at its namesake on workRemote. A
+// branch that already tracks something is left alone. Failures are
+// reported as warnings since the worktree is usable without tracking.
+func setUpBranchTracking(barePath, branch string) {
+	set, err := git.EnsureBranchUpstream(barePath, branch, workRemote)
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not set up tracking for branch '%s': %v", branch, err))
+	} else if set {
+		ui.PrintSuccess(fmt.Sprintf("Set up tracking for branch '%s' on %s", branch, workRemote))
+	}
+}
 
-				// For the default branch, use the saved SiteName from project config
-				// For feature branches, use the worktree folder name
-				siteName := folderName
-				if branch == pc.DefaultBranch && pc.Config.SiteName != "" {
-					siteName = pc.Config.SiteName
-				}
+// recordBranchParent remembers the local branch a new branch was created
+// from, so 'arbor list --stack' and 'arbor sync --stack' know the stack.
+// Branches created from a remote branch, tag or commit have no parent.
+func recordBranchParent(barePath, branch, base string) {
+	if base == branch || !git.BranchExists(barePath, base) {
+		return
+	}
+	if err := git.SetBranchParent(barePath, branch, base); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not record parent branch of '%s': %v", branch, err))
+	}
+}
 
-				promptMode := types.PromptMode{
-					Interactive:   ui.IsInteractive(),
-					NoInteractive: false,
-					Force:         false,
-					CI:            os.Getenv("CI") != "",
-				}
-				if err := pc.ScaffoldManager().RunScaffold(absWorktreePath, branch, repoName, siteName, preset, pc.Config, pc.BarePath, promptMode, false, verbose, quiet); err != nil {
-					ui.PrintErrorWithHint("Scaffold steps failed", err.Error())
-				}
-			} else {
-				ui.PrintInfo("Skipped scaffold (use 'arbor scaffold <branch>' to scaffold manually)")
+// workDetached creates a worktree on a detached HEAD at a tag or commit,
+// e.g. to reproduce a bug against a release. There is no branch, so
+// tracking setup is skipped and scaffold steps see the folder name as the
+// branch.
+func workDetached(pc *ProjectContext, args []string, answers workAnswers, dryRun, verbose, quiet, skipScaffold, trust, jsonOutput bool) error {
+	ref := args[0]
+	commit, err := git.ResolveCommit(pc.BarePath, ref)
+	if err != nil {
+		return fmt.Errorf("%q not found (expected a tag, commit or branch)", ref)
+	}
+
+	worktreePath := filepath.Join(pc.ProjectPath, utils.SanitisePath(ref))
+	if len(args) > 1 {
+		worktreePath = args[1]
+	}
+
+	absWorktreePath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return fmt.Errorf("getting absolute path: %w", err)
+	}
+
+	ui.PrintStep(fmt.Sprintf("Creating detached worktree at '%s' (%s)", ref, commit[:7]))
+	ui.PrintInfo(fmt.Sprintf("Path: %s", absWorktreePath))
+
+	if !dryRun {
+		if err := git.CreateDetachedWorktree(pc.BarePath, absWorktreePath, commit); err != nil {
+			return fmt.Errorf("creating worktree: %w", err)
+		}
+		if err := applyWorktreeSkeleton(pc.Config, pc.ProjectPath, absWorktreePath, verbose); err != nil {
+			ui.PrintWarning(err.Error())
+		}
+	} else {
+		ui.PrintInfo("[DRY RUN] Would create worktree")
+	}
+
+	branch := filepath.Base(absWorktreePath)
+	if err := scaffoldNewWorktree(pc, absWorktreePath, branch, answers, dryRun, verbose, quiet, skipScaffold, trust); err != nil {
+		return err
+	}
+
+	return finishWork(pc, absWorktreePath, branch, fmt.Sprintf("Worktree ready at %s (detached)", absWorktreePath), dryRun, quiet, skipScaffold, jsonOutput)
+}
+
+// finishWork reports a worktree 'arbor work' has set up, followed by its
+// next steps, or prints just the next steps as JSON with --json.
+func finishWork(pc *ProjectContext, path, branch, done string, dryRun, quiet, skipScaffold, jsonOutput bool) error {
+	steps := buildNextSteps(pc, path, branch, skipScaffold && !dryRun)
+	steps.DryRun = dryRun
+	if jsonOutput {
+		return printNextStepsJSON(os.Stdout, steps)
+	}
+
+	ui.PrintDone(done)
+	if !dryRun && !quiet {
+		printNextSteps(os.Stdout, steps)
+	}
+	return nil
+}
+
+// scaffoldNewWorktree runs the scaffold for a worktree 'arbor work' just
+// created, or queues it when scaffolding is skipped.
+func scaffoldNewWorktree(pc *ProjectContext, absWorktreePath, branch string, answers workAnswers, dryRun, verbose, quiet, skipScaffold, trust bool) error {
+	if !dryRun {
+		if !skipScaffold {
+			preset := pc.Config.Preset
+			if preset == "" {
+				preset = pc.PresetManager().Detect(absWorktreePath)
 			}
 
-			// Check if .arbor.local should be gitignored
-			if !quiet {
-				checkArborLocalGitignore(absWorktreePath)
+			if verbose && preset != "" {
+				ui.PrintInfo(fmt.Sprintf("Running scaffold for preset: %s", preset))
+			}
+
+			repoName := filepath.Base(filepath.Dir(absWorktreePath))
+			folderName := filepath.Base(absWorktreePath)
+
+			// For the default branch, use the saved SiteName from project config
+			// For feature branches, use the worktree folder name
+			siteName := folderName
+			if branch == pc.DefaultBranch && pc.Config.SiteName != "" {
+				siteName = pc.Config.SiteName
+			}
+
+			promptMode := types.PromptMode{
+				Interactive:   ui.IsInteractive(),
+				NoInteractive: false,
+				Force:         false,
+				CI:            os.Getenv("CI") != "",
+				Trust:         trust,
+			}
+			err := ensureConfigTrusted(pc.ProjectPath, pc.Config, promptMode)
+			if err == nil {
+				err = pc.ScaffoldManager().ScaffoldWorktree(absWorktreePath, branch, pc.Config, scaffold.RunOptions{
+					RepoName:   repoName,
+					SiteName:   siteName,
+					Preset:     preset,
+					BarePath:   pc.BarePath,
+					PromptMode: promptMode,
+					Verbose:    verbose,
+					Quiet:      quiet,
+					Answers:    answers.answers,
+					Sandbox:    sandboxSteps,
+				})
+			}
+			if err != nil {
+				ui.PrintErrorWithHint(i18n.T("hint.scaffold_failed"), err.Error())
+			} else {
+				if err := answers.save(); err != nil {
+					ui.PrintWarning(err.Error())
+				}
+				runPostScaffoldChecks(pc, pc.Config, absWorktreePath, quiet)
 			}
 		} else {
-			ui.PrintInfo("[DRY RUN] Would run scaffold steps")
+			if err := config.SetScaffoldPending(absWorktreePath, true); err != nil {
+				return fmt.Errorf("recording pending scaffold: %w", err)
+			}
+			ui.PrintInfo("Scaffold deferred (run 'arbor scaffold --pending' or 'arbor scaffold <branch>' when ready)")
 		}
 
-		ui.PrintDone(fmt.Sprintf("Worktree ready at %s", absWorktreePath))
-		return nil
-	},
+		// Check if .arbor.local should be gitignored
+		if !quiet {
+			checkArborLocalGitignore(absWorktreePath)
+		}
+	} else {
+		ui.PrintInfo("[DRY RUN] Would run scaffold steps")
+	}
+
+	return nil
 }
 
 func isCommandAvailable(name string) bool {
@@ -190,7 +454,16 @@ func isCommandAvailable(name string) bool {
 func init() {
 	rootCmd.AddCommand(workCmd)
 
-	workCmd.Flags().StringP("base", "b", "", "Base branch for new worktree")
+	workCmd.Flags().StringP("base", "b", "", "Base for a new branch: a branch, remote branch (origin/release/1.2), tag or commit")
+	workCmd.Flags().Bool("detach", false, "Create a detached worktree at a tag or commit instead of a branch")
+	workCmd.Flags().Bool("no-fetch", false, "Skip fetching origin first when work.fetch_first is set")
 	workCmd.Flags().Bool("no-track", false, "Skip setting up remote tracking for new branches")
 	workCmd.Flags().Bool("skip-scaffold", false, "Skip scaffold steps during work")
+	workCmd.Flags().Bool("no-scaffold", false, "Create the worktree now and queue its scaffold for 'arbor scaffold --pending'")
+	workCmd.Flags().Bool("json", false, "Output the worktree's next steps as JSON")
+	workCmd.Flags().Bool("refresh", false, "Refresh the cached remote branch list shown by the branch pickers")
+	workCmd.Flags().StringArray("matrix", nil, "Create a worktree per value, e.g. php=8.2,8.3 (repeat for more keys)")
+	workCmd.Flags().StringArray("profile", nil, "Enable the scaffold steps gated on a profile, e.g. testing (repeatable)")
+	workCmd.Flags().String("answers", "", "Replay scaffold prompt answers from a file written by --record-answers")
+	workCmd.Flags().String("record-answers", "", "Write the scaffold prompt answers given to a file to share with the team")
 }
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// matrixKeyPattern matches matrix keys, which become template variables.
+var matrixKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// matrixCell is one combination of matrix values, such as php=8.3 and
+// node=20, and the suffix that names its worktree, such as php83-node20.
+type matrixCell struct {
+	Suffix string
+	Vars   map[string]string
+}
+
+// parseMatrix expands --matrix values such as "php=8.2,8.3" into every
+// combination of their values, in the order given.
+func parseMatrix(specs []string) ([]matrixCell, error) {
+	cells := []matrixCell{{Vars: map[string]string{}}}
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		key, list, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || list == "" {
+			return nil, fmt.Errorf("invalid --matrix %q (expected KEY=VALUE,VALUE, e.g. php=8.2,8.3)", spec)
+		}
+		if !matrixKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid --matrix key %q (use letters, digits and underscores)", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("--matrix key %q is given twice", key)
+		}
+		seen[key] = true
+
+		var values []string
+		for _, value := range strings.Split(list, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("--matrix %q has no values", key)
+		}
+
+		var expanded []matrixCell
+		for _, cell := range cells {
+			for _, value := range values {
+				vars := make(map[string]string, len(cell.Vars)+1)
+				for k, v := range cell.Vars {
+					vars[k] = v
+				}
+				vars[key] = value
+				suffix := key + matrixSuffixValue(value)
+				if cell.Suffix != "" {
+					suffix = cell.Suffix + "-" + suffix
+				}
+				expanded = append(expanded, matrixCell{Suffix: suffix, Vars: vars})
+			}
+		}
+		cells = expanded
+	}
+
+	suffixes := make(map[string]bool, len(cells))
+	for _, cell := range cells {
+		if suffixes[cell.Suffix] {
+			return nil, fmt.Errorf("--matrix values give two worktrees the name %q", cell.Suffix)
+		}
+		suffixes[cell.Suffix] = true
+	}
+	return cells, nil
+}
+
+// matrixSuffixValue keeps the letters and digits of a matrix value, so
+// 8.3 names a worktree php83.
+func matrixSuffixValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// matrixOptions holds the settings 'arbor work --matrix' creates its
+// worktrees with.
+type matrixOptions struct {
+	Answers      workAnswers
+	DryRun       bool
+	Verbose      bool
+	Quiet        bool
+	SkipScaffold bool
+	Trust        bool
+	Track        bool
+}
+
+// workMatrix creates a worktree per matrix cell, each on a detached HEAD at
+// the tip of branch, which is created from base first when it does not
+// exist. Each worktree records its matrix values in .arbor.local, so every
+// scaffold of it sees them as template variables.
+func workMatrix(pc *ProjectContext, branch, base string, exists bool, cells []matrixCell, opts matrixOptions) error {
+	if !exists {
+		ui.PrintStep(fmt.Sprintf("Creating branch '%s' from '%s'", branch, base))
+		if !opts.DryRun {
+			if err := git.CreateBranch(pc.BarePath, branch, base); err != nil {
+				return err
+			}
+			recordBranchParent(pc.BarePath, branch, base)
+			if opts.Track {
+				setUpBranchTracking(pc.BarePath, branch)
+			}
+		}
+	}
+
+	worktrees, err := git.ListWorktrees(pc.BarePath)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	for _, cell := range cells {
+		path, err := filepath.Abs(filepath.Join(pc.ProjectPath, utils.SanitisePath(branch+"-"+cell.Suffix)))
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+		if matrixWorktreeExists(worktrees, path) {
+			ui.PrintInfo(fmt.Sprintf("Worktree already exists at %s", path))
+			continue
+		}
+
+		ui.PrintStep(fmt.Sprintf("Creating worktree for '%s' with %s", branch, formatMatrixVars(cell.Vars)))
+		ui.PrintInfo(fmt.Sprintf("Path: %s", path))
+		if opts.DryRun {
+			ui.PrintInfo("[DRY RUN] Would create worktree")
+		} else {
+			if err := git.CreateDetachedWorktree(pc.BarePath, path, "refs/heads/"+branch); err != nil {
+				return fmt.Errorf("creating worktree: %w", err)
+			}
+			if err := config.SetMatrix(path, cell.Vars); err != nil {
+				return fmt.Errorf("recording matrix values: %w", err)
+			}
+			if err := applyWorktreeSkeleton(pc.Config, pc.ProjectPath, path, opts.Verbose); err != nil {
+				ui.PrintWarning(err.Error())
+			}
+		}
+
+		if err := scaffoldNewWorktree(pc, path, branch, opts.Answers, opts.DryRun, opts.Verbose, opts.Quiet, opts.SkipScaffold, opts.Trust); err != nil {
+			return err
+		}
+		ui.PrintDone(fmt.Sprintf("Worktree ready at %s", path))
+	}
+
+	if !opts.DryRun && !opts.Quiet {
+		ui.PrintInfo(fmt.Sprintf("Matrix worktrees are on a detached HEAD; run 'git checkout --detach %s' in one to move it to the branch's latest commit", branch))
+	}
+	return nil
+}
+
+func matrixWorktreeExists(worktrees []git.Worktree, path string) bool {
+	for _, wt := range worktrees {
+		if wt.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// formatMatrixVars formats matrix values as "node=20 php=8.3", sorted by key.
+func formatMatrixVars(vars map[string]string) string {
+	pairs := make([]string, 0, len(vars))
+	for key, value := range vars {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+// openMatrixProject returns the project of a test repo whose scaffold
+// writes the php matrix value to php.txt.
+func openMatrixProject(t *testing.T) *ProjectContext {
+	t.Helper()
+	projectDir, _ := setupRecycleProject(t, `default_branch: main
+preset: ""
+scaffold:
+  steps:
+    - name: bash.run
+      command: echo {{ .php }} > php.txt
+`)
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Chdir(originalDir) })
+	require.NoError(t, os.Chdir(projectDir))
+
+	pc, err := OpenProjectFromCWD()
+	require.NoError(t, err)
+	return pc
+}
+
+func TestWorkMatrix(t *testing.T) {
+	pc := openMatrixProject(t)
+	cells, err := parseMatrix([]string{"php=8.2,8.3"})
+	require.NoError(t, err)
+
+	require.NoError(t, workMatrix(pc, "compat", "main", false, cells, matrixOptions{Quiet: true}))
+
+	assert.True(t, git.BranchExists(pc.BarePath, "compat"))
+	for _, cell := range cells {
+		path := filepath.Join(pc.ProjectPath, "compat-"+cell.Suffix)
+		detached, err := git.IsDetachedHEAD(path)
+		require.NoError(t, err)
+		assert.True(t, detached, "matrix worktrees share the branch on a detached HEAD")
+
+		state, err := config.ReadLocalState(path)
+		require.NoError(t, err)
+		assert.Equal(t, cell.Vars, state.Matrix)
+		assert.Equal(t, cell.Vars["php"]+"\n", readTestFile(t, filepath.Join(path, "php.txt")), "the scaffold sees the matrix values")
+	}
+
+	t.Run("skips worktrees that exist", func(t *testing.T) {
+		require.NoError(t, workMatrix(pc, "compat", "main", true, cells, matrixOptions{Quiet: true, SkipScaffold: true}))
+	})
+}
+
+func TestWorkMatrix_DryRun(t *testing.T) {
+	pc := openMatrixProject(t)
+	cells, err := parseMatrix([]string{"php=8.2,8.3"})
+	require.NoError(t, err)
+
+	require.NoError(t, workMatrix(pc, "compat", "main", false, cells, matrixOptions{DryRun: true, Quiet: true}))
+
+	assert.False(t, git.BranchExists(pc.BarePath, "compat"))
+	assert.NoDirExists(t, filepath.Join(pc.ProjectPath, "compat-php82"))
+}
+
+func TestMatrixSuffixValue(t *testing.T) {
+	assert.Equal(t, "83", matrixSuffixValue("8.3"))
+	assert.Equal(t, "lts", matrixSuffixValue("lts/*"))
+}
+
+func TestFormatMatrixVars(t *testing.T) {
+	assert.Equal(t, "node=20 php=8.3", formatMatrixVars(map[string]string{"php": "8.3", "node": "20"}))
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/artisanexperiences/arbor/internal/git"
 )
@@ -171,3 +172,24 @@ func TestWorkCommand_SetsUpBranchTracking(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "refs/heads/feature", strings.TrimSpace(string(output)))
 }
+
+func TestParseMatrix(t *testing.T) {
+	cells, err := parseMatrix([]string{"php=8.2,8.3", "node=20"})
+	require.NoError(t, err)
+	require.Len(t, cells, 2)
+	assert.Equal(t, "php82-node20", cells[0].Suffix)
+	assert.Equal(t, map[string]string{"php": "8.2", "node": "20"}, cells[0].Vars)
+	assert.Equal(t, "php83-node20", cells[1].Suffix)
+	assert.Equal(t, map[string]string{"php": "8.3", "node": "20"}, cells[1].Vars)
+
+	for _, specs := range [][]string{
+		{"php"},
+		{"php="},
+		{"php-version=8.2"},
+		{"php=8.2", "php=8.3"},
+		{"php=8.2,82"},
+	} {
+		_, err := parseMatrix(specs)
+		assert.Error(t, err, "%v", specs)
+	}
+}
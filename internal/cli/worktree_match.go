@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// findWorktree returns the worktree arg names exactly: by folder name,
+// branch, or path, absolute or relative to projectPath.
+func findWorktree(worktrees []git.Worktree, projectPath, arg string) *git.Worktree {
+	target := arg
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(projectPath, target)
+	}
+	for i, wt := range worktrees {
+		if wt.Branch == "(bare)" {
+			continue
+		}
+		if filepath.Base(wt.Path) == arg || (!wt.Detached && wt.Branch == arg) || filepath.Clean(wt.Path) == filepath.Clean(target) {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// matchWorktree returns the worktree arg names, like findWorktree, or else
+// the one worktree whose folder or branch name contains arg, or else the
+// one that fuzzy-matches it, so 'auth' finds feature/user-auth. When
+// several match, the error lists them.
+func matchWorktree(worktrees []git.Worktree, projectPath, arg string) (*git.Worktree, error) {
+	if wt := findWorktree(worktrees, projectPath, arg); wt != nil {
+		return wt, nil
+	}
+
+	query := strings.ToLower(arg)
+	var contains []int
+	type scored struct {
+		index, score int
+	}
+	var fuzzy []scored
+	for i, wt := range worktrees {
+		if wt.Branch == "(bare)" {
+			continue
+		}
+		names := []string{filepath.Base(wt.Path)}
+		if !wt.Detached {
+			names = append(names, wt.Branch)
+		}
+
+		best, matched := 0, false
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(name), query) {
+				contains = append(contains, i)
+				matched = false
+				break
+			}
+			if score, ok := utils.FuzzyScore(arg, name); ok && (!matched || score > best) {
+				best, matched = score, true
+			}
+		}
+		if matched {
+			fuzzy = append(fuzzy, scored{i, best})
+		}
+	}
+
+	candidates := contains
+	if len(candidates) == 0 {
+		sort.SliceStable(fuzzy, func(i, j int) bool { return fuzzy[i].score > fuzzy[j].score })
+		for _, f := range fuzzy {
+			candidates = append(candidates, f.index)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("worktree '%s' not found: %w", arg, arborerrors.ErrWorktreeNotFound)
+	case 1:
+		return &worktrees[candidates[0]], nil
+	}
+
+	labels := make([]string, len(candidates))
+	for i, c := range candidates {
+		labels[i] = worktreeCandidateLabel(worktrees[c])
+	}
+	return nil, fmt.Errorf("'%s' matches %d worktrees, be more specific:\n  %s", arg, len(candidates), strings.Join(labels, "\n  "))
+}
+
+// worktreeCandidateLabel names a worktree in an ambiguous match: its
+// folder, and its branch when that differs.
+func worktreeCandidateLabel(wt git.Worktree) string {
+	folder := filepath.Base(wt.Path)
+	if wt.Detached || wt.Branch == folder {
+		return folder
+	}
+	return fmt.Sprintf("%s (%s)", folder, wt.Branch)
+}
+
+// filterWorktrees returns the worktrees whose folder or branch name
+// contains or fuzzy-matches query, keeping their order.
+func filterWorktrees(worktrees []git.Worktree, query string) []git.Worktree {
+	if strings.TrimSpace(query) == "" {
+		return worktrees
+	}
+	var filtered []git.Worktree
+	for _, wt := range worktrees {
+		names := []string{filepath.Base(wt.Path)}
+		if !wt.Detached {
+			names = append(names, wt.Branch)
+		}
+		for _, name := range names {
+			if _, ok := utils.FuzzyScore(query, name); ok {
+				filtered = append(filtered, wt)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func TestMatchWorktree(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Path: "/code/app/.bare", Branch: "(bare)"},
+		{Path: "/code/app/main", Branch: "main", IsMain: true},
+		{Path: "/code/app/feature-user-auth", Branch: "feature/user-auth"},
+		{Path: "/code/app/feature-billing", Branch: "feature/billing"},
+		{Path: "/code/app/fix-auth-redirect", Branch: "fix/auth-redirect"},
+		{Path: "/code/app/spike", Head: "abc123", Detached: true},
+	}
+
+	match := func(arg string) string {
+		t.Helper()
+		wt, err := matchWorktree(worktrees, "/code/app", arg)
+		require.NoError(t, err, arg)
+		return wt.Path
+	}
+
+	assert.Equal(t, "/code/app/feature-billing", match("feature-billing"), "folder name")
+	assert.Equal(t, "/code/app/feature-billing", match("feature/billing"), "branch")
+	assert.Equal(t, "/code/app/feature-billing", match("/code/app/feature-billing"), "absolute path")
+	assert.Equal(t, "/code/app/spike", match("spike"), "detached worktree by folder")
+	assert.Equal(t, "/code/app/feature-user-auth", match("user-auth"), "substring")
+	assert.Equal(t, "/code/app/feature-user-auth", match("usr auth"), "fuzzy")
+	assert.Equal(t, "/code/app/fix-auth-redirect", match("redir"), "substring")
+
+	_, err := matchWorktree(worktrees, "/code/app", "auth")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'auth' matches 2 worktrees")
+	assert.Contains(t, err.Error(), "feature-user-auth (feature/user-auth)")
+	assert.Contains(t, err.Error(), "fix-auth-redirect (fix/auth-redirect)")
+
+	_, err = matchWorktree(worktrees, "/code/app", "bare")
+	assert.ErrorIs(t, err, arborerrors.ErrWorktreeNotFound, "the bare repository is never matched")
+	_, err = matchWorktree(worktrees, "/code/app", "zzz")
+	assert.ErrorIs(t, err, arborerrors.ErrWorktreeNotFound)
+}
+
+func TestFindWorktree_ExactOnly(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Path: "/code/app/feature-user-auth", Branch: "feature/user-auth"},
+	}
+	assert.NotNil(t, findWorktree(worktrees, "/code/app", "feature-user-auth"))
+	assert.NotNil(t, findWorktree(worktrees, "/code/app", "feature/user-auth"))
+	assert.Nil(t, findWorktree(worktrees, "/code/app", "auth"))
+}
+
+func TestFilterWorktrees(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Path: "/code/app/main", Branch: "main"},
+		{Path: "/code/app/feature-user-auth", Branch: "feature/user-auth"},
+		{Path: "/code/app/spike", Detached: true},
+	}
+
+	assert.Equal(t, worktrees, filterWorktrees(worktrees, " "))
+	assert.Equal(t, worktrees[1:2], filterWorktrees(worktrees, "usr auth"))
+	assert.Equal(t, worktrees[2:], filterWorktrees(worktrees, "spk"))
+	assert.Empty(t, filterWorktrees(worktrees, "zzz"))
+}
@@ -0,0 +1,68 @@
+package concurrency
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// OrderedOutput buffers the output of tasks running in parallel and writes
+// it in task order, so lines from different tasks never interleave. Each
+// task's output is written as soon as it and every task before it are
+// done.
+type OrderedOutput struct {
+	mu   sync.Mutex
+	w    io.Writer
+	bufs []bytes.Buffer
+	done []bool
+	next int
+	err  error
+}
+
+// NewOrderedOutput returns an OrderedOutput for n tasks writing to w.
+func NewOrderedOutput(w io.Writer, n int) *OrderedOutput {
+	return &OrderedOutput{
+		w:    w,
+		bufs: make([]bytes.Buffer, n),
+		done: make([]bool, n),
+	}
+}
+
+// Writer returns the writer for task i.
+func (o *OrderedOutput) Writer(i int) io.Writer {
+	return taskWriter{o: o, i: i}
+}
+
+// Done marks task i finished and writes the output of every finished task
+// that is no longer waiting on an earlier one.
+func (o *OrderedOutput) Done(i int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.done[i] = true
+	for o.next < len(o.done) && o.done[o.next] {
+		if o.err == nil {
+			_, o.err = o.bufs[o.next].WriteTo(o.w)
+		}
+		o.bufs[o.next] = bytes.Buffer{}
+		o.next++
+	}
+}
+
+// Err returns the first error writing to the underlying writer.
+func (o *OrderedOutput) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+type taskWriter struct {
+	o *OrderedOutput
+	i int
+}
+
+func (t taskWriter) Write(p []byte) (int, error) {
+	t.o.mu.Lock()
+	defer t.o.mu.Unlock()
+	return t.o.bufs[t.i].Write(p)
+}
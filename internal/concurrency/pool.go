@@ -0,0 +1,77 @@
+// Package concurrency runs independent tasks, such as git commands for
+// each worktree, in parallel with a bounded number of workers, collecting
+// their errors and keeping their output in task order.
+package concurrency
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// DefaultLimit is the number of workers used when a limit of 0 or less is
+// given.
+func DefaultLimit() int {
+	return runtime.NumCPU()
+}
+
+// Pool runs functions on at most limit goroutines at a time.
+type Pool struct {
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewPool returns a pool running at most limit functions at once.
+func NewPool(limit int) *Pool {
+	if limit <= 0 {
+		limit = DefaultLimit()
+	}
+	return &Pool{sem: make(chan struct{}, limit)}
+}
+
+// Go runs fn once a worker is free. It blocks while all workers are busy.
+func (p *Pool) Go(fn func() error) {
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		if err := fn(); err != nil {
+			p.mu.Lock()
+			p.errs = append(p.errs, err)
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for every function passed to Go and returns their errors
+// joined, or nil if all succeeded.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
+
+// Map calls fn for each item on at most limit goroutines and returns the
+// results in the order of items. Every item is processed even when some
+// fail; the error joins all failures, in item order.
+func Map[T, R any](items []T, limit int, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	pool := NewPool(limit)
+	for i, item := range items {
+		pool.Go(func() error {
+			results[i], errs[i] = fn(item)
+			return nil
+		})
+	}
+	_ = pool.Wait()
+
+	return results, errors.Join(errs...)
+}
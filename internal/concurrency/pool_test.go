@@ -0,0 +1,126 @@
+package concurrency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_LimitsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	pool := NewPool(2)
+	for i := 0; i < 8; i++ {
+		pool.Go(func() error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+
+	require.NoError(t, pool.Wait())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestPool_JoinsErrors(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	pool := NewPool(0)
+	pool.Go(func() error { return errA })
+	pool.Go(func() error { return nil })
+	pool.Go(func() error { return errB })
+
+	err := pool.Wait()
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+}
+
+func TestMap(t *testing.T) {
+	t.Run("keeps results in item order", func(t *testing.T) {
+		items := []int{5, 1, 4, 2, 3}
+		results, err := Map(items, 3, func(n int) (string, error) {
+			time.Sleep(time.Duration(n) * time.Millisecond)
+			return fmt.Sprint(n * 10), nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"50", "10", "40", "20", "30"}, results)
+	})
+
+	t.Run("processes every item and joins errors", func(t *testing.T) {
+		var calls atomic.Int32
+		results, err := Map([]string{"ok", "bad", "worse"}, 2, func(s string) (int, error) {
+			calls.Add(1)
+			if s != "ok" {
+				return 0, fmt.Errorf("%s item", s)
+			}
+			return len(s), nil
+		})
+
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, []int{2, 0, 0}, results)
+		assert.EqualError(t, err, "bad item\nworse item")
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		results, err := Map(nil, 4, func(s string) (int, error) { return 0, nil })
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestOrderedOutput(t *testing.T) {
+	var out strings.Builder
+	output := NewOrderedOutput(&out, 3)
+
+	fmt.Fprintln(output.Writer(2), "third")
+	fmt.Fprintln(output.Writer(1), "second")
+	output.Done(2)
+	output.Done(1)
+	assert.Empty(t, out.String(), "nothing is written while the first task runs")
+
+	fmt.Fprintln(output.Writer(0), "first")
+	output.Done(0)
+	assert.Equal(t, "first\nsecond\nthird\n", out.String())
+	assert.NoError(t, output.Err())
+}
+
+func TestOrderedOutput_Parallel(t *testing.T) {
+	var out strings.Builder
+	const tasks = 20
+	output := NewOrderedOutput(&out, tasks)
+
+	pool := NewPool(4)
+	for i := 0; i < tasks; i++ {
+		pool.Go(func() error {
+			w := output.Writer(i)
+			for line := 0; line < 3; line++ {
+				fmt.Fprintf(w, "task %d line %d\n", i, line)
+			}
+			output.Done(i)
+			return nil
+		})
+	}
+	require.NoError(t, pool.Wait())
+
+	var want strings.Builder
+	for i := 0; i < tasks; i++ {
+		for line := 0; line < 3; line++ {
+			fmt.Fprintf(&want, "task %d line %d\n", i, line)
+		}
+	}
+	assert.Equal(t, want.String(), out.String())
+}
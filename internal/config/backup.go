@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// localStateBackupFile is the name of .arbor.local's backup in the
+// worktree's git directory.
+const localStateBackupFile = "arbor.local.bak"
+
+// BackupPath returns where the version of the config or env file at path
+// that its last write replaced is kept: next to it with a .bak suffix, or
+// for .arbor.local and env files such as .env in the worktree's git
+// directory, as arbor.local.bak and arbor.env.bak, so they never show up as
+// untracked files.
+func BackupPath(path string) string {
+	name := filepath.Base(path)
+	if name == ".arbor.local" || strings.HasPrefix(name, ".env") {
+		if gitDir := worktreeGitDir(filepath.Dir(path)); gitDir != "" {
+			if name == ".arbor.local" {
+				return filepath.Join(gitDir, localStateBackupFile)
+			}
+			return filepath.Join(gitDir, "arbor"+name+".bak")
+		}
+	}
+	return path + ".bak"
+}
+
+// worktreeGitDir returns the git directory of the worktree at
+// worktreePath from its .git entry, or "" when it has none.
+func worktreeGitDir(worktreePath string) string {
+	dotGit := filepath.Join(worktreePath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return dotGit
+	}
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	dir = filepath.FromSlash(strings.TrimSpace(dir))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(worktreePath, dir)
+	}
+	return dir
+}
+
+// WriteConfigFile atomically replaces the config file at path with
+// content, keeping the version it replaces at BackupPath(path). Writes
+// that change nothing leave the backup alone.
+func WriteConfigFile(path string, content []byte) error {
+	defer markWritten()
+	return writeWithBackup(path, content, 0644)
+}
+
+// WriteEnvFile atomically replaces the env file at path with content,
+// keeping the version it replaces at BackupPath(path). Env files hold
+// secrets, so a new backup is readable only by the user.
+func WriteEnvFile(path string, content []byte) error {
+	return writeWithBackup(path, content, 0600)
+}
+
+func writeWithBackup(path string, content []byte, backupPerm os.FileMode) error {
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(previous, content):
+		return nil
+	case err == nil:
+		if err := utils.WriteFileAtomic(BackupPath(path), previous, backupPerm); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	return utils.WriteFileAtomic(path, content, 0644)
+}
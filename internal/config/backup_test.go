@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteConfigFile_Backup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arbor.yaml")
+	backup := BackupPath(path)
+	assert.Equal(t, path+".bak", backup)
+
+	require.NoError(t, WriteConfigFile(path, []byte("preset: php\n")))
+	assert.NoFileExists(t, backup, "a new file has nothing to back up")
+
+	require.NoError(t, WriteConfigFile(path, []byte("preset: laravel\n")))
+	require.NoError(t, WriteConfigFile(path, []byte("preset: laravel\n")))
+	content, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "preset: php\n", string(content), "writes that change nothing keep the backup")
+
+	require.NoError(t, WriteConfigFile(path, []byte("preset: node\n")))
+	content, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "preset: laravel\n", string(content), "only the previous version is kept")
+}
+
+func TestBackupPath_LocalState(t *testing.T) {
+	worktree := t.TempDir()
+	output, err := exec.Command("git", "init", worktree).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	assert.Equal(t, filepath.Join(worktree, ".git", "arbor.local.bak"), BackupPath(filepath.Join(worktree, ".arbor.local")))
+	assert.Equal(t, filepath.Join(worktree, ".git", "arbor.env.bak"), BackupPath(filepath.Join(worktree, ".env")))
+	assert.Equal(t, filepath.Join(worktree, ".git", "arbor.env.testing.bak"), BackupPath(filepath.Join(worktree, ".env.testing")))
+
+	linked := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: ../.bare/worktrees/feature\n"), 0644))
+	assert.Equal(t, filepath.Join(linked, "..", ".bare", "worktrees", "feature", "arbor.local.bak"), BackupPath(filepath.Join(linked, ".arbor.local")))
+
+	plain := t.TempDir()
+	assert.Equal(t, filepath.Join(plain, ".arbor.local.bak"), BackupPath(filepath.Join(plain, ".arbor.local")))
+}
@@ -1,12 +1,20 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
+
+	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
 )
 
 const (
@@ -22,6 +30,9 @@ const (
 
 const DefaultBranch = "main"
 
+// DefaultEnvFile is the primary env file when a project sets no env_file.
+const DefaultEnvFile = ".env"
+
 var DefaultBranchCandidates = []string{"main", "master", "develop"}
 
 // Condition key constants for use in step configurations
@@ -30,6 +41,10 @@ const (
 	ConditionCommandExists   = "command_exists"
 	ConditionOS              = "os"
 	ConditionEnvFileContains = "env_file_contains"
+	ConditionEnvFileEquals   = "env_file_equals"
+	ConditionBranchMatches   = "branch_matches"
+	ConditionIsDefaultBranch = "is_default_branch"
+	ConditionRemoteExists    = "remote_exists"
 	ConditionNot             = "not"
 )
 
@@ -42,6 +57,107 @@ type Config struct {
 	Cleanup       CleanupConfig         `mapstructure:"cleanup"`
 	Tools         map[string]ToolConfig `mapstructure:"tools"`
 	Sync          SyncConfig            `mapstructure:"sync"`
+	Work          WorkConfig            `mapstructure:"work"`
+	Test          TestConfig            `mapstructure:"test"`
+	CopyState     CopyStateConfig       `mapstructure:"copy_state"`
+	Push          PushConfig            `mapstructure:"push"`
+	Trash         TrashConfig           `mapstructure:"trash"`
+	Naming        NamingConfig          `mapstructure:"naming"`
+	// DbNaming is "random" (default) for suffixes generated per Naming, or
+	// "branch" to derive the database suffix from the branch name.
+	DbNaming string `mapstructure:"db_naming"`
+	// WorktreeSkeleton is a directory whose contents are copied into every
+	// new worktree before scaffolding. Relative paths are resolved against
+	// the project root.
+	WorktreeSkeleton string `mapstructure:"worktree_skeleton"`
+	// EnvFile is the worktree's primary env file, used by env and db steps
+	// that name no file, e.g. .env.local for Symfony or Next.js projects.
+	// Relative to the worktree; defaults to .env.
+	EnvFile string `mapstructure:"env_file"`
+	// CI adjusts the scaffold run by 'arbor ci bootstrap'.
+	CI CIConfig `mapstructure:"ci"`
+	// Checks verify a scaffolded worktree works, run by 'arbor check'.
+	// When empty, the preset's default checks are used.
+	Checks []CheckConfig `mapstructure:"checks"`
+	// Policy denies risky commands and requires confirmation for
+	// destructive steps.
+	Policy PolicyConfig `mapstructure:"policy"`
+	// Sandbox restricts the environment, network and writable paths of
+	// command steps.
+	Sandbox SandboxConfig `mapstructure:"sandbox"`
+	// Shell is the shell bash.run steps that name none run with; empty
+	// means bash.
+	Shell string `mapstructure:"shell"`
+	// StepGroups are named lists of steps that scaffold, cleanup and ci
+	// steps include with group: NAME.
+	StepGroups map[string][]StepConfig `mapstructure:"step_groups"`
+	// Overrides hold config for one operating system, keyed by its
+	// runtime.GOOS name. The section for the running OS is merged over the
+	// rest of the file when it is loaded: maps merge key by key, while
+	// lists and values replace what the file sets.
+	Overrides map[string]map[string]interface{} `mapstructure:"overrides"`
+}
+
+// OverrideOSes are the operating systems overrides may name.
+var OverrideOSes = []string{"darwin", "linux", "windows"}
+
+// PrimaryEnvFile returns the project's primary env file name.
+func (c *Config) PrimaryEnvFile() string {
+	if c.EnvFile == "" {
+		return DefaultEnvFile
+	}
+	return c.EnvFile
+}
+
+// NamingConfig controls how worktree database suffixes are generated.
+// Style is "words" (default, adjective_noun), "numeric" (random digits) or
+// "hash" (derived from the branch name, so a branch always gets the same
+// suffix). PrefixLength and SuffixLength cap the adjective and noun of the
+// words style; for numeric and hash, SuffixLength is the number of
+// characters generated.
+type NamingConfig struct {
+	Style        string   `mapstructure:"style"`
+	Adjectives   []string `mapstructure:"adjectives"`
+	Nouns        []string `mapstructure:"nouns"`
+	PrefixLength int      `mapstructure:"prefix_length"`
+	SuffixLength int      `mapstructure:"suffix_length"`
+}
+
+// TrashConfig controls whether removed worktrees are kept in
+// .arbor/trash for `arbor undo` and how long they are kept
+type TrashConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	RetentionDays int  `mapstructure:"retention_days"`
+}
+
+// WorkConfig represents configuration for the work command
+type WorkConfig struct {
+	// FetchFirst fetches origin before creating a worktree, so branches
+	// that exist on the remote are created at, or fast-forwarded to, the
+	// remote tip.
+	FetchFirst bool `mapstructure:"fetch_first"`
+}
+
+// TestConfig represents configuration for the test command
+type TestConfig struct {
+	// Command runs the project's tests, e.g. "php artisan test --parallel".
+	// When empty, it is detected from composer.json and package.json.
+	Command string `mapstructure:"command"`
+}
+
+// CopyStateConfig represents configuration for the copy-state command
+type CopyStateConfig struct {
+	// Paths lists the gitignored files and directories to copy, relative
+	// to the worktree. When empty, the env file, storage/app and
+	// public/uploads are copied.
+	Paths []string `mapstructure:"paths"`
+}
+
+// PushConfig represents configuration for the push command
+type PushConfig struct {
+	// Protected lists branch patterns (e.g. release/*) that need
+	// confirmation before pushing, in addition to the default branch.
+	Protected []string `mapstructure:"protected"`
 }
 
 // SyncConfig represents sync configuration for the sync command
@@ -50,6 +166,38 @@ type SyncConfig struct {
 	Strategy  string `mapstructure:"strategy"`
 	Remote    string `mapstructure:"remote"`
 	AutoStash *bool  `mapstructure:"auto_stash"` // Pointer to distinguish between unset and false
+	// RebaseOptions are extra git rebase options, such as --autosquash or
+	// --update-refs, from an allowlist.
+	RebaseOptions []string `mapstructure:"rebase_options"`
+}
+
+// CIConfig adjusts the scaffold steps for a CI checkout. Herd steps are
+// never run in CI.
+type CIConfig struct {
+	// Steps, when set, replace the scaffold steps in CI.
+	Steps []StepConfig `mapstructure:"steps"`
+	// Skip names steps that are not run in CI.
+	Skip []string `mapstructure:"skip"`
+	// Env pins values in the primary env file, e.g. DB_CONNECTION: sqlite.
+	// Keys are upper-cased, as YAML keys are read case-insensitively.
+	Env map[string]string `mapstructure:"env"`
+}
+
+// CheckConfig is a health check run by 'arbor check'. Name selects the
+// check: http, artisan.about, db, queue, command or files.
+type CheckConfig struct {
+	Name string `mapstructure:"name"`
+	// URL is requested by the http check, defaulting to APP_URL.
+	URL string `mapstructure:"url"`
+	// Status is the HTTP status the http check expects. When unset any
+	// status below 400 passes.
+	Status int `mapstructure:"status"`
+	// Insecure skips TLS certificate verification for the http check.
+	Insecure bool `mapstructure:"insecure"`
+	// Command is run by the command check, which passes when it exits 0.
+	Command string `mapstructure:"command"`
+	// Optional checks report failures as warnings.
+	Optional bool `mapstructure:"optional"`
 }
 
 // PreFlight defines checks that run before scaffold execution.
@@ -63,11 +211,131 @@ type ScaffoldConfig struct {
 	PreFlight *PreFlight   `mapstructure:"pre_flight"`
 	Steps     []StepConfig `mapstructure:"steps"`
 	Override  bool         `mapstructure:"override"`
+	// Strict fails the scaffold when a continue_on_error step fails, after
+	// the remaining steps have run.
+	Strict bool `mapstructure:"strict"`
+	// RunChecks runs the project's health checks after each scaffold.
+	// Failed checks are reported as warnings.
+	RunChecks bool `mapstructure:"run_checks"`
+	// PresetOverrides disable or change single preset steps, so the rest
+	// of the preset keeps applying without override.
+	PresetOverrides PresetOverrides `mapstructure:"preset_overrides"`
+	// Defaults are settings every scaffold step gets unless it sets them
+	// itself.
+	Defaults StepDefaults `mapstructure:"defaults"`
+	// Profiles enable the steps whose profile condition names them, such
+	// as the laravel preset's testing steps. arbor scaffold --profile adds
+	// to them.
+	Profiles []string `mapstructure:"profiles"`
+}
+
+// StepDefaults are settings shared by the scaffold steps, so that they
+// need not be repeated on each. Condition keys are added to a step's
+// condition unless it sets the same key. OnConnectionFailure, SSLMode and
+// SSLCA only apply to database steps.
+type StepDefaults struct {
+	Condition           map[string]interface{} `mapstructure:"condition"`
+	Lock                string                 `mapstructure:"lock"`
+	OnConnectionFailure string                 `mapstructure:"on_connection_failure"`
+	SSLMode             string                 `mapstructure:"ssl_mode"`
+	SSLCA               string                 `mapstructure:"ssl_ca"`
+}
+
+// Apply returns step with the defaults it does not set itself.
+func (d StepDefaults) Apply(step StepConfig) StepConfig {
+	if len(d.Condition) > 0 {
+		condition := make(map[string]interface{}, len(d.Condition)+len(step.Condition))
+		for key, value := range d.Condition {
+			condition[key] = value
+		}
+		for key, value := range step.Condition {
+			condition[key] = value
+		}
+		step.Condition = condition
+	}
+	if step.Lock == "" {
+		step.Lock = d.Lock
+	}
+	if strings.HasPrefix(step.Name, "db.") {
+		if step.Name == "db.create" && step.OnConnectionFailure == "" {
+			step.OnConnectionFailure = d.OnConnectionFailure
+		}
+		if step.SSLMode == "" {
+			step.SSLMode = d.SSLMode
+		}
+		if step.SSLCA == "" {
+			step.SSLCA = d.SSLCA
+		}
+	}
+	return step
+}
+
+// PresetOverrides change the preset steps their keys select. A key is a
+// step name optionally followed by the leading args of the steps to
+// change, e.g. "node.npm ci" or "php.laravel migrate:fresh".
+type PresetOverrides map[string]PresetOverride
+
+// Selectors returns the keys in the order their overrides apply: shorter
+// selectors first, so "node.npm ci" wins over "node.npm" for the steps
+// both select, and alphabetically among selectors of the same length.
+func (o PresetOverrides) Selectors() []string {
+	selectors := make([]string, 0, len(o))
+	for selector := range o {
+		selectors = append(selectors, selector)
+	}
+	sort.Slice(selectors, func(i, j int) bool {
+		a, b := len(strings.Fields(selectors[i])), len(strings.Fields(selectors[j]))
+		if a != b {
+			return a < b
+		}
+		return selectors[i] < selectors[j]
+	})
+	return selectors
+}
+
+// PresetOverride changes the preset steps its selector picks. Fields left
+// unset keep the preset's value.
+type PresetOverride struct {
+	Enabled   *bool                  `mapstructure:"enabled"`
+	Args      []string               `mapstructure:"args"`
+	Condition map[string]interface{} `mapstructure:"condition"`
+}
+
+// SelectorMatches reports whether a preset_overrides selector picks a
+// step.
+func SelectorMatches(selector string, step StepConfig) bool {
+	fields := strings.Fields(selector)
+	if len(fields) == 0 || fields[0] != step.Name || len(step.Args) < len(fields)-1 {
+		return false
+	}
+	for i, arg := range fields[1:] {
+		if step.Args[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns step with the override's fields set.
+func (o PresetOverride) Apply(step StepConfig) StepConfig {
+	if o.Enabled != nil {
+		step.Enabled = o.Enabled
+	}
+	if o.Args != nil {
+		step.Args = o.Args
+	}
+	if o.Condition != nil {
+		step.Condition = o.Condition
+	}
+	return step
 }
 
 // StepConfig represents a scaffold step configuration
 type StepConfig struct {
-	Name       string                 `mapstructure:"name"`
+	Name string `mapstructure:"name"`
+	// Group, instead of Name, includes the steps of a step group in its
+	// place. Only Enabled and Condition may be set alongside it.
+	Group      string                 `mapstructure:"group"`
 	Enabled    *bool                  `mapstructure:"enabled"`
 	Args       []string               `mapstructure:"args"`
 	Command    string                 `mapstructure:"command"`
@@ -82,6 +350,76 @@ type StepConfig struct {
 	Source     string                 `mapstructure:"source"`
 	SourceFile string                 `mapstructure:"source_file"`
 	Type       string                 `mapstructure:"type"`
+	// Mode is env.write's behaviour towards existing keys: upsert
+	// (default), update_only or append_if_missing.
+	Mode string `mapstructure:"mode"`
+	// ContinueOnError records a failure of this step as a warning and
+	// carries on with the remaining steps instead of aborting the run.
+	ContinueOnError bool `mapstructure:"continue_on_error"`
+	// Lock names a machine-wide lock held while the step runs, so
+	// parallel scaffolds take turns using a shared resource.
+	Lock string `mapstructure:"lock"`
+	// OnConnectionFailure is db.create's behaviour when the database
+	// server cannot be reached: skip (default), fail or retry.
+	OnConnectionFailure string `mapstructure:"on_connection_failure"`
+	// SSLMode, SSLCA and SSLSkipVerify configure TLS for db.create and
+	// db.destroy connections.
+	SSLMode       string `mapstructure:"ssl_mode"`
+	SSLCA         string `mapstructure:"ssl_ca"`
+	SSLSkipVerify bool   `mapstructure:"ssl_skip_verify"`
+	// Charset and Collation (MySQL) and Owner and Template (PostgreSQL)
+	// are applied to databases db.create creates. laravel.permissions
+	// grants Owner, the web server user, access to its paths.
+	Charset   string `mapstructure:"charset"`
+	Collation string `mapstructure:"collation"`
+	Owner     string `mapstructure:"owner"`
+	Template  string `mapstructure:"template"`
+	// ConfirmAbove is how many databases db.destroy drops without asking;
+	// dropping more needs a confirmation, or --force when prompts are
+	// off. 0 means DefaultDbDestroyConfirmAbove.
+	ConfirmAbove int `mapstructure:"confirm_above"`
+	// Shell is the shell bash.run runs its command with: bash (default),
+	// zsh, sh or pwsh. Overrides the project's shell.
+	Shell string `mapstructure:"shell"`
+	// Paths are the package directories composer.link registers as path
+	// repositories, or the directories laravel.permissions makes
+	// writable, relative to the worktree.
+	Paths []string `mapstructure:"paths"`
+	// Host and Port are the SMTP server mail.catcher points the app at.
+	// They and Tag are left out of a step's JSON when empty, as fields
+	// added from here on should be, so the hashes recorded for steps that
+	// do not set them stay valid.
+	Host string `mapstructure:"host" json:",omitempty"`
+	Port int    `mapstructure:"port" json:",omitempty"`
+	// Tag labels the mail mail.catcher's worktree sends, so mail from
+	// different worktrees can be told apart in the catcher.
+	Tag string `mapstructure:"tag" json:",omitempty"`
+	// EOL is the line ending file.copy writes: auto (default), keep, lf
+	// or crlf.
+	EOL string `mapstructure:"eol" json:",omitempty"`
+	// Packages maps the package names node.link links to their
+	// directories, relative to the worktree.
+	Packages map[string]string `mapstructure:"packages"`
+	// Provides names template variables the step sets for later steps,
+	// besides the one store_as names.
+	Provides []string `mapstructure:"provides"`
+	// Requires names template variables the step needs. Each must be a
+	// built-in variable or be provided by an earlier step; this is
+	// checked before any step runs.
+	Requires []string `mapstructure:"requires"`
+}
+
+// StepConfigKeys returns the keys a scaffold step accepts in arbor.yaml, in
+// the order StepConfig declares them.
+func StepConfigKeys() []string {
+	t := reflect.TypeOf(StepConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		if tag := t.Field(i).Tag.Get("mapstructure"); tag != "" {
+			keys = append(keys, tag)
+		}
+	}
+	return keys
 }
 
 // GetConditionString returns a string value from the condition map for the given key.
@@ -117,10 +455,32 @@ func (s StepConfig) HasCondition(key string) bool {
 	return exists
 }
 
+// ProvidedVars returns the template variables the step sets: those it
+// declares in provides, the store_as variable, and the key env.read reads
+// when it names no store_as.
+func (s StepConfig) ProvidedVars() []string {
+	vars := append([]string(nil), s.Provides...)
+	switch {
+	case s.StoreAs != "":
+		vars = append(vars, s.StoreAs)
+	case s.Name == "env.read" && s.Key != "":
+		vars = append(vars, s.Key)
+	}
+	return vars
+}
+
 // CleanupStep represents a cleanup step configuration
 type CleanupStep struct {
-	Name      string                 `mapstructure:"name"`
+	Name string `mapstructure:"name"`
+	// Group, instead of Name, includes the steps of a step group.
+	Group     string                 `mapstructure:"group"`
 	Condition map[string]interface{} `mapstructure:"condition"`
+	// Args, Type and ConfirmAbove configure db.destroy, and Packages and
+	// Type node.unlink, as they do on a scaffold step.
+	Args         []string          `mapstructure:"args"`
+	Type         string            `mapstructure:"type"`
+	ConfirmAbove int               `mapstructure:"confirm_above"`
+	Packages     map[string]string `mapstructure:"packages"`
 }
 
 // GetConditionString returns a string value from the condition map for the given key.
@@ -159,6 +519,9 @@ func (s CleanupStep) HasCondition(key string) bool {
 // CleanupConfig represents cleanup configuration
 type CleanupConfig struct {
 	Steps []CleanupStep `mapstructure:"steps"`
+	// PresetOverrides disable or change single cleanup steps of the
+	// preset, like scaffold.preset_overrides.
+	PresetOverrides PresetOverrides `mapstructure:"preset_overrides"`
 }
 
 // ToolConfig represents tool-specific configuration
@@ -168,10 +531,26 @@ type ToolConfig struct {
 
 // GlobalConfig represents the global configuration
 type GlobalConfig struct {
-	DefaultBranch string               `mapstructure:"default_branch"`
-	DetectedTools map[string]bool      `mapstructure:"detected_tools"`
-	Tools         map[string]ToolInfo  `mapstructure:"tools"`
-	Scaffold      GlobalScaffoldConfig `mapstructure:"scaffold"`
+	DefaultBranch string                    `mapstructure:"default_branch"`
+	DetectedTools map[string]bool           `mapstructure:"detected_tools"`
+	Tools         map[string]ToolInfo       `mapstructure:"tools"`
+	Scaffold      GlobalScaffoldConfig      `mapstructure:"scaffold"`
+	UI            GlobalUIConfig            `mapstructure:"ui"`
+	Templates     map[string]TemplateConfig `mapstructure:"templates"`
+}
+
+// TemplateConfig defines a workspace template for `arbor init --template`.
+// Command generates a new project at {{ .Path }}.
+type TemplateConfig struct {
+	Description string `mapstructure:"description"`
+	Command     string `mapstructure:"command"`
+	Preset      string `mapstructure:"preset"`
+}
+
+// GlobalUIConfig represents terminal output preferences
+type GlobalUIConfig struct {
+	Theme  string `mapstructure:"theme"`
+	Locale string `mapstructure:"locale"`
 }
 
 // ToolInfo represents detected tool information
@@ -188,7 +567,13 @@ type GlobalScaffoldConfig struct {
 
 // LoadProject loads project configuration from arbor.yaml
 func LoadProject(path string) (*Config, error) {
-	v := viper.New()
+	return loadProject(path, runtime.GOOS)
+}
+
+func loadProject(path, goos string) (*Config, error) {
+	// Keys are never split on dots, so preset_overrides selectors such as
+	// "node.npm ci" stay single keys.
+	v := viper.NewWithOptions(viper.KeyDelimiter("::"))
 
 	v.SetConfigName("arbor")
 	v.SetConfigType("yaml")
@@ -200,6 +585,9 @@ func LoadProject(path string) (*Config, error) {
 		}
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
+	if err := mergeOverrides(v, goos); err != nil {
+		return nil, err
+	}
 
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
@@ -209,6 +597,39 @@ func LoadProject(path string) (*Config, error) {
 	return &config, nil
 }
 
+// mergeOverrides merges the overrides section for goos over the config
+// read into v.
+func mergeOverrides(v *viper.Viper, goos string) error {
+	if !v.IsSet("overrides") {
+		return nil
+	}
+	overrides, ok := v.Get("overrides").(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("parsing config: overrides must be a map of operating systems")
+	}
+	for name, values := range overrides {
+		if !slices.Contains(OverrideOSes, name) {
+			return fmt.Errorf("parsing config: overrides.%s: unknown operating system (want one of %s)", name, strings.Join(OverrideOSes, ", "))
+		}
+		section, ok := values.(map[string]interface{})
+		if !ok && values != nil {
+			return fmt.Errorf("parsing config: overrides.%s must be a map", name)
+		}
+		if _, nested := section["overrides"]; nested {
+			return fmt.Errorf("parsing config: overrides.%s cannot contain overrides", name)
+		}
+	}
+
+	values, _ := overrides[goos].(map[string]interface{})
+	if len(values) == 0 {
+		return nil
+	}
+	if err := v.MergeConfigMap(values); err != nil {
+		return fmt.Errorf("merging overrides.%s: %w", goos, err)
+	}
+	return nil
+}
+
 // LoadGlobal loads global configuration from arbor.yaml
 func LoadGlobal() (*GlobalConfig, error) {
 	configDir, err := GetGlobalConfigDir()
@@ -224,7 +645,7 @@ func LoadGlobal() (*GlobalConfig, error) {
 
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return nil, fmt.Errorf("global arbor.yaml not found in %s", configDir)
+			return nil, fmt.Errorf("global arbor.yaml not found in %s: %w", configDir, arborerrors.ErrConfigNotFound)
 		}
 		return nil, fmt.Errorf("reading global config: %w", err)
 	}
@@ -238,8 +659,16 @@ func LoadGlobal() (*GlobalConfig, error) {
 }
 
 // SaveProject saves project configuration to arbor.yaml.
-// Preserves existing YAML structure, comments, and formatting.
+// Preserves existing YAML structure, comments, and formatting. A key set
+// by the overrides section for the running OS is saved there, so the
+// base value other systems read stays as it was.
 func SaveProject(path string, config *Config) error {
+	return saveProject(path, config, runtime.GOOS)
+}
+
+func saveProject(path string, config *Config, goos string) error {
+	defer markWritten()
+
 	configPath := filepath.Join(path, "arbor.yaml")
 
 	// Read existing file content if it exists
@@ -271,12 +700,24 @@ func SaveProject(path string, config *Config) error {
 		}
 	}
 
+	// The overrides section for goos, whose keys are saved there
+	override := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if overrides := nodeValue(root, "overrides"); overrides != nil && overrides.Kind == yaml.MappingNode {
+		if section := nodeValue(overrides, goos); section != nil && section.Kind == yaml.MappingNode {
+			override = section
+		}
+	}
+
 	// Helper function to set or update a value in the mapping
 	setValue := func(key string, value interface{}) {
+		mapping := root
+		if nodeValue(override, key) != nil {
+			mapping = override
+		}
 		// Find if key already exists
-		for i := 0; i < len(root.Content); i += 2 {
-			if root.Content[i].Value == key {
-				valueNode := root.Content[i+1]
+		for i := 0; i < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				valueNode := mapping.Content[i+1]
 				replacement := interfaceToNode(value)
 				if valueNode.Kind == yaml.ScalarNode && replacement.Kind == yaml.ScalarNode {
 					valueNode.Value = replacement.Value
@@ -284,21 +725,34 @@ func SaveProject(path string, config *Config) error {
 					return
 				}
 				// Update existing value
-				root.Content[i+1] = replacement
+				mapping.Content[i+1] = replacement
 				return
 			}
 		}
 		// Key doesn't exist, add it
-		root.Content = append(root.Content, &yaml.Node{
+		mapping.Content = append(mapping.Content, &yaml.Node{
 			Kind:  yaml.ScalarNode,
 			Tag:   "!!str",
 			Value: key,
 		})
-		root.Content = append(root.Content, interfaceToNode(value))
+		mapping.Content = append(mapping.Content, interfaceToNode(value))
 	}
 
 	// Helper function to set nested values (e.g., sync.upstream)
 	setNestedValue := func(section string, values map[string]interface{}, orderedKeys []string) {
+		// Values the override sets go to its section
+		if overridden := nodeValue(override, section); overridden != nil && overridden.Kind == yaml.MappingNode {
+			for _, key := range orderedKeys {
+				if value, ok := values[key]; ok && nodeValue(overridden, key) != nil {
+					setNodeValue(overridden, key, interfaceToNode(value))
+					delete(values, key)
+				}
+			}
+			if len(values) == 0 {
+				return
+			}
+		}
+
 		// Find the section
 		var sectionNode *yaml.Node
 		var sectionIndex int
@@ -397,7 +851,7 @@ func SaveProject(path string, config *Config) error {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
+	if err := WriteConfigFile(configPath, content); err != nil {
 		return fmt.Errorf("writing config: %w", err)
 	}
 
@@ -477,6 +931,8 @@ func GetGlobalConfigDir() (string, error) {
 
 // CreateGlobalConfig creates the global config directory and file
 func CreateGlobalConfig(config *GlobalConfig) error {
+	defer markWritten()
+
 	configDir, err := GetGlobalConfigDir()
 	if err != nil {
 		return err
@@ -491,16 +947,56 @@ func CreateGlobalConfig(config *GlobalConfig) error {
 	v.SetConfigType("yaml")
 	v.AddConfigPath(configDir)
 
-	if err := v.MergeConfigMap(map[string]interface{}{
+	values := map[string]interface{}{
 		"default_branch": config.DefaultBranch,
 		"detected_tools": config.DetectedTools,
-		"scaffold":       config.Scaffold,
-	}); err != nil {
+		"scaffold": map[string]interface{}{
+			"parallel_dependencies": config.Scaffold.ParallelDependencies,
+			"interactive":           config.Scaffold.Interactive,
+		},
+	}
+	if len(config.Tools) > 0 {
+		tools := map[string]interface{}{}
+		for name, info := range config.Tools {
+			tools[name] = map[string]interface{}{"path": info.Path, "version": info.Version}
+		}
+		values["tools"] = tools
+	}
+	ui := map[string]interface{}{}
+	if config.UI.Theme != "" {
+		ui["theme"] = config.UI.Theme
+	}
+	if config.UI.Locale != "" {
+		ui["locale"] = config.UI.Locale
+	}
+	if len(ui) > 0 {
+		values["ui"] = ui
+	}
+	if len(config.Templates) > 0 {
+		templates := map[string]interface{}{}
+		for name, t := range config.Templates {
+			template := map[string]interface{}{"command": t.Command}
+			if t.Description != "" {
+				template["description"] = t.Description
+			}
+			if t.Preset != "" {
+				template["preset"] = t.Preset
+			}
+			templates[name] = template
+		}
+		values["templates"] = templates
+	}
+
+	if err := v.MergeConfigMap(values); err != nil {
 		return fmt.Errorf("merging config: %w", err)
 	}
 
+	var content bytes.Buffer
+	if err := v.WriteConfigTo(&content); err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
 	configPath := filepath.Join(configDir, "arbor.yaml")
-	if err := v.WriteConfigAs(configPath); err != nil {
+	if err := WriteConfigFile(configPath, content.Bytes()); err != nil {
 		return fmt.Errorf("writing config: %w", err)
 	}
 
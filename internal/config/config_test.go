@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -26,6 +27,194 @@ default_branch: main
 	assert.Equal(t, "main", cfg.DefaultBranch)
 }
 
+func TestLoadProject_EnvFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte("env_file: .env.local\n"), 0644))
+
+	cfg, err := LoadProject(tmpDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, ".env.local", cfg.PrimaryEnvFile())
+	assert.Equal(t, ".env", (&Config{}).PrimaryEnvFile())
+}
+
+func TestLoadProject_PresetOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `scaffold:
+  preset_overrides:
+    "node.npm ci":
+      enabled: false
+    php.laravel migrate:fresh:
+      args: ["migrate", "--force"]
+    node.npm:
+      condition:
+        file_exists: package.json
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(configContent), 0644))
+
+	cfg, err := LoadProject(tmpDir)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Scaffold.PresetOverrides, 3)
+	override := cfg.Scaffold.PresetOverrides["node.npm ci"]
+	require.NotNil(t, override.Enabled)
+	assert.False(t, *override.Enabled)
+	assert.Equal(t, []string{"migrate", "--force"}, cfg.Scaffold.PresetOverrides["php.laravel migrate:fresh"].Args)
+	assert.Equal(t, []string{"node.npm", "node.npm ci", "php.laravel migrate:fresh"}, cfg.Scaffold.PresetOverrides.Selectors(), "more specific selectors apply last")
+
+	assert.True(t, SelectorMatches("node.npm ci", StepConfig{Name: "node.npm", Args: []string{"ci"}}))
+	assert.False(t, SelectorMatches("node.npm ci", StepConfig{Name: "node.npm", Args: []string{"run", "build"}}))
+	assert.False(t, SelectorMatches("node.npm ci", StepConfig{Name: "node.npm"}))
+}
+
+func TestLoadProject_StepGroups(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `step_groups:
+  Frontend-Setup:
+    - name: node.npm
+      args: ["ci"]
+    - name: node.npm
+      args: ["run", "build"]
+scaffold:
+  steps:
+    - group: frontend-setup
+      condition:
+        file_exists: package.json
+cleanup:
+  steps:
+    - group: Frontend-Setup
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(configContent), 0644))
+
+	cfg, err := LoadProject(tmpDir)
+
+	require.NoError(t, err)
+	assert.Len(t, cfg.StepGroups["frontend-setup"], 2)
+	assert.Equal(t, "frontend-setup", cfg.Scaffold.Steps[0].Group)
+	assert.Equal(t, "Frontend-Setup", cfg.Cleanup.Steps[0].Group)
+
+	located, errs := cfg.ExpandSteps("scaffold.steps", cfg.Scaffold.Steps)
+	require.Empty(t, errs)
+	require.Len(t, located, 2)
+	assert.Equal(t, []string{"run", "build"}, located[1].Step.Args)
+	assert.Equal(t, map[string]interface{}{"file_exists": "package.json"}, located[1].Step.Condition)
+}
+
+func TestLoadProject_Overrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `site_name: shop
+db_naming: branch
+step_groups:
+  serve:
+    - name: herd.link
+scaffold:
+  steps:
+    - name: php.composer
+      args: ["install"]
+    - group: serve
+  defaults:
+    lock: db
+    ssl_mode: prefer
+overrides:
+  linux:
+    db_naming: random
+    step_groups:
+      serve:
+        - name: bash.run
+          command: valet link
+    scaffold:
+      defaults:
+        ssl_mode: require
+  windows: {}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(configContent), 0644))
+
+	cfg, err := loadProject(tmpDir, "linux")
+	require.NoError(t, err)
+	assert.Equal(t, "shop", cfg.SiteName)
+	assert.Equal(t, "random", cfg.DbNaming)
+	assert.Equal(t, []StepConfig{{Name: "bash.run", Command: "valet link"}}, cfg.StepGroups["serve"], "lists are replaced")
+	assert.Equal(t, "db", cfg.Scaffold.Defaults.Lock, "maps are merged")
+	assert.Equal(t, "require", cfg.Scaffold.Defaults.SSLMode)
+	assert.Len(t, cfg.Scaffold.Steps, 2)
+
+	cfg, err = loadProject(tmpDir, "darwin")
+	require.NoError(t, err)
+	assert.Equal(t, "branch", cfg.DbNaming)
+	assert.Equal(t, []StepConfig{{Name: "herd.link"}}, cfg.StepGroups["serve"])
+	assert.Equal(t, "prefer", cfg.Scaffold.Defaults.SSLMode)
+
+	cfg, err = loadProject(tmpDir, "windows")
+	require.NoError(t, err)
+	assert.Equal(t, "branch", cfg.DbNaming)
+}
+
+func TestLoadProject_InvalidOverrides(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"unknown os": {"overrides:\n  macos:\n    db_naming: branch\n", "overrides.macos: unknown operating system"},
+		"not a map":  {"overrides: darwin\n", "overrides must be a map"},
+		"os not map": {"overrides:\n  darwin: [a]\n", "overrides.darwin must be a map"},
+		"nested":     {"overrides:\n  darwin:\n    overrides:\n      linux: {}\n", "overrides.darwin cannot contain overrides"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(tt.content), 0644))
+
+			_, err := loadProject(tmpDir, "linux")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLoadProject_ScaffoldDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `scaffold:
+  defaults:
+    lock: shared
+    ssl_mode: require
+    condition:
+      file_exists: composer.json
+  steps:
+    - name: db.create
+      ssl_mode: disable
+    - name: bash.run
+      command: make
+      condition:
+        file_exists: Makefile
+        command_exists: make
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(configContent), 0644))
+
+	cfg, err := LoadProject(tmpDir)
+	require.NoError(t, err)
+	defaults := cfg.Scaffold.Defaults
+
+	db := defaults.Apply(cfg.Scaffold.Steps[0])
+	assert.Equal(t, "shared", db.Lock)
+	assert.Equal(t, "disable", db.SSLMode, "the step's own setting wins")
+	assert.Equal(t, map[string]interface{}{"file_exists": "composer.json"}, db.Condition)
+
+	run := defaults.Apply(cfg.Scaffold.Steps[1])
+	assert.Empty(t, run.SSLMode, "database settings only apply to database steps")
+	assert.Equal(t, map[string]interface{}{"file_exists": "Makefile", "command_exists": "make"}, run.Condition)
+	assert.Equal(t, map[string]interface{}{"file_exists": "composer.json"}, defaults.Condition, "defaults are left unchanged")
+}
+
+func TestLoadProject_ScaffoldProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte("scaffold:\n  profiles: [testing]\n"), 0644))
+
+	cfg, err := LoadProject(tmpDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testing"}, cfg.Scaffold.Profiles)
+}
+
 func TestLoadProject_MissingConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -207,3 +396,94 @@ func loadGlobalFromTestDir(testDir string) (*GlobalConfig, error) {
 
 	return &config, nil
 }
+
+func TestCreateGlobalConfig_Templates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	require.NoError(t, CreateGlobalConfig(&GlobalConfig{
+		DefaultBranch: "main",
+		Templates: map[string]TemplateConfig{
+			"statamic": {Command: "statamic new {{ .Path }}", Preset: "laravel"},
+		},
+	}))
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, TemplateConfig{Command: "statamic new {{ .Path }}", Preset: "laravel"}, cfg.Templates["statamic"])
+}
+
+func TestCreateGlobalConfig_RoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	want := &GlobalConfig{
+		DefaultBranch: "develop",
+		DetectedTools: map[string]bool{"php": true, "herd": false},
+		Tools:         map[string]ToolInfo{"php": {Path: "/usr/bin/php", Version: "8.3.0"}},
+		Scaffold:      GlobalScaffoldConfig{ParallelDependencies: true, Interactive: true},
+		UI:            GlobalUIConfig{Theme: "dracula"},
+	}
+	require.NoError(t, CreateGlobalConfig(want))
+
+	got, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func BenchmarkLoadProject(b *testing.B) {
+	tmpDir := b.TempDir()
+	configContent := `preset: laravel
+default_branch: main
+env_file: .env
+scaffold:
+  steps:
+    - name: php.composer
+      args: [install]
+      condition:
+        file_exists: composer.lock
+    - name: env.write
+      key: DB_DATABASE
+      value: "{{ .DbName }}"
+    - name: node.npm
+      args: [ci]
+cleanup:
+  steps:
+    - name: db.destroy
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "arbor.yaml"), []byte(configContent), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadProject(tmpDir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStepConfigKeys(t *testing.T) {
+	keys := StepConfigKeys()
+	assert.Equal(t, "name", keys[0])
+	assert.Contains(t, keys, "store_as")
+	assert.Contains(t, keys, "continue_on_error")
+	assert.Len(t, keys, reflect.TypeOf(StepConfig{}).NumField())
+}
+
+func TestStepConfig_ProvidedVars(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  StepConfig
+		want []string
+	}{
+		{"declared and store_as", StepConfig{Name: "bash.run", StoreAs: "Commit", Provides: []string{"Token"}}, []string{"Token", "Commit"}},
+		{"env.read key", StepConfig{Name: "env.read", Key: "APP_URL"}, []string{"APP_URL"}},
+		{"env.read store_as wins over key", StepConfig{Name: "env.read", Key: "APP_URL", StoreAs: "AppUrl"}, []string{"AppUrl"}},
+		{"nothing", StepConfig{Name: "file.copy", Key: "ignored"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.ProvidedVars())
+		})
+	}
+}
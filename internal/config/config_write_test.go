@@ -119,6 +119,56 @@ custom_field: custom_value
 			t.Errorf("DefaultBranch mismatch: expected 'develop', got '%s'", loaded.DefaultBranch)
 		}
 	})
+
+	t.Run("keeps base keys an OS override sets", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "arbor.yaml")
+		initialContent := `preset: laravel
+default_branch: main
+sync:
+  upstream: main
+  remote: origin
+overrides:
+  darwin:
+    preset: php
+    sync:
+      upstream: develop
+`
+		if err := os.WriteFile(configPath, []byte(initialContent), 0644); err != nil {
+			t.Fatalf("failed to create initial config: %v", err)
+		}
+
+		cfg, err := loadProject(tmpDir, "darwin")
+		if err != nil {
+			t.Fatalf("loadProject failed: %v", err)
+		}
+		cfg.Sync.Strategy = "merge"
+		if err := saveProject(tmpDir, cfg, "darwin"); err != nil {
+			t.Fatalf("saveProject failed: %v", err)
+		}
+
+		base, err := loadProject(tmpDir, "linux")
+		if err != nil {
+			t.Fatalf("loadProject failed: %v", err)
+		}
+		if base.Preset != "laravel" {
+			t.Errorf("expected base Preset 'laravel', got '%s'", base.Preset)
+		}
+		if base.Sync.Upstream != "main" {
+			t.Errorf("expected base Sync.Upstream 'main', got '%s'", base.Sync.Upstream)
+		}
+		if base.Sync.Strategy != "merge" {
+			t.Errorf("expected Sync.Strategy 'merge', got '%s'", base.Sync.Strategy)
+		}
+
+		darwin, err := loadProject(tmpDir, "darwin")
+		if err != nil {
+			t.Fatalf("loadProject failed: %v", err)
+		}
+		if darwin.Preset != "php" || darwin.Sync.Upstream != "develop" {
+			t.Errorf("expected the darwin override to be kept, got preset '%s' and upstream '%s'", darwin.Preset, darwin.Sync.Upstream)
+		}
+	})
 }
 
 // Helper function to check if a string contains a substring
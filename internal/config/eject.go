@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeNode returns v as arbor.yaml would spell it: structs become
+// mappings of their non-zero fields, keyed by mapstructure tag in the
+// order they are declared but with condition last, and string lists use
+// flow style, e.g. args: [install]. Slices of structs become sequences.
+func EncodeNode(v interface{}) (*yaml.Node, error) {
+	value := reflect.ValueOf(v)
+	switch {
+	case value.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		var condition []*yaml.Node
+		for i := range value.NumField() {
+			field, fieldValue := value.Type().Field(i), value.Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" || !field.IsExported() || fieldValue.IsZero() {
+				continue
+			}
+			valueNode, err := EncodeNode(fieldValue.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("encoding %s: %w", key, err)
+			}
+			pair := []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode}
+			if key == "condition" {
+				condition = pair
+				continue
+			}
+			node.Content = append(node.Content, pair...)
+		}
+		node.Content = append(node.Content, condition...)
+		return node, nil
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := range value.Len() {
+			item, err := EncodeNode(value.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String {
+		node.Style = yaml.FlowStyle
+	}
+	return node, nil
+}
+
+// MarshalYAML returns doc, a document or a node, as YAML indented by two
+// spaces like the arbor.yaml examples.
+func MarshalYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EjectPresetSteps copies a preset's scaffold steps into the arbor.yaml at
+// path, before the steps it already lists, and sets scaffold.override so
+// that only the copies run. The preset's steps run first, so the scaffold
+// runs the same steps as before. scaffold.preset_overrides is removed: the
+// caller applies it to steps beforehand. Comments in arbor.yaml are kept.
+func EjectPresetSteps(path string, steps []StepConfig) error {
+	configPath := filepath.Join(path, "arbor.yaml")
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("reading arbor.yaml: %w", err)
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return fmt.Errorf("parsing arbor.yaml: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("arbor.yaml: expected a mapping at the top level")
+	}
+
+	scaffold, err := mappingValue(root, "scaffold")
+	if err != nil {
+		return err
+	}
+	stepsNode, err := EncodeNode(steps)
+	if err != nil {
+		return err
+	}
+	if existing := nodeValue(scaffold, "steps"); existing != nil {
+		if existing.Kind != yaml.SequenceNode {
+			return fmt.Errorf("arbor.yaml: scaffold.steps must be a list")
+		}
+		existing.Content = append(stepsNode.Content, existing.Content...)
+	} else {
+		setNodeValue(scaffold, "steps", stepsNode)
+	}
+	setNodeValue(scaffold, "override", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+	deleteNodeValue(scaffold, "preset_overrides")
+
+	out, err := MarshalYAML(doc)
+	if err != nil {
+		return fmt.Errorf("marshaling arbor.yaml: %w", err)
+	}
+	markWritten()
+	if err := WriteConfigFile(configPath, out); err != nil {
+		return fmt.Errorf("writing arbor.yaml: %w", err)
+	}
+	return nil
+}
+
+// nodeValue returns the value of key in a mapping node, or nil.
+func nodeValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the mapping under key, adding an empty one when the
+// key is missing or null.
+func mappingValue(mapping *yaml.Node, key string) (*yaml.Node, error) {
+	value := nodeValue(mapping, key)
+	if value == nil || value.Tag == "!!null" {
+		value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setNodeValue(mapping, key, value)
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("arbor.yaml: %s must be a mapping", key)
+	}
+	return value, nil
+}
+
+// setNodeValue sets key in a mapping node, keeping its position when it
+// is already there.
+func setNodeValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteNodeValue removes key from a mapping node.
+func deleteNodeValue(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeNode(t *testing.T) {
+	disabled := false
+	node, err := EncodeNode([]StepConfig{
+		{Name: "php.laravel", Args: []string{"migrate", "--force"}, Condition: map[string]interface{}{"file_exists": "artisan"}, Enabled: &disabled},
+		{Name: "env.write", Key: "APP_KEY", Value: "{{ .AppKey }}"},
+	})
+	require.NoError(t, err)
+
+	out, err := MarshalYAML(node)
+	require.NoError(t, err)
+	assert.Equal(t, `- name: php.laravel
+  enabled: false
+  args: [migrate, --force]
+  condition:
+    file_exists: artisan
+- name: env.write
+  key: APP_KEY
+  value: '{{ .AppKey }}'
+`, string(out))
+}
+
+func TestEjectPresetSteps(t *testing.T) {
+	dir := t.TempDir()
+	configContent := `# project config
+preset: laravel
+scaffold:
+  preset_overrides:
+    node.npm ci:
+      enabled: false
+  steps:
+    # runs last
+    - name: bash.run
+      command: make
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "arbor.yaml"), []byte(configContent), 0644))
+
+	require.NoError(t, EjectPresetSteps(dir, []StepConfig{{Name: "php.composer", Args: []string{"install"}}}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "arbor.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# project config
+preset: laravel
+scaffold:
+  steps:
+    - name: php.composer
+      args: [install]
+    # runs last
+    - name: bash.run
+      command: make
+  override: true
+`, string(data))
+
+	cfg, err := LoadProject(dir)
+	require.NoError(t, err)
+	assert.True(t, cfg.Scaffold.Override)
+	assert.Len(t, cfg.Scaffold.Steps, 2)
+	assert.Empty(t, cfg.Scaffold.PresetOverrides)
+}
+
+func TestEjectPresetSteps_NoScaffoldSection(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "arbor.yaml"), []byte("preset: php\n"), 0644))
+
+	require.NoError(t, EjectPresetSteps(dir, []StepConfig{{Name: "php.composer", Args: []string{"install"}}}))
+
+	cfg, err := LoadProject(dir)
+	require.NoError(t, err)
+	assert.True(t, cfg.Scaffold.Override)
+	assert.Equal(t, []StepConfig{{Name: "php.composer", Args: []string{"install"}}}, cfg.Scaffold.Steps)
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// FileJournalEntry records a file in a worktree that scaffolds created or
+// changed, by the SHA-256 of its content.
+type FileJournalEntry struct {
+	// Before is the file's hash before the first scaffold changed it;
+	// empty when a scaffold created it.
+	Before string `yaml:"before,omitempty"`
+	// After is the file's hash as the last scaffold left it; empty when
+	// a scaffold removed it.
+	After string `yaml:"after,omitempty"`
+}
+
+// FileChange is a file as a scaffold found it, before a step changed it.
+type FileChange struct {
+	// Path is relative to the worktree, with forward slashes.
+	Path    string
+	Existed bool
+	Content []byte
+}
+
+// JournaledFile is a journaled file and how it compares with what the
+// last scaffold left.
+type JournaledFile struct {
+	Path string
+	FileJournalEntry
+	// Edited is set when the file was changed or removed since the last
+	// scaffold left it.
+	Edited bool
+}
+
+// originalsDir is where the original content of journaled files is kept,
+// by hash, in the worktree's git directory.
+const originalsDir = "arbor-originals"
+
+// HashFileContent returns the hex SHA-256 of file content, as recorded in
+// FileJournalEntry.
+func HashFileContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileOriginalsDir returns the directory holding the original content of
+// the worktree's journaled files: in its git directory, or next to
+// .arbor.local outside a repository.
+func fileOriginalsDir(worktreePath string) string {
+	if gitDir := worktreeGitDir(worktreePath); gitDir != "" {
+		return filepath.Join(gitDir, originalsDir)
+	}
+	return filepath.Join(worktreePath, "."+originalsDir)
+}
+
+// hashFile returns the hash of the file at path, or "" when it does not
+// exist.
+func hashFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return HashFileContent(content), nil
+}
+
+// RecordFileChanges adds the files a scaffold changed to the worktree's
+// file journal in .arbor.local, keeping their original content so
+// RevertFiles can restore it. A file already journaled keeps the original
+// from the first scaffold that changed it, and one that ends up as it
+// originally was is dropped from the journal.
+func RecordFileChanges(worktreePath string, changes []FileChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	state, err := ReadLocalState(worktreePath)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]FileJournalEntry, len(state.Files)+len(changes))
+	for path, entry := range state.Files {
+		files[path] = entry
+	}
+
+	for _, change := range changes {
+		after, err := hashFile(filepath.Join(worktreePath, filepath.FromSlash(change.Path)))
+		if err != nil {
+			return fmt.Errorf("hashing %s: %w", change.Path, err)
+		}
+		entry, journaled := files[change.Path]
+		if !journaled {
+			if change.Existed {
+				entry.Before = HashFileContent(change.Content)
+				if err := saveOriginal(worktreePath, entry.Before, change.Content); err != nil {
+					return err
+				}
+			}
+		}
+		entry.After = after
+		if entry.Before == entry.After {
+			delete(files, change.Path)
+			continue
+		}
+		files[change.Path] = entry
+	}
+
+	if err := updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		if len(files) > 0 {
+			existing["files"] = files
+		} else {
+			delete(existing, "files")
+		}
+	}); err != nil {
+		return err
+	}
+	return pruneOriginals(worktreePath, files)
+}
+
+// saveOriginal keeps content, whose hash is hash, for RevertFiles.
+func saveOriginal(worktreePath, hash string, content []byte) error {
+	dir := fileOriginalsDir(worktreePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(dir, hash), content, 0600); err != nil {
+		return fmt.Errorf("saving original content: %w", err)
+	}
+	return nil
+}
+
+// pruneOriginals removes saved originals no journal entry refers to.
+func pruneOriginals(worktreePath string, files map[string]FileJournalEntry) error {
+	dir := fileOriginalsDir(worktreePath)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", dir, err)
+	}
+	used := make(map[string]bool, len(files))
+	for _, entry := range files {
+		used[entry.Before] = true
+	}
+	for _, entry := range entries {
+		if !used[entry.Name()] {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return fmt.Errorf("removing saved original: %w", err)
+			}
+		}
+	}
+	if len(files) == 0 {
+		_ = os.Remove(dir)
+	}
+	return nil
+}
+
+// JournaledFiles returns the worktree's journaled files, sorted by path.
+func JournaledFiles(worktreePath string) ([]JournaledFile, error) {
+	state, err := ReadLocalState(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]JournaledFile, 0, len(state.Files))
+	for path, entry := range state.Files {
+		current, err := hashFile(filepath.Join(worktreePath, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("hashing %s: %w", path, err)
+		}
+		files = append(files, JournaledFile{Path: path, FileJournalEntry: entry, Edited: current != entry.After})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// RevertFiles puts the worktree's journaled files back as they were before
+// any scaffold changed them, removing the files scaffolds created, and
+// empties the journal. It returns the paths it reverted. Nothing is
+// changed when the original of a file is missing.
+func RevertFiles(worktreePath string) ([]string, error) {
+	files, err := JournaledFiles(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+
+	originals := make(map[string][]byte, len(files))
+	for _, file := range files {
+		if file.Before == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(fileOriginalsDir(worktreePath), file.Before))
+		if err != nil {
+			return nil, fmt.Errorf("reading the original of %s: %w", file.Path, err)
+		}
+		originals[file.Path] = content
+	}
+
+	reverted := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(worktreePath, filepath.FromSlash(file.Path))
+		if file.Before == "" {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return reverted, fmt.Errorf("removing %s: %w", file.Path, err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return reverted, fmt.Errorf("restoring %s: %w", file.Path, err)
+			}
+			if err := utils.WriteFileAtomic(path, originals[file.Path], 0644); err != nil {
+				return reverted, fmt.Errorf("restoring %s: %w", file.Path, err)
+			}
+		}
+		reverted = append(reverted, file.Path)
+	}
+
+	if err := updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		delete(existing, "files")
+	}); err != nil {
+		return reverted, err
+	}
+	return reverted, pruneOriginals(worktreePath, nil)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJournal_RecordAndRevert(t *testing.T) {
+	worktree := t.TempDir()
+	output, err := exec.Command("git", "init", worktree).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	envPath := filepath.Join(worktree, ".env")
+	createdPath := filepath.Join(worktree, "config", "local.php")
+	require.NoError(t, os.WriteFile(envPath, []byte("APP_NAME=app\n"), 0644))
+
+	// A scaffold changes .env and creates config/local.php
+	require.NoError(t, os.WriteFile(envPath, []byte("APP_NAME=app\nDB_DATABASE=app_one\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Dir(createdPath), 0755))
+	require.NoError(t, os.WriteFile(createdPath, []byte("<?php\n"), 0644))
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{
+		{Path: ".env", Existed: true, Content: []byte("APP_NAME=app\n")},
+		{Path: "config/local.php"},
+	}))
+
+	state, err := ReadLocalState(worktree)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FileJournalEntry{
+		".env":             {Before: HashFileContent([]byte("APP_NAME=app\n")), After: HashFileContent([]byte("APP_NAME=app\nDB_DATABASE=app_one\n"))},
+		"config/local.php": {After: HashFileContent([]byte("<?php\n"))},
+	}, state.Files)
+	assert.FileExists(t, filepath.Join(worktree, ".git", originalsDir, state.Files[".env"].Before), "originals are kept out of the worktree")
+
+	// A later scaffold keeps the original from the first
+	require.NoError(t, os.WriteFile(envPath, []byte("APP_NAME=app\nDB_DATABASE=app_two\n"), 0644))
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{
+		{Path: ".env", Existed: true, Content: []byte("APP_NAME=app\nDB_DATABASE=app_one\n")},
+	}))
+
+	files, err := JournaledFiles(worktree)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, ".env", files[0].Path)
+	assert.False(t, files[0].Edited)
+	assert.Equal(t, HashFileContent([]byte("APP_NAME=app\n")), files[0].Before)
+
+	require.NoError(t, os.WriteFile(createdPath, []byte("<?php // edited\n"), 0644))
+	files, err = JournaledFiles(worktree)
+	require.NoError(t, err)
+	assert.True(t, files[1].Edited, "edits after the scaffold are detected")
+
+	reverted, err := RevertFiles(worktree)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env", "config/local.php"}, reverted)
+
+	content, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "APP_NAME=app\n", string(content))
+	assert.NoFileExists(t, createdPath, "files scaffolds created are removed")
+
+	state, err = ReadLocalState(worktree)
+	require.NoError(t, err)
+	assert.Empty(t, state.Files)
+	assert.NoDirExists(t, filepath.Join(worktree, ".git", originalsDir))
+}
+
+func TestFileJournal_UnchangedFilesAreDropped(t *testing.T) {
+	worktree := t.TempDir()
+	envPath := filepath.Join(worktree, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("A=1\n"), 0644))
+
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{{Path: ".env", Existed: true, Content: []byte("A=1\n")}}))
+	files, err := JournaledFiles(worktree)
+	require.NoError(t, err)
+	assert.Empty(t, files, "a file left as it was is not journaled")
+
+	require.NoError(t, os.WriteFile(envPath, []byte("A=2\n"), 0644))
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{{Path: ".env", Existed: true, Content: []byte("A=1\n")}}))
+	require.NoError(t, os.WriteFile(envPath, []byte("A=1\n"), 0644))
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{{Path: ".env", Existed: true, Content: []byte("A=2\n")}}))
+
+	files, err = JournaledFiles(worktree)
+	require.NoError(t, err)
+	assert.Empty(t, files, "a file changed back to its original leaves the journal")
+	assert.NoDirExists(t, filepath.Join(worktree, "."+originalsDir))
+}
+
+func TestRevertFiles_MissingOriginal(t *testing.T) {
+	worktree := t.TempDir()
+	envPath := filepath.Join(worktree, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("A=2\n"), 0644))
+	require.NoError(t, RecordFileChanges(worktree, []FileChange{{Path: ".env", Existed: true, Content: []byte("A=1\n")}}))
+	require.NoError(t, os.RemoveAll(filepath.Join(worktree, "."+originalsDir)))
+
+	_, err := RevertFiles(worktree)
+	require.Error(t, err)
+	content, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, "A=2\n", string(content), "nothing is changed when an original is missing")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +12,30 @@ import (
 // LocalState represents worktree-local state that should never be committed
 type LocalState struct {
 	DbSuffix string `yaml:"db_suffix"`
+	// DbShared is set when the worktree chose to reuse the database of
+	// another worktree, so sharing its db_suffix is intended.
+	DbShared bool `yaml:"db_shared,omitempty"`
+	// DbPrefixes are the sanitized prefixes db.create named this
+	// worktree's databases with, so db.destroy drops only those.
+	DbPrefixes []string `yaml:"db_prefixes,omitempty"`
+	// ScaffoldPending is set when the worktree was created with its
+	// scaffold deferred, and cleared once a scaffold succeeds.
+	ScaffoldPending bool `yaml:"scaffold_pending,omitempty"`
+	// LockfileHashes holds the hash of each dependency lockfile as last
+	// seen by 'arbor daemon', keyed by file name.
+	LockfileHashes map[string]string `yaml:"lockfile_hashes,omitempty"`
+	// Matrix holds the template variables of a worktree created by
+	// 'arbor work --matrix', such as php: "8.3".
+	Matrix map[string]string `yaml:"matrix,omitempty"`
+	// Preset and PresetVersion are the preset whose steps the last full
+	// scaffold ran, and PresetSteps the hashes of those steps, so a newer
+	// arbor can tell which of its preset steps the worktree has not run.
+	Preset        string   `yaml:"preset,omitempty"`
+	PresetVersion int      `yaml:"preset_version,omitempty"`
+	PresetSteps   []string `yaml:"preset_steps,omitempty"`
+	// Files journals the files scaffolds created or changed, keyed by
+	// their path relative to the worktree.
+	Files map[string]FileJournalEntry `yaml:"files,omitempty"`
 }
 
 // ReadLocalState reads worktree-local state from .arbor.local
@@ -34,8 +59,200 @@ func ReadLocalState(worktreePath string) (*LocalState, error) {
 	return &state, nil
 }
 
+// LocalStateValues returns every value in a worktree's .arbor.local as a
+// string, keyed by its dotted path, such as matrix.php, leaving out the
+// file journal. It is empty when the file does not exist.
+func LocalStateValues(worktreePath string) (map[string]string, error) {
+	values := make(map[string]string)
+	content, err := os.ReadFile(filepath.Join(worktreePath, ".arbor.local"))
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading local state: %w", err)
+	}
+
+	var existing map[string]interface{}
+	if err := yaml.Unmarshal(content, &existing); err != nil {
+		return nil, fmt.Errorf("parsing local state: %w", err)
+	}
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, item := range v {
+				flatten(prefix+"."+key, item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				flatten(fmt.Sprintf("%s[%d]", prefix, i), item)
+			}
+		default:
+			values[prefix] = fmt.Sprint(v)
+		}
+	}
+	for key, value := range existing {
+		// The file journal's hashes are bookkeeping, not settings
+		if key == "files" {
+			continue
+		}
+		flatten(key, value)
+	}
+	return values, nil
+}
+
 // WriteLocalState writes worktree-local state to .arbor.local
 func WriteLocalState(worktreePath string, data LocalState) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		// Merge new data into existing state
+		if data.DbSuffix != "" {
+			existing["db_suffix"] = data.DbSuffix
+		}
+	})
+}
+
+// SetDbShared marks or clears a worktree's deliberate reuse of another
+// worktree's database.
+func SetDbShared(worktreePath string, shared bool) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		if shared {
+			existing["db_shared"] = true
+		} else {
+			delete(existing, "db_shared")
+		}
+	})
+}
+
+// AddDbPrefix records a prefix db.create named one of the worktree's
+// databases with.
+func AddDbPrefix(worktreePath, prefix string) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		var prefixes []interface{}
+		if list, ok := existing["db_prefixes"].([]interface{}); ok {
+			prefixes = list
+		}
+		for _, p := range prefixes {
+			if p == prefix {
+				return
+			}
+		}
+		existing["db_prefixes"] = append(prefixes, prefix)
+	})
+}
+
+// ClearDbState forgets a worktree's database suffix, its sharing and its
+// prefixes, so the next scaffold names new databases.
+func ClearDbState(worktreePath string) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		delete(existing, "db_suffix")
+		delete(existing, "db_shared")
+		delete(existing, "db_prefixes")
+	})
+}
+
+// SetScaffoldPending marks or clears a worktree's deferred scaffold.
+func SetScaffoldPending(worktreePath string, pending bool) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		if pending {
+			existing["scaffold_pending"] = true
+		} else {
+			delete(existing, "scaffold_pending")
+		}
+	})
+}
+
+// SetLockfileHashes replaces the recorded lockfile hashes.
+func SetLockfileHashes(worktreePath string, hashes map[string]string) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		if len(hashes) > 0 {
+			existing["lockfile_hashes"] = hashes
+		} else {
+			delete(existing, "lockfile_hashes")
+		}
+	})
+}
+
+// SetMatrix replaces the matrix variables recorded for a worktree.
+func SetMatrix(worktreePath string, vars map[string]string) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		if len(vars) > 0 {
+			existing["matrix"] = vars
+		} else {
+			delete(existing, "matrix")
+		}
+	})
+}
+
+// SetPresetRecord records the preset, version and step hashes of the
+// worktree's last full scaffold.
+func SetPresetRecord(worktreePath, preset string, version int, steps []string) error {
+	return updateLocalState(worktreePath, func(existing map[string]interface{}) {
+		existing["preset"] = preset
+		existing["preset_version"] = version
+		existing["preset_steps"] = steps
+	})
+}
+
+// RelocateLocalState rewrites .arbor.local values that point inside oldPath
+// to the same place under newPath, after the worktree was moved there. It
+// reports whether anything changed.
+func RelocateLocalState(worktreePath, oldPath, newPath string) (bool, error) {
+	configPath := filepath.Join(worktreePath, ".arbor.local")
+
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading local state: %w", err)
+	}
+
+	var existing map[string]interface{}
+	if err := yaml.Unmarshal(content, &existing); err != nil {
+		return false, fmt.Errorf("parsing existing local state: %w", err)
+	}
+
+	changed := false
+	var relocate func(value interface{}) interface{}
+	relocate = func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			if v == oldPath || strings.HasPrefix(v, oldPath+string(filepath.Separator)) {
+				changed = true
+				return newPath + strings.TrimPrefix(v, oldPath)
+			}
+		case map[string]interface{}:
+			for key, item := range v {
+				v[key] = relocate(item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				v[i] = relocate(item)
+			}
+		}
+		return value
+	}
+	relocate(existing)
+	if !changed {
+		return false, nil
+	}
+
+	content, err = yaml.Marshal(existing)
+	if err != nil {
+		return false, fmt.Errorf("marshaling local state: %w", err)
+	}
+	markWritten()
+	if err := WriteConfigFile(configPath, content); err != nil {
+		return false, fmt.Errorf("writing local state: %w", err)
+	}
+	return true, nil
+}
+
+// updateLocalState applies update to the existing .arbor.local values,
+// preserving keys it does not touch.
+func updateLocalState(worktreePath string, update func(map[string]interface{})) error {
+	defer markWritten()
+
 	configPath := filepath.Join(worktreePath, ".arbor.local")
 
 	// Read existing state if it exists
@@ -50,10 +267,7 @@ func WriteLocalState(worktreePath string, data LocalState) error {
 		existing = make(map[string]interface{})
 	}
 
-	// Merge new data into existing state
-	if data.DbSuffix != "" {
-		existing["db_suffix"] = data.DbSuffix
-	}
+	update(existing)
 
 	// Marshal and write
 	content, err := yaml.Marshal(existing)
@@ -61,7 +275,7 @@ func WriteLocalState(worktreePath string, data LocalState) error {
 		return fmt.Errorf("marshaling local state: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
+	if err := WriteConfigFile(configPath, content); err != nil {
 		return fmt.Errorf("writing local state: %w", err)
 	}
 
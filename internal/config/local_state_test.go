@@ -164,3 +164,206 @@ func TestWriteLocalState_EmptyDbSuffix(t *testing.T) {
 		t.Errorf("expected db_suffix 'original' to be preserved, got: %v", data["db_suffix"])
 	}
 }
+
+func TestSetScaffoldPending(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := WriteLocalState(tmpDir, LocalState{DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := SetScaffoldPending(tmpDir, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.ScaffoldPending {
+		t.Error("expected scaffold to be pending")
+	}
+	if state.DbSuffix != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %q", state.DbSuffix)
+	}
+
+	if err := SetScaffoldPending(tmpDir, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, ".arbor.local"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+	if _, ok := data["scaffold_pending"]; ok {
+		t.Error("expected scaffold_pending to be removed")
+	}
+	if data["db_suffix"] != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %v", data["db_suffix"])
+	}
+}
+
+func TestSetMatrix(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := WriteLocalState(tmpDir, LocalState{DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SetMatrix(tmpDir, map[string]string{"php": "8.3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Matrix["php"] != "8.3" {
+		t.Errorf("expected php 8.3 in matrix, got: %v", state.Matrix)
+	}
+	if state.DbSuffix != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %q", state.DbSuffix)
+	}
+
+	if err := SetMatrix(tmpDir, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err = ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(state.Matrix) != 0 {
+		t.Errorf("expected matrix to be removed, got: %v", state.Matrix)
+	}
+}
+
+func TestSetPresetRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := WriteLocalState(tmpDir, LocalState{DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SetPresetRecord(tmpDir, "laravel", 2, []string{"a1b2c3d4", "e5f60718"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Preset != "laravel" || state.PresetVersion != 2 {
+		t.Errorf("expected laravel v2, got: %s v%d", state.Preset, state.PresetVersion)
+	}
+	if len(state.PresetSteps) != 2 || state.PresetSteps[1] != "e5f60718" {
+		t.Errorf("expected the step hashes to be recorded, got: %v", state.PresetSteps)
+	}
+	if state.DbSuffix != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %q", state.DbSuffix)
+	}
+}
+
+func TestRelocateLocalState(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(string(filepath.Separator)+"projects", "app", "feature")
+	newPath := filepath.Join(string(filepath.Separator)+"fast", "feature")
+
+	content := "db_suffix: swift_runner\n" +
+		"sqlite: " + filepath.Join(oldPath, "database", "app.sqlite") + "\n" +
+		"sibling: " + oldPath + "-2\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, ".arbor.local"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	changed, err := RelocateLocalState(tmpDir, oldPath, newPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected local state to change")
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".arbor.local"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	var state map[string]interface{}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+	if state["sqlite"] != filepath.Join(newPath, "database", "app.sqlite") {
+		t.Errorf("expected sqlite path to move, got: %v", state["sqlite"])
+	}
+	if state["sibling"] != oldPath+"-2" {
+		t.Errorf("expected sibling path to be untouched, got: %v", state["sibling"])
+	}
+	if state["db_suffix"] != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %v", state["db_suffix"])
+	}
+}
+
+func TestRelocateLocalState_MissingFile(t *testing.T) {
+	changed, err := RelocateLocalState(t.TempDir(), "/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed {
+		t.Error("expected no change without .arbor.local")
+	}
+}
+
+func TestAddDbPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := WriteLocalState(tmpDir, LocalState{DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, prefix := range []string{"shop", "testing", "shop"} {
+		if err := AddDbPrefix(tmpDir, prefix); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	state, err := ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(state.DbPrefixes) != 2 || state.DbPrefixes[0] != "shop" || state.DbPrefixes[1] != "testing" {
+		t.Errorf("expected prefixes [shop testing], got: %v", state.DbPrefixes)
+	}
+	if state.DbSuffix != "swift_runner" {
+		t.Errorf("expected db_suffix to be preserved, got: %q", state.DbSuffix)
+	}
+}
+
+func TestClearDbState(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := WriteLocalState(tmpDir, LocalState{DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := AddDbPrefix(tmpDir, "shop"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SetScaffoldPending(tmpDir, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ClearDbState(tmpDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := ReadLocalState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.DbSuffix != "" || len(state.DbPrefixes) != 0 {
+		t.Errorf("expected database state to be cleared, got suffix %q and prefixes %v", state.DbSuffix, state.DbPrefixes)
+	}
+	if !state.ScaffoldPending {
+		t.Error("expected scaffold_pending to be preserved")
+	}
+}
@@ -48,7 +48,8 @@ func MigrateDbSuffixToLocal(worktreePath string) (bool, error) {
 		return false, fmt.Errorf("marshaling arbor.yaml: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, newContent, 0644); err != nil {
+	markWritten()
+	if err := WriteConfigFile(configPath, newContent); err != nil {
 		return false, fmt.Errorf("writing arbor.yaml: %w", err)
 	}
 
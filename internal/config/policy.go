@@ -0,0 +1,90 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// PolicyConfig restricts what a project's scaffold and cleanup steps may
+// do, so a reviewed arbor.yaml cannot run risky commands unnoticed.
+type PolicyConfig struct {
+	// DenyCommands are command patterns bash.run and command.run steps may
+	// not run, such as "rm -rf" or "curl | bash". See DeniedCommand.
+	DenyCommands []string `mapstructure:"deny_commands"`
+	// RequireConfirmation names steps, such as db.destroy, that only run
+	// after the user confirms them or passes --trust.
+	RequireConfirmation []string `mapstructure:"require_confirmation"`
+}
+
+// DeniedCommand returns the first deny_commands pattern command matches.
+// A pattern matches when the command contains the pattern's words in
+// order, so "curl | bash" matches "curl -fsSL https://x.sh | bash" and
+// "rm -rf" matches "rm -rf build". Shell operators such as |, ; and &&
+// are words of their own, whether or not spaces surround them.
+func (p PolicyConfig) DeniedCommand(command string) (string, bool) {
+	words := commandWords(command)
+	for _, pattern := range p.DenyCommands {
+		patternWords := commandWords(pattern)
+		if len(patternWords) > 0 && containsInOrder(words, patternWords) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// RequiresConfirmation reports whether the policy requires confirmation
+// before the named step runs.
+func (p PolicyConfig) RequiresConfirmation(step string) bool {
+	return slices.Contains(p.RequireConfirmation, step)
+}
+
+// commandWords splits a command into words at whitespace and around runs
+// of the shell operator characters |, &, ; < and >.
+func commandWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	operator := false
+
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range command {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case strings.ContainsRune("|&;<>", r):
+			if !operator {
+				flush()
+			}
+			operator = true
+			word.WriteRune(r)
+			continue
+		default:
+			if operator {
+				flush()
+			}
+			word.WriteRune(r)
+		}
+		operator = false
+	}
+	flush()
+	return words
+}
+
+func containsInOrder(words, pattern []string) bool {
+	i := 0
+	for _, word := range words {
+		if word == pattern[i] {
+			i++
+			if i == len(pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
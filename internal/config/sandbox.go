@@ -0,0 +1,18 @@
+package config
+
+// SandboxConfig runs bash.run, command.run and binary steps in a restricted
+// environment, for projects that scaffold branches nobody has reviewed.
+type SandboxConfig struct {
+	// Enabled turns the sandbox on for every scaffold and cleanup run;
+	// --sandbox turns it on for one command.
+	Enabled bool `mapstructure:"enabled"`
+	// AllowEnv names environment variables steps still receive, on top of
+	// PATH, HOME and the locale. Names may end in *, e.g. COMPOSER_*.
+	AllowEnv []string `mapstructure:"allow_env"`
+	// Network allows steps network access, e.g. for composer install.
+	Network bool `mapstructure:"network"`
+	// WritablePaths are directories outside the worktree steps may write
+	// to, such as a package manager cache. A leading ~ is the home
+	// directory.
+	WritablePaths []string `mapstructure:"writable_paths"`
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// schemaDraft is the JSON Schema dialect of generated schemas; it is the
+// one yaml-language-server supports best.
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// SchemaOptions supplies the values arbor.yaml accepts that are defined
+// outside this package, such as registered step names.
+type SchemaOptions struct {
+	// Enums lists the allowed values of keys by dotted path from the root
+	// of the file. List items add no segment and map values add "*", so a
+	// step's name is "scaffold.steps.name" and a template's preset
+	// "templates.*.preset".
+	Enums map[string][]string
+	// EnumDescriptions describe the values of Enums, in the same order,
+	// for editors that show them while completing.
+	EnumDescriptions map[string][]string
+	// StepRequired lists the keys each step requires besides name, by
+	// step name.
+	StepRequired map[string][]string
+	// ConditionKeys are the keys a condition may use.
+	ConditionKeys []string
+}
+
+// SchemaStepPaths are the paths of the lists of scaffold steps in
+// arbor.yaml, for SchemaOptions.Enums.
+var SchemaStepPaths = []string{"scaffold.steps", "ci.steps", "step_groups.*"}
+
+// schemaEnums are the allowed values of keys defined in this package.
+var schemaEnums = func() map[string][]string {
+	enums := map[string][]string{
+		"db_naming": {"random", "branch"},
+		"shell":     Shells,
+		"scaffold.defaults.on_connection_failure": {OnConnectionFailureSkip, OnConnectionFailureFail, OnConnectionFailureRetry},
+		"scaffold.defaults.ssl_mode":              SSLModes,
+	}
+	for _, path := range SchemaStepPaths {
+		enums[path+".mode"] = []string{EnvWriteModeUpsert, EnvWriteModeUpdateOnly, EnvWriteModeAppendIfMissing}
+		enums[path+".on_connection_failure"] = []string{OnConnectionFailureSkip, OnConnectionFailureFail, OnConnectionFailureRetry}
+		enums[path+".ssl_mode"] = SSLModes
+		enums[path+".shell"] = Shells
+		enums[path+".eol"] = EOLs
+	}
+	return enums
+}()
+
+// ProjectSchema returns a JSON Schema for arbor.yaml.
+func ProjectSchema(opts SchemaOptions) map[string]any {
+	return newSchemaBuilder(opts).document("arbor.yaml", reflect.TypeOf(Config{}))
+}
+
+// GlobalSchema returns a JSON Schema for the global arbor.yaml.
+func GlobalSchema(opts SchemaOptions) map[string]any {
+	return newSchemaBuilder(opts).document("arbor global config", reflect.TypeOf(GlobalConfig{}))
+}
+
+type schemaBuilder struct {
+	enums            map[string][]string
+	enumDescriptions map[string][]string
+	stepRequired     map[string][]string
+	conditionKeys    []string
+	usesCondition    bool
+}
+
+func newSchemaBuilder(opts SchemaOptions) *schemaBuilder {
+	enums := make(map[string][]string, len(schemaEnums)+len(opts.Enums))
+	for path, values := range schemaEnums {
+		enums[path] = values
+	}
+	for path, values := range opts.Enums {
+		enums[path] = values
+	}
+	return &schemaBuilder{
+		enums:            enums,
+		enumDescriptions: opts.EnumDescriptions,
+		stepRequired:     opts.StepRequired,
+		conditionKeys:    opts.ConditionKeys,
+	}
+}
+
+func (b *schemaBuilder) document(title string, t reflect.Type) map[string]any {
+	schema := b.typeSchema(t, "")
+	schema["$schema"] = schemaDraft
+	schema["title"] = title
+	if b.usesCondition {
+		schema["definitions"] = map[string]any{"condition": b.conditionSchema()}
+	}
+	return schema
+}
+
+// typeSchema describes a Go type decoded from YAML by mapstructure. path is
+// the dotted path of the key holding it.
+func (b *schemaBuilder) typeSchema(t reflect.Type, path string) map[string]any {
+	if values, ok := b.enums[path]; ok && t.Kind() == reflect.String {
+		schema := map[string]any{"type": "string", "enum": values}
+		if descriptions := b.enumDescriptions[path]; len(descriptions) == len(values) {
+			schema["enumDescriptions"] = descriptions
+		}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.typeSchema(t.Elem(), path)
+	case reflect.Struct:
+		return b.structSchema(t, path)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": b.typeSchema(t.Elem(), path)}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": b.typeSchema(t.Elem(), joinSchemaPath(path, "*"))}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type, path string) map[string]any {
+	properties := make(map[string]any)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		key, _, _ = strings.Cut(key, ",")
+		if key == "condition" && field.Type.Kind() == reflect.Map {
+			b.usesCondition = true
+			properties[key] = map[string]any{"$ref": "#/definitions/condition"}
+			continue
+		}
+		if key == "overrides" && path == "" {
+			properties[key] = overridesSchema()
+			continue
+		}
+		properties[key] = b.typeSchema(field.Type, joinSchemaPath(path, key))
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	// Steps are identified by name; everything else in arbor.yaml is
+	// optional.
+	if _, ok := properties["name"]; ok && strings.HasSuffix(path, ".steps") {
+		schema["required"] = []string{"name"}
+		if rules := b.stepRequiredRules(properties); len(rules) > 0 {
+			schema["allOf"] = rules
+		}
+	}
+	return schema
+}
+
+// stepRequiredRules requires the keys each step needs when a step list
+// item names it. Steps needing keys the item cannot have are left out.
+func (b *schemaBuilder) stepRequiredRules(properties map[string]any) []any {
+	names := make([]string, 0, len(b.stepRequired))
+	for name := range b.stepRequired {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var rules []any
+	for _, name := range names {
+		required := b.stepRequired[name]
+		if len(required) == 0 || slices.ContainsFunc(required, func(key string) bool { return properties[key] == nil }) {
+			continue
+		}
+		rules = append(rules, map[string]any{
+			"if":   map[string]any{"properties": map[string]any{"name": map[string]any{"const": name}}},
+			"then": map[string]any{"required": required},
+		})
+	}
+	return rules
+}
+
+// conditionSchema describes a condition: a map of condition keys, all of
+// which must hold, or a list of such maps. "not" negates a condition.
+func (b *schemaBuilder) conditionSchema() map[string]any {
+	properties := make(map[string]any, len(b.conditionKeys))
+	for _, key := range b.conditionKeys {
+		properties[key] = map[string]any{}
+	}
+	if slices.Contains(b.conditionKeys, ConditionNot) {
+		properties[ConditionNot] = map[string]any{"$ref": "#/definitions/condition"}
+	}
+
+	object := map[string]any{"type": "object"}
+	if len(properties) > 0 {
+		object["properties"] = properties
+		object["additionalProperties"] = false
+	}
+	return map[string]any{
+		"anyOf": []any{
+			object,
+			map[string]any{"type": "array", "items": map[string]any{"$ref": "#/definitions/condition"}},
+		},
+	}
+}
+
+// overridesSchema describes overrides: each operating system's section
+// accepts the keys of the whole file.
+func overridesSchema() map[string]any {
+	properties := make(map[string]any, len(OverrideOSes))
+	for _, goos := range OverrideOSes {
+		properties[goos] = map[string]any{"$ref": "#"}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectSchema(t *testing.T) {
+	schema := ProjectSchema(SchemaOptions{
+		Enums:         map[string][]string{"scaffold.steps.name": {"bash.run"}},
+		ConditionKeys: []string{ConditionFileExists, ConditionNot},
+	})
+
+	assert.Equal(t, schemaDraft, schema["$schema"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "enum": []string{"random", "branch"}}, properties["db_naming"])
+
+	scaffold := properties["scaffold"].(map[string]any)["properties"].(map[string]any)
+	step := scaffold["steps"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, []string{"name"}, step["required"])
+
+	stepProperties := step["properties"].(map[string]any)
+	assert.Equal(t, []string{"bash.run"}, stepProperties["name"].(map[string]any)["enum"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, stepProperties["args"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, stepProperties["enabled"])
+	assert.Equal(t, map[string]any{"$ref": "#/definitions/condition"}, stepProperties["condition"])
+	assert.Equal(t, map[string]any{"$ref": "#/definitions/condition"}, scaffold["pre_flight"].(map[string]any)["properties"].(map[string]any)["condition"])
+
+	overrides := properties["overrides"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#"}, overrides["properties"].(map[string]any)["darwin"])
+	assert.Equal(t, false, overrides["additionalProperties"])
+
+	condition := schema["definitions"].(map[string]any)["condition"].(map[string]any)
+	object := condition["anyOf"].([]any)[0].(map[string]any)
+	assert.Equal(t, map[string]any{
+		ConditionFileExists: map[string]any{},
+		ConditionNot:        map[string]any{"$ref": "#/definitions/condition"},
+	}, object["properties"])
+}
+
+func TestProjectSchema_StepMetadata(t *testing.T) {
+	schema := ProjectSchema(SchemaOptions{
+		Enums:            map[string][]string{"scaffold.steps.name": {"bash.run", "db.create"}},
+		EnumDescriptions: map[string][]string{"scaffold.steps.name": {"Running bash command", "Creating database"}},
+		StepRequired:     map[string][]string{"bash.run": {"command"}, "unknown.step": {"not_a_key"}},
+	})
+
+	scaffold := schema["properties"].(map[string]any)["scaffold"].(map[string]any)["properties"].(map[string]any)
+	step := scaffold["steps"].(map[string]any)["items"].(map[string]any)
+	name := step["properties"].(map[string]any)["name"].(map[string]any)
+	assert.Equal(t, []string{"Running bash command", "Creating database"}, name["enumDescriptions"])
+	assert.Equal(t, []any{
+		map[string]any{
+			"if":   map[string]any{"properties": map[string]any{"name": map[string]any{"const": "bash.run"}}},
+			"then": map[string]any{"required": []string{"command"}},
+		},
+	}, step["allOf"], "keys a step item cannot have are not required")
+}
+
+func TestGlobalSchema_MapValues(t *testing.T) {
+	schema := GlobalSchema(SchemaOptions{
+		Enums: map[string][]string{"templates.*.preset": {"laravel"}},
+	})
+
+	properties := schema["properties"].(map[string]any)
+	template := properties["templates"].(map[string]any)["additionalProperties"].(map[string]any)
+	preset := template["properties"].(map[string]any)["preset"].(map[string]any)
+	assert.Equal(t, []string{"laravel"}, preset["enum"])
+	assert.NotContains(t, schema, "definitions")
+}
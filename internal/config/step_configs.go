@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 // StepValidator is an interface for step-specific configuration validation.
@@ -33,11 +36,25 @@ func (c BinaryStepConfig) Validate() error {
 	return nil
 }
 
+// Line endings for file.copy's eol: auto converts to the line ending git
+// checks the destination out with, keep copies the file byte for byte, and
+// lf and crlf convert to that line ending.
+const (
+	EOLAuto = "auto"
+	EOLKeep = "keep"
+	EOLLF   = "lf"
+	EOLCRLF = "crlf"
+)
+
+// EOLs are the accepted eol values for file.copy, default first.
+var EOLs = []string{EOLAuto, EOLKeep, EOLLF, EOLCRLF}
+
 // FileCopyConfig represents configuration for file.copy step
 type FileCopyConfig struct {
 	BaseStepConfig
 	From string `mapstructure:"from"`
 	To   string `mapstructure:"to"`
+	EOL  string `mapstructure:"eol"`
 }
 
 // Validate checks that required fields are present for file.copy step
@@ -48,14 +65,25 @@ func (c FileCopyConfig) Validate() error {
 	if c.To == "" {
 		return fmt.Errorf("file.copy: 'to' is required")
 	}
+	if c.EOL != "" && !slices.Contains(EOLs, c.EOL) {
+		return fmt.Errorf("file.copy: 'eol' must be auto, keep, lf or crlf, got %q", c.EOL)
+	}
 	return nil
 }
 
+// DefaultShell is the shell bash.run uses when neither the step nor the
+// project names one.
+const DefaultShell = "bash"
+
+// Shells are the accepted shell values for bash.run, default first.
+var Shells = []string{DefaultShell, "zsh", "sh", "pwsh"}
+
 // BashRunConfig represents configuration for bash.run step
 type BashRunConfig struct {
 	BaseStepConfig
 	Command string `mapstructure:"command"`
 	StoreAs string `mapstructure:"store_as"`
+	Shell   string `mapstructure:"shell"`
 }
 
 // Validate checks that required fields are present for bash.run step
@@ -63,6 +91,9 @@ func (c BashRunConfig) Validate() error {
 	if c.Command == "" {
 		return fmt.Errorf("bash.run: 'command' is required")
 	}
+	if c.Shell != "" && !slices.Contains(Shells, c.Shell) {
+		return fmt.Errorf("bash.run: 'shell' must be bash, zsh, sh or pwsh, got %q", c.Shell)
+	}
 	return nil
 }
 
@@ -81,6 +112,102 @@ func (c CommandRunConfig) Validate() error {
 	return nil
 }
 
+// GitConfigConfig represents configuration for git.config step
+type GitConfigConfig struct {
+	BaseStepConfig
+	Key   string `mapstructure:"key"`
+	Value string `mapstructure:"value"`
+}
+
+// Validate checks that required fields are present for git.config step
+func (c GitConfigConfig) Validate() error {
+	if c.Key == "" {
+		return fmt.Errorf("git.config: 'key' is required")
+	}
+	if c.Value == "" {
+		return fmt.Errorf("git.config: 'value' is required")
+	}
+	return nil
+}
+
+// Types for git.hooks: directory installs the scripts in a directory of
+// the worktree, husky and lefthook run that hook manager.
+const (
+	GitHooksDirectory = "directory"
+	GitHooksHusky     = "husky"
+	GitHooksLefthook  = "lefthook"
+)
+
+// DefaultGitHooksDir is the directory git.hooks installs hook scripts from
+// when it names none.
+const DefaultGitHooksDir = ".githooks"
+
+// GitHooksConfig represents configuration for git.hooks step
+type GitHooksConfig struct {
+	BaseStepConfig
+	Type string `mapstructure:"type"`
+	From string `mapstructure:"from"`
+}
+
+// Validate checks that the type of a git.hooks step is known
+func (c GitHooksConfig) Validate() error {
+	switch c.Type {
+	case "", GitHooksDirectory, GitHooksHusky, GitHooksLefthook:
+	default:
+		return fmt.Errorf("git.hooks: 'type' must be directory, husky or lefthook, got %q", c.Type)
+	}
+	return nil
+}
+
+// ComposerLinkConfig represents configuration for composer.link step
+type ComposerLinkConfig struct {
+	BaseStepConfig
+	Paths []string `mapstructure:"paths"`
+}
+
+// Validate checks that required fields are present for composer.link step
+func (c ComposerLinkConfig) Validate() error {
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("composer.link: 'paths' is required")
+	}
+	return nil
+}
+
+// Package managers node.link and node.unlink can use. When a step names
+// none, the worktree's lockfile decides.
+const (
+	NodeLinkNpm  = "npm"
+	NodeLinkYarn = "yarn"
+)
+
+// Schedule runners scheduler.register can install. When a step names
+// none, the OS decides.
+const (
+	SchedulerLaunchd = "launchd"
+	SchedulerSystemd = "systemd"
+	SchedulerCron    = "cron"
+)
+
+// NodeLinkConfig represents configuration for node.link and node.unlink steps
+type NodeLinkConfig struct {
+	BaseStepConfig
+	Type     string            `mapstructure:"type"`
+	Packages map[string]string `mapstructure:"packages"`
+}
+
+// Validate checks that packages are given and the package manager is known
+func (c NodeLinkConfig) Validate() error {
+	if len(c.Packages) == 0 {
+		return fmt.Errorf("%s: 'packages' is required", c.Name)
+	}
+	switch c.Type {
+	case "", NodeLinkNpm, NodeLinkYarn:
+	default:
+		return fmt.Errorf("%s: 'type' must be npm or yarn, got %q", c.Name, c.Type)
+	}
+	return nil
+}
+
 // EnvReadConfig represents configuration for env.read step
 type EnvReadConfig struct {
 	BaseStepConfig
@@ -97,12 +224,22 @@ func (c EnvReadConfig) Validate() error {
 	return nil
 }
 
+// Modes for env.write: upsert sets the key whether or not it exists,
+// update_only only changes a key already in the file and append_if_missing
+// only adds a key that is not.
+const (
+	EnvWriteModeUpsert          = "upsert"
+	EnvWriteModeUpdateOnly      = "update_only"
+	EnvWriteModeAppendIfMissing = "append_if_missing"
+)
+
 // EnvWriteConfig represents configuration for env.write step
 type EnvWriteConfig struct {
 	BaseStepConfig
 	Key   string `mapstructure:"key"`
 	Value string `mapstructure:"value"`
 	File  string `mapstructure:"file"`
+	Mode  string `mapstructure:"mode"`
 }
 
 // Validate checks that required fields are present for env.write step
@@ -110,6 +247,27 @@ func (c EnvWriteConfig) Validate() error {
 	if c.Key == "" {
 		return fmt.Errorf("env.write: 'key' is required")
 	}
+	switch c.Mode {
+	case "", EnvWriteModeUpsert, EnvWriteModeUpdateOnly, EnvWriteModeAppendIfMissing:
+	default:
+		return fmt.Errorf("env.write: 'mode' must be upsert, update_only or append_if_missing, got %q", c.Mode)
+	}
+	return nil
+}
+
+// EnvUnsetConfig represents configuration for env.unset step
+type EnvUnsetConfig struct {
+	BaseStepConfig
+	Key  string   `mapstructure:"key"`
+	Keys []string `mapstructure:"keys"`
+	File string   `mapstructure:"file"`
+}
+
+// Validate checks that required fields are present for env.unset step
+func (c EnvUnsetConfig) Validate() error {
+	if c.Key == "" && len(c.Keys) == 0 {
+		return fmt.Errorf("env.unset: either 'key' or 'keys' must be specified")
+	}
 	return nil
 }
 
@@ -134,30 +292,94 @@ func (c EnvCopyConfig) Validate() error {
 	return nil
 }
 
+// Behaviours for db.create when the database server cannot be reached.
+const (
+	OnConnectionFailureSkip  = "skip"
+	OnConnectionFailureFail  = "fail"
+	OnConnectionFailureRetry = "retry"
+)
+
+// SSLModes are the accepted ssl_mode values for database steps, from least
+// to most strict.
+var SSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// validateSSLMode checks a database step's ssl_mode.
+func validateSSLMode(step, mode string) error {
+	if mode == "" || slices.Contains(SSLModes, mode) {
+		return nil
+	}
+	return fmt.Errorf("%s: 'ssl_mode' must be one of %s, got %q", step, strings.Join(SSLModes, ", "), mode)
+}
+
 // DbCreateConfig represents configuration for db.create step
 type DbCreateConfig struct {
 	BaseStepConfig
-	Args []string `mapstructure:"args"`
-	Type string   `mapstructure:"type"`
+	Args                []string `mapstructure:"args"`
+	Type                string   `mapstructure:"type"`
+	OnConnectionFailure string   `mapstructure:"on_connection_failure"`
+	SSLMode             string   `mapstructure:"ssl_mode"`
+	Charset             string   `mapstructure:"charset"`
+	Collation           string   `mapstructure:"collation"`
+	Owner               string   `mapstructure:"owner"`
+	Template            string   `mapstructure:"template"`
 }
 
+// dbIdentifierPattern matches the charset, collation, owner and template
+// names db.create accepts; they are interpolated into CREATE DATABASE.
+var dbIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_$-]*$`)
+
 // Validate checks that the db.create step config is valid.
 // All fields are optional for db.create.
 func (c DbCreateConfig) Validate() error {
+	switch c.OnConnectionFailure {
+	case "", OnConnectionFailureSkip, OnConnectionFailureFail, OnConnectionFailureRetry:
+	default:
+		return fmt.Errorf("db.create: 'on_connection_failure' must be skip, fail or retry, got %q", c.OnConnectionFailure)
+	}
+	if err := validateSSLMode("db.create", c.SSLMode); err != nil {
+		return err
+	}
+
+	for _, field := range []struct{ key, value string }{
+		{"charset", c.Charset},
+		{"collation", c.Collation},
+		{"owner", c.Owner},
+		{"template", c.Template},
+	} {
+		if field.value != "" && !dbIdentifierPattern.MatchString(field.value) {
+			return fmt.Errorf("db.create: '%s' must contain only letters, digits, '_', '$' and '-', got %q", field.key, field.value)
+		}
+	}
+
+	if c.Type == "pgsql" && (c.Charset != "" || c.Collation != "") {
+		return fmt.Errorf("db.create: 'charset' and 'collation' apply to mysql databases, use 'template' for pgsql")
+	}
+	if c.Type == "mysql" && (c.Owner != "" || c.Template != "") {
+		return fmt.Errorf("db.create: 'owner' and 'template' apply to pgsql databases")
+	}
 	return nil
 }
 
+// DefaultDbDestroyConfirmAbove is how many databases db.destroy drops
+// without asking when confirm_above is not set.
+const DefaultDbDestroyConfirmAbove = 3
+
 // DbDestroyConfig represents configuration for db.destroy step
 type DbDestroyConfig struct {
 	BaseStepConfig
-	Args []string `mapstructure:"args"`
-	Type string   `mapstructure:"type"`
+	Args         []string `mapstructure:"args"`
+	Type         string   `mapstructure:"type"`
+	SSLMode      string   `mapstructure:"ssl_mode"`
+	ConfirmAbove int      `mapstructure:"confirm_above"`
 }
 
 // Validate checks that the db.destroy step config is valid.
 // All fields are optional for db.destroy.
 func (c DbDestroyConfig) Validate() error {
-	return nil
+	if c.ConfirmAbove < 0 {
+		return fmt.Errorf("db.destroy: 'confirm_above' must not be negative, got %d", c.ConfirmAbove)
+	}
+	return validateSSLMode("db.destroy", c.SSLMode)
 }
 
 // ValidateStepConfig validates a StepConfig based on its step type.
@@ -176,12 +398,14 @@ func ValidateStepConfig(stepName string, cfg StepConfig) error {
 			BaseStepConfig: base,
 			From:           cfg.From,
 			To:             cfg.To,
+			EOL:            cfg.EOL,
 		}.Validate()
 	case "bash.run":
 		return BashRunConfig{
 			BaseStepConfig: base,
 			Command:        cfg.Command,
 			StoreAs:        cfg.StoreAs,
+			Shell:          cfg.Shell,
 		}.Validate()
 	case "command.run":
 		return CommandRunConfig{
@@ -189,6 +413,31 @@ func ValidateStepConfig(stepName string, cfg StepConfig) error {
 			Command:        cfg.Command,
 			StoreAs:        cfg.StoreAs,
 		}.Validate()
+	case "git.config":
+		return GitConfigConfig{
+			BaseStepConfig: base,
+			Key:            cfg.Key,
+			Value:          cfg.Value,
+		}.Validate()
+	case "git.hooks":
+		return GitHooksConfig{
+			BaseStepConfig: base,
+			Type:           cfg.Type,
+			From:           cfg.From,
+		}.Validate()
+	case "composer.link":
+		return ComposerLinkConfig{
+			BaseStepConfig: base,
+			Paths:          cfg.Paths,
+		}.Validate()
+	case "composer.unlink":
+		return nil
+	case "node.link", "node.unlink":
+		return NodeLinkConfig{
+			BaseStepConfig: base,
+			Type:           cfg.Type,
+			Packages:       cfg.Packages,
+		}.Validate()
 	case "env.read":
 		return EnvReadConfig{
 			BaseStepConfig: base,
@@ -202,6 +451,14 @@ func ValidateStepConfig(stepName string, cfg StepConfig) error {
 			Key:            cfg.Key,
 			Value:          cfg.Value,
 			File:           cfg.File,
+			Mode:           cfg.Mode,
+		}.Validate()
+	case "env.unset":
+		return EnvUnsetConfig{
+			BaseStepConfig: base,
+			Key:            cfg.Key,
+			Keys:           cfg.Keys,
+			File:           cfg.File,
 		}.Validate()
 	case "env.copy":
 		return EnvCopyConfig{
@@ -214,15 +471,23 @@ func ValidateStepConfig(stepName string, cfg StepConfig) error {
 		}.Validate()
 	case "db.create":
 		return DbCreateConfig{
-			BaseStepConfig: base,
-			Args:           cfg.Args,
-			Type:           cfg.Type,
+			BaseStepConfig:      base,
+			Args:                cfg.Args,
+			Type:                cfg.Type,
+			OnConnectionFailure: cfg.OnConnectionFailure,
+			SSLMode:             cfg.SSLMode,
+			Charset:             cfg.Charset,
+			Collation:           cfg.Collation,
+			Owner:               cfg.Owner,
+			Template:            cfg.Template,
 		}.Validate()
 	case "db.destroy":
 		return DbDestroyConfig{
 			BaseStepConfig: base,
 			Args:           cfg.Args,
 			Type:           cfg.Type,
+			SSLMode:        cfg.SSLMode,
+			ConfirmAbove:   cfg.ConfirmAbove,
 		}.Validate()
 	default:
 		// Binary steps (php, npm, composer, etc.) and unknown steps
@@ -54,6 +54,19 @@ func TestValidateStepConfig(t *testing.T) {
 			wantErr:  true,
 			errMsg:   "bash.run: 'command' is required",
 		},
+		{
+			name:     "bash.run with a supported shell",
+			stepName: "bash.run",
+			cfg:      StepConfig{Command: "Get-ChildItem", Shell: "pwsh"},
+			wantErr:  false,
+		},
+		{
+			name:     "bash.run with an unknown shell",
+			stepName: "bash.run",
+			cfg:      StepConfig{Command: "ls", Shell: "fish"},
+			wantErr:  true,
+			errMsg:   "bash.run: 'shell' must be bash, zsh, sh or pwsh, got \"fish\"",
+		},
 		{
 			name:     "command.run with command",
 			stepName: "command.run",
@@ -105,6 +118,31 @@ func TestValidateStepConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "env.write: 'key' is required",
 		},
+		{
+			name:     "env.write unknown mode",
+			stepName: "env.write",
+			cfg: StepConfig{
+				Key:  "DB_DATABASE",
+				Mode: "replace",
+			},
+			wantErr: true,
+			errMsg:  `env.write: 'mode' must be upsert, update_only or append_if_missing, got "replace"`,
+		},
+		{
+			name:     "env.unset with keys",
+			stepName: "env.unset",
+			cfg: StepConfig{
+				Keys: []string{"SENTRY_DSN"},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "env.unset missing key and keys",
+			stepName: "env.unset",
+			cfg:      StepConfig{},
+			wantErr:  true,
+			errMsg:   "env.unset: either 'key' or 'keys' must be specified",
+		},
 		{
 			name:     "env.copy with source and key",
 			stepName: "env.copy",
@@ -156,6 +194,65 @@ func TestValidateStepConfig(t *testing.T) {
 			cfg:      StepConfig{},
 			wantErr:  false,
 		},
+		{
+			name:     "db.create with on_connection_failure",
+			stepName: "db.create",
+			cfg:      StepConfig{OnConnectionFailure: "retry"},
+			wantErr:  false,
+		},
+		{
+			name:     "db.create with ssl options",
+			stepName: "db.create",
+			cfg:      StepConfig{SSLMode: "verify-full", SSLCA: "/etc/ssl/ca.pem"},
+			wantErr:  false,
+		},
+		{
+			name:     "db.create with mysql create options",
+			stepName: "db.create",
+			cfg:      StepConfig{Type: "mysql", Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"},
+			wantErr:  false,
+		},
+		{
+			name:     "db.create with pgsql create options",
+			stepName: "db.create",
+			cfg:      StepConfig{Type: "pgsql", Owner: "app-user", Template: "template0"},
+			wantErr:  false,
+		},
+		{
+			name:     "db.create with unsafe collation",
+			stepName: "db.create",
+			cfg:      StepConfig{Collation: "utf8mb4`; DROP DATABASE x"},
+			wantErr:  true,
+			errMsg:   "db.create: 'collation' must contain only letters, digits, '_', '$' and '-', got \"utf8mb4`; DROP DATABASE x\"",
+		},
+		{
+			name:     "db.create with charset for pgsql",
+			stepName: "db.create",
+			cfg:      StepConfig{Type: "pgsql", Charset: "utf8"},
+			wantErr:  true,
+			errMsg:   "db.create: 'charset' and 'collation' apply to mysql databases, use 'template' for pgsql",
+		},
+		{
+			name:     "db.create with owner for mysql",
+			stepName: "db.create",
+			cfg:      StepConfig{Type: "mysql", Owner: "app"},
+			wantErr:  true,
+			errMsg:   "db.create: 'owner' and 'template' apply to pgsql databases",
+		},
+		{
+			name:     "db.destroy with unknown ssl_mode",
+			stepName: "db.destroy",
+			cfg:      StepConfig{SSLMode: "strict"},
+			wantErr:  true,
+			errMsg:   `db.destroy: 'ssl_mode' must be one of disable, allow, prefer, require, verify-ca, verify-full, got "strict"`,
+		},
+		{
+			name:     "db.create with unknown on_connection_failure",
+			stepName: "db.create",
+			cfg:      StepConfig{OnConnectionFailure: "ignore"},
+			wantErr:  true,
+			errMsg:   `db.create: 'on_connection_failure' must be skip, fail or retry, got "ignore"`,
+		},
 		{
 			name:     "db.destroy with optional fields",
 			stepName: "db.destroy",
@@ -259,6 +356,27 @@ func TestFileCopyConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "file.copy: 'from' is required",
 		},
+		{
+			name: "valid eol",
+			config: FileCopyConfig{
+				BaseStepConfig: BaseStepConfig{Name: "file.copy"},
+				From:           "source.txt",
+				To:             "dest.txt",
+				EOL:            EOLCRLF,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid eol",
+			config: FileCopyConfig{
+				BaseStepConfig: BaseStepConfig{Name: "file.copy"},
+				From:           "source.txt",
+				To:             "dest.txt",
+				EOL:            "windows",
+			},
+			wantErr: true,
+			errMsg:  `file.copy: 'eol' must be auto, keep, lf or crlf, got "windows"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -423,6 +541,25 @@ func TestEnvWriteConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "env.write: 'key' is required",
 		},
+		{
+			name: "valid mode",
+			config: EnvWriteConfig{
+				BaseStepConfig: BaseStepConfig{Name: "env.write"},
+				Key:            "DB_DATABASE",
+				Mode:           EnvWriteModeAppendIfMissing,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown mode",
+			config: EnvWriteConfig{
+				BaseStepConfig: BaseStepConfig{Name: "env.write"},
+				Key:            "DB_DATABASE",
+				Mode:           "replace",
+			},
+			wantErr: true,
+			errMsg:  `env.write: 'mode' must be upsert, update_only or append_if_missing, got "replace"`,
+		},
 	}
 
 	for _, tt := range tests {
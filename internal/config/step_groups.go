@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LocatedStep is a step config and where it is defined, such as
+// scaffold.steps[2], or scaffold.steps[2] > step_groups.frontend[0] for a
+// step included from a group.
+type LocatedStep struct {
+	Step     StepConfig
+	Location string
+	// Group is the outermost step group the step was included from, or ""
+	// for a step listed directly.
+	Group string
+}
+
+// ExpandSteps returns steps, listed at path such as scaffold.steps, with
+// every group reference replaced by the steps of its group. Groups may
+// include other groups. It also returns an error for each invalid
+// reference; the other steps are still returned.
+func (c *Config) ExpandSteps(path string, steps []StepConfig) ([]LocatedStep, []error) {
+	var located []LocatedStep
+	var errs []error
+	for i, step := range steps {
+		expanded, stepErrs := c.expandStep(step, fmt.Sprintf("%s[%d]", path, i), nil)
+		located = append(located, expanded...)
+		errs = append(errs, stepErrs...)
+	}
+	return located, errs
+}
+
+// ExpandCleanupGroup returns the steps of the group a cleanup step at
+// location includes.
+func (c *Config) ExpandCleanupGroup(step CleanupStep, location string) ([]LocatedStep, []error) {
+	reference := step
+	reference.Group, reference.Condition = "", nil
+	if !reflect.DeepEqual(reference, CleanupStep{}) {
+		return nil, errorf("%s (group %s): only condition can be set with group", location, step.Group)
+	}
+	return c.expandStep(StepConfig{Group: step.Group, Condition: step.Condition}, location, nil)
+}
+
+// expandStep expands one step listed at location. seen holds the groups
+// being expanded, outermost first, to catch groups that include
+// themselves.
+func (c *Config) expandStep(step StepConfig, location string, seen []string) ([]LocatedStep, []error) {
+	if step.Group == "" {
+		return []LocatedStep{{Step: step, Location: location}}, nil
+	}
+
+	reference := step
+	reference.Group, reference.Enabled, reference.Condition = "", nil, nil
+	if !reflect.DeepEqual(reference, StepConfig{}) {
+		return nil, errorf("%s (group %s): only enabled and condition can be set with group", location, step.Group)
+	}
+
+	// Viper lowercases map keys, so step_groups names are matched
+	// case-insensitively.
+	name := strings.ToLower(step.Group)
+	for _, outer := range seen {
+		if outer == name {
+			return nil, errorf("%s (group %s): step group includes itself (%s)", location, step.Group, strings.Join(append(seen, name), " > "))
+		}
+	}
+	groupSteps, ok := c.StepGroups[name]
+	if !ok {
+		return nil, errorf("%s (group %s): no such step group in step_groups", location, step.Group)
+	}
+
+	var located []LocatedStep
+	var errs []error
+	for i, groupStep := range groupSteps {
+		groupStep, err := applyGroupReference(groupStep, step)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s > step_groups.%s[%d]: %w", location, name, i, err))
+			continue
+		}
+		expanded, stepErrs := c.expandStep(groupStep, fmt.Sprintf("%s > step_groups.%s[%d]", location, name, i), append(seen, name))
+		for j := range expanded {
+			expanded[j].Group = name
+		}
+		located = append(located, expanded...)
+		errs = append(errs, stepErrs...)
+	}
+	return located, errs
+}
+
+// errorf returns a single formatted error as a list.
+func errorf(format string, args ...interface{}) []error {
+	return []error{fmt.Errorf(format, args...)}
+}
+
+// applyGroupReference returns a step of a group with the enabled flag and
+// condition of the reference that included it: a disabled reference
+// disables the step, and the step runs only when both conditions hold.
+func applyGroupReference(step, reference StepConfig) (StepConfig, error) {
+	if reference.Enabled != nil && !*reference.Enabled {
+		step.Enabled = reference.Enabled
+	}
+	if len(reference.Condition) == 0 {
+		return step, nil
+	}
+
+	condition := make(map[string]interface{}, len(step.Condition)+len(reference.Condition))
+	for key, value := range step.Condition {
+		condition[key] = value
+	}
+	for key, value := range reference.Condition {
+		if existing, ok := condition[key]; ok && !reflect.DeepEqual(existing, value) {
+			return step, fmt.Errorf("condition %s is set both by the step and by the group reference", key)
+		}
+		condition[key] = value
+	}
+	step.Condition = condition
+	return step, nil
+}
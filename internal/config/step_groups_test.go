@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandSteps(t *testing.T) {
+	cfg := &Config{StepGroups: map[string][]StepConfig{
+		"frontend": {{Name: "node.npm", Args: []string{"ci"}}, {Group: "build"}},
+		"build":    {{Name: "node.npm", Args: []string{"run", "build"}}},
+	}}
+
+	located, errs := cfg.ExpandSteps("scaffold.steps", []StepConfig{
+		{Name: "php.composer", Args: []string{"install"}},
+		{Group: "Frontend"},
+	})
+
+	require.Empty(t, errs)
+	assert.Equal(t, []LocatedStep{
+		{Step: StepConfig{Name: "php.composer", Args: []string{"install"}}, Location: "scaffold.steps[0]"},
+		{Step: StepConfig{Name: "node.npm", Args: []string{"ci"}}, Location: "scaffold.steps[1] > step_groups.frontend[0]", Group: "frontend"},
+		{Step: StepConfig{Name: "node.npm", Args: []string{"run", "build"}}, Location: "scaffold.steps[1] > step_groups.frontend[1] > step_groups.build[0]", Group: "frontend"},
+	}, located)
+}
+
+func TestExpandSteps_Reference(t *testing.T) {
+	disabled := false
+	cfg := &Config{StepGroups: map[string][]StepConfig{
+		"assets": {
+			{Name: "node.npm", Args: []string{"ci"}},
+			{Name: "node.npm", Args: []string{"run", "build"}, Condition: map[string]interface{}{"file_exists": "vite.config.js"}},
+		},
+	}}
+
+	located, errs := cfg.ExpandSteps("scaffold.steps", []StepConfig{
+		{Group: "assets", Enabled: &disabled, Condition: map[string]interface{}{"file_exists": "package.json"}},
+	})
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "scaffold.steps[0] > step_groups.assets[1]: condition file_exists is set both by the step and by the group reference")
+	require.Len(t, located, 1)
+	assert.Equal(t, &disabled, located[0].Step.Enabled)
+	assert.Equal(t, map[string]interface{}{"file_exists": "package.json"}, located[0].Step.Condition)
+}
+
+func TestExpandSteps_InvalidReferences(t *testing.T) {
+	cfg := &Config{StepGroups: map[string][]StepConfig{
+		"a": {{Group: "b"}},
+		"b": {{Group: "a"}},
+	}}
+
+	located, errs := cfg.ExpandSteps("ci.steps", []StepConfig{
+		{Name: "bash.run", Command: "make"},
+		{Group: "a"},
+		{Group: "missing"},
+		{Group: "a", Name: "bash.run"},
+	})
+
+	assert.Equal(t, []LocatedStep{{Step: StepConfig{Name: "bash.run", Command: "make"}, Location: "ci.steps[0]"}}, located)
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"ci.steps[1] > step_groups.a[0] > step_groups.b[0] (group a): step group includes itself (a > b > a)",
+		"ci.steps[2] (group missing): no such step group in step_groups",
+		"ci.steps[3] (group a): only enabled and condition can be set with group",
+	}, messages)
+}
+
+func TestExpandCleanupGroup(t *testing.T) {
+	cfg := &Config{StepGroups: map[string][]StepConfig{"sites": {{Name: "herd"}}}}
+
+	located, errs := cfg.ExpandCleanupGroup(CleanupStep{Group: "sites"}, "cleanup.steps[0]")
+	require.Empty(t, errs)
+	assert.Equal(t, []LocatedStep{{Step: StepConfig{Name: "herd"}, Location: "cleanup.steps[0] > step_groups.sites[0]", Group: "sites"}}, located)
+
+	_, errs = cfg.ExpandCleanupGroup(CleanupStep{Group: "sites", Type: "mysql"}, "cleanup.steps[0]")
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "cleanup.steps[0] (group sites): only condition can be set with group")
+}
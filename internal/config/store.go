@@ -0,0 +1,108 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// writes counts config writes made by this package. Store entries remember
+// the count they were read at and are re-read once it moves on, so a value
+// read before SaveProject, WriteLocalState and the like is never served
+// after them.
+var writes atomic.Uint64
+
+// markWritten records that a config file may have changed.
+func markWritten() {
+	writes.Add(1)
+}
+
+// Store memoises config reads for the length of one command, so the
+// project config, the global config and each worktree's .arbor.local are
+// read and parsed once however many callers need them. A nil *Store reads
+// from disk every time.
+//
+// Values returned by a Store are shared; callers must not modify them.
+type Store struct {
+	mu       sync.Mutex
+	projects map[string]storeEntry[*Config]
+	global   *storeEntry[*GlobalConfig]
+	local    map[string]storeEntry[*LocalState]
+}
+
+type storeEntry[T any] struct {
+	value T
+	err   error
+	gen   uint64
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{
+		projects: make(map[string]storeEntry[*Config]),
+		local:    make(map[string]storeEntry[*LocalState]),
+	}
+}
+
+// Reset forgets every read, so long-running commands such as 'arbor serve'
+// see files changed by other processes.
+func (s *Store) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.projects)
+	clear(s.local)
+	s.global = nil
+}
+
+// Project returns the project config in path, as LoadProject would.
+func (s *Store) Project(path string) (*Config, error) {
+	if s == nil {
+		return LoadProject(path)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gen := writes.Load()
+	if entry, ok := s.projects[path]; ok && entry.gen == gen {
+		return entry.value, entry.err
+	}
+	cfg, err := LoadProject(path)
+	s.projects[path] = storeEntry[*Config]{value: cfg, err: err, gen: gen}
+	return cfg, err
+}
+
+// Global returns the global config, as LoadGlobal would.
+func (s *Store) Global() (*GlobalConfig, error) {
+	if s == nil {
+		return LoadGlobal()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gen := writes.Load()
+	if s.global != nil && s.global.gen == gen {
+		return s.global.value, s.global.err
+	}
+	cfg, err := LoadGlobal()
+	s.global = &storeEntry[*GlobalConfig]{value: cfg, err: err, gen: gen}
+	return cfg, err
+}
+
+// LocalState returns a worktree's .arbor.local, as ReadLocalState would.
+func (s *Store) LocalState(worktreePath string) (*LocalState, error) {
+	if s == nil {
+		return ReadLocalState(worktreePath)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gen := writes.Load()
+	if entry, ok := s.local[worktreePath]; ok && entry.gen == gen {
+		return entry.value, entry.err
+	}
+	state, err := ReadLocalState(worktreePath)
+	s.local[worktreePath] = storeEntry[*LocalState]{value: state, err: err, gen: gen}
+	return state, err
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_LocalState(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, ".arbor.local")
+	require.NoError(t, os.WriteFile(localPath, []byte("db_suffix: sunset\n"), 0644))
+
+	store := NewStore()
+	state, err := store.LocalState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "sunset", state.DbSuffix)
+
+	// Changes made behind the store's back are not seen within a command.
+	require.NoError(t, os.WriteFile(localPath, []byte("db_suffix: dawn\n"), 0644))
+	state, err = store.LocalState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "sunset", state.DbSuffix)
+
+	// Writes through the config package are.
+	require.NoError(t, WriteLocalState(dir, LocalState{DbSuffix: "noon"}))
+	state, err = store.LocalState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "noon", state.DbSuffix)
+}
+
+func TestStore_Project(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "arbor.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("preset: laravel\n"), 0644))
+
+	store := NewStore()
+	cfg, err := store.Project(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "laravel", cfg.Preset)
+
+	again, err := store.Project(dir)
+	require.NoError(t, err)
+	assert.Same(t, cfg, again)
+
+	cfg.Preset = "php"
+	require.NoError(t, SaveProject(dir, cfg))
+	reloaded, err := store.Project(dir)
+	require.NoError(t, err)
+	assert.NotSame(t, cfg, reloaded)
+	assert.Equal(t, "php", reloaded.Preset)
+
+	_, err = store.Project(t.TempDir())
+	assert.ErrorContains(t, err, "arbor.yaml not found")
+}
+
+func TestStore_Nil(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, ".arbor.local")
+
+	var store *Store
+	require.NoError(t, os.WriteFile(localPath, []byte("db_suffix: sunset\n"), 0644))
+	state, err := store.LocalState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "sunset", state.DbSuffix)
+
+	require.NoError(t, os.WriteFile(localPath, []byte("db_suffix: dawn\n"), 0644))
+	state, err = store.LocalState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "dawn", state.DbSuffix)
+}
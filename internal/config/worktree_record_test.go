@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWorktreeRecords_RoundTrip(t *testing.T) {
+	projectPath := t.TempDir()
+
+	records := []WorktreeRecord{
+		{Path: filepath.Join(projectPath, "feature-b"), Branch: "feature/b", DbSuffix: "sunset", DbConnection: "mysql", Preset: "laravel", SiteName: "feature-b"},
+		{Path: filepath.Join(projectPath, "feature-a"), Branch: "feature/a"},
+	}
+	for _, r := range records {
+		if err := WriteWorktreeRecord(projectPath, r); err != nil {
+			t.Fatalf("unexpected error writing record: %v", err)
+		}
+	}
+
+	got, err := ReadWorktreeRecords(projectPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(got))
+	}
+	if got[0] != records[1] || got[1] != records[0] {
+		t.Errorf("expected records sorted by path, got %+v", got)
+	}
+
+	if err := RemoveWorktreeRecord(projectPath, records[0].Path); err != nil {
+		t.Fatalf("unexpected error removing record: %v", err)
+	}
+	if err := RemoveWorktreeRecord(projectPath, records[0].Path); err != nil {
+		t.Errorf("removing a missing record should not fail, got: %v", err)
+	}
+
+	got, err = ReadWorktreeRecords(projectPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Branch != "feature/a" {
+		t.Errorf("expected only feature/a to remain, got %+v", got)
+	}
+}
+
+func TestReadWorktreeRecords_MissingDir(t *testing.T) {
+	records, err := ReadWorktreeRecords(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error for missing directory, got: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestReadWorktreeRecords_InvalidFile(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(WorktreeRecordsDir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(WorktreeRecordsDir(projectPath), "broken.yaml"), []byte("path: [unclosed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadWorktreeRecords(projectPath); err == nil {
+		t.Error("expected error for invalid record")
+	}
+}
+
+func TestMoveWorktreeRecord(t *testing.T) {
+	projectPath := t.TempDir()
+	oldPath := filepath.Join(projectPath, "feature-a")
+	newPath := filepath.Join(t.TempDir(), "feature-b")
+
+	if err := WriteWorktreeRecord(projectPath, WorktreeRecord{Path: oldPath, Branch: "feature/a", DbSuffix: "swift_runner"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := MoveWorktreeRecord(projectPath, oldPath, newPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := ReadWorktreeRecords(projectPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Path != newPath || records[0].DbSuffix != "swift_runner" {
+		t.Errorf("expected record moved to %s with its state, got %+v", newPath, records[0])
+	}
+}
+
+func TestMoveWorktreeRecord_Missing(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := MoveWorktreeRecord(projectPath, filepath.Join(projectPath, "a"), filepath.Join(projectPath, "b")); err != nil {
+		t.Errorf("expected no error for a missing record, got: %v", err)
+	}
+}
+
+func TestReadWorktreeRecord(t *testing.T) {
+	projectPath := t.TempDir()
+	worktreePath := filepath.Join(projectPath, "feature-a")
+
+	record, err := ReadWorktreeRecord(projectPath, worktreePath)
+	if err != nil || record != nil {
+		t.Fatalf("expected no record, got %+v, %v", record, err)
+	}
+
+	report := &ScaffoldReport{
+		Time:       time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		DurationMs: 1200,
+		Steps: []ScaffoldStepReport{
+			{Name: "php.composer", Status: "completed", DurationMs: 1100},
+			{Name: "herd", Status: "skipped", Reason: "condition not met"},
+		},
+	}
+	if err := WriteWorktreeRecord(projectPath, WorktreeRecord{Path: worktreePath, Branch: "feature/a", LastScaffold: report}); err != nil {
+		t.Fatalf("unexpected error writing record: %v", err)
+	}
+
+	record, err = ReadWorktreeRecord(projectPath, worktreePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record == nil || !reflect.DeepEqual(record.LastScaffold, report) {
+		t.Errorf("expected the scaffold report to round-trip, got %+v", record)
+	}
+
+	// A record for another worktree with the same folder name is not returned
+	record, err = ReadWorktreeRecord(projectPath, filepath.Join(projectPath, "elsewhere", "feature-a"))
+	if err != nil || record != nil {
+		t.Errorf("expected no record for another path, got %+v, %v", record, err)
+	}
+}
@@ -45,6 +45,11 @@ func NewCommandExecutor(commander Commander) *CommandExecutor {
 	return &CommandExecutor{commander: commander}
 }
 
+// Commander returns the Commander the executor runs commands with.
+func (e *CommandExecutor) Commander() Commander {
+	return e.commander
+}
+
 // RunBinary executes a binary command with arguments.
 // The binary can contain spaces (e.g., "php artisan") and will be properly split.
 func (e *CommandExecutor) RunBinary(ctx context.Context, dir string, binary string, args []string) ([]byte, error) {
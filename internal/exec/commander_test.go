@@ -3,6 +3,7 @@ package exec
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -208,3 +209,46 @@ func TestMockCommander_ErrorResponse(t *testing.T) {
 		t.Errorf("expected 'error output', got: %s", string(output))
 	}
 }
+
+func TestCommandExecutor_RunWithShell(t *testing.T) {
+	tests := []struct {
+		shell    string
+		wantArgs []string
+	}{
+		{"bash", []string{"-c", "echo 'a b' | wc -c"}},
+		{"zsh", []string{"-c", "echo 'a b' | wc -c"}},
+		{"sh", []string{"-c", "echo 'a b' | wc -c"}},
+		{"pwsh", []string{"-NoProfile", "-NonInteractive", "-Command", "echo 'a b' | wc -c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			mock := NewMockCommander()
+			executor := NewCommandExecutor(mock)
+
+			if _, err := executor.RunWithShell(context.Background(), "/worktree", tt.shell, "echo 'a b' | wc -c"); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			call := mock.LastCall()
+			if call.Command != tt.shell {
+				t.Errorf("expected command %q, got %q", tt.shell, call.Command)
+			}
+			if strings.Join(call.Args, "\x00") != strings.Join(tt.wantArgs, "\x00") {
+				t.Errorf("expected args %q, got %q", tt.wantArgs, call.Args)
+			}
+		})
+	}
+
+	t.Run("unknown shell", func(t *testing.T) {
+		mock := NewMockCommander()
+		executor := NewCommandExecutor(mock)
+
+		if _, err := executor.RunWithShell(context.Background(), "/worktree", "fish", "ls"); err == nil {
+			t.Error("expected error for unsupported shell, got nil")
+		}
+		if mock.CallCount() != 0 {
+			t.Errorf("expected no calls, got %d", mock.CallCount())
+		}
+	})
+}
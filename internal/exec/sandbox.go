@@ -0,0 +1,198 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Isolation levels a SandboxCommander can provide, from strongest to
+// weakest. Every level filters the environment.
+const (
+	// IsolationBubblewrap runs commands under bwrap: the file system is
+	// read-only outside the sandbox root and the network is unshared.
+	IsolationBubblewrap = "bwrap"
+	// IsolationSandboxExec runs commands under macOS sandbox-exec with a
+	// profile that denies network access and writes outside the root.
+	IsolationSandboxExec = "sandbox-exec"
+	// IsolationUnshare runs commands in a new network namespace only;
+	// writes are not confined.
+	IsolationUnshare = "unshare"
+	// IsolationEnv only filters the environment.
+	IsolationEnv = "env"
+)
+
+// DefaultSandboxEnv lists the environment variables a sandboxed command
+// always receives. Sandbox.AllowEnv adds to it.
+var DefaultSandboxEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "TERM", "TMPDIR"}
+
+// Sandbox describes the restrictions a SandboxCommander applies.
+type Sandbox struct {
+	// Root is the directory commands may write to, usually the worktree.
+	Root string
+	// Writable lists further directories commands may write to, such as
+	// a package manager cache.
+	Writable []string
+	// AllowEnv names environment variables passed through in addition to
+	// DefaultSandboxEnv. Names may use path.Match patterns, e.g. COMPOSER_*.
+	AllowEnv []string
+	// Network allows network access.
+	Network bool
+}
+
+// SandboxCommander runs commands through another Commander with a
+// filtered environment, confined to the sandbox root and without network
+// access where the platform has a tool for it: bwrap or unshare on Linux
+// and sandbox-exec on macOS.
+type SandboxCommander struct {
+	inner     Commander
+	sandbox   Sandbox
+	isolation string
+	environ   func() []string
+}
+
+// NewSandboxCommander returns a SandboxCommander that runs commands
+// through inner, or a RealCommander when inner is nil. The isolation tool
+// is chosen once, from the tools found on PATH.
+func NewSandboxCommander(inner Commander, sandbox Sandbox) *SandboxCommander {
+	return newSandboxCommander(inner, sandbox, runtime.GOOS, exec.LookPath, os.Environ)
+}
+
+func newSandboxCommander(inner Commander, sandbox Sandbox, goos string, lookPath func(string) (string, error), environ func() []string) *SandboxCommander {
+	if inner == nil {
+		inner = &RealCommander{}
+	}
+	return &SandboxCommander{
+		inner:     inner,
+		sandbox:   sandbox,
+		isolation: detectIsolation(goos, sandbox, lookPath),
+		environ:   environ,
+	}
+}
+
+// detectIsolation returns the strongest isolation the platform offers.
+// Windows has none: it cannot filter the environment through env either.
+func detectIsolation(goos string, sandbox Sandbox, lookPath func(string) (string, error)) string {
+	has := func(tool string) bool {
+		_, err := lookPath(tool)
+		return err == nil
+	}
+	switch goos {
+	case "windows":
+		return ""
+	case "linux":
+		if has("bwrap") {
+			return IsolationBubblewrap
+		}
+		if !sandbox.Network && has("unshare") {
+			return IsolationUnshare
+		}
+	case "darwin":
+		if has("sandbox-exec") {
+			return IsolationSandboxExec
+		}
+	}
+	return IsolationEnv
+}
+
+// Isolation returns the isolation level commands run with, one of the
+// Isolation constants, or "" when sandboxing is unsupported.
+func (c *SandboxCommander) Isolation() string {
+	return c.isolation
+}
+
+// Confined reports whether writes outside the sandbox root are blocked.
+func (c *SandboxCommander) Confined() bool {
+	return c.isolation == IsolationBubblewrap || c.isolation == IsolationSandboxExec
+}
+
+// Run runs the command with the filtered environment inside the sandbox.
+func (c *SandboxCommander) Run(ctx context.Context, dir string, command string, args ...string) ([]byte, error) {
+	if c.isolation == "" {
+		return nil, fmt.Errorf("sandbox is not supported on this platform")
+	}
+
+	wrapped := append(c.isolationArgs(dir), command)
+	wrapped = append(wrapped, args...)
+
+	envArgs := append([]string{"-i"}, c.filteredEnv()...)
+	return c.inner.Run(ctx, dir, "env", append(envArgs, wrapped...)...)
+}
+
+// filteredEnv returns the NAME=value pairs of the current environment the
+// sandbox passes through, sorted by name.
+func (c *SandboxCommander) filteredEnv() []string {
+	var env []string
+	for _, entry := range c.environ() {
+		name, _, ok := strings.Cut(entry, "=")
+		if ok && c.allowsEnv(name) {
+			env = append(env, entry)
+		}
+	}
+	slices.Sort(env)
+	return env
+}
+
+func (c *SandboxCommander) allowsEnv(name string) bool {
+	if slices.Contains(DefaultSandboxEnv, name) {
+		return true
+	}
+	for _, pattern := range c.sandbox.AllowEnv {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isolationArgs returns the command line prefix that runs a command in
+// dir under the chosen isolation tool.
+func (c *SandboxCommander) isolationArgs(dir string) []string {
+	switch c.isolation {
+	case IsolationBubblewrap:
+		args := []string{"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+		for _, writable := range c.sandbox.Writable {
+			args = append(args, "--bind-try", writable, writable)
+		}
+		args = append(args, "--bind", c.sandbox.Root, c.sandbox.Root)
+		if !c.sandbox.Network {
+			args = append(args, "--unshare-net")
+		}
+		return append(args, "--die-with-parent", "--chdir", dir)
+	case IsolationSandboxExec:
+		return []string{"sandbox-exec", "-p", c.sandboxProfile()}
+	case IsolationUnshare:
+		return []string{"unshare", "--net", "--map-root-user"}
+	}
+	return nil
+}
+
+// sandboxProfile returns the sandbox-exec profile for the sandbox. Temp
+// directories and /dev stay writable so common tools keep working.
+func (c *SandboxCommander) sandboxProfile() string {
+	var b strings.Builder
+	b.WriteString("(version 1)\n(allow default)\n")
+	if !c.sandbox.Network {
+		b.WriteString("(deny network*)\n")
+	}
+	b.WriteString("(deny file-write*)\n(allow file-write*")
+	writable := append([]string{c.sandbox.Root}, c.sandbox.Writable...)
+	writable = append(writable, "/private/tmp", "/private/var/folders", "/dev")
+	for _, dir := range writable {
+		// sandbox-exec matches resolved paths, so /tmp must be given as
+		// /private/tmp.
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		fmt.Fprintf(&b, " (subpath %s)", strconv.Quote(dir))
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookPathFor(tools ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, tool := range tools {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestDetectIsolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		tools   []string
+		network bool
+		want    string
+	}{
+		{"linux with bwrap", "linux", []string{"bwrap", "unshare"}, false, IsolationBubblewrap},
+		{"linux with unshare only", "linux", []string{"unshare"}, false, IsolationUnshare},
+		{"linux unshare is pointless with network", "linux", []string{"unshare"}, true, IsolationEnv},
+		{"linux without tools", "linux", nil, false, IsolationEnv},
+		{"macos with sandbox-exec", "darwin", []string{"sandbox-exec"}, false, IsolationSandboxExec},
+		{"other unix", "freebsd", []string{"bwrap"}, false, IsolationEnv},
+		{"windows", "windows", []string{"bwrap"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectIsolation(tt.goos, Sandbox{Network: tt.network}, lookPathFor(tt.tools...))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSandboxCommander_Run(t *testing.T) {
+	environ := func() []string {
+		return []string{"PATH=/usr/bin", "HOME=/home/dev", "AWS_SECRET_ACCESS_KEY=secret", "COMPOSER_HOME=/home/dev/.composer", "EMPTY="}
+	}
+	sandbox := Sandbox{
+		Root:     "/work/feature",
+		Writable: []string{"/home/dev/.cache"},
+		AllowEnv: []string{"COMPOSER_*", "EMPTY"},
+	}
+
+	t.Run("bwrap confines writes and unshares the network", func(t *testing.T) {
+		mock := NewMockCommander()
+		c := newSandboxCommander(mock, sandbox, "linux", lookPathFor("bwrap"), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "bash", "-c", "make")
+		require.NoError(t, err)
+		assert.True(t, c.Confined())
+
+		call := mock.LastCall()
+		require.NotNil(t, call)
+		assert.Equal(t, "/work/feature", call.Dir)
+		assert.Equal(t, "env", call.Command)
+		assert.Equal(t, "-i COMPOSER_HOME=/home/dev/.composer EMPTY= HOME=/home/dev PATH=/usr/bin "+
+			"bwrap --ro-bind / / --dev /dev --proc /proc --tmpfs /tmp --bind-try /home/dev/.cache /home/dev/.cache "+
+			"--bind /work/feature /work/feature --unshare-net --die-with-parent --chdir /work/feature bash -c make",
+			strings.Join(call.Args, " "))
+	})
+
+	t.Run("bwrap keeps the network when allowed", func(t *testing.T) {
+		mock := NewMockCommander()
+		allowed := sandbox
+		allowed.Network = true
+		c := newSandboxCommander(mock, allowed, "linux", lookPathFor("bwrap"), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "composer", "install")
+		require.NoError(t, err)
+		assert.NotContains(t, mock.LastCall().Args, "--unshare-net")
+	})
+
+	t.Run("unshare only blocks the network", func(t *testing.T) {
+		mock := NewMockCommander()
+		c := newSandboxCommander(mock, sandbox, "linux", lookPathFor("unshare"), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "php", "artisan", "migrate")
+		require.NoError(t, err)
+		assert.False(t, c.Confined())
+		assert.Contains(t, strings.Join(mock.LastCall().Args, " "), "PATH=/usr/bin unshare --net --map-root-user php artisan migrate")
+	})
+
+	t.Run("sandbox-exec denies network and writes outside the root", func(t *testing.T) {
+		mock := NewMockCommander()
+		c := newSandboxCommander(mock, sandbox, "darwin", lookPathFor("sandbox-exec"), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "sh", "-c", "make")
+		require.NoError(t, err)
+		assert.True(t, c.Confined())
+
+		args := mock.LastCall().Args
+		i := indexOf(args, "sandbox-exec")
+		require.GreaterOrEqual(t, i, 0)
+		profile := args[i+2]
+		assert.Contains(t, profile, "(deny network*)")
+		assert.Contains(t, profile, "(deny file-write*)")
+		assert.Contains(t, profile, `(subpath "/work/feature")`)
+		assert.Contains(t, profile, `(subpath "/home/dev/.cache")`)
+		assert.Equal(t, []string{"sh", "-c", "make"}, args[i+3:])
+	})
+
+	t.Run("without tools only the environment is filtered", func(t *testing.T) {
+		mock := NewMockCommander()
+		c := newSandboxCommander(mock, sandbox, "linux", lookPathFor(), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "npm", "ci")
+		require.NoError(t, err)
+		assert.Equal(t, IsolationEnv, c.Isolation())
+		assert.NotContains(t, strings.Join(mock.LastCall().Args, " "), "AWS_SECRET_ACCESS_KEY")
+		assert.Equal(t, []string{"npm", "ci"}, mock.LastCall().Args[len(mock.LastCall().Args)-2:])
+	})
+
+	t.Run("windows is unsupported", func(t *testing.T) {
+		mock := NewMockCommander()
+		c := newSandboxCommander(mock, sandbox, "windows", lookPathFor(), environ)
+
+		_, err := c.Run(context.Background(), "/work/feature", "npm", "ci")
+		require.Error(t, err)
+		assert.Equal(t, 0, mock.CallCount())
+	})
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,29 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+)
+
+// ShellArgs returns the arguments that make shell run command. The command
+// is passed as a single argument, so it reaches the shell unchanged on
+// every platform: os/exec quotes it for Windows command lines.
+func ShellArgs(shell, command string) ([]string, error) {
+	switch shell {
+	case "bash", "zsh", "sh":
+		return []string{"-c", command}, nil
+	case "pwsh":
+		return []string{"-NoProfile", "-NonInteractive", "-Command", command}, nil
+	}
+	return nil, fmt.Errorf("unsupported shell %q", shell)
+}
+
+// RunWithShell executes a command through the named shell: bash, zsh, sh
+// or pwsh.
+func (e *CommandExecutor) RunWithShell(ctx context.Context, dir string, shell string, command string) ([]byte, error) {
+	args, err := ShellArgs(shell, command)
+	if err != nil {
+		return nil, err
+	}
+	return e.commander.Run(ctx, dir, shell, args...)
+}
@@ -0,0 +1,164 @@
+// Package ghactions reports scaffold runs to GitHub Actions jobs: a job
+// summary with the step table, and step outputs for later workflow steps.
+// Log groups and error annotations come from ui.GitHubRenderer.
+package ghactions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+)
+
+// Enabled reports whether arbor is running in a GitHub Actions job.
+func Enabled(getenv func(string) string) bool {
+	return getenv("GITHUB_ACTIONS") == "true"
+}
+
+// StepResult is a step's outcome, for the job summary.
+type StepResult struct {
+	Name     string
+	Status   string
+	Reason   string
+	Error    string
+	Duration time.Duration
+}
+
+// Recorder collects step results and durations from scaffold progress.
+type Recorder struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	started map[string]time.Time
+	results []StepResult
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{now: time.Now, started: make(map[string]time.Time)}
+}
+
+// Progress is a scaffold progress callback.
+func (r *Recorder) Progress(ev scaffold.StepEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := StepResult{Name: ev.Step.Name(), Status: ev.Status, Reason: ev.Reason}
+	switch ev.Status {
+	case scaffold.StepStarted:
+		r.started[ev.ID] = r.now()
+		return
+	case scaffold.StepCompleted, scaffold.StepFailed:
+		result.Duration = r.now().Sub(r.started[ev.ID])
+		if ev.Error != nil {
+			result.Error = ev.Error.Error()
+		}
+	}
+	r.results = append(r.results, result)
+}
+
+// Results returns the outcomes of the steps recorded so far.
+func (r *Recorder) Results() []StepResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]StepResult(nil), r.results...)
+}
+
+// WriteSummary writes a markdown section headed title with a table of the
+// recorded steps, for $GITHUB_STEP_SUMMARY.
+func (r *Recorder) WriteSummary(w io.Writer, title string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	b.WriteString("| Step | Status | Duration |\n")
+	b.WriteString("| --- | --- | --- |\n")
+
+	var total time.Duration
+	for _, result := range r.Results() {
+		status := result.Status
+		switch {
+		case result.Reason != "":
+			status += " (" + result.Reason + ")"
+		case result.Error != "":
+			status += ": " + firstLine(result.Error)
+		}
+		duration := ""
+		if result.Status != scaffold.StepSkipped {
+			duration = result.Duration.Round(time.Millisecond).String()
+			total += result.Duration
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", escapeCell(stepLabel(result.Name)), escapeCell(status), duration)
+	}
+	fmt.Fprintf(&b, "\nTotal: %s\n\n", total.Round(time.Millisecond))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteOutputs writes outputs in the $GITHUB_OUTPUT format, sorted by
+// name. Values are written with a random delimiter, so they may span lines.
+func WriteOutputs(w io.Writer, outputs map[string]string) error {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		delimiter, err := newDelimiter()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", name, delimiter, outputs[name], delimiter)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// AppendToFile calls write with path opened for appending, as GitHub
+// expects for $GITHUB_STEP_SUMMARY and $GITHUB_OUTPUT.
+func AppendToFile(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func newDelimiter() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating output delimiter: %w", err)
+	}
+	return "ARBOR_" + hex.EncodeToString(buf), nil
+}
+
+// stepLabel names a step in the summary: its description, when it
+// has one, followed by its name.
+func stepLabel(name string) string {
+	if desc := scaffold.StepDescription(name); desc != "" {
+		return fmt.Sprintf("%s (%s)", desc, name)
+	}
+	return name
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// escapeCell keeps text from breaking a markdown table row.
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,78 @@
+package ghactions
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+type namedStep struct{ name string }
+
+func (s namedStep) Name() string                                        { return s.name }
+func (s namedStep) Run(*types.ScaffoldContext, types.StepOptions) error { return nil }
+func (s namedStep) Condition(*types.ScaffoldContext) bool               { return true }
+
+func TestEnabled(t *testing.T) {
+	env := map[string]string{"GITHUB_ACTIONS": "true"}
+	assert.True(t, Enabled(func(k string) string { return env[k] }))
+	assert.False(t, Enabled(func(string) string { return "" }))
+}
+
+func TestRecorder_WriteSummary(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRecorder()
+	r.now = func() time.Time { return clock }
+
+	r.Progress(scaffold.StepEvent{ID: "0", Step: namedStep{"one"}, Status: scaffold.StepStarted})
+	clock = clock.Add(1500 * time.Millisecond)
+	r.Progress(scaffold.StepEvent{ID: "0", Step: namedStep{"one"}, Status: scaffold.StepCompleted})
+	r.Progress(scaffold.StepEvent{ID: "1", Step: namedStep{"two"}, Status: scaffold.StepSkipped, Reason: "condition not met"})
+	r.Progress(scaffold.StepEvent{ID: "2", Step: namedStep{"three"}, Status: scaffold.StepStarted})
+	clock = clock.Add(250 * time.Millisecond)
+	r.Progress(scaffold.StepEvent{ID: "2", Step: namedStep{"three"}, Status: scaffold.StepFailed, Error: errors.New("exit 1 | bad\ndetails")})
+
+	var b strings.Builder
+	require.NoError(t, r.WriteSummary(&b, "arbor scaffold: feature"))
+
+	assert.Contains(t, b.String(), "### arbor scaffold: feature\n")
+	assert.Contains(t, b.String(), "| one | completed | 1.5s |\n")
+	assert.Contains(t, b.String(), "| two | skipped (condition not met) |  |\n")
+	assert.Contains(t, b.String(), "| three | failed: exit 1 \\| bad | 250ms |\n")
+	assert.Contains(t, b.String(), "Total: 1.75s\n")
+}
+
+func TestWriteOutputs(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, WriteOutputs(&b, map[string]string{"site_url": "https://app.test", "db_name": "app_feature"}))
+
+	re := regexp.MustCompile(`^db_name<<(ARBOR_[0-9a-f]+)\napp_feature\n(ARBOR_[0-9a-f]+)\nsite_url<<(ARBOR_[0-9a-f]+)\nhttps://app.test\n(ARBOR_[0-9a-f]+)\n$`)
+	m := re.FindStringSubmatch(b.String())
+	require.NotNil(t, m, b.String())
+	assert.Equal(t, m[1], m[2])
+	assert.Equal(t, m[3], m[4])
+}
+
+func TestAppendToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("before\n"), 0644))
+
+	require.NoError(t, AppendToFile(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "after\n")
+		return err
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "before\nafter\n", string(data))
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CheckoutLineEnding returns the line ending, "lf" or "crlf", git gives
+// the text of relativePath when checking it out in the worktree at
+// worktreePath. It follows the path's text and eol attributes, then
+// core.autocrlf and core.eol, and returns "" when git leaves the line
+// endings as they are. Whether a text=auto file is text is left to the
+// caller.
+func CheckoutLineEnding(worktreePath, relativePath string) (string, error) {
+	output, err := exec.Command("git", "-C", worktreePath, "check-attr", "-z", "text", "eol", "--", relativePath).Output()
+	if err != nil {
+		return "", fmt.Errorf("reading attributes of %s: %w", relativePath, err)
+	}
+	// -z prints path, attribute and value, each followed by a NUL
+	attrs := make(map[string]string)
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		attrs[fields[i+1]] = fields[i+2]
+	}
+
+	text, eol := attrs["text"], attrs["eol"]
+	if text == "unset" {
+		return "", nil
+	}
+	if eol == "lf" || eol == "crlf" {
+		return eol, nil
+	}
+
+	autocrlf := strings.ToLower(worktreeConfigGet(worktreePath, "core.autocrlf"))
+	switch autocrlf {
+	case "true", "yes", "on", "1":
+		return "crlf", nil
+	}
+	if text == "unspecified" || text == "" {
+		return "", nil
+	}
+	if autocrlf == "input" {
+		return "lf", nil
+	}
+	switch strings.ToLower(worktreeConfigGet(worktreePath, "core.eol")) {
+	case "lf":
+		return "lf", nil
+	case "crlf":
+		return "crlf", nil
+	}
+	if runtime.GOOS == "windows" {
+		return "crlf", nil
+	}
+	return "lf", nil
+}
+
+// worktreeConfigGet reads key as git sees it in the worktree at
+// worktreePath; it returns "" when the key is unset.
+func worktreeConfigGet(worktreePath, key string) string {
+	output, err := exec.Command("git", "-C", worktreePath, "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckoutLineEnding(t *testing.T) {
+	native := "lf"
+	if runtime.GOOS == "windows" {
+		native = "crlf"
+	}
+
+	tests := []struct {
+		name       string
+		attributes string
+		autocrlf   string
+		eol        string
+		path       string
+		want       string
+	}{
+		{name: "no attributes", autocrlf: "false", path: ".env", want: ""},
+		{name: "autocrlf", autocrlf: "true", path: ".env", want: "crlf"},
+		{name: "autocrlf input", autocrlf: "input", path: ".env", want: ""},
+		{name: "eol attribute", attributes: "*.cmd text eol=crlf\n", autocrlf: "false", path: "bin/run.cmd", want: "crlf"},
+		{name: "eol attribute beats autocrlf", attributes: ".env text eol=lf\n", autocrlf: "true", path: ".env", want: "lf"},
+		{name: "binary", attributes: "*.png binary\n", autocrlf: "true", path: "logo.png", want: ""},
+		{name: "text with core.eol", attributes: "* text=auto\n", autocrlf: "false", eol: "crlf", path: ".env", want: "crlf"},
+		{name: "text with autocrlf input", attributes: "* text=auto\n", autocrlf: "input", path: ".env", want: "lf"},
+		{name: "text native", attributes: "* text\n", autocrlf: "false", path: ".env", want: native},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if output, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+				t.Fatalf("failed to init git repo: %v\n%s", err, output)
+			}
+			gitConfig := func(key, value string) {
+				if output, err := exec.Command("git", "-C", dir, "config", key, value).CombinedOutput(); err != nil {
+					t.Fatalf("failed to set %s: %v\n%s", key, err, output)
+				}
+			}
+			gitConfig("core.autocrlf", tt.autocrlf)
+			if tt.eol != "" {
+				gitConfig("core.eol", tt.eol)
+			}
+			if tt.attributes != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(tt.attributes), 0644); err != nil {
+					t.Fatalf("failed to write .gitattributes: %v", err)
+				}
+			}
+
+			got, err := CheckoutLineEnding(dir, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckoutLineEnding(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := CheckoutLineEnding(t.TempDir(), ".env"); err == nil {
+		t.Error("expected an error outside a repository")
+	}
+}
@@ -0,0 +1,197 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Batch answers read-only questions about one repository while starting as
+// few git processes as possible, which matters on Windows and network
+// filesystems where each process costs tens of milliseconds. Refs are read
+// with a single for-each-ref, other revisions go through one long-running
+// 'git cat-file --batch-check', and ancestry answers are cached by commit
+// hash, which never goes stale.
+//
+// A Batch is meant to live for one command. It is safe for concurrent use;
+// call Close when done to stop the cat-file process.
+type Batch struct {
+	repoPath string
+
+	refsOnce sync.Once
+	refs     map[string]string
+	refsErr  error
+
+	catMu  sync.Mutex
+	cat    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+
+	ancestorsMu   sync.Mutex
+	ancestors     map[[2]string]bool
+	mergeBaseRuns int
+}
+
+// NewBatch returns a Batch for the repository at repoPath. No git process
+// is started until the first query.
+func NewBatch(repoPath string) *Batch {
+	return &Batch{
+		repoPath:  repoPath,
+		ancestors: make(map[[2]string]bool),
+	}
+}
+
+// refPrefixes is the order git itself tries when a short name matches
+// several refs, so Resolve agrees with rev-parse.
+var refPrefixes = []string{"", "refs/", "refs/tags/", "refs/heads/", "refs/remotes/"}
+
+// Resolve returns the commit hash that ref points at. Branches, remote
+// branches and tags come from the ref table; anything else is resolved by
+// the cat-file process.
+func (b *Batch) Resolve(ref string) (string, error) {
+	if ref == "" || strings.ContainsAny(ref, "\n\r") {
+		return "", fmt.Errorf("resolving %q: not a branch, tag or commit", ref)
+	}
+
+	refs, err := b.loadRefs()
+	if err != nil {
+		return "", err
+	}
+	for _, prefix := range refPrefixes {
+		if sha, ok := refs[prefix+ref]; ok {
+			return sha, nil
+		}
+	}
+
+	return b.catFile(ref + "^{commit}")
+}
+
+// IsAncestor reports whether ancestor is reachable from commit, like
+// IsMerged. Answers are cached by the pair of commit hashes.
+func (b *Batch) IsAncestor(ancestor, commit string) (bool, error) {
+	from, err := b.Resolve(ancestor)
+	if err != nil {
+		return false, err
+	}
+	to, err := b.Resolve(commit)
+	if err != nil {
+		return false, err
+	}
+	if from == to {
+		return true, nil
+	}
+
+	key := [2]string{from, to}
+	b.ancestorsMu.Lock()
+	answer, ok := b.ancestors[key]
+	b.ancestorsMu.Unlock()
+	if ok {
+		return answer, nil
+	}
+
+	answer, err = IsMerged(b.repoPath, from, to)
+	if err != nil {
+		return false, err
+	}
+
+	b.ancestorsMu.Lock()
+	b.ancestors[key] = answer
+	b.mergeBaseRuns++
+	b.ancestorsMu.Unlock()
+	return answer, nil
+}
+
+// Close stops the cat-file process, if one was started.
+func (b *Batch) Close() error {
+	b.catMu.Lock()
+	defer b.catMu.Unlock()
+
+	if b.cat == nil {
+		return nil
+	}
+	_ = b.stdin.Close()
+	err := b.cat.Wait()
+	b.cat = nil
+	if err != nil {
+		return fmt.Errorf("git cat-file: %w", err)
+	}
+	return nil
+}
+
+// loadRefs reads every branch, remote branch and tag with one for-each-ref.
+// Annotated tags map to the commit they point at.
+func (b *Batch) loadRefs() (map[string]string, error) {
+	b.refsOnce.Do(func() {
+		cmd := exec.Command("git", "-C", b.repoPath, "for-each-ref",
+			"--format=%(objectname) %(*objectname) %(refname)",
+			"refs/heads/", "refs/remotes/", "refs/tags/")
+		output, err := cmd.Output()
+		if err != nil {
+			b.refsErr = fmt.Errorf("listing refs: %w", err)
+			return
+		}
+
+		b.refs = make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			fields := strings.Fields(line)
+			switch len(fields) {
+			case 2:
+				b.refs[fields[1]] = fields[0]
+			case 3:
+				b.refs[fields[2]] = fields[1]
+			}
+		}
+	})
+	return b.refs, b.refsErr
+}
+
+// catFile asks the cat-file process for the object rev names, starting the
+// process on first use.
+func (b *Batch) catFile(rev string) (string, error) {
+	b.catMu.Lock()
+	defer b.catMu.Unlock()
+
+	if b.cat == nil {
+		if err := b.startCatFile(); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintln(b.stdin, rev); err != nil {
+		return "", fmt.Errorf("git cat-file: %w", err)
+	}
+	line, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("git cat-file: %w", err)
+	}
+	line = strings.TrimSpace(line)
+
+	sha, kind, ok := strings.Cut(line, " ")
+	if !ok || kind != "commit" {
+		return "", fmt.Errorf("resolving %q: not a branch, tag or commit", strings.TrimSuffix(rev, "^{commit}"))
+	}
+	return sha, nil
+}
+
+func (b *Batch) startCatFile() error {
+	cmd := exec.Command("git", "-C", b.repoPath, "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("starting git cat-file: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("starting git cat-file: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting git cat-file: %w", err)
+	}
+
+	b.cat = cmd
+	b.stdin = stdin
+	b.stdout = bufio.NewReader(stdout)
+	return nil
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBatchRepo(t *testing.T) string {
+	t.Helper()
+
+	barePath, _ := createTestRepo(t)
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test User", "-c", "user.email=test@test.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	featurePath := filepath.Join(filepath.Dir(barePath), "feature")
+	require.NoError(t, CreateWorktree(barePath, featurePath, "feature", "main"))
+	run(featurePath, "commit", "--allow-empty", "-m", "feature")
+	run(barePath, "branch", "fresh", "main")
+	run(barePath, "tag", "-a", "v1", "-m", "v1", "feature")
+
+	return barePath
+}
+
+func TestBatch_Resolve(t *testing.T) {
+	barePath := setupBatchRepo(t)
+	batch := NewBatch(barePath)
+	defer func() { assert.NoError(t, batch.Close()) }()
+
+	for _, ref := range []string{"main", "feature", "refs/heads/feature", "v1", "feature~1"} {
+		want, err := ResolveCommit(barePath, ref)
+		require.NoError(t, err)
+
+		got, err := batch.Resolve(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, got, ref)
+	}
+
+	_, err := batch.Resolve("does-not-exist")
+	assert.EqualError(t, err, `resolving "does-not-exist": not a branch, tag or commit`)
+
+	// The cat-file process keeps answering after a miss.
+	head, err := batch.Resolve("main~0")
+	require.NoError(t, err)
+	want, _ := ResolveCommit(barePath, "main")
+	assert.Equal(t, want, head)
+}
+
+func TestBatch_IsAncestor(t *testing.T) {
+	barePath := setupBatchRepo(t)
+	batch := NewBatch(barePath)
+	defer func() { assert.NoError(t, batch.Close()) }()
+
+	tests := []struct {
+		ancestor, commit string
+		want             bool
+	}{
+		{"main", "feature", true},
+		{"feature", "main", false},
+		{"fresh", "main", true},
+		{"fresh", "feature", true},
+		{"feature", "fresh", false},
+	}
+	for _, tt := range tests {
+		got, err := batch.IsAncestor(tt.ancestor, tt.commit)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s in %s", tt.ancestor, tt.commit)
+
+		direct, err := IsMerged(barePath, tt.ancestor, tt.commit)
+		require.NoError(t, err)
+		assert.Equal(t, direct, got, "%s in %s", tt.ancestor, tt.commit)
+	}
+
+	// main and fresh are the same commit, so only the two distinct pairs
+	// needed a merge-base, and each only once.
+	assert.Equal(t, 2, batch.mergeBaseRuns)
+
+	_, err := batch.IsAncestor("does-not-exist", "main")
+	assert.Error(t, err)
+}
+
+func TestBatch_CloseWithoutQueries(t *testing.T) {
+	batch := NewBatch(t.TempDir())
+	assert.NoError(t, batch.Close())
+}
@@ -3,6 +3,8 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -24,6 +26,29 @@ func SetBranchUpstream(barePath, branch, remote string) error {
 	return nil
 }
 
+// EnsureBranchUpstream sets up tracking for a branch on remote unless the
+// branch already tracks something or the remote is not configured, e.g. in
+// a project created from a template. It reports whether tracking was set.
+func EnsureBranchUpstream(barePath, branch, remote string) (bool, error) {
+	hasTracking, err := HasBranchTracking(barePath, branch)
+	if err != nil || hasTracking {
+		return false, err
+	}
+
+	remotes, err := ListRemotes(barePath)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(remotes, remote) {
+		return false, nil
+	}
+
+	if err := SetBranchUpstream(barePath, branch, remote); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // HasBranchTracking checks if a branch has upstream tracking configured.
 func HasBranchTracking(barePath, branch string) (bool, error) {
 	cmd := exec.Command("git", "-C", barePath, "config", "--get", fmt.Sprintf("branch.%s.remote", branch))
@@ -37,6 +62,38 @@ func HasBranchTracking(barePath, branch string) (bool, error) {
 	return true, nil
 }
 
+// BranchUpstream returns the remote branch a branch tracks, e.g.
+// "origin/feature", or "" when it tracks nothing.
+func BranchUpstream(repoPath, branch string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "for-each-ref", "--format=%(upstream:short)", "refs/heads/"+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading upstream of %s: %w", branch, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AheadBehind counts the commits on ref that are not on base (ahead) and
+// the commits on base that are not on ref (behind).
+func AheadBehind(repoPath, ref, base string) (ahead, behind int, err error) {
+	cmd := exec.Command("git", "-C", repoPath, "rev-list", "--left-right", "--count", ref+"..."+base, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("comparing %s with %s: %w", ref, base, err)
+	}
+	counts := strings.Fields(string(output))
+	if len(counts) != 2 {
+		return 0, 0, fmt.Errorf("comparing %s with %s: unexpected output %q", ref, base, output)
+	}
+	if ahead, err = strconv.Atoi(counts[0]); err != nil {
+		return 0, 0, fmt.Errorf("comparing %s with %s: %w", ref, base, err)
+	}
+	if behind, err = strconv.Atoi(counts[1]); err != nil {
+		return 0, 0, fmt.Errorf("comparing %s with %s: %w", ref, base, err)
+	}
+	return ahead, behind, nil
+}
+
 // GetBranchRefs returns all local and remote branch names.
 // Local branches are returned as-is (e.g., "main", "feature/foo").
 // Remote branches are returned with remote prefix (e.g., "origin/main").
@@ -77,3 +134,100 @@ func ListLocalBranches(barePath string) ([]string, error) {
 	local, _, err := GetBranchRefs(barePath)
 	return local, err
 }
+
+// ListTags returns tag names, most recently created first. A positive limit
+// caps the number of tags returned.
+func ListTags(barePath string, limit int) ([]string, error) {
+	args := []string{"-C", barePath, "for-each-ref", "--sort=-creatordate", "--format=%(refname:short)"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--count=%d", limit))
+	}
+	args = append(args, "refs/tags/")
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+
+	var tags []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			tags = append(tags, line)
+		}
+	}
+	return tags, nil
+}
+
+// ResolveCommit resolves a branch, remote branch, tag or commit to the
+// commit hash it points at.
+func ResolveCommit(barePath, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", barePath, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving %q: not a branch, tag or commit", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FastForwardBranch moves a branch that is not checked out forward to ref,
+// reporting whether it moved. It fails without changing anything if the
+// branch has commits ref does not contain.
+func FastForwardBranch(barePath, branch, ref string) (bool, error) {
+	current, err := ResolveCommit(barePath, "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	target, err := ResolveCommit(barePath, ref)
+	if err != nil {
+		return false, err
+	}
+	if current == target {
+		return false, nil
+	}
+
+	ancestor, err := IsMerged(barePath, current, target)
+	if err != nil {
+		return false, err
+	}
+	if !ancestor {
+		return false, fmt.Errorf("cannot fast-forward %s: it has diverged from %s", branch, ref)
+	}
+
+	cmd := exec.Command("git", "-C", barePath, "update-ref", "refs/heads/"+branch, target, current)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("fast-forwarding %s: %w\n%s", branch, err, string(output))
+	}
+	return true, nil
+}
+
+// RenameBranch renames a branch with git branch -m. Git moves the branch's
+// config, such as its upstream and recorded parent, to the new name.
+func RenameBranch(barePath, oldName, newName string) error {
+	output, err := exec.Command("git", "-C", barePath, "branch", "-m", oldName, newName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git branch -m failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// GetBranchRemote returns the remote a branch tracks, or "" if it tracks
+// none.
+func GetBranchRemote(barePath, branch string) (string, error) {
+	cmd := exec.Command("git", "-C", barePath, "config", "--get", fmt.Sprintf("branch.%s.remote", branch))
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading branch remote: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ValidateBranchName returns an error if name is not a valid branch name.
+func ValidateBranchName(name string) error {
+	if err := exec.Command("git", "check-ref-format", "--branch", name).Run(); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RemoteBranchCacheTTL is how long a cached remote branch list is used
+// before git is asked again.
+const RemoteBranchCacheTTL = 10 * time.Minute
+
+// remoteBranchCacheFile is the cache file's name in the cache directory.
+const remoteBranchCacheFile = "remote-branches.json"
+
+type remoteBranchCache struct {
+	CachedAt time.Time `json:"cachedAt"`
+	Branches []string  `json:"branches"`
+}
+
+// CachedRemoteBranches returns ListRemoteBranches, cached in cacheDir so
+// interactive pickers open quickly on repositories with many branches.
+// The cache is used until ttl has passed or the repository has been
+// fetched since (FETCH_HEAD is newer); refresh skips it. Failing to write
+// the cache is not an error.
+func CachedRemoteBranches(barePath, cacheDir string, ttl time.Duration, refresh bool) ([]string, error) {
+	path := filepath.Join(cacheDir, remoteBranchCacheFile)
+	if !refresh {
+		if branches, ok := readRemoteBranchCache(path, barePath, ttl); ok {
+			return branches, nil
+		}
+	}
+
+	branches, err := ListRemoteBranches(barePath)
+	if err != nil {
+		return nil, err
+	}
+	_ = writeRemoteBranchCache(path, branches)
+	return branches, nil
+}
+
+func readRemoteBranchCache(path, barePath string, ttl time.Duration) ([]string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cache remoteBranchCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false
+	}
+	if time.Since(cache.CachedAt) > ttl {
+		return nil, false
+	}
+	if info, err := os.Stat(filepath.Join(barePath, "FETCH_HEAD")); err == nil && info.ModTime().After(cache.CachedAt) {
+		return nil, false
+	}
+	return cache.Branches, true
+}
+
+// writeRemoteBranchCache writes the cache through a temporary file, so
+// concurrent pickers never read a partial file.
+func writeRemoteBranchCache(path string, branches []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.Marshal(remoteBranchCache{CachedAt: time.Now(), Branches: branches})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), remoteBranchCacheFile+".*")
+	if err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,53 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedRemoteBranches(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	addRemoteRef := func(name string) {
+		out, err := exec.Command("git", "-C", barePath, "update-ref", "refs/remotes/origin/"+name, "main").CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	addRemoteRef("feature/one")
+	branches, err := CachedRemoteBranches(barePath, cacheDir, time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin/feature/one"}, branches)
+
+	addRemoteRef("feature/two")
+	branches, err = CachedRemoteBranches(barePath, cacheDir, time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin/feature/one"}, branches, "served from the cache")
+
+	branches, err = CachedRemoteBranches(barePath, cacheDir, time.Hour, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin/feature/one", "origin/feature/two"}, branches, "refresh skips the cache")
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		addRemoteRef("feature/three")
+		branches, err := CachedRemoteBranches(barePath, cacheDir, 0, false)
+		require.NoError(t, err)
+		assert.Len(t, branches, 3)
+	})
+
+	t.Run("is invalidated by a fetch", func(t *testing.T) {
+		addRemoteRef("feature/four")
+		require.NoError(t, os.WriteFile(filepath.Join(barePath, "FETCH_HEAD"), nil, 0644))
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(barePath, "FETCH_HEAD"), future, future))
+
+		branches, err := CachedRemoteBranches(barePath, cacheDir, time.Hour, false)
+		require.NoError(t, err)
+		assert.Len(t, branches, 4)
+	})
+}
@@ -74,6 +74,31 @@ func TestHasBranchTracking(t *testing.T) {
 	assert.True(t, has)
 }
 
+func TestBranchUpstream(t *testing.T) {
+	clonePath := setupFastForwardRepo(t)
+
+	upstream, err := BranchUpstream(clonePath, "main")
+	assert.NoError(t, err)
+	assert.Equal(t, "origin/main", upstream)
+
+	assert.NoError(t, exec.Command("git", "-C", clonePath, "branch", "local-only").Run())
+	upstream, err = BranchUpstream(clonePath, "local-only")
+	assert.NoError(t, err)
+	assert.Empty(t, upstream)
+}
+
+func TestAheadBehind(t *testing.T) {
+	clonePath := setupFastForwardRepo(t)
+
+	ahead, behind, err := AheadBehind(clonePath, "main", "origin/main")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ahead)
+	assert.Equal(t, 1, behind)
+
+	_, _, err = AheadBehind(clonePath, "main", "origin/missing")
+	assert.Error(t, err)
+}
+
 func TestGetBranchRefs(t *testing.T) {
 	barePath, _ := createTestRepo(t)
 
@@ -97,3 +122,159 @@ func TestListLocalBranches(t *testing.T) {
 	// Should have at least main branch
 	assert.Contains(t, branches, "main")
 }
+
+func TestListTags(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+
+	tags, err := ListTags(barePath, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+
+	for _, tag := range []string{"v1.0.0", "v1.1.0"} {
+		assert.NoError(t, exec.Command("git", "-C", barePath, "tag", tag).Run())
+	}
+
+	tags, err = ListTags(barePath, 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v1.0.0", "v1.1.0"}, tags)
+
+	tags, err = ListTags(barePath, 1)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+}
+
+func TestResolveCommit(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+
+	output, err := exec.Command("git", "-C", barePath, "rev-parse", "main").Output()
+	assert.NoError(t, err)
+	head := strings.TrimSpace(string(output))
+
+	assert.NoError(t, exec.Command("git", "-C", barePath, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "tag", "-a", "v1.0.0", "-m", "v1.0.0").Run())
+
+	for _, ref := range []string{"main", "v1.0.0", head, head[:8]} {
+		commit, err := ResolveCommit(barePath, ref)
+		assert.NoError(t, err, ref)
+		assert.Equal(t, head, commit, ref)
+	}
+
+	_, err = ResolveCommit(barePath, "origin/release/1.2")
+	assert.Error(t, err)
+}
+
+func TestFastForwardBranch(t *testing.T) {
+	barePath, repoDir := createTestRepo(t)
+	assert.NoError(t, ConfigureFetchRefspec(barePath, repoDir))
+
+	for _, args := range [][]string{
+		{"checkout", "-b", "feature/x"},
+		{"commit", "--allow-empty", "-m", "first"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		assert.NoError(t, cmd.Run())
+	}
+	assert.NoError(t, FetchRemote(barePath, "origin"))
+	assert.NoError(t, CreateBranch(barePath, "feature/x", "main"))
+
+	moved, err := FastForwardBranch(barePath, "feature/x", "origin/feature/x")
+	assert.NoError(t, err)
+	assert.True(t, moved)
+
+	local, _ := ResolveCommit(barePath, "refs/heads/feature/x")
+	remote, _ := ResolveCommit(barePath, "origin/feature/x")
+	assert.Equal(t, remote, local)
+
+	moved, err = FastForwardBranch(barePath, "feature/x", "origin/feature/x")
+	assert.NoError(t, err)
+	assert.False(t, moved, "an up to date branch should not move")
+}
+
+func TestFastForwardBranch_Diverged(t *testing.T) {
+	barePath, repoDir := createTestRepo(t)
+	assert.NoError(t, ConfigureFetchRefspec(barePath, repoDir))
+
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", "remote only")
+	cmd.Dir = repoDir
+	assert.NoError(t, cmd.Run())
+	assert.NoError(t, FetchRemote(barePath, "origin"))
+
+	cmd = exec.Command("git", "-C", barePath, "-c", "user.name=Test User", "-c", "user.email=test@example.com",
+		"commit-tree", "main^{tree}", "-p", "main", "-m", "local only")
+	output, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.NoError(t, exec.Command("git", "-C", barePath, "update-ref", "refs/heads/main", strings.TrimSpace(string(output))).Run())
+	before, _ := ResolveCommit(barePath, "refs/heads/main")
+
+	moved, err := FastForwardBranch(barePath, "main", "origin/main")
+	assert.Error(t, err)
+	assert.False(t, moved)
+
+	after, _ := ResolveCommit(barePath, "refs/heads/main")
+	assert.Equal(t, before, after, "a diverged branch should be left alone")
+}
+
+func TestEnsureBranchUpstream(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	assert.NoError(t, CreateBranch(barePath, "feature", "main"))
+
+	set, err := EnsureBranchUpstream(barePath, "feature", "origin")
+	assert.NoError(t, err)
+	assert.True(t, set)
+
+	output, err := exec.Command("git", "-C", barePath, "config", "--get", "branch.feature.merge").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "refs/heads/feature", strings.TrimSpace(string(output)))
+
+	set, err = EnsureBranchUpstream(barePath, "feature", "origin")
+	assert.NoError(t, err)
+	assert.False(t, set, "a tracked branch should be left alone")
+}
+
+func TestEnsureBranchUpstream_KeepsExistingTracking(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	assert.NoError(t, exec.Command("git", "-C", barePath, "config", "branch.main.remote", "upstream").Run())
+	assert.NoError(t, exec.Command("git", "-C", barePath, "config", "branch.main.merge", "refs/heads/develop").Run())
+
+	set, err := EnsureBranchUpstream(barePath, "main", "origin")
+	assert.NoError(t, err)
+	assert.False(t, set)
+
+	output, err := exec.Command("git", "-C", barePath, "config", "--get", "branch.main.merge").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "refs/heads/develop", strings.TrimSpace(string(output)))
+}
+
+func TestEnsureBranchUpstream_NoRemote(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	assert.NoError(t, exec.Command("git", "-C", barePath, "remote", "remove", "origin").Run())
+
+	set, err := EnsureBranchUpstream(barePath, "main", "origin")
+	assert.NoError(t, err)
+	assert.False(t, set)
+
+	hasTracking, err := HasBranchTracking(barePath, "main")
+	assert.NoError(t, err)
+	assert.False(t, hasTracking, "tracking a missing remote would break git push")
+}
+
+func TestRenameBranch(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+
+	assert.NoError(t, exec.Command("git", "-C", barePath, "branch", "old-name", "main").Run())
+	assert.NoError(t, SetBranchParent(barePath, "old-name", "main"))
+
+	assert.NoError(t, RenameBranch(barePath, "old-name", "new-name"))
+	assert.False(t, BranchExists(barePath, "old-name"))
+	assert.True(t, BranchExists(barePath, "new-name"))
+
+	parents, err := BranchParents(barePath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"new-name": "main"}, parents, "git moves branch config on rename")
+}
+
+func TestValidateBranchName(t *testing.T) {
+	assert.NoError(t, ValidateBranchName("feature/login"))
+	assert.Error(t, ValidateBranchName("bad..name"))
+	assert.Error(t, ValidateBranchName("with space"))
+}
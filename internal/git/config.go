@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// worktreeConfigMu serialises enabling per-worktree config, which rewrites
+// the shared repository config.
+var worktreeConfigMu sync.Mutex
+
+// SetWorktreeConfig sets key to value in the config of the worktree at
+// worktreePath only, leaving the repository's other worktrees as they are.
+// Per-worktree config is enabled first if the repository does not use it
+// yet.
+func SetWorktreeConfig(worktreePath, key, value string) error {
+	if err := enableWorktreeConfig(worktreePath); err != nil {
+		return err
+	}
+	output, err := exec.Command("git", "-C", worktreePath, "config", "--worktree", key, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("setting %s: %w\n%s", key, err, string(output))
+	}
+	return nil
+}
+
+// enableWorktreeConfig turns on extensions.worktreeConfig for the
+// repository worktreePath belongs to. core.bare and core.worktree are
+// moved from the shared config to the main worktree's config.worktree, as
+// git-worktree(1) requires: otherwise the bare repository's core.bare would
+// apply to every linked worktree and break them.
+func enableWorktreeConfig(worktreePath string) error {
+	worktreeConfigMu.Lock()
+	defer worktreeConfigMu.Unlock()
+
+	commonDir, err := gitCommonDir(worktreePath)
+	if err != nil {
+		return err
+	}
+	sharedConfig := filepath.Join(commonDir, "config")
+	mainConfig := filepath.Join(commonDir, "config.worktree")
+
+	if enabled, _ := configGet(sharedConfig, "--bool", "extensions.worktreeConfig"); enabled == "true" {
+		return nil
+	}
+
+	for _, key := range []string{"core.bare", "core.worktree"} {
+		value, ok := configGet(sharedConfig, key)
+		if !ok {
+			continue
+		}
+		if err := configSet(mainConfig, key, value); err != nil {
+			return err
+		}
+		if output, err := exec.Command("git", "config", "--file", sharedConfig, "--unset", key).CombinedOutput(); err != nil {
+			return fmt.Errorf("moving %s to %s: %w\n%s", key, mainConfig, err, string(output))
+		}
+	}
+
+	// Extensions are only honoured by repository format version 1.
+	if err := configSet(sharedConfig, "core.repositoryFormatVersion", "1"); err != nil {
+		return err
+	}
+	return configSet(sharedConfig, "extensions.worktreeConfig", "true")
+}
+
+// GitDir returns the git directory private to the worktree at
+// worktreePath, such as .bare/worktrees/<name> for a linked worktree.
+func GitDir(worktreePath string) (string, error) {
+	return revParseDir(worktreePath, "--git-dir")
+}
+
+func gitCommonDir(worktreePath string) (string, error) {
+	return revParseDir(worktreePath, "--git-common-dir")
+}
+
+// revParseDir runs git rev-parse with flag and returns the directory it
+// prints as an absolute path.
+func revParseDir(worktreePath, flag string) (string, error) {
+	output, err := exec.Command("git", "-C", worktreePath, "rev-parse", flag).Output()
+	if err != nil {
+		return "", fmt.Errorf("finding git directory of %s: %w", worktreePath, err)
+	}
+	dir := filepath.FromSlash(strings.TrimSpace(string(output)))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(worktreePath, dir)
+	}
+	return dir, nil
+}
+
+// configGet reads key from a config file; ok is false when it is unset.
+func configGet(file string, args ...string) (string, bool) {
+	cmdArgs := append([]string{"config", "--file", file, "--get"}, args...)
+	output, err := exec.Command("git", cmdArgs...).Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+func configSet(file, key, value string) error {
+	output, err := exec.Command("git", "config", "--file", file, key, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("setting %s in %s: %w\n%s", key, file, err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWorktreeConfig(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+	mainPath := filepath.Join(projectDir, "main")
+	featurePath := filepath.Join(projectDir, "feature")
+	require.NoError(t, exec.Command("git", "-C", barePath, "worktree", "add", mainPath, "main").Run())
+	require.NoError(t, exec.Command("git", "-C", barePath, "worktree", "add", "-b", "feature", featurePath).Run())
+
+	gitOutput := func(dir string, args ...string) string {
+		output, _ := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		return strings.TrimSpace(string(output))
+	}
+
+	require.NoError(t, SetWorktreeConfig(featurePath, "user.email", "dev@work.example"))
+	require.NoError(t, SetWorktreeConfig(featurePath, "push.autoSetupRemote", "true"))
+
+	assert.Equal(t, "dev@work.example", gitOutput(featurePath, "config", "user.email"))
+	assert.Equal(t, "true", gitOutput(featurePath, "config", "push.autoSetupRemote"))
+	assert.Empty(t, gitOutput(mainPath, "config", "--get", "push.autoSetupRemote"), "other worktrees are unaffected")
+
+	for _, dir := range []string{mainPath, featurePath} {
+		assert.Equal(t, "true", gitOutput(dir, "rev-parse", "--is-inside-work-tree"), "%s stays a working tree", dir)
+	}
+	assert.Equal(t, "true", gitOutput(barePath, "rev-parse", "--is-bare-repository"))
+}
@@ -0,0 +1,144 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictedFiles returns the paths, relative to the worktree root, that
+// still have unresolved conflicts in the index.
+func ConflictedFiles(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "diff", "--name-only", "--diff-filter=U")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing conflicted files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// StageResolved stages conflicted files that no longer contain conflict
+// markers, including files deleted to resolve the conflict, and returns the
+// files that still do.
+func StageResolved(worktreePath string) ([]string, error) {
+	files, err := ConflictedFiles(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved, remaining []string
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(worktreePath, file))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		if hasConflictMarkers(content) {
+			remaining = append(remaining, file)
+		} else {
+			resolved = append(resolved, file)
+		}
+	}
+
+	if len(resolved) > 0 {
+		args := append([]string{"-C", worktreePath, "add", "-A", "--"}, resolved...)
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("staging resolved files: %w\n%s", err, string(output))
+		}
+	}
+	return remaining, nil
+}
+
+func hasConflictMarkers(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.HasPrefix(line, []byte("<<<<<<< ")) || bytes.HasPrefix(line, []byte(">>>>>>> ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContinueRebase resumes a rebase after its conflicts are staged, without
+// opening an editor for commit messages.
+func ContinueRebase(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "rebase", "--continue")
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "conflict") {
+			return &RebaseConflictError{Output: outputStr}
+		}
+		return fmt.Errorf("git rebase --continue failed: %w\n%s", err, outputStr)
+	}
+	return nil
+}
+
+// AbortRebase aborts a rebase, restoring the branch to where it was.
+func AbortRebase(worktreePath string) error {
+	output, err := exec.Command("git", "-C", worktreePath, "rebase", "--abort").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git rebase --abort failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// ContinueMerge concludes a merge after its conflicts are staged, using the
+// prepared merge commit message.
+func ContinueMerge(worktreePath string) error {
+	output, err := exec.Command("git", "-C", worktreePath, "commit", "--no-edit").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("completing merge failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// AbortMerge aborts a merge, restoring the branch to where it was.
+func AbortMerge(worktreePath string) error {
+	output, err := exec.Command("git", "-C", worktreePath, "merge", "--abort").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git merge --abort failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// RunMergetool runs git mergetool on the worktree's conflicts, attached to
+// the terminal.
+func RunMergetool(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "mergetool")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git mergetool failed: %w", err)
+	}
+	return nil
+}
+
+// GitPath returns the path of name inside a worktree's git directory, for
+// state that belongs to the worktree but must stay out of its files.
+func GitPath(worktreePath, name string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", name)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("locating git path %s: %w", name, err)
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktreePath, path)
+	}
+	return path, nil
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupConflictRepo creates a repository whose "feature" branch conflicts
+// with "main" in README.md and is checked out mid-rebase onto main.
+func setupConflictRepo(t *testing.T) string {
+	t.Helper()
+
+	repoPath := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, output)
+	}
+	write := func(content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte(content), 0644))
+	}
+
+	run("init", "-b", "main")
+	run("config", "user.name", "Test User")
+	run("config", "user.email", "test@example.com")
+	write("base\n")
+	run("add", "README.md")
+	run("commit", "-m", "base")
+
+	run("checkout", "-b", "feature")
+	write("feature\n")
+	run("commit", "-am", "feature")
+
+	run("checkout", "main")
+	write("main\n")
+	run("commit", "-am", "main")
+
+	run("checkout", "feature")
+	err := exec.Command("git", "-C", repoPath, "rebase", "main").Run()
+	require.Error(t, err, "expected the rebase to stop with conflicts")
+
+	return repoPath
+}
+
+func TestConflictedFiles(t *testing.T) {
+	repoPath := setupConflictRepo(t)
+
+	files, err := ConflictedFiles(repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, files)
+}
+
+func TestStageResolved(t *testing.T) {
+	repoPath := setupConflictRepo(t)
+
+	remaining, err := StageResolved(repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, remaining, "files with conflict markers stay unresolved")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("main and feature\n"), 0644))
+
+	remaining, err = StageResolved(repoPath)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	files, err := ConflictedFiles(repoPath)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestContinueRebase(t *testing.T) {
+	repoPath := setupConflictRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("main and feature\n"), 0644))
+	_, err := StageResolved(repoPath)
+	require.NoError(t, err)
+
+	require.NoError(t, ContinueRebase(repoPath))
+	assert.False(t, IsRebaseInProgress(repoPath))
+
+	branch, err := GetCurrentBranch(repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, "feature", branch)
+}
+
+func TestAbortRebase(t *testing.T) {
+	repoPath := setupConflictRepo(t)
+
+	require.NoError(t, AbortRebase(repoPath))
+	assert.False(t, IsRebaseInProgress(repoPath))
+
+	content, err := os.ReadFile(filepath.Join(repoPath, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "feature\n", string(content))
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	assert.True(t, hasConflictMarkers([]byte("a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> feature\n")))
+	assert.False(t, hasConflictMarkers([]byte("a\n=======\nb\n")))
+	assert.False(t, hasConflictMarkers(nil))
+}
+
+func TestGitPath(t *testing.T) {
+	repoPath := setupStashTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	path, err := GitPath(repoPath, "arbor-test")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repoPath, ".git", "arbor-test"), path)
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// PushRejectedError is returned when the remote rejects a push because the
+// branch is not a fast-forward of the remote branch, e.g. after a rebase.
+type PushRejectedError struct {
+	Output string
+}
+
+func (e *PushRejectedError) Error() string {
+	return "push rejected: remote branch has commits the local branch does not\n" + e.Output
+}
+
+// Push pushes the current branch of a worktree to the branch of the same
+// name on remote and sets it as the upstream. forceWithLease overwrites the
+// remote branch only if it is still where the last fetch saw it.
+func Push(worktreePath, remote string, forceWithLease bool) error {
+	args := []string{"-C", worktreePath, "push", "-u"}
+	if forceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	args = append(args, remote, "HEAD")
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		if !forceWithLease && isNonFastForward(string(output)) {
+			return &PushRejectedError{Output: string(output)}
+		}
+		return fmt.Errorf("git push failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+func isNonFastForward(output string) bool {
+	return strings.Contains(output, "non-fast-forward") || strings.Contains(output, "fetch first")
+}
+
+// WebURL converts a remote URL such as git@github.com:owner/repo.git,
+// ssh://git@host/owner/repo or https://host/owner/repo.git into the
+// repository's https web address. It returns false for remotes without a
+// web address, such as local paths.
+func WebURL(remoteURL string) (string, bool) {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, repoPath string
+	switch {
+	case strings.HasPrefix(remoteURL, "https://"), strings.HasPrefix(remoteURL, "http://"), strings.HasPrefix(remoteURL, "ssh://"):
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Hostname() == "" {
+			return "", false
+		}
+		host, repoPath = u.Hostname(), u.Path
+	case strings.Contains(remoteURL, "@") && strings.Contains(remoteURL, ":"):
+		// scp-like syntax: user@host:owner/repo.git
+		userHost, p, _ := strings.Cut(remoteURL, ":")
+		_, host, _ = strings.Cut(userHost, "@")
+		repoPath = p
+	default:
+		return "", false
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", false
+	}
+	return "https://" + host + "/" + repoPath, true
+}
+
+// CompareURL returns the page for opening a pull or merge request from
+// branch into base on the remote's host. GitLab and Bitbucket get their own
+// formats; other hosts use GitHub's compare page.
+func CompareURL(remoteURL, base, branch string) (string, bool) {
+	web, ok := WebURL(remoteURL)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case strings.Contains(web, "://gitlab."):
+		return fmt.Sprintf("%s/-/merge_requests/new?merge_request%%5Bsource_branch%%5D=%s&merge_request%%5Btarget_branch%%5D=%s",
+			web, url.QueryEscape(branch), url.QueryEscape(base)), true
+	case strings.Contains(web, "://bitbucket.org/"):
+		return fmt.Sprintf("%s/pull-requests/new?source=%s&dest=%s", web, url.QueryEscape(branch), url.QueryEscape(base)), true
+	default:
+		return fmt.Sprintf("%s/compare/%s...%s?expand=1", web, escapeBranch(base), escapeBranch(branch)), true
+	}
+}
+
+// escapeBranch escapes a branch name for a URL path, keeping the slashes
+// of names like feature/login readable.
+func escapeBranch(branch string) string {
+	return strings.ReplaceAll(url.PathEscape(branch), "%2F", "/")
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+		ok     bool
+	}{
+		{"git@github.com:owner/repo.git", "https://github.com/owner/repo", true},
+		{"https://github.com/owner/repo.git", "https://github.com/owner/repo", true},
+		{"https://user@gitlab.com/group/sub/repo", "https://gitlab.com/group/sub/repo", true},
+		{"ssh://git@bitbucket.org:22/team/repo.git", "https://bitbucket.org/team/repo", true},
+		{"/srv/git/repo.git", "", false},
+		{"owner/repo", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, ok := WebURL(tt.remote)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:owner/repo.git", "https://github.com/owner/repo/compare/main...feature/login?expand=1"},
+		{"git@gitlab.com:group/repo.git", "https://gitlab.com/group/repo/-/merge_requests/new?merge_request%5Bsource_branch%5D=feature%2Flogin&merge_request%5Btarget_branch%5D=main"},
+		{"git@bitbucket.org:team/repo.git", "https://bitbucket.org/team/repo/pull-requests/new?source=feature%2Flogin&dest=main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, ok := CompareURL(tt.remote, "main", "feature/login")
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, ok := CompareURL("/srv/git/repo.git", "main", "feature")
+	assert.False(t, ok)
+}
+
+func TestPush(t *testing.T) {
+	barePath, repoDir := createTestRepo(t)
+
+	// Push to a separate bare repository so the source repo's checked out
+	// branch does not block the push.
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	require.NoError(t, exec.Command("git", "clone", "--bare", repoDir, remoteDir).Run())
+	require.NoError(t, ConfigureFetchRefspec(barePath, remoteDir))
+
+	featurePath := filepath.Join(filepath.Dir(barePath), "feature")
+	require.NoError(t, CreateWorktree(barePath, featurePath, "feature", "main"))
+	commit := func(message string) {
+		cmd := exec.Command("git", "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", message)
+		cmd.Dir = featurePath
+		require.NoError(t, cmd.Run())
+	}
+	commit("feature work")
+
+	require.NoError(t, Push(featurePath, "origin", false))
+
+	local, err := ResolveCommit(barePath, "refs/heads/feature")
+	require.NoError(t, err)
+	remote, err := ResolveCommit(remoteDir, "refs/heads/feature")
+	require.NoError(t, err)
+	assert.Equal(t, local, remote)
+
+	hasTracking, err := HasBranchTracking(barePath, "feature")
+	require.NoError(t, err)
+	assert.True(t, hasTracking, "push should set the upstream")
+
+	// Rewrite the pushed commit, as a rebase would.
+	cmd := exec.Command("git", "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--amend", "--allow-empty", "-m", "rewritten")
+	cmd.Dir = featurePath
+	require.NoError(t, cmd.Run())
+
+	err = Push(featurePath, "origin", false)
+	var rejected *PushRejectedError
+	assert.True(t, errors.As(err, &rejected), "expected a rejected push, got %v", err)
+
+	require.NoError(t, Push(featurePath, "origin", true))
+}
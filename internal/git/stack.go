@@ -0,0 +1,126 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// parentConfigKey is the branch config key holding the branch a branch was
+// created from. Keeping it under branch.<name> lets git carry it along when
+// the branch is renamed.
+const parentConfigKey = "arborParent"
+
+// SetBranchParent records parent as the branch that branch is stacked on.
+func SetBranchParent(barePath, branch, parent string) error {
+	key := fmt.Sprintf("branch.%s.%s", branch, parentConfigKey)
+	output, err := exec.Command("git", "-C", barePath, "config", key, parent).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("recording parent of %s: %w\n%s", branch, err, string(output))
+	}
+	return nil
+}
+
+// BranchParents returns the recorded parent of every branch that has one,
+// keyed by branch name.
+func BranchParents(barePath string) (map[string]string, error) {
+	cmd := exec.Command("git", "-C", barePath, "config", "--get-regexp", `^branch\..*\.arborparent$`)
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means no keys matched
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("reading branch parents: %w", err)
+	}
+
+	parents := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, parent, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		// key is branch.<name>.arborparent; names may contain dots
+		branch := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), "."+strings.ToLower(parentConfigKey))
+		parents[branch] = parent
+	}
+	return parents, nil
+}
+
+// StackBranches returns the branches stacked on root, directly or through
+// other stacked branches, with every branch after its parent.
+func StackBranches(parents map[string]string, root string) []string {
+	children := make(map[string][]string)
+	for branch, parent := range parents {
+		children[parent] = append(children[parent], branch)
+	}
+
+	var order []string
+	seen := map[string]bool{root: true}
+	var walk func(branch string)
+	walk = func(branch string) {
+		kids := children[branch]
+		slices.Sort(kids)
+		for _, kid := range kids {
+			// Guard against cycles from hand-edited config
+			if seen[kid] {
+				continue
+			}
+			seen[kid] = true
+			order = append(order, kid)
+			walk(kid)
+		}
+	}
+	walk(root)
+	return order
+}
+
+// StackRoot follows recorded parents up from branch and returns the bottom
+// of its stack: the last branch before one that is not stacked, such as
+// the default branch.
+func StackRoot(parents map[string]string, branch, base string) string {
+	seen := map[string]bool{branch: true}
+	for {
+		parent, ok := parents[branch]
+		if !ok || parent == base || seen[parent] {
+			return branch
+		}
+		seen[parent] = true
+		branch = parent
+	}
+}
+
+// RebaseBranchOnto rebases the current worktree branch onto parent. If
+// oldParent, the parent's tip before it was itself rebased, is still in the
+// branch, only the commits after it are replayed, so the parent's rewritten
+// commits are not applied twice.
+func RebaseBranchOnto(worktreePath, parent, oldParent string, options ...string) error {
+	args := []string{"-C", worktreePath, "rebase"}
+	if slices.Contains(options, "--autosquash") {
+		args = append(args, "--interactive")
+	}
+	args = append(args, options...)
+	if oldParent != "" && isAncestor(worktreePath, oldParent, "HEAD") {
+		args = append(args, "--onto", parent, oldParent)
+	} else {
+		args = append(args, parent)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "conflict") {
+			return &RebaseConflictError{Output: outputStr}
+		}
+		return fmt.Errorf("git rebase failed: %w\n%s", err, outputStr)
+	}
+	return nil
+}
+
+func isAncestor(repoPath, ancestor, commit string) bool {
+	return exec.Command("git", "-C", repoPath, "merge-base", "--is-ancestor", ancestor, commit).Run() == nil
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchParents(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+
+	parents, err := BranchParents(barePath)
+	require.NoError(t, err)
+	assert.Empty(t, parents)
+
+	require.NoError(t, SetBranchParent(barePath, "feature/a", "main"))
+	require.NoError(t, SetBranchParent(barePath, "feature/a.b", "feature/a"))
+
+	parents, err = BranchParents(barePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"feature/a":   "main",
+		"feature/a.b": "feature/a",
+	}, parents)
+}
+
+func TestStackBranches(t *testing.T) {
+	parents := map[string]string{
+		"a":     "main",
+		"b":     "a",
+		"c":     "b",
+		"b2":    "a",
+		"other": "main",
+	}
+
+	assert.Equal(t, []string{"b", "c", "b2"}, StackBranches(parents, "a"))
+	assert.Equal(t, []string{"c"}, StackBranches(parents, "b"))
+	assert.Empty(t, StackBranches(parents, "c"))
+}
+
+func TestStackBranches_Cycle(t *testing.T) {
+	parents := map[string]string{"a": "b", "b": "a"}
+	assert.Equal(t, []string{"b"}, StackBranches(parents, "a"))
+}
+
+func TestStackRoot(t *testing.T) {
+	parents := map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"x": "y",
+		"y": "x",
+	}
+
+	assert.Equal(t, "a", StackRoot(parents, "c", "main"))
+	assert.Equal(t, "a", StackRoot(parents, "a", "main"))
+	assert.Equal(t, "unstacked", StackRoot(parents, "unstacked", "main"))
+	assert.NotEmpty(t, StackRoot(parents, "x", "main"), "cycles must terminate")
+}
+
+func TestRebaseBranchOnto(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, output)
+		return strings.TrimSpace(string(output))
+	}
+	commitFile := func(name string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(name), 0644))
+		git("add", name)
+		git("commit", "-m", name)
+	}
+
+	git("init", "-b", "main")
+	git("config", "user.name", "Test User")
+	git("config", "user.email", "test@example.com")
+	commitFile("base")
+	git("checkout", "-b", "a")
+	commitFile("a1")
+	git("checkout", "-b", "b")
+	commitFile("b1")
+
+	// Rewrite a, as syncing it onto an updated main would
+	oldA := git("rev-parse", "a")
+	git("checkout", "main")
+	commitFile("upstream")
+	git("checkout", "a")
+	git("rebase", "main")
+	git("checkout", "b")
+
+	require.NoError(t, RebaseBranchOnto(repoPath, "a", oldA))
+
+	assert.Equal(t, "b1\na1\nupstream\nbase", git("log", "--format=%s"))
+}
@@ -26,7 +26,42 @@ func StashAll(worktreePath string, message string) error {
 // PopStash pops the most recent stash
 // Returns an error if there are conflicts or if the pop fails
 func PopStash(worktreePath string) error {
-	cmd := exec.Command("git", "-C", worktreePath, "stash", "pop")
+	return popStash(worktreePath, "stash@{0}")
+}
+
+// LatestStash returns the commit of the most recent stash, or "" if there
+// are none. The commit identifies the stash even after others are pushed.
+func LatestStash(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "-q", "--verify", "refs/stash")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading latest stash: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// PopStashCommit pops the stash with the given commit, wherever it now is
+// in the stash list.
+func PopStashCommit(worktreePath, commit string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "list", "--format=%H")
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("listing stashes: %w", err)
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if strings.TrimSpace(line) == commit {
+			return popStash(worktreePath, fmt.Sprintf("stash@{%d}", i))
+		}
+	}
+	return fmt.Errorf("stash %s not found - it may have been popped or dropped already", commit)
+}
+
+func popStash(worktreePath, ref string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "stash", "pop", ref)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		outputStr := string(output)
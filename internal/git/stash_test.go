@@ -382,3 +382,59 @@ func TestHasChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestLatestStash(t *testing.T) {
+	repoPath := setupStashTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commit, err := LatestStash(repoPath)
+	if err != nil {
+		t.Fatalf("LatestStash() error = %v", err)
+	}
+	if commit != "" {
+		t.Errorf("LatestStash() = %q, want empty without stashes", commit)
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Modified\n"), 0644)
+	if err := StashAll(repoPath, "test stash"); err != nil {
+		t.Fatalf("StashAll() error = %v", err)
+	}
+
+	commit, err = LatestStash(repoPath)
+	if err != nil {
+		t.Fatalf("LatestStash() error = %v", err)
+	}
+	if len(commit) != 40 {
+		t.Errorf("LatestStash() = %q, want a commit hash", commit)
+	}
+}
+
+func TestPopStashCommit(t *testing.T) {
+	repoPath := setupStashTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	readmePath := filepath.Join(repoPath, "README.md")
+	os.WriteFile(readmePath, []byte("# Ours\n"), 0644)
+	StashAll(repoPath, "ours")
+	ours, _ := LatestStash(repoPath)
+
+	// A later stash pushes ours down to stash@{1}
+	os.WriteFile(filepath.Join(repoPath, "other.txt"), []byte("other\n"), 0644)
+	StashAll(repoPath, "other")
+
+	if err := PopStashCommit(repoPath, ours); err != nil {
+		t.Fatalf("PopStashCommit() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(readmePath)
+	if string(content) != "# Ours\n" {
+		t.Errorf("README.md = %q, want the popped stash's content", content)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "other.txt")); !os.IsNotExist(err) {
+		t.Error("expected the other stash to stay stashed")
+	}
+
+	if err := PopStashCommit(repoPath, ours); err == nil {
+		t.Error("expected an error popping a stash that no longer exists")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
@@ -17,10 +18,38 @@ func FetchRemote(barePath, remote string) error {
 	return nil
 }
 
-// RebaseOnto runs git rebase from the current worktree onto the specified remote/branch
-func RebaseOnto(worktreePath, remote, upstream string) error {
+// AllowedRebaseOptions lists the git rebase options sync.rebase_options may pass through
+var AllowedRebaseOptions = []string{
+	"--autosquash", "--no-autosquash",
+	"--update-refs", "--no-update-refs",
+	"--rebase-merges", "--no-rebase-merges",
+}
+
+// ValidateRebaseOptions returns an error for options not in AllowedRebaseOptions
+func ValidateRebaseOptions(options []string) error {
+	for _, option := range options {
+		if !slices.Contains(AllowedRebaseOptions, option) {
+			return fmt.Errorf("unsupported rebase option %q (allowed: %s)", option, strings.Join(AllowedRebaseOptions, ", "))
+		}
+	}
+	return nil
+}
+
+// RebaseOnto runs git rebase from the current worktree onto the specified remote/branch.
+// options must be validated with ValidateRebaseOptions. --autosquash runs the rebase
+// as an interactive one that accepts the todo list unedited, since git before 2.44
+// ignores it otherwise.
+func RebaseOnto(worktreePath, remote, upstream string, options ...string) error {
 	ref := fmt.Sprintf("%s/%s", remote, upstream)
-	cmd := exec.Command("git", "-C", worktreePath, "rebase", ref)
+	args := []string{"-C", worktreePath, "rebase"}
+	if slices.Contains(options, "--autosquash") {
+		args = append(args, "--interactive")
+	}
+	args = append(args, options...)
+	args = append(args, ref)
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		// Check if it's a conflict by looking at output
@@ -49,6 +78,33 @@ func MergeInto(worktreePath, remote, upstream string) error {
 	return nil
 }
 
+// FastForwardTo fast-forwards the current worktree branch to the specified
+// remote/branch with git merge --ff-only, never creating a merge commit
+func FastForwardTo(worktreePath, remote, upstream string) error {
+	ref := fmt.Sprintf("%s/%s", remote, upstream)
+	cmd := exec.Command("git", "-C", worktreePath, "merge", "--ff-only", ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "Not possible to fast-forward") || strings.Contains(outputStr, "not possible to fast-forward") {
+			return &DivergedError{Ref: ref, Output: outputStr}
+		}
+		return fmt.Errorf("git merge --ff-only failed: %w\n%s", err, outputStr)
+	}
+	return nil
+}
+
+// DivergedError represents a fast-forward that failed because the branch
+// has commits the upstream does not
+type DivergedError struct {
+	Ref    string
+	Output string
+}
+
+func (e *DivergedError) Error() string {
+	return fmt.Sprintf("cannot fast-forward: branch has diverged from %s\n\nSync with --strategy rebase or --strategy merge, or reset the branch to %s", e.Ref, e.Ref)
+}
+
 // RebaseConflictError represents a rebase that failed due to conflicts
 type RebaseConflictError struct {
 	Output string
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -198,3 +199,106 @@ func TestMergeConflictError(t *testing.T) {
 		t.Errorf("expected error message:\n%s\n\ngot:\n%s", expected, err.Error())
 	}
 }
+
+func setupFastForwardRepo(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	originPath := filepath.Join(tmpDir, "origin")
+	clonePath := filepath.Join(tmpDir, "clone")
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test User", "-c", "user.email=test@test.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	run(tmpDir, "init", "-b", "main", originPath)
+	os.WriteFile(filepath.Join(originPath, "test.txt"), []byte("one"), 0644)
+	run(originPath, "add", "test.txt")
+	run(originPath, "commit", "-m", "one")
+	run(tmpDir, "clone", originPath, clonePath)
+	run(clonePath, "config", "user.name", "Test User")
+	run(clonePath, "config", "user.email", "test@test.com")
+
+	os.WriteFile(filepath.Join(originPath, "test.txt"), []byte("two"), 0644)
+	run(originPath, "commit", "-am", "two")
+	run(clonePath, "fetch", "origin")
+
+	return clonePath
+}
+
+func TestFastForwardTo(t *testing.T) {
+	clonePath := setupFastForwardRepo(t)
+
+	if err := FastForwardTo(clonePath, "origin", "main"); err != nil {
+		t.Fatalf("FastForwardTo failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(clonePath, "test.txt"))
+	if string(content) != "two" {
+		t.Errorf("expected fast-forwarded content %q, got %q", "two", content)
+	}
+}
+
+func TestFastForwardTo_Diverged(t *testing.T) {
+	clonePath := setupFastForwardRepo(t)
+
+	os.WriteFile(filepath.Join(clonePath, "local.txt"), []byte("local"), 0644)
+	exec.Command("git", "-C", clonePath, "add", "local.txt").Run()
+	exec.Command("git", "-C", clonePath, "-c", "user.name=Test User", "-c", "user.email=test@test.com", "commit", "-m", "local").Run()
+
+	err := FastForwardTo(clonePath, "origin", "main")
+	if _, ok := err.(*DivergedError); !ok {
+		t.Fatalf("expected DivergedError, got %T: %v", err, err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(clonePath, "test.txt"))
+	if string(content) != "one" {
+		t.Errorf("expected branch to be left alone, got %q", content)
+	}
+}
+
+func TestValidateRebaseOptions(t *testing.T) {
+	if err := ValidateRebaseOptions([]string{"--autosquash", "--update-refs"}); err != nil {
+		t.Errorf("expected allowed options to validate, got %v", err)
+	}
+	if err := ValidateRebaseOptions(nil); err != nil {
+		t.Errorf("expected no options to validate, got %v", err)
+	}
+	for _, option := range []string{"--exec=rm -rf /", "-i", "--onto", "autosquash"} {
+		if err := ValidateRebaseOptions([]string{option}); err == nil {
+			t.Errorf("expected %q to be rejected", option)
+		}
+	}
+}
+
+func TestRebaseOnto_Autosquash(t *testing.T) {
+	clonePath := setupFastForwardRepo(t)
+	commit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", clonePath, "-c", "user.name=Test User", "-c", "user.email=test@test.com", "commit"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, output)
+		}
+	}
+
+	os.WriteFile(filepath.Join(clonePath, "local.txt"), []byte("local"), 0644)
+	exec.Command("git", "-C", clonePath, "add", "local.txt").Run()
+	commit("-m", "local")
+	os.WriteFile(filepath.Join(clonePath, "local.txt"), []byte("local fixed"), 0644)
+	commit("-am", "fixup! local")
+
+	if err := RebaseOnto(clonePath, "origin", "main", "--autosquash"); err != nil {
+		t.Fatalf("RebaseOnto failed: %v", err)
+	}
+
+	output, err := exec.Command("git", "-C", clonePath, "log", "--format=%s", "origin/main..HEAD").Output()
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != "local" {
+		t.Errorf("expected the fixup to be squashed into 'local', got commits:\n%s", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/artisanexperiences/arbor/internal/concurrency"
 	"github.com/artisanexperiences/arbor/internal/config"
 	arborerrors "github.com/artisanexperiences/arbor/internal/errors"
 )
@@ -20,6 +21,31 @@ type Worktree struct {
 	IsMain    bool
 	IsCurrent bool
 	IsMerged  bool
+	// ScaffoldPending is read from the worktree's .arbor.local by callers
+	// that need it; the git helpers leave it false.
+	ScaffoldPending bool
+	// Detached worktrees have no branch; Head is the commit they are at.
+	Detached bool
+	Head     string
+	// Parent is the branch this one is stacked on, filled in from
+	// BranchParents by callers that need it.
+	Parent string
+	// DirMismatch is set by callers when the worktree's folder is no longer
+	// named after its branch, e.g. after 'git branch -m'.
+	DirMismatch bool
+}
+
+// Label returns the worktree's branch, or "(detached at <commit>)" for
+// detached worktrees.
+func (wt Worktree) Label() string {
+	if !wt.Detached {
+		return wt.Branch
+	}
+	head := wt.Head
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return fmt.Sprintf("(detached at %s)", head)
 }
 
 // CreateWorktree creates a new worktree from a branch
@@ -55,6 +81,17 @@ func CreateWorktree(barePath, worktreePath, branch, baseBranch string) error {
 	return nil
 }
 
+// SwitchToNewBranch checks out a new branch created at base in an existing
+// worktree. Files git ignores, such as vendor and node_modules, are kept.
+func SwitchToNewBranch(worktreePath, branch, base string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "switch", "--no-track", "-c", branch, base)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git switch failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
 // RemoveWorktree removes a worktree
 func RemoveWorktree(worktreePath string, force bool) error {
 	args := []string{"worktree", "remove"}
@@ -76,7 +113,63 @@ func RemoveWorktree(worktreePath string, force bool) error {
 	return nil
 }
 
-// ListWorktrees lists all worktrees in a bare repository
+// MoveWorktree moves a worktree to newPath with git worktree move, which
+// keeps git's worktree metadata pointing at it
+func MoveWorktree(barePath, worktreePath, newPath string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "-C", barePath, "worktree", "move", worktreePath, newPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outputStr := string(output)
+		if strings.Contains(outputStr, "cross-device") || strings.Contains(outputStr, "different disk drive") {
+			return &CrossDeviceMoveError{Output: outputStr}
+		}
+		return fmt.Errorf("git worktree move failed: %w\n%s", err, outputStr)
+	}
+	return nil
+}
+
+// CrossDeviceMoveError is returned by MoveWorktree when newPath is on
+// another filesystem, which git worktree move cannot rename across
+type CrossDeviceMoveError struct {
+	Output string
+}
+
+func (e *CrossDeviceMoveError) Error() string {
+	return "cannot move worktree to another filesystem with git worktree move\n" + e.Output
+}
+
+// RepairWorktree points git's metadata for a worktree at worktreePath after
+// its folder was moved without git worktree move
+func RepairWorktree(barePath, worktreePath string) error {
+	cmd := exec.Command("git", "-C", barePath, "worktree", "repair", worktreePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree repair failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// CreateDetachedWorktree creates a worktree on a detached HEAD at ref, which
+// may be a tag, a commit or a branch
+func CreateDetachedWorktree(barePath, worktreePath, ref string) error {
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "-C", barePath, "worktree", "add", "--detach", worktreePath, ref)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree add failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// ListWorktrees lists all worktrees in a bare repository, including
+// worktrees on a detached HEAD
 func ListWorktrees(barePath string) ([]Worktree, error) {
 	cmd := exec.Command("git", "-C", barePath, "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
@@ -88,7 +181,7 @@ func ListWorktrees(barePath string) ([]Worktree, error) {
 
 	var worktrees []Worktree
 	var currentPath string
-	var currentBranch string
+	var currentHead string
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -102,16 +195,27 @@ func ListWorktrees(barePath string) ([]Worktree, error) {
 			if !filepath.IsAbs(currentPath) && parentDir != "" {
 				currentPath = filepath.Join(parentDir, currentPath)
 			}
+			currentHead = ""
+		} else if strings.HasPrefix(line, "HEAD ") {
+			currentHead = strings.TrimSpace(strings.TrimPrefix(line, "HEAD "))
 		} else if strings.HasPrefix(line, "branch refs/heads/") {
-			currentBranch = strings.TrimPrefix(line, "branch refs/heads/")
+			currentBranch := strings.TrimPrefix(line, "branch refs/heads/")
 			currentBranch = strings.TrimSpace(currentBranch)
 			if currentPath != "" && currentBranch != "" {
 				worktrees = append(worktrees, Worktree{
 					Path:   currentPath,
 					Branch: currentBranch,
+					Head:   currentHead,
 				})
 				currentPath = ""
 			}
+		} else if line == "detached" && currentPath != "" {
+			worktrees = append(worktrees, Worktree{
+				Path:     currentPath,
+				Detached: true,
+				Head:     currentHead,
+			})
+			currentPath = ""
 		}
 	}
 
@@ -127,32 +231,31 @@ func ListWorktreesDetailed(barePath, currentWorktreePath, defaultBranch string)
 
 	currentWorktreePathEval, _ := filepath.EvalSymlinks(currentWorktreePath)
 
-	mergeStatusCache := make(map[string]bool)
-
 	for i := range worktrees {
 		wt := &worktrees[i]
 		wt.IsMain = wt.Branch == defaultBranch
 		wtPathEval, _ := filepath.EvalSymlinks(wt.Path)
 		wt.IsCurrent = wtPathEval == currentWorktreePathEval
-		if wt.Branch != defaultBranch {
-			cacheKey1 := wt.Branch + "->" + defaultBranch
-			featureInDefault, ok := mergeStatusCache[cacheKey1]
-			if !ok {
-				featureInDefault, err = IsMerged(barePath, wt.Branch, defaultBranch)
-				mergeStatusCache[cacheKey1] = featureInDefault
-			}
-			if err != nil {
-				wt.IsMerged = false
-				continue
-			}
-			cacheKey2 := defaultBranch + "->" + wt.Branch
-			defaultInFeature, ok := mergeStatusCache[cacheKey2]
-			if !ok {
-				defaultInFeature, err = IsMerged(barePath, defaultBranch, wt.Branch)
-				mergeStatusCache[cacheKey2] = defaultInFeature
-			}
-			wt.IsMerged = featureInDefault && !defaultInFeature
+	}
+
+	// Merge checks share a Batch, so branches at the same commit cost one
+	// git process between them, and the rest run in parallel. A branch whose
+	// status cannot be read counts as unmerged.
+	batch := NewBatch(barePath)
+	defer func() { _ = batch.Close() }()
+	merged, _ := concurrency.Map(worktrees, 0, func(wt Worktree) (bool, error) {
+		if wt.Detached || wt.Branch == defaultBranch {
+			return false, nil
 		}
+		featureInDefault, err := batch.IsAncestor(wt.Branch, defaultBranch)
+		if err != nil || !featureInDefault {
+			return false, err
+		}
+		defaultInFeature, err := batch.IsAncestor(defaultBranch, wt.Branch)
+		return !defaultInFeature, err
+	})
+	for i := range worktrees {
+		worktrees[i].IsMerged = merged[i]
 	}
 
 	return worktrees, nil
@@ -398,8 +501,154 @@ func FindBarePath(worktreePath string) (string, error) {
 
 		parent := filepath.Dir(current)
 		if parent == current {
-			return "", fmt.Errorf(".bare not found in %s or any parent directory: %w", absPath, arborerrors.ErrWorktreeNotFound)
+			break
 		}
 		current = parent
 	}
+
+	// Worktrees moved out of the project with 'arbor mv' find it through
+	// their .git file
+	if barePath, ok := bareFromGitFile(absPath); ok {
+		return barePath, nil
+	}
+	return "", fmt.Errorf(".bare not found in %s or any parent directory: %w", absPath, arborerrors.ErrWorktreeNotFound)
+}
+
+// TopLevel returns the root of the working tree containing path, for
+// plain clones such as CI checkouts that have no .bare.
+func TopLevel(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("finding repository root: %w", err)
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
+}
+
+// bareFromGitFile finds the nearest .git file at or above path and returns
+// the .bare repository it links the worktree to.
+func bareFromGitFile(path string) (string, bool) {
+	for current := path; ; current = filepath.Dir(current) {
+		content, err := os.ReadFile(filepath.Join(current, ".git"))
+		if err == nil {
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+			if !ok {
+				return "", false
+			}
+			// gitdir is <bare>/worktrees/<name>
+			gitDir = filepath.Clean(strings.TrimSpace(gitDir))
+			barePath := filepath.Dir(filepath.Dir(gitDir))
+			if filepath.Base(filepath.Dir(gitDir)) != "worktrees" || filepath.Base(barePath) != ".bare" {
+				return "", false
+			}
+			return barePath, true
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			// A .git directory: an ordinary repository, not an arbor worktree
+			return "", false
+		}
+		if filepath.Dir(current) == current {
+			return "", false
+		}
+	}
+}
+
+// HeadCommit returns the commit SHA checked out in a worktree
+func HeadCommit(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HeadCommitShort returns the abbreviated commit hash of worktreePath's HEAD.
+func HeadCommitShort(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HeadAuthor returns the author name of worktreePath's HEAD commit.
+func HeadAuthor(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "log", "-1", "--format=%an", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading HEAD author: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CreateBranch creates a branch pointing at commit
+func CreateBranch(barePath, branch, commit string) error {
+	cmd := exec.Command("git", "-C", barePath, "branch", branch, commit)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("creating branch: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// AddWorktreeNoCheckout links a worktree for an existing branch without
+// checking out any files, leaving worktreePath containing only .git
+func AddWorktreeNoCheckout(barePath, worktreePath, branch string) error {
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "-C", barePath, "worktree", "add", "--no-checkout", worktreePath, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree add failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// ResetIndex resets a worktree's index to HEAD, leaving files untouched
+func ResetIndex(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "reset", "--quiet")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git reset failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// InitBare creates an empty bare repository whose HEAD points at branch
+func InitBare(barePath, branch string) error {
+	if err := os.MkdirAll(barePath, 0755); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "init", "--bare", "--quiet", barePath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git init failed: %w\n%s", err, string(output))
+	}
+	cmd = exec.Command("git", "-C", barePath, "symbolic-ref", "HEAD", "refs/heads/"+branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("setting initial branch: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// CommitAll commits every non-ignored file in dir to the bare repository's
+// current branch, without dir needing to be a worktree
+func CommitAll(barePath, dir, message string) error {
+	gitArgs := []string{"--git-dir", barePath, "--work-tree", dir}
+
+	cmd := exec.Command("git", append(gitArgs, "add", "--all")...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %w\n%s", err, string(output))
+	}
+	cmd = exec.Command("git", append(gitArgs, "commit", "--quiet", "-m", message)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit failed: %w\n%s", err, string(output))
+	}
+	// The bare repository's index is only needed for the commit.
+	if err := os.Remove(filepath.Join(barePath, "index")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing index: %w", err)
+	}
+	return nil
 }
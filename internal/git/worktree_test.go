@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,7 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func createTestRepo(t *testing.T) (string, string) {
+func createTestRepo(t testing.TB) (string, string) {
 	tmpDir := t.TempDir()
 	repoDir := filepath.Join(tmpDir, "repo")
 	barePath := filepath.Join(tmpDir, ".bare")
@@ -374,6 +375,153 @@ func TestRemoveWorktree(t *testing.T) {
 	}
 }
 
+func TestSwitchToNewBranch(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	featurePath := filepath.Join(filepath.Dir(barePath), "feature")
+	if err := CreateWorktree(barePath, featurePath, "feature", "main"); err != nil {
+		t.Fatalf("creating feature worktree: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(featurePath, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(featurePath, ".gitignore"), []byte("vendor/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", ".gitignore"}, {"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "Ignore vendor"}} {
+		if output, err := exec.Command("git", append([]string{"-C", featurePath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	if err := SwitchToNewBranch(featurePath, "recycled", "main"); err != nil {
+		t.Fatalf("switching branch: %v", err)
+	}
+
+	branch, err := GetCurrentBranch(featurePath)
+	if err != nil {
+		t.Fatalf("getting current branch: %v", err)
+	}
+	assert.Equal(t, "recycled", branch)
+	assert.NoFileExists(t, filepath.Join(featurePath, ".gitignore"), "files of the old branch should be gone")
+	assert.DirExists(t, filepath.Join(featurePath, "vendor"))
+
+	if err := SwitchToNewBranch(featurePath, "recycled", "main"); err == nil {
+		t.Error("expected an error for an existing branch")
+	}
+}
+
+func TestMoveWorktree(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+
+	oldPath := filepath.Join(projectDir, "feature")
+	if err := CreateWorktree(barePath, oldPath, "feature", "main"); err != nil {
+		t.Fatalf("creating feature worktree: %v", err)
+	}
+
+	newPath := filepath.Join(projectDir, "renamed")
+	if err := MoveWorktree(barePath, oldPath, newPath); err != nil {
+		t.Fatalf("moving worktree: %v", err)
+	}
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("old worktree path should be gone")
+	}
+
+	worktrees, err := ListWorktrees(barePath)
+	if err != nil {
+		t.Fatalf("listing worktrees: %v", err)
+	}
+	found := false
+	for _, wt := range worktrees {
+		if wt.Branch == "feature" {
+			found = true
+			if filepath.Base(wt.Path) != "renamed" {
+				t.Errorf("expected feature worktree at %s, got %s", newPath, wt.Path)
+			}
+		}
+	}
+	if !found {
+		t.Error("feature worktree missing after move")
+	}
+}
+
+func TestCreateWorktreeFromTag(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+
+	if err := exec.Command("git", "-C", barePath, "tag", "v1.0.0").Run(); err != nil {
+		t.Fatalf("tagging: %v", err)
+	}
+	tagCommit, err := ResolveCommit(barePath, "v1.0.0")
+	if err != nil {
+		t.Fatalf("resolving tag: %v", err)
+	}
+
+	featurePath := filepath.Join(projectDir, "hotfix")
+	if err := CreateWorktree(barePath, featurePath, "hotfix", "v1.0.0"); err != nil {
+		t.Fatalf("creating worktree: %v", err)
+	}
+
+	head, err := HeadCommit(featurePath)
+	if err != nil {
+		t.Fatalf("reading HEAD: %v", err)
+	}
+	if head != tagCommit {
+		t.Errorf("expected worktree at %s, got %s", tagCommit, head)
+	}
+	if !BranchExists(barePath, "hotfix") {
+		t.Error("hotfix branch should be created from the tag")
+	}
+}
+
+func TestCreateDetachedWorktree(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+
+	if err := exec.Command("git", "-C", barePath, "tag", "v1.0.0").Run(); err != nil {
+		t.Fatalf("tagging: %v", err)
+	}
+
+	mainPath := filepath.Join(projectDir, "main")
+	if err := CreateWorktree(barePath, mainPath, "main", ""); err != nil {
+		t.Fatalf("creating main worktree: %v", err)
+	}
+	releasePath := filepath.Join(projectDir, "v1.0.0")
+	if err := CreateDetachedWorktree(barePath, releasePath, "v1.0.0"); err != nil {
+		t.Fatalf("creating detached worktree: %v", err)
+	}
+
+	worktrees, err := ListWorktreesDetailed(barePath, mainPath, "main")
+	if err != nil {
+		t.Fatalf("listing worktrees: %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("expected 2 worktrees, got %d: %+v", len(worktrees), worktrees)
+	}
+
+	var detached *Worktree
+	for i := range worktrees {
+		if worktrees[i].Detached {
+			detached = &worktrees[i]
+		}
+	}
+	if detached == nil {
+		t.Fatal("detached worktree should be listed")
+	}
+
+	tagCommit, _ := ResolveCommit(barePath, "v1.0.0")
+	if detached.Head != tagCommit {
+		t.Errorf("expected head %s, got %s", tagCommit, detached.Head)
+	}
+	if detached.Branch != "" || detached.IsMain || detached.IsMerged {
+		t.Errorf("detached worktree should have no branch or merge status: %+v", detached)
+	}
+	if want := "(detached at " + tagCommit[:7] + ")"; detached.Label() != want {
+		t.Errorf("expected label %q, got %q", want, detached.Label())
+	}
+}
+
 func TestCreateWorktreeBranchNaming(t *testing.T) {
 	barePath, _ := createTestRepo(t)
 	projectDir := filepath.Dir(barePath)
@@ -939,3 +1087,96 @@ func TestListWorktrees_PorcelainParsing_CurrentBehavior(t *testing.T) {
 	assert.NotNil(t, mainWt, "main worktree should exist")
 	assert.Equal(t, "main", mainWt.Branch)
 }
+
+func TestFindBarePath_WorktreeOutsideProject(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+
+	outside := filepath.Join(t.TempDir(), "feature")
+	if err := CreateWorktree(barePath, outside, "feature", "main"); err != nil {
+		t.Fatalf("creating feature worktree: %v", err)
+	}
+	subDir := filepath.Join(outside, "app", "Models")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := FindBarePath(subDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(barePath)
+	got, _ := filepath.EvalSymlinks(found)
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestFindBarePath_OrdinaryRepository(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := exec.Command("git", "init", repoDir).Run(); err != nil {
+		t.Fatalf("initializing git repo: %v", err)
+	}
+
+	if _, err := FindBarePath(repoDir); err == nil {
+		t.Error("expected error for a repository without .bare")
+	}
+}
+
+func TestRepairWorktree(t *testing.T) {
+	barePath, _ := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+
+	oldPath := filepath.Join(projectDir, "feature")
+	if err := CreateWorktree(barePath, oldPath, "feature", "main"); err != nil {
+		t.Fatalf("creating feature worktree: %v", err)
+	}
+
+	// Simulate a move that bypassed git worktree move
+	newPath := filepath.Join(t.TempDir(), "feature")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("moving worktree: %v", err)
+	}
+	if err := RepairWorktree(barePath, newPath); err != nil {
+		t.Fatalf("repairing worktree: %v", err)
+	}
+
+	if _, err := GetCurrentBranch(newPath); err != nil {
+		t.Errorf("moved worktree should be usable: %v", err)
+	}
+	worktrees, err := ListWorktrees(barePath)
+	if err != nil {
+		t.Fatalf("listing worktrees: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(newPath)
+	for _, wt := range worktrees {
+		if wt.Branch != "feature" {
+			continue
+		}
+		if got, _ := filepath.EvalSymlinks(wt.Path); got != want {
+			t.Errorf("expected feature worktree at %s, got %s", want, wt.Path)
+		}
+	}
+}
+
+func BenchmarkListWorktreesDetailed(b *testing.B) {
+	barePath, _ := createTestRepo(b)
+	projectDir := filepath.Dir(barePath)
+
+	mainPath := filepath.Join(projectDir, "main")
+	if err := CreateWorktree(barePath, mainPath, "main", ""); err != nil {
+		b.Fatalf("creating main worktree: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		branch := fmt.Sprintf("feature-%d", i)
+		if err := CreateWorktree(barePath, filepath.Join(projectDir, branch), branch, "main"); err != nil {
+			b.Fatalf("creating %s worktree: %v", branch, err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ListWorktreesDetailed(barePath, mainPath, "main"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
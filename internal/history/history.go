@@ -0,0 +1,102 @@
+// Package history keeps a per-project audit log of state-changing arbor
+// commands in <project>/.arbor/history.log, one JSON object per line.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// Results recorded for an invocation.
+const (
+	ResultOK        = "ok"
+	ResultError     = "error"
+	ResultCancelled = "cancelled"
+)
+
+// Entry is one recorded arbor invocation.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args"`
+	Dir     string    `json:"dir"`
+	// DurationMs is the wall-clock run time in milliseconds.
+	DurationMs int64  `json:"durationMs"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Path returns the history log for a project.
+func Path(projectPath string) string {
+	return filepath.Join(projectPath, ".arbor", "history.log")
+}
+
+// Append adds an entry to the project's history log.
+func Append(projectPath string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(Path(projectPath)), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(Path(projectPath), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening history log: %w", err)
+	}
+	defer f.Close()
+
+	// A single write of a short line keeps concurrent appends from
+	// interleaving.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing history log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the project's history, oldest first. Lines that cannot be
+// parsed are skipped.
+func Read(projectPath string) ([]Entry, error) {
+	f, err := os.Open(Path(projectPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening history log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading history log: %w", err)
+	}
+	return entries, nil
+}
+
+// CurrentUser returns the name recorded for the person running arbor.
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,63 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	projectPath := t.TempDir()
+	first := Entry{
+		Time:       time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+		User:       "taylor",
+		Command:    "work",
+		Args:       []string{"feature/auth"},
+		Dir:        "/code/app/main",
+		DurationMs: 1500,
+		Result:     ResultOK,
+	}
+	second := Entry{
+		Time:    time.Date(2026, 10, 17, 9, 45, 0, 0, time.UTC),
+		User:    "jess",
+		Command: "remove",
+		Args:    []string{"feature-auth", "--force"},
+		Result:  ResultError,
+		Error:   "worktree not found",
+	}
+
+	require.NoError(t, Append(projectPath, first))
+	require.NoError(t, Append(projectPath, second))
+
+	entries, err := Read(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{first, second}, entries)
+}
+
+func TestRead_MissingLog(t *testing.T) {
+	entries, err := Read(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRead_SkipsCorruptLines(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, Append(projectPath, Entry{Command: "gc", Result: ResultOK}))
+
+	f, err := os.OpenFile(Path(projectPath), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("{truncated\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, Append(projectPath, Entry{Command: "prune", Result: ResultOK}))
+
+	entries, err := Read(projectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "gc", entries[0].Command)
+	assert.Equal(t, "prune", entries[1].Command)
+}
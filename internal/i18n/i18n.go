@@ -0,0 +1,181 @@
+// Package i18n provides the message catalog for user-facing CLI text.
+//
+// Messages are looked up by key and formatted with fmt verbs. The English
+// catalog is embedded in the binary; community translations are YAML files
+// with the same keys, either embedded under locales/ or placed in
+// <global-config-dir>/locales/<locale>.yaml.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when no locale is configured or a key is missing.
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var embedded embed.FS
+
+// Catalog resolves message keys for a locale, falling back to the base
+// language and then to English.
+type Catalog struct {
+	locale   string
+	messages []map[string]string
+}
+
+var (
+	mu      sync.RWMutex
+	current = mustLoad(DetectLocale(os.Getenv), "")
+)
+
+// DetectLocale returns the locale from LC_ALL, LC_MESSAGES or LANG,
+// normalised to "ll" or "ll_CC". "C" and "POSIX" map to English.
+func DetectLocale(getenv func(string) string) string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(key); value != "" {
+			return NormalizeLocale(value)
+		}
+	}
+	return DefaultLocale
+}
+
+// NormalizeLocale strips encoding and modifier suffixes from a POSIX locale
+// name, e.g. "pt_BR.UTF-8" becomes "pt_BR" and "de-DE" becomes "de_DE".
+func NormalizeLocale(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i != -1 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// Load builds a catalog for locale. Translations in localesDir (if non-empty)
+// take precedence over embedded ones.
+func Load(locale, localesDir string) (*Catalog, error) {
+	locale = NormalizeLocale(locale)
+	c := &Catalog{locale: locale}
+
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "_"); found {
+		candidates = append(candidates, base)
+	}
+	if locale != DefaultLocale {
+		candidates = append(candidates, DefaultLocale)
+	}
+
+	for _, name := range candidates {
+		if localesDir != "" {
+			msgs, err := readCatalogFile(filepath.Join(localesDir, name+".yaml"))
+			if err != nil {
+				return nil, err
+			}
+			if msgs != nil {
+				c.messages = append(c.messages, msgs)
+			}
+		}
+		data, err := embedded.ReadFile("locales/" + name + ".yaml")
+		if err != nil {
+			continue
+		}
+		msgs, err := parseCatalog(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing embedded %s catalog: %w", name, err)
+		}
+		c.messages = append(c.messages, msgs)
+	}
+
+	return c, nil
+}
+
+func mustLoad(locale, localesDir string) *Catalog {
+	c, err := Load(locale, localesDir)
+	if err != nil {
+		panic(fmt.Sprintf("loading built-in message catalog: %v", err))
+	}
+	return c
+}
+
+func readCatalogFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading catalog %s: %w", path, err)
+	}
+	msgs, err := parseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing catalog %s: %w", path, err)
+	}
+	return msgs, nil
+}
+
+func parseCatalog(data []byte) (map[string]string, error) {
+	msgs := make(map[string]string)
+	if err := yaml.Unmarshal(data, &msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// Locale returns the catalog's normalised locale.
+func (c *Catalog) Locale() string {
+	return c.locale
+}
+
+// Lookup returns the raw message for key and whether it was found.
+func (c *Catalog) Lookup(key string) (string, bool) {
+	for _, msgs := range c.messages {
+		if msg, ok := msgs[key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// T formats the message for key with args. Unknown keys return the key
+// itself so missing translations are visible rather than silent.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	msg, ok := c.Lookup(key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// SetLocale replaces the active catalog.
+func SetLocale(locale, localesDir string) error {
+	c, err := Load(locale, localesDir)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	current = c
+	mu.Unlock()
+	return nil
+}
+
+// Current returns the active catalog.
+func Current() *Catalog {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T formats key using the active catalog.
+func T(key string, args ...interface{}) string {
+	return Current().T(key, args...)
+}
@@ -0,0 +1,145 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "unset", expected: "en"},
+		{name: "LANG with encoding", env: map[string]string{"LANG": "de_DE.UTF-8"}, expected: "de_DE"},
+		{name: "LC_MESSAGES over LANG", env: map[string]string{"LANG": "de_DE", "LC_MESSAGES": "fr_FR"}, expected: "fr_FR"},
+		{name: "LC_ALL over everything", env: map[string]string{"LANG": "de_DE", "LC_MESSAGES": "fr_FR", "LC_ALL": "es"}, expected: "es"},
+		{name: "C locale", env: map[string]string{"LANG": "C"}, expected: "en"},
+		{name: "POSIX locale", env: map[string]string{"LANG": "POSIX"}, expected: "en"},
+		{name: "modifier", env: map[string]string{"LANG": "sr_RS@latin"}, expected: "sr_RS"},
+		{name: "BCP 47 tag", env: map[string]string{"LANG": "pt-BR"}, expected: "pt_BR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.expected, DetectLocale(getenv))
+		})
+	}
+}
+
+func TestCatalog_T(t *testing.T) {
+	c, err := Load("en", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "List all worktrees", c.T("cmd.list.short"))
+	assert.Equal(t, "3 worktrees", c.T("table.worktrees.many", 3))
+	assert.Equal(t, "missing.key", c.T("missing.key"))
+}
+
+func TestLoad_UserCatalogFallback(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.yaml"), []byte(`cmd.list.short: "Alle Worktrees auflisten"
+table.worktrees.many: "%d Worktrees"
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de_AT.yaml"), []byte(`table.worktrees.many: "%d Arbeitsbäume"
+`), 0644))
+
+	c, err := Load("de_AT.UTF-8", dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "de_AT", c.Locale())
+	assert.Equal(t, "2 Arbeitsbäume", c.T("table.worktrees.many", 2), "region catalog wins")
+	assert.Equal(t, "Alle Worktrees auflisten", c.T("cmd.list.short"), "falls back to language catalog")
+	assert.Equal(t, "Sync current worktree with upstream branch", c.T("cmd.sync.short"), "falls back to English")
+}
+
+func TestLoad_InvalidUserCatalog(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("not: [valid"), 0644))
+
+	_, err := Load("fr", dir)
+	assert.Error(t, err)
+}
+
+func TestSetLocale(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nl.yaml"), []byte(`prompt.yes: "Ja"`), 0644))
+
+	previous := Current()
+	t.Cleanup(func() {
+		mu.Lock()
+		current = previous
+		mu.Unlock()
+	})
+
+	require.NoError(t, SetLocale("nl_NL", dir))
+	assert.Equal(t, "Ja", T("prompt.yes"))
+	assert.Equal(t, "No", T("prompt.no"))
+}
+
+// TestEnglishCatalogCoversSource ensures every literal key passed to T in the
+// codebase exists in the English catalog, so no user sees a raw key.
+func TestEnglishCatalogCoversSource(t *testing.T) {
+	c, err := Load(DefaultLocale, "")
+	require.NoError(t, err)
+
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	var missing []string
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "T" {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			key, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			if _, ok := c.Lookup(key); !ok {
+				missing = append(missing, fset.Position(lit.Pos()).String()+": "+key)
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, missing, "keys missing from locales/en.yaml")
+}
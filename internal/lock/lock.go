@@ -0,0 +1,93 @@
+// Package lock provides machine-wide named locks so scaffolds running in
+// parallel do not use a shared resource, such as the composer cache or
+// Herd, at the same time.
+//
+// Each lock is an OS file lock on <global config dir>/locks/<name>.lock.
+// The operating system releases it when the holding process exits, so a
+// crashed scaffold never leaves a stale lock behind.
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Lock is a held named lock.
+type Lock struct {
+	name string
+	file *os.File
+}
+
+// ValidateName checks that name can be used as a lock file name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("lock name must start with a letter or digit and contain only letters, digits, '.', '_' and '-', got %q", name)
+	}
+	return nil
+}
+
+// Dir returns the directory holding the lock files.
+func Dir() (string, error) {
+	configDir, err := config.GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "locks"), nil
+}
+
+// Acquire blocks until it holds the named lock. onWait, if non-nil, is
+// called once before blocking when another process holds the lock.
+func Acquire(name string, onWait func()) (*Lock, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, name+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock %s: %w", name, err)
+	}
+
+	locked, err := tryLockFile(file)
+	if err == nil && !locked {
+		if onWait != nil {
+			onWait()
+		}
+		err = lockFile(file)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("acquiring lock %s: %w", name, err)
+	}
+
+	return &Lock{name: name, file: file}, nil
+}
+
+// Name returns the lock's name.
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// Release releases the lock. The lock file is left in place; removing it
+// could let two processes lock different files of the same name.
+func (l *Lock) Release() error {
+	unlockErr := unlockFile(l.file)
+	closeErr := l.file.Close()
+	if unlockErr != nil {
+		return fmt.Errorf("releasing lock %s: %w", l.name, unlockErr)
+	}
+	return closeErr
+}
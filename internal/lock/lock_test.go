@@ -0,0 +1,87 @@
+package lock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"composer-cache", "herd", "npm_cache.v2", "9"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "-cache", ".hidden", "../etc", "a/b", "with space"} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestAcquire_CreatesLockFile(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	held, err := Acquire("composer-cache", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "composer-cache", held.Name())
+	assert.FileExists(t, filepath.Join(configDir, "arbor", "locks", "composer-cache.lock"))
+
+	require.NoError(t, held.Release())
+}
+
+func TestAcquire_WaitsForHolder(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	first, err := Acquire("herd", nil)
+	require.NoError(t, err)
+
+	waiting := make(chan struct{})
+	acquired := make(chan *Lock)
+	go func() {
+		second, err := Acquire("herd", func() { close(waiting) })
+		assert.NoError(t, err)
+		acquired <- second
+	}()
+
+	select {
+	case <-waiting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Acquire did not wait for the held lock")
+	}
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire succeeded while the lock was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Release())
+
+	select {
+	case second := <-acquired:
+		require.NotNil(t, second)
+		require.NoError(t, second.Release())
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Acquire did not get the lock after release")
+	}
+}
+
+func TestAcquire_IndependentNames(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	first, err := Acquire("composer-cache", nil)
+	require.NoError(t, err)
+	defer func() { _ = first.Release() }()
+
+	second, err := Acquire("npm-cache", func() { t.Error("unexpected wait for a different lock") })
+	require.NoError(t, err)
+	require.NoError(t, second.Release())
+}
+
+func TestAcquire_InvalidName(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	_, err := Acquire("../escape", nil)
+	assert.Error(t, err)
+}
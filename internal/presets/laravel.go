@@ -15,15 +15,31 @@ type Laravel struct {
 func NewLaravel() *Laravel {
 	return &Laravel{
 		basePreset: basePreset{
-			name: "laravel",
+			name:    "laravel",
+			version: 7,
 			defaultSteps: []config.StepConfig{
+				// storage and bootstrap/cache first: package:discover, which
+				// 'composer install' runs, writes to bootstrap/cache.
+				{Name: "laravel.permissions"},
 				{Name: "php.composer", Args: []string{"install"}, Condition: map[string]interface{}{"file_exists": "composer.lock"}},
 				{Name: "php.composer", Args: []string{"update"}, Condition: map[string]interface{}{"not": map[string]interface{}{"file_exists": "composer.lock"}}},
 				{Name: "file.copy", From: ".env.example", To: ".env"},
+				// The shared-app-key profile reuses the main worktree's
+				// APP_KEY, so its cookies and encrypted values stay valid;
+				// otherwise each worktree generates its own.
+				{Name: "env.copy_from_main", Keys: []string{"APP_KEY"}, Condition: map[string]interface{}{"profile": "shared-app-key"}},
 				{Name: "php.laravel", Args: []string{"key:generate", "--show", "--no-interaction"}, StoreAs: "AppKey", Condition: map[string]interface{}{"env_file_missing": "APP_KEY"}},
 				{Name: "env.write", Key: "APP_KEY", Value: "{{ .AppKey }}", Condition: map[string]interface{}{"env_file_missing": "APP_KEY"}},
+				// Reverb's app credentials, generated as 'reverb:install'
+				// does, before the frontend build reads them.
+				{Name: "php", Args: []string{"-r", "echo random_int(100000, 999999);"}, StoreAs: "ReverbAppId", Condition: reverbCondition("REVERB_APP_ID")},
+				{Name: "env.write", Key: "REVERB_APP_ID", Value: "{{ .ReverbAppId }}", Condition: reverbCondition("REVERB_APP_ID")},
+				{Name: "php", Args: []string{"-r", "echo bin2hex(random_bytes(10));"}, StoreAs: "ReverbAppKey", Condition: reverbCondition("REVERB_APP_KEY")},
+				{Name: "env.write", Key: "REVERB_APP_KEY", Value: "{{ .ReverbAppKey }}", Condition: reverbCondition("REVERB_APP_KEY")},
+				{Name: "php", Args: []string{"-r", "echo bin2hex(random_bytes(10));"}, StoreAs: "ReverbAppSecret", Condition: reverbCondition("REVERB_APP_SECRET")},
+				{Name: "env.write", Key: "REVERB_APP_SECRET", Value: "{{ .ReverbAppSecret }}", Condition: reverbCondition("REVERB_APP_SECRET")},
 				{Name: "db.create", Condition: map[string]interface{}{"env_file_contains": map[string]interface{}{"file": ".env", "key": "DB_CONNECTION"}}},
-				{Name: "env.write", Key: "DB_DATABASE", Value: "{{ .SanitizedSiteName }}_{{ .DbSuffix }}", Condition: map[string]interface{}{"env_file_contains": map[string]interface{}{"file": ".env", "key": "DB_CONNECTION"}}},
+				{Name: "env.write", Key: "DB_DATABASE", Value: "{{ .DbName }}", Condition: map[string]interface{}{"env_file_contains": map[string]interface{}{"file": ".env", "key": "DB_CONNECTION"}}},
 				{Name: "node.npm", Args: []string{"ci"}, Condition: map[string]interface{}{"file_exists": "package-lock.json"}},
 				{
 					Name: "php.laravel", Args: []string{"migrate:fresh", "--seed", "--no-interaction"},
@@ -36,18 +52,114 @@ func NewLaravel() *Laravel {
 						},
 					},
 				},
-				{Name: "node.npm", Args: []string{"run", "build"}, Condition: map[string]interface{}{"file_exists": "package-lock.json"}},
+				// The frontend build: Vite's build script, or build:ssr for
+				// Inertia apps that render on the server, which builds the
+				// client bundle too; Mix projects have no build script.
+				{
+					Name: "node.npm", Args: []string{"run", "build"},
+					Condition: map[string]interface{}{
+						"file_exists":     "package-lock.json",
+						"file_has_script": "build",
+						"not": map[string]interface{}{
+							"composer_has_package": "inertiajs/inertia-laravel",
+							"file_has_script":      "build:ssr",
+						},
+					},
+				},
+				{
+					Name: "node.npm", Args: []string{"run", "build:ssr"},
+					Condition: map[string]interface{}{
+						"file_exists":          "package-lock.json",
+						"composer_has_package": "inertiajs/inertia-laravel",
+						"file_has_script":      "build:ssr",
+					},
+				},
+				{
+					Name: "node.npm", Args: []string{"run", "production"},
+					Condition: map[string]interface{}{
+						"file_exists":      "package-lock.json",
+						"node_has_package": "laravel-mix",
+						"not":              map[string]interface{}{"file_has_script": "build"},
+					},
+				},
 				{Name: "php.laravel", Args: []string{"storage:link", "--no-interaction"}},
+				// Octane is installed once its package is required: the
+				// install writes config/octane.php and fetches the server.
+				{
+					Name: "php.laravel", Args: []string{"octane:install", "--no-interaction"}, ContinueOnError: true,
+					Condition: map[string]interface{}{
+						"composer_has_package": "laravel/octane",
+						"not":                  map[string]interface{}{"file_exists": "config/octane.php"},
+					},
+				},
+				{Name: "php.laravel", Args: []string{"horizon:publish", "--no-interaction"}, ContinueOnError: true, Condition: map[string]interface{}{"composer_has_package": "laravel/horizon"}},
+				// Projects that serve Livewire's assets from public/ publish
+				// them from a composer script, which 'composer install' does
+				// not run.
+				{
+					Name: "php.laravel", Args: []string{"vendor:publish", "--tag=livewire:assets", "--force", "--no-interaction"},
+					Condition: map[string]interface{}{
+						"composer_has_package": "livewire/livewire",
+						"file_contains":        map[string]interface{}{"file": "composer.json", "pattern": "livewire:assets"},
+					},
+				},
 				{Name: "herd", Args: []string{"link", "--secure", "{{ .SiteName }}"}},
+				// The scheduler profile runs the worktree's schedule every
+				// minute; cleanup removes the runner again.
+				{Name: "scheduler.register", Condition: map[string]interface{}{"profile": "scheduler"}},
+				// The testing profile makes the worktree test-ready: its own
+				// test database, named like the app's so db.destroy drops it,
+				// a .env.testing pointing at it, and a smoke run of the suite.
+				{Name: "db.create", Args: []string{"--prefix", "{{ .SanitizedSiteName }}_test", "--skip-migration-prompt"}, Condition: testDatabaseCondition()},
+				{Name: "file.copy", From: ".env", To: ".env.testing", Condition: map[string]interface{}{"profile": "testing", "not": map[string]interface{}{"file_exists": ".env.testing"}}},
+				{Name: "env.write", Key: "APP_ENV", Value: "testing", File: ".env.testing", Condition: map[string]interface{}{"profile": "testing", "file_exists": ".env.testing"}},
+				{Name: "env.write", Key: "DB_DATABASE", Value: "{{ .TestDbName }}", File: ".env.testing", Condition: testDatabaseCondition()},
+				{
+					Name: "php.laravel", Args: []string{"test", "--parallel", "--stop-on-failure", "-q"}, ContinueOnError: true,
+					Condition: map[string]interface{}{"profile": "testing", "file_exists": "vendor/brianium/paratest"},
+				},
+				{
+					Name: "php.laravel", Args: []string{"test", "--stop-on-failure", "-q"}, ContinueOnError: true,
+					Condition: map[string]interface{}{"profile": "testing", "not": map[string]interface{}{"file_exists": "vendor/brianium/paratest"}},
+				},
 			},
 			cleanupSteps: []config.CleanupStep{
 				{Name: "herd", Condition: nil},
 				{Name: "db.destroy", Condition: nil},
+				{Name: "scheduler.unregister", Condition: nil},
+			},
+			defaultChecks: []config.CheckConfig{
+				{Name: "http"},
+				{Name: "artisan.about"},
+				{Name: "db"},
+				{Name: "laravel.permissions"},
+				{Name: "queue", Optional: true},
+				{Name: "mail", Optional: true},
+				{Name: "files"},
 			},
 		},
 	}
 }
 
+// reverbCondition holds when the project requires Reverb and the env file
+// does not set key.
+func reverbCondition(key string) map[string]interface{} {
+	return map[string]interface{}{
+		"composer_has_package": "laravel/reverb",
+		"env_file_missing":     key,
+	}
+}
+
+// testDatabaseCondition holds when the testing profile is enabled and the
+// app uses a database server, which tests get a database of their own on.
+func testDatabaseCondition() map[string]interface{} {
+	return map[string]interface{}{
+		"profile":           "testing",
+		"env_file_contains": map[string]interface{}{"file": ".env", "key": "DB_CONNECTION"},
+		"not":               map[string]interface{}{"env_file_equals": map[string]interface{}{"file": ".env", "key": "DB_CONNECTION", "value": "sqlite"}},
+	}
+}
+
 func (p *Laravel) Detect(path string) bool {
 	composerPath := filepath.Join(path, "composer.json")
 	if _, err := os.Stat(composerPath); err != nil {
@@ -62,6 +174,19 @@ func (p *Laravel) Detect(path string) bool {
 	return true
 }
 
+// DevCommands prefers the composer dev script Laravel 11 ships, which runs
+// the server, queue worker, log tail and Vite together.
+func (p *Laravel) DevCommands(path string, served bool) []string {
+	if hasScript(path, "composer.json", "dev") {
+		return []string{"composer run dev"}
+	}
+	var commands []string
+	if !served {
+		commands = append(commands, "php artisan serve")
+	}
+	return append(commands, p.basePreset.DevCommands(path, served)...)
+}
+
 func (p *Laravel) Suggest(path string) string {
 	env := utils.ReadEnvFile(path, ".env")
 	if env["DB_CONNECTION"] != "" {
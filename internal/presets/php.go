@@ -14,12 +14,18 @@ type PHP struct {
 func NewPHP() *PHP {
 	return &PHP{
 		basePreset: basePreset{
-			name: "php",
+			name:    "php",
+			version: 1,
 			defaultSteps: []config.StepConfig{
 				{Name: "php.composer", Args: []string{"install"}, Condition: map[string]interface{}{"file_exists": "composer.lock"}},
 				{Name: "php.composer", Args: []string{"update"}, Condition: map[string]interface{}{"not": map[string]interface{}{"file_exists": "composer.lock"}}},
 			},
 			cleanupSteps: nil,
+			defaultChecks: []config.CheckConfig{
+				{Name: "http"},
+				{Name: "db"},
+				{Name: "files"},
+			},
 		},
 	}
 }
@@ -1,26 +1,46 @@
 package presets
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
 	"github.com/artisanexperiences/arbor/internal/config"
 )
 
 type Preset interface {
 	Name() string
+	// Version is raised whenever the preset's scaffold steps change, so
+	// worktrees scaffolded with an older version can be told about it.
+	Version() int
 	Detect(path string) bool
 	DefaultSteps() []config.StepConfig
 	CleanupSteps() []config.CleanupStep
+	// DevCommands returns the commands that start the development servers
+	// of the project at path. served reports whether Herd or Valet already
+	// serves it.
+	DevCommands(path string, served bool) []string
+	// DefaultChecks returns the health checks 'arbor check' runs when the
+	// project configures none.
+	DefaultChecks() []config.CheckConfig
 }
 
 type basePreset struct {
-	name         string
-	defaultSteps []config.StepConfig
-	cleanupSteps []config.CleanupStep
+	name          string
+	version       int
+	defaultSteps  []config.StepConfig
+	cleanupSteps  []config.CleanupStep
+	defaultChecks []config.CheckConfig
 }
 
 func (p *basePreset) Name() string {
 	return p.name
 }
 
+func (p *basePreset) Version() int {
+	return p.version
+}
+
 func (p *basePreset) DefaultSteps() []config.StepConfig {
 	return p.defaultSteps
 }
@@ -28,3 +48,32 @@ func (p *basePreset) DefaultSteps() []config.StepConfig {
 func (p *basePreset) CleanupSteps() []config.CleanupStep {
 	return p.cleanupSteps
 }
+
+func (p *basePreset) DefaultChecks() []config.CheckConfig {
+	return p.defaultChecks
+}
+
+// DevCommands runs the package.json dev script, when there is one.
+func (p *basePreset) DevCommands(path string, served bool) []string {
+	if hasScript(path, "package.json", "dev") {
+		return []string{"npm run dev"}
+	}
+	return nil
+}
+
+// hasScript reports whether the composer.json or package.json at path
+// defines a script called name.
+func hasScript(path, file, name string) bool {
+	data, err := os.ReadFile(filepath.Join(path, file))
+	if err != nil {
+		return false
+	}
+	var manifest struct {
+		Scripts map[string]any `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+	_, ok := manifest.Scripts[name]
+	return ok
+}
@@ -1,12 +1,20 @@
 package presets
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
 func TestLaravelPreset_Detect(t *testing.T) {
@@ -57,59 +65,281 @@ func TestLaravelPreset_Name(t *testing.T) {
 	assert.Equal(t, "laravel", preset.Name())
 }
 
+// findStep returns the index and config of the first step with want's name
+// and, when set, its args, key, file and destination. It fails the test
+// when no step matches.
+func findStep(t *testing.T, steps []config.StepConfig, want config.StepConfig) (int, config.StepConfig) {
+	t.Helper()
+	for i, step := range steps {
+		if step.Name == want.Name &&
+			(want.Args == nil || slices.Equal(step.Args, want.Args)) &&
+			(want.Key == "" || step.Key == want.Key) &&
+			(want.File == "" || step.File == want.File) &&
+			(want.To == "" || step.To == want.To) {
+			return i, step
+		}
+	}
+	require.FailNow(t, "step not found", "no %s step with args %v, key %q, file %q, to %q", want.Name, want.Args, want.Key, want.File, want.To)
+	return -1, config.StepConfig{}
+}
+
 func TestLaravelPreset_DefaultSteps(t *testing.T) {
 	preset := NewLaravel()
 	steps := preset.DefaultSteps()
 
-	assert.Len(t, steps, 12)
-
-	assert.Equal(t, "php.composer", steps[0].Name)
-	assert.Equal(t, []string{"install"}, steps[0].Args)
-	assert.Equal(t, "composer.lock", steps[0].Condition["file_exists"])
-
-	assert.Equal(t, "php.composer", steps[1].Name)
-	assert.Equal(t, []string{"update"}, steps[1].Args)
-	assert.NotNil(t, steps[1].Condition["not"])
-
-	assert.Equal(t, "file.copy", steps[2].Name)
-	assert.Equal(t, ".env.example", steps[2].From)
-	assert.Equal(t, ".env", steps[2].To)
-
-	assert.Equal(t, "php.laravel", steps[3].Name)
-	assert.Equal(t, []string{"key:generate", "--show", "--no-interaction"}, steps[3].Args)
-	assert.Equal(t, "AppKey", steps[3].StoreAs)
-
-	assert.Equal(t, "env.write", steps[4].Name)
-	assert.Equal(t, "APP_KEY", steps[4].Key)
-	assert.Equal(t, "{{ .AppKey }}", steps[4].Value)
-
-	assert.Equal(t, "db.create", steps[5].Name)
-
-	assert.Equal(t, "env.write", steps[6].Name)
-	assert.Equal(t, "DB_DATABASE", steps[6].Key)
-	assert.Equal(t, "{{ .SanitizedSiteName }}_{{ .DbSuffix }}", steps[6].Value)
-
-	assert.Equal(t, "node.npm", steps[7].Name)
-	assert.Equal(t, []string{"ci"}, steps[7].Args)
-	assert.NotNil(t, steps[7].Condition, "npm ci should have a condition")
-	assert.Equal(t, "package-lock.json", steps[7].Condition["file_exists"])
+	assert.Len(t, steps, 32)
+
+	assert.Equal(t, "laravel.permissions", steps[0].Name, "permissions are set before composer install writes bootstrap/cache")
+
+	composerInstall, step := findStep(t, steps, config.StepConfig{Name: "php.composer", Args: []string{"install"}})
+	assert.Equal(t, "composer.lock", step.Condition["file_exists"])
+	_, step = findStep(t, steps, config.StepConfig{Name: "php.composer", Args: []string{"update"}})
+	assert.NotNil(t, step.Condition["not"])
+
+	envCopy, step := findStep(t, steps, config.StepConfig{Name: "file.copy", To: ".env"})
+	assert.Equal(t, ".env.example", step.From)
+
+	_, step = findStep(t, steps, config.StepConfig{Name: "env.copy_from_main"})
+	assert.Equal(t, []string{"APP_KEY"}, step.Keys)
+	assert.Equal(t, "shared-app-key", step.Condition["profile"])
+
+	keyGenerate, step := findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"key:generate", "--show", "--no-interaction"}})
+	assert.Equal(t, "AppKey", step.StoreAs)
+	assert.Greater(t, keyGenerate, composerInstall, "artisan needs vendor/")
+	assert.Greater(t, keyGenerate, envCopy)
+	appKey, step := findStep(t, steps, config.StepConfig{Name: "env.write", Key: "APP_KEY"})
+	assert.Equal(t, "{{ .AppKey }}", step.Value)
+	assert.Greater(t, appKey, keyGenerate)
+
+	for _, key := range []string{"REVERB_APP_ID", "REVERB_APP_KEY", "REVERB_APP_SECRET"} {
+		i, write := findStep(t, steps, config.StepConfig{Name: "env.write", Key: key})
+		generate := steps[i-1]
+		assert.Equal(t, "php", generate.Name)
+		assert.Equal(t, "laravel/reverb", generate.Condition["composer_has_package"])
+		assert.Equal(t, "{{ ."+generate.StoreAs+" }}", write.Value)
+		assert.Equal(t, generate.Condition, write.Condition)
+	}
+
+	dbCreate, step := findStep(t, steps, config.StepConfig{Name: "db.create"})
+	assert.Empty(t, step.Args)
+	dbWrite, step := findStep(t, steps, config.StepConfig{Name: "env.write", Key: "DB_DATABASE"})
+	assert.Empty(t, step.File)
+	assert.Equal(t, "{{ .DbName }}", step.Value)
+	assert.Greater(t, dbWrite, dbCreate)
+
+	npmCI, step := findStep(t, steps, config.StepConfig{Name: "node.npm", Args: []string{"ci"}})
+	assert.Equal(t, "package-lock.json", step.Condition["file_exists"])
+
+	migrate, _ := findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"migrate:fresh", "--seed", "--no-interaction"}})
+	assert.Greater(t, migrate, dbWrite)
+
+	for _, script := range []string{"build", "build:ssr", "production"} {
+		build, _ := findStep(t, steps, config.StepConfig{Name: "node.npm", Args: []string{"run", script}})
+		assert.Greater(t, build, npmCI, script)
+	}
+
+	_, step = findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"octane:install", "--no-interaction"}})
+	assert.Equal(t, "laravel/octane", step.Condition["composer_has_package"])
+	_, step = findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"horizon:publish", "--no-interaction"}})
+	assert.Equal(t, "laravel/horizon", step.Condition["composer_has_package"])
+	findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"vendor:publish", "--tag=livewire:assets", "--force", "--no-interaction"}})
+	findStep(t, steps, config.StepConfig{Name: "herd"})
+	_, step = findStep(t, steps, config.StepConfig{Name: "scheduler.register"})
+	assert.Equal(t, "scheduler", step.Condition["profile"])
+
+	testDbCreate, _ := findStep(t, steps, config.StepConfig{Name: "db.create", Args: []string{"--prefix", "{{ .SanitizedSiteName }}_test", "--skip-migration-prompt"}})
+	testEnvCopy, _ := findStep(t, steps, config.StepConfig{Name: "file.copy", To: ".env.testing"})
+	findStep(t, steps, config.StepConfig{Name: "env.write", Key: "APP_ENV", File: ".env.testing"})
+	testDbWrite, step := findStep(t, steps, config.StepConfig{Name: "env.write", Key: "DB_DATABASE", File: ".env.testing"})
+	assert.Equal(t, "{{ .TestDbName }}", step.Value)
+	assert.Greater(t, testDbWrite, testDbCreate)
+	assert.Greater(t, testDbWrite, testEnvCopy)
+	parallel, step := findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"test", "--parallel", "--stop-on-failure", "-q"}})
+	assert.True(t, step.ContinueOnError)
+	serial, step := findStep(t, steps, config.StepConfig{Name: "php.laravel", Args: []string{"test", "--stop-on-failure", "-q"}})
+	assert.True(t, step.ContinueOnError)
+	assert.Greater(t, parallel, testDbWrite)
+	assert.Greater(t, serial, testDbWrite)
+	for _, step := range steps[testDbCreate:] {
+		assert.Equal(t, "testing", step.Condition["profile"])
+	}
+}
 
-	assert.Equal(t, "php.laravel", steps[8].Name)
-	assert.Equal(t, []string{"migrate:fresh", "--seed", "--no-interaction"}, steps[8].Args)
+func TestLaravelPreset_FrontendSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		composer string
+		pkg      string
+		want     []string
+	}{
+		{
+			name:     "vite",
+			composer: `{"require": {"laravel/framework": "^11.0"}}`,
+			pkg:      `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0"}}`,
+			want:     []string{"npm run build"},
+		},
+		{
+			name:     "inertia with ssr",
+			composer: `{"require": {"inertiajs/inertia-laravel": "^2.0"}}`,
+			pkg:      `{"scripts": {"build": "vite build", "build:ssr": "vite build && vite build --ssr"}}`,
+			want:     []string{"npm run build:ssr"},
+		},
+		{
+			name:     "inertia without ssr",
+			composer: `{"require": {"inertiajs/inertia-laravel": "^2.0"}}`,
+			pkg:      `{"scripts": {"build": "vite build"}}`,
+			want:     []string{"npm run build"},
+		},
+		{
+			name:     "ssr only",
+			composer: `{"require": {"inertiajs/inertia-laravel": "^2.0"}}`,
+			pkg:      `{"scripts": {"build:ssr": "vite build --ssr"}}`,
+			want:     []string{"npm run build:ssr"},
+		},
+		{
+			name:     "mix",
+			composer: `{"require": {"laravel/framework": "^8.0"}}`,
+			pkg:      `{"scripts": {"production": "mix --production"}, "devDependencies": {"laravel-mix": "^6.0"}}`,
+			want:     []string{"npm run production"},
+		},
+		{
+			name:     "livewire with published assets",
+			composer: `{"require": {"livewire/livewire": "^3.0"}, "scripts": {"post-update-cmd": ["@php artisan vendor:publish --tag=livewire:assets --ansi --force"]}}`,
+			pkg:      `{"scripts": {"build": "vite build"}}`,
+			want:     []string{"npm run build", "php artisan vendor:publish --tag=livewire:assets --force --no-interaction"},
+		},
+		{
+			name:     "livewire served by its route",
+			composer: `{"require": {"livewire/livewire": "^3.0"}}`,
+			pkg:      `{"scripts": {"build": "vite build"}}`,
+			want:     []string{"npm run build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "composer.json"), []byte(tt.composer), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(tt.pkg), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(`{}`), 0644))
+			ctx := &types.ScaffoldContext{WorktreePath: dir}
+
+			var run []string
+			for _, step := range NewLaravel().DefaultSteps() {
+				frontend := step.Name == "node.npm" && len(step.Args) > 0 && step.Args[0] == "run"
+				livewire := len(step.Args) > 0 && step.Args[0] == "vendor:publish"
+				if !frontend && !livewire {
+					continue
+				}
+				ok, err := ctx.EvaluateCondition(step.Condition)
+				require.NoError(t, err)
+				if ok {
+					binary := map[string]string{"node.npm": "npm", "php.laravel": "php artisan"}[step.Name]
+					run = append(run, binary+" "+strings.Join(step.Args, " "))
+				}
+			}
+			assert.Equal(t, tt.want, run)
+		})
+	}
+}
 
-	assert.Equal(t, "node.npm", steps[9].Name)
-	assert.Equal(t, []string{"run", "build"}, steps[9].Args)
-	assert.NotNil(t, steps[9].Condition, "npm run build should have a condition")
-	assert.Equal(t, "package-lock.json", steps[9].Condition["file_exists"])
+func TestLaravelPreset_TestingSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []string
+		env      string
+		paratest bool
+		want     []string
+	}{
+		{
+			name: "without the testing profile",
+			env:  "DB_CONNECTION=mysql\n",
+		},
+		{
+			name:     "database server",
+			profiles: []string{"testing"},
+			env:      "DB_CONNECTION=mysql\n",
+			paratest: true,
+			want:     []string{"db.create", "file.copy", "env.write APP_ENV", "env.write DB_DATABASE", "php.laravel test --parallel --stop-on-failure -q"},
+		},
+		{
+			name:     "sqlite",
+			profiles: []string{"testing"},
+			env:      "DB_CONNECTION=sqlite\n",
+			want:     []string{"file.copy", "env.write APP_ENV", "php.laravel test --stop-on-failure -q"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(tt.env), 0644))
+			if tt.paratest {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor", "brianium", "paratest"), 0755))
+			}
+			ctx := &types.ScaffoldContext{WorktreePath: dir, Profiles: tt.profiles}
+
+			var run []string
+			for _, step := range NewLaravel().DefaultSteps()[26:] {
+				ok, err := ctx.EvaluateCondition(step.Condition)
+				require.NoError(t, err)
+				if !ok {
+					continue
+				}
+				label := strings.TrimSpace(step.Name + " " + step.Key)
+				if step.Name == "php.laravel" {
+					label += " " + strings.Join(step.Args, " ")
+				}
+				run = append(run, label)
+				if step.Name == "file.copy" {
+					// The later steps depend on the copy existing.
+					require.NoError(t, os.WriteFile(filepath.Join(dir, step.To), []byte(tt.env), 0644))
+				}
+			}
+			assert.Equal(t, tt.want, run)
+		})
+	}
 }
 
 func TestLaravelPreset_CleanupSteps(t *testing.T) {
 	preset := NewLaravel()
 	steps := preset.CleanupSteps()
 
-	assert.Len(t, steps, 2)
+	assert.Len(t, steps, 3)
 	assert.Equal(t, "herd", steps[0].Name)
 	assert.Equal(t, "db.destroy", steps[1].Name)
+	assert.Equal(t, "scheduler.unregister", steps[2].Name)
+}
+
+func TestLaravelPreset_DevCommands(t *testing.T) {
+	t.Run("uses the composer dev script", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "composer.json"), []byte(`{"scripts": {"dev": ["npx concurrently"]}}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"scripts": {"dev": "vite"}}`), 0644))
+
+		assert.Equal(t, []string{"composer run dev"}, NewLaravel().DevCommands(tmpDir, false))
+	})
+
+	t.Run("serves with artisan unless Herd or Valet does", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"scripts": {"dev": "vite"}}`), 0644))
+
+		assert.Equal(t, []string{"php artisan serve", "npm run dev"}, NewLaravel().DevCommands(tmpDir, false))
+		assert.Equal(t, []string{"npm run dev"}, NewLaravel().DevCommands(tmpDir, true))
+	})
+}
+
+func TestPresets_DefaultChecks(t *testing.T) {
+	assert.Equal(t, []config.CheckConfig{
+		{Name: "http"},
+		{Name: "artisan.about"},
+		{Name: "db"},
+		{Name: "laravel.permissions"},
+		{Name: "queue", Optional: true},
+		{Name: "mail", Optional: true},
+		{Name: "files"},
+	}, NewLaravel().DefaultChecks())
+	assert.Equal(t, []config.CheckConfig{{Name: "http"}, {Name: "db"}, {Name: "files"}}, NewPHP().DefaultChecks())
 }
 
 func TestPHPPreset_Detect(t *testing.T) {
@@ -157,6 +387,32 @@ func TestPHPPreset_CleanupSteps(t *testing.T) {
 	assert.Nil(t, steps)
 }
 
+// TestPresets_Version pins each preset's scaffold steps to its version:
+// worktrees scaffolded with an older version are told to run
+// 'arbor scaffold --upgrade' only when the version changes. After changing
+// a preset's steps, raise its version and update the hash here.
+func TestPresets_Version(t *testing.T) {
+	tests := []struct {
+		preset  Preset
+		version int
+		hash    string
+	}{
+		{NewLaravel(), 7, "34d48872ec4992f0"},
+		{NewPHP(), 1, "75390bbcc95e3c31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset.Name(), func(t *testing.T) {
+			data, err := json.Marshal(tt.preset.DefaultSteps())
+			require.NoError(t, err)
+			sum := sha256.Sum256(data)
+
+			assert.Equal(t, tt.version, tt.preset.Version())
+			assert.Equal(t, tt.hash, hex.EncodeToString(sum[:8]), "the steps changed: raise the preset's version")
+		})
+	}
+}
+
 func TestManager_RegisterAndGet(t *testing.T) {
 	m := NewManager()
 
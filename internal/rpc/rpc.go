@@ -0,0 +1,275 @@
+// Package rpc implements the JSON-RPC 2.0 protocol spoken by 'arbor serve'.
+// Messages are JSON objects, one per line, in both directions. Requests on
+// a connection are handled concurrently, so a long call such as a scaffold
+// does not hold up others; a handler streams progress to its caller as
+// notifications while it runs.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+)
+
+// Version is the JSON-RPC version of every message.
+const Version = "2.0"
+
+// Error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the first of the codes JSON-RPC leaves to
+	// servers; arbor uses it for operations that ran and failed.
+	CodeServerError = -32000
+)
+
+// maxMessageSize bounds a single request line.
+const maxMessageSize = 4 << 20
+
+// Request is a call or, without an ID, a notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a Request that has an ID.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is a message the server sends without expecting an answer,
+// such as progress of a running call.
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Error is a JSON-RPC error. Handlers return one to choose the code sent to
+// the client; any other error is sent as CodeInternalError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InvalidParams returns a CodeInvalidParams error.
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler answers one method. The returned value is the call's result.
+type Handler func(ctx context.Context, call *Call) (any, error)
+
+// Call is a request being handled.
+type Call struct {
+	Method string
+	Params json.RawMessage
+	id     json.RawMessage
+	conn   *conn
+}
+
+// Decode unmarshals the call's params into v. Missing params leave v
+// unchanged.
+func (c *Call) Decode(v any) error {
+	if len(c.Params) == 0 || bytes.Equal(c.Params, []byte("null")) {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(c.Params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return InvalidParams("invalid params for %s: %v", c.Method, err)
+	}
+	return nil
+}
+
+// Notify sends a notification to the caller while the call runs. The
+// call's ID is added to params as "id", so clients can tell which call it
+// belongs to.
+func (c *Call) Notify(method string, params map[string]any) error {
+	if params == nil {
+		params = make(map[string]any)
+	}
+	if c.id != nil {
+		params["id"] = c.id
+	}
+	return c.conn.write(Notification{JSONRPC: Version, Method: method, Params: params})
+}
+
+// Server dispatches requests to handlers by method name.
+type Server struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewServer returns a server with no methods.
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]Handler)}
+}
+
+// Handle registers h for method, replacing any earlier handler.
+func (s *Server) Handle(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+// Methods returns the registered method names, sorted.
+func (s *Server) Methods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	methods := make([]string, 0, len(s.handlers))
+	for method := range s.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Serve accepts connections on l until ctx is done or l fails, serving
+// each on its own goroutine. It closes l before returning.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = c.Close() }()
+			_ = s.ServeConn(ctx, c)
+		}()
+	}
+}
+
+// ServeConn reads requests from rw until it is closed or ctx is done, and
+// waits for calls in flight before returning. When rw is an io.Closer it
+// is closed once ctx is done, to unblock the read.
+func (s *Server) ServeConn(ctx context.Context, rw io.ReadWriter) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if closer, ok := rw.(io.Closer); ok {
+		stop := context.AfterFunc(ctx, func() { _ = closer.Close() })
+		defer stop()
+	}
+
+	c := &conn{w: rw}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(rw)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			_ = c.write(Response{JSONRPC: Version, ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: fmt.Sprintf("parse error: %v", err)}})
+			continue
+		}
+		if req.JSONRPC != Version || req.Method == "" {
+			_ = c.write(Response{JSONRPC: Version, ID: nullIfEmpty(req.ID), Error: &Error{Code: CodeInvalidRequest, Message: "invalid request"}})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.dispatch(ctx, c, req)
+		}()
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("reading request: %w", err)
+	}
+	return nil
+}
+
+// dispatch runs the handler for req and writes its response. Requests
+// without an ID are notifications and get no response.
+func (s *Server) dispatch(ctx context.Context, c *conn, req Request) {
+	s.mu.RLock()
+	handler, ok := s.handlers[req.Method]
+	s.mu.RUnlock()
+
+	var result any
+	var err error
+	if ok {
+		result, err = handler(ctx, &Call{Method: req.Method, Params: req.Params, id: req.ID, conn: c})
+	} else {
+		err = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	if req.ID == nil {
+		return
+	}
+	resp := Response{JSONRPC: Version, ID: req.ID, Result: result}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	} else if result == nil {
+		resp.Result = struct{}{}
+	}
+	_ = c.write(resp)
+}
+
+func nullIfEmpty(id json.RawMessage) json.RawMessage {
+	if id == nil {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+// conn serialises writes from concurrent calls on one connection.
+type conn struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (c *conn) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	data = append(data, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(data)
+	return err
+}
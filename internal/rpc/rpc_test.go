@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// client sends raw lines to a server over an in-memory connection and
+// reads its messages back.
+type client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func newClient(t *testing.T, s *Server) *client {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.ServeConn(ctx, serverConn)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		_ = clientConn.Close()
+		<-done
+	})
+	return &client{conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+}
+
+func (c *client) send(t *testing.T, line string) {
+	t.Helper()
+	_, err := c.conn.Write([]byte(line + "\n"))
+	require.NoError(t, err)
+}
+
+func (c *client) read(t *testing.T) map[string]any {
+	t.Helper()
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.True(t, c.scanner.Scan(), "expected a message: %v", c.scanner.Err())
+	var msg map[string]any
+	require.NoError(t, json.Unmarshal(c.scanner.Bytes(), &msg))
+	return msg
+}
+
+func TestServer_Call(t *testing.T) {
+	s := NewServer()
+	s.Handle("add", func(ctx context.Context, call *Call) (any, error) {
+		var params struct{ A, B int }
+		if err := call.Decode(&params); err != nil {
+			return nil, err
+		}
+		return params.A + params.B, nil
+	})
+	c := newClient(t, s)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"add","params":{"a":2,"b":3}}`)
+	assert.Equal(t, map[string]any{"jsonrpc": "2.0", "id": 1.0, "result": 5.0}, c.read(t))
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := NewServer()
+	s.Handle("fail", func(ctx context.Context, call *Call) (any, error) {
+		return nil, errors.New("something broke")
+	})
+	s.Handle("strict", func(ctx context.Context, call *Call) (any, error) {
+		var params struct{ Name string }
+		return nil, call.Decode(&params)
+	})
+	c := newClient(t, s)
+
+	errorCode := func(msg map[string]any) float64 {
+		t.Helper()
+		require.Contains(t, msg, "error", msg)
+		return msg["error"].(map[string]any)["code"].(float64)
+	}
+
+	c.send(t, `not json`)
+	assert.Equal(t, float64(CodeParseError), errorCode(c.read(t)))
+
+	c.send(t, `{"id":1,"method":"fail"}`)
+	assert.Equal(t, float64(CodeInvalidRequest), errorCode(c.read(t)))
+
+	c.send(t, `{"jsonrpc":"2.0","id":2,"method":"missing"}`)
+	assert.Equal(t, float64(CodeMethodNotFound), errorCode(c.read(t)))
+
+	c.send(t, `{"jsonrpc":"2.0","id":3,"method":"fail"}`)
+	msg := c.read(t)
+	assert.Equal(t, float64(CodeInternalError), errorCode(msg))
+	assert.Equal(t, "something broke", msg["error"].(map[string]any)["message"])
+
+	c.send(t, `{"jsonrpc":"2.0","id":4,"method":"strict","params":{"other":1}}`)
+	assert.Equal(t, float64(CodeInvalidParams), errorCode(c.read(t)))
+}
+
+func TestServer_NotifyAndNotifications(t *testing.T) {
+	s := NewServer()
+	s.Handle("work", func(ctx context.Context, call *Call) (any, error) {
+		for i := 1; i <= 2; i++ {
+			if err := call.Notify("progress", map[string]any{"step": i}); err != nil {
+				return nil, err
+			}
+		}
+		return "done", nil
+	})
+	c := newClient(t, s)
+
+	// A request without an ID gets no response.
+	c.send(t, `{"jsonrpc":"2.0","method":"work"}`)
+	assert.Equal(t, "progress", c.read(t)["method"])
+	assert.Equal(t, "progress", c.read(t)["method"])
+
+	c.send(t, `{"jsonrpc":"2.0","id":"abc","method":"work"}`)
+	assert.Equal(t, map[string]any{"jsonrpc": "2.0", "method": "progress", "params": map[string]any{"id": "abc", "step": 1.0}}, c.read(t))
+	assert.Equal(t, map[string]any{"jsonrpc": "2.0", "method": "progress", "params": map[string]any{"id": "abc", "step": 2.0}}, c.read(t))
+	assert.Equal(t, map[string]any{"jsonrpc": "2.0", "id": "abc", "result": "done"}, c.read(t))
+}
+
+func TestServer_ConcurrentCalls(t *testing.T) {
+	release := make(chan struct{})
+	s := NewServer()
+	s.Handle("slow", func(ctx context.Context, call *Call) (any, error) {
+		<-release
+		return "slow", nil
+	})
+	s.Handle("fast", func(ctx context.Context, call *Call) (any, error) {
+		return "fast", nil
+	})
+	c := newClient(t, s)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"slow"}`)
+	c.send(t, `{"jsonrpc":"2.0","id":2,"method":"fast"}`)
+	assert.Equal(t, "fast", c.read(t)["result"], "a slow call does not hold up others")
+	close(release)
+	assert.Equal(t, "slow", c.read(t)["result"])
+}
+
+func TestServer_Serve(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "arbor.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	s := NewServer()
+	s.Handle("ping", func(ctx context.Context, call *Call) (any, error) {
+		return "pong", nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx, l) }()
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	c := &client{conn: conn, scanner: bufio.NewScanner(conn)}
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	assert.Equal(t, "pong", c.read(t)["result"])
+	require.NoError(t, conn.Close())
+
+	cancel()
+	assert.NoError(t, <-served)
+	assert.Equal(t, []string{"ping"}, s.Methods())
+}
@@ -366,3 +366,83 @@ func TestConditionEvaluator_fileHasScript(t *testing.T) {
 		assert.False(t, result)
 	})
 }
+
+func TestConditionEvaluator_composerHasPackage(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := &types.ScaffoldContext{
+		WorktreePath: tmpDir,
+		Branch:       "test-branch",
+		Preset:       "laravel",
+		Env:          make(map[string]string),
+	}
+
+	t.Run("composer.json does not exist", func(t *testing.T) {
+		result, err := ctx.EvaluateCondition(map[string]interface{}{"composer_has_package": "laravel/horizon"})
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "composer.json"), []byte(`{
+		"description": "Uses laravel/octane behind a proxy",
+		"require": {"php": "^8.2", "Laravel/Horizon": "^5.0"},
+		"require-dev": {"laravel/reverb": "^1.0"},
+		"suggest": {"laravel/pulse": "For monitoring"}
+	}`), 0644))
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bool
+	}{
+		{"required package, ignoring case", "laravel/horizon", true},
+		{"dev package", "laravel/reverb", true},
+		{"every package of a list", []interface{}{"laravel/horizon", "laravel/reverb"}, true},
+		{"a list with a missing package", []interface{}{"laravel/horizon", "laravel/pulse"}, false},
+		{"mentioned but not required", "laravel/octane", false},
+		{"a longer package name", "laravel/horizon-watcher", false},
+		{"map form", map[string]interface{}{"package": "laravel/reverb"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ctx.EvaluateCondition(map[string]interface{}{"composer_has_package": tt.value})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+
+	t.Run("invalid composer.json", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "composer.json"), []byte(`{"require": `), 0644))
+
+		_, err := ctx.EvaluateCondition(map[string]interface{}{"composer_has_package": "laravel/horizon"})
+		assert.ErrorContains(t, err, "parsing composer.json")
+	})
+}
+
+func TestConditionEvaluator_nodeHasPackage(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := &types.ScaffoldContext{WorktreePath: tmpDir, Env: make(map[string]string)}
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{
+		"scripts": {"production": "mix --production"},
+		"dependencies": {"vue": "^3.4"},
+		"devDependencies": {"laravel-mix": "^6.0"}
+	}`), 0644))
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bool
+	}{
+		{"dependency", "vue", true},
+		{"dev dependency", "laravel-mix", true},
+		{"script name", "production", false},
+		{"missing package", "vite", false},
+		{"every package of a list", []interface{}{"vue", "laravel-mix"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ctx.EvaluateCondition(map[string]interface{}{"node_has_package": tt.value})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
@@ -1,28 +1,96 @@
 package scaffold
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"strings"
 	"sync"
+	"time"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/lock"
+	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/ui"
 )
 
 type ExecutionResult struct {
+	// ID is the step's stable ID; see PlannedStep.
+	ID      string
 	Step    types.ScaffoldStep
 	Error   error
 	Skipped bool
+	// SkipReason is why a skipped step was skipped.
+	SkipReason string
+	// Duration is how long the step ran; it is zero for skipped steps
+	// and dry runs.
+	Duration time.Duration
+	// Continued is set when the step failed but was marked
+	// continue_on_error, so the run carried on.
+	Continued bool
+}
+
+// Reasons a planned step is skipped.
+const (
+	SkipDisabled     = "disabled"
+	SkipConditionNot = "condition not met"
+)
+
+// PlannedStep is a step's place in a run. ID combines the step's position,
+// name and a hash of its configuration, so the same step in the same
+// config has the same ID in every run, and results can be matched to it.
+type PlannedStep struct {
+	ID         string
+	Index      int
+	Step       types.ScaffoldStep
+	SkipReason string
+	// err is why the step's condition could not be evaluated; the step
+	// fails when it is reached.
+	err error
+}
+
+// Skipped reports whether the plan skips the step.
+func (p PlannedStep) Skipped() bool {
+	return p.SkipReason != ""
+}
+
+// Step statuses reported in a StepEvent.
+const (
+	StepStarted   = "started"
+	StepCompleted = "completed"
+	StepSkipped   = "skipped"
+	StepFailed    = "failed"
+)
+
+// StepEvent reports a step starting or finishing, for callers that show
+// progress themselves instead of reading the terminal output. Current and
+// Total count the steps that run; skipped steps have a Current of 0.
+type StepEvent struct {
+	ID      string
+	Step    types.ScaffoldStep
+	Status  string
+	Current int
+	Total   int
+	// Reason is why a skipped step was skipped.
+	Reason string
+	Error  error
 }
 
 type StepExecutor struct {
-	steps        []types.ScaffoldStep
-	ctx          *types.ScaffoldContext
-	opts         types.StepOptions
-	results      []ExecutionResult
-	mu           sync.Mutex
-	completedCnt int
-	skippedCnt   int
+	steps           []types.ScaffoldStep
+	ctx             *types.ScaffoldContext
+	opts            types.StepOptions
+	results         []ExecutionResult
+	mu              sync.Mutex
+	completedCnt    int
+	skippedCnt      int
+	failedCnt       int
+	continueOnError map[int]bool
+	locks           map[int]string
+	conditions      map[int]map[string]interface{}
+	configHashes    map[int]string
+	progress        func(StepEvent)
 }
 
 func NewStepExecutor(steps []types.ScaffoldStep, ctx *types.ScaffoldContext, opts types.StepOptions) *StepExecutor {
@@ -33,134 +101,225 @@ func NewStepExecutor(steps []types.ScaffoldStep, ctx *types.ScaffoldContext, opt
 	}
 }
 
+// SetStepConfig applies the configuration the step at index was created
+// from: its condition, continue_on_error and lock settings, and the hash
+// in its ID.
+func (e *StepExecutor) SetStepConfig(index int, cfg config.StepConfig) {
+	if e.configHashes == nil {
+		e.configHashes = make(map[int]string)
+	}
+	e.configHashes[index] = configHash(cfg)
+	if len(cfg.Condition) > 0 {
+		e.SetCondition(index, cfg.Condition)
+	}
+	if cfg.ContinueOnError {
+		e.SetContinueOnError(index)
+	}
+	if cfg.Lock != "" {
+		e.SetLock(index, cfg.Lock)
+	}
+}
+
+// SetContinueOnError marks the step at index as best-effort: if it fails,
+// the failure is recorded as a warning and the remaining steps still run.
+func (e *StepExecutor) SetContinueOnError(index int) {
+	if e.continueOnError == nil {
+		e.continueOnError = make(map[int]bool)
+	}
+	e.continueOnError[index] = true
+}
+
+// SetCondition makes the step at index run only when condition holds, as
+// well as the step's own Condition.
+func (e *StepExecutor) SetCondition(index int, condition map[string]interface{}) {
+	if e.conditions == nil {
+		e.conditions = make(map[int]map[string]interface{})
+	}
+	e.conditions[index] = condition
+}
+
+// SetLock makes the step at index hold the named machine-wide lock while
+// it runs.
+func (e *StepExecutor) SetLock(index int, name string) {
+	if e.locks == nil {
+		e.locks = make(map[int]string)
+	}
+	e.locks[index] = name
+}
+
+// SetProgress makes the executor report each step's progress to fn.
+func (e *StepExecutor) SetProgress(fn func(StepEvent)) {
+	e.progress = fn
+}
+
+// Plan evaluates each step's enabled flag and condition once, in order.
+func (e *StepExecutor) Plan() []PlannedStep {
+	plan := make([]PlannedStep, 0, len(e.steps))
+	for i, step := range e.steps {
+		planned := PlannedStep{ID: e.stepID(i, step), Index: i, Step: step}
+		if !isStepEnabled(step) {
+			planned.SkipReason = SkipDisabled
+		} else {
+			e.checkCondition(&planned)
+		}
+		plan = append(plan, planned)
+	}
+	return plan
+}
+
+// ValidatePlan runs, in order, the plan checks of the steps that would
+// run, as a dry run does, and returns their problems keyed by step index.
+// Steps that check their plan may set variables for the ones after them,
+// such as the database name db.create would use.
+func (e *StepExecutor) ValidatePlan() map[int]error {
+	problems := make(map[int]error)
+	for _, planned := range e.Plan() {
+		if planned.err != nil {
+			problems[planned.Index] = planned.err
+			continue
+		}
+		if planned.Skipped() {
+			continue
+		}
+		if validator, ok := planned.Step.(types.PlanValidator); ok {
+			if err := validator.ValidatePlan(e.ctx, e.opts); err != nil {
+				problems[planned.Index] = err
+			}
+		}
+	}
+	return problems
+}
+
+// checkCondition skips the planned step when its configured condition or
+// its own Condition does not hold.
+func (e *StepExecutor) checkCondition(planned *PlannedStep) {
+	planned.SkipReason = ""
+	planned.err = nil
+	if condition := e.conditions[planned.Index]; len(condition) > 0 {
+		met, err := e.ctx.EvaluateCondition(condition)
+		if err != nil {
+			planned.err = fmt.Errorf("evaluating condition: %w", err)
+			return
+		}
+		if !met {
+			planned.SkipReason = SkipConditionNot
+			return
+		}
+	}
+	if !planned.Step.Condition(e.ctx) {
+		planned.SkipReason = SkipConditionNot
+	}
+}
+
+// Execute runs the planned steps in order: preset steps first, followed by
+// config steps. Once a step has run, the decisions planned for later
+// steps are checked again, since a condition may depend on what an earlier
+// step created, such as a copied .env.
 func (e *StepExecutor) Execute() error {
+	// Database connections shared between steps are closed once the run
+	// ends.
+	defer func() { _ = e.ctx.CloseClients() }()
+
+	e.ctx.StartConditionCache()
+	defer e.ctx.StopConditionCache()
+
 	e.results = make([]ExecutionResult, 0, len(e.steps))
 	e.completedCnt = 0
 	e.skippedCnt = 0
+	e.failedCnt = 0
+
+	plan := e.Plan()
 
 	// Count active steps for progress tracking
-	activeSteps := e.countActiveSteps()
+	activeSteps := 0
+	for _, planned := range plan {
+		if !planned.Skipped() {
+			activeSteps++
+		}
+	}
 	currentStep := 0
-
-	// Execute steps sequentially in the order they were provided
-	// Preset steps come first, followed by config steps
-	for _, step := range e.steps {
-		// Check if step is enabled
-		enabled := true
-		if stepConfig, ok := step.(interface{ IsEnabled() bool }); ok {
-			enabled = stepConfig.IsEnabled()
+	stale := false
+
+	for _, planned := range plan {
+		if stale && planned.SkipReason != SkipDisabled {
+			wasSkipped := planned.Skipped()
+			e.checkCondition(&planned)
+			if wasSkipped && !planned.Skipped() {
+				activeSteps++
+			} else if !wasSkipped && planned.Skipped() {
+				activeSteps--
+			}
 		}
 
-		if !enabled {
-			e.mu.Lock()
-			e.results = append(e.results, ExecutionResult{
-				Step:    step,
-				Skipped: true,
-			})
-			e.skippedCnt++
-			e.mu.Unlock()
+		step := planned.Step
+		if planned.Skipped() {
+			e.recordResult(ExecutionResult{ID: planned.ID, Step: step, Skipped: true, SkipReason: planned.SkipReason})
+			e.report(StepEvent{ID: planned.ID, Step: step, Status: StepSkipped, Total: activeSteps, Reason: planned.SkipReason})
 			if e.opts.Verbose {
-				fmt.Printf("Skipping step (disabled): %s\n", step.Name())
+				fmt.Printf("Skipping step (%s): %s\n", planned.SkipReason, step.Name())
 			}
 			continue
 		}
 
-		// Check condition
-		if !step.Condition(e.ctx) {
-			e.mu.Lock()
-			e.results = append(e.results, ExecutionResult{
-				Step:    step,
-				Skipped: true,
-			})
-			e.skippedCnt++
-			e.mu.Unlock()
-			if e.opts.Verbose {
-				fmt.Printf("Skipping step (condition not met): %s\n", step.Name())
+		// Increment current step counter
+		currentStep++
+		e.report(StepEvent{ID: planned.ID, Step: step, Status: StepStarted, Current: currentStep, Total: activeSteps})
+
+		if planned.err != nil {
+			e.report(StepEvent{ID: planned.ID, Step: step, Status: StepFailed, Current: currentStep, Total: activeSteps, Error: planned.err})
+			if err := e.recordFailure(planned, planned.err, 0); err != nil {
+				return err
 			}
 			continue
 		}
 
-		// Increment current step counter
-		currentStep++
+		if e.opts.DryRun {
+			if validator, ok := step.(types.PlanValidator); ok {
+				if err := validator.ValidatePlan(e.ctx, e.opts); err != nil {
+					e.report(StepEvent{ID: planned.ID, Step: step, Status: StepFailed, Current: currentStep, Total: activeSteps, Error: err})
+					if err := e.recordFailure(planned, err, 0); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+
+			if e.opts.Verbose {
+				fmt.Printf("[%d/%d] Executing step: %s\n", currentStep, activeSteps, step.Name())
+				fmt.Printf("[DRY-RUN] Would execute: %s\n", step.Name())
+			} else if !e.opts.Quiet {
+				fmt.Printf("[DRY-RUN] [%d/%d] Would execute: %s\n", currentStep, activeSteps, getStepDescription(step))
+			}
+			e.recordResult(ExecutionResult{ID: planned.ID, Step: step})
+			e.report(StepEvent{ID: planned.ID, Step: step, Status: StepCompleted, Current: currentStep, Total: activeSteps})
+			continue
+		}
 
 		// Execute the step based on mode
+		run := func() error { return step.Run(e.ctx, e.opts) }
 		if e.opts.Verbose {
 			// Verbose mode: print detailed output
 			fmt.Printf("[%d/%d] Executing step: %s\n", currentStep, activeSteps, step.Name())
-
-			if e.opts.DryRun {
-				fmt.Printf("[DRY-RUN] Would execute: %s\n", step.Name())
-				e.mu.Lock()
-				e.results = append(e.results, ExecutionResult{
-					Step: step,
-				})
-				e.completedCnt++
-				e.mu.Unlock()
-			} else {
-				if err := step.Run(e.ctx, e.opts); err != nil {
-					e.mu.Lock()
-					e.results = append(e.results, ExecutionResult{
-						Step:  step,
-						Error: err,
-					})
-					e.mu.Unlock()
-					return fmt.Errorf("step %s failed: %w", step.Name(), err)
-				}
-				e.mu.Lock()
-				e.results = append(e.results, ExecutionResult{
-					Step: step,
-				})
-				e.completedCnt++
-				e.mu.Unlock()
-				fmt.Printf("✓ [%d/%d] %s completed\n", currentStep, activeSteps, step.Name())
-			}
 		} else if !e.opts.Quiet {
 			// Normal mode: use spinner
-			if e.opts.DryRun {
-				desc := getStepDescription(step)
-				fmt.Printf("[DRY-RUN] [%d/%d] Would execute: %s\n", currentStep, activeSteps, desc)
-				e.mu.Lock()
-				e.results = append(e.results, ExecutionResult{
-					Step: step,
-				})
-				e.completedCnt++
-				e.mu.Unlock()
-			} else {
-				if err := e.executeWithSpinner(step, currentStep, activeSteps); err != nil {
-					e.mu.Lock()
-					e.results = append(e.results, ExecutionResult{
-						Step:  step,
-						Error: err,
-					})
-					e.mu.Unlock()
-					return fmt.Errorf("step %s failed: %w", step.Name(), err)
-				}
-				e.mu.Lock()
-				e.results = append(e.results, ExecutionResult{
-					Step: step,
-				})
-				e.completedCnt++
-				e.mu.Unlock()
-			}
-		} else {
-			// Quiet mode: silent execution
-			if !e.opts.DryRun {
-				if err := step.Run(e.ctx, e.opts); err != nil {
-					e.mu.Lock()
-					e.results = append(e.results, ExecutionResult{
-						Step:  step,
-						Error: err,
-					})
-					e.mu.Unlock()
-					return fmt.Errorf("step %s failed: %w", step.Name(), err)
-				}
+			run = func() error { return e.executeWithSpinner(step, currentStep, activeSteps) }
+		}
+
+		started := time.Now()
+		err := e.runStep(planned.Index, run)
+		duration := time.Since(started)
+		stale = true
+		if err != nil {
+			e.report(StepEvent{ID: planned.ID, Step: step, Status: StepFailed, Current: currentStep, Total: activeSteps, Error: err})
+			if err := e.recordFailure(planned, err, duration); err != nil {
+				return err
 			}
-			e.mu.Lock()
-			e.results = append(e.results, ExecutionResult{
-				Step: step,
-			})
-			e.completedCnt++
-			e.mu.Unlock()
+			continue
+		}
+		e.recordResult(ExecutionResult{ID: planned.ID, Step: step, Duration: duration})
+		e.report(StepEvent{ID: planned.ID, Step: step, Status: StepCompleted, Current: currentStep, Total: activeSteps})
+		if e.opts.Verbose {
+			fmt.Printf("✓ [%d/%d] %s completed\n", currentStep, activeSteps, step.Name())
 		}
 	}
 
@@ -172,94 +331,164 @@ func (e *StepExecutor) Execute() error {
 	return nil
 }
 
+// report passes ev to the progress callback, if there is one.
+func (e *StepExecutor) report(ev StepEvent) {
+	if e.progress != nil {
+		e.progress(ev)
+	}
+}
+
+// recordResult records a completed or skipped step.
+func (e *StepExecutor) recordResult(result ExecutionResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.results = append(e.results, result)
+	if result.Skipped {
+		e.skippedCnt++
+	} else {
+		e.completedCnt++
+	}
+}
+
 func (e *StepExecutor) Results() []ExecutionResult {
 	return e.results
 }
 
-// getStepDescription returns a friendly description for a step
-func getStepDescription(step types.ScaffoldStep) string {
-	stepName := step.Name()
+// runStep runs a step through fn. Steps may create or change files, so
+// cached results of conditions reading the worktree are dropped after.
+func (e *StepExecutor) runStep(index int, fn func() error) error {
+	defer e.ctx.InvalidateFileConditions()
+	return e.withStepLock(index, fn)
+}
 
-	// Map common steps to friendly descriptions
-	descriptions := map[string]string{
-		"php.composer.install": "Installing composer dependencies",
-		"php.composer.update":  "Updating composer dependencies",
-		"node.npm.install":     "Installing npm packages",
-		"node.npm.run":         "Running npm script",
-		"node.yarn.install":    "Installing yarn packages",
-		"node.pnpm.install":    "Installing pnpm packages",
-		"node.bun":             "Running bun",
-		"file.copy":            "Copying files",
-		"file.template":        "Processing template files",
-		"env.read":             "Reading environment variables",
-		"env.write":            "Writing environment variables",
-		"db.create":            "Creating database",
-		"db.destroy":           "Destroying database",
-		"bash.run":             "Running bash command",
-		"command.run":          "Running command",
-		"herd":                 "Managing Herd",
+// withStepLock runs fn while holding the step's lock, if it has one.
+// Waiting for a lock held by another scaffold is reported so a slow step
+// is not mistaken for a hung one.
+func (e *StepExecutor) withStepLock(index int, fn func() error) error {
+	name := e.locks[index]
+	if name == "" {
+		return fn()
 	}
 
-	baseDesc := descriptions[stepName]
-
-	// For Laravel artisan commands, try to extract the command name
-	if stepName == "php.laravel" {
-		// Try to get the args from the step
-		if argGetter, ok := step.(interface{ GetArgs() []string }); ok {
-			args := argGetter.GetArgs()
-			if len(args) > 0 {
-				// Extract the command (first part before any arguments)
-				cmdPart := strings.Split(args[0], " ")[0]
-				baseDesc = fmt.Sprintf("Running artisan %s", cmdPart)
-			}
-		}
-		if baseDesc == "" {
-			baseDesc = "Running artisan command"
+	held, err := lock.Acquire(name, func() {
+		if !e.opts.Quiet {
+			ui.PrintInfo(fmt.Sprintf("Waiting for lock %q held by another scaffold...", name))
 		}
+	})
+	if err != nil {
+		return err
 	}
+	defer func() { _ = held.Release() }()
 
-	// For npm run, try to extract the script name
-	if stepName == "node.npm.run" {
-		if argGetter, ok := step.(interface{ GetArgs() []string }); ok {
-			args := argGetter.GetArgs()
-			if len(args) > 0 {
-				baseDesc = fmt.Sprintf("Running npm %s", args[0])
-			}
-		}
+	return fn()
+}
+
+// recordFailure records a failed step. Failures of continue_on_error steps
+// become warnings and return nil so the run carries on; any other failure
+// is returned to abort the run.
+func (e *StepExecutor) recordFailure(planned PlannedStep, err error, duration time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	step := planned.Step
+	continued := e.continueOnError[planned.Index]
+	e.results = append(e.results, ExecutionResult{
+		ID:        planned.ID,
+		Step:      step,
+		Error:     err,
+		Duration:  duration,
+		Continued: continued,
+	})
+	if !continued {
+		return fmt.Errorf("step %s failed: %w", step.Name(), err)
 	}
 
-	// For herd commands, try to extract the subcommand
-	if stepName == "herd" {
-		if argGetter, ok := step.(interface{ GetArgs() []string }); ok {
-			args := argGetter.GetArgs()
-			if len(args) > 0 {
-				baseDesc = fmt.Sprintf("Running herd %s", args[0])
-			}
-		}
+	e.failedCnt++
+	e.ctx.AddWarning(fmt.Sprintf("%s failed (continue_on_error): %v", step.Name(), err))
+	if e.opts.Verbose {
+		fmt.Printf("✗ %s failed, continuing\n", step.Name())
 	}
+	return nil
+}
 
-	// If no description found, use the step name
-	if baseDesc == "" {
-		baseDesc = fmt.Sprintf("Running %s", stepName)
+// FailedCount returns the number of continue_on_error steps that failed
+// during the last Execute.
+func (e *StepExecutor) FailedCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.failedCnt
+}
+
+// defaultSteps documents the built-in steps for StepDescription.
+var defaultSteps = sync.OnceValue(func() *steps.Registry {
+	registry := steps.NewRegistry()
+	registry.RegisterDefaults()
+	return registry
+})
+
+// StepDescription returns the registered description of a built-in step,
+// or "" for unknown steps.
+func StepDescription(name string) string {
+	info, _ := defaultSteps().Info(name)
+	return info.Description
+}
+
+// getStepDescription describes a step for progress output: what the
+// configured step does when it says, otherwise its registered description,
+// followed by its name.
+func getStepDescription(step types.ScaffoldStep) string {
+	stepName := step.Name()
+
+	var desc string
+	if describer, ok := step.(types.Describer); ok {
+		desc = describer.Description()
+	}
+	if desc == "" {
+		desc = StepDescription(stepName)
+	}
+	if desc == "" {
+		desc = fmt.Sprintf("Running %s", stepName)
 	}
 
-	return fmt.Sprintf("%s (%s)", baseDesc, stepName)
+	return fmt.Sprintf("%s (%s)", desc, stepName)
 }
 
-// countActiveSteps counts steps that will actually run (not skipped)
-func (e *StepExecutor) countActiveSteps() int {
-	count := 0
-	for _, step := range e.steps {
-		enabled := true
-		if stepConfig, ok := step.(interface{ IsEnabled() bool }); ok {
-			enabled = stepConfig.IsEnabled()
-		}
+// stepID returns the stable ID of the step at index.
+func (e *StepExecutor) stepID(index int, step types.ScaffoldStep) string {
+	hash, ok := e.configHashes[index]
+	if !ok {
+		hash = configHash(nil)
+	}
+	return fmt.Sprintf("%d:%s:%s", index, step.Name(), hash)
+}
 
-		if enabled && step.Condition(e.ctx) {
-			count++
-		}
+// configHash returns a short hash of a step's configuration.
+func configHash(cfg interface{}) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", cfg))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:4])
+}
+
+// isStepEnabled reports whether a step is enabled. Steps that do not
+// expose IsEnabled are always enabled.
+func isStepEnabled(step types.ScaffoldStep) bool {
+	if stepConfig, ok := step.(interface{ IsEnabled() bool }); ok {
+		return stepConfig.IsEnabled()
 	}
-	return count
+	return true
+}
+
+// disabledStep is a step whose config sets enabled: false. The executor
+// skips it, and reports it as disabled.
+type disabledStep struct {
+	types.ScaffoldStep
+}
+
+func (disabledStep) IsEnabled() bool {
+	return false
 }
 
 // executeWithSpinner runs a step with a spinner showing progress
@@ -285,7 +514,7 @@ func (e *StepExecutor) printSummary() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if e.completedCnt > 0 || e.skippedCnt > 0 {
+	if e.completedCnt > 0 || e.skippedCnt > 0 || e.failedCnt > 0 {
 		summary := fmt.Sprintf("%d step", e.completedCnt)
 		if e.completedCnt != 1 {
 			summary += "s"
@@ -296,6 +525,17 @@ func (e *StepExecutor) printSummary() {
 			summary += fmt.Sprintf(", %d skipped", e.skippedCnt)
 		}
 
-		ui.PrintSuccess(summary)
+		// Best-effort failures do not abort the run but should not read
+		// as a clean success either.
+		if e.failedCnt > 0 {
+			summary += fmt.Sprintf(", %d failed with warnings", e.failedCnt)
+			ui.PrintWarning(summary)
+		} else {
+			ui.PrintSuccess(summary)
+		}
+	}
+
+	for _, warning := range e.ctx.Warnings() {
+		ui.PrintWarning(warning)
 	}
 }
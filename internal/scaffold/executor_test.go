@@ -1,10 +1,16 @@
 package scaffold
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/lock"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
@@ -70,6 +76,76 @@ func TestStepExecutor_Execute_ConditionFalse(t *testing.T) {
 	assert.False(t, step2.runCalled)
 }
 
+// fileConditionStep runs only when its file exists and creates another.
+type fileConditionStep struct {
+	requires  string
+	creates   string
+	runCalled bool
+}
+
+func (s *fileConditionStep) Name() string {
+	return "file.step"
+}
+
+func (s *fileConditionStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	s.runCalled = true
+	if s.creates == "" {
+		return nil
+	}
+	return os.WriteFile(filepath.Join(ctx.WorktreePath, s.creates), nil, 0644)
+}
+
+func (s *fileConditionStep) Condition(ctx *types.ScaffoldContext) bool {
+	if s.requires == "" {
+		return true
+	}
+	result, err := ctx.EvaluateCondition(map[string]interface{}{"file_exists": s.requires})
+	return err == nil && result
+}
+
+func TestStepExecutor_Execute_ConditionsSeeEarlierSteps(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+
+	creator := &fileConditionStep{creates: ".env"}
+	dependent := &fileConditionStep{requires: ".env"}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{creator, dependent}, ctx, types.StepOptions{Quiet: true})
+
+	assert.NoError(t, executor.Execute())
+	assert.True(t, creator.runCalled)
+	assert.True(t, dependent.runCalled, "condition cached before the file was created should be re-evaluated")
+}
+
+func TestStepExecutor_Execute_ConfiguredCondition(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+
+	gated := &mockStep{name: "env.write", conditionResult: true}
+	ungated := &mockStep{name: "env.write", conditionResult: true}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{gated, ungated}, ctx, types.StepOptions{Quiet: true})
+	executor.SetStepConfig(0, config.StepConfig{Name: "env.write", Condition: map[string]interface{}{"file_exists": ".env"}})
+	executor.SetStepConfig(1, config.StepConfig{Name: "env.write"})
+
+	assert.NoError(t, executor.Execute())
+	assert.False(t, gated.runCalled, "a step whose configured condition fails is skipped")
+	assert.True(t, ungated.runCalled)
+}
+
+func TestStepExecutor_Execute_ConditionError(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "composer.json"), []byte("{"), 0644))
+	ctx := &types.ScaffoldContext{WorktreePath: dir}
+
+	step := &mockStep{name: "php.laravel", conditionResult: true}
+	executor := NewStepExecutor([]types.ScaffoldStep{step}, ctx, types.StepOptions{Quiet: true})
+	executor.SetCondition(0, map[string]interface{}{"composer_has_package": "laravel/horizon"})
+
+	err := executor.Execute()
+
+	assert.ErrorContains(t, err, "evaluating condition")
+	assert.False(t, step.runCalled)
+}
+
 func TestStepExecutor_Execute_StepFails(t *testing.T) {
 	ctx := &types.ScaffoldContext{
 		WorktreePath: "/tmp",
@@ -90,6 +166,82 @@ func TestStepExecutor_Execute_StepFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "step2 failed")
 }
 
+func TestStepExecutor_Execute_ContinueOnError(t *testing.T) {
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/tmp",
+		Branch:       "test",
+	}
+
+	step1 := &mockStep{name: "step1", conditionResult: true, runError: assert.AnError}
+	step2 := &mockStep{name: "step2", conditionResult: true}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{Quiet: true})
+	executor.SetContinueOnError(0)
+
+	err := executor.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, step2.runCalled, "steps after a continue_on_error failure should still run")
+	assert.Equal(t, 1, executor.FailedCount())
+
+	results := executor.Results()
+	assert.Len(t, results, 2)
+	assert.ErrorIs(t, results[0].Error, assert.AnError)
+	assert.True(t, results[0].Continued)
+	assert.NoError(t, results[1].Error)
+
+	assert.Len(t, ctx.Warnings(), 1)
+	assert.Contains(t, ctx.Warnings()[0], "step1 failed (continue_on_error)")
+}
+
+func TestStepExecutor_Execute_ContinueOnErrorOnlyMarkedSteps(t *testing.T) {
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/tmp",
+		Branch:       "test",
+	}
+
+	step1 := &mockStep{name: "step1", conditionResult: true, runError: assert.AnError}
+	step2 := &mockStep{name: "step2", conditionResult: true}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{Quiet: true})
+	executor.SetContinueOnError(1)
+
+	err := executor.Execute()
+
+	assert.Error(t, err)
+	assert.False(t, step2.runCalled)
+	assert.Equal(t, 0, executor.FailedCount())
+	assert.Empty(t, ctx.Warnings())
+}
+
+func TestStepExecutor_Execute_HoldsStepLock(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/tmp",
+		Branch:       "test",
+	}
+
+	step1 := &mockStep{name: "step1", conditionResult: true}
+	step2 := &mockStep{name: "step2", conditionResult: true}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{Quiet: true})
+	executor.SetLock(0, "composer-cache")
+
+	err := executor.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, step1.runCalled)
+	assert.True(t, step2.runCalled)
+	assert.FileExists(t, filepath.Join(configDir, "arbor", "locks", "composer-cache.lock"))
+
+	// The lock is released once the step finishes.
+	held, err := lock.Acquire("composer-cache", func() { t.Error("lock still held after the run") })
+	assert.NoError(t, err)
+	assert.NoError(t, held.Release())
+}
+
 func TestStepExecutor_Execute_DryRun(t *testing.T) {
 	ctx := &types.ScaffoldContext{
 		WorktreePath: "/tmp",
@@ -109,6 +261,87 @@ func TestStepExecutor_Execute_DryRun(t *testing.T) {
 	assert.False(t, step1.runCalled)
 }
 
+// validatingStep is a step that fails plan validation.
+type validatingStep struct {
+	mockStep
+	validateErr error
+}
+
+func (s *validatingStep) ValidatePlan(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	return s.validateErr
+}
+
+func TestStepExecutor_Execute_DryRunValidatesPlan(t *testing.T) {
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/tmp",
+		Branch:       "test",
+	}
+
+	step1 := &validatingStep{mockStep: mockStep{name: "step1", conditionResult: true}, validateErr: errors.New("name too long")}
+	step2 := &mockStep{name: "step2", conditionResult: true}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{
+		DryRun: true,
+		Quiet:  true,
+	})
+
+	err := executor.Execute()
+
+	assert.ErrorContains(t, err, "step step1 failed: name too long")
+	assert.False(t, step1.runCalled)
+
+	// Validation only runs for dry runs.
+	executor = NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{Quiet: true})
+	assert.NoError(t, executor.Execute())
+	assert.True(t, step1.runCalled)
+}
+
+func TestStepExecutor_ValidatePlan(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+	failing := &validatingStep{mockStep: mockStep{name: "step1", conditionResult: true}, validateErr: errors.New("name too long")}
+	skipped := &validatingStep{mockStep: mockStep{name: "step2", conditionResult: false}, validateErr: errors.New("not checked")}
+	passing := &validatingStep{mockStep: mockStep{name: "step3", conditionResult: true}}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{failing, skipped, passing, &mockStep{name: "step4", conditionResult: true}}, ctx, types.StepOptions{DryRun: true, Quiet: true})
+	problems := executor.ValidatePlan()
+
+	assert.Equal(t, map[int]error{0: failing.validateErr}, problems)
+	assert.False(t, failing.runCalled || passing.runCalled, "no step runs")
+}
+
+// closerFunc adapts a function to io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+// clientStep opens a shared client when run.
+type clientStep struct {
+	mockStep
+	closed *bool
+}
+
+func (s *clientStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	_, err := ctx.SharedClient("db", func() (io.Closer, error) {
+		return closerFunc(func() error { *s.closed = true; return nil }), nil
+	})
+	return err
+}
+
+func TestStepExecutor_Execute_ClosesSharedClients(t *testing.T) {
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/tmp",
+		Branch:       "test",
+	}
+
+	closed := false
+	step := &clientStep{mockStep: mockStep{name: "step1", conditionResult: true}, closed: &closed}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step}, ctx, types.StepOptions{Quiet: true})
+
+	assert.NoError(t, executor.Execute())
+	assert.True(t, closed, "shared clients should be closed when the run ends")
+}
+
 func TestStepExecutor_Results(t *testing.T) {
 	ctx := &types.ScaffoldContext{
 		WorktreePath: "/tmp",
@@ -287,3 +520,149 @@ func TestStepExecutor_LaravelPresetStepOrdering(t *testing.T) {
 	assert.Equal(t, "php.laravel storage:link", results[7].Step.Name())
 	assert.Equal(t, "herd", results[8].Step.Name())
 }
+
+// countingStep counts how often its condition is evaluated.
+type countingStep struct {
+	mockStep
+	conditionCalls int
+}
+
+func (s *countingStep) Condition(ctx *types.ScaffoldContext) bool {
+	s.conditionCalls++
+	return s.conditionResult
+}
+
+func TestStepExecutor_Plan(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+	steps := []types.ScaffoldStep{
+		&mockStep{name: "step1", conditionResult: true},
+		&mockStep{name: "step2", conditionResult: false},
+	}
+
+	executor := NewStepExecutor(steps, ctx, types.StepOptions{Quiet: true})
+	executor.SetStepConfig(0, config.StepConfig{Name: "step1", Args: []string{"install"}})
+	plan := executor.Plan()
+
+	assert.Len(t, plan, 2)
+	assert.False(t, plan[0].Skipped())
+	assert.Equal(t, SkipConditionNot, plan[1].SkipReason)
+	assert.Regexp(t, `^0:step1:[0-9a-f]{8}$`, plan[0].ID)
+	assert.Regexp(t, `^1:step2:[0-9a-f]{8}$`, plan[1].ID)
+
+	t.Run("IDs are stable across runs", func(t *testing.T) {
+		again := NewStepExecutor(steps, ctx, types.StepOptions{Quiet: true})
+		again.SetStepConfig(0, config.StepConfig{Name: "step1", Args: []string{"install"}})
+		assert.Equal(t, plan[0].ID, again.Plan()[0].ID)
+	})
+
+	t.Run("IDs change with the step's config", func(t *testing.T) {
+		changed := NewStepExecutor(steps, ctx, types.StepOptions{Quiet: true})
+		changed.SetStepConfig(0, config.StepConfig{Name: "step1", Args: []string{"update"}})
+		assert.NotEqual(t, plan[0].ID, changed.Plan()[0].ID)
+	})
+
+	t.Run("results carry the planned IDs", func(t *testing.T) {
+		assert.NoError(t, executor.Execute())
+		results := executor.Results()
+		assert.Equal(t, plan[0].ID, results[0].ID)
+		assert.Equal(t, plan[1].ID, results[1].ID)
+		assert.True(t, results[1].Skipped)
+	})
+}
+
+func TestStepExecutor_Execute_EvaluatesConditionsOnce(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+	step1 := &countingStep{mockStep: mockStep{name: "step1", conditionResult: true}}
+	step2 := &countingStep{mockStep: mockStep{name: "step2", conditionResult: false}}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{step1, step2}, ctx, types.StepOptions{DryRun: true, Quiet: true})
+	assert.NoError(t, executor.Execute())
+
+	assert.Equal(t, 1, step1.conditionCalls)
+	assert.Equal(t, 1, step2.conditionCalls)
+}
+
+func TestStepExecutor_Execute_RechecksPlanAfterStepsRun(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+	if err := os.WriteFile(filepath.Join(ctx.WorktreePath, ".env"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	remover := &removingStep{path: filepath.Join(ctx.WorktreePath, ".env")}
+	dependent := &fileConditionStep{requires: ".env"}
+
+	executor := NewStepExecutor([]types.ScaffoldStep{remover, dependent}, ctx, types.StepOptions{Quiet: true})
+	assert.False(t, executor.Plan()[1].Skipped())
+
+	assert.NoError(t, executor.Execute())
+	assert.False(t, dependent.runCalled, "a step whose condition stopped holding should be skipped")
+	assert.Equal(t, SkipConditionNot, executor.Plan()[1].SkipReason)
+}
+
+// removingStep deletes a file when run.
+type removingStep struct {
+	path string
+}
+
+func (s *removingStep) Name() string {
+	return "remove"
+}
+
+func (s *removingStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	return os.Remove(s.path)
+}
+
+func (s *removingStep) Condition(ctx *types.ScaffoldContext) bool {
+	return true
+}
+
+func TestStepExecutor_Progress(t *testing.T) {
+	ctx := &types.ScaffoldContext{WorktreePath: t.TempDir()}
+	failure := errors.New("boom")
+	steps := []types.ScaffoldStep{
+		&mockStep{name: "step1", conditionResult: true},
+		&mockStep{name: "step2", conditionResult: false},
+		&mockStep{name: "step3", conditionResult: true, runError: failure},
+	}
+
+	type event struct {
+		name, status   string
+		current, total int
+		reason         string
+		err            error
+	}
+	var events []event
+
+	executor := NewStepExecutor(steps, ctx, types.StepOptions{Quiet: true})
+	executor.SetContinueOnError(2)
+	executor.SetProgress(func(ev StepEvent) {
+		assert.NotEmpty(t, ev.ID)
+		events = append(events, event{ev.Step.Name(), ev.Status, ev.Current, ev.Total, ev.Reason, ev.Error})
+	})
+	assert.NoError(t, executor.Execute())
+
+	assert.Equal(t, []event{
+		{"step1", StepStarted, 1, 2, "", nil},
+		{"step1", StepCompleted, 1, 2, "", nil},
+		{"step2", StepSkipped, 0, 2, SkipConditionNot, nil},
+		{"step3", StepStarted, 2, 2, "", nil},
+		{"step3", StepFailed, 2, 2, "", failure},
+	}, events)
+}
+
+// describedStep is a step describing what it does, like a binary step.
+type describedStep struct {
+	mockStep
+	description string
+}
+
+func (s *describedStep) Description() string {
+	return s.description
+}
+
+func TestGetStepDescription(t *testing.T) {
+	assert.Equal(t, "Creating database (db.create)", getStepDescription(&mockStep{name: "db.create"}))
+	assert.Equal(t, "Running custom.step (custom.step)", getStepDescription(&mockStep{name: "custom.step"}))
+	assert.Equal(t, "Running composer install (php.composer)", getStepDescription(&describedStep{mockStep{name: "php.composer"}, "Running composer install"}))
+	assert.Equal(t, "Running composer (php.composer)", getStepDescription(&describedStep{mockStep{name: "php.composer"}, ""}), "falls back to the registered description")
+}
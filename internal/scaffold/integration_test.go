@@ -268,8 +268,8 @@ APP_NAME=myapp
 	})
 }
 
-func TestIntegration_RunScaffoldSuffixLoading(t *testing.T) {
-	t.Run("RunScaffold loads existing suffix from local state", func(t *testing.T) {
+func TestIntegration_ScaffoldWorktreeSuffixLoading(t *testing.T) {
+	t.Run("ScaffoldWorktree loads existing suffix from local state", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		envContent := `DB_CONNECTION=mysql
@@ -288,15 +288,15 @@ APP_NAME=myapp
 		cfg := &config.Config{Preset: ""}
 		manager := NewScaffoldManager()
 
-		err = manager.RunScaffold(tmpDir, "test", "myrepo", "myapp", "", cfg, "", testPromptMode(), false, false, false)
+		err = manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "myrepo", SiteName: "myapp", PromptMode: testPromptMode()})
 		require.NoError(t, err)
 
 		localStateAfter, err := config.ReadLocalState(tmpDir)
 		require.NoError(t, err)
-		assert.Equal(t, existingSuffix, localStateAfter.DbSuffix, "RunScaffold should preserve existing suffix from local state")
+		assert.Equal(t, existingSuffix, localStateAfter.DbSuffix, "ScaffoldWorktree should preserve existing suffix from local state")
 	})
 
-	t.Run("RunScaffold generates new suffix when none exists", func(t *testing.T) {
+	t.Run("ScaffoldWorktree generates new suffix when none exists", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		envContent := `DB_CONNECTION=mysql
@@ -311,18 +311,75 @@ APP_NAME=myapp
 		cfg := &config.Config{Preset: ""}
 		manager := NewScaffoldManager()
 
-		err := manager.RunScaffold(tmpDir, "test", "myrepo", "myapp", "", cfg, "", testPromptMode(), false, false, false)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "myrepo", SiteName: "myapp", PromptMode: testPromptMode()})
 		require.NoError(t, err)
 
 		localStateAfter, err := config.ReadLocalState(tmpDir)
 		require.NoError(t, err)
-		assert.NotEmpty(t, localStateAfter.DbSuffix, "RunScaffold should generate new suffix when none exists in local state")
+		assert.NotEmpty(t, localStateAfter.DbSuffix, "ScaffoldWorktree should generate new suffix when none exists in local state")
 
 		parts := strings.Split(localStateAfter.DbSuffix, "_")
 		assert.Len(t, parts, 2, "Suffix should be in format {adjective}_{noun}")
 	})
 }
 
+func TestIntegration_ScaffoldWorktreeContinueOnError(t *testing.T) {
+	newConfig := func(strict bool) *config.Config {
+		return &config.Config{
+			Scaffold: config.ScaffoldConfig{
+				Override: true,
+				Strict:   strict,
+				Steps: []config.StepConfig{
+					{Name: "bash.run", Command: "exit 1", ContinueOnError: true},
+					{Name: "bash.run", Command: "touch ran"},
+				},
+			},
+		}
+	}
+
+	t.Run("failed step is a warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		err := NewScaffoldManager().ScaffoldWorktree(tmpDir, "test", newConfig(false), RunOptions{RepoName: "myrepo", SiteName: "myapp", PromptMode: testPromptMode(), Quiet: true})
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(tmpDir, "ran"))
+	})
+
+	t.Run("strict fails after running remaining steps", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		err := NewScaffoldManager().ScaffoldWorktree(tmpDir, "test", newConfig(true), RunOptions{RepoName: "myrepo", SiteName: "myapp", PromptMode: testPromptMode(), Quiet: true})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 continue_on_error step(s) failed")
+		assert.FileExists(t, filepath.Join(tmpDir, "ran"))
+	})
+}
+
+func TestIntegration_ScaffoldWorktreeInvalidStepConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Scaffold: config.ScaffoldConfig{
+			Override: true,
+			Steps: []config.StepConfig{
+				{Name: "bash.run", Command: "touch ran"},
+				{Name: "bash.run"},
+				{Name: "bash.runn", Command: "true"},
+			},
+		},
+	}
+
+	err := NewScaffoldManager().ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "myrepo", SiteName: "myapp", PromptMode: testPromptMode(), Quiet: true})
+
+	var configErr *StepConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Len(t, configErr.Errs, 2, "every invalid step is reported")
+	assert.Contains(t, err.Error(), "2 invalid scaffold step configs:")
+	assert.Contains(t, err.Error(), `scaffold.steps[1] (bash.run): required field "command" is missing`)
+	assert.Contains(t, err.Error(), `scaffold.steps[2] (bash.runn): unknown step "bash.runn"`)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "ran"), "no step runs when any config is invalid")
+	assert.NoFileExists(t, filepath.Join(tmpDir, ".arbor.local"), "nothing is written when any config is invalid")
+}
+
 func TestIntegration_MultipleDatabasesSharedSuffix(t *testing.T) {
 	t.Run("multiple db.create steps share same suffix", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -457,7 +514,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.NoError(t, err, "Pre-flight should pass when all dependencies exist")
 	})
 
@@ -478,7 +535,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		require.Error(t, err, "Pre-flight should fail when map form dependencies are missing")
 		assert.Contains(t, err.Error(), "Missing environment variables")
 		assert.Contains(t, err.Error(), "NONEXISTENT_MAP_ENV")
@@ -506,7 +563,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		require.Error(t, err, "Pre-flight should fail when nested condition fails")
 		assert.EqualError(t, err, "pre-flight checks failed")
 	})
@@ -526,7 +583,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.Error(t, err, "Pre-flight should fail when env var is missing")
 		assert.Contains(t, err.Error(), "pre-flight checks failed")
 		assert.Contains(t, err.Error(), "Missing environment variables")
@@ -548,7 +605,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.Error(t, err, "Pre-flight should fail when command is missing")
 		assert.Contains(t, err.Error(), "pre-flight checks failed")
 		assert.Contains(t, err.Error(), "Missing commands")
@@ -570,7 +627,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.Error(t, err, "Pre-flight should fail when file is missing")
 		assert.Contains(t, err.Error(), "pre-flight checks failed")
 		assert.Contains(t, err.Error(), "Missing files")
@@ -594,7 +651,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.Error(t, err, "Pre-flight should fail when multiple dependencies are missing")
 		assert.Contains(t, err.Error(), "pre-flight checks failed")
 		assert.Contains(t, err.Error(), "Missing environment variables")
@@ -618,7 +675,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.NoError(t, err, "Scaffold should run normally when no pre-flight is configured")
 	})
 
@@ -640,7 +697,7 @@ func TestIntegration_PreFlightChecks(t *testing.T) {
 		}
 
 		manager := NewScaffoldManager()
-		err := manager.RunScaffold(tmpDir, "test", "testrepo", "testsite", "", cfg, "", testPromptMode(), false, false, true)
+		err := manager.ScaffoldWorktree(tmpDir, "test", cfg, RunOptions{RepoName: "testrepo", SiteName: "testsite", PromptMode: testPromptMode(), Quiet: true})
 		assert.Error(t, err, "Pre-flight should fail when ANY file is missing")
 		assert.Contains(t, err.Error(), "Missing files")
 		assert.Contains(t, err.Error(), "missing.txt")
@@ -1,35 +1,51 @@
 package scaffold
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/scaffold/steps"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/scaffold/words"
 	"github.com/artisanexperiences/arbor/internal/ui"
+	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 type ScaffoldManager struct {
 	presets     map[string]Preset
 	presetOrder []string
 	registry    StepRegistry
+	// configs, when set, memoises .arbor.local reads for the command and
+	// is handed to every step through the scaffold context.
+	configs *config.Store
+	// confirm asks the user to confirm the steps policy.require_confirmation
+	// lists; tests replace it.
+	confirm func(title, description string) (bool, error)
+	// lookPath finds the shells bash.run steps use; tests replace it.
+	lookPath func(file string) (string, error)
 }
 
 // StepRegistry defines the interface for step creation.
 // This abstraction allows for dependency injection and testing.
 type StepRegistry interface {
 	Create(name string, cfg config.StepConfig) (types.ScaffoldStep, error)
+	// Validate checks a step config without creating the step, returning
+	// every problem joined.
+	Validate(name string, cfg config.StepConfig) error
 	ListRegistered() []string
 }
 
 type Preset interface {
 	Name() string
+	// Version changes whenever DefaultSteps does.
+	Version() int
 	Detect(path string) bool
 	DefaultSteps() []config.StepConfig
 	CleanupSteps() []config.CleanupStep
@@ -51,9 +67,19 @@ func NewScaffoldManagerWithRegistry(registry StepRegistry) *ScaffoldManager {
 		presets:     make(map[string]Preset),
 		presetOrder: make([]string, 0),
 		registry:    registry,
+		confirm: func(title, description string) (bool, error) {
+			return ui.ConfirmWithDefault(title, description, false)
+		},
+		lookPath: exec.LookPath,
 	}
 }
 
+// SetConfigStore makes the manager and its steps read worktree state
+// through configs instead of from disk each time.
+func (m *ScaffoldManager) SetConfigStore(configs *config.Store) {
+	m.configs = configs
+}
+
 // globalStepRegistryAdapter adapts the global step functions to the StepRegistry interface.
 // This provides backward compatibility during the migration to explicit registry.
 type globalStepRegistryAdapter struct{}
@@ -62,10 +88,19 @@ func (a *globalStepRegistryAdapter) Create(name string, cfg config.StepConfig) (
 	return steps.Create(name, cfg)
 }
 
+func (a *globalStepRegistryAdapter) Validate(name string, cfg config.StepConfig) error {
+	return steps.Validate(name, cfg)
+}
+
 func (a *globalStepRegistryAdapter) ListRegistered() []string {
 	return steps.ListRegistered()
 }
 
+// StepNames returns the names of the steps the manager can run, sorted.
+func (m *ScaffoldManager) StepNames() []string {
+	return m.registry.ListRegistered()
+}
+
 func (m *ScaffoldManager) RegisterPreset(preset Preset) {
 	m.presets[preset.Name()] = preset
 	m.presetOrder = append(m.presetOrder, preset.Name())
@@ -86,75 +121,134 @@ func (m *ScaffoldManager) DetectPreset(path string) string {
 }
 
 func (m *ScaffoldManager) GetStepsForWorktree(cfg *config.Config, worktreePath, branch string) ([]types.ScaffoldStep, error) {
-	var stepsList []types.ScaffoldStep
+	located, overrideErrs, _ := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	return m.createSteps("scaffold", located, overrideErrs, cfg.Policy)
+}
 
-	presetName := cfg.Preset
-	if presetName == "" {
-		presetName = m.DetectPreset(worktreePath)
+// StepConfigsForWorktree returns the scaffold step configs for a worktree:
+// the preset's default steps followed by the arbor.yaml steps, or only the
+// arbor.yaml steps when scaffold.override is set.
+func (m *ScaffoldManager) StepConfigsForWorktree(cfg *config.Config, worktreePath string) []config.StepConfig {
+	located, _, _ := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	stepConfigs := make([]config.StepConfig, len(located))
+	for i, step := range located {
+		stepConfigs[i] = step.cfg
 	}
+	return stepConfigs
+}
+
+// locatedStep is a step config and where it is defined, so that config
+// errors can point at it: a YAML path such as scaffold.steps[2] for the
+// arbor.yaml steps, or the preset and position for a preset's steps.
+type locatedStep struct {
+	cfg      config.StepConfig
+	location string
+	// group is the step group the step was included from, if any.
+	group string
+}
 
-	if preset, ok := m.GetPreset(presetName); ok {
-		for _, stepConfig := range preset.DefaultSteps() {
-			step, err := m.registry.Create(stepConfig.Name, stepConfig)
-			if err != nil {
-				return nil, fmt.Errorf("creating step %q: %w", stepConfig.Name, err)
+// scaffoldStepsForWorktree returns the scaffold steps and where they are
+// defined, with scaffold.preset_overrides applied to the preset's steps,
+// step groups expanded and scaffold.defaults applied, an error for each
+// invalid group reference, and a warning for each override that selects
+// none of the preset's steps.
+func (m *ScaffoldManager) scaffoldStepsForWorktree(cfg *config.Config, worktreePath string) ([]locatedStep, []error, []string) {
+	var located []locatedStep
+	var errs []error
+	var warnings []string
+
+	if !cfg.Scaffold.Override {
+		if preset, ok := m.GetPreset(m.presetName(cfg, worktreePath)); ok {
+			for i, stepConfig := range preset.DefaultSteps() {
+				located = append(located, locatedStep{cfg: stepConfig, location: fmt.Sprintf("preset %s, step %d", preset.Name(), i+1)})
 			}
-			stepsList = append(stepsList, step)
+			warnings = applyPresetOverrides(located, cfg.Scaffold.PresetOverrides, "scaffold", preset.Name())
 		}
 	}
 
-	if cfg.Scaffold.Override {
-		overrideSteps, err := m.stepsFromConfig(cfg.Scaffold.Steps)
-		if err != nil {
-			return nil, err
+	expanded, groupErrs := cfg.ExpandSteps("scaffold.steps", cfg.Scaffold.Steps)
+	for _, step := range expanded {
+		located = append(located, locatedStep{cfg: step.Step, location: step.Location, group: step.Group})
+	}
+	errs = append(errs, groupErrs...)
+
+	for i := range located {
+		located[i].cfg = cfg.Scaffold.Defaults.Apply(located[i].cfg)
+	}
+	return located, errs, warnings
+}
+
+// applyPresetOverrides applies the overrides, in the order of their
+// selectors, to the preset steps they select, and returns a warning for
+// each override that selects none. Overrides are only checked against a
+// preset's steps, so a project whose worktrees detect no preset can still
+// set them, and a selector for a step another preset version dropped is
+// not an error.
+func applyPresetOverrides(presetSteps []locatedStep, overrides config.PresetOverrides, kind, preset string) []string {
+	var warnings []string
+	for _, selector := range overrides.Selectors() {
+		matched := false
+		for j, step := range presetSteps {
+			if config.SelectorMatches(selector, step.cfg) {
+				presetSteps[j].cfg = overrides[selector].Apply(step.cfg)
+				matched = true
+			}
 		}
-		stepsList = overrideSteps
-	} else {
-		additionalSteps, err := m.stepsFromConfig(cfg.Scaffold.Steps)
-		if err != nil {
-			return nil, err
+		if !matched {
+			warnings = append(warnings, fmt.Sprintf("%s.preset_overrides[%q] selects no %s step of preset %s", kind, selector, kind, preset))
 		}
-		stepsList = append(stepsList, additionalSteps...)
 	}
+	return warnings
+}
 
-	return stepsList, nil
+func (m *ScaffoldManager) presetName(cfg *config.Config, worktreePath string) string {
+	if cfg.Preset != "" {
+		return cfg.Preset
+	}
+	return m.DetectPreset(worktreePath)
 }
 
+// GetCleanupSteps returns the preset's cleanup steps followed by the
+// arbor.yaml ones. Every step config is checked; a *StepConfigError lists
+// all the invalid ones.
 func (m *ScaffoldManager) GetCleanupSteps(cfg *config.Config, worktreePath, branch string) ([]types.ScaffoldStep, error) {
-	var stepsList []types.ScaffoldStep
+	located, overrideErrs, _ := m.cleanupStepsForWorktree(cfg, worktreePath)
+	return m.createSteps("cleanup", located, overrideErrs, cfg.Policy)
+}
 
-	presetName := cfg.Preset
-	if presetName == "" {
-		presetName = m.DetectPreset(worktreePath)
-	}
+func (m *ScaffoldManager) cleanupStepsForWorktree(cfg *config.Config, worktreePath string) ([]locatedStep, []error, []string) {
+	var located []locatedStep
+	var errs []error
+	var warnings []string
 
-	if preset, ok := m.GetPreset(presetName); ok {
-		for _, cleanupConfig := range preset.CleanupSteps() {
-			stepConfig := m.cleanupConfigToStepConfig(cleanupConfig)
-			step, err := m.registry.Create(cleanupConfig.Name, stepConfig)
-			if err != nil {
-				return nil, fmt.Errorf("creating cleanup step %q: %w", cleanupConfig.Name, err)
-			}
-			stepsList = append(stepsList, step)
+	if preset, ok := m.GetPreset(m.presetName(cfg, worktreePath)); ok {
+		for i, cleanupConfig := range preset.CleanupSteps() {
+			located = append(located, locatedStep{cfg: m.cleanupConfigToStepConfig(cleanupConfig), location: fmt.Sprintf("preset %s, cleanup step %d", preset.Name(), i+1)})
 		}
+		warnings = applyPresetOverrides(located, cfg.Cleanup.PresetOverrides, "cleanup", preset.Name())
 	}
-
-	for _, cleanupConfig := range cfg.Cleanup.Steps {
-		stepConfig := m.cleanupConfigToStepConfig(cleanupConfig)
-		step, err := m.registry.Create(cleanupConfig.Name, stepConfig)
-		if err != nil {
-			return nil, fmt.Errorf("creating cleanup step %q: %w", cleanupConfig.Name, err)
+	for i, cleanupConfig := range cfg.Cleanup.Steps {
+		location := fmt.Sprintf("cleanup.steps[%d]", i)
+		if cleanupConfig.Group == "" {
+			located = append(located, locatedStep{cfg: m.cleanupConfigToStepConfig(cleanupConfig), location: location})
+			continue
 		}
-		stepsList = append(stepsList, step)
+		expanded, groupErrs := cfg.ExpandCleanupGroup(cleanupConfig, location)
+		for _, step := range expanded {
+			located = append(located, locatedStep{cfg: step.Step, location: step.Location, group: step.Group})
+		}
+		errs = append(errs, groupErrs...)
 	}
-
-	return stepsList, nil
+	return located, errs, warnings
 }
 
 func (m *ScaffoldManager) cleanupConfigToStepConfig(cleanupConfig config.CleanupStep) config.StepConfig {
 	stepConfig := config.StepConfig{
-		Name: cleanupConfig.Name,
-		Args: nil,
+		Name:         cleanupConfig.Name,
+		Args:         cleanupConfig.Args,
+		Type:         cleanupConfig.Type,
+		ConfirmAbove: cleanupConfig.ConfirmAbove,
+		Packages:     cleanupConfig.Packages,
 	}
 	if cleanupConfig.Name == "herd" {
 		stepConfig.Args = []string{"unlink"}
@@ -169,52 +263,286 @@ func (m *ScaffoldManager) cleanupConfigToStepConfig(cleanupConfig config.Cleanup
 	return stepConfig
 }
 
-func (m *ScaffoldManager) stepsFromConfig(stepConfigs []config.StepConfig) ([]types.ScaffoldStep, error) {
-	stepsList := make([]types.ScaffoldStep, 0, len(stepConfigs))
+// createSteps validates every step config through the registry, its
+// command against policy.deny_commands and its requires against the
+// variables earlier steps provide, before creating any step, and
+// reports all the invalid ones, after the problems already found, in a
+// *StepConfigError rather than only the first.
+func (m *ScaffoldManager) createSteps(kind string, located []locatedStep, problems []error, policy config.PolicyConfig) ([]types.ScaffoldStep, error) {
+	configErr := &StepConfigError{Kind: kind, Errs: problems}
+	available := make(map[string]bool)
+	for _, name := range types.BuiltinVars {
+		available[name] = true
+	}
+	for _, step := range located {
+		var errs []error
+		if err := m.registry.Validate(step.cfg.Name, step.cfg); err != nil {
+			errs = flattenErrors(err)
+		}
+		if err := checkDeniedCommand(policy, step.cfg.Name, step.cfg.Command); err != nil {
+			errs = append(errs, err)
+		}
+		// Steps run in order, so a required variable must come from an
+		// enabled step listed earlier.
+		if step.cfg.Enabled == nil || *step.cfg.Enabled {
+			for _, name := range step.cfg.Requires {
+				if !available[name] {
+					errs = append(errs, fmt.Errorf("requires %s, which no earlier step provides", name))
+				}
+			}
+			for _, name := range step.cfg.ProvidedVars() {
+				available[name] = true
+			}
+		}
+		if len(errs) > 0 {
+			configErr.Errs = append(configErr.Errs, &StepProblem{
+				Location: step.location,
+				Step:     step.cfg.Name,
+				Errs:     errs,
+			})
+		}
+	}
+	if len(configErr.Errs) > 0 {
+		return nil, configErr
+	}
 
-	for _, cfg := range stepConfigs {
-		step, err := m.registry.Create(cfg.Name, cfg)
+	stepsList := make([]types.ScaffoldStep, 0, len(located))
+	for _, step := range located {
+		created, err := m.registry.Create(step.cfg.Name, step.cfg)
 		if err != nil {
-			return nil, fmt.Errorf("creating step %q: %w", cfg.Name, err)
+			return nil, fmt.Errorf("%s: %w", step.location, err)
+		}
+		if step.cfg.Enabled != nil && !*step.cfg.Enabled {
+			created = disabledStep{created}
 		}
-		stepsList = append(stepsList, step)
+		stepsList = append(stepsList, created)
 	}
-
 	return stepsList, nil
 }
 
+// checkShells fails when a shell that bash.run steps use is not installed,
+// naming each shell and the step that uses it, before any step runs.
+func (m *ScaffoldManager) checkShells(ctx *types.ScaffoldContext, located []locatedStep, stepsList []types.ScaffoldStep) error {
+	var missing []string
+	for i, step := range stepsList {
+		shellStep, ok := step.(types.ShellStep)
+		if !ok {
+			continue
+		}
+		shell := shellStep.Shell(ctx)
+		if _, err := m.lookPath(shell); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%s)", shell, located[i].location))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("pre-flight checks failed:\n\nMissing shells:\n  - %s\n\nInstall them or choose another shell with 'shell' in arbor.yaml",
+		strings.Join(missing, "\n  - "))
+}
+
+// sandboxConfig returns cfg's sandbox section, enabled when force is set.
+func sandboxConfig(cfg *config.Config, force bool) config.SandboxConfig {
+	sandbox := cfg.Sandbox
+	if force {
+		sandbox.Enabled = true
+	}
+	return sandbox
+}
+
+// checkDeniedCommand rejects a bash.run or command.run command matching
+// policy.deny_commands. Steps check their command again once templates are
+// rendered.
+func checkDeniedCommand(policy config.PolicyConfig, step, command string) error {
+	if step != "bash.run" && step != "command.run" {
+		return nil
+	}
+	if pattern, denied := policy.DeniedCommand(command); denied {
+		return fmt.Errorf("command matches policy.deny_commands pattern %q", pattern)
+	}
+	return nil
+}
+
+// confirmSteps asks the user to confirm the steps policy.require_confirmation
+// lists, and drops them from the run when the user declines. With --trust,
+// or in a dry run, nothing is asked; when arbor cannot prompt, the run
+// fails rather than running them unconfirmed.
+func (m *ScaffoldManager) confirmSteps(policy config.PolicyConfig, located []locatedStep, stepsList []types.ScaffoldStep, opts RunOptions) ([]locatedStep, []types.ScaffoldStep, error) {
+	var labels []string
+	for _, step := range located {
+		if policy.RequiresConfirmation(step.cfg.Name) {
+			labels = append(labels, fmt.Sprintf("%s (%s)", step.cfg.Name, step.location))
+		}
+	}
+	if len(labels) == 0 || opts.DryRun || opts.PromptMode.Trust {
+		return located, stepsList, nil
+	}
+
+	if !opts.PromptMode.Allow() {
+		return nil, nil, fmt.Errorf("policy.require_confirmation: %s must be confirmed; run interactively or pass --trust", strings.Join(labels, ", "))
+	}
+
+	confirmed, err := m.confirm("Run steps that need confirmation?", "policy.require_confirmation in arbor.yaml lists:\n  "+strings.Join(labels, "\n  "))
+	if err != nil {
+		return nil, nil, err
+	}
+	if confirmed {
+		return located, stepsList, nil
+	}
+
+	var keptLocated []locatedStep
+	var keptSteps []types.ScaffoldStep
+	for i, step := range located {
+		if policy.RequiresConfirmation(step.cfg.Name) {
+			ui.PrintWarning(fmt.Sprintf("Skipping %s (%s): not confirmed", step.cfg.Name, step.location))
+			continue
+		}
+		keptLocated = append(keptLocated, step)
+		keptSteps = append(keptSteps, stepsList[i])
+	}
+	return keptLocated, keptSteps, nil
+}
+
+// flattenErrors splits joined errors into their parts.
+func flattenErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+// StepConfigError lists every invalid step config of a scaffold or cleanup
+// run, so they can all be fixed before trying again.
+type StepConfigError struct {
+	// Kind is "scaffold", "cleanup" or "ci".
+	Kind string
+	Errs []error
+}
+
+func (e *StepConfigError) Error() string {
+	var b strings.Builder
+	if len(e.Errs) == 1 {
+		fmt.Fprintf(&b, "invalid %s step config:", e.Kind)
+	} else {
+		fmt.Fprintf(&b, "%d invalid %s step configs:", len(e.Errs), e.Kind)
+	}
+	for _, err := range e.Errs {
+		lines := strings.Split(err.Error(), "\n")
+		fmt.Fprintf(&b, "\n  - %s", lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(&b, "\n    %s", line)
+		}
+	}
+	return b.String()
+}
+
+func (e *StepConfigError) Unwrap() []error {
+	return e.Errs
+}
+
+// StepProblem is an invalid step config: where it is defined, such as
+// scaffold.steps[2], the step name, and everything wrong with it.
+type StepProblem struct {
+	Location string
+	Step     string
+	Errs     []error
+}
+
+func (p *StepProblem) Error() string {
+	if len(p.Errs) == 1 {
+		return fmt.Sprintf("%s (%s): %v", p.Location, p.Step, p.Errs[0])
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s):", p.Location, p.Step)
+	for _, err := range p.Errs {
+		fmt.Fprintf(&b, "\n- %v", err)
+	}
+	return b.String()
+}
+
+func (p *StepProblem) Unwrap() []error {
+	return p.Errs
+}
+
+// RunScaffold runs the scaffold steps for a worktree.
+//
+// Deprecated: Use ScaffoldWorktree, which takes RunOptions instead of
+// positional flags.
 func (m *ScaffoldManager) RunScaffold(worktreePath, branch, repoName, siteName, preset string, cfg *config.Config, barePath string, promptMode types.PromptMode, dryRun, verbose, quiet bool) error {
-	ctx := m.newScaffoldContext(worktreePath, branch, repoName, siteName, preset, barePath)
+	return m.ScaffoldWorktree(worktreePath, branch, cfg, RunOptions{
+		RepoName:   repoName,
+		SiteName:   siteName,
+		Preset:     preset,
+		BarePath:   barePath,
+		PromptMode: promptMode,
+		DryRun:     dryRun,
+		Verbose:    verbose,
+		Quiet:      quiet,
+	})
+}
+
+// ScaffoldWorktree runs the scaffold steps cfg defines for a worktree,
+// after the pre-flight checks, and records the worktree for 'arbor gc'.
+func (m *ScaffoldManager) ScaffoldWorktree(worktreePath, branch string, cfg *config.Config, opts RunOptions) error {
+	ctx := m.runContext(worktreePath, branch, cfg, opts)
+
+	// Every step config is checked before anything runs or is written, so
+	// a typo in arbor.yaml never leaves a half-scaffolded worktree.
+	located, overrideErrs, overrideWarnings := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	stepsList, err := m.createSteps("scaffold", located, overrideErrs, cfg.Policy)
+	if err != nil {
+		return err
+	}
+	for _, warning := range overrideWarnings {
+		ctx.AddWarning(warning)
+	}
+	located, stepsList, err = m.confirmSteps(cfg.Policy, located, stepsList, opts)
+	if err != nil {
+		return err
+	}
 
 	// Run pre-flight checks with spinner
-	if !quiet {
-		if err := m.runPreFlightWithSpinner(&ctx, &cfg.Scaffold); err != nil {
+	if !opts.Quiet {
+		if err := m.runPreFlightWithSpinner(ctx, &cfg.Scaffold); err != nil {
 			return err
 		}
 	} else {
 		// Quiet mode: run without spinner
-		if err := m.runPreFlightChecks(&ctx, &cfg.Scaffold); err != nil {
+		if err := m.runPreFlightChecks(ctx, &cfg.Scaffold); err != nil {
 			return err
 		}
 	}
+	if err := m.checkShells(ctx, located, stepsList); err != nil {
+		return err
+	}
 
 	// Migrate db_suffix from arbor.yaml to .arbor.local if present
-	if !dryRun {
+	if !opts.DryRun {
 		if _, err := config.MigrateDbSuffixToLocal(worktreePath); err != nil {
 			return fmt.Errorf("migrating db_suffix: %w", err)
 		}
 	}
 
 	// Load local state instead of worktree config
-	localState, err := config.ReadLocalState(worktreePath)
+	localState, err := m.configs.LocalState(worktreePath)
 	if err != nil {
 		return fmt.Errorf("reading local state: %w", err)
 	}
 
+	applyMatrixVars(ctx, localState)
+
 	if localState.DbSuffix == "" {
-		newSuffix := words.GenerateSuffix()
+		newSuffix, err := m.NewDbSuffix(cfg, worktreePath, branch, opts.BarePath)
+		if err != nil {
+			return fmt.Errorf("generating db_suffix: %w", err)
+		}
 		ctx.SetDbSuffix(newSuffix)
-		if !dryRun {
+		if !opts.DryRun {
 			if err := config.WriteLocalState(worktreePath, config.LocalState{DbSuffix: newSuffix}); err != nil {
 				return fmt.Errorf("writing db_suffix to local state: %w", err)
 			}
@@ -223,43 +551,330 @@ func (m *ScaffoldManager) RunScaffold(worktreePath, branch, repoName, siteName,
 		ctx.SetDbSuffix(localState.DbSuffix)
 	}
 
-	stepsList, err := m.GetStepsForWorktree(cfg, worktreePath, branch)
-	if err != nil {
-		return fmt.Errorf("getting scaffold steps: %w", err)
+	executor := NewStepExecutor(stepsList, ctx, opts.stepOptions())
+	executor.SetProgress(opts.Progress)
+	for i, step := range located {
+		executor.SetStepConfig(i, step.cfg)
 	}
-
-	opts := m.stepOptionsFromFlags(dryRun, verbose, quiet, promptMode)
-
-	executor := NewStepExecutor(stepsList, &ctx, opts)
+	started := time.Now()
 	if err := executor.Execute(); err != nil {
+		// Steps that ran before the failure may already have created
+		// databases, links or files, so record them on a best-effort basis:
+		// a recording failure is a warning and the step's error is returned.
+		if !opts.DryRun {
+			if recordErr := m.recordWorktree(ctx, scaffoldReport(started, executor.Results(), err)); recordErr != nil {
+				warnScaffold(ctx, fmt.Sprintf("recording worktree state: %v", recordErr))
+			}
+			if recordErr := config.RecordFileChanges(worktreePath, ctx.FileChanges()); recordErr != nil {
+				warnScaffold(ctx, fmt.Sprintf("recording file changes: %v; 'arbor scaffold --revert-files' cannot restore this run's edits", recordErr))
+			}
+		}
 		return err
 	}
 
+	if !opts.DryRun {
+		if err := m.recordWorktree(ctx, scaffoldReport(started, executor.Results(), nil)); err != nil {
+			return fmt.Errorf("recording worktree state: %w", err)
+		}
+		if err := config.RecordFileChanges(worktreePath, ctx.FileChanges()); err != nil {
+			return fmt.Errorf("recording file changes: %w", err)
+		}
+		if err := m.RecordPresetSteps(cfg, worktreePath); err != nil {
+			return err
+		}
+	}
+
+	if failed := executor.FailedCount(); failed > 0 && cfg.Scaffold.Strict {
+		return fmt.Errorf("%d continue_on_error step(s) failed (scaffold.strict is set)", failed)
+	}
+
+	if !opts.DryRun && localState.ScaffoldPending {
+		if err := config.SetScaffoldPending(worktreePath, false); err != nil {
+			return fmt.Errorf("clearing pending scaffold: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// recordWorktree writes the project-level record used by 'arbor gc' to clean
+// up after worktrees that are deleted outside arbor, and by 'arbor info' to
+// show the last scaffold.
+func (m *ScaffoldManager) recordWorktree(ctx *types.ScaffoldContext, report *config.ScaffoldReport) error {
+	if ctx.BarePath == "" {
+		return nil
+	}
+	env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
+	return config.WriteWorktreeRecord(filepath.Dir(ctx.BarePath), config.WorktreeRecord{
+		Path:         ctx.WorktreePath,
+		Branch:       ctx.Branch,
+		DbSuffix:     ctx.GetDbSuffix(),
+		DbConnection: env["DB_CONNECTION"],
+		Preset:       ctx.Preset,
+		SiteName:     ctx.SiteName,
+		LastScaffold: report,
+	})
+}
+
+// scaffoldReport summarises a scaffold that started at started and ended
+// now with err.
+func scaffoldReport(started time.Time, results []ExecutionResult, err error) *config.ScaffoldReport {
+	report := &config.ScaffoldReport{
+		Time:       started,
+		DurationMs: time.Since(started).Milliseconds(),
+		Steps:      make([]config.ScaffoldStepReport, 0, len(results)),
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	for _, result := range results {
+		step := config.ScaffoldStepReport{
+			Name:       result.Step.Name(),
+			Status:     StepCompleted,
+			DurationMs: result.Duration.Milliseconds(),
+		}
+		switch {
+		case result.Skipped:
+			step.Status = StepSkipped
+			step.Reason = result.SkipReason
+		case result.Error != nil:
+			step.Status = StepFailed
+			step.Error = result.Error.Error()
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return report
+}
+
+// RunCleanup runs the cleanup steps for a worktree.
+//
+// Deprecated: Use CleanupWorktree, which takes CleanupOptions instead of
+// positional flags.
 func (m *ScaffoldManager) RunCleanup(worktreePath, branch, repoName, siteName, preset string, cfg *config.Config, barePath string, promptMode types.PromptMode, dryRun, verbose, quiet bool) error {
-	ctx := m.newScaffoldContext(worktreePath, branch, repoName, siteName, preset, barePath)
+	_, err := m.RunCleanupWithReport(worktreePath, branch, repoName, siteName, preset, cfg, barePath, promptMode, dryRun, verbose, quiet)
+	return err
+}
+
+// RunCleanupWithReport runs the cleanup steps like RunCleanup and returns the
+// resources they removed.
+//
+// Deprecated: Use CleanupWorktree, which takes CleanupOptions instead of
+// positional flags.
+func (m *ScaffoldManager) RunCleanupWithReport(worktreePath, branch, repoName, siteName, preset string, cfg *config.Config, barePath string, promptMode types.PromptMode, dryRun, verbose, quiet bool) ([]types.Resource, error) {
+	return m.CleanupWorktree(worktreePath, branch, cfg, CleanupOptions{
+		RepoName:   repoName,
+		SiteName:   siteName,
+		Preset:     preset,
+		BarePath:   barePath,
+		PromptMode: promptMode,
+		DryRun:     dryRun,
+		Verbose:    verbose,
+		Quiet:      quiet,
+	})
+}
+
+// CleanupWorktree runs the cleanup steps for a worktree and returns the
+// resources they removed. Resources removed before a failing step are
+// returned alongside the error.
+func (m *ScaffoldManager) CleanupWorktree(worktreePath, branch string, cfg *config.Config, opts CleanupOptions) ([]types.Resource, error) {
+	ctx := m.newScaffoldContext(worktreePath, branch, opts.RepoName, opts.SiteName, opts.Preset, opts.BarePath)
+	ctx.EnvFile = cfg.EnvFile
+	ctx.DefaultBranch = cfg.DefaultBranch
+	ctx.Policy = cfg.Policy
+	ctx.Sandbox = sandboxConfig(cfg, opts.Sandbox)
+	ctx.Shell = cfg.Shell
+	ctx.Profiles = cfg.Scaffold.Profiles
+
+	located, overrideErrs, overrideWarnings := m.cleanupStepsForWorktree(cfg, worktreePath)
+	stepsList, err := m.createSteps("cleanup", located, overrideErrs, cfg.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("getting cleanup steps: %w", err)
+	}
+	for _, warning := range overrideWarnings {
+		ctx.AddWarning(warning)
+	}
+	if located, stepsList, err = m.confirmSteps(cfg.Policy, located, stepsList, RunOptions(opts)); err != nil {
+		return nil, err
+	}
+	if err := m.checkShells(ctx, located, stepsList); err != nil {
+		return nil, err
+	}
+
+	executor := NewStepExecutor(stepsList, ctx, RunOptions(opts).stepOptions())
+	executor.SetProgress(opts.Progress)
+	if err := executor.Execute(); err != nil {
+		return ctx.Removed(), err
+	}
+
+	return ctx.Removed(), nil
+}
+
+// PlanCleanup returns the resources the cleanup steps for a worktree would
+// remove.
+//
+// Deprecated: Use PlanWorktreeCleanup, which takes CleanupOptions.
+func (m *ScaffoldManager) PlanCleanup(worktreePath, branch, repoName, siteName, preset string, cfg *config.Config, barePath string, promptMode types.PromptMode) ([]types.Resource, error) {
+	return m.PlanWorktreeCleanup(worktreePath, branch, cfg, CleanupOptions{
+		RepoName:   repoName,
+		SiteName:   siteName,
+		Preset:     preset,
+		BarePath:   barePath,
+		PromptMode: promptMode,
+	})
+}
+
+// PlanWorktreeCleanup returns the resources the cleanup steps for a
+// worktree would remove, without running them. Steps that cannot be
+// planned are reported in the joined error; resources from the other steps
+// are still returned.
+func (m *ScaffoldManager) PlanWorktreeCleanup(worktreePath, branch string, cfg *config.Config, opts CleanupOptions) ([]types.Resource, error) {
+	ctx := m.newScaffoldContext(worktreePath, branch, opts.RepoName, opts.SiteName, opts.Preset, opts.BarePath)
+	ctx.EnvFile = cfg.EnvFile
+	ctx.DefaultBranch = cfg.DefaultBranch
 
 	stepsList, err := m.GetCleanupSteps(cfg, worktreePath, branch)
 	if err != nil {
-		return fmt.Errorf("getting cleanup steps: %w", err)
+		return nil, fmt.Errorf("getting cleanup steps: %w", err)
 	}
 
-	opts := m.stepOptionsFromFlags(dryRun, verbose, quiet, promptMode)
+	stepOpts := types.StepOptions{DryRun: true, Quiet: true, PromptMode: opts.PromptMode}
+	defer func() { _ = ctx.CloseClients() }()
 
-	executor := NewStepExecutor(stepsList, &ctx, opts)
-	if err := executor.Execute(); err != nil {
-		return err
+	var resources []types.Resource
+	var errs []error
+	for _, step := range stepsList {
+		planner, ok := step.(types.CleanupPlanner)
+		if !ok || !isStepEnabled(step) || !step.Condition(ctx) {
+			continue
+		}
+		planned, err := planner.PlanCleanup(ctx, stepOpts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("planning %s: %w", step.Name(), err))
+			continue
+		}
+		resources = append(resources, planned...)
 	}
 
-	return nil
+	return resources, errors.Join(errs...)
+}
+
+// NewContext returns the context scaffold steps would start with for a
+// worktree, including the db suffix persisted in .arbor.local. Unlike
+// ScaffoldWorktree it runs no checks and never generates a suffix.
+func (m *ScaffoldManager) NewContext(worktreePath, branch, repoName, siteName, preset, barePath string) (*types.ScaffoldContext, error) {
+	ctx := m.newScaffoldContext(worktreePath, branch, repoName, siteName, preset, barePath)
+
+	localState, err := m.configs.LocalState(worktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading local state: %w", err)
+	}
+	ctx.SetDbSuffix(localState.DbSuffix)
+	applyMatrixVars(ctx, localState)
+
+	return ctx, nil
+}
+
+// applyMatrixVars sets the template variables of a worktree created by
+// 'arbor work --matrix'.
+func applyMatrixVars(ctx *types.ScaffoldContext, state *config.LocalState) {
+	for key, value := range state.Matrix {
+		ctx.SetVar(key, value)
+	}
+}
+
+// NewDbSuffix returns a new database suffix for the worktree at
+// worktreePath, as its first scaffold would generate it.
+func (m *ScaffoldManager) NewDbSuffix(cfg *config.Config, worktreePath, branch, barePath string) (string, error) {
+	localState, err := m.configs.LocalState(worktreePath)
+	if err != nil {
+		return "", fmt.Errorf("reading local state: %w", err)
+	}
+	// Matrix worktrees share a branch, so a branch-derived suffix comes
+	// from the worktree's folder, such as feature-x-php83.
+	if len(localState.Matrix) > 0 {
+		branch = filepath.Base(worktreePath)
+	}
+	return generateDbSuffix(m.configs, cfg, worktreePath, branch, barePath)
 }
 
-func (m *ScaffoldManager) newScaffoldContext(worktreePath, branch, repoName, siteName, preset, barePath string) types.ScaffoldContext {
+// generateDbSuffix returns a new database suffix for a worktree following
+// the project's db_naming and naming config. Branch-derived suffixes get a
+// hash appended when another worktree's branch already uses the same one.
+func generateDbSuffix(configs *config.Store, cfg *config.Config, worktreePath, branch, barePath string) (string, error) {
+	switch cfg.DbNaming {
+	case "", words.DbNamingRandom:
+		return words.GenerateSuffixFor(cfg.Naming, branch)
+	case words.DbNamingBranch:
+		suffix := words.BranchSuffix(branch, false)
+		// Discovery is best-effort: without it the plain suffix is used.
+		others, _ := steps.DiscoverWorktreeDatabases(configs, barePath, worktreePath)
+		for _, other := range others {
+			if other.DbSuffix == suffix && other.Branch != branch {
+				return words.BranchSuffix(branch, true), nil
+			}
+		}
+		return suffix, nil
+	default:
+		return "", fmt.Errorf("unknown db_naming %q (expected %s or %s)", cfg.DbNaming, words.DbNamingRandom, words.DbNamingBranch)
+	}
+}
+
+// runContext returns the context for a scaffold of the worktree with cfg.
+func (m *ScaffoldManager) runContext(worktreePath, branch string, cfg *config.Config, opts RunOptions) *types.ScaffoldContext {
+	ctx := m.newScaffoldContext(worktreePath, branch, opts.RepoName, opts.SiteName, opts.Preset, opts.BarePath)
+	ctx.DbNaming = cfg.DbNaming
+	ctx.Naming = cfg.Naming
+	ctx.EnvFile = cfg.EnvFile
+	ctx.DefaultBranch = cfg.DefaultBranch
+	ctx.Answers = opts.Answers
+	ctx.Policy = cfg.Policy
+	ctx.Sandbox = sandboxConfig(cfg, opts.Sandbox)
+	ctx.Shell = cfg.Shell
+	ctx.Profiles = cfg.Scaffold.Profiles
+	return ctx
+}
+
+// ValidateScaffoldPlan runs the checks a dry run of the worktree's
+// scaffold makes, such as database name lengths, without running any step,
+// and returns the problems keyed by the index of the step in PlanScaffold's
+// entries. A worktree without a database suffix is checked with one
+// generated as its first scaffold would.
+func (m *ScaffoldManager) ValidateScaffoldPlan(worktreePath, branch string, cfg *config.Config, opts RunOptions) (map[int]error, error) {
+	located, problems, _ := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	stepsList, err := m.createSteps("scaffold", located, problems, cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := m.runContext(worktreePath, branch, cfg, opts)
+	localState, err := m.configs.LocalState(worktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading local state: %w", err)
+	}
+	applyMatrixVars(ctx, localState)
+	suffix := localState.DbSuffix
+	if suffix == "" {
+		if suffix, err = m.NewDbSuffix(cfg, worktreePath, branch, opts.BarePath); err != nil {
+			return nil, fmt.Errorf("generating db_suffix: %w", err)
+		}
+	}
+	ctx.SetDbSuffix(suffix)
+
+	opts.DryRun = true
+	executor := NewStepExecutor(stepsList, ctx, opts.stepOptions())
+	for i, step := range located {
+		executor.SetStepConfig(i, step.cfg)
+	}
+	return executor.ValidatePlan(), nil
+}
+
+// newScaffoldContext returns the context steps share during a run. Steps
+// record variables, resources and clients in it, so it is only passed by
+// pointer.
+func (m *ScaffoldManager) newScaffoldContext(worktreePath, branch, repoName, siteName, preset, barePath string) *types.ScaffoldContext {
 	path := filepath.Base(worktreePath)
 	repoPath := filepath.Base(filepath.Dir(worktreePath))
-	return types.ScaffoldContext{
+	return &types.ScaffoldContext{
 		WorktreePath: worktreePath,
 		Branch:       branch,
 		RepoName:     repoName,
@@ -270,15 +885,7 @@ func (m *ScaffoldManager) newScaffoldContext(worktreePath, branch, repoName, sit
 		RepoPath:     repoPath,
 		BarePath:     barePath,
 		Vars:         make(map[string]string),
-	}
-}
-
-func (m *ScaffoldManager) stepOptionsFromFlags(dryRun, verbose, quiet bool, promptMode types.PromptMode) types.StepOptions {
-	return types.StepOptions{
-		DryRun:     dryRun,
-		Verbose:    verbose,
-		Quiet:      quiet,
-		PromptMode: promptMode,
+		Configs:      m.configs,
 	}
 }
 
@@ -499,3 +1106,11 @@ func (m *ScaffoldManager) checkMissingFiles(ctx *types.ScaffoldContext, value in
 
 	return missing, errors
 }
+
+// warnScaffold records a warning on ctx and prints it. The executor prints
+// the context's warnings only after a successful run, so warnings raised
+// once a run has failed are printed here.
+func warnScaffold(ctx *types.ScaffoldContext, message string) {
+	ctx.AddWarning(message)
+	ui.PrintWarning(message)
+}
@@ -0,0 +1,556 @@
+package scaffold
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// planningStep is a cleanup step that removes a single resource.
+type planningStep struct {
+	mockStep
+	resource types.Resource
+	planErr  error
+}
+
+func (s *planningStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	s.runCalled = true
+	if s.runError != nil {
+		return s.runError
+	}
+	ctx.RecordRemoved(s.resource.Kind, s.resource.Name)
+	return nil
+}
+
+func (s *planningStep) PlanCleanup(ctx *types.ScaffoldContext, opts types.StepOptions) ([]types.Resource, error) {
+	if s.planErr != nil {
+		return nil, s.planErr
+	}
+	return []types.Resource{s.resource}, nil
+}
+
+// stubRegistry returns pre-built steps by name.
+type stubRegistry map[string]types.ScaffoldStep
+
+func (r stubRegistry) Create(name string, cfg config.StepConfig) (types.ScaffoldStep, error) {
+	step, ok := r[name]
+	if !ok {
+		return nil, errors.New("unknown step " + name)
+	}
+	return step, nil
+}
+
+func (r stubRegistry) Validate(name string, cfg config.StepConfig) error {
+	if _, ok := r[name]; !ok {
+		return errors.New("unknown step " + name)
+	}
+	return nil
+}
+
+func (r stubRegistry) ListRegistered() []string {
+	return nil
+}
+
+func cleanupConfig(names ...string) *config.Config {
+	cfg := &config.Config{}
+	for _, name := range names {
+		cfg.Cleanup.Steps = append(cfg.Cleanup.Steps, config.CleanupStep{Name: name})
+	}
+	return cfg
+}
+
+func TestScaffoldManager_PlanWorktreeCleanup(t *testing.T) {
+	db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, resource: types.Resource{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}
+	herd := &planningStep{mockStep: mockStep{name: "herd", conditionResult: false}, resource: types.Resource{Kind: types.ResourceHerdLink, Name: "feature"}}
+	other := &mockStep{name: "bash.run", conditionResult: true}
+
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd, "bash.run": other})
+	resources, err := m.PlanWorktreeCleanup(t.TempDir(), "feature", cleanupConfig("db.destroy", "herd", "bash.run"), CleanupOptions{SiteName: "feature"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, resources, "steps whose condition fails are not planned")
+	assert.False(t, db.runCalled, "planning must not run steps")
+	assert.False(t, other.runCalled)
+}
+
+func TestScaffoldManager_PlanWorktreeCleanup_CollectsErrors(t *testing.T) {
+	db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, planErr: errors.New("connection refused")}
+	herd := &planningStep{mockStep: mockStep{name: "herd", conditionResult: true}, resource: types.Resource{Kind: types.ResourceHerdLink, Name: "feature"}}
+
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd})
+	resources, err := m.PlanWorktreeCleanup(t.TempDir(), "feature", cleanupConfig("db.destroy", "herd"), CleanupOptions{SiteName: "feature"})
+
+	assert.ErrorContains(t, err, "planning db.destroy: connection refused")
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceHerdLink, Name: "feature"}}, resources)
+}
+
+func TestScaffoldManager_CleanupConfigToStepConfig(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{})
+
+	db := m.cleanupConfigToStepConfig(config.CleanupStep{Name: "db.destroy", Args: []string{"--prefix", "shop"}, Type: "pgsql", ConfirmAbove: 5})
+	assert.Equal(t, config.StepConfig{Name: "db.destroy", Args: []string{"--prefix", "shop"}, Type: "pgsql", ConfirmAbove: 5}, db)
+
+	node := m.cleanupConfigToStepConfig(config.CleanupStep{Name: "node.unlink", Packages: map[string]string{"@acme/ui": "../ui"}})
+	assert.Equal(t, map[string]string{"@acme/ui": "../ui"}, node.Packages)
+
+	herd := m.cleanupConfigToStepConfig(config.CleanupStep{Name: "herd"})
+	assert.Equal(t, []string{"unlink"}, herd.Args)
+}
+
+func TestScaffoldManager_GetCleanupSteps_ReportsAllConfigErrors(t *testing.T) {
+	herd := &mockStep{name: "herd", conditionResult: true}
+
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"herd": herd})
+	stepsList, err := m.GetCleanupSteps(cleanupConfig("db.destory", "herd", "bash.rn"), t.TempDir(), "feature")
+
+	var configErr *StepConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Nil(t, stepsList)
+	assert.Equal(t, "2 invalid cleanup step configs:\n  - cleanup.steps[0] (db.destory): unknown step db.destory\n  - cleanup.steps[2] (bash.rn): unknown step bash.rn", err.Error())
+}
+
+func TestScaffoldManager_CleanupWorktree_RequireConfirmation(t *testing.T) {
+	newManager := func(confirmed bool) (*ScaffoldManager, *planningStep, *planningStep, *bool) {
+		db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, resource: types.Resource{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}
+		herd := &planningStep{mockStep: mockStep{name: "herd", conditionResult: true}, resource: types.Resource{Kind: types.ResourceHerdLink, Name: "feature"}}
+		m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd})
+		asked := false
+		m.confirm = func(title, description string) (bool, error) {
+			asked = true
+			assert.Contains(t, description, "db.destroy (cleanup.steps[0])")
+			return confirmed, nil
+		}
+		return m, db, herd, &asked
+	}
+	policyConfig := func() *config.Config {
+		cfg := cleanupConfig("db.destroy", "herd")
+		cfg.Policy.RequireConfirmation = []string{"db.destroy"}
+		return cfg
+	}
+	interactive := types.PromptMode{Interactive: true}
+
+	t.Run("runs confirmed steps", func(t *testing.T) {
+		m, db, herd, asked := newManager(true)
+		_, err := m.CleanupWorktree(t.TempDir(), "feature", policyConfig(), CleanupOptions{PromptMode: interactive, Quiet: true})
+
+		require.NoError(t, err)
+		assert.True(t, *asked)
+		assert.True(t, db.runCalled)
+		assert.True(t, herd.runCalled)
+	})
+
+	t.Run("skips declined steps", func(t *testing.T) {
+		m, db, herd, _ := newManager(false)
+		removed, err := m.CleanupWorktree(t.TempDir(), "feature", policyConfig(), CleanupOptions{PromptMode: interactive, Quiet: true})
+
+		require.NoError(t, err)
+		assert.False(t, db.runCalled)
+		assert.True(t, herd.runCalled)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceHerdLink, Name: "feature"}}, removed)
+	})
+
+	t.Run("fails when it cannot ask", func(t *testing.T) {
+		m, db, herd, asked := newManager(true)
+		_, err := m.CleanupWorktree(t.TempDir(), "feature", policyConfig(), CleanupOptions{PromptMode: types.PromptMode{Interactive: true, Force: true}, Quiet: true})
+
+		assert.ErrorContains(t, err, "db.destroy (cleanup.steps[0]) must be confirmed; run interactively or pass --trust")
+		assert.False(t, *asked)
+		assert.False(t, db.runCalled)
+		assert.False(t, herd.runCalled)
+	})
+
+	t.Run("trust runs without asking", func(t *testing.T) {
+		m, db, _, asked := newManager(false)
+		_, err := m.CleanupWorktree(t.TempDir(), "feature", policyConfig(), CleanupOptions{PromptMode: types.PromptMode{NoInteractive: true, Trust: true}, Quiet: true})
+
+		require.NoError(t, err)
+		assert.False(t, *asked)
+		assert.True(t, db.runCalled)
+	})
+}
+
+func TestScaffoldManager_GetStepsForWorktree_DenyCommands(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"bash.run": &mockStep{name: "bash.run"}})
+	cfg := &config.Config{
+		Scaffold: config.ScaffoldConfig{Override: true, Steps: []config.StepConfig{
+			{Name: "bash.run", Command: "npm ci"},
+			{Name: "bash.run", Command: "curl -fsSL https://example.com/setup.sh | bash"},
+		}},
+		Policy: config.PolicyConfig{DenyCommands: []string{"curl | bash"}},
+	}
+
+	_, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+
+	assert.EqualError(t, err, "invalid scaffold step config:\n  - scaffold.steps[1] (bash.run): command matches policy.deny_commands pattern \"curl | bash\"")
+}
+
+// shellStep is a mockStep that runs through the project's shell.
+type shellStep struct {
+	mockStep
+}
+
+func (s *shellStep) Shell(ctx *types.ScaffoldContext) string {
+	if ctx.Shell != "" {
+		return ctx.Shell
+	}
+	return config.DefaultShell
+}
+
+func TestScaffoldManager_CleanupWorktree_MissingShell(t *testing.T) {
+	step := &shellStep{mockStep{name: "bash.run", conditionResult: true}}
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"bash.run": step})
+	m.lookPath = func(file string) (string, error) {
+		if file == "bash" {
+			return "/bin/bash", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	t.Run("runs when the shell is installed", func(t *testing.T) {
+		_, err := m.CleanupWorktree(t.TempDir(), "feature", cleanupConfig("bash.run"), CleanupOptions{Quiet: true})
+
+		require.NoError(t, err)
+		assert.True(t, step.runCalled)
+	})
+
+	t.Run("fails before running when the shell is missing", func(t *testing.T) {
+		step.runCalled = false
+		cfg := cleanupConfig("bash.run")
+		cfg.Shell = "zsh"
+
+		_, err := m.CleanupWorktree(t.TempDir(), "feature", cfg, CleanupOptions{Quiet: true})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Missing shells:\n  - zsh (cleanup.steps[0])")
+		assert.False(t, step.runCalled)
+	})
+}
+
+func TestScaffoldManager_GetStepsForWorktree_Requires(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{
+		"bash.run": &mockStep{name: "bash.run"},
+		"env.read": &mockStep{name: "env.read"},
+	})
+	disabled := false
+	stepsConfig := func(steps ...config.StepConfig) *config.Config {
+		return &config.Config{Scaffold: config.ScaffoldConfig{Override: true, Steps: steps}}
+	}
+
+	t.Run("accepts variables provided earlier or built in", func(t *testing.T) {
+		cfg := stepsConfig(
+			config.StepConfig{Name: "bash.run", Command: "hostname", StoreAs: "Host"},
+			config.StepConfig{Name: "env.read", Key: "APP_URL"},
+			config.StepConfig{Name: "bash.run", Command: "make token", Provides: []string{"Token"}},
+			config.StepConfig{Name: "bash.run", Command: "deploy", Requires: []string{"Host", "APP_URL", "Token", "DbSuffix"}},
+		)
+
+		_, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports variables provided later, by disabled steps or not at all", func(t *testing.T) {
+		cfg := stepsConfig(
+			config.StepConfig{Name: "bash.run", Command: "deploy", Requires: []string{"OriginalApp"}},
+			config.StepConfig{Name: "bash.run", Command: "make token", Provides: []string{"Token"}, Enabled: &disabled},
+			config.StepConfig{Name: "bash.run", Command: "cp -r ../app .", Provides: []string{"OriginalApp"}},
+			config.StepConfig{Name: "bash.run", Command: "use", Requires: []string{"Token"}},
+		)
+
+		_, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+
+		assert.EqualError(t, err, "2 invalid scaffold step configs:\n"+
+			"  - scaffold.steps[0] (bash.run): requires OriginalApp, which no earlier step provides\n"+
+			"  - scaffold.steps[3] (bash.run): requires Token, which no earlier step provides")
+	})
+}
+
+func TestScaffoldManager_CleanupWorktree(t *testing.T) {
+	db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, resource: types.Resource{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}
+	herd := &planningStep{mockStep: mockStep{name: "herd", conditionResult: true, runError: errors.New("herd failed")}, resource: types.Resource{Kind: types.ResourceHerdLink, Name: "feature"}}
+
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd})
+	removed, err := m.CleanupWorktree(t.TempDir(), "feature", cleanupConfig("db.destroy", "herd"), CleanupOptions{SiteName: "feature", Quiet: true})
+
+	assert.Error(t, err)
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, removed, "resources removed before the failure are reported")
+}
+
+// suffixStep records the db suffix and DB_CONNECTION visible in the worktree
+// when it runs.
+type suffixStep struct {
+	mockStep
+	suffix     string
+	connection string
+}
+
+func (s *suffixStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	s.runCalled = true
+	state, err := config.ReadLocalState(ctx.WorktreePath)
+	if err != nil {
+		return err
+	}
+	s.suffix = state.DbSuffix
+	s.connection = utils.ReadEnvFile(ctx.WorktreePath, ".env")["DB_CONNECTION"]
+	ctx.RecordRemoved(types.ResourceDatabase, "app_"+state.DbSuffix)
+	return nil
+}
+
+func TestScaffoldManager_RunOrphanCleanup(t *testing.T) {
+	record := config.WorktreeRecord{
+		Path:         filepath.Join(t.TempDir(), "feature"),
+		Branch:       "feature",
+		DbSuffix:     "swift_runner",
+		DbConnection: "mysql",
+	}
+
+	t.Run("stages recorded state and removes the placeholder", func(t *testing.T) {
+		step := &suffixStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}}
+		m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": step})
+
+		removed, err := m.RunOrphanCleanup(record, cleanupConfig("db.destroy"), "", types.PromptMode{}, false, false, true)
+
+		require.NoError(t, err)
+		assert.True(t, step.runCalled)
+		assert.Equal(t, "swift_runner", step.suffix)
+		assert.Equal(t, "mysql", step.connection)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, removed)
+		assert.NoDirExists(t, record.Path)
+	})
+
+	t.Run("dry run plans without running", func(t *testing.T) {
+		db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, resource: types.Resource{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}
+		m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db})
+
+		planned, err := m.RunOrphanCleanup(record, cleanupConfig("db.destroy"), "", types.PromptMode{}, true, false, true)
+
+		require.NoError(t, err)
+		assert.False(t, db.runCalled)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, planned)
+		assert.NoDirExists(t, record.Path)
+	})
+
+	t.Run("refuses existing directories", func(t *testing.T) {
+		existing := record
+		existing.Path = t.TempDir()
+		m := NewScaffoldManagerWithRegistry(stubRegistry{})
+
+		_, err := m.RunOrphanCleanup(existing, cleanupConfig(), "", types.PromptMode{}, false, false, true)
+
+		assert.Error(t, err)
+		assert.DirExists(t, existing.Path)
+	})
+}
+
+func TestScaffoldReport(t *testing.T) {
+	results := []ExecutionResult{
+		{Step: &mockStep{name: "php.composer"}, Duration: 1500 * time.Millisecond},
+		{Step: &mockStep{name: "herd"}, Skipped: true, SkipReason: SkipConditionNot},
+		{Step: &mockStep{name: "db.create"}, Error: errors.New("connection refused"), Duration: 20 * time.Millisecond},
+	}
+
+	report := scaffoldReport(time.Now(), results, errors.New("step db.create failed"))
+
+	assert.Equal(t, "step db.create failed", report.Error)
+	assert.Equal(t, []config.ScaffoldStepReport{
+		{Name: "php.composer", Status: StepCompleted, DurationMs: 1500},
+		{Name: "herd", Status: StepSkipped, Reason: SkipConditionNot},
+		{Name: "db.create", Status: StepFailed, Error: "connection refused", DurationMs: 20},
+	}, report.Steps)
+}
+
+func TestGenerateDbSuffix(t *testing.T) {
+	t.Run("branch naming derives the suffix from the branch", func(t *testing.T) {
+		suffix, err := generateDbSuffix(nil, &config.Config{DbNaming: "branch"}, t.TempDir(), "feature/auth", "")
+		require.NoError(t, err)
+		assert.Equal(t, "feature_auth", suffix)
+	})
+
+	t.Run("random naming follows the naming config", func(t *testing.T) {
+		suffix, err := generateDbSuffix(nil, &config.Config{Naming: config.NamingConfig{Style: "hash", SuffixLength: 4}}, t.TempDir(), "feature/auth", "")
+		require.NoError(t, err)
+		assert.Len(t, suffix, 4)
+	})
+
+	t.Run("rejects unknown modes", func(t *testing.T) {
+		_, err := generateDbSuffix(nil, &config.Config{DbNaming: "uuid"}, t.TempDir(), "feature/auth", "")
+		assert.ErrorContains(t, err, "unknown db_naming")
+	})
+}
+
+// stubPreset is a preset with fixed steps, detected everywhere.
+type stubPreset struct {
+	version int
+	steps   []config.StepConfig
+	cleanup []config.CleanupStep
+}
+
+func (p stubPreset) Name() string                       { return "stub" }
+func (p stubPreset) Version() int                       { return p.version }
+func (p stubPreset) Detect(path string) bool            { return true }
+func (p stubPreset) DefaultSteps() []config.StepConfig  { return p.steps }
+func (p stubPreset) CleanupSteps() []config.CleanupStep { return p.cleanup }
+
+func TestScaffoldManager_PresetOverrides(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{
+		"node.npm":    &mockStep{name: "node.npm"},
+		"php.laravel": &mockStep{name: "php.laravel"},
+		"herd":        &mockStep{name: "herd"},
+		"db.destroy":  &mockStep{name: "db.destroy"},
+	})
+	m.RegisterPreset(stubPreset{
+		steps: []config.StepConfig{
+			{Name: "node.npm", Args: []string{"ci"}},
+			{Name: "php.laravel", Args: []string{"migrate:fresh", "--seed"}},
+			{Name: "node.npm", Args: []string{"run", "build"}},
+		},
+		cleanup: []config.CleanupStep{{Name: "herd"}, {Name: "db.destroy"}},
+	})
+	disabled := false
+
+	t.Run("disables and patches the steps selected", func(t *testing.T) {
+		cfg := &config.Config{Scaffold: config.ScaffoldConfig{PresetOverrides: config.PresetOverrides{
+			"node.npm ci":               {Enabled: &disabled},
+			"php.laravel migrate:fresh": {Args: []string{"migrate", "--force"}},
+		}}}
+
+		stepConfigs := m.StepConfigsForWorktree(cfg, t.TempDir())
+
+		require.Len(t, stepConfigs, 3)
+		assert.Equal(t, &disabled, stepConfigs[0].Enabled)
+		assert.Equal(t, []string{"migrate", "--force"}, stepConfigs[1].Args)
+		assert.Nil(t, stepConfigs[2].Enabled, "node.npm run build is not selected by node.npm ci")
+
+		stepsList, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+		require.NoError(t, err)
+		assert.False(t, isStepEnabled(stepsList[0]))
+		assert.True(t, isStepEnabled(stepsList[1]))
+	})
+
+	t.Run("a step name selects every step of that name", func(t *testing.T) {
+		cfg := &config.Config{Scaffold: config.ScaffoldConfig{PresetOverrides: config.PresetOverrides{
+			"node.npm": {Enabled: &disabled},
+		}}}
+
+		stepConfigs := m.StepConfigsForWorktree(cfg, t.TempDir())
+
+		assert.Equal(t, &disabled, stepConfigs[0].Enabled)
+		assert.Equal(t, &disabled, stepConfigs[2].Enabled)
+	})
+
+	t.Run("a more specific selector wins", func(t *testing.T) {
+		enabled := true
+		cfg := &config.Config{Scaffold: config.ScaffoldConfig{PresetOverrides: config.PresetOverrides{
+			"node.npm ci": {Enabled: &enabled},
+			"node.npm":    {Enabled: &disabled},
+		}}}
+
+		stepConfigs := m.StepConfigsForWorktree(cfg, t.TempDir())
+
+		assert.Equal(t, &enabled, stepConfigs[0].Enabled)
+		assert.Equal(t, &disabled, stepConfigs[2].Enabled)
+	})
+
+	t.Run("warns about overrides that select no step", func(t *testing.T) {
+		cfg := &config.Config{Scaffold: config.ScaffoldConfig{PresetOverrides: config.PresetOverrides{
+			"node.npm install": {Enabled: &disabled},
+		}}}
+
+		_, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+		require.NoError(t, err, "a selector matching nothing does not stop the scaffold")
+
+		_, _, warnings := m.scaffoldStepsForWorktree(cfg, t.TempDir())
+		assert.Equal(t, []string{`scaffold.preset_overrides["node.npm install"] selects no scaffold step of preset stub`}, warnings)
+	})
+
+	t.Run("ignores overrides when the preset steps are overridden", func(t *testing.T) {
+		cfg := &config.Config{Scaffold: config.ScaffoldConfig{Override: true, PresetOverrides: config.PresetOverrides{
+			"node.npm install": {Enabled: &disabled},
+		}}}
+
+		_, _, warnings := m.scaffoldStepsForWorktree(cfg, t.TempDir())
+
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("disables preset cleanup steps", func(t *testing.T) {
+		cfg := &config.Config{Cleanup: config.CleanupConfig{PresetOverrides: config.PresetOverrides{
+			"db.destroy": {Enabled: &disabled},
+		}}}
+
+		stepsList, err := m.GetCleanupSteps(cfg, t.TempDir(), "feature")
+
+		require.NoError(t, err)
+		require.Len(t, stepsList, 2)
+		assert.True(t, isStepEnabled(stepsList[0]))
+		assert.False(t, isStepEnabled(stepsList[1]))
+	})
+}
+
+func TestScaffoldManager_CleanupWorktree_SkipsDisabledSteps(t *testing.T) {
+	db := &mockStep{name: "db.destroy", conditionResult: true}
+	herd := &mockStep{name: "herd", conditionResult: true}
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd})
+	m.RegisterPreset(stubPreset{cleanup: []config.CleanupStep{{Name: "herd"}, {Name: "db.destroy"}}})
+	disabled := false
+	cfg := &config.Config{Cleanup: config.CleanupConfig{PresetOverrides: config.PresetOverrides{"db.destroy": {Enabled: &disabled}}}}
+
+	_, err := m.CleanupWorktree(t.TempDir(), "feature", cfg, CleanupOptions{Quiet: true})
+
+	require.NoError(t, err)
+	assert.True(t, herd.runCalled)
+	assert.False(t, db.runCalled, "disabled steps do not run")
+}
+
+func TestScaffoldManager_StepGroups(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{
+		"node.npm":   &mockStep{name: "node.npm"},
+		"herd":       &mockStep{name: "herd"},
+		"db.destroy": &mockStep{name: "db.destroy"},
+	})
+	cfg := &config.Config{
+		StepGroups: map[string][]config.StepConfig{
+			"frontend": {{Name: "node.npm", Args: []string{"ci"}}, {Name: "node.npm", Args: []string{"run", "build"}}},
+			"teardown": {{Name: "herd"}, {Name: "db.destroy"}},
+		},
+		Scaffold: config.ScaffoldConfig{Override: true, Steps: []config.StepConfig{{Group: "frontend"}, {Group: "backend"}}},
+		Cleanup:  config.CleanupConfig{Steps: []config.CleanupStep{{Group: "teardown"}}},
+	}
+
+	_, err := m.GetStepsForWorktree(cfg, t.TempDir(), "feature")
+	assert.EqualError(t, err, "invalid scaffold step config:\n"+
+		"  - scaffold.steps[1] (group backend): no such step group in step_groups")
+
+	cfg.Scaffold.Steps = cfg.Scaffold.Steps[:1]
+	stepConfigs := m.StepConfigsForWorktree(cfg, t.TempDir())
+	assert.Equal(t, cfg.StepGroups["frontend"], stepConfigs)
+
+	cleanupSteps, err := m.GetCleanupSteps(cfg, t.TempDir(), "feature")
+	require.NoError(t, err)
+	require.Len(t, cleanupSteps, 2)
+	assert.Equal(t, "herd", cleanupSteps[0].Name())
+	assert.Equal(t, "db.destroy", cleanupSteps[1].Name())
+}
+
+func TestScaffoldManager_ScaffoldDefaults(t *testing.T) {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"node.npm": &mockStep{name: "node.npm"}, "bash.run": &mockStep{name: "bash.run"}})
+	m.RegisterPreset(stubPreset{steps: []config.StepConfig{{Name: "node.npm", Args: []string{"ci"}}}})
+	cfg := &config.Config{Scaffold: config.ScaffoldConfig{
+		Defaults: config.StepDefaults{Lock: "shared"},
+		Steps:    []config.StepConfig{{Name: "bash.run", Command: "make", Lock: "make"}},
+	}}
+
+	stepConfigs := m.StepConfigsForWorktree(cfg, t.TempDir())
+
+	require.Len(t, stepConfigs, 2)
+	assert.Equal(t, "shared", stepConfigs[0].Lock, "preset steps get the defaults too")
+	assert.Equal(t, "make", stepConfigs[1].Lock)
+}
@@ -0,0 +1,106 @@
+package scaffold
+
+import (
+	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// RunOptions configures ScaffoldWorktree. The zero value runs with no repo,
+// site or preset name, no bare repository and default prompting.
+type RunOptions struct {
+	RepoName string
+	SiteName string
+	// Preset names the preset the worktree uses, for steps and templates.
+	// Which steps run is decided by the config, not by this field.
+	Preset     string
+	BarePath   string
+	PromptMode types.PromptMode
+	DryRun     bool
+	Verbose    bool
+	Quiet      bool
+	// Progress, when set, is called as each step starts and finishes.
+	Progress func(StepEvent)
+	// Answers, when set, replays recorded prompt answers and records the
+	// answers given.
+	Answers *prompts.Answers
+	// Sandbox runs command steps in the sandbox even when arbor.yaml does
+	// not enable it (--sandbox).
+	Sandbox bool
+}
+
+// CleanupOptions configures CleanupWorktree and PlanWorktreeCleanup. It
+// takes the same settings as a scaffold run; planning ignores DryRun,
+// Verbose, Quiet and Progress.
+type CleanupOptions RunOptions
+
+// Option sets one field of RunOptions or CleanupOptions, for callers that
+// build them from a list of choices rather than a struct literal.
+type Option func(*RunOptions)
+
+// NewRunOptions returns RunOptions with opts applied in order.
+func NewRunOptions(opts ...Option) RunOptions {
+	var o RunOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewCleanupOptions returns CleanupOptions with opts applied in order.
+func NewCleanupOptions(opts ...Option) CleanupOptions {
+	return CleanupOptions(NewRunOptions(opts...))
+}
+
+// WithNames sets the repo, site and preset names.
+func WithNames(repoName, siteName, preset string) Option {
+	return func(o *RunOptions) {
+		o.RepoName = repoName
+		o.SiteName = siteName
+		o.Preset = preset
+	}
+}
+
+// WithBarePath sets the project's bare repository.
+func WithBarePath(barePath string) Option {
+	return func(o *RunOptions) { o.BarePath = barePath }
+}
+
+// WithPromptMode sets how steps may prompt.
+func WithPromptMode(mode types.PromptMode) Option {
+	return func(o *RunOptions) { o.PromptMode = mode }
+}
+
+// WithDryRun sets whether steps only report what they would do.
+func WithDryRun(dryRun bool) Option {
+	return func(o *RunOptions) { o.DryRun = dryRun }
+}
+
+// WithVerbose sets whether steps print their commands and output.
+func WithVerbose(verbose bool) Option {
+	return func(o *RunOptions) { o.Verbose = verbose }
+}
+
+// WithQuiet sets whether progress output is suppressed.
+func WithQuiet(quiet bool) Option {
+	return func(o *RunOptions) { o.Quiet = quiet }
+}
+
+// WithProgress sets the callback reporting each step's progress.
+func WithProgress(fn func(StepEvent)) Option {
+	return func(o *RunOptions) { o.Progress = fn }
+}
+
+// WithAnswers sets the prompt answers to replay and record.
+func WithAnswers(answers *prompts.Answers) Option {
+	return func(o *RunOptions) { o.Answers = answers }
+}
+
+// stepOptions returns the options passed to each step.
+func (o RunOptions) stepOptions() types.StepOptions {
+	return types.StepOptions{
+		DryRun:     o.DryRun,
+		Verbose:    o.Verbose,
+		Quiet:      o.Quiet,
+		PromptMode: o.PromptMode,
+	}
+}
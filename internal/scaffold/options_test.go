@@ -0,0 +1,48 @@
+package scaffold
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestNewRunOptions(t *testing.T) {
+	mode := types.PromptMode{CI: true}
+	opts := NewRunOptions(
+		WithNames("repo", "site", "laravel"),
+		WithBarePath("/project/.bare"),
+		WithPromptMode(mode),
+		WithDryRun(true),
+		WithQuiet(true),
+	)
+
+	assert.Equal(t, RunOptions{
+		RepoName:   "repo",
+		SiteName:   "site",
+		Preset:     "laravel",
+		BarePath:   "/project/.bare",
+		PromptMode: mode,
+		DryRun:     true,
+		Quiet:      true,
+	}, opts)
+	assert.Equal(t, types.StepOptions{DryRun: true, Quiet: true, PromptMode: mode}, opts.stepOptions())
+}
+
+func TestNewCleanupOptions(t *testing.T) {
+	opts := NewCleanupOptions(WithVerbose(true), WithVerbose(false), WithNames("", "site", ""))
+	assert.Equal(t, CleanupOptions{SiteName: "site"}, opts, "later options win")
+}
+
+func TestScaffoldManager_DeprecatedCleanupMatchesCleanupWorktree(t *testing.T) {
+	db := &planningStep{mockStep: mockStep{name: "db.destroy", conditionResult: true}, resource: types.Resource{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}
+	herd := &planningStep{mockStep: mockStep{name: "herd", conditionResult: true, runError: errors.New("herd failed")}, resource: types.Resource{Kind: types.ResourceHerdLink, Name: "feature"}}
+
+	m := NewScaffoldManagerWithRegistry(stubRegistry{"db.destroy": db, "herd": herd})
+	removed, err := m.RunCleanupWithReport(t.TempDir(), "feature", "", "feature", "", cleanupConfig("db.destroy", "herd"), "", types.PromptMode{}, false, false, true)
+
+	assert.ErrorContains(t, err, "herd failed")
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, removed)
+}
@@ -0,0 +1,78 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// RunOrphanCleanup runs the cleanup steps for a worktree whose directory no
+// longer exists, using the state saved in its project-level record. In dry
+// run mode the cleanup is planned instead and the resources it would remove
+// are returned.
+//
+// Cleanup steps read their state from the worktree (.arbor.local, env file) and
+// run commands such as 'herd unlink' from inside it, so a placeholder
+// directory holding the recorded state is staged at the original path for
+// the duration of the cleanup and removed afterwards.
+func (m *ScaffoldManager) RunOrphanCleanup(record config.WorktreeRecord, cfg *config.Config, barePath string, promptMode types.PromptMode, dryRun, verbose, quiet bool) ([]types.Resource, error) {
+	if _, err := os.Lstat(record.Path); err == nil {
+		return nil, fmt.Errorf("worktree directory %s still exists", record.Path)
+	}
+
+	if err := stageOrphanPlaceholder(record, cfg.PrimaryEnvFile()); err != nil {
+		_ = os.RemoveAll(record.Path)
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(record.Path) }()
+
+	// Preset detection needs the worktree files, so fall back to the preset
+	// recorded when the worktree was scaffolded.
+	cleanupCfg := *cfg
+	if cleanupCfg.Preset == "" {
+		cleanupCfg.Preset = record.Preset
+	}
+
+	siteName := record.SiteName
+	if siteName == "" {
+		siteName = filepath.Base(record.Path)
+	}
+
+	opts := CleanupOptions{
+		SiteName:   siteName,
+		Preset:     cleanupCfg.Preset,
+		BarePath:   barePath,
+		PromptMode: promptMode,
+		Verbose:    verbose,
+		Quiet:      quiet,
+	}
+	if dryRun {
+		return m.PlanWorktreeCleanup(record.Path, record.Branch, &cleanupCfg, opts)
+	}
+	return m.CleanupWorktree(record.Path, record.Branch, &cleanupCfg, opts)
+}
+
+func stageOrphanPlaceholder(record config.WorktreeRecord, envFile string) error {
+	if err := os.MkdirAll(record.Path, 0755); err != nil {
+		return fmt.Errorf("creating placeholder for %s: %w", record.Path, err)
+	}
+	if record.DbSuffix != "" {
+		if err := config.WriteLocalState(record.Path, config.LocalState{DbSuffix: record.DbSuffix}); err != nil {
+			return err
+		}
+	}
+	if record.DbConnection != "" {
+		content := fmt.Sprintf("DB_CONNECTION=%s\n", record.DbConnection)
+		envPath := filepath.Join(record.Path, envFile)
+		if err := os.MkdirAll(filepath.Dir(envPath), 0755); err != nil {
+			return fmt.Errorf("creating placeholder for %s: %w", envFile, err)
+		}
+		if err := os.WriteFile(envPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing placeholder %s: %w", envFile, err)
+		}
+	}
+	return nil
+}
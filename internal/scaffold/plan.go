@@ -0,0 +1,225 @@
+package scaffold
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// PlanEntry is a step of a worktree's scaffold plan, in the order it
+// runs.
+type PlanEntry struct {
+	Config   config.StepConfig
+	Location string
+	// Group is the step group the step was included from, or "".
+	Group string
+	// Needs are the variables the step requires from earlier steps, each
+	// with the step that provides it.
+	Needs []PlanDependency
+}
+
+// PlanDependency is a variable a step requires and the index of the
+// earlier step that provides it.
+type PlanDependency struct {
+	Step int
+	Var  string
+}
+
+// Enabled reports whether the step runs; disabled steps are skipped.
+func (s PlanEntry) Enabled() bool {
+	return s.Config.Enabled == nil || *s.Config.Enabled
+}
+
+// Label names the step by its name and arguments, or its command for
+// command steps, e.g. "node.npm run build".
+func (s PlanEntry) Label() string {
+	if s.Config.Command != "" && (s.Config.Name == "bash.run" || s.Config.Name == "command.run") {
+		return s.Config.Name + ": " + s.Config.Command
+	}
+	return strings.TrimSpace(s.Config.Name + " " + strings.Join(s.Config.Args, " "))
+}
+
+// Notes describe when and how the step runs: its condition keys, its
+// lock, and whether it is disabled or may fail.
+func (s PlanEntry) Notes() []string {
+	var notes []string
+	if !s.Enabled() {
+		notes = append(notes, "disabled")
+	}
+	if len(s.Config.Condition) > 0 {
+		keys := make([]string, 0, len(s.Config.Condition))
+		for key := range s.Config.Condition {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		notes = append(notes, "when "+strings.Join(keys, ", "))
+	}
+	if s.Config.Lock != "" {
+		notes = append(notes, "lock "+s.Config.Lock)
+	}
+	if s.Config.ContinueOnError {
+		notes = append(notes, "continue on error")
+	}
+	return notes
+}
+
+// PlanScaffold returns the steps a scaffold of the worktree at
+// worktreePath runs, in order, with the variables each takes from earlier
+// steps. Like a scaffold, it fails with a *StepConfigError when a step
+// config is invalid.
+func (m *ScaffoldManager) PlanScaffold(cfg *config.Config, worktreePath string) ([]PlanEntry, error) {
+	located, problems, _ := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	if _, err := m.createSteps("scaffold", located, problems, cfg.Policy); err != nil {
+		return nil, err
+	}
+
+	providers := make(map[string]int)
+	planned := make([]PlanEntry, len(located))
+	for i, step := range located {
+		planned[i] = PlanEntry{Config: step.cfg, Location: step.location, Group: step.group}
+		if !planned[i].Enabled() {
+			continue
+		}
+		// Built-in variables have no provider, and createSteps has
+		// checked every other one comes from an earlier step.
+		for _, name := range step.cfg.Requires {
+			if provider, ok := providers[name]; ok {
+				planned[i].Needs = append(planned[i].Needs, PlanDependency{Step: provider, Var: name})
+			}
+		}
+		for _, name := range step.cfg.ProvidedVars() {
+			providers[name] = i
+		}
+	}
+	return planned, nil
+}
+
+// Graph formats accepted by RenderPlanGraph.
+const (
+	GraphMermaid = "mermaid"
+	GraphDOT     = "dot"
+)
+
+// RenderPlanGraph draws a scaffold plan as a Mermaid flowchart or a
+// Graphviz DOT digraph. Solid edges follow the order steps run in, dashed
+// edges connect the step providing a variable to the steps requiring it,
+// and the steps of each group reference are boxed together.
+func RenderPlanGraph(steps []PlanEntry, format string) (string, error) {
+	switch format {
+	case GraphMermaid:
+		return renderMermaid(steps), nil
+	case GraphDOT:
+		return renderDOT(steps), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (expected %s or %s)", format, GraphMermaid, GraphDOT)
+}
+
+// planRuns splits the steps into runs of consecutive steps from the same
+// group reference, or single steps listed directly, as index ranges.
+func planRuns(steps []PlanEntry) [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(steps); {
+		end := i + 1
+		if steps[i].Group != "" {
+			reference, _, _ := strings.Cut(steps[i].Location, " > ")
+			for end < len(steps) && steps[end].Group == steps[i].Group && strings.HasPrefix(steps[end].Location, reference+" > ") {
+				end++
+			}
+		}
+		runs = append(runs, [2]int{i, end})
+		i = end
+	}
+	return runs
+}
+
+func renderMermaid(steps []PlanEntry) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	node := func(indent string, i int) {
+		label := mermaidEscape(fmt.Sprintf("%d. %s", i+1, steps[i].Label()))
+		for _, note := range steps[i].Notes() {
+			label += "<br/><i>" + mermaidEscape(note) + "</i>"
+		}
+		fmt.Fprintf(&b, "%ss%d[\"%s\"]\n", indent, i, label)
+	}
+	for n, run := range planRuns(steps) {
+		if steps[run[0]].Group == "" {
+			node("  ", run[0])
+			continue
+		}
+		fmt.Fprintf(&b, "  subgraph g%d[\"group %s\"]\n", n, mermaidEscape(steps[run[0]].Group))
+		for i := run[0]; i < run[1]; i++ {
+			node("    ", i)
+		}
+		b.WriteString("  end\n")
+	}
+	for i := 1; i < len(steps); i++ {
+		fmt.Fprintf(&b, "  s%d --> s%d\n", i-1, i)
+	}
+	for i, step := range steps {
+		for _, need := range step.Needs {
+			fmt.Fprintf(&b, "  s%d -. %s .-> s%d\n", need.Step, need.Var, i)
+		}
+	}
+	var disabled []string
+	for i, step := range steps {
+		if !step.Enabled() {
+			disabled = append(disabled, fmt.Sprintf("s%d", i))
+		}
+	}
+	if len(disabled) > 0 {
+		b.WriteString("  classDef disabled stroke-dasharray: 5 5,color:#999\n")
+		fmt.Fprintf(&b, "  class %s disabled\n", strings.Join(disabled, ","))
+	}
+	return b.String()
+}
+
+func renderDOT(steps []PlanEntry) string {
+	var b strings.Builder
+	b.WriteString("digraph scaffold {\n  node [shape=box];\n")
+	node := func(indent string, i int) {
+		label := fmt.Sprintf("%d. %s", i+1, steps[i].Label())
+		for _, note := range steps[i].Notes() {
+			label += "\n" + note
+		}
+		attrs := "label=" + dotQuote(label)
+		if !steps[i].Enabled() {
+			attrs += ", style=dashed, fontcolor=gray"
+		}
+		fmt.Fprintf(&b, "%ss%d [%s];\n", indent, i, attrs)
+	}
+	for n, run := range planRuns(steps) {
+		if steps[run[0]].Group == "" {
+			node("  ", run[0])
+			continue
+		}
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%s;\n", n, dotQuote("group "+steps[run[0]].Group))
+		for i := run[0]; i < run[1]; i++ {
+			node("    ", i)
+		}
+		b.WriteString("  }\n")
+	}
+	for i := 1; i < len(steps); i++ {
+		fmt.Fprintf(&b, "  s%d -> s%d;\n", i-1, i)
+	}
+	for i, step := range steps {
+		for _, need := range step.Needs {
+			fmt.Fprintf(&b, "  s%d -> s%d [style=dashed, label=%s];\n", need.Step, i, dotQuote(need.Var))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaidEscape replaces the characters that end or mark up a Mermaid
+// label with entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// dotQuote returns s as a DOT string literal.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
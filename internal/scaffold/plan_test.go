@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func planTestConfig() *config.Config {
+	return &config.Config{
+		StepGroups: map[string][]config.StepConfig{
+			"assets": {
+				{Name: "node.npm", Args: []string{"ci"}},
+				{Name: "node.npm", Args: []string{"run", "build"}, Lock: "npm"},
+			},
+		},
+		Scaffold: config.ScaffoldConfig{Steps: []config.StepConfig{
+			{Name: "bash.run", Command: `echo "app" > name`, StoreAs: "app"},
+			{Group: "assets", Condition: map[string]interface{}{"file_exists": "package.json"}},
+			{Name: "bash.run", Command: "echo {{ .app }}", Requires: []string{"app", "Path"}},
+		}},
+	}
+}
+
+func planTestManager() *ScaffoldManager {
+	return NewScaffoldManagerWithRegistry(stubRegistry{
+		"bash.run": &mockStep{name: "bash.run"},
+		"node.npm": &mockStep{name: "node.npm"},
+	})
+}
+
+func TestScaffoldManager_PlanScaffold(t *testing.T) {
+	steps, err := planTestManager().PlanScaffold(planTestConfig(), t.TempDir())
+
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+	assert.Equal(t, "bash.run: echo \"app\" > name", steps[0].Label())
+	assert.Equal(t, "assets", steps[1].Group)
+	assert.Equal(t, "node.npm run build", steps[2].Label())
+	assert.Equal(t, []string{"when file_exists", "lock npm"}, steps[2].Notes())
+	assert.Equal(t, []PlanDependency{{Step: 0, Var: "app"}}, steps[3].Needs, "built-in variables have no provider")
+}
+
+func TestScaffoldManager_PlanScaffold_InvalidConfig(t *testing.T) {
+	cfg := &config.Config{Scaffold: config.ScaffoldConfig{Steps: []config.StepConfig{{Group: "missing"}}}}
+
+	_, err := planTestManager().PlanScaffold(cfg, t.TempDir())
+
+	var configErr *StepConfigError
+	assert.ErrorAs(t, err, &configErr)
+}
+
+func TestScaffoldManager_ValidateScaffoldPlan(t *testing.T) {
+	worktree := t.TempDir()
+	manager := NewScaffoldManagerWithRegistry(stubRegistry{
+		"bash.run":  &mockStep{name: "bash.run", conditionResult: true},
+		"env.write": &validatingStep{mockStep: mockStep{name: "env.write", conditionResult: true}, validateErr: errors.New("name too long")},
+	})
+	cfg := &config.Config{Scaffold: config.ScaffoldConfig{Steps: []config.StepConfig{
+		{Name: "bash.run", Command: "true"},
+		{Name: "env.write", Key: "DB_DATABASE", Value: "{{ .DbName }}"},
+	}}}
+
+	problems, err := manager.ValidateScaffoldPlan(worktree, "feature/x", cfg, RunOptions{SiteName: "shop"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]error{1: errors.New("name too long")}, problems)
+	assert.NoFileExists(t, filepath.Join(worktree, ".arbor.local"), "planning does not write the worktree's suffix")
+}
+
+func TestRenderPlanGraph(t *testing.T) {
+	steps, err := planTestManager().PlanScaffold(planTestConfig(), t.TempDir())
+	require.NoError(t, err)
+
+	mermaid, err := RenderPlanGraph(steps, GraphMermaid)
+	require.NoError(t, err)
+	assert.Equal(t, `flowchart TD
+  s0["1. bash.run: echo #quot;app#quot; #gt; name"]
+  subgraph g1["group assets"]
+    s1["2. node.npm ci<br/><i>when file_exists</i>"]
+    s2["3. node.npm run build<br/><i>when file_exists</i><br/><i>lock npm</i>"]
+  end
+  s3["4. bash.run: echo {{ .app }}"]
+  s0 --> s1
+  s1 --> s2
+  s2 --> s3
+  s0 -. app .-> s3
+`, mermaid)
+
+	dot, err := RenderPlanGraph(steps, GraphDOT)
+	require.NoError(t, err)
+	assert.Equal(t, `digraph scaffold {
+  node [shape=box];
+  s0 [label="1. bash.run: echo \"app\" > name"];
+  subgraph cluster_1 {
+    label="group assets";
+    s1 [label="2. node.npm ci\nwhen file_exists"];
+    s2 [label="3. node.npm run build\nwhen file_exists\nlock npm"];
+  }
+  s3 [label="4. bash.run: echo {{ .app }}"];
+  s0 -> s1;
+  s1 -> s2;
+  s2 -> s3;
+  s0 -> s3 [style=dashed, label="app"];
+}
+`, dot)
+
+	_, err = RenderPlanGraph(steps, "svg")
+	assert.EqualError(t, err, `unknown graph format "svg" (expected mermaid or dot)`)
+}
+
+func TestPlanRuns_SeparatesGroupReferences(t *testing.T) {
+	steps := []PlanEntry{
+		{Location: "scaffold.steps[0] > step_groups.a[0]", Group: "a"},
+		{Location: "scaffold.steps[0] > step_groups.a[1]", Group: "a"},
+		{Location: "scaffold.steps[1] > step_groups.a[0]", Group: "a"},
+		{Location: "scaffold.steps[2]"},
+	}
+
+	assert.Equal(t, [][2]int{{0, 2}, {2, 3}, {3, 4}}, planRuns(steps))
+}
@@ -0,0 +1,143 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// PresetStatus compares the preset steps a worktree's last full scaffold
+// ran with the ones this arbor would run.
+type PresetStatus struct {
+	// Name and Version are the preset this arbor runs for the worktree.
+	Name    string
+	Version int
+	// ScaffoldedName and ScaffoldedVersion are the preset the worktree was
+	// last scaffolded with, or "" and 0 when that was not recorded.
+	ScaffoldedName    string
+	ScaffoldedVersion int
+	// Changed are the indexes of the preset steps the worktree has not
+	// run: steps added or changed since its last scaffold.
+	Changed []int
+}
+
+// Recorded reports whether the worktree's last scaffold recorded its
+// preset steps. Worktrees scaffolded by an older arbor have no record.
+func (s *PresetStatus) Recorded() bool {
+	return s.ScaffoldedName != ""
+}
+
+// Outdated reports whether this arbor's preset has steps the worktree's
+// last scaffold did not run.
+func (s *PresetStatus) Outdated() bool {
+	return s.Recorded() && len(s.Changed) > 0
+}
+
+// PresetStatus returns how the preset steps a scaffold of the worktree at
+// worktreePath would run differ from the ones its last scaffold ran. It
+// returns nil when no preset steps run: no preset applies, or
+// scaffold.override is set.
+func (m *ScaffoldManager) PresetStatus(cfg *config.Config, worktreePath string) (*PresetStatus, error) {
+	preset, ok := m.scaffoldPreset(cfg, worktreePath)
+	if !ok {
+		return nil, nil
+	}
+	state, err := m.configs.LocalState(worktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading local state: %w", err)
+	}
+
+	status := &PresetStatus{
+		Name:              preset.Name(),
+		Version:           preset.Version(),
+		ScaffoldedName:    state.Preset,
+		ScaffoldedVersion: state.PresetVersion,
+	}
+	for i, hash := range presetStepHashes(preset) {
+		// A different preset shares no steps with this one, even where
+		// they happen to match.
+		if state.Preset != preset.Name() || !slices.Contains(state.PresetSteps, hash) {
+			status.Changed = append(status.Changed, i)
+		}
+	}
+	return status, nil
+}
+
+// RecordPresetSteps records in the worktree's .arbor.local the preset whose
+// steps a scaffold of it runs, with their hashes, so PresetStatus can tell
+// when a later arbor changes them. It records nothing when no preset steps
+// run.
+func (m *ScaffoldManager) RecordPresetSteps(cfg *config.Config, worktreePath string) error {
+	preset, ok := m.scaffoldPreset(cfg, worktreePath)
+	if !ok {
+		return nil
+	}
+	if err := config.SetPresetRecord(worktreePath, preset.Name(), preset.Version(), presetStepHashes(preset)); err != nil {
+		return fmt.Errorf("recording preset steps: %w", err)
+	}
+	return nil
+}
+
+// PresetUpgradeConfig returns a copy of cfg whose scaffold runs only the
+// preset steps status lists as changed, and the earlier preset steps
+// providing variables they require, with scaffold.preset_overrides and
+// scaffold.defaults applied. None of arbor.yaml's own steps run.
+func (m *ScaffoldManager) PresetUpgradeConfig(cfg *config.Config, worktreePath string, status *PresetStatus) (*config.Config, error) {
+	located, problems, _ := m.scaffoldStepsForWorktree(cfg, worktreePath)
+	if _, err := m.createSteps("scaffold", located, problems, cfg.Policy); err != nil {
+		return nil, err
+	}
+
+	// The preset's steps come first, in order, and a step's providers
+	// come before it, so walking back marks providers of providers too.
+	run := make([]bool, len(located))
+	for _, i := range status.Changed {
+		run[i] = true
+	}
+	for i := len(run) - 1; i >= 0; i-- {
+		if !run[i] {
+			continue
+		}
+		for _, name := range located[i].cfg.Requires {
+			for j := i - 1; j >= 0; j-- {
+				if slices.Contains(located[j].cfg.ProvidedVars(), name) {
+					run[j] = true
+					break
+				}
+			}
+		}
+	}
+
+	upgrade := *cfg
+	upgrade.Scaffold.Override = true
+	upgrade.Scaffold.PresetOverrides = nil
+	upgrade.Scaffold.Defaults = config.StepDefaults{}
+	upgrade.Scaffold.Steps = nil
+	for i, step := range located {
+		if run[i] {
+			upgrade.Scaffold.Steps = append(upgrade.Scaffold.Steps, step.cfg)
+		}
+	}
+	return &upgrade, nil
+}
+
+// scaffoldPreset returns the preset whose steps a scaffold of the worktree
+// runs, if any.
+func (m *ScaffoldManager) scaffoldPreset(cfg *config.Config, worktreePath string) (Preset, bool) {
+	if cfg.Scaffold.Override {
+		return nil, false
+	}
+	return m.GetPreset(m.presetName(cfg, worktreePath))
+}
+
+// presetStepHashes returns a hash of each of the preset's scaffold steps
+// as the preset defines them, before the project's overrides.
+func presetStepHashes(preset Preset) []string {
+	steps := preset.DefaultSteps()
+	hashes := make([]string, len(steps))
+	for i, step := range steps {
+		hashes[i] = configHash(step)
+	}
+	return hashes
+}
@@ -0,0 +1,98 @@
+package scaffold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func presetVersionTestManager(preset stubPreset) *ScaffoldManager {
+	m := NewScaffoldManagerWithRegistry(stubRegistry{
+		"bash.run": &mockStep{name: "bash.run"},
+		"node.npm": &mockStep{name: "node.npm"},
+	})
+	m.RegisterPreset(preset)
+	return m
+}
+
+func TestScaffoldManager_PresetStatus(t *testing.T) {
+	v1 := stubPreset{version: 1, steps: []config.StepConfig{
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "node.npm", Args: []string{"run", "build"}},
+	}}
+	v2 := stubPreset{version: 2, steps: []config.StepConfig{
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "node.npm", Args: []string{"run", "build", "--production"}},
+		{Name: "bash.run", Command: "echo done"},
+	}}
+	cfg := &config.Config{}
+
+	t.Run("a worktree without a record is not outdated", func(t *testing.T) {
+		status, err := presetVersionTestManager(v2).PresetStatus(cfg, t.TempDir())
+
+		require.NoError(t, err)
+		assert.Equal(t, "stub", status.Name)
+		assert.False(t, status.Recorded())
+		assert.False(t, status.Outdated())
+	})
+
+	t.Run("lists the steps added or changed since the recorded scaffold", func(t *testing.T) {
+		path := t.TempDir()
+		require.NoError(t, presetVersionTestManager(v1).RecordPresetSteps(cfg, path))
+
+		status, err := presetVersionTestManager(v2).PresetStatus(cfg, path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, status.ScaffoldedVersion)
+		assert.Equal(t, 2, status.Version)
+		assert.Equal(t, []int{1, 2}, status.Changed)
+		assert.True(t, status.Outdated())
+	})
+
+	t.Run("is up to date once recorded", func(t *testing.T) {
+		path := t.TempDir()
+		m := presetVersionTestManager(v2)
+		require.NoError(t, m.RecordPresetSteps(cfg, path))
+
+		status, err := m.PresetStatus(cfg, path)
+
+		require.NoError(t, err)
+		assert.Empty(t, status.Changed)
+		assert.False(t, status.Outdated())
+	})
+
+	t.Run("is nil when scaffold.override is set", func(t *testing.T) {
+		status, err := presetVersionTestManager(v2).PresetStatus(&config.Config{Scaffold: config.ScaffoldConfig{Override: true}}, t.TempDir())
+
+		require.NoError(t, err)
+		assert.Nil(t, status)
+	})
+}
+
+func TestScaffoldManager_PresetUpgradeConfig(t *testing.T) {
+	disabled := false
+	m := presetVersionTestManager(stubPreset{version: 2, steps: []config.StepConfig{
+		{Name: "bash.run", Command: "echo key", StoreAs: "key"},
+		{Name: "node.npm", Args: []string{"ci"}},
+		{Name: "bash.run", Command: "echo {{ .key }}", Requires: []string{"key"}},
+	}})
+	cfg := &config.Config{Scaffold: config.ScaffoldConfig{
+		Steps:           []config.StepConfig{{Name: "bash.run", Command: "echo project"}},
+		PresetOverrides: config.PresetOverrides{"node.npm": {Enabled: &disabled}},
+		Defaults:        config.StepDefaults{Lock: "deps"},
+	}}
+
+	upgrade, err := m.PresetUpgradeConfig(cfg, t.TempDir(), &PresetStatus{Changed: []int{1, 2}})
+
+	require.NoError(t, err)
+	assert.True(t, upgrade.Scaffold.Override)
+	assert.Empty(t, upgrade.Scaffold.PresetOverrides)
+	require.Len(t, upgrade.Scaffold.Steps, 3, "the step providing key runs again")
+	assert.Equal(t, "echo key", upgrade.Scaffold.Steps[0].Command)
+	assert.Equal(t, &disabled, upgrade.Scaffold.Steps[1].Enabled)
+	assert.Equal(t, "deps", upgrade.Scaffold.Steps[2].Lock)
+	assert.False(t, cfg.Scaffold.Override, "cfg is not modified")
+}
@@ -0,0 +1,162 @@
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Answer keys for the prompts scaffold steps ask.
+const (
+	// AnswerDatabase is "new", or "reuse:<branch>" to share the database
+	// of the worktree on branch.
+	AnswerDatabase = "db.database"
+	// AnswerMigrate is "yes" or "no".
+	AnswerMigrate = "db.migrate"
+)
+
+// AnswerNewDatabase is the AnswerDatabase value for creating a database.
+const AnswerNewDatabase = "new"
+
+// answersHeader starts every answers file written by Save.
+const answersHeader = "# Scaffold answers recorded by arbor.\n# Replay them with: arbor work BRANCH --answers FILE\n"
+
+// Answers are prompt answers keyed by prompt. Steps look up an answer
+// before prompting, so a loaded file replays a teammate's choices, and
+// record the answers they are given, so they can be saved and shared.
+// Recorded answers are not replayed in the same run, so a prompt asked by
+// several steps is asked each time. A nil *Answers has no answers and
+// records nothing.
+type Answers struct {
+	mu       sync.Mutex
+	replay   map[string]string
+	recorded map[string]string
+}
+
+// NewAnswers returns an empty set of answers.
+func NewAnswers() *Answers {
+	return &Answers{replay: make(map[string]string), recorded: make(map[string]string)}
+}
+
+// answersFile is the on-disk form of Answers.
+type answersFile struct {
+	Answers map[string]any `yaml:"answers"`
+}
+
+// LoadAnswers reads answers from a file written by Save. Values may be
+// hand-edited, so YAML booleans are read as yes or no.
+func LoadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers: %w", err)
+	}
+	var file answersFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing answers %s: %w", path, err)
+	}
+
+	a := NewAnswers()
+	for key, value := range file.Answers {
+		switch v := value.(type) {
+		case bool:
+			a.replay[key] = YesNo(v)
+		default:
+			a.replay[key] = fmt.Sprint(v)
+		}
+	}
+	return a, nil
+}
+
+// Get returns the loaded answer for key.
+func (a *Answers) Get(key string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	value, ok := a.replay[key]
+	return value, ok
+}
+
+// Set records the answer given for key. When several are given, the
+// last is kept.
+func (a *Answers) Set(key, value string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recorded[key] = value
+}
+
+// Len returns the number of answers loaded or recorded.
+func (a *Answers) Len() int {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.merged())
+}
+
+// merged returns the loaded answers overlaid with the recorded ones.
+func (a *Answers) merged() map[string]string {
+	values := make(map[string]string, len(a.replay)+len(a.recorded))
+	for key, value := range a.replay {
+		values[key] = value
+	}
+	for key, value := range a.recorded {
+		values[key] = value
+	}
+	return values
+}
+
+// Save writes the loaded and recorded answers to path, sorted by key.
+func (a *Answers) Save(path string) error {
+	a.mu.Lock()
+	values := a.merged()
+	a.mu.Unlock()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(answersHeader)
+	b.WriteString("answers:\n")
+	for _, key := range keys {
+		value, err := yaml.Marshal(values[key])
+		if err != nil {
+			return fmt.Errorf("encoding answer %s: %w", key, err)
+		}
+		fmt.Fprintf(&b, "  %s: %s", key, value)
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("writing answers: %w", err)
+	}
+	return nil
+}
+
+// YesNo returns the answer for a confirmation.
+func YesNo(confirmed bool) string {
+	if confirmed {
+		return "yes"
+	}
+	return "no"
+}
+
+// IsYes reports whether a recorded confirmation answer is affirmative.
+func IsYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "yes", "y", "true":
+		return true
+	}
+	return false
+}
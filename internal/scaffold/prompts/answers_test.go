@@ -0,0 +1,51 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnswers_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team-defaults.yaml")
+
+	a := NewAnswers()
+	a.Set(AnswerMigrate, "yes")
+	a.Set(AnswerDatabase, "reuse:main")
+	_, replayed := a.Get(AnswerMigrate)
+	assert.False(t, replayed, "recorded answers are not replayed in the same run")
+	require.NoError(t, a.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, answersHeader+"answers:\n  db.database: reuse:main\n  db.migrate: \"yes\"\n", string(data))
+
+	loaded, err := LoadAnswers(path)
+	require.NoError(t, err)
+	answer, ok := loaded.Get(AnswerDatabase)
+	assert.True(t, ok)
+	assert.Equal(t, "reuse:main", answer)
+	assert.Equal(t, 2, loaded.Len())
+}
+
+func TestLoadAnswers_HandEdited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("answers:\n  db.migrate: false\n"), 0644))
+
+	loaded, err := LoadAnswers(path)
+	require.NoError(t, err)
+	answer, _ := loaded.Get(AnswerMigrate)
+	assert.Equal(t, "no", answer)
+	assert.False(t, IsYes(answer))
+}
+
+func TestAnswers_Nil(t *testing.T) {
+	var a *Answers
+	a.Set(AnswerMigrate, "yes")
+	_, ok := a.Get(AnswerMigrate)
+	assert.False(t, ok)
+	assert.Equal(t, 0, a.Len())
+}
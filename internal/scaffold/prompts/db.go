@@ -4,6 +4,9 @@ package prompts
 type DatabaseOption struct {
 	Label    string
 	DbSuffix string
+	// Branch is the branch of the worktree using the database; empty for
+	// creating a new one.
+	Branch string
 }
 
 // DbPrompter defines the prompt contract for database-related steps.
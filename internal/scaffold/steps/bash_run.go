@@ -5,17 +5,25 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
 	"github.com/artisanexperiences/arbor/internal/scaffold/template"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
 type BashRunStep struct {
-	command  string
-	storeAs  string
+	command string
+	storeAs string
+	// shell is the step's shell setting; empty uses the project's shell.
+	shell    string
 	executor *arbor_exec.CommandExecutor
 }
 
+var (
+	_ types.ScaffoldStep = (*BashRunStep)(nil)
+	_ types.ShellStep    = (*BashRunStep)(nil)
+)
+
 // NewBashRunStep creates a bash step with the default command executor.
 func NewBashRunStep(command string, storeAs string) *BashRunStep {
 	return NewBashRunStepWithExecutor(command, storeAs, nil)
@@ -34,6 +42,14 @@ func NewBashRunStepWithExecutor(command string, storeAs string, executor *arbor_
 	}
 }
 
+// NewBashRunStepFromConfig creates a bash step from its arbor.yaml config.
+// This is the factory function used by the registry.
+func NewBashRunStepFromConfig(cfg config.StepConfig) *BashRunStep {
+	step := NewBashRunStep(cfg.Command, cfg.StoreAs)
+	step.shell = cfg.Shell
+	return step
+}
+
 func (s *BashRunStep) Name() string {
 	return "bash.run"
 }
@@ -43,9 +59,12 @@ func (s *BashRunStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	if err != nil {
 		return fmt.Errorf("template replacement failed: %w", err)
 	}
+	if pattern, denied := ctx.Policy.DeniedCommand(command); denied {
+		return fmt.Errorf("bash.run: command matches policy.deny_commands pattern %q", pattern)
+	}
 
 	// Use the command executor for testability
-	output, err := s.executor.RunBash(context.Background(), ctx.WorktreePath, command)
+	output, err := sandboxed(ctx, s.executor).RunWithShell(context.Background(), ctx.WorktreePath, s.Shell(ctx), command)
 	if err != nil {
 		return fmt.Errorf("bash.run failed: %w\n%s", err, string(output))
 	}
@@ -60,6 +79,18 @@ func (s *BashRunStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	return nil
 }
 
+// Shell returns the shell the step runs its command with: the step's own
+// shell, else the project's, else bash.
+func (s *BashRunStep) Shell(ctx *types.ScaffoldContext) string {
+	if s.shell != "" {
+		return s.shell
+	}
+	if ctx.Shell != "" {
+		return ctx.Shell
+	}
+	return config.DefaultShell
+}
+
 func (s *BashRunStep) Condition(ctx *types.ScaffoldContext) bool {
 	return true
 }
@@ -1,11 +1,15 @@
 package steps
 
 import (
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
 	"github.com/artisanexperiences/arbor/internal/scaffold/template"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
@@ -181,3 +185,67 @@ func (s *BashRunStep) replaceTemplateForTest(str string, ctx *types.ScaffoldCont
 func (s *BashRunStep) templateReplaceForTest(str string, ctx *types.ScaffoldContext) (string, error) {
 	return template.ReplaceTemplateVars(str, ctx)
 }
+
+func TestBashRunStep_DenyCommands(t *testing.T) {
+	dir := t.TempDir()
+	step := NewBashRunStep("{{ .setup }} && touch ran", "")
+	ctx := &types.ScaffoldContext{
+		WorktreePath: dir,
+		Policy:       config.PolicyConfig{DenyCommands: []string{"rm -rf"}},
+	}
+	ctx.SetVar("setup", "rm -rf build")
+
+	err := step.Run(ctx, types.StepOptions{})
+
+	assert.ErrorContains(t, err, `command matches policy.deny_commands pattern "rm -rf"`)
+	assert.NoFileExists(t, filepath.Join(dir, "ran"), "a denied command is not run")
+}
+
+func TestBashRunStep_Sandbox(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the sandbox is not supported on Windows")
+	}
+
+	mock := arbor_exec.NewMockCommander()
+	step := NewBashRunStepWithExecutor("echo $SECRET", "", arbor_exec.NewCommandExecutor(mock))
+	ctx := &types.ScaffoldContext{
+		WorktreePath: "/work/feature",
+		Sandbox:      config.SandboxConfig{Enabled: true},
+	}
+
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	call := mock.LastCall()
+	require.NotNil(t, call)
+	assert.Equal(t, "env", call.Command, "sandboxed commands run with a cleared environment")
+	assert.Equal(t, "-i", call.Args[0])
+	assert.Equal(t, []string{"bash", "-c", "echo $SECRET"}, call.Args[len(call.Args)-3:])
+}
+
+func TestBashRunStep_Shell(t *testing.T) {
+	t.Run("runs with the step's shell", func(t *testing.T) {
+		dir := t.TempDir()
+		step := NewBashRunStepFromConfig(config.StepConfig{Command: "echo $0 > shell.txt", Shell: "sh"})
+
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: dir}, types.StepOptions{}))
+		assert.FileExists(t, filepath.Join(dir, "shell.txt"))
+	})
+
+	t.Run("falls back to the project's shell, then bash", func(t *testing.T) {
+		step := NewBashRunStepFromConfig(config.StepConfig{Command: "ls"})
+		assert.Equal(t, "bash", step.Shell(&types.ScaffoldContext{}))
+		assert.Equal(t, "pwsh", step.Shell(&types.ScaffoldContext{Shell: "pwsh"}))
+
+		own := NewBashRunStepFromConfig(config.StepConfig{Command: "ls", Shell: "zsh"})
+		assert.Equal(t, "zsh", own.Shell(&types.ScaffoldContext{Shell: "pwsh"}))
+	})
+
+	t.Run("passes the command to pwsh as one argument", func(t *testing.T) {
+		mock := arbor_exec.NewMockCommander()
+		step := NewBashRunStepWithExecutor("Write-Output \"a b\"", "", arbor_exec.NewCommandExecutor(mock))
+
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: "/work", Shell: "pwsh"}, types.StepOptions{}))
+		assert.Equal(t, "pwsh", mock.LastCall().Command)
+		assert.Equal(t, []string{"-NoProfile", "-NonInteractive", "-Command", "Write-Output \"a b\""}, mock.LastCall().Args)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/artisanexperiences/arbor/internal/config"
@@ -21,6 +22,12 @@ type BinaryStep struct {
 	executor  *arbor_exec.CommandExecutor
 }
 
+var (
+	_ types.ScaffoldStep   = (*BinaryStep)(nil)
+	_ types.CleanupPlanner = (*BinaryStep)(nil)
+	_ types.Describer      = (*BinaryStep)(nil)
+)
+
 // NewBinaryStep creates a binary step with the default command executor.
 func NewBinaryStep(name, binary string, args []string, storeAs string) *BinaryStep {
 	return NewBinaryStepWithExecutor(name, binary, args, storeAs, nil)
@@ -63,6 +70,18 @@ func (s *BinaryStep) GetArgs() []string {
 	return s.args
 }
 
+// Description names the program the step runs and its subcommand, such as
+// "Running composer install" or "Running artisan migrate:fresh". Steps
+// without args are described by their registered description.
+func (s *BinaryStep) Description() string {
+	if len(s.args) == 0 {
+		return ""
+	}
+	program := strings.TrimPrefix(s.binary, "php ")
+	subcommand, _, _ := strings.Cut(s.args[0], " ")
+	return fmt.Sprintf("Running %s %s", program, subcommand)
+}
+
 func (s *BinaryStep) Condition(ctx *types.ScaffoldContext) bool {
 	if len(s.condition) > 0 {
 		result, err := ctx.EvaluateCondition(s.condition)
@@ -90,11 +109,15 @@ func (s *BinaryStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) err
 	}
 
 	// Use the command executor for testability
-	output, err := s.executor.RunBinary(context.Background(), ctx.WorktreePath, s.binary, allArgs)
+	output, err := sandboxed(ctx, s.executor).RunBinary(context.Background(), ctx.WorktreePath, s.binary, allArgs)
 	if err != nil {
 		return fmt.Errorf("%s failed: %w\n%s", s.name, err, string(output))
 	}
 
+	if site := s.herdUnlinkSite(ctx); site != "" {
+		ctx.RecordRemoved(types.ResourceHerdLink, site)
+	}
+
 	if s.storeAs != "" {
 		ctx.SetVar(s.storeAs, strings.TrimSpace(string(output)))
 		if opts.Verbose {
@@ -105,6 +128,31 @@ func (s *BinaryStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) err
 	return nil
 }
 
+// PlanCleanup reports the Herd link removed by a `herd unlink` step.
+// Other binary steps remove nothing arbor can track.
+func (s *BinaryStep) PlanCleanup(ctx *types.ScaffoldContext, opts types.StepOptions) ([]types.Resource, error) {
+	site := s.herdUnlinkSite(ctx)
+	if site == "" {
+		return nil, nil
+	}
+	return []types.Resource{{Kind: types.ResourceHerdLink, Name: site}}, nil
+}
+
+// herdUnlinkSite returns the site a `herd unlink [name]` step removes, or ""
+// for any other step. Without a name Herd unlinks the working directory.
+func (s *BinaryStep) herdUnlinkSite(ctx *types.ScaffoldContext) string {
+	if s.binary != "herd" || len(s.args) == 0 || s.args[0] != "unlink" {
+		return ""
+	}
+	if len(s.args) > 1 {
+		if site, err := template.ReplaceTemplateVars(s.args[1], ctx); err == nil {
+			return site
+		}
+		return s.args[1]
+	}
+	return filepath.Base(ctx.WorktreePath)
+}
+
 func (s *BinaryStep) replaceTemplate(args []string, ctx *types.ScaffoldContext) []string {
 	for i, arg := range args {
 		replaced, err := template.ReplaceTemplateVars(arg, ctx)
@@ -681,3 +681,33 @@ func TestBinaryStep_OutputCapture(t *testing.T) {
 		assert.Equal(t, "PhpOutput", binaryStep.storeAs)
 	})
 }
+
+func TestBinaryStep_PlanCleanup(t *testing.T) {
+	t.Run("herd unlink reports the worktree site", func(t *testing.T) {
+		step := NewBinaryStep("herd", "herd", []string{"unlink"}, "")
+		ctx := &types.ScaffoldContext{WorktreePath: "/projects/myapp/feature-auth"}
+
+		resources, err := step.PlanCleanup(ctx, types.StepOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceHerdLink, Name: "feature-auth"}}, resources)
+	})
+
+	t.Run("herd unlink with a name reports that site", func(t *testing.T) {
+		step := NewBinaryStep("herd", "herd", []string{"unlink", "{{ .SiteName }}"}, "")
+		ctx := &types.ScaffoldContext{WorktreePath: "/projects/myapp/feature-auth", SiteName: "myapp"}
+
+		resources, err := step.PlanCleanup(ctx, types.StepOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceHerdLink, Name: "myapp"}}, resources)
+	})
+
+	t.Run("other binaries plan nothing", func(t *testing.T) {
+		step := NewBinaryStep("herd", "herd", []string{"link"}, "")
+		resources, err := step.PlanCleanup(&types.ScaffoldContext{WorktreePath: t.TempDir()}, types.StepOptions{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+}
@@ -15,6 +15,8 @@ type CommandRunStep struct {
 	executor *arbor_exec.CommandExecutor
 }
 
+var _ types.ScaffoldStep = (*CommandRunStep)(nil)
+
 // NewCommandRunStep creates a command step with the default command executor.
 func NewCommandRunStep(command string, storeAs string) *CommandRunStep {
 	return NewCommandRunStepWithExecutor(command, storeAs, nil)
@@ -38,8 +40,11 @@ func (s *CommandRunStep) Name() string {
 }
 
 func (s *CommandRunStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	if pattern, denied := ctx.Policy.DeniedCommand(s.command); denied {
+		return fmt.Errorf("command.run: command matches policy.deny_commands pattern %q", pattern)
+	}
 	// Use the command executor for testability
-	output, err := s.executor.RunShell(context.Background(), ctx.WorktreePath, s.command)
+	output, err := sandboxed(ctx, s.executor).RunShell(context.Background(), ctx.WorktreePath, s.command)
 	if err != nil {
 		return fmt.Errorf("command.run failed: %w\n%s", err, string(output))
 	}
@@ -0,0 +1,272 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// composerLinkPrefix starts the name of every repository composer.link
+// adds, so composer.unlink removes those and leaves the project's own.
+const composerLinkPrefix = "arbor-link-"
+
+// ComposerLinkStep registers local packages, such as a sibling worktree of
+// a package being developed alongside the app, as composer path
+// repositories and updates just those packages so they are symlinked into
+// vendor.
+type ComposerLinkStep struct {
+	paths    []string
+	args     []string
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep = (*ComposerLinkStep)(nil)
+	_ types.Describer    = (*ComposerLinkStep)(nil)
+)
+
+// NewComposerLinkStep creates a composer.link step with the default command executor.
+func NewComposerLinkStep(cfg config.StepConfig) *ComposerLinkStep {
+	return NewComposerLinkStepWithExecutor(cfg, nil)
+}
+
+// NewComposerLinkStepWithExecutor creates a composer.link step with a custom
+// command executor. This is useful for testing with mock executors.
+func NewComposerLinkStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *ComposerLinkStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	return &ComposerLinkStep{paths: cfg.Paths, args: cfg.Args, executor: executor}
+}
+
+func (s *ComposerLinkStep) Name() string {
+	return "composer.link"
+}
+
+// Description counts the packages, such as "Linking 2 composer packages".
+func (s *ComposerLinkStep) Description() string {
+	if len(s.paths) == 1 {
+		return "Linking 1 composer package"
+	}
+	return fmt.Sprintf("Linking %d composer packages", len(s.paths))
+}
+
+func (s *ComposerLinkStep) Condition(ctx *types.ScaffoldContext) bool {
+	_, err := os.Stat(filepath.Join(ctx.WorktreePath, "composer.json"))
+	return err == nil
+}
+
+func (s *ComposerLinkStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	executor := sandboxed(ctx, s.executor)
+
+	var names []string
+	for _, p := range s.paths {
+		path, err := template.ReplaceTemplateVars(p, ctx)
+		if err != nil {
+			return fmt.Errorf("template replacement failed: %w", err)
+		}
+		name, err := composerPackageName(resolvePath(ctx.WorktreePath, path))
+		if err != nil {
+			return fmt.Errorf("composer.link: %w", err)
+		}
+
+		// composer resolves relative path repositories against the
+		// directory of composer.json, which is the worktree.
+		key := "repositories." + composerLinkRepository(name)
+		output, err := executor.RunBinary(context.Background(), ctx.WorktreePath, "composer", []string{"config", key, "path", path})
+		if err != nil {
+			return fmt.Errorf("composer.link: registering %s failed: %w\n%s", path, err, string(output))
+		}
+		if opts.Verbose {
+			fmt.Printf("  Linked %s from %s\n", name, path)
+		}
+		names = append(names, name)
+	}
+
+	return composerUpdate(ctx, executor, "composer.link", names, s.args)
+}
+
+// ComposerUnlinkStep removes the path repositories composer.link added and
+// updates the linked packages back to their released versions, so
+// composer.json and composer.lock can be committed. It does nothing when no
+// links are registered.
+type ComposerUnlinkStep struct {
+	args     []string
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep = (*ComposerUnlinkStep)(nil)
+	_ types.Describer    = (*ComposerUnlinkStep)(nil)
+)
+
+// NewComposerUnlinkStep creates a composer.unlink step with the default command executor.
+func NewComposerUnlinkStep(cfg config.StepConfig) *ComposerUnlinkStep {
+	return NewComposerUnlinkStepWithExecutor(cfg, nil)
+}
+
+// NewComposerUnlinkStepWithExecutor creates a composer.unlink step with a
+// custom command executor. This is useful for testing with mock executors.
+func NewComposerUnlinkStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *ComposerUnlinkStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	return &ComposerUnlinkStep{args: cfg.Args, executor: executor}
+}
+
+func (s *ComposerUnlinkStep) Name() string {
+	return "composer.unlink"
+}
+
+func (s *ComposerUnlinkStep) Description() string {
+	return "Unlinking composer packages"
+}
+
+func (s *ComposerUnlinkStep) Condition(ctx *types.ScaffoldContext) bool {
+	links, err := ComposerLinks(ctx.WorktreePath)
+	return err == nil && len(links) > 0
+}
+
+func (s *ComposerUnlinkStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	links, err := ComposerLinks(ctx.WorktreePath)
+	if err != nil {
+		return fmt.Errorf("composer.unlink: %w", err)
+	}
+	executor := sandboxed(ctx, s.executor)
+
+	var names []string
+	for _, link := range links {
+		output, err := executor.RunBinary(context.Background(), ctx.WorktreePath, "composer", []string{"config", "--unset", "repositories." + link.Repository})
+		if err != nil {
+			return fmt.Errorf("composer.unlink: removing %s failed: %w\n%s", link.Repository, err, string(output))
+		}
+		if opts.Verbose {
+			fmt.Printf("  Unlinked %s\n", link.Path)
+		}
+		// A package whose directory is gone cannot be named; it is left
+		// for the next full composer update.
+		if name, err := composerPackageName(resolvePath(ctx.WorktreePath, link.Path)); err == nil {
+			names = append(names, name)
+		}
+	}
+
+	return composerUpdate(ctx, executor, "composer.unlink", names, s.args)
+}
+
+// ComposerLink is a path repository added by composer.link.
+type ComposerLink struct {
+	// Repository is the repository's name in composer.json.
+	Repository string
+	// Path is the package directory, as written in composer.json.
+	Path string
+}
+
+// ComposerLinks returns the path repositories composer.link registered in
+// the composer.json in dir, in name order. Composer writes named
+// repositories either as an object keyed by name or as a list of entries
+// with a name field; both are read.
+func ComposerLinks(dir string) ([]ComposerLink, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading composer.json: %w", err)
+	}
+	var manifest struct {
+		Repositories json.RawMessage `json:"repositories"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing composer.json: %w", err)
+	}
+
+	type repository struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+	// Entries that are not objects, such as "packagist.org": false, fail
+	// to decode and are skipped.
+	var repos []repository
+	var byName map[string]json.RawMessage
+	var list []json.RawMessage
+	if err := json.Unmarshal(manifest.Repositories, &byName); err == nil {
+		for name, raw := range byName {
+			var repo repository
+			if json.Unmarshal(raw, &repo) == nil {
+				repo.Name = name
+				repos = append(repos, repo)
+			}
+		}
+	} else if err := json.Unmarshal(manifest.Repositories, &list); err == nil {
+		for _, raw := range list {
+			var repo repository
+			if json.Unmarshal(raw, &repo) == nil {
+				repos = append(repos, repo)
+			}
+		}
+	}
+
+	var links []ComposerLink
+	for _, repo := range repos {
+		if repo.Type == "path" && strings.HasPrefix(repo.Name, composerLinkPrefix) {
+			links = append(links, ComposerLink{Repository: repo.Name, Path: repo.URL})
+		}
+	}
+	slices.SortFunc(links, func(a, b ComposerLink) int {
+		return strings.Compare(a.Repository, b.Repository)
+	})
+	return links, nil
+}
+
+// composerLinkRepository returns the repository name composer.link uses
+// for a package, such as arbor-link-acme-billing for acme/billing.
+func composerLinkRepository(pkg string) string {
+	return composerLinkPrefix + strings.ReplaceAll(pkg, "/", "-")
+}
+
+// composerPackageName returns the name declared in the composer.json in dir.
+func composerPackageName(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return "", fmt.Errorf("reading package composer.json: %w", err)
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", filepath.Join(dir, "composer.json"), err)
+	}
+	if manifest.Name == "" {
+		return "", fmt.Errorf("%s has no package name", filepath.Join(dir, "composer.json"))
+	}
+	return manifest.Name, nil
+}
+
+// composerUpdate updates only the named packages, leaving the rest of
+// composer.lock as it is.
+func composerUpdate(ctx *types.ScaffoldContext, executor *arbor_exec.CommandExecutor, step string, names, extra []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	args := append([]string{"update"}, names...)
+	args = append(args, extra...)
+	output, err := executor.RunBinary(context.Background(), ctx.WorktreePath, "composer", args)
+	if err != nil {
+		return fmt.Errorf("%s: composer update failed: %w\n%s", step, err, string(output))
+	}
+	return nil
+}
+
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
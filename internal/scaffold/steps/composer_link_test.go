@@ -0,0 +1,110 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func writeComposerJSON(t *testing.T, dir, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "composer.json"), []byte(content), 0644))
+}
+
+func TestComposerLinkStep(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writeComposerJSON(t, app, `{"name": "acme/app"}`)
+	writeComposerJSON(t, filepath.Join(root, "billing"), `{"name": "acme/billing"}`)
+
+	mock := arbor_exec.NewMockCommander()
+	step := NewComposerLinkStepWithExecutor(config.StepConfig{
+		Paths: []string{"../{{ .Package }}"},
+		Args:  []string{"--no-scripts"},
+	}, arbor_exec.NewCommandExecutor(mock))
+	ctx := &types.ScaffoldContext{WorktreePath: app}
+	ctx.SetVar("Package", "billing")
+
+	assert.Equal(t, "Linking 1 composer package", step.Description())
+	require.True(t, step.Condition(ctx))
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	require.Equal(t, 2, mock.CallCount())
+	assert.Equal(t, "composer", mock.GetCall(0).Command)
+	assert.Equal(t, []string{"config", "repositories.arbor-link-acme-billing", "path", "../billing"}, mock.GetCall(0).Args)
+	assert.Equal(t, []string{"update", "acme/billing", "--no-scripts"}, mock.GetCall(1).Args)
+	assert.Equal(t, app, mock.GetCall(1).Dir)
+}
+
+func TestComposerLinkStep_MissingPackage(t *testing.T) {
+	app := t.TempDir()
+	writeComposerJSON(t, app, `{"name": "acme/app"}`)
+
+	mock := arbor_exec.NewMockCommander()
+	step := NewComposerLinkStepWithExecutor(config.StepConfig{Paths: []string{"../missing"}}, arbor_exec.NewCommandExecutor(mock))
+
+	err := step.Run(&types.ScaffoldContext{WorktreePath: app}, types.StepOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading package composer.json")
+	assert.Zero(t, mock.CallCount())
+}
+
+func TestComposerUnlinkStep(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writeComposerJSON(t, app, `{
+		"name": "acme/app",
+		"repositories": {
+			"packagist.org": false,
+			"private": {"type": "composer", "url": "https://repo.example.com"},
+			"arbor-link-acme-billing": {"type": "path", "url": "../billing"},
+			"arbor-link-acme-gone": {"type": "path", "url": "../gone"}
+		}
+	}`)
+	writeComposerJSON(t, filepath.Join(root, "billing"), `{"name": "acme/billing"}`)
+
+	mock := arbor_exec.NewMockCommander()
+	step := NewComposerUnlinkStepWithExecutor(config.StepConfig{}, arbor_exec.NewCommandExecutor(mock))
+	ctx := &types.ScaffoldContext{WorktreePath: app}
+
+	require.True(t, step.Condition(ctx))
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	require.Equal(t, 3, mock.CallCount())
+	assert.Equal(t, []string{"config", "--unset", "repositories.arbor-link-acme-billing"}, mock.GetCall(0).Args)
+	assert.Equal(t, []string{"config", "--unset", "repositories.arbor-link-acme-gone"}, mock.GetCall(1).Args)
+	assert.Equal(t, []string{"update", "acme/billing"}, mock.GetCall(2).Args, "packages whose directory is gone are not updated")
+}
+
+func TestComposerLinks(t *testing.T) {
+	t.Run("list of repositories", func(t *testing.T) {
+		dir := t.TempDir()
+		writeComposerJSON(t, dir, `{"repositories": [
+			{"packagist.org": false},
+			{"name": "arbor-link-acme-billing", "type": "path", "url": "../billing"},
+			{"name": "local", "type": "path", "url": "../local"}
+		]}`)
+
+		links, err := ComposerLinks(dir)
+		require.NoError(t, err)
+		assert.Equal(t, []ComposerLink{{Repository: "arbor-link-acme-billing", Path: "../billing"}}, links)
+	})
+
+	t.Run("no repositories", func(t *testing.T) {
+		dir := t.TempDir()
+		writeComposerJSON(t, dir, `{"name": "acme/app"}`)
+
+		links, err := ComposerLinks(dir)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+		assert.False(t, NewComposerUnlinkStep(config.StepConfig{}).Condition(&types.ScaffoldContext{WorktreePath: dir}))
+	})
+}
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/git"
 	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/scaffold/words"
 	"github.com/artisanexperiences/arbor/internal/ui"
@@ -20,43 +24,73 @@ type WorktreeDatabase struct {
 	WorktreePath string
 	Branch       string
 	DbSuffix     string
+	// Shared is set when the worktree chose to reuse another worktree's
+	// database.
+	Shared bool
+}
+
+// DbSuffixCollision is a database suffix that more than one worktree uses
+// as its own, e.g. after .arbor.local was copied from one to another.
+// Destroying either worktree would drop the other's databases.
+type DbSuffixCollision struct {
+	DbSuffix string
+	// Worktrees are the worktrees using DbSuffix, sorted by branch.
+	// Worktrees that chose to reuse the database are left out.
+	Worktrees []WorktreeDatabase
 }
 
 type DbCreateStep struct {
-	name          string
-	args          []string
-	dbType        string
-	clientFactory DatabaseClientFactory
-	prompter      prompts.DbPrompter
+	name                string
+	args                []string
+	dbType              string
+	onConnectionFailure string
+	tls                 dbTLS
+	createOpts          CreateOptions
+	clientFactory       DatabaseClientFactory
+	prompter            prompts.DbPrompter
 }
 
+var (
+	_ types.ScaffoldStep  = (*DbCreateStep)(nil)
+	_ types.PlanValidator = (*DbCreateStep)(nil)
+)
+
 func NewDbCreateStep(cfg config.StepConfig) *DbCreateStep {
 	return &DbCreateStep{
-		name:          "db.create",
-		args:          cfg.Args,
-		dbType:        cfg.Type,
-		clientFactory: DefaultDatabaseClientFactory,
-		prompter:      ui.UIDbPrompter{},
+		name:                "db.create",
+		args:                cfg.Args,
+		dbType:              cfg.Type,
+		onConnectionFailure: cfg.OnConnectionFailure,
+		tls:                 newDbTLS(cfg),
+		createOpts:          newCreateOptions(cfg),
+		clientFactory:       DefaultDatabaseClientFactory,
+		prompter:            ui.UIDbPrompter{},
 	}
 }
 
 func NewDbCreateStepWithFactory(cfg config.StepConfig, factory DatabaseClientFactory) *DbCreateStep {
 	return &DbCreateStep{
-		name:          "db.create",
-		args:          cfg.Args,
-		dbType:        cfg.Type,
-		clientFactory: factory,
-		prompter:      ui.UIDbPrompter{},
+		name:                "db.create",
+		args:                cfg.Args,
+		dbType:              cfg.Type,
+		onConnectionFailure: cfg.OnConnectionFailure,
+		tls:                 newDbTLS(cfg),
+		createOpts:          newCreateOptions(cfg),
+		clientFactory:       factory,
+		prompter:            ui.UIDbPrompter{},
 	}
 }
 
 func NewDbCreateStepWithPrompter(cfg config.StepConfig, factory DatabaseClientFactory, prompter prompts.DbPrompter) *DbCreateStep {
 	return &DbCreateStep{
-		name:          "db.create",
-		args:          cfg.Args,
-		dbType:        cfg.Type,
-		clientFactory: factory,
-		prompter:      prompter,
+		name:                "db.create",
+		args:                cfg.Args,
+		dbType:              cfg.Type,
+		onConnectionFailure: cfg.OnConnectionFailure,
+		tls:                 newDbTLS(cfg),
+		createOpts:          newCreateOptions(cfg),
+		clientFactory:       factory,
+		prompter:            prompter,
 	}
 }
 
@@ -89,7 +123,7 @@ func (s *DbCreateStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) e
 			}
 		}
 		if dbName == "" {
-			env := utils.ReadEnvFile(ctx.WorktreePath, ".env")
+			env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
 			dbName = env["DB_DATABASE"]
 		}
 		if dbName == "" {
@@ -108,6 +142,7 @@ func (s *DbCreateStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) e
 		if opts.Verbose {
 			fmt.Printf("  Using existing database with suffix: %s\n", ctx.GetDbSuffix())
 		}
+		ctx.SetVar("DbName", words.DatabaseName(s.getPrefixOrSiteName(ctx), ctx.GetDbSuffix(), 0))
 		// Still prompt for migrations even when reusing
 		if err := s.handleMigrationPrompt(ctx, opts); err != nil {
 			return err
@@ -128,6 +163,19 @@ func (s *DbCreateStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) e
 	return nil
 }
 
+// ValidatePlan sets DbName to the name the step would create, from its
+// --prefix and the worktree's suffix, so the steps after it check and
+// write that name. Database names are shortened to fit the engine, so the
+// step itself has nothing to report.
+func (s *DbCreateStep) ValidatePlan(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	engine, err := s.detectEngine(ctx)
+	if err != nil || engine == "sqlite" || ctx.GetDbSuffix() == "" {
+		return nil
+	}
+	ctx.SetVar("DbName", words.DatabaseName(s.getPrefixOrSiteName(ctx), ctx.GetDbSuffix(), 0))
+	return nil
+}
+
 func (s *DbCreateStep) detectEngine(ctx *types.ScaffoldContext) (string, error) {
 	if s.dbType != "" {
 		switch s.dbType {
@@ -138,7 +186,7 @@ func (s *DbCreateStep) detectEngine(ctx *types.ScaffoldContext) (string, error)
 		}
 	}
 
-	env := utils.ReadEnvFile(ctx.WorktreePath, ".env")
+	env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
 	if conn := env["DB_CONNECTION"]; conn != "" {
 		switch conn {
 		case "mysql", "mariadb":
@@ -150,19 +198,29 @@ func (s *DbCreateStep) detectEngine(ctx *types.ScaffoldContext) (string, error)
 		}
 	}
 
-	return "", fmt.Errorf("database type not specified and DB_CONNECTION not found in .env")
+	return "", fmt.Errorf("database type not specified and DB_CONNECTION not found in %s", ctx.PrimaryEnvFile())
 }
 
 func (s *DbCreateStep) getPrefixOrSiteName(ctx *types.ScaffoldContext) string {
-	for i, arg := range s.args {
-		if arg == "--prefix" && i+1 < len(s.args) {
-			return s.args[i+1]
+	return databasePrefix(s.args, ctx)
+}
+
+// databasePrefix returns the prefix of the database names a db step with
+// args uses: its --prefix, with template variables replaced, or else the
+// site name, APP_NAME or "app".
+func databasePrefix(args []string, ctx *types.ScaffoldContext) string {
+	for i, arg := range args {
+		if arg == "--prefix" && i+1 < len(args) {
+			if prefix, err := template.ReplaceTemplateVars(args[i+1], ctx); err == nil {
+				return prefix
+			}
+			return args[i+1]
 		}
 	}
 
 	siteName := ctx.SiteName
 	if siteName == "" {
-		env := utils.ReadEnvFile(ctx.WorktreePath, ".env")
+		env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
 		siteName = env["APP_NAME"]
 	}
 	if siteName == "" {
@@ -171,11 +229,13 @@ func (s *DbCreateStep) getPrefixOrSiteName(ctx *types.ScaffoldContext) string {
 	return siteName
 }
 
-func (s *DbCreateStep) parseConnectionOptions() DatabaseOptions {
+func (s *DbCreateStep) parseConnectionOptions(ctx *types.ScaffoldContext) DatabaseOptions {
 	opts := DatabaseOptions{
 		Host:     "127.0.0.1",
 		Username: "root",
+		Socket:   connectionSocket(s.args, ctx),
 	}
+	s.tls.apply(&opts, ctx)
 
 	for i, arg := range s.args {
 		if arg == "--username" && i+1 < len(s.args) {
@@ -197,23 +257,29 @@ func (s *DbCreateStep) parseConnectionOptions() DatabaseOptions {
 
 const maxDbCreateRetries = 5
 
+// maxDbConnectAttempts is the number of pings made with
+// on_connection_failure: retry.
+const maxDbConnectAttempts = 5
+
+// dbConnectBackoff is the delay before the first connection retry; it
+// doubles after each attempt.
+var dbConnectBackoff = 500 * time.Millisecond
+
 func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string, opts types.StepOptions) error {
 	siteName := s.getPrefixOrSiteName(ctx)
-	dbOpts := s.parseConnectionOptions()
+	dbOpts := s.parseConnectionOptions(ctx)
 
-	client, err := s.clientFactory(engine, dbOpts)
+	client, err := sharedClient(ctx, s.clientFactory, engine, dbOpts)
 	if err != nil {
 		return fmt.Errorf("creating database client: %w", err)
 	}
-	defer func() { _ = client.Close() }()
 
-	if err := client.Ping(); err != nil {
-		if opts.Verbose {
-			fmt.Printf("  Could not connect to %s database: %v\n", engine, err)
-		}
-		return nil
+	if connected, err := s.connect(ctx, client, engine, dbOpts, opts); !connected {
+		return err
 	}
 
+	createOpts := s.createOptionsFor(ctx, engine)
+
 	var lastErr error
 	for attempt := 0; attempt < maxDbCreateRetries; attempt++ {
 		var dbName string
@@ -222,10 +288,15 @@ func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string
 		existingSuffix := ctx.GetDbSuffix()
 		if existingSuffix != "" {
 			suffix = existingSuffix
-			dbName = fmt.Sprintf("%s_%s", words.SanitizeSiteName(siteName), suffix)
+			dbName = words.DatabaseName(siteName, suffix, 0)
 		} else {
-			dbName = words.GenerateDatabaseName(siteName, 0)
-			suffix = words.ExtractSuffix(dbName)
+			// A new suffix follows the project's naming config, as the
+			// first one did.
+			suffix, err = words.GenerateSuffixFor(ctx.Naming, ctx.Branch)
+			if err != nil {
+				return fmt.Errorf("generating db_suffix: %w", err)
+			}
+			dbName = words.DatabaseName(siteName, suffix, 0)
 			ctx.SetDbSuffix(suffix)
 		}
 
@@ -233,7 +304,7 @@ func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string
 			fmt.Printf("  Generated database name: %s (attempt %d/%d)\n", dbName, attempt+1, maxDbCreateRetries)
 		}
 
-		err := client.CreateDatabase(dbName)
+		err := client.CreateDatabase(dbName, createOpts)
 		if err == nil {
 			if opts.Verbose {
 				fmt.Printf("  Database '%s' created successfully.\n", dbName)
@@ -243,6 +314,8 @@ func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string
 					fmt.Printf("  warning: failed to persist db_suffix: %v\n", err)
 				}
 			}
+			ctx.SetVar("DbName", dbName)
+			s.recordDbPrefix(ctx, siteName, opts)
 			return nil
 		}
 
@@ -250,6 +323,20 @@ func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string
 			return fmt.Errorf("failed to create database: %w", err)
 		}
 
+		// Branch-named databases are predictable, so an existing one belongs
+		// to this branch, e.g. after .arbor.local was lost.
+		if ctx.DbNaming == words.DbNamingBranch && existingSuffix != "" {
+			if opts.Verbose {
+				fmt.Printf("  Database '%s' already exists, reusing it.\n", dbName)
+			}
+			if err := s.persistDbSuffix(ctx); err != nil && opts.Verbose {
+				fmt.Printf("  warning: failed to persist db_suffix: %v\n", err)
+			}
+			ctx.SetVar("DbName", dbName)
+			s.recordDbPrefix(ctx, siteName, opts)
+			return nil
+		}
+
 		if opts.Verbose {
 			fmt.Printf("  Database '%s' already exists, retrying...\n", dbName)
 		}
@@ -260,6 +347,66 @@ func (s *DbCreateStep) createWithRetry(ctx *types.ScaffoldContext, engine string
 	return fmt.Errorf("failed to create database after %d attempts: %w", maxDbCreateRetries, lastErr)
 }
 
+func newCreateOptions(cfg config.StepConfig) CreateOptions {
+	return CreateOptions{
+		Charset:   cfg.Charset,
+		Collation: cfg.Collation,
+		Owner:     cfg.Owner,
+		Template:  cfg.Template,
+	}
+}
+
+// createOptionsFor returns the step's create options that apply to engine.
+// Options for the other engine, possible when the engine is detected from
+// .env, are dropped with a warning.
+func (s *DbCreateStep) createOptionsFor(ctx *types.ScaffoldContext, engine string) CreateOptions {
+	createOpts := s.createOpts
+	switch {
+	case engine == "mysql" && (createOpts.Owner != "" || createOpts.Template != ""):
+		ctx.AddWarning("db.create: owner and template apply to pgsql databases and were ignored for mysql")
+		createOpts.Owner, createOpts.Template = "", ""
+	case engine == "pgsql" && (createOpts.Charset != "" || createOpts.Collation != ""):
+		ctx.AddWarning("db.create: charset and collation apply to mysql databases and were ignored for pgsql")
+		createOpts.Charset, createOpts.Collation = "", ""
+	}
+	return createOpts
+}
+
+// connect pings the database server, applying the step's
+// on_connection_failure behaviour when it cannot be reached: skip records a
+// warning for the scaffold summary, fail returns an error, and retry pings
+// again with exponential backoff before failing. It reports whether the
+// server is reachable.
+func (s *DbCreateStep) connect(ctx *types.ScaffoldContext, client DatabaseClient, engine string, dbOpts DatabaseOptions, opts types.StepOptions) (bool, error) {
+	err := client.Ping()
+	if err != nil && s.onConnectionFailure == config.OnConnectionFailureRetry {
+		delay := dbConnectBackoff
+		for attempt := 1; attempt < maxDbConnectAttempts && err != nil; attempt++ {
+			if opts.Verbose {
+				fmt.Printf("  Could not connect to %s database (attempt %d/%d), retrying in %s...\n", engine, attempt, maxDbConnectAttempts, delay)
+			}
+			time.Sleep(delay)
+			delay *= 2
+			err = client.Ping()
+		}
+	}
+	if err == nil {
+		return true, nil
+	}
+
+	address := dbOpts.Address()
+	switch s.onConnectionFailure {
+	case config.OnConnectionFailureFail, config.OnConnectionFailureRetry:
+		return false, fmt.Errorf("connecting to %s database at %s: %w", engine, address, err)
+	}
+
+	if opts.Verbose {
+		fmt.Printf("  Could not connect to %s database: %v\n", engine, err)
+	}
+	ctx.AddWarning(fmt.Sprintf("db.create skipped: could not connect to %s database at %s (%v)", engine, address, err))
+	return false, nil
+}
+
 func (s *DbCreateStep) persistDbSuffix(ctx *types.ScaffoldContext) error {
 	suffix := ctx.GetDbSuffix()
 	if suffix == "" {
@@ -270,11 +417,22 @@ func (s *DbCreateStep) persistDbSuffix(ctx *types.ScaffoldContext) error {
 	return config.WriteLocalState(ctx.WorktreePath, config.LocalState{DbSuffix: suffix})
 }
 
+// recordDbPrefix records the prefix of a database the step created, so
+// db.destroy knows the worktree's database names.
+func (s *DbCreateStep) recordDbPrefix(ctx *types.ScaffoldContext, prefix string, opts types.StepOptions) {
+	if err := config.AddDbPrefix(ctx.WorktreePath, words.SanitizeSiteName(prefix)); err != nil && opts.Verbose {
+		fmt.Printf("  warning: failed to record the database prefix: %v\n", err)
+	}
+}
+
 // handleDatabaseSelection prompts the user to choose between creating a new database
-// or reusing an existing one from another worktree.
+// or reusing an existing one from another worktree. A recorded answer is
+// replayed without prompting, and the choice made is recorded.
 func (s *DbCreateStep) handleDatabaseSelection(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	answer, replay := ctx.Answers.Get(prompts.AnswerDatabase)
+
 	// Only prompt if prompts are allowed and we haven't already done selection
-	if !opts.PromptMode.Allow() || ctx.GetVar("db_selection_done") == "true" {
+	if (!replay && !opts.PromptMode.Allow()) || ctx.GetVar("db_selection_done") == "true" {
 		return nil
 	}
 
@@ -287,7 +445,7 @@ func (s *DbCreateStep) handleDatabaseSelection(ctx *types.ScaffoldContext, opts
 	}
 
 	// Discover databases from other worktrees
-	databases, err := discoverWorktreeDatabases(ctx.BarePath, ctx.WorktreePath)
+	databases, err := DiscoverWorktreeDatabases(ctx.Configs, ctx.BarePath, ctx.WorktreePath)
 	if err != nil {
 		// Log error but don't fail - just skip discovery
 		if opts.Verbose {
@@ -313,22 +471,38 @@ func (s *DbCreateStep) handleDatabaseSelection(ctx *types.ScaffoldContext, opts
 		options = append(options, prompts.DatabaseOption{
 			Label:    fmt.Sprintf("Use database from '%s' (%s)", db.Branch, db.DbSuffix),
 			DbSuffix: db.DbSuffix,
+			Branch:   db.Branch,
 		})
 	}
 
-	// Prompt user to select
-	selectedSuffix, err := s.prompter.SelectDatabase(options)
-	if err != nil {
-		return fmt.Errorf("database selection prompt: %w", err)
+	var selected prompts.DatabaseOption
+	if replay {
+		selected = replayDatabaseAnswer(answer, options, opts)
+	} else {
+		// Prompt user to select
+		selectedSuffix, err := s.prompter.SelectDatabase(options)
+		if err != nil {
+			return fmt.Errorf("database selection prompt: %w", err)
+		}
+		for _, option := range options {
+			if option.DbSuffix == selectedSuffix {
+				selected = option
+			}
+		}
+		if selected.DbSuffix == "" {
+			ctx.Answers.Set(prompts.AnswerDatabase, prompts.AnswerNewDatabase)
+		} else {
+			ctx.Answers.Set(prompts.AnswerDatabase, "reuse:"+selected.Branch)
+		}
 	}
 
 	// If user chose to create new database, continue with normal flow
-	if selectedSuffix == "" {
+	if selected.DbSuffix == "" {
 		return nil
 	}
 
 	// User chose to reuse existing database
-	ctx.SetDbSuffix(selectedSuffix)
+	ctx.SetDbSuffix(selected.DbSuffix)
 	ctx.SetVar("use_existing_db", "true")
 
 	// Persist the suffix to .arbor.local
@@ -337,12 +511,47 @@ func (s *DbCreateStep) handleDatabaseSelection(ctx *types.ScaffoldContext, opts
 			fmt.Printf("  warning: failed to persist db_suffix: %v\n", err)
 		}
 	}
+	if err := config.SetDbShared(ctx.WorktreePath, true); err != nil && opts.Verbose {
+		fmt.Printf("  warning: failed to record the shared database: %v\n", err)
+	}
 
 	return nil
 }
 
-// handleMigrationPrompt asks the user if they want to run migrations.
+// replayDatabaseAnswer returns the option a recorded AnswerDatabase
+// chooses. Reusing the database of a branch without a worktree here
+// falls back to creating a new database.
+func replayDatabaseAnswer(answer string, options []prompts.DatabaseOption, opts types.StepOptions) prompts.DatabaseOption {
+	branch, reuse := strings.CutPrefix(answer, "reuse:")
+	if !reuse {
+		return options[0]
+	}
+	for _, option := range options[1:] {
+		if option.Branch == branch {
+			return option
+		}
+	}
+	if !opts.Quiet {
+		fmt.Printf("  No database from '%s' to reuse, creating a new one\n", branch)
+	}
+	return options[0]
+}
+
+// handleMigrationPrompt asks the user if they want to run migrations,
+// unless the answer is recorded, and records the answer given.
 func (s *DbCreateStep) handleMigrationPrompt(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	// A database no migrate step runs against, such as a test database,
+	// has nothing to ask about.
+	if slices.Contains(s.args, "--skip-migration-prompt") {
+		return nil
+	}
+	if answer, ok := ctx.Answers.Get(prompts.AnswerMigrate); ok {
+		if !prompts.IsYes(answer) {
+			ctx.SetVar("skip_migrations", "true")
+		}
+		return nil
+	}
+
 	// Only prompt if prompts are allowed
 	if !opts.PromptMode.Allow() {
 		return nil
@@ -353,13 +562,14 @@ func (s *DbCreateStep) handleMigrationPrompt(ctx *types.ScaffoldContext, opts ty
 	databaseName := ""
 	if suffix := ctx.GetDbSuffix(); suffix != "" {
 		siteName := s.getPrefixOrSiteName(ctx)
-		databaseName = fmt.Sprintf("%s_%s", words.SanitizeSiteName(siteName), suffix)
+		databaseName = words.DatabaseName(siteName, suffix, 0)
 	}
 
 	confirmed, err := s.prompter.ConfirmMigrations(databaseName)
 	if err != nil {
 		return fmt.Errorf("migration confirmation prompt: %w", err)
 	}
+	ctx.Answers.Set(prompts.AnswerMigrate, prompts.YesNo(confirmed))
 
 	// If user declined, set context variable to skip migrations
 	if !confirmed {
@@ -404,15 +614,24 @@ type DbDestroyStep struct {
 	name          string
 	args          []string
 	dbType        string
+	tls           dbTLS
+	confirmAbove  int
 	clientFactory DatabaseClientFactory
 	prompter      prompts.DbPrompter
 }
 
+var (
+	_ types.ScaffoldStep   = (*DbDestroyStep)(nil)
+	_ types.CleanupPlanner = (*DbDestroyStep)(nil)
+)
+
 func NewDbDestroyStep(cfg config.StepConfig) *DbDestroyStep {
 	return &DbDestroyStep{
 		name:          "db.destroy",
 		args:          cfg.Args,
 		dbType:        cfg.Type,
+		tls:           newDbTLS(cfg),
+		confirmAbove:  cfg.ConfirmAbove,
 		clientFactory: DefaultDatabaseClientFactory,
 		prompter:      ui.UIDbPrompter{},
 	}
@@ -423,6 +642,8 @@ func NewDbDestroyStepWithFactory(cfg config.StepConfig, factory DatabaseClientFa
 		name:          "db.destroy",
 		args:          cfg.Args,
 		dbType:        cfg.Type,
+		tls:           newDbTLS(cfg),
+		confirmAbove:  cfg.ConfirmAbove,
 		clientFactory: factory,
 		prompter:      ui.UIDbPrompter{},
 	}
@@ -433,6 +654,8 @@ func NewDbDestroyStepWithPrompter(cfg config.StepConfig, factory DatabaseClientF
 		name:          "db.destroy",
 		args:          cfg.Args,
 		dbType:        cfg.Type,
+		tls:           newDbTLS(cfg),
+		confirmAbove:  cfg.ConfirmAbove,
 		clientFactory: factory,
 		prompter:      prompter,
 	}
@@ -447,15 +670,7 @@ func (s *DbDestroyStep) Condition(ctx *types.ScaffoldContext) bool {
 }
 
 func (s *DbDestroyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
-	suffix := ctx.GetDbSuffix()
-	if suffix == "" {
-		localState, err := config.ReadLocalState(ctx.WorktreePath)
-		if err != nil {
-			return nil
-		}
-		suffix = localState.DbSuffix
-	}
-
+	suffix := s.resolveSuffix(ctx)
 	if suffix == "" {
 		if opts.Verbose {
 			fmt.Printf("  No database suffix found, skipping cleanup.\n")
@@ -481,7 +696,92 @@ func (s *DbDestroyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions)
 		return nil
 	}
 
-	return s.destroyDatabases(engine, suffix, opts)
+	return s.destroyDatabases(ctx, engine, suffix, opts)
+}
+
+// PlanCleanup lists the databases Run would drop without dropping them.
+func (s *DbDestroyStep) PlanCleanup(ctx *types.ScaffoldContext, opts types.StepOptions) ([]types.Resource, error) {
+	suffix := s.resolveSuffix(ctx)
+	if suffix == "" {
+		return nil, nil
+	}
+
+	engine, err := s.detectEngine(ctx)
+	if err != nil || engine == "sqlite" {
+		return nil, nil
+	}
+
+	client, err := sharedClient(ctx, s.clientFactory, engine, s.parseConnectionOptions(ctx, engine))
+	if err != nil {
+		return nil, fmt.Errorf("creating database client: %w", err)
+	}
+
+	if err := client.Ping(); err != nil {
+		return nil, fmt.Errorf("connecting to %s database: %w", engine, err)
+	}
+
+	databases, _, err := s.matchingDatabases(ctx, client, suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]types.Resource, len(databases))
+	for i, dbName := range databases {
+		resources[i] = types.Resource{Kind: types.ResourceDatabase, Name: dbName}
+	}
+	return resources, nil
+}
+
+// matchingDatabases returns the databases named for the worktree's suffix,
+// and the others whose names end in it: a database is the worktree's when
+// its name is one db.create gives it, with a prefix recorded in
+// .arbor.local, the step's --prefix, or db.create's default prefix.
+func (s *DbDestroyStep) matchingDatabases(ctx *types.ScaffoldContext, client DatabaseClient, suffix string) (owned, others []string, err error) {
+	databases, err := client.ListDatabases(fmt.Sprintf("%%_%s", suffix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing databases: %w", err)
+	}
+
+	prefixes := []string{databasePrefix(s.args, ctx)}
+	if localState, err := ctx.Configs.LocalState(ctx.WorktreePath); err == nil {
+		prefixes = append(prefixes, localState.DbPrefixes...)
+	}
+	known := make(map[string]bool, len(prefixes))
+	for _, prefix := range prefixes {
+		known[words.DatabaseName(prefix, suffix, 0)] = true
+	}
+
+	for _, name := range databases {
+		if known[name] {
+			owned = append(owned, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(owned)
+	sort.Strings(others)
+	return owned, others, nil
+}
+
+// confirmLimit is how many databases the step drops without asking.
+func (s *DbDestroyStep) confirmLimit() int {
+	if s.confirmAbove > 0 {
+		return s.confirmAbove
+	}
+	return config.DefaultDbDestroyConfirmAbove
+}
+
+// resolveSuffix returns the worktree's database suffix from the context,
+// falling back to .arbor.local.
+func (s *DbDestroyStep) resolveSuffix(ctx *types.ScaffoldContext) string {
+	if suffix := ctx.GetDbSuffix(); suffix != "" {
+		return suffix
+	}
+	localState, err := ctx.Configs.LocalState(ctx.WorktreePath)
+	if err != nil {
+		return ""
+	}
+	return localState.DbSuffix
 }
 
 func (s *DbDestroyStep) detectEngine(ctx *types.ScaffoldContext) (string, error) {
@@ -494,7 +794,7 @@ func (s *DbDestroyStep) detectEngine(ctx *types.ScaffoldContext) (string, error)
 		}
 	}
 
-	env := utils.ReadEnvFile(ctx.WorktreePath, ".env")
+	env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
 	if conn := env["DB_CONNECTION"]; conn != "" {
 		switch conn {
 		case "mysql", "mariadb":
@@ -506,13 +806,15 @@ func (s *DbDestroyStep) detectEngine(ctx *types.ScaffoldContext) (string, error)
 		}
 	}
 
-	return "", fmt.Errorf("database type not specified and DB_CONNECTION not found in .env")
+	return "", fmt.Errorf("database type not specified and DB_CONNECTION not found in %s", ctx.PrimaryEnvFile())
 }
 
-func (s *DbDestroyStep) parseConnectionOptions(engine string) DatabaseOptions {
+func (s *DbDestroyStep) parseConnectionOptions(ctx *types.ScaffoldContext, engine string) DatabaseOptions {
 	opts := DatabaseOptions{
-		Host: "127.0.0.1",
+		Host:   "127.0.0.1",
+		Socket: connectionSocket(s.args, ctx),
 	}
+	s.tls.apply(&opts, ctx)
 
 	if engine == "pgsql" {
 		opts.Username = "postgres"
@@ -540,17 +842,16 @@ func (s *DbDestroyStep) parseConnectionOptions(engine string) DatabaseOptions {
 	return opts
 }
 
-func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepOptions) error {
-	dbOpts := s.parseConnectionOptions(engine)
+func (s *DbDestroyStep) destroyDatabases(ctx *types.ScaffoldContext, engine, suffix string, opts types.StepOptions) error {
+	dbOpts := s.parseConnectionOptions(ctx, engine)
 
-	client, err := s.clientFactory(engine, dbOpts)
+	client, err := sharedClient(ctx, s.clientFactory, engine, dbOpts)
 	if err != nil {
 		if opts.Verbose {
 			fmt.Printf("  Could not create database client: %v\n", err)
 		}
 		return nil
 	}
-	defer func() { _ = client.Close() }()
 
 	if err := client.Ping(); err != nil {
 		if opts.Verbose {
@@ -559,8 +860,7 @@ func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepO
 		return nil
 	}
 
-	pattern := fmt.Sprintf("%%_%s", suffix)
-	databases, err := client.ListDatabases(pattern)
+	databases, others, err := s.matchingDatabases(ctx, client, suffix)
 	if err != nil {
 		if opts.Verbose {
 			fmt.Printf("  Failed to list databases: %v\n", err)
@@ -568,14 +868,21 @@ func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepO
 		return nil
 	}
 
+	if len(others) > 0 && !opts.Quiet {
+		fmt.Printf("  Leaving %s: not named by db.create for this worktree\n", strings.Join(others, ", "))
+	}
 	if len(databases) == 0 {
 		if opts.Verbose {
 			fmt.Printf("  No databases matching pattern found.\n")
 		}
 		return nil
 	}
+	if !opts.Quiet {
+		fmt.Printf("  Databases to drop: %s\n", strings.Join(databases, ", "))
+	}
 
-	// Prompt for confirmation in interactive mode
+	// Prompt for confirmation in interactive mode. Without prompts, more
+	// databases than the limit are only dropped with --force.
 	if opts.PromptMode.Allow() {
 		confirmed, err := s.prompter.ConfirmDatabaseDrop(suffix, databases)
 		if err != nil {
@@ -587,6 +894,9 @@ func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepO
 			}
 			return nil
 		}
+	} else if len(databases) > s.confirmLimit() && !opts.PromptMode.Force && !opts.DryRun {
+		ctx.AddWarning(fmt.Sprintf("db.destroy did not drop %d databases (%s): more than %d need confirmation, run again with --force to drop them", len(databases), strings.Join(databases, ", "), s.confirmLimit()))
+		return nil
 	}
 
 	for _, dbName := range databases {
@@ -603,6 +913,7 @@ func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepO
 			}
 			continue
 		}
+		ctx.RecordRemoved(types.ResourceDatabase, dbName)
 
 		if opts.Verbose {
 			fmt.Printf("  Dropped database: %s\n", dbName)
@@ -612,9 +923,9 @@ func (s *DbDestroyStep) destroyDatabases(engine, suffix string, opts types.StepO
 	return nil
 }
 
-// discoverWorktreeDatabases finds other worktrees that have a DbSuffix configured.
+// DiscoverWorktreeDatabases finds other worktrees that have a DbSuffix configured.
 // Excludes the current worktree from results and sorts by branch name for deterministic ordering.
-func discoverWorktreeDatabases(barePath, currentWorktreePath string) ([]WorktreeDatabase, error) {
+func DiscoverWorktreeDatabases(configs *config.Store, barePath, currentWorktreePath string) ([]WorktreeDatabase, error) {
 	if barePath == "" {
 		return nil, nil
 	}
@@ -643,7 +954,7 @@ func discoverWorktreeDatabases(barePath, currentWorktreePath string) ([]Worktree
 		}
 
 		// Try to read local state
-		localState, err := config.ReadLocalState(wt.Path)
+		localState, err := configs.LocalState(wt.Path)
 		if err != nil {
 			// Skip worktrees that don't have .arbor.local or can't be read
 			continue
@@ -655,6 +966,7 @@ func discoverWorktreeDatabases(barePath, currentWorktreePath string) ([]Worktree
 				WorktreePath: wt.Path,
 				Branch:       wt.Branch,
 				DbSuffix:     localState.DbSuffix,
+				Shared:       localState.DbShared,
 			})
 		}
 	}
@@ -666,3 +978,30 @@ func discoverWorktreeDatabases(barePath, currentWorktreePath string) ([]Worktree
 
 	return results, nil
 }
+
+// FindDbSuffixCollisions returns the database suffixes that more than one
+// worktree uses without having chosen to share it, sorted by suffix.
+func FindDbSuffixCollisions(configs *config.Store, barePath string) ([]DbSuffixCollision, error) {
+	databases, err := DiscoverWorktreeDatabases(configs, barePath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	bySuffix := make(map[string][]WorktreeDatabase)
+	for _, db := range databases {
+		if !db.Shared {
+			bySuffix[db.DbSuffix] = append(bySuffix[db.DbSuffix], db)
+		}
+	}
+
+	var collisions []DbSuffixCollision
+	for suffix, worktrees := range bySuffix {
+		if len(worktrees) > 1 {
+			collisions = append(collisions, DbSuffixCollision{DbSuffix: suffix, Worktrees: worktrees})
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].DbSuffix < collisions[j].DbSuffix
+	})
+	return collisions, nil
+}
@@ -1,12 +1,20 @@
 package steps
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -201,6 +209,7 @@ func TestDbCreateStep(t *testing.T) {
 		localState, err := config.ReadLocalState(tmpDir)
 		require.NoError(t, err)
 		assert.Equal(t, suffix, localState.DbSuffix, "DbSuffix should be persisted to .arbor.local")
+		assert.Equal(t, []string{"testapp"}, localState.DbPrefixes, "the prefix should be recorded for db.destroy")
 	})
 
 	t.Run("reads APP_NAME from .env if SiteName is empty", func(t *testing.T) {
@@ -399,6 +408,7 @@ func TestDbCreateStep(t *testing.T) {
 		createCalls := mockClient.GetCreateCalls()
 		assert.Len(t, createCalls, 1)
 		assert.Equal(t, "app_shared_suffix", createCalls[0], "Should use prefix with shared suffix")
+		assert.Equal(t, "app_shared_suffix", ctx.GetVar("DbName"), "DbName should name the created database")
 	})
 
 	t.Run("retries on database exists error", func(t *testing.T) {
@@ -426,6 +436,58 @@ func TestDbCreateStep(t *testing.T) {
 		assert.Equal(t, 1, mockClient.DatabaseCount(), "Should have created one database")
 	})
 
+	t.Run("retries with suffixes following the naming config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetExistsOnFirstNCalls(1)
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{
+			WorktreePath: tmpDir,
+			SiteName:     "testapp",
+			DbSuffix:     "1234",
+			Naming:       config.NamingConfig{Style: "numeric", SuffixLength: 6},
+		}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		createCalls := mockClient.GetCreateCalls()
+		require.Len(t, createCalls, 2)
+		assert.Equal(t, "testapp_1234", createCalls[0])
+		assert.Regexp(t, `^testapp_[0-9]{6}$`, createCalls[1], "the new suffix is numeric, not adjective_noun")
+		assert.Equal(t, strings.TrimPrefix(createCalls[1], "testapp_"), ctx.GetDbSuffix())
+	})
+
+	t.Run("reuses an existing branch-named database", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		envFile := filepath.Join(tmpDir, ".env")
+		if err := os.WriteFile(envFile, []byte("DB_CONNECTION=mysql\n"), 0644); err != nil {
+			t.Fatalf("writing env file: %v", err)
+		}
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetExistsOnFirstNCalls(1)
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{
+			WorktreePath: tmpDir,
+			SiteName:     "testapp",
+			DbSuffix:     "feature_auth",
+			DbNaming:     "branch",
+		}
+
+		err := step.Run(ctx, types.StepOptions{Verbose: false})
+		assert.NoError(t, err)
+		assert.Equal(t, "feature_auth", ctx.GetDbSuffix())
+		assert.Equal(t, []string{"testapp_feature_auth"}, mockClient.GetCreateCalls(), "Should not retry with a new name")
+
+		state, err := config.ReadLocalState(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, "feature_auth", state.DbSuffix)
+	})
+
 	t.Run("fails after max retries", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -468,6 +530,60 @@ func TestDbCreateStep(t *testing.T) {
 		err := step.Run(ctx, types.StepOptions{Verbose: false})
 		assert.NoError(t, err, "Should not error when ping fails, just skip")
 		assert.Empty(t, ctx.GetDbSuffix(), "DbSuffix should not be set when skipped")
+		assert.Equal(t, []string{"db.create skipped: could not connect to mysql database at 127.0.0.1 (connection refused)"}, ctx.Warnings())
+	})
+
+	t.Run("fails when ping fails with on_connection_failure: fail", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetPingError(errors.New("connection refused"))
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{OnConnectionFailure: "fail", Args: []string{"--port", "3307"}}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		err := step.Run(ctx, types.StepOptions{})
+		assert.EqualError(t, err, "connecting to mysql database at 127.0.0.1:3307: connection refused")
+		assert.Equal(t, 1, mockClient.PingCount())
+		assert.Empty(t, mockClient.GetCreateCalls())
+	})
+
+	t.Run("retries the connection with on_connection_failure: retry", func(t *testing.T) {
+		defer func(backoff time.Duration) { dbConnectBackoff = backoff }(dbConnectBackoff)
+		dbConnectBackoff = time.Millisecond
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetPingErrorOnFirstNCalls(2, errors.New("connection refused"))
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{OnConnectionFailure: "retry"}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.Equal(t, 3, mockClient.PingCount())
+		assert.Len(t, mockClient.GetCreateCalls(), 1)
+		assert.Empty(t, ctx.Warnings())
+	})
+
+	t.Run("fails after exhausting connection retries", func(t *testing.T) {
+		defer func(backoff time.Duration) { dbConnectBackoff = backoff }(dbConnectBackoff)
+		dbConnectBackoff = time.Millisecond
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetPingError(errors.New("connection refused"))
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{OnConnectionFailure: "retry"}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		err := step.Run(ctx, types.StepOptions{})
+		assert.ErrorContains(t, err, "connecting to mysql database")
+		assert.Equal(t, maxDbConnectAttempts, mockClient.PingCount())
 	})
 }
 
@@ -512,6 +628,31 @@ func TestHandleMigrationPrompt(t *testing.T) {
 			"Should use the custom prefix, not the site name")
 	})
 
+	t.Run("renders template variables in the prefix", func(t *testing.T) {
+		mockPrompter := &mockDbPrompter{confirmResult: true}
+		step := NewDbCreateStepWithPrompter(config.StepConfig{
+			Args: []string{"--prefix", "{{ .SanitizedSiteName }}_test"},
+		}, MockClientFactory(NewMockDatabaseClient()), mockPrompter)
+		ctx := &types.ScaffoldContext{WorktreePath: t.TempDir(), SiteName: "my-app"}
+		ctx.SetDbSuffix("swift_runner")
+
+		require.NoError(t, step.handleMigrationPrompt(ctx, types.StepOptions{PromptMode: types.PromptMode{Interactive: true}}))
+		assert.Equal(t, "my_app_test_swift_runner", mockPrompter.confirmMigrationsCall)
+	})
+
+	t.Run("does not prompt with --skip-migration-prompt", func(t *testing.T) {
+		mockPrompter := &mockDbPrompter{confirmResult: false}
+		step := NewDbCreateStepWithPrompter(config.StepConfig{
+			Args: []string{"--prefix", "app_test", "--skip-migration-prompt"},
+		}, MockClientFactory(NewMockDatabaseClient()), mockPrompter)
+		ctx := &types.ScaffoldContext{WorktreePath: t.TempDir(), SiteName: "myapp"}
+		ctx.SetDbSuffix("swift_runner")
+
+		require.NoError(t, step.handleMigrationPrompt(ctx, types.StepOptions{PromptMode: types.PromptMode{Interactive: true}}))
+		assert.Equal(t, "", mockPrompter.confirmMigrationsCall)
+		assert.Equal(t, "", ctx.GetVar("skip_migrations"))
+	})
+
 	t.Run("passes empty database name to prompter when no suffix is set", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -569,6 +710,100 @@ func TestHandleMigrationPrompt(t *testing.T) {
 		assert.Equal(t, "", mockPrompter.confirmMigrationsCall,
 			"Should not call prompter when prompts are not allowed")
 	})
+
+	t.Run("records the answer given", func(t *testing.T) {
+		mockPrompter := &mockDbPrompter{confirmResult: false}
+		step := NewDbCreateStepWithPrompter(config.StepConfig{}, MockClientFactory(NewMockDatabaseClient()), mockPrompter)
+		ctx := &types.ScaffoldContext{WorktreePath: t.TempDir(), SiteName: "myapp", Answers: prompts.NewAnswers()}
+
+		require.NoError(t, step.handleMigrationPrompt(ctx, types.StepOptions{PromptMode: types.PromptMode{Interactive: true}}))
+
+		path := filepath.Join(t.TempDir(), "answers.yaml")
+		require.NoError(t, ctx.Answers.Save(path))
+		replayed, err := prompts.LoadAnswers(path)
+		require.NoError(t, err)
+		answer, _ := replayed.Get(prompts.AnswerMigrate)
+		assert.Equal(t, "no", answer)
+	})
+
+	t.Run("replays a recorded answer without prompting", func(t *testing.T) {
+		mockPrompter := &mockDbPrompter{confirmResult: true}
+		step := NewDbCreateStepWithPrompter(config.StepConfig{}, MockClientFactory(NewMockDatabaseClient()), mockPrompter)
+		ctx := &types.ScaffoldContext{
+			WorktreePath: t.TempDir(),
+			SiteName:     "myapp",
+			Answers:      loadTestAnswers(t, "answers:\n  db.migrate: no\n"),
+		}
+		ctx.SetDbSuffix("swift_runner")
+
+		// Replayed even though prompts are not allowed, e.g. in CI
+		require.NoError(t, step.handleMigrationPrompt(ctx, types.StepOptions{}))
+		assert.Equal(t, "", mockPrompter.confirmMigrationsCall)
+		assert.Equal(t, "true", ctx.GetVar("skip_migrations"))
+	})
+}
+
+func TestReplayDatabaseAnswer(t *testing.T) {
+	options := []prompts.DatabaseOption{
+		{Label: "Create new database"},
+		{Label: "Use database from 'main' (swift_runner)", DbSuffix: "swift_runner", Branch: "main"},
+	}
+
+	assert.Equal(t, options[0], replayDatabaseAnswer("new", options, types.StepOptions{}))
+	assert.Equal(t, options[1], replayDatabaseAnswer("reuse:main", options, types.StepOptions{}))
+	assert.Equal(t, options[0], replayDatabaseAnswer("reuse:develop", options, types.StepOptions{Quiet: true}),
+		"a branch without a worktree here falls back to a new database")
+}
+
+func TestDbCreateStep_ValidatePlan(t *testing.T) {
+	writeEnv := func(t *testing.T, content string) string {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte(content), 0644))
+		return tmpDir
+	}
+
+	t.Run("sets DbName from the prefix without creating anything", func(t *testing.T) {
+		mockClient := NewMockDatabaseClient()
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=pgsql\n"), SiteName: "shop"}
+		ctx.SetDbSuffix("swift_runner")
+		step := NewDbCreateStepWithFactory(config.StepConfig{Args: []string{"--prefix", "{{ .SiteName }}_billing"}}, MockClientFactory(mockClient))
+
+		require.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+		assert.Equal(t, "shop_billing_swift_runner", ctx.GetVar("DbName"))
+		assert.Empty(t, mockClient.GetCreateCalls())
+	})
+
+	t.Run("lets env.write check the name db.create would create", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=mysql\n"), SiteName: "shop"}
+		ctx.SetDbSuffix("swift_runner")
+		create := NewDbCreateStepWithFactory(config.StepConfig{Args: []string{"--prefix", strings.Repeat("a", 70)}}, MockClientFactory(NewMockDatabaseClient()))
+		write := NewEnvWriteStep(config.StepConfig{Key: "DB_DATABASE", Value: "{{ .DbName }}"})
+
+		require.NoError(t, create.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+		assert.Len(t, ctx.GetVar("DbName"), 63, "long prefixes are shortened to fit")
+		assert.NoError(t, write.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+	})
+
+	t.Run("leaves DbName unset for sqlite and without a suffix", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=sqlite\n")}
+		ctx.SetDbSuffix("swift_runner")
+		step := NewDbCreateStep(config.StepConfig{})
+		require.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+		assert.Empty(t, ctx.GetVar("DbName"))
+
+		ctx = &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=mysql\n")}
+		require.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+		assert.Empty(t, ctx.GetVar("DbName"))
+	})
+}
+
+func loadTestAnswers(t *testing.T, content string) *prompts.Answers {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	answers, err := prompts.LoadAnswers(path)
+	require.NoError(t, err)
+	return answers
 }
 
 func TestDbDestroyStep(t *testing.T) {
@@ -632,17 +867,19 @@ func TestDbDestroyStep(t *testing.T) {
 		assert.Equal(t, "%_swift_runner", listCalls[0])
 	})
 
-	t.Run("drops databases matching suffix", func(t *testing.T) {
+	t.Run("drops databases with known prefixes matching suffix", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		envFile := filepath.Join(tmpDir, ".env")
 		if err := os.WriteFile(envFile, []byte("DB_CONNECTION=mysql\n"), 0644); err != nil {
 			t.Fatalf("writing env file: %v", err)
 		}
+		require.NoError(t, config.AddDbPrefix(tmpDir, "testing"))
 
 		mockClient := NewMockDatabaseClient()
-		mockClient.AddDatabase("app1_test_suffix")
-		mockClient.AddDatabase("app2_test_suffix")
+		mockClient.AddDatabase("app_test_suffix")
+		mockClient.AddDatabase("testing_test_suffix")
+		mockClient.AddDatabase("other_test_suffix")
 
 		step := NewDbDestroyStepWithFactory(config.StepConfig{}, MockClientFactory(mockClient))
 		ctx := &types.ScaffoldContext{
@@ -650,12 +887,45 @@ func TestDbDestroyStep(t *testing.T) {
 		}
 		ctx.SetDbSuffix("test_suffix")
 
-		err := step.Run(ctx, types.StepOptions{Verbose: false})
+		err := step.Run(ctx, types.StepOptions{Quiet: true})
 		assert.NoError(t, err)
 
 		dropCalls := mockClient.GetDropCalls()
 		assert.Len(t, dropCalls, 2, "Should have dropped 2 databases")
-		assert.Equal(t, 0, mockClient.DatabaseCount(), "All databases should be dropped")
+		assert.Equal(t, 1, mockClient.DatabaseCount(), "A database with an unknown prefix should be left")
+		assert.ElementsMatch(t, []types.Resource{
+			{Kind: types.ResourceDatabase, Name: "app_test_suffix"},
+			{Kind: types.ResourceDatabase, Name: "testing_test_suffix"},
+		}, ctx.Removed(), "Dropped databases should be recorded")
+	})
+
+	t.Run("needs force to drop more databases than confirm_above", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		envFile := filepath.Join(tmpDir, ".env")
+		if err := os.WriteFile(envFile, []byte("DB_CONNECTION=mysql\n"), 0644); err != nil {
+			t.Fatalf("writing env file: %v", err)
+		}
+		require.NoError(t, config.AddDbPrefix(tmpDir, "testing"))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.AddDatabase("app_test_suffix")
+		mockClient.AddDatabase("testing_test_suffix")
+
+		step := NewDbDestroyStepWithFactory(config.StepConfig{ConfirmAbove: 1}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{
+			WorktreePath: tmpDir,
+		}
+		ctx.SetDbSuffix("test_suffix")
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{Quiet: true, PromptMode: types.PromptMode{NoInteractive: true}}))
+		assert.Empty(t, mockClient.GetDropCalls())
+		if assert.Len(t, ctx.Warnings(), 1) {
+			assert.Contains(t, ctx.Warnings()[0], "--force")
+		}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{Quiet: true, PromptMode: types.PromptMode{Force: true}}))
+		assert.Len(t, mockClient.GetDropCalls(), 2)
 	})
 
 	t.Run("auto-detects mysql engine from DB_CONNECTION env", func(t *testing.T) {
@@ -884,7 +1154,7 @@ func createTestRepo(t *testing.T) string {
 func TestDiscoverWorktreeDatabases(t *testing.T) {
 	t.Run("returns nil when barePath is empty", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		results, err := discoverWorktreeDatabases("", tmpDir)
+		results, err := DiscoverWorktreeDatabases(nil, "", tmpDir)
 		assert.NoError(t, err)
 		assert.Nil(t, results)
 	})
@@ -896,7 +1166,7 @@ func TestDiscoverWorktreeDatabases(t *testing.T) {
 		require.NoError(t, git.CreateWorktree(barePath, mainPath, "main", ""))
 		require.NoError(t, config.WriteLocalState(mainPath, config.LocalState{DbSuffix: "main_suffix"}))
 
-		results, err := discoverWorktreeDatabases(barePath, mainPath)
+		results, err := DiscoverWorktreeDatabases(nil, barePath, mainPath)
 		assert.NoError(t, err)
 		assert.Empty(t, results)
 	})
@@ -912,7 +1182,7 @@ func TestDiscoverWorktreeDatabases(t *testing.T) {
 		require.NoError(t, config.WriteLocalState(mainPath, config.LocalState{DbSuffix: "main_suffix"}))
 		require.NoError(t, config.WriteLocalState(featurePath, config.LocalState{DbSuffix: "feature_suffix"}))
 
-		results, err := discoverWorktreeDatabases(barePath, mainPath)
+		results, err := DiscoverWorktreeDatabases(nil, barePath, mainPath)
 		assert.NoError(t, err)
 		if assert.Len(t, results, 1) {
 			assert.Equal(t, "feature", results[0].Branch)
@@ -932,7 +1202,7 @@ func TestDiscoverWorktreeDatabases(t *testing.T) {
 		require.NoError(t, git.CreateWorktree(barePath, featurePath, "feature", "main"))
 		require.NoError(t, config.WriteLocalState(featurePath, config.LocalState{DbSuffix: "feature_suffix"}))
 
-		results, err := discoverWorktreeDatabases(barePath, mainPath)
+		results, err := DiscoverWorktreeDatabases(nil, barePath, mainPath)
 		assert.NoError(t, err)
 		if assert.Len(t, results, 1) {
 			assert.Equal(t, "feature", results[0].Branch)
@@ -953,7 +1223,7 @@ func TestDiscoverWorktreeDatabases(t *testing.T) {
 		require.NoError(t, config.WriteLocalState(zuluPath, config.LocalState{DbSuffix: "zulu_suffix"}))
 		require.NoError(t, config.WriteLocalState(alphaPath, config.LocalState{DbSuffix: "alpha_suffix"}))
 
-		results, err := discoverWorktreeDatabases(barePath, mainPath)
+		results, err := DiscoverWorktreeDatabases(nil, barePath, mainPath)
 		assert.NoError(t, err)
 		if assert.Len(t, results, 2) {
 			assert.Equal(t, "alpha", results[0].Branch)
@@ -961,3 +1231,358 @@ func TestDiscoverWorktreeDatabases(t *testing.T) {
 		}
 	})
 }
+
+func TestFindDbSuffixCollisions(t *testing.T) {
+	barePath := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+	paths := map[string]string{}
+	for _, branch := range []string{"main", "alpha", "beta", "gamma"} {
+		paths[branch] = filepath.Join(projectDir, branch)
+		base := "main"
+		if branch == "main" {
+			base = ""
+		}
+		require.NoError(t, git.CreateWorktree(barePath, paths[branch], branch, base))
+	}
+	require.NoError(t, config.WriteLocalState(paths["main"], config.LocalState{DbSuffix: "swift_runner"}))
+	require.NoError(t, config.WriteLocalState(paths["alpha"], config.LocalState{DbSuffix: "swift_runner"}))
+	require.NoError(t, config.WriteLocalState(paths["beta"], config.LocalState{DbSuffix: "calm_river"}))
+	require.NoError(t, config.WriteLocalState(paths["gamma"], config.LocalState{DbSuffix: "calm_river"}))
+	require.NoError(t, config.SetDbShared(paths["gamma"], true))
+
+	collisions, err := FindDbSuffixCollisions(nil, barePath)
+	require.NoError(t, err)
+	require.Len(t, collisions, 1, "a worktree that chose to share a database is not a collision")
+	assert.Equal(t, "swift_runner", collisions[0].DbSuffix)
+	require.Len(t, collisions[0].Worktrees, 2)
+	assert.Equal(t, "alpha", collisions[0].Worktrees[0].Branch)
+	assert.Equal(t, "main", collisions[0].Worktrees[1].Branch)
+}
+
+func TestDbDestroyStep_PlanCleanup(t *testing.T) {
+	writeEnv := func(t *testing.T, dir, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(content), 0644); err != nil {
+			t.Fatalf("writing env file: %v", err)
+		}
+	}
+
+	t.Run("lists matching databases without dropping them", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeEnv(t, tmpDir, "DB_CONNECTION=mysql\n")
+		if err := config.WriteLocalState(tmpDir, config.LocalState{DbSuffix: "swift_runner"}); err != nil {
+			t.Fatalf("writing local state: %v", err)
+		}
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.AddDatabase("app_swift_runner")
+
+		step := NewDbDestroyStepWithFactory(config.StepConfig{}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+
+		resources, err := step.PlanCleanup(ctx, types.StepOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.Resource{{Kind: types.ResourceDatabase, Name: "app_swift_runner"}}, resources)
+		assert.Empty(t, mockClient.GetDropCalls())
+		assert.Empty(t, ctx.Removed())
+	})
+
+	t.Run("plans nothing without a suffix", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeEnv(t, tmpDir, "DB_CONNECTION=mysql\n")
+
+		step := NewDbDestroyStepWithFactory(config.StepConfig{}, MockClientFactory(NewMockDatabaseClient()))
+		resources, err := step.PlanCleanup(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+
+	t.Run("reports connection failures", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeEnv(t, tmpDir, "DB_CONNECTION=pgsql\n")
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetPingError(errors.New("connection refused"))
+
+		step := NewDbDestroyStepWithFactory(config.StepConfig{}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+		ctx.SetDbSuffix("test_suffix")
+
+		_, err := step.PlanCleanup(ctx, types.StepOptions{})
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
+
+func TestSharedClient(t *testing.T) {
+	t.Run("steps in a run share one client per connection", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		opened := 0
+		factory := func(engine string, opts DatabaseOptions) (DatabaseClient, error) {
+			opened++
+			return mockClient, nil
+		}
+
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+		ctx.SetDbSuffix("swift_runner")
+		for _, prefix := range []string{"app", "app_testing"} {
+			step := NewDbCreateStepWithFactory(config.StepConfig{Args: []string{"--prefix", prefix}}, factory)
+			require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		}
+
+		assert.Equal(t, 1, opened, "Should open one client for both steps")
+		assert.Equal(t, []string{"app_swift_runner", "app_testing_swift_runner"}, mockClient.GetCreateCalls())
+		assert.Equal(t, 0, mockClient.CloseCount(), "Steps should not close the shared client")
+
+		require.NoError(t, ctx.CloseClients())
+		assert.Equal(t, 1, mockClient.CloseCount())
+	})
+
+	t.Run("different connection options get separate clients", func(t *testing.T) {
+		opened := 0
+		factory := func(engine string, opts DatabaseOptions) (DatabaseClient, error) {
+			opened++
+			return NewMockDatabaseClient(), nil
+		}
+
+		ctx := &types.ScaffoldContext{}
+		_, err := sharedClient(ctx, factory, "mysql", DatabaseOptions{Host: "127.0.0.1"})
+		require.NoError(t, err)
+		_, err = sharedClient(ctx, factory, "mysql", DatabaseOptions{Host: "db.internal"})
+		require.NoError(t, err)
+		_, err = sharedClient(ctx, factory, "pgsql", DatabaseOptions{Host: "127.0.0.1"})
+		require.NoError(t, err)
+		_, err = sharedClient(ctx, factory, "mysql", DatabaseOptions{Host: "127.0.0.1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, opened)
+	})
+
+	t.Run("factory errors are not cached", func(t *testing.T) {
+		calls := 0
+		factory := func(engine string, opts DatabaseOptions) (DatabaseClient, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("driver unavailable")
+			}
+			return NewMockDatabaseClient(), nil
+		}
+
+		ctx := &types.ScaffoldContext{}
+		_, err := sharedClient(ctx, factory, "mysql", DatabaseOptions{})
+		assert.EqualError(t, err, "driver unavailable")
+		_, err = sharedClient(ctx, factory, "mysql", DatabaseOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestDatabaseSocketOptions(t *testing.T) {
+	capture := func(opts *DatabaseOptions) DatabaseClientFactory {
+		return func(engine string, o DatabaseOptions) (DatabaseClient, error) {
+			*opts = o
+			return NewMockDatabaseClient(), nil
+		}
+	}
+
+	t.Run("db.create uses --socket", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\nDB_SOCKET=/tmp/env.sock\n"), 0644))
+
+		var opts DatabaseOptions
+		step := NewDbCreateStepWithFactory(config.StepConfig{Args: []string{"--socket", "/tmp/mysql.sock"}}, capture(&opts))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.Equal(t, "/tmp/mysql.sock", opts.Socket, "--socket takes precedence over DB_SOCKET")
+	})
+
+	t.Run("db.destroy reads DB_SOCKET from .env", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=pgsql\nDB_SOCKET=/var/run/postgresql\n"), 0644))
+
+		var opts DatabaseOptions
+		step := NewDbDestroyStepWithFactory(config.StepConfig{}, capture(&opts))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+		ctx.SetDbSuffix("swift_runner")
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.Equal(t, "/var/run/postgresql", opts.Socket)
+	})
+
+	t.Run("connection failures name the socket", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		mockClient.SetPingError(errors.New("no such file or directory"))
+
+		step := NewDbCreateStepWithFactory(config.StepConfig{OnConnectionFailure: "fail", Args: []string{"--socket", "/tmp/mysql.sock"}}, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		err := step.Run(ctx, types.StepOptions{})
+		assert.EqualError(t, err, "connecting to mysql database at /tmp/mysql.sock: no such file or directory")
+	})
+}
+
+func TestPostgresSocketHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		socket   string
+		wantHost string
+		wantPort string
+	}{
+		{"socket directory", "/var/run/postgresql", "/var/run/postgresql", "5432"},
+		{"socket file", "/tmp/.s.PGSQL.5433", "/tmp", "5433"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := postgresSocketHost(tt.socket, "5432")
+			assert.Equal(t, tt.wantHost, host)
+			assert.Equal(t, tt.wantPort, port)
+		})
+	}
+}
+
+func TestDatabaseTLSOptions(t *testing.T) {
+	t.Run("step config takes precedence over .env", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=mysql\nDB_SSLMODE=prefer\nMYSQL_ATTR_SSL_CA=/etc/ssl/env-ca.pem\n"), 0644))
+
+		var opts DatabaseOptions
+		factory := func(engine string, o DatabaseOptions) (DatabaseClient, error) {
+			opts = o
+			return NewMockDatabaseClient(), nil
+		}
+		step := NewDbCreateStepWithFactory(config.StepConfig{SSLMode: "verify-ca", SSLSkipVerify: true}, factory)
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.Equal(t, "verify-ca", opts.SSLMode)
+		assert.Equal(t, "/etc/ssl/env-ca.pem", opts.SSLCA, "CA falls back to MYSQL_ATTR_SSL_CA")
+		assert.True(t, opts.SSLSkipVerify)
+	})
+
+	t.Run("db.destroy reads DB_SSLMODE from .env", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION=pgsql\nDB_SSLMODE=require\n"), 0644))
+
+		var opts DatabaseOptions
+		factory := func(engine string, o DatabaseOptions) (DatabaseClient, error) {
+			opts = o
+			return NewMockDatabaseClient(), nil
+		}
+		step := NewDbDestroyStepWithFactory(config.StepConfig{}, factory)
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+		ctx.SetDbSuffix("swift_runner")
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.Equal(t, "require", opts.SSLMode)
+	})
+}
+
+func TestDatabaseOptions_SSLMode(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DatabaseOptions
+		want string
+	}{
+		{"defaults to disable", DatabaseOptions{}, "disable"},
+		{"explicit mode", DatabaseOptions{SSLMode: "prefer"}, "prefer"},
+		{"CA implies verify-full", DatabaseOptions{SSLCA: "/ca.pem"}, "verify-full"},
+		{"skip-verify implies require", DatabaseOptions{SSLSkipVerify: true}, "require"},
+		{"skip-verify relaxes verify modes", DatabaseOptions{SSLMode: "verify-full", SSLSkipVerify: true}, "require"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.sslMode())
+		})
+	}
+}
+
+func TestMySQLTLSParam(t *testing.T) {
+	tests := []struct {
+		opts DatabaseOptions
+		want string
+	}{
+		{DatabaseOptions{}, ""},
+		{DatabaseOptions{SSLMode: "prefer"}, "preferred"},
+		{DatabaseOptions{SSLMode: "require"}, "skip-verify"},
+		{DatabaseOptions{SSLMode: "verify-full"}, "true"},
+	}
+	for _, tt := range tests {
+		got, err := mysqlTLSParam(tt.opts)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "ssl_mode %q", tt.opts.SSLMode)
+	}
+
+	t.Run("registers a config for a CA certificate", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, testCACertificate(t), 0644))
+
+		got, err := mysqlTLSParam(DatabaseOptions{Host: "db.example.com", SSLCA: caFile})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(got, "arbor-"), "expected a registered config name, got %q", got)
+	})
+
+	t.Run("rejects unreadable or invalid CA files", func(t *testing.T) {
+		_, err := mysqlTLSParam(DatabaseOptions{SSLCA: filepath.Join(t.TempDir(), "missing.pem")})
+		assert.ErrorContains(t, err, "reading ssl_ca")
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0644))
+		_, err = mysqlTLSParam(DatabaseOptions{SSLCA: caFile})
+		assert.ErrorContains(t, err, "contains no PEM certificates")
+	})
+}
+
+// testCACertificate returns a self-signed CA certificate in PEM form.
+func testCACertificate(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "arbor test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestDbCreateStep_CreateOptions(t *testing.T) {
+	run := func(t *testing.T, connection string, cfg config.StepConfig) (*MockDatabaseClient, *types.ScaffoldContext) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_CONNECTION="+connection+"\n"), 0644))
+
+		mockClient := NewMockDatabaseClient()
+		step := NewDbCreateStepWithFactory(cfg, MockClientFactory(mockClient))
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "testapp"}
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		return mockClient, ctx
+	}
+
+	t.Run("passes charset and collation to mysql", func(t *testing.T) {
+		mockClient, ctx := run(t, "mysql", config.StepConfig{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"})
+		assert.Equal(t, []CreateOptions{{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}}, mockClient.GetCreateOptions())
+		assert.Empty(t, ctx.Warnings())
+	})
+
+	t.Run("passes owner and template to pgsql", func(t *testing.T) {
+		mockClient, _ := run(t, "pgsql", config.StepConfig{Owner: "app", Template: "template0"})
+		assert.Equal(t, []CreateOptions{{Owner: "app", Template: "template0"}}, mockClient.GetCreateOptions())
+	})
+
+	t.Run("drops options for the other engine with a warning", func(t *testing.T) {
+		mockClient, ctx := run(t, "pgsql", config.StepConfig{Charset: "utf8mb4", Owner: "app"})
+		assert.Equal(t, []CreateOptions{{Owner: "app"}}, mockClient.GetCreateOptions())
+		assert.Equal(t, []string{"db.create: charset and collation apply to mysql databases and were ignored for pgsql"}, ctx.Warnings())
+	})
+}
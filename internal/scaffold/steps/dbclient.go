@@ -1,17 +1,29 @@
 package steps
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 // DatabaseClient abstracts database operations for testability
 type DatabaseClient interface {
-	CreateDatabase(name string) error
+	CreateDatabase(name string, opts CreateOptions) error
 	DropDatabase(name string) error
 	ListDatabases(pattern string) ([]string, error)
 	Ping() error
@@ -21,12 +33,106 @@ type DatabaseClient interface {
 // DatabaseClientFactory creates DatabaseClient instances
 type DatabaseClientFactory func(engine string, opts DatabaseOptions) (DatabaseClient, error)
 
+// CreateOptions are settings for a new database. Charset and Collation
+// apply to MySQL, Owner and Template to PostgreSQL.
+type CreateOptions struct {
+	Charset   string
+	Collation string
+	Owner     string
+	Template  string
+}
+
 // DatabaseOptions holds connection parameters
 type DatabaseOptions struct {
 	Host     string
 	Port     string
 	Username string
 	Password string
+	// Socket is a Unix socket path; when set it is used instead of
+	// Host and Port.
+	Socket string
+	// SSLMode is a PostgreSQL-style TLS mode (disable, allow, prefer,
+	// require, verify-ca, verify-full), SSLCA a CA certificate file and
+	// SSLSkipVerify disables certificate verification.
+	SSLMode       string
+	SSLCA         string
+	SSLSkipVerify bool
+}
+
+// sslMode returns the TLS mode to connect with. Without an explicit mode a
+// CA implies verify-full, skip-verify implies require, and no settings
+// mean disable. Skip-verify relaxes the verify modes to require.
+func (o DatabaseOptions) sslMode() string {
+	mode := o.SSLMode
+	if mode == "" {
+		switch {
+		case o.SSLCA != "":
+			mode = "verify-full"
+		case o.SSLSkipVerify:
+			mode = "require"
+		default:
+			mode = "disable"
+		}
+	}
+	if o.SSLSkipVerify && (mode == "verify-ca" || mode == "verify-full") {
+		mode = "require"
+	}
+	return mode
+}
+
+// dbTLS holds a database step's configured TLS settings.
+type dbTLS struct {
+	mode       string
+	ca         string
+	skipVerify bool
+}
+
+func newDbTLS(cfg config.StepConfig) dbTLS {
+	return dbTLS{mode: cfg.SSLMode, ca: cfg.SSLCA, skipVerify: cfg.SSLSkipVerify}
+}
+
+// apply sets opts' TLS settings from the step config, falling back to
+// DB_SSLMODE and DB_SSL_CA (or Laravel's MYSQL_ATTR_SSL_CA) in the
+// worktree's primary env file.
+func (t dbTLS) apply(opts *DatabaseOptions, ctx *types.ScaffoldContext) {
+	env := utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())
+	opts.SSLMode = cmp.Or(t.mode, env["DB_SSLMODE"])
+	opts.SSLCA = cmp.Or(t.ca, env["DB_SSL_CA"], env["MYSQL_ATTR_SSL_CA"])
+	opts.SSLSkipVerify = t.skipVerify
+}
+
+// Address describes where the options connect to, for messages.
+func (o DatabaseOptions) Address() string {
+	if o.Socket != "" {
+		return o.Socket
+	}
+	if o.Port != "" {
+		return o.Host + ":" + o.Port
+	}
+	return o.Host
+}
+
+// connectionSocket returns the Unix socket to connect through: --socket
+// from args, else DB_SOCKET from the worktree's primary env file.
+func connectionSocket(args []string, ctx *types.ScaffoldContext) string {
+	for i, arg := range args {
+		if arg == "--socket" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return utils.ReadEnvFile(ctx.WorktreePath, ctx.PrimaryEnvFile())["DB_SOCKET"]
+}
+
+// postgresSocketHost returns the host and port for connecting to
+// PostgreSQL through socket. PostgreSQL takes the socket's directory as the
+// host and reads the port from the socket name (.s.PGSQL.<port>), so both a
+// directory and a full socket path are accepted.
+func postgresSocketHost(socket, port string) (string, string) {
+	base := filepath.Base(socket)
+	if socketPort, ok := strings.CutPrefix(base, ".s.PGSQL."); ok && socketPort != "" {
+		return filepath.Dir(socket), socketPort
+	}
+	return socket, port
 }
 
 // DefaultDatabaseClientFactory creates real database clients
@@ -41,6 +147,86 @@ func DefaultDatabaseClientFactory(engine string, opts DatabaseOptions) (Database
 	}
 }
 
+// sharedClient returns the run's client for engine and opts, creating it
+// with factory on first use so steps connecting to the same server reuse
+// one connection. The client is closed at the end of the run by
+// ScaffoldContext.CloseClients, not by the step.
+func sharedClient(ctx *types.ScaffoldContext, factory DatabaseClientFactory, engine string, opts DatabaseOptions) (DatabaseClient, error) {
+	key := fmt.Sprintf("%s|%+v", engine, opts)
+	client, err := ctx.SharedClient(key, func() (io.Closer, error) {
+		return factory(engine, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(DatabaseClient), nil
+}
+
+// mysqlTLSParam returns the MySQL driver's tls DSN parameter for opts,
+// registering a TLS config when a CA certificate is given. An empty result
+// means no TLS.
+func mysqlTLSParam(opts DatabaseOptions) (string, error) {
+	mode := opts.sslMode()
+	switch mode {
+	case "disable":
+		return "", nil
+	case "allow", "prefer":
+		return "preferred", nil
+	case "require":
+		return "skip-verify", nil
+	}
+
+	if opts.SSLCA == "" {
+		return "true", nil
+	}
+
+	pem, err := os.ReadFile(opts.SSLCA)
+	if err != nil {
+		return "", fmt.Errorf("reading ssl_ca: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return "", fmt.Errorf("ssl_ca %s contains no PEM certificates", opts.SSLCA)
+	}
+
+	tlsConfig := &tls.Config{RootCAs: roots, ServerName: opts.Host}
+	if mode == "verify-ca" {
+		// Verify the chain against the CA but not the host name.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertChain(rawCerts, roots)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(mode + "|" + opts.Host + "|" + opts.SSLCA))
+	name := "arbor-" + hex.EncodeToString(sum[:8])
+	if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+		return "", fmt.Errorf("registering mysql tls config: %w", err)
+	}
+	return name, nil
+}
+
+// verifyCertChain verifies a server's certificate chain against roots.
+func verifyCertChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("server sent no certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("parsing server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
+
 // MySQLClient implements DatabaseClient for MySQL
 type MySQLClient struct {
 	db   *sql.DB
@@ -60,6 +246,17 @@ func NewMySQLClient(opts DatabaseOptions) (*MySQLClient, error) {
 	}
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/", opts.Username, opts.Password, opts.Host, opts.Port)
+	if opts.Socket != "" {
+		dsn = fmt.Sprintf("%s:%s@unix(%s)/", opts.Username, opts.Password, opts.Socket)
+	}
+
+	tlsParam, err := mysqlTLSParam(opts)
+	if err != nil {
+		return nil, err
+	}
+	if tlsParam != "" {
+		dsn += "?tls=" + tlsParam
+	}
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("opening mysql connection: %w", err)
@@ -76,8 +273,14 @@ func (c *MySQLClient) Close() error {
 	return c.db.Close()
 }
 
-func (c *MySQLClient) CreateDatabase(name string) error {
+func (c *MySQLClient) CreateDatabase(name string, opts CreateOptions) error {
 	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", name)
+	if opts.Charset != "" {
+		query += fmt.Sprintf(" CHARACTER SET `%s`", opts.Charset)
+	}
+	if opts.Collation != "" {
+		query += fmt.Sprintf(" COLLATE `%s`", opts.Collation)
+	}
 	_, err := c.db.Exec(query)
 	if err != nil {
 		return fmt.Errorf("creating database %s: %w", name, err)
@@ -131,8 +334,17 @@ func NewPostgreSQLClient(opts DatabaseOptions) (*PostgreSQLClient, error) {
 		opts.Username = "postgres"
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable",
-		opts.Host, opts.Port, opts.Username, opts.Password)
+	host, port := opts.Host, opts.Port
+	if opts.Socket != "" {
+		host, port = postgresSocketHost(opts.Socket, port)
+	}
+
+	mode := opts.sslMode()
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=%s",
+		host, port, opts.Username, opts.Password, mode)
+	if opts.SSLCA != "" && strings.HasPrefix(mode, "verify-") {
+		dsn += fmt.Sprintf(" sslrootcert='%s'", strings.ReplaceAll(opts.SSLCA, "'", `\'`))
+	}
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("opening postgres connection: %w", err)
@@ -149,7 +361,7 @@ func (c *PostgreSQLClient) Close() error {
 	return c.db.Close()
 }
 
-func (c *PostgreSQLClient) CreateDatabase(name string) error {
+func (c *PostgreSQLClient) CreateDatabase(name string, opts CreateOptions) error {
 	var exists bool
 	err := c.db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&exists)
 	if err != nil {
@@ -160,6 +372,12 @@ func (c *PostgreSQLClient) CreateDatabase(name string) error {
 	}
 
 	query := fmt.Sprintf("CREATE DATABASE \"%s\"", name)
+	if opts.Owner != "" {
+		query += fmt.Sprintf(" OWNER \"%s\"", opts.Owner)
+	}
+	if opts.Template != "" {
+		query += fmt.Sprintf(" TEMPLATE \"%s\"", opts.Template)
+	}
 	_, err = c.db.Exec(query)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
@@ -9,9 +9,13 @@ type MockDatabaseClient struct {
 	mu           sync.Mutex
 	databases    map[string]bool
 	createCalls  []string
+	createOpts   []CreateOptions
 	dropCalls    []string
 	listCalls    []string
 	pingError    error
+	pingFailures int
+	pingCalls    int
+	closeCalls   int
 	createError  error
 	dropError    error
 	listError    error
@@ -30,18 +34,35 @@ func NewMockDatabaseClient() *MockDatabaseClient {
 }
 
 func (m *MockDatabaseClient) Ping() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pingCalls++
+	if m.pingFailures > 0 && m.pingCalls > m.pingFailures {
+		return nil
+	}
 	return m.pingError
 }
 
 func (m *MockDatabaseClient) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeCalls++
 	return nil
 }
 
-func (m *MockDatabaseClient) CreateDatabase(name string) error {
+// CloseCount returns the number of times Close was called.
+func (m *MockDatabaseClient) CloseCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closeCalls
+}
+
+func (m *MockDatabaseClient) CreateDatabase(name string, opts CreateOptions) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.createCalls = append(m.createCalls, name)
+	m.createOpts = append(m.createOpts, opts)
 	m.callCount++
 
 	if m.createError != nil {
@@ -95,6 +116,19 @@ func (m *MockDatabaseClient) SetPingError(err error) {
 	m.pingError = err
 }
 
+// SetPingErrorOnFirstNCalls makes the first n pings fail with err.
+func (m *MockDatabaseClient) SetPingErrorOnFirstNCalls(n int, err error) {
+	m.pingError = err
+	m.pingFailures = n
+}
+
+// PingCount returns the number of pings made.
+func (m *MockDatabaseClient) PingCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pingCalls
+}
+
 func (m *MockDatabaseClient) SetCreateError(err error) {
 	m.createError = err
 }
@@ -125,6 +159,13 @@ func (m *MockDatabaseClient) GetCreateCalls() []string {
 	return result
 }
 
+// GetCreateOptions returns the options passed to each CreateDatabase call.
+func (m *MockDatabaseClient) GetCreateOptions() []CreateOptions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CreateOptions(nil), m.createOpts...)
+}
+
 func (m *MockDatabaseClient) GetDropCalls() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
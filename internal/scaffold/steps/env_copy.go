@@ -17,8 +17,13 @@ type EnvCopyStep struct {
 	sourceFile string
 	keys       []string
 	file       string
+	// fromMain copies from the default branch's worktree instead of
+	// source, skipping the keys it does not set.
+	fromMain bool
 }
 
+var _ types.ScaffoldStep = (*EnvCopyStep)(nil)
+
 func NewEnvCopyStep(cfg config.StepConfig) *EnvCopyStep {
 	keys := cfg.Keys
 	if len(keys) == 0 && cfg.Key != "" {
@@ -34,6 +39,16 @@ func NewEnvCopyStep(cfg config.StepConfig) *EnvCopyStep {
 	}
 }
 
+// NewEnvCopyFromMainStep creates an env.copy_from_main step, which copies
+// keys from the worktree on the default branch, such as its APP_KEY so
+// cookies and encrypted values stay valid across worktrees.
+func NewEnvCopyFromMainStep(cfg config.StepConfig) *EnvCopyStep {
+	step := NewEnvCopyStep(cfg)
+	step.name = "env.copy_from_main"
+	step.fromMain = true
+	return step
+}
+
 func (s *EnvCopyStep) Name() string {
 	return s.name
 }
@@ -45,21 +60,39 @@ func (s *EnvCopyStep) Condition(ctx *types.ScaffoldContext) bool {
 func (s *EnvCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
 	sourceFile := s.sourceFile
 	if sourceFile == "" {
-		sourceFile = ".env"
+		sourceFile = ctx.PrimaryEnvFile()
 	}
 
 	targetFile := s.file
 	if targetFile == "" {
-		targetFile = ".env"
+		targetFile = ctx.PrimaryEnvFile()
 	}
 
 	sourcePath := s.source
-	if !filepath.IsAbs(sourcePath) {
+	if s.fromMain {
+		mainPath, err := ctx.MainWorktreePath()
+		if err != nil {
+			return fmt.Errorf("finding the main worktree: %w", err)
+		}
+		if sameDir(mainPath, ctx.WorktreePath) {
+			if opts.Verbose {
+				fmt.Println("  This is the main worktree, nothing to copy")
+			}
+			return nil
+		}
+		sourcePath = mainPath
+	} else if !filepath.IsAbs(sourcePath) {
 		sourcePath = filepath.Join(ctx.WorktreePath, sourcePath)
 	}
 
 	sourceEnvPath := filepath.Join(sourcePath, sourceFile)
 	if _, err := os.Stat(sourceEnvPath); os.IsNotExist(err) {
+		// A main worktree that is not scaffolded yet has nothing to share;
+		// the worktree keeps, or generates, its own values.
+		if s.fromMain {
+			ctx.AddWarning(fmt.Sprintf("%s skipped: %s does not exist", s.name, sourceEnvPath))
+			return nil
+		}
 		return fmt.Errorf("source file %q does not exist", sourceEnvPath)
 	}
 
@@ -69,7 +102,7 @@ func (s *EnvCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	valuesToCopy := make(map[string]string)
 
 	for _, key := range s.keys {
-		if value, ok := sourceEnv[key]; ok {
+		if value, ok := sourceEnv[key]; ok && (value != "" || !s.fromMain) {
 			valuesToCopy[key] = value
 		} else {
 			missingKeys = append(missingKeys, key)
@@ -77,7 +110,13 @@ func (s *EnvCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	}
 
 	if len(missingKeys) > 0 {
-		return fmt.Errorf("keys not found in source: %s", strings.Join(missingKeys, ", "))
+		if !s.fromMain {
+			return fmt.Errorf("keys not found in source: %s", strings.Join(missingKeys, ", "))
+		}
+		ctx.AddWarning(fmt.Sprintf("%s: the main worktree does not set %s", s.name, strings.Join(missingKeys, ", ")))
+		if len(valuesToCopy) == 0 {
+			return nil
+		}
 	}
 
 	targetPath := filepath.Join(ctx.WorktreePath, targetFile)
@@ -101,34 +140,11 @@ func (s *EnvCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	}
 
 	for key, value := range valuesToCopy {
-		content = updateEnvContent(content, key, value)
-	}
-
-	tmpFile, err := os.CreateTemp(filepath.Dir(targetPath), filepath.Base(targetPath)+".*.tmp")
-	if err != nil {
-		return fmt.Errorf("creating temp file: %w", err)
-	}
-	tmpFileName := tmpFile.Name()
-
-	if _, err := tmpFile.Write(content); err != nil {
-		_ = tmpFile.Close()
-		_ = os.Remove(tmpFileName)
-		return fmt.Errorf("writing temp file: %w", err)
+		content = utils.SetEnvValue(content, key, value)
 	}
 
-	if err := tmpFile.Close(); err != nil {
-		_ = os.Remove(tmpFileName)
-		return fmt.Errorf("closing temp file: %w", err)
-	}
-
-	if err := os.Chmod(tmpFileName, oldPerms); err != nil {
-		_ = os.Remove(tmpFileName)
-		return fmt.Errorf("setting permissions: %w", err)
-	}
-
-	if err := os.Rename(tmpFileName, targetPath); err != nil {
-		_ = os.Remove(tmpFileName)
-		return fmt.Errorf("renaming temp file: %w", err)
+	if err := writeEnvFile(ctx, targetPath, content, oldPerms); err != nil {
+		return err
 	}
 
 	if opts.Verbose {
@@ -138,33 +154,14 @@ func (s *EnvCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) er
 	return nil
 }
 
-func updateEnvContent(content []byte, key, value string) []byte {
-	if len(content) == 0 {
-		return []byte(fmt.Sprintf("%s=%s\n", key, value))
-	}
-
-	lines := strings.Split(string(content), "\n")
-	updated := false
-
-	for i, line := range lines {
-		if strings.HasPrefix(line, key+"=") || strings.HasPrefix(line, key+" ") {
-			lines[i] = fmt.Sprintf("%s=%s", key, value)
-			updated = true
-			break
-		}
+// sameDir reports whether a and b name the same directory, resolving
+// symlinks such as macOS's /var -> /private/var.
+func sameDir(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
 	}
-
-	if !updated {
-		if !strings.HasSuffix(string(content), "\n") {
-			content = append(content, '\n')
-		}
-		content = append(content, []byte(fmt.Sprintf("%s=%s\n", key, value))...)
-	} else {
-		content = []byte(strings.Join(lines, "\n"))
-		if !strings.HasSuffix(string(content), "\n") {
-			content = append(content, '\n')
-		}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
 	}
-
-	return content
+	return filepath.Clean(a) == filepath.Clean(b)
 }
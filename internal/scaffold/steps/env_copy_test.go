@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
@@ -238,3 +239,57 @@ func TestEnvCopyStep(t *testing.T) {
 		assert.Contains(t, err.Error(), "MISSING_KEY")
 	})
 }
+
+func TestEnvCopyFromMainStep(t *testing.T) {
+	barePath := createTestRepo(t)
+	projectDir := filepath.Dir(barePath)
+	mainPath := filepath.Join(projectDir, "main")
+	featurePath := filepath.Join(projectDir, "feature")
+	require.NoError(t, git.CreateWorktree(barePath, mainPath, "main", ""))
+	require.NoError(t, git.CreateWorktree(barePath, featurePath, "feature", "main"))
+
+	run := func(t *testing.T, path string, cfg config.StepConfig) *types.ScaffoldContext {
+		t.Helper()
+		ctx := &types.ScaffoldContext{WorktreePath: path, BarePath: barePath, DefaultBranch: "main"}
+		require.NoError(t, NewEnvCopyFromMainStep(cfg).Run(ctx, types.StepOptions{}))
+		return ctx
+	}
+
+	t.Run("skips when the main worktree has no env file", func(t *testing.T) {
+		ctx := run(t, featurePath, config.StepConfig{Keys: []string{"APP_KEY"}})
+
+		assert.NoFileExists(t, filepath.Join(featurePath, ".env"))
+		require.Len(t, ctx.Warnings(), 1)
+		assert.Contains(t, ctx.Warnings()[0], "does not exist")
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, ".env"), []byte("APP_KEY=base64:main\nREVERB_APP_KEY=\n"), 0644))
+
+	t.Run("copies the main worktree's keys", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(featurePath, ".env"), []byte("APP_NAME=feature\nAPP_KEY=\n"), 0644))
+
+		ctx := run(t, featurePath, config.StepConfig{Keys: []string{"APP_KEY", "REVERB_APP_KEY"}})
+
+		content, err := os.ReadFile(filepath.Join(featurePath, ".env"))
+		require.NoError(t, err)
+		assert.Equal(t, "APP_NAME=feature\nAPP_KEY=base64:main\n", string(content))
+		assert.Equal(t, []string{"env.copy_from_main: the main worktree does not set REVERB_APP_KEY"}, ctx.Warnings())
+	})
+
+	t.Run("does nothing in the main worktree", func(t *testing.T) {
+		ctx := run(t, mainPath, config.StepConfig{Keys: []string{"APP_KEY"}})
+
+		content, err := os.ReadFile(filepath.Join(mainPath, ".env"))
+		require.NoError(t, err)
+		assert.Equal(t, "APP_KEY=base64:main\nREVERB_APP_KEY=\n", string(content))
+		assert.Empty(t, ctx.Warnings())
+	})
+
+	t.Run("fails without a main worktree", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: featurePath, BarePath: barePath, DefaultBranch: "develop"}
+		err := NewEnvCopyFromMainStep(config.StepConfig{Keys: []string{"APP_KEY"}}).Run(ctx, types.StepOptions{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no worktree is on the default branch develop")
+	})
+}
@@ -15,6 +15,8 @@ type EnvReadStep struct {
 	file    string
 }
 
+var _ types.ScaffoldStep = (*EnvReadStep)(nil)
+
 func NewEnvReadStep(cfg config.StepConfig) *EnvReadStep {
 	return &EnvReadStep{
 		name:    "env.read",
@@ -35,7 +37,7 @@ func (s *EnvReadStep) Condition(ctx *types.ScaffoldContext) bool {
 func (s *EnvReadStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
 	file := s.file
 	if file == "" {
-		file = ".env"
+		file = ctx.PrimaryEnvFile()
 	}
 
 	env := utils.ReadEnvFile(ctx.WorktreePath, file)
@@ -0,0 +1,95 @@
+package steps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// EnvUnsetStep removes keys from an env file, leaving the rest of the file
+// as it is.
+type EnvUnsetStep struct {
+	name string
+	keys []string
+	file string
+}
+
+var _ types.ScaffoldStep = (*EnvUnsetStep)(nil)
+
+func NewEnvUnsetStep(cfg config.StepConfig) *EnvUnsetStep {
+	keys := cfg.Keys
+	if len(keys) == 0 && cfg.Key != "" {
+		keys = []string{cfg.Key}
+	}
+
+	return &EnvUnsetStep{
+		name: "env.unset",
+		keys: keys,
+		file: cfg.File,
+	}
+}
+
+func (s *EnvUnsetStep) Name() string {
+	return s.name
+}
+
+func (s *EnvUnsetStep) Condition(ctx *types.ScaffoldContext) bool {
+	return true
+}
+
+func (s *EnvUnsetStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	file := s.file
+	if file == "" {
+		file = ctx.PrimaryEnvFile()
+	}
+
+	filePath := filepath.Join(ctx.WorktreePath, file)
+
+	lock := getFileLock(filePath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		if opts.Verbose {
+			fmt.Printf("  %s does not exist, nothing to unset\n", file)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("checking file: %w", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	var removed []string
+	for _, key := range s.keys {
+		var ok bool
+		if content, ok = utils.UnsetEnvValue(content, key); ok {
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) == 0 {
+		if opts.Verbose {
+			fmt.Printf("  No keys to remove from %s\n", file)
+		}
+		return nil
+	}
+
+	if err := writeEnvFile(ctx, filePath, content, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if opts.Verbose {
+		fmt.Printf("  Removed %d key(s) from %s\n", len(removed), file)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestEnvUnsetStep(t *testing.T) {
+	t.Run("name returns env.unset", func(t *testing.T) {
+		step := NewEnvUnsetStep(config.StepConfig{})
+		assert.Equal(t, "env.unset", step.Name())
+	})
+
+	t.Run("removes keys and keeps the rest of the file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		envFile := filepath.Join(tmpDir, ".env")
+		require.NoError(t, os.WriteFile(envFile, []byte("# App\nAPP_NAME=arbor\nSENTRY_DSN=https://example\n\n# Mail\nMAIL_HOST=smtp\nMAIL_PORT=25\n"), 0600))
+
+		step := NewEnvUnsetStep(config.StepConfig{Keys: []string{"SENTRY_DSN", "MAIL_PORT", "MISSING"}})
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+		content, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, "# App\nAPP_NAME=arbor\n\n# Mail\nMAIL_HOST=smtp\n", string(content))
+
+		info, err := os.Stat(envFile)
+		require.NoError(t, err)
+		if os.PathSeparator == '/' {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("uses the project's env file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		envFile := filepath.Join(tmpDir, ".env.local")
+		require.NoError(t, os.WriteFile(envFile, []byte("A=1\nB=2\n"), 0644))
+
+		step := NewEnvUnsetStep(config.StepConfig{Key: "A"})
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir, EnvFile: ".env.local"}, types.StepOptions{}))
+
+		content, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, "B=2\n", string(content))
+	})
+
+	t.Run("missing file is not an error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		step := NewEnvUnsetStep(config.StepConfig{Key: "A"})
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".env"))
+	})
+}
@@ -1,6 +1,7 @@
 package steps
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 	"github.com/artisanexperiences/arbor/internal/fs"
 	"github.com/artisanexperiences/arbor/internal/scaffold/template"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/scaffold/words"
+	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
 // fileLocks ensures only one goroutine modifies a given file at a time
@@ -30,15 +33,38 @@ func getFileLock(path string) *sync.Mutex {
 	return fileLocks[path]
 }
 
+// writeEnvFile atomically replaces the env file at path with content,
+// recording the change for the worktree's file journal. The first change a
+// run makes to a file keeps the version it replaces at config.BackupPath,
+// so the single .bak holds the file as it was before the run rather than
+// before its last step.
+func writeEnvFile(ctx *types.ScaffoldContext, path string, content []byte, perm os.FileMode) error {
+	if ctx.RecordFileChange(path) {
+		previous, err := os.ReadFile(path)
+		if err == nil && !bytes.Equal(previous, content) {
+			if err := utils.WriteFileAtomic(config.BackupPath(path), previous, 0600); err != nil {
+				return fmt.Errorf("backing up %s: %w", filepath.Base(path), err)
+			}
+		}
+	}
+	return utils.WriteFileAtomic(path, content, perm)
+}
+
 type EnvWriteStep struct {
 	name      string
 	key       string
 	value     string
 	file      string
+	mode      string
 	fs        fs.FS
 	useRealFS bool // flag to indicate if we should use real FS for atomic operations
 }
 
+var (
+	_ types.ScaffoldStep  = (*EnvWriteStep)(nil)
+	_ types.PlanValidator = (*EnvWriteStep)(nil)
+)
+
 // NewEnvWriteStep creates an env.write step with the default file system.
 func NewEnvWriteStep(cfg config.StepConfig) *EnvWriteStep {
 	return NewEnvWriteStepWithFS(cfg, nil)
@@ -57,6 +83,7 @@ func NewEnvWriteStepWithFS(cfg config.StepConfig, filesystem fs.FS) *EnvWriteSte
 		key:       cfg.Key,
 		value:     cfg.Value,
 		file:      cfg.File,
+		mode:      cfg.Mode,
 		fs:        filesystem,
 		useRealFS: useRealFS,
 	}
@@ -70,10 +97,42 @@ func (s *EnvWriteStep) Condition(ctx *types.ScaffoldContext) bool {
 	return true
 }
 
+// ValidatePlan reports database names the step would write that are longer
+// than the env file's DB_CONNECTION allows.
+func (s *EnvWriteStep) ValidatePlan(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	if !strings.Contains(s.key, "DB_DATABASE") {
+		return nil
+	}
+
+	file := s.file
+	if file == "" {
+		file = ctx.PrimaryEnvFile()
+	}
+
+	var engine string
+	switch utils.ReadEnvFile(ctx.WorktreePath, file)["DB_CONNECTION"] {
+	case "mysql", "mariadb":
+		engine = "mysql"
+	case "pgsql", "postgres", "postgresql":
+		engine = "pgsql"
+	default:
+		return nil
+	}
+
+	value, err := template.ReplaceTemplateVars(s.value, ctx)
+	if err != nil {
+		return fmt.Errorf("template replacement failed: %w", err)
+	}
+	if limit := words.MaxIdentifierLength(engine); len(value) > limit {
+		return fmt.Errorf("%s %q is %d characters; %s database names are limited to %d", s.key, value, len(value), engine, limit)
+	}
+	return nil
+}
+
 func (s *EnvWriteStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
 	file := s.file
 	if file == "" {
-		file = ".env"
+		file = ctx.PrimaryEnvFile()
 	}
 
 	replacedValue, err := template.ReplaceTemplateVars(s.value, ctx)
@@ -100,78 +159,37 @@ func (s *EnvWriteStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) e
 		oldPerms = 0644
 	}
 
-	var content []byte
-	if _, err := s.fs.Stat(filePath); err != nil {
-		// File doesn't exist, create new content
-		content = []byte(fmt.Sprintf("%s=%s\n", s.key, replacedValue))
-	} else {
-		// File exists, read and update
-		content, err = s.fs.ReadFile(filePath)
+	var original []byte
+	if _, err := s.fs.Stat(filePath); err == nil {
+		original, err = s.fs.ReadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("reading file: %w", err)
 		}
+	}
 
-		var updated bool
-		lines := strings.Split(string(content), "\n")
-		for i, line := range lines {
-			if strings.HasPrefix(line, s.key+"=") || strings.HasPrefix(line, s.key+" ") {
-				lines[i] = fmt.Sprintf("%s=%s", s.key, replacedValue)
-				updated = true
-				break
-			}
+	exists := utils.EnvExists(utils.ParseEnv(original), s.key)
+	if (s.mode == config.EnvWriteModeUpdateOnly && !exists) || (s.mode == config.EnvWriteModeAppendIfMissing && exists) {
+		if opts.Verbose {
+			fmt.Printf("  Left %s unchanged in %s (mode %s)\n", s.key, file, s.mode)
 		}
+		return nil
+	}
 
-		if !updated {
-			if !strings.HasSuffix(string(content), "\n") {
-				content = append(content, '\n')
-			}
-			content = append(content, []byte(fmt.Sprintf("%s=%s\n", s.key, replacedValue))...)
-		} else {
-			content = []byte(strings.Join(lines, "\n"))
-			if !strings.HasSuffix(string(content), "\n") {
-				content = append(content, '\n')
-			}
+	content := utils.SetEnvValue(original, s.key, replacedValue)
+	if original != nil && bytes.Equal(content, original) {
+		if opts.Verbose {
+			fmt.Printf("  %s already set in %s\n", s.key, file)
 		}
+		return nil
 	}
 
-	// For real FS, use atomic write with temp file
-	// For mock FS, write directly (CreateTemp not fully supported)
+	// A mock FS cannot hold the temporary file, so it is written directly.
 	if s.useRealFS {
-		// Use a unique temp file name to avoid race conditions when multiple
-		// env.write steps run in parallel with the same priority
-		tmpFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
-		if err != nil {
-			return fmt.Errorf("creating temp file: %w", err)
-		}
-		tmpFileName := tmpFile.Name()
-
-		// Write content and close the file
-		if _, err := tmpFile.Write(content); err != nil {
-			_ = tmpFile.Close()
-			_ = os.Remove(tmpFileName)
-			return fmt.Errorf("writing temp file: %w", err)
-		}
-
-		if err := tmpFile.Close(); err != nil {
-			_ = os.Remove(tmpFileName)
-			return fmt.Errorf("closing temp file: %w", err)
-		}
-
-		// Set permissions
-		if err := os.Chmod(tmpFileName, oldPerms); err != nil {
-			_ = os.Remove(tmpFileName)
-			return fmt.Errorf("setting permissions: %w", err)
-		}
-
-		if err := os.Rename(tmpFileName, filePath); err != nil {
-			_ = os.Remove(tmpFileName)
-			return fmt.Errorf("renaming temp file: %w", err)
-		}
-	} else {
-		// For mock FS, write directly without atomic operations
-		if err := s.fs.WriteFile(filePath, content, oldPerms); err != nil {
-			return fmt.Errorf("writing file: %w", err)
+		if err := writeEnvFile(ctx, filePath, content, oldPerms); err != nil {
+			return err
 		}
+	} else if err := s.fs.WriteFile(filePath, content, oldPerms); err != nil {
+		return fmt.Errorf("writing file: %w", err)
 	}
 
 	if opts.Verbose {
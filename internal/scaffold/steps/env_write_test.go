@@ -40,6 +40,20 @@ func TestEnvWriteStep(t *testing.T) {
 		assert.Equal(t, "DB_DATABASE=test_db\n", string(content))
 	})
 
+	t.Run("defaults to the project's env file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		step := NewEnvWriteStep(config.StepConfig{Key: "DATABASE_URL", Value: "postgres://localhost/app"})
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, EnvFile: ".env.local"}
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, ".env.local"))
+		require.NoError(t, err)
+		assert.Equal(t, "DATABASE_URL=postgres://localhost/app\n", string(content))
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".env"))
+	})
+
 	t.Run("creates parent directory if it doesn't exist", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		nestedPath := filepath.Join(tmpDir, "nonexistent", "nested")
@@ -291,4 +305,99 @@ APP_NAME=myapp
 			assert.False(t, strings.Contains(file.Name(), ".tmp"), "no temp files should remain")
 		}
 	})
+
+	t.Run("keeps the env file as it was before the run as a backup", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, ".git"), 0755))
+		envPath := filepath.Join(tmpDir, ".env")
+		require.NoError(t, os.WriteFile(envPath, []byte("APP_NAME=shop\n"), 0600))
+
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+		require.NoError(t, NewEnvWriteStep(config.StepConfig{Key: "DB_DATABASE", Value: "shop_one"}).Run(ctx, types.StepOptions{}))
+		require.NoError(t, NewEnvUnsetStep(config.StepConfig{Keys: []string{"APP_NAME"}}).Run(ctx, types.StepOptions{}))
+
+		backup, err := os.ReadFile(filepath.Join(tmpDir, ".git", "arbor.env.bak"))
+		require.NoError(t, err)
+		assert.Equal(t, "APP_NAME=shop\n", string(backup), "later steps of the run keep the first backup")
+		assert.NoFileExists(t, envPath+".bak", "the backup is kept out of the worktree")
+
+		info, err := os.Stat(envPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the env file keeps its permissions")
+	})
+}
+
+func TestEnvWriteStep_Modes(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		content string
+		want    string
+	}{
+		{"upsert updates", config.EnvWriteModeUpsert, "APP_ENV=production\n", "APP_ENV=local\n"},
+		{"upsert appends", "", "OTHER=1\n", "OTHER=1\nAPP_ENV=local\n"},
+		{"update_only updates", config.EnvWriteModeUpdateOnly, "APP_ENV=production\n", "APP_ENV=local\n"},
+		{"update_only skips missing key", config.EnvWriteModeUpdateOnly, "OTHER=1\n", "OTHER=1\n"},
+		{"append_if_missing appends", config.EnvWriteModeAppendIfMissing, "OTHER=1\n", "OTHER=1\nAPP_ENV=local\n"},
+		{"append_if_missing keeps existing key", config.EnvWriteModeAppendIfMissing, "APP_ENV=production\n", "APP_ENV=production\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			envFile := filepath.Join(tmpDir, ".env")
+			require.NoError(t, os.WriteFile(envFile, []byte(tt.content), 0644))
+
+			step := NewEnvWriteStep(config.StepConfig{Key: "APP_ENV", Value: "local", Mode: tt.mode})
+			require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+			content, err := os.ReadFile(envFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+
+	t.Run("update_only does not create the file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		step := NewEnvWriteStep(config.StepConfig{Key: "APP_ENV", Value: "local", Mode: config.EnvWriteModeUpdateOnly})
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".env"))
+	})
+}
+
+func TestEnvWriteStep_ValidatePlan(t *testing.T) {
+	longName := strings.Repeat("a", 70)
+
+	writeEnv := func(t *testing.T, content string) string {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte(content), 0644))
+		return tmpDir
+	}
+
+	t.Run("rejects database names over the engine limit", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=pgsql\n"), Vars: map[string]string{"Name": longName}}
+		step := NewEnvWriteStep(config.StepConfig{Key: "DB_DATABASE", Value: "{{ .Name }}"})
+
+		err := step.ValidatePlan(ctx, types.StepOptions{DryRun: true})
+		assert.ErrorContains(t, err, "pgsql database names are limited to 63")
+	})
+
+	t.Run("allows names within the limit", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=mysql\n"), SiteName: "shop", DbSuffix: "swift_runner"}
+		step := NewEnvWriteStep(config.StepConfig{Key: "DB_DATABASE", Value: "{{ .DbName }}"})
+
+		assert.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+	})
+
+	t.Run("ignores sqlite paths and other keys", func(t *testing.T) {
+		ctx := &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=sqlite\n")}
+		step := NewEnvWriteStep(config.StepConfig{Key: "DB_DATABASE", Value: longName})
+		assert.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+
+		ctx = &types.ScaffoldContext{WorktreePath: writeEnv(t, "DB_CONNECTION=mysql\n")}
+		step = NewEnvWriteStep(config.StepConfig{Key: "APP_NAME", Value: longName})
+		assert.NoError(t, step.ValidatePlan(ctx, types.StepOptions{DryRun: true}))
+	})
 }
@@ -1,19 +1,26 @@
 package steps
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/fs"
+	"github.com/artisanexperiences/arbor/internal/git"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
 type FileCopyStep struct {
 	from string
 	to   string
-	fs   fs.FS
+	// eol is one of config.EOLs; empty means auto.
+	eol string
+	fs  fs.FS
 }
 
+var _ types.ScaffoldStep = (*FileCopyStep)(nil)
+
 // NewFileCopyStep creates a file copy step with the default file system.
 func NewFileCopyStep(from, to string) *FileCopyStep {
 	return NewFileCopyStepWithFS(from, to, nil)
@@ -28,6 +35,14 @@ func NewFileCopyStepWithFS(from, to string, filesystem fs.FS) *FileCopyStep {
 	return &FileCopyStep{from: from, to: to, fs: filesystem}
 }
 
+// NewFileCopyStepFromConfig creates a file copy step from its arbor.yaml
+// config. This is the factory function used by the registry.
+func NewFileCopyStepFromConfig(cfg config.StepConfig) *FileCopyStep {
+	step := NewFileCopyStep(cfg.From, cfg.To)
+	step.eol = cfg.EOL
+	return step
+}
+
 func (s *FileCopyStep) Name() string {
 	return "file.copy"
 }
@@ -45,7 +60,9 @@ func (s *FileCopyStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) e
 	if err != nil {
 		return fmt.Errorf("reading source file %s: %w", fromPath, err)
 	}
+	data = s.convertLineEndings(ctx, data, opts)
 
+	ctx.RecordFileChange(toPath)
 	if err := s.fs.WriteFile(toPath, data, 0644); err != nil {
 		return fmt.Errorf("writing destination file %s: %w", toPath, err)
 	}
@@ -58,3 +75,36 @@ func (s *FileCopyStep) Condition(ctx *types.ScaffoldContext) bool {
 	_, err := s.fs.Stat(fromPath)
 	return err == nil
 }
+
+// convertLineEndings gives text the line ending eol asks for. In auto
+// mode that is the one git checks the destination out with, so a copy
+// matches the repository's .gitattributes and core.autocrlf; outside a
+// repository the file is left as it is. Binary files are never changed.
+func (s *FileCopyStep) convertLineEndings(ctx *types.ScaffoldContext, data []byte, opts types.StepOptions) []byte {
+	eol := s.eol
+	if eol == "" {
+		eol = config.EOLAuto
+	}
+	if eol == config.EOLKeep || !bytes.Contains(data, []byte("\n")) || bytes.IndexByte(data, 0) >= 0 {
+		return data
+	}
+	if eol == config.EOLAuto {
+		ending, err := git.CheckoutLineEnding(ctx.WorktreePath, filepath.ToSlash(s.to))
+		if err != nil {
+			if opts.Verbose {
+				fmt.Printf("  Keeping line endings of %s: %v\n", s.from, err)
+			}
+			return data
+		}
+		if ending == "" {
+			return data
+		}
+		eol = ending
+	}
+
+	lf := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if eol == config.EOLCRLF {
+		return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	}
+	return lf
+}
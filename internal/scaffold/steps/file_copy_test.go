@@ -2,11 +2,14 @@ package steps
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 )
 
@@ -96,4 +99,61 @@ func TestFileCopyStep(t *testing.T) {
 		step := NewFileCopyStep("from", "to")
 		assert.Equal(t, "file.copy", step.Name())
 	})
+
+	t.Run("converts mixed line endings", func(t *testing.T) {
+		mixed := "A=1\r\nB=2\nC=3\r\n"
+		tests := map[string]string{
+			config.EOLKeep: mixed,
+			config.EOLLF:   "A=1\nB=2\nC=3\n",
+			config.EOLCRLF: "A=1\r\nB=2\r\nC=3\r\n",
+			// Outside a repository auto leaves the file as it is
+			config.EOLAuto: mixed,
+		}
+		for eol, want := range tests {
+			tmpDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "source.txt"), []byte(mixed), 0644))
+
+			step := NewFileCopyStepFromConfig(config.StepConfig{From: "source.txt", To: "destination.txt", EOL: eol})
+			require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+			result, err := os.ReadFile(filepath.Join(tmpDir, "destination.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, want, string(result), "eol: %s", eol)
+		}
+	})
+
+	t.Run("leaves binary files alone", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		content := []byte("\x89PNG\r\n\x1a\n\x00")
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "logo.png"), content, 0644))
+
+		step := NewFileCopyStepFromConfig(config.StepConfig{From: "logo.png", To: "copy.png", EOL: config.EOLLF})
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: tmpDir}, types.StepOptions{}))
+
+		result, err := os.ReadFile(filepath.Join(tmpDir, "copy.png"))
+		require.NoError(t, err)
+		assert.Equal(t, content, result)
+	})
+
+	t.Run("auto follows gitattributes of the destination", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		output, err := exec.Command("git", "init", tmpDir).CombinedOutput()
+		require.NoError(t, err, string(output))
+		output, err = exec.Command("git", "-C", tmpDir, "config", "core.autocrlf", "false").CombinedOutput()
+		require.NoError(t, err, string(output))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitattributes"), []byte("*.cmd text eol=crlf\n.env text eol=lf\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "run.stub"), []byte("@echo off\nphp artisan serve\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env.example"), []byte("A=1\r\nB=2\r\n"), 0644))
+
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir}
+		require.NoError(t, NewFileCopyStep("run.stub", "run.cmd").Run(ctx, types.StepOptions{}))
+		require.NoError(t, NewFileCopyStep(".env.example", ".env").Run(ctx, types.StepOptions{}))
+
+		cmd, err := os.ReadFile(filepath.Join(tmpDir, "run.cmd"))
+		require.NoError(t, err)
+		assert.Equal(t, "@echo off\r\nphp artisan serve\r\n", string(cmd))
+		env, err := os.ReadFile(filepath.Join(tmpDir, ".env"))
+		require.NoError(t, err)
+		assert.Equal(t, "A=1\nB=2\n", string(env))
+	})
 }
@@ -0,0 +1,59 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// GitConfigStep sets a git config value for one worktree, such as the
+// user.email to commit with or core.hooksPath, without touching the
+// repository's other worktrees.
+type GitConfigStep struct {
+	key   string
+	value string
+}
+
+var (
+	_ types.ScaffoldStep = (*GitConfigStep)(nil)
+	_ types.Describer    = (*GitConfigStep)(nil)
+)
+
+func NewGitConfigStep(cfg config.StepConfig) *GitConfigStep {
+	return &GitConfigStep{
+		key:   cfg.Key,
+		value: cfg.Value,
+	}
+}
+
+func (s *GitConfigStep) Name() string {
+	return "git.config"
+}
+
+// Description names the key being set, such as "Setting git config
+// user.email".
+func (s *GitConfigStep) Description() string {
+	return fmt.Sprintf("Setting git config %s", s.key)
+}
+
+func (s *GitConfigStep) Condition(ctx *types.ScaffoldContext) bool {
+	return true
+}
+
+func (s *GitConfigStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	value, err := template.ReplaceTemplateVars(s.value, ctx)
+	if err != nil {
+		return fmt.Errorf("template replacement failed: %w", err)
+	}
+
+	if err := git.SetWorktreeConfig(ctx.WorktreePath, s.key, value); err != nil {
+		return fmt.Errorf("git.config: %w", err)
+	}
+	if opts.Verbose {
+		fmt.Printf("  Set %s=%s for this worktree\n", s.key, value)
+	}
+	return nil
+}
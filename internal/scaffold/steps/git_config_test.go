@@ -0,0 +1,37 @@
+package steps
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestGitConfigStep(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+
+	step := NewGitConfigStep(config.StepConfig{Key: "user.email", Value: "{{ .Branch }}@work.example"})
+	assert.Equal(t, "git.config", step.Name())
+	assert.Equal(t, "Setting git config user.email", step.Description())
+
+	ctx := &types.ScaffoldContext{WorktreePath: dir, Branch: "feature"}
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	output, err := exec.Command("git", "-C", dir, "config", "--worktree", "user.email").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "feature@work.example", strings.TrimSpace(string(output)))
+}
+
+func TestGitConfigStep_NotARepository(t *testing.T) {
+	step := NewGitConfigStep(config.StepConfig{Key: "core.hooksPath", Value: ".githooks"})
+
+	err := step.Run(&types.ScaffoldContext{WorktreePath: t.TempDir()}, types.StepOptions{})
+
+	assert.ErrorContains(t, err, "git.config:")
+}
@@ -0,0 +1,179 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// gitHookNames are the hooks git runs; other files in the hooks directory,
+// such as a README, are not installed.
+var gitHookNames = []string{
+	"applypatch-msg", "pre-applypatch", "post-applypatch", "pre-commit", "pre-merge-commit",
+	"prepare-commit-msg", "commit-msg", "post-commit", "pre-rebase", "post-checkout", "post-merge",
+	"pre-push", "pre-receive", "update", "proc-receive", "post-receive", "post-update",
+	"reference-transaction", "push-to-checkout", "pre-auto-gc", "post-rewrite",
+	"sendemail-validate", "fsmonitor-watchman", "post-index-change",
+}
+
+var lefthookConfigs = []string{"lefthook.yml", "lefthook.yaml", ".lefthook.yml", ".lefthook.yaml"}
+
+// GitHooksStep installs git hooks for one worktree. Worktrees do not share
+// hooks installed by hand, so every new worktree needs them installed.
+//
+// The directory type copies the hook scripts in from into the worktree's
+// git directory, rendering those ending in .tmpl as templates, and points
+// the worktree's core.hooksPath at them. The husky and lefthook types run
+// the project's hook manager instead.
+type GitHooksStep struct {
+	hookType string
+	from     string
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep = (*GitHooksStep)(nil)
+	_ types.Describer    = (*GitHooksStep)(nil)
+)
+
+// NewGitHooksStep creates a git.hooks step with the default command executor.
+func NewGitHooksStep(cfg config.StepConfig) *GitHooksStep {
+	return NewGitHooksStepWithExecutor(cfg, nil)
+}
+
+// NewGitHooksStepWithExecutor creates a git.hooks step with a custom command
+// executor. This is useful for testing with mock executors.
+func NewGitHooksStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *GitHooksStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	hookType := cfg.Type
+	if hookType == "" {
+		hookType = config.GitHooksDirectory
+	}
+	from := cfg.From
+	if from == "" {
+		from = config.DefaultGitHooksDir
+	}
+	return &GitHooksStep{hookType: hookType, from: from, executor: executor}
+}
+
+func (s *GitHooksStep) Name() string {
+	return "git.hooks"
+}
+
+// Description names where the hooks come from, such as "Installing git
+// hooks from .githooks" or "Installing husky hooks".
+func (s *GitHooksStep) Description() string {
+	if s.hookType == config.GitHooksDirectory {
+		return fmt.Sprintf("Installing git hooks from %s", s.from)
+	}
+	return fmt.Sprintf("Installing %s hooks", s.hookType)
+}
+
+// Condition skips the step in worktrees without the hooks directory or the
+// hook manager's config.
+func (s *GitHooksStep) Condition(ctx *types.ScaffoldContext) bool {
+	switch s.hookType {
+	case config.GitHooksHusky:
+		return isDir(filepath.Join(ctx.WorktreePath, ".husky"))
+	case config.GitHooksLefthook:
+		return slices.ContainsFunc(lefthookConfigs, func(name string) bool {
+			_, err := os.Stat(filepath.Join(ctx.WorktreePath, name))
+			return err == nil
+		})
+	}
+	return isDir(filepath.Join(ctx.WorktreePath, s.from))
+}
+
+func (s *GitHooksStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	switch s.hookType {
+	case config.GitHooksHusky:
+		return s.runManager(ctx, "npx", []string{"--no-install", "husky"})
+	case config.GitHooksLefthook:
+		if _, err := exec.LookPath("lefthook"); err == nil {
+			return s.runManager(ctx, "lefthook", []string{"install"})
+		}
+		return s.runManager(ctx, "npx", []string{"--no-install", "lefthook", "install"})
+	}
+	return s.installDirectory(ctx, opts)
+}
+
+func (s *GitHooksStep) runManager(ctx *types.ScaffoldContext, binary string, args []string) error {
+	output, err := sandboxed(ctx, s.executor).RunBinary(context.Background(), ctx.WorktreePath, binary, args)
+	if err != nil {
+		return fmt.Errorf("git.hooks: %s failed: %w\n%s", s.hookType, err, string(output))
+	}
+	return nil
+}
+
+// installDirectory copies the hooks into <git-dir>/arbor-hooks, replacing
+// any installed before, so hooks removed from the project stop running.
+func (s *GitHooksStep) installDirectory(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	src := filepath.Join(ctx.WorktreePath, s.from)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("git.hooks: reading %s: %w", s.from, err)
+	}
+
+	gitDir, err := git.GitDir(ctx.WorktreePath)
+	if err != nil {
+		return fmt.Errorf("git.hooks: %w", err)
+	}
+	dst := filepath.Join(gitDir, "arbor-hooks")
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("git.hooks: removing old hooks: %w", err)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("git.hooks: creating %s: %w", dst, err)
+	}
+
+	var installed []string
+	for _, entry := range entries {
+		name, isTemplate := strings.CutSuffix(entry.Name(), ".tmpl")
+		if entry.IsDir() || !slices.Contains(gitHookNames, name) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("git.hooks: reading %s: %w", entry.Name(), err)
+		}
+		if isTemplate {
+			rendered, err := template.ReplaceTemplateVars(string(content), ctx)
+			if err != nil {
+				return fmt.Errorf("git.hooks: rendering %s: %w", entry.Name(), err)
+			}
+			content = []byte(rendered)
+		}
+		if err := os.WriteFile(filepath.Join(dst, name), content, 0755); err != nil {
+			return fmt.Errorf("git.hooks: writing %s: %w", name, err)
+		}
+		installed = append(installed, name)
+	}
+	if len(installed) == 0 {
+		return fmt.Errorf("git.hooks: no git hooks found in %s; name scripts after the hook they run for, such as pre-commit", s.from)
+	}
+
+	if err := git.SetWorktreeConfig(ctx.WorktreePath, "core.hooksPath", dst); err != nil {
+		return fmt.Errorf("git.hooks: %w", err)
+	}
+	if opts.Verbose {
+		fmt.Printf("  Installed %s\n", strings.Join(installed, ", "))
+	}
+	return nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
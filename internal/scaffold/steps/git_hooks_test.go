@@ -0,0 +1,98 @@
+package steps
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestGitHooksStep_Directory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	hooksDir := filepath.Join(dir, ".githooks")
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte("#!/bin/sh\nexit 0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-push.tmpl"), []byte("#!/bin/sh\necho {{ .Branch }}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "README.md"), []byte("hooks"), 0644))
+
+	step := NewGitHooksStep(config.StepConfig{})
+	ctx := &types.ScaffoldContext{WorktreePath: dir, Branch: "feature"}
+	assert.Equal(t, "Installing git hooks from .githooks", step.Description())
+	require.True(t, step.Condition(ctx))
+
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	output, err := exec.Command("git", "-C", dir, "config", "core.hooksPath").Output()
+	require.NoError(t, err)
+	installed := strings.TrimSpace(string(output))
+	assert.Equal(t, filepath.Join(dir, ".git", "arbor-hooks"), installed)
+
+	prePush, err := os.ReadFile(filepath.Join(installed, "pre-push"))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho feature\n", string(prePush), "templates are rendered")
+	assert.FileExists(t, filepath.Join(installed, "pre-commit"))
+	assert.NoFileExists(t, filepath.Join(installed, "README.md"), "files not named after a hook are skipped")
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(installed, "pre-commit"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&0100, "hooks are executable")
+	}
+
+	t.Run("reinstalling drops removed hooks", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(hooksDir, "pre-commit")))
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		assert.NoFileExists(t, filepath.Join(installed, "pre-commit"))
+	})
+}
+
+func TestGitHooksStep_DirectoryWithoutHooks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	step := NewGitHooksStep(config.StepConfig{From: "hooks"})
+	ctx := &types.ScaffoldContext{WorktreePath: dir}
+
+	assert.False(t, step.Condition(ctx), "skipped when the directory is missing")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "hooks"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hooks", "lint.sh"), []byte("#!/bin/sh\n"), 0644))
+	err := step.Run(ctx, types.StepOptions{})
+	assert.ErrorContains(t, err, "no git hooks found in hooks")
+}
+
+func TestGitHooksStep_Husky(t *testing.T) {
+	dir := t.TempDir()
+	mock := arbor_exec.NewMockCommander()
+	step := NewGitHooksStepWithExecutor(config.StepConfig{Type: "husky"}, arbor_exec.NewCommandExecutor(mock))
+	ctx := &types.ScaffoldContext{WorktreePath: dir}
+
+	assert.Equal(t, "Installing husky hooks", step.Description())
+	assert.False(t, step.Condition(ctx))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".husky"), 0755))
+	assert.True(t, step.Condition(ctx))
+
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+	assert.Equal(t, "npx", mock.LastCall().Command)
+	assert.Equal(t, []string{"--no-install", "husky"}, mock.LastCall().Args)
+	assert.Equal(t, dir, mock.LastCall().Dir)
+}
+
+func TestGitHooksStep_LefthookCondition(t *testing.T) {
+	dir := t.TempDir()
+	step := NewGitHooksStep(config.StepConfig{Type: "lefthook"})
+	ctx := &types.ScaffoldContext{WorktreePath: dir}
+
+	assert.False(t, step.Condition(ctx))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".lefthook.yml"), []byte("pre-commit: {}\n"), 0644))
+	assert.True(t, step.Condition(ctx))
+}
@@ -0,0 +1,208 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// LaravelWritablePaths are the directories a Laravel app writes to at
+// runtime, relative to the worktree.
+var LaravelWritablePaths = []string{"storage", "bootstrap/cache"}
+
+// laravelRuntimeDirs are the directories Laravel expects under its writable
+// paths. Repositories usually keep them with .gitignore files, but a
+// missing one, such as storage/framework/views, fails every request.
+var laravelRuntimeDirs = []string{
+	"storage/app/public",
+	"storage/framework/cache/data",
+	"storage/framework/sessions",
+	"storage/framework/views",
+	"storage/logs",
+	"bootstrap/cache",
+}
+
+// LaravelPermissionsStep makes a Laravel app's storage and cache
+// directories writable: it creates the runtime directories that are
+// missing, makes them writable by the user and group, and, when an owner
+// is set, grants the web server user access the way the OS supports.
+type LaravelPermissionsStep struct {
+	paths    []string
+	owner    string
+	goos     string
+	root     bool
+	executor *arbor_exec.CommandExecutor
+}
+
+var _ types.ScaffoldStep = (*LaravelPermissionsStep)(nil)
+
+// NewLaravelPermissionsStep creates a laravel.permissions step with the
+// default command executor.
+func NewLaravelPermissionsStep(cfg config.StepConfig) *LaravelPermissionsStep {
+	return NewLaravelPermissionsStepWithExecutor(cfg, nil)
+}
+
+// NewLaravelPermissionsStepWithExecutor creates a laravel.permissions step
+// with a custom command executor. This is useful for testing with mock
+// executors.
+func NewLaravelPermissionsStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *LaravelPermissionsStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	paths := cfg.Paths
+	if len(paths) == 0 {
+		paths = LaravelWritablePaths
+	}
+	return &LaravelPermissionsStep{
+		paths:    paths,
+		owner:    cfg.Owner,
+		goos:     runtime.GOOS,
+		root:     os.Geteuid() == 0,
+		executor: executor,
+	}
+}
+
+func (s *LaravelPermissionsStep) Name() string {
+	return "laravel.permissions"
+}
+
+func (s *LaravelPermissionsStep) Condition(ctx *types.ScaffoldContext) bool {
+	return true
+}
+
+func (s *LaravelPermissionsStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	for _, dir := range s.runtimeDirs() {
+		if err := os.MkdirAll(filepath.Join(ctx.WorktreePath, dir), 0775); err != nil {
+			return fmt.Errorf("creating %s: %w", dir, err)
+		}
+	}
+
+	for _, path := range s.paths {
+		// Windows has no group or other bits; its ACLs are set below.
+		if s.goos != "windows" {
+			if err := makeGroupWritable(filepath.Join(ctx.WorktreePath, path)); err != nil {
+				return fmt.Errorf("making %s writable: %w", path, err)
+			}
+		}
+		if s.owner == "" {
+			continue
+		}
+		binary, args := s.grantCommand(path)
+		if opts.Verbose {
+			fmt.Printf("  Running: %s %s\n", binary, strings.Join(args, " "))
+		}
+		output, err := sandboxed(ctx, s.executor).RunBinary(context.Background(), ctx.WorktreePath, binary, args)
+		if err != nil {
+			return fmt.Errorf("granting %s access to %s: %w\n%s", s.owner, path, err, string(output))
+		}
+	}
+
+	if problems := LaravelPermissionProblems(ctx.WorktreePath, s.paths); len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// runtimeDirs returns the runtime directories under the step's paths.
+func (s *LaravelPermissionsStep) runtimeDirs() []string {
+	dirs := append([]string(nil), s.paths...)
+	for _, dir := range laravelRuntimeDirs {
+		for _, path := range s.paths {
+			if dir != path && strings.HasPrefix(dir, path+"/") {
+				dirs = append(dirs, dir)
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// grantCommand returns the command giving the owner read and write access
+// to path and everything created in it later: an inheritable ACL where the
+// OS has them, or chown when arbor runs as root, as in a container.
+func (s *LaravelPermissionsStep) grantCommand(path string) (string, []string) {
+	switch {
+	case s.goos == "windows":
+		return "icacls", []string{filepath.FromSlash(path), "/grant", s.owner + ":(OI)(CI)M", "/T", "/Q"}
+	case s.root:
+		return "chown", []string{"-R", s.owner, path}
+	case s.goos == "darwin":
+		return "chmod", []string{"-R", "+a", s.owner + " allow delete,write,append,file_inherit,directory_inherit", path}
+	default:
+		return "setfacl", []string{"-R", "-m", "u:" + s.owner + ":rwX", "-m", "d:u:" + s.owner + ":rwX", path}
+	}
+}
+
+// makeGroupWritable gives the user and group read and write access to the
+// files under root, and lets them enter its directories.
+func makeGroupWritable(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		want := info.Mode().Perm() | 0660
+		if d.IsDir() {
+			want |= 0110
+		}
+		if want == info.Mode().Perm() {
+			return nil
+		}
+		return os.Chmod(path, want)
+	})
+}
+
+// LaravelPermissionProblems returns what keeps the current user from
+// writing to the given paths of the worktree and the Laravel runtime
+// directories under them: directories that are missing or not writable.
+func LaravelPermissionProblems(worktreePath string, paths []string) []string {
+	step := &LaravelPermissionsStep{paths: paths}
+	var problems []string
+	var missing []string
+	for _, dir := range step.runtimeDirs() {
+		// A missing path's runtime directories are missing too.
+		if slices.ContainsFunc(missing, func(path string) bool { return strings.HasPrefix(dir, path+"/") }) {
+			continue
+		}
+		full := filepath.Join(worktreePath, dir)
+		info, err := os.Stat(full)
+		switch {
+		case err != nil:
+			missing = append(missing, dir)
+			problems = append(problems, fmt.Sprintf("%s is missing", dir))
+		case !info.IsDir():
+			problems = append(problems, fmt.Sprintf("%s is not a directory", dir))
+		case !canWriteDir(full):
+			problems = append(problems, fmt.Sprintf("%s is not writable", dir))
+		}
+	}
+	return problems
+}
+
+// canWriteDir reports whether a file can be created in dir, which, unlike
+// the mode bits, accounts for ACLs and the user arbor runs as.
+func canWriteDir(dir string) bool {
+	f, err := os.CreateTemp(dir, ".arbor-write-test-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+	return true
+}
@@ -0,0 +1,108 @@
+package steps
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestLaravelPermissionsStep(t *testing.T) {
+	t.Run("creates the runtime directories and makes them writable", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "storage", "logs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "storage", "logs", "laravel.log"), nil, 0600))
+
+		mock := arbor_exec.NewMockCommander()
+		step := NewLaravelPermissionsStepWithExecutor(config.StepConfig{}, arbor_exec.NewCommandExecutor(mock))
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: dir}, types.StepOptions{}))
+
+		for _, path := range laravelRuntimeDirs {
+			assert.DirExists(t, filepath.Join(dir, path))
+		}
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(filepath.Join(dir, "storage", "logs"))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0775), info.Mode().Perm())
+			info, err = os.Stat(filepath.Join(dir, "storage", "logs", "laravel.log"))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+		}
+		assert.Zero(t, mock.CallCount(), "no owner, nothing to grant")
+	})
+
+	t.Run("grants the owner access", func(t *testing.T) {
+		dir := t.TempDir()
+		mock := arbor_exec.NewMockCommander()
+		step := NewLaravelPermissionsStepWithExecutor(config.StepConfig{Owner: "www-data"}, arbor_exec.NewCommandExecutor(mock))
+		step.goos, step.root = "linux", false
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: dir}, types.StepOptions{}))
+
+		require.Equal(t, 2, mock.CallCount())
+		assert.Equal(t, "setfacl", mock.GetCall(0).Command)
+		assert.Equal(t, []string{"-R", "-m", "u:www-data:rwX", "-m", "d:u:www-data:rwX", "storage"}, mock.GetCall(0).Args)
+		assert.Equal(t, dir, mock.GetCall(0).Dir)
+		assert.Equal(t, "bootstrap/cache", mock.GetCall(1).Args[len(mock.GetCall(1).Args)-1])
+	})
+
+	t.Run("reports a failed grant", func(t *testing.T) {
+		mock := arbor_exec.NewMockCommander()
+		step := NewLaravelPermissionsStepWithExecutor(config.StepConfig{Owner: "www-data", Paths: []string{"storage"}}, arbor_exec.NewCommandExecutor(mock))
+		step.goos, step.root = "linux", false
+		binary, args := step.grantCommand("storage")
+		mock.SetResponse(binary, args, []byte("setfacl: Operation not supported"), errors.New("exit status 1"))
+
+		err := step.Run(&types.ScaffoldContext{WorktreePath: t.TempDir()}, types.StepOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "granting www-data access to storage")
+		assert.Contains(t, err.Error(), "Operation not supported")
+	})
+}
+
+func TestLaravelPermissionsStep_GrantCommand(t *testing.T) {
+	tests := []struct {
+		goos   string
+		root   bool
+		binary string
+		args   []string
+	}{
+		{"linux", false, "setfacl", []string{"-R", "-m", "u:www-data:rwX", "-m", "d:u:www-data:rwX", "storage"}},
+		{"linux", true, "chown", []string{"-R", "www-data", "storage"}},
+		{"darwin", false, "chmod", []string{"-R", "+a", "www-data allow delete,write,append,file_inherit,directory_inherit", "storage"}},
+		{"windows", false, "icacls", []string{"storage", "/grant", "www-data:(OI)(CI)M", "/T", "/Q"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.binary, func(t *testing.T) {
+			step := &LaravelPermissionsStep{owner: "www-data", goos: tt.goos, root: tt.root}
+			binary, args := step.grantCommand("storage")
+			assert.Equal(t, tt.binary, binary)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestLaravelPermissionProblems(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "storage", "logs"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bootstrap"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bootstrap", "cache"), nil, 0644))
+
+	problems := LaravelPermissionProblems(dir, LaravelWritablePaths)
+
+	assert.Contains(t, problems, "bootstrap/cache is not a directory")
+	assert.Contains(t, problems, "storage/framework/views is missing")
+	assert.NotContains(t, problems, "storage is missing")
+	assert.NotContains(t, problems, "storage/logs is missing")
+
+	assert.Equal(t, []string{"storage is missing", "bootstrap/cache is missing"}, LaravelPermissionProblems(t.TempDir(), LaravelWritablePaths),
+		"the runtime directories of a missing path are not listed")
+}
@@ -0,0 +1,137 @@
+package steps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// Defaults for mail.catcher: Mailpit's and MailHog's SMTP port on this
+// machine, and a tag naming the worktree's site.
+const (
+	DefaultMailCatcherHost = "127.0.0.1"
+	DefaultMailCatcherPort = 1025
+	DefaultMailCatcherTag  = "{{ .SiteName }}"
+)
+
+// MailCatcherStep points a worktree's MAIL_* settings at a mail catcher
+// shared by all worktrees, such as Mailpit or MailHog, and labels the mail
+// it sends with the worktree's tag: as the SMTP username, which Mailpit
+// started with --tags-username turns into a tag, and as a prefix of the
+// sender name, which any catcher shows.
+type MailCatcherStep struct {
+	host string
+	port int
+	tag  string
+	file string
+}
+
+var _ types.ScaffoldStep = (*MailCatcherStep)(nil)
+
+func NewMailCatcherStep(cfg config.StepConfig) *MailCatcherStep {
+	step := &MailCatcherStep{
+		host: cfg.Host,
+		port: cfg.Port,
+		tag:  cfg.Tag,
+		file: cfg.File,
+	}
+	if step.host == "" {
+		step.host = DefaultMailCatcherHost
+	}
+	if step.port == 0 {
+		step.port = DefaultMailCatcherPort
+	}
+	if step.tag == "" {
+		step.tag = DefaultMailCatcherTag
+	}
+	return step
+}
+
+func (s *MailCatcherStep) Name() string {
+	return "mail.catcher"
+}
+
+func (s *MailCatcherStep) Condition(ctx *types.ScaffoldContext) bool {
+	return true
+}
+
+func (s *MailCatcherStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	file := s.file
+	if file == "" {
+		file = ctx.PrimaryEnvFile()
+	}
+
+	tag, err := template.ReplaceTemplateVars(s.tag, ctx)
+	if err != nil {
+		return fmt.Errorf("template replacement failed: %w", err)
+	}
+
+	filePath := filepath.Join(ctx.WorktreePath, file)
+
+	lock := getFileLock(filePath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var oldPerms os.FileMode = 0644
+	var content []byte
+	if info, err := os.Stat(filePath); err == nil {
+		oldPerms = info.Mode().Perm()
+		content, err = os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+	}
+	env := utils.ParseEnv(content)
+
+	values := []struct{ key, value string }{
+		{"MAIL_MAILER", "smtp"},
+		{"MAIL_HOST", s.host},
+		{"MAIL_PORT", strconv.Itoa(s.port)},
+		{"MAIL_USERNAME", tag},
+		{"MAIL_PASSWORD", tag},
+		{"MAIL_FROM_NAME", tagSenderName(env["MAIL_FROM_NAME"], tag)},
+	}
+	// Catchers speak plain SMTP; Laravel 11 names the setting MAIL_SCHEME.
+	for _, key := range []string{"MAIL_ENCRYPTION", "MAIL_SCHEME"} {
+		if utils.EnvExists(env, key) {
+			values = append(values, struct{ key, value string }{key, "null"})
+		}
+	}
+	for _, v := range values {
+		content = utils.SetEnvValue(content, v.key, v.value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("creating parent directory: %w", err)
+	}
+
+	if err := writeEnvFile(ctx, filePath, content, oldPerms); err != nil {
+		return err
+	}
+
+	if opts.Verbose {
+		fmt.Printf("  Pointed mail at %s:%d, tagged %s, in %s\n", s.host, s.port, tag, file)
+	}
+
+	return nil
+}
+
+// tagSenderName prefixes the sender name with the tag, once; an empty name
+// becomes the app's name.
+func tagSenderName(name, tag string) string {
+	prefix := "[" + tag + "] "
+	if strings.HasPrefix(name, prefix) {
+		return name
+	}
+	if name == "" {
+		name = "${APP_NAME}"
+	}
+	return prefix + name
+}
@@ -0,0 +1,76 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func TestMailCatcherStep(t *testing.T) {
+	t.Run("points mail at the catcher and tags it", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		envFile := filepath.Join(tmpDir, ".env")
+		require.NoError(t, os.WriteFile(envFile, []byte(`APP_NAME=Shop
+MAIL_MAILER=log
+MAIL_HOST=mailpit
+MAIL_PORT=2525
+MAIL_USERNAME=null
+MAIL_PASSWORD=null
+MAIL_ENCRYPTION=tls
+MAIL_FROM_ADDRESS="hello@example.com"
+MAIL_FROM_NAME="${APP_NAME}"
+`), 0600))
+
+		step := NewMailCatcherStep(config.StepConfig{})
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, SiteName: "feature-x"}
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+		content, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, `APP_NAME=Shop
+MAIL_MAILER=smtp
+MAIL_HOST=127.0.0.1
+MAIL_PORT=1025
+MAIL_USERNAME=feature-x
+MAIL_PASSWORD=feature-x
+MAIL_ENCRYPTION=null
+MAIL_FROM_ADDRESS="hello@example.com"
+MAIL_FROM_NAME="[feature-x] ${APP_NAME}"
+`, string(content))
+
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+		again, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(content), string(again), "running again changes nothing")
+
+		info, err := os.Stat(envFile)
+		require.NoError(t, err)
+		if os.PathSeparator == '/' {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("uses the configured server and tag", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		step := NewMailCatcherStep(config.StepConfig{Host: "mailhog.test", Port: 2025, Tag: "{{ .Branch }}", File: ".env.local"})
+		ctx := &types.ScaffoldContext{WorktreePath: tmpDir, Branch: "feature/login"}
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, ".env.local"))
+		require.NoError(t, err)
+		assert.Equal(t, `MAIL_MAILER=smtp
+MAIL_HOST=mailhog.test
+MAIL_PORT=2025
+MAIL_USERNAME=feature/login
+MAIL_PASSWORD=feature/login
+MAIL_FROM_NAME="[feature/login] ${APP_NAME}"
+`, string(content))
+	})
+}
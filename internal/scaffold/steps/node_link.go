@@ -0,0 +1,263 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/template"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// NodeLinkStep links local checkouts of node packages, such as a package
+// being developed in another worktree, into the worktree's node_modules
+// with npm link or yarn link. Packages maps each package name to its
+// directory.
+type NodeLinkStep struct {
+	manager  string
+	packages map[string]string
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep = (*NodeLinkStep)(nil)
+	_ types.Describer    = (*NodeLinkStep)(nil)
+)
+
+// NewNodeLinkStep creates a node.link step with the default command executor.
+func NewNodeLinkStep(cfg config.StepConfig) *NodeLinkStep {
+	return NewNodeLinkStepWithExecutor(cfg, nil)
+}
+
+// NewNodeLinkStepWithExecutor creates a node.link step with a custom command
+// executor. This is useful for testing with mock executors.
+func NewNodeLinkStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *NodeLinkStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	return &NodeLinkStep{manager: cfg.Type, packages: cfg.Packages, executor: executor}
+}
+
+func (s *NodeLinkStep) Name() string {
+	return "node.link"
+}
+
+// Description counts the packages, such as "Linking 2 node packages".
+func (s *NodeLinkStep) Description() string {
+	if len(s.packages) == 1 {
+		return "Linking 1 node package"
+	}
+	return fmt.Sprintf("Linking %d node packages", len(s.packages))
+}
+
+func (s *NodeLinkStep) Condition(ctx *types.ScaffoldContext) bool {
+	_, err := os.Stat(filepath.Join(ctx.WorktreePath, "package.json"))
+	return err == nil
+}
+
+func (s *NodeLinkStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	names := sortedKeys(s.packages)
+	paths := make([]string, len(names))
+	for i, name := range names {
+		path, err := template.ReplaceTemplateVars(s.packages[name], ctx)
+		if err != nil {
+			return fmt.Errorf("template replacement failed: %w", err)
+		}
+		// Linking a directory that holds a different package would
+		// replace the wrong entry in node_modules.
+		declared, err := nodePackageName(resolvePath(ctx.WorktreePath, path))
+		if err != nil {
+			return fmt.Errorf("node.link: %w", err)
+		}
+		if declared != name {
+			return fmt.Errorf("node.link: %s holds package %q, not %q", path, declared, name)
+		}
+		paths[i] = path
+	}
+
+	executor := sandboxed(ctx, s.executor)
+	run := func(dir, binary string, args ...string) error {
+		output, err := executor.RunBinary(context.Background(), dir, binary, args)
+		if err != nil {
+			return fmt.Errorf("node.link: %s %v failed: %w\n%s", binary, args, err, string(output))
+		}
+		return nil
+	}
+
+	switch nodeLinkManager(ctx.WorktreePath, s.manager) {
+	case config.NodeLinkNpm:
+		// npm link replaces the links it made before, so every package is
+		// linked in one call.
+		if err := run(ctx.WorktreePath, "npm", append([]string{"link"}, paths...)...); err != nil {
+			return err
+		}
+	case nodeLinkYarnBerry:
+		if err := run(ctx.WorktreePath, "yarn", append([]string{"link"}, paths...)...); err != nil {
+			return err
+		}
+	default:
+		// Yarn classic links by name, once the package directory has
+		// registered itself.
+		for i, name := range names {
+			if err := run(resolvePath(ctx.WorktreePath, paths[i]), "yarn", "link"); err != nil {
+				return err
+			}
+			if err := run(ctx.WorktreePath, "yarn", "link", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Verbose {
+		for i, name := range names {
+			fmt.Printf("  Linked %s from %s\n", name, paths[i])
+		}
+	}
+	return nil
+}
+
+// NodeUnlinkStep removes the links node.link made, for example in
+// cleanup.steps. Run the package manager's install afterwards to get the
+// released versions back. It does nothing when none of the packages is
+// linked.
+type NodeUnlinkStep struct {
+	manager  string
+	packages map[string]string
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep = (*NodeUnlinkStep)(nil)
+	_ types.Describer    = (*NodeUnlinkStep)(nil)
+)
+
+// NewNodeUnlinkStep creates a node.unlink step with the default command executor.
+func NewNodeUnlinkStep(cfg config.StepConfig) *NodeUnlinkStep {
+	return NewNodeUnlinkStepWithExecutor(cfg, nil)
+}
+
+// NewNodeUnlinkStepWithExecutor creates a node.unlink step with a custom
+// command executor. This is useful for testing with mock executors.
+func NewNodeUnlinkStepWithExecutor(cfg config.StepConfig, executor *arbor_exec.CommandExecutor) *NodeUnlinkStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	return &NodeUnlinkStep{manager: cfg.Type, packages: cfg.Packages, executor: executor}
+}
+
+func (s *NodeUnlinkStep) Name() string {
+	return "node.unlink"
+}
+
+func (s *NodeUnlinkStep) Description() string {
+	return "Unlinking node packages"
+}
+
+func (s *NodeUnlinkStep) Condition(ctx *types.ScaffoldContext) bool {
+	return len(s.linked(ctx.WorktreePath)) > 0
+}
+
+// linked returns the packages whose node_modules entry is a symlink, in
+// name order.
+func (s *NodeUnlinkStep) linked(worktree string) []string {
+	var names []string
+	for _, name := range sortedKeys(s.packages) {
+		info, err := os.Lstat(filepath.Join(worktree, "node_modules", filepath.FromSlash(name)))
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (s *NodeUnlinkStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	names := s.linked(ctx.WorktreePath)
+	if len(names) == 0 {
+		return nil
+	}
+
+	executor := sandboxed(ctx, s.executor)
+	run := func(binary string, args ...string) error {
+		output, err := executor.RunBinary(context.Background(), ctx.WorktreePath, binary, args)
+		if err != nil {
+			return fmt.Errorf("node.unlink: %s %v failed: %w\n%s", binary, args, err, string(output))
+		}
+		return nil
+	}
+
+	switch nodeLinkManager(ctx.WorktreePath, s.manager) {
+	case config.NodeLinkNpm:
+		if err := run("npm", append([]string{"unlink", "--no-save"}, names...)...); err != nil {
+			return err
+		}
+	case nodeLinkYarnBerry:
+		if err := run("yarn", append([]string{"unlink"}, names...)...); err != nil {
+			return err
+		}
+	default:
+		for _, name := range names {
+			if err := run("yarn", "unlink", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Verbose {
+		for _, name := range names {
+			fmt.Printf("  Unlinked %s\n", name)
+		}
+	}
+	return nil
+}
+
+// nodeLinkYarnBerry is the manager nodeLinkManager returns for Yarn 2 and
+// later, whose link commands take paths instead of registered names.
+const nodeLinkYarnBerry = "yarn-berry"
+
+// nodeLinkManager returns the package manager to link with: the configured
+// one, or yarn when the worktree has a yarn.lock and npm otherwise. Yarn
+// is reported as nodeLinkYarnBerry when the worktree has a .yarnrc.yml.
+func nodeLinkManager(worktree, configured string) string {
+	manager := configured
+	if manager == "" {
+		manager = config.NodeLinkNpm
+		if _, err := os.Stat(filepath.Join(worktree, "yarn.lock")); err == nil {
+			manager = config.NodeLinkYarn
+		}
+	}
+	if manager == config.NodeLinkYarn {
+		if _, err := os.Stat(filepath.Join(worktree, ".yarnrc.yml")); err == nil {
+			return nodeLinkYarnBerry
+		}
+	}
+	return manager
+}
+
+// nodePackageName returns the name declared in the package.json in dir.
+func nodePackageName(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", fmt.Errorf("reading package.json: %w", err)
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", filepath.Join(dir, "package.json"), err)
+	}
+	return manifest.Name, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+func writePackageJSON(t *testing.T, dir, name string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "`+name+`"}`), 0644))
+}
+
+func TestNodeLinkStep(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writePackageJSON(t, app, "app")
+	writePackageJSON(t, filepath.Join(root, "ui"), "@acme/ui")
+	writePackageJSON(t, filepath.Join(root, "icons"), "@acme/icons")
+	packages := map[string]string{"@acme/ui": "../ui", "@acme/icons": "../icons"}
+
+	t.Run("npm links every package in one call", func(t *testing.T) {
+		mock := arbor_exec.NewMockCommander()
+		step := NewNodeLinkStepWithExecutor(config.StepConfig{Packages: packages}, arbor_exec.NewCommandExecutor(mock))
+		ctx := &types.ScaffoldContext{WorktreePath: app}
+
+		assert.Equal(t, "Linking 2 node packages", step.Description())
+		require.True(t, step.Condition(ctx))
+		require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+		require.Equal(t, 1, mock.CallCount())
+		assert.Equal(t, "npm", mock.LastCall().Command)
+		assert.Equal(t, []string{"link", "../icons", "../ui"}, mock.LastCall().Args)
+	})
+
+	t.Run("yarn classic registers each package first", func(t *testing.T) {
+		mock := arbor_exec.NewMockCommander()
+		step := NewNodeLinkStepWithExecutor(config.StepConfig{Type: "yarn", Packages: map[string]string{"@acme/ui": "../ui"}}, arbor_exec.NewCommandExecutor(mock))
+
+		require.NoError(t, step.Run(&types.ScaffoldContext{WorktreePath: app}, types.StepOptions{}))
+
+		require.Equal(t, 2, mock.CallCount())
+		assert.Equal(t, filepath.Join(root, "ui"), mock.GetCall(0).Dir)
+		assert.Equal(t, []string{"link"}, mock.GetCall(0).Args)
+		assert.Equal(t, app, mock.GetCall(1).Dir)
+		assert.Equal(t, []string{"link", "@acme/ui"}, mock.GetCall(1).Args)
+	})
+
+	t.Run("rejects a directory holding another package", func(t *testing.T) {
+		mock := arbor_exec.NewMockCommander()
+		step := NewNodeLinkStepWithExecutor(config.StepConfig{Packages: map[string]string{"@acme/ui": "../icons"}}, arbor_exec.NewCommandExecutor(mock))
+
+		err := step.Run(&types.ScaffoldContext{WorktreePath: app}, types.StepOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `holds package "@acme/icons", not "@acme/ui"`)
+		assert.Zero(t, mock.CallCount())
+	})
+}
+
+func TestNodeUnlinkStep(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writePackageJSON(t, app, "app")
+	writePackageJSON(t, filepath.Join(root, "ui"), "@acme/ui")
+	require.NoError(t, os.WriteFile(filepath.Join(app, "yarn.lock"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(app, ".yarnrc.yml"), nil, 0644))
+
+	mock := arbor_exec.NewMockCommander()
+	step := NewNodeUnlinkStepWithExecutor(config.StepConfig{
+		Packages: map[string]string{"@acme/ui": "../ui", "@acme/icons": "../icons"},
+	}, arbor_exec.NewCommandExecutor(mock))
+	ctx := &types.ScaffoldContext{WorktreePath: app}
+
+	assert.False(t, step.Condition(ctx), "skipped when nothing is linked")
+
+	scope := filepath.Join(app, "node_modules", "@acme")
+	require.NoError(t, os.MkdirAll(scope, 0755))
+	if err := os.Symlink(filepath.Join(root, "ui"), filepath.Join(scope, "ui")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(scope, "icons"), 0755))
+
+	require.True(t, step.Condition(ctx))
+	require.NoError(t, step.Run(ctx, types.StepOptions{}))
+
+	require.Equal(t, 1, mock.CallCount())
+	assert.Equal(t, "yarn", mock.LastCall().Command)
+	assert.Equal(t, []string{"unlink", "@acme/ui"}, mock.LastCall().Args, "installed packages are left alone")
+}
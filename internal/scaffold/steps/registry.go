@@ -1,22 +1,39 @@
 package steps
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
 	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/lock"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
 	"github.com/artisanexperiences/arbor/internal/scaffold/validation"
 )
 
 type StepFactory func(cfg config.StepConfig) types.ScaffoldStep
 
+// StepInfo documents a registered step for 'arbor step list', the
+// arbor.yaml schema and progress output.
+type StepInfo struct {
+	Name string `json:"name"`
+	// Description says what the step does the way progress output shows
+	// it while the step runs, such as "Creating database".
+	Description string `json:"description"`
+	// RequiredFields are the config keys the step needs besides name.
+	// When empty, they are taken from the step's validator.
+	RequiredFields []string `json:"requiredFields,omitempty"`
+	// Examples are arbor.yaml step entries using the step.
+	Examples []string `json:"examples,omitempty"`
+}
+
 // Registry provides explicit step registration and creation.
 // Use NewRegistry() to create an instance, or use the global functions
 // for backward compatibility during migration.
 type Registry struct {
 	factories  map[string]StepFactory
 	validators map[string]*validation.Validator
+	infos      map[string]StepInfo
 	order      []string
 }
 
@@ -25,6 +42,7 @@ func NewRegistry() *Registry {
 	return &Registry{
 		factories:  make(map[string]StepFactory),
 		validators: make(map[string]*validation.Validator),
+		infos:      make(map[string]StepInfo),
 		order:      make([]string, 0),
 	}
 }
@@ -39,37 +57,83 @@ func (r *Registry) Register(name string, factory StepFactory) {
 // The validator will be used to validate configuration before creating the step.
 // Panics if a step with the same name is already registered.
 func (r *Registry) RegisterWithValidator(name string, factory StepFactory, validator *validation.Validator) {
+	r.RegisterWithInfo(StepInfo{Name: name}, factory, validator)
+}
+
+// RegisterWithInfo adds a step factory, documented by info, with an
+// optional validator to the registry.
+// Panics if a step with the same name is already registered.
+func (r *Registry) RegisterWithInfo(info StepInfo, factory StepFactory, validator *validation.Validator) {
+	name := info.Name
 	if _, exists := r.factories[name]; exists {
 		panic(fmt.Sprintf("step %q already registered", name))
 	}
 	r.factories[name] = factory
 	if validator != nil {
 		r.validators[name] = validator
+		if len(info.RequiredFields) == 0 {
+			info.RequiredFields = validator.RequiredFields()
+		}
 	}
+	r.infos[name] = info
 	r.order = append(r.order, name)
 }
 
+// Info returns the documentation of a registered step.
+func (r *Registry) Info(name string) (StepInfo, bool) {
+	info, ok := r.infos[name]
+	return info, ok
+}
+
+// Infos returns the documentation of all registered steps, sorted by name.
+func (r *Registry) Infos() []StepInfo {
+	names := r.ListRegistered()
+	infos := make([]StepInfo, len(names))
+	for i, name := range names {
+		infos[i] = r.infos[name]
+	}
+	return infos
+}
+
 // Create instantiates a step by name with the given configuration.
 // Validates the configuration before creating the step using registered validators.
 // Falls back to built-in validation if no validator is registered.
 // Returns an error if the step is not registered or config is invalid.
 func (r *Registry) Create(name string, cfg config.StepConfig) (types.ScaffoldStep, error) {
-	// Use registered validator if available
-	if validator, ok := r.validators[name]; ok && validator != nil {
-		if err := validator.Validate(cfg); err != nil {
-			return nil, err
-		}
-	} else {
-		// Fall back to built-in validation
-		if err := config.ValidateStepConfig(name, cfg); err != nil {
-			return nil, fmt.Errorf("invalid config for step %q: %w", name, err)
-		}
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, r.unknownStepError(name)
+	}
+	if err := r.Validate(name, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config for step %q: %w", name, err)
+	}
+	return factory(cfg), nil
+}
+
+// Validate checks a step config without creating the step: the lock name,
+// then the step's registered validator, or the built-in validation when it
+// has none. Every problem is returned, joined, without naming the step.
+func (r *Registry) Validate(name string, cfg config.StepConfig) error {
+	if _, ok := r.factories[name]; !ok {
+		return r.unknownStepError(name)
 	}
 
-	if factory, ok := r.factories[name]; ok {
-		return factory(cfg), nil
+	var errs []error
+	if cfg.Lock != "" {
+		if err := lock.ValidateName(cfg.Lock); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if validator, ok := r.validators[name]; ok && validator != nil {
+		errs = append(errs, validator.Problems(cfg)...)
+	} else if err := config.ValidateStepConfig(name, cfg); err != nil {
+		errs = append(errs, err)
 	}
-	return nil, fmt.Errorf("unknown step %q (available: %v)", name, r.ListRegistered())
+	return errors.Join(errs...)
+}
+
+func (r *Registry) unknownStepError(name string) error {
+	return fmt.Errorf("unknown step %q (available: %v)", name, r.ListRegistered())
 }
 
 // ListRegistered returns a sorted list of all registered step names.
@@ -86,43 +150,180 @@ func (r *Registry) RegisterDefaults() {
 	for _, b := range binaries {
 		name := b.name
 		binary := b.binary
-		r.Register(name, func(cfg config.StepConfig) types.ScaffoldStep {
+		r.RegisterWithInfo(StepInfo{Name: name, Description: b.description, Examples: b.examples}, func(cfg config.StepConfig) types.ScaffoldStep {
 			return NewBinaryStepWithCondition(name, cfg, binary)
-		})
+		}, nil)
 	}
 
 	// Other steps with validators
-	r.RegisterWithValidator("file.copy", func(cfg config.StepConfig) types.ScaffoldStep {
-		return NewFileCopyStep(cfg.From, cfg.To)
+	r.RegisterWithInfo(StepInfo{
+		Name:        "file.copy",
+		Description: "Copying files",
+		Examples:    []string{"name: file.copy\nfrom: .env.example\nto: .env", "name: file.copy\nfrom: stubs/run.cmd\nto: run.cmd\neol: crlf"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewFileCopyStepFromConfig(cfg)
 	}, validation.NewFileCopyValidator())
 
-	r.RegisterWithValidator("bash.run", func(cfg config.StepConfig) types.ScaffoldStep {
-		return NewBashRunStep(cfg.Command, cfg.StoreAs)
+	r.RegisterWithInfo(StepInfo{
+		Name:        "bash.run",
+		Description: "Running bash command",
+		Examples:    []string{"name: bash.run\ncommand: echo \"{{ .SiteName }}\" > site.txt", "name: bash.run\nshell: pwsh\ncommand: Copy-Item .env.example .env"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewBashRunStepFromConfig(cfg)
 	}, validation.NewBashRunValidator())
 
-	r.RegisterWithValidator("command.run", func(cfg config.StepConfig) types.ScaffoldStep {
+	r.RegisterWithInfo(StepInfo{
+		Name:        "command.run",
+		Description: "Running command",
+		Examples:    []string{"name: command.run\ncommand: git rev-parse --short HEAD\nstore_as: Commit"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewCommandRunStep(cfg.Command, cfg.StoreAs)
 	}, validation.NewCommandRunValidator())
 
-	r.RegisterWithValidator("env.read", func(cfg config.StepConfig) types.ScaffoldStep {
+	r.RegisterWithInfo(StepInfo{
+		Name:        "git.config",
+		Description: "Setting git config",
+		Examples: []string{
+			"name: git.config\nkey: user.email\nvalue: me@work.example",
+			"name: git.config\nkey: core.hooksPath\nvalue: .githooks",
+		},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewGitConfigStep(cfg)
+	}, validation.NewGitConfigValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "git.hooks",
+		Description: "Installing git hooks",
+		Examples: []string{
+			"name: git.hooks\nfrom: .githooks",
+			"name: git.hooks\ntype: husky",
+		},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewGitHooksStep(cfg)
+	}, validation.NewGitHooksValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:           "composer.link",
+		Description:    "Linking composer packages",
+		Examples:       []string{"name: composer.link\npaths: [../billing]"},
+		RequiredFields: []string{"paths"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewComposerLinkStep(cfg)
+	}, validation.NewComposerLinkValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "composer.unlink",
+		Description: "Unlinking composer packages",
+		Examples:    []string{"name: composer.unlink"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewComposerUnlinkStep(cfg)
+	}, nil)
+
+	r.RegisterWithInfo(StepInfo{
+		Name:           "node.link",
+		Description:    "Linking node packages",
+		Examples:       []string{"name: node.link\npackages:\n  \"@acme/ui\": ../ui"},
+		RequiredFields: []string{"packages"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewNodeLinkStep(cfg)
+	}, validation.NewNodeLinkValidator("node.link"))
+
+	r.RegisterWithInfo(StepInfo{
+		Name:           "node.unlink",
+		Description:    "Unlinking node packages",
+		Examples:       []string{"name: node.unlink\npackages:\n  \"@acme/ui\": ../ui"},
+		RequiredFields: []string{"packages"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewNodeUnlinkStep(cfg)
+	}, validation.NewNodeLinkValidator("node.unlink"))
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "laravel.permissions",
+		Description: "Fixing storage permissions",
+		Examples:    []string{"name: laravel.permissions", "name: laravel.permissions\nowner: www-data"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewLaravelPermissionsStep(cfg)
+	}, validation.NewLaravelPermissionsValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "mail.catcher",
+		Description: "Pointing mail at the mail catcher",
+		Examples:    []string{"name: mail.catcher", "name: mail.catcher\nhost: 127.0.0.1\nport: 1025\ntag: \"{{ .SiteName }}\""},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewMailCatcherStep(cfg)
+	}, validation.NewMailCatcherValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "scheduler.register",
+		Description: "Registering the schedule runner",
+		Examples:    []string{"name: scheduler.register", "name: scheduler.register\ntype: cron"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewSchedulerRegisterStep(cfg)
+	}, validation.NewSchedulerValidator("scheduler.register"))
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "scheduler.unregister",
+		Description: "Removing the schedule runner",
+		Examples:    []string{"name: scheduler.unregister"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewSchedulerUnregisterStep(cfg)
+	}, validation.NewSchedulerValidator("scheduler.unregister"))
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "env.read",
+		Description: "Reading environment variables",
+		Examples:    []string{"name: env.read\nkey: APP_URL\nstore_as: AppUrl"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewEnvReadStep(cfg)
 	}, validation.NewEnvReadValidator())
 
-	r.RegisterWithValidator("env.write", func(cfg config.StepConfig) types.ScaffoldStep {
+	r.RegisterWithInfo(StepInfo{
+		Name:        "env.write",
+		Description: "Writing environment variables",
+		Examples:    []string{"name: env.write\nkey: DB_DATABASE\nvalue: \"{{ .DbName }}\""},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewEnvWriteStep(cfg)
 	}, validation.NewEnvWriteValidator())
 
-	r.RegisterWithValidator("env.copy", func(cfg config.StepConfig) types.ScaffoldStep {
+	r.RegisterWithInfo(StepInfo{
+		Name:        "env.copy",
+		Description: "Copying environment variables",
+		Examples:    []string{"name: env.copy\nsource: ../main\nkeys: [STRIPE_KEY, STRIPE_SECRET]"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewEnvCopyStep(cfg)
 	}, validation.NewEnvCopyValidator())
 
+	r.RegisterWithInfo(StepInfo{
+		Name:        "env.copy_from_main",
+		Description: "Copying environment variables from the main worktree",
+		Examples:    []string{"name: env.copy_from_main\nkeys: [APP_KEY]"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewEnvCopyFromMainStep(cfg)
+	}, validation.NewEnvCopyFromMainValidator())
+
+	r.RegisterWithInfo(StepInfo{
+		Name:        "env.unset",
+		Description: "Removing environment variables",
+		Examples:    []string{"name: env.unset\nkeys: [TELESCOPE_ENABLED]"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
+		return NewEnvUnsetStep(cfg)
+	}, validation.NewEnvUnsetValidator())
+
 	// Steps without custom validators (use built-in validation)
-	r.Register("db.create", func(cfg config.StepConfig) types.ScaffoldStep {
+	r.RegisterWithInfo(StepInfo{
+		Name:        "db.create",
+		Description: "Creating database",
+		Examples:    []string{"name: db.create\ntype: mysql"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewDbCreateStep(cfg)
-	})
-	r.Register("db.destroy", func(cfg config.StepConfig) types.ScaffoldStep {
+	}, nil)
+	r.RegisterWithInfo(StepInfo{
+		Name:        "db.destroy",
+		Description: "Destroying database",
+		Examples:    []string{"name: db.destroy\ntype: mysql"},
+	}, func(cfg config.StepConfig) types.ScaffoldStep {
 		return NewDbDestroyStep(cfg)
-	})
+	}, nil)
 }
 
 // Global registry for backward compatibility during migration.
@@ -141,26 +342,45 @@ func Create(name string, cfg config.StepConfig) (types.ScaffoldStep, error) {
 	return globalRegistry.Create(name, cfg)
 }
 
+// Validate checks a step config using the global registry.
+// Deprecated: Use Registry.Validate() instead.
+func Validate(name string, cfg config.StepConfig) error {
+	return globalRegistry.Validate(name, cfg)
+}
+
 // ListRegistered returns a sorted list of all registered steps from the global registry.
 // Deprecated: Use Registry.ListRegistered() instead.
 func ListRegistered() []string {
 	return globalRegistry.ListRegistered()
 }
 
+// BinaryFor returns the command a binary step, such as php.composer, runs
+// its args with.
+func BinaryFor(name string) (string, bool) {
+	for _, b := range binaries {
+		if b.name == name {
+			return b.binary, true
+		}
+	}
+	return "", false
+}
+
 type binaryDefinition struct {
-	name   string
-	binary string
+	name        string
+	binary      string
+	description string
+	examples    []string
 }
 
 var binaries = []binaryDefinition{
-	{"php", "php"},
-	{"php.composer", "composer"},
-	{"php.laravel", "php artisan"},
-	{"node.npm", "npm"},
-	{"node.yarn", "yarn"},
-	{"node.pnpm", "pnpm"},
-	{"node.bun", "bun"},
-	{"herd", "herd"},
+	{"php", "php", "Running php", []string{"name: php\nargs: [artisan, storage:link]"}},
+	{"php.composer", "composer", "Running composer", []string{"name: php.composer\nargs: [install]"}},
+	{"php.laravel", "php artisan", "Running artisan command", []string{"name: php.laravel\nargs: [migrate:fresh, --seed, --no-interaction]"}},
+	{"node.npm", "npm", "Running npm", []string{"name: node.npm\nargs: [ci]"}},
+	{"node.yarn", "yarn", "Running yarn", []string{"name: node.yarn\nargs: [install]"}},
+	{"node.pnpm", "pnpm", "Running pnpm", []string{"name: node.pnpm\nargs: [install]"}},
+	{"node.bun", "bun", "Running bun", []string{"name: node.bun\nargs: [install]"}},
+	{"herd", "herd", "Managing Herd", []string{"name: herd\nargs: [link, --secure, \"{{ .SiteName }}\"]"}},
 }
 
 func init() {
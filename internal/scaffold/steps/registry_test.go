@@ -3,8 +3,10 @@ package steps
 import (
 	"testing"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 	"github.com/artisanexperiences/arbor/internal/scaffold/types"
@@ -246,6 +248,52 @@ func TestExplicitRegistry_Create(t *testing.T) {
 		assert.Nil(t, step)
 		assert.Contains(t, err.Error(), "unknown step")
 	})
+
+	t.Run("rejects invalid lock name", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.RegisterDefaults()
+
+		cfg := config.StepConfig{Name: "php.composer", Args: []string{"install"}, Lock: "../cache"}
+		step, err := registry.Create("php.composer", cfg)
+
+		assert.Error(t, err)
+		assert.Nil(t, step)
+		assert.Contains(t, err.Error(), "lock name")
+	})
+}
+
+func TestExplicitRegistry_Validate(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterDefaults()
+
+	t.Run("returns every problem without naming the step", func(t *testing.T) {
+		err := registry.Validate("file.copy", config.StepConfig{Name: "file.copy", Lock: "../cache"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `required field "from" is missing`)
+		assert.Contains(t, err.Error(), `required field "to" is missing`)
+		assert.Contains(t, err.Error(), "../cache")
+		assert.NotContains(t, err.Error(), "file.copy")
+	})
+
+	t.Run("uses built-in validation for steps without a validator", func(t *testing.T) {
+		assert.Error(t, registry.Validate("db.create", config.StepConfig{Name: "db.create", Type: "pgsql", Charset: "utf8mb4"}))
+		assert.NoError(t, registry.Validate("db.create", config.StepConfig{Name: "db.create"}))
+	})
+
+	t.Run("rejects unknown steps", func(t *testing.T) {
+		err := registry.Validate("bash.runn", config.StepConfig{Name: "bash.runn"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown step "bash.runn"`)
+	})
+
+	t.Run("Create names the step", func(t *testing.T) {
+		_, err := registry.Create("bash.run", config.StepConfig{Name: "bash.run"})
+
+		require.Error(t, err)
+		assert.Equal(t, `invalid config for step "bash.run": required field "command" is missing`, err.Error())
+	})
 }
 
 func TestExplicitRegistry_RegisterDefaults(t *testing.T) {
@@ -254,26 +302,38 @@ func TestExplicitRegistry_RegisterDefaults(t *testing.T) {
 		registry.RegisterDefaults()
 
 		registered := registry.ListRegistered()
-		assert.Len(t, registered, 16) // 8 binary steps + 8 other steps
+		assert.Len(t, registered, 28) // 8 binary steps + 20 other steps
 
 		// Verify all expected steps are present
 		expectedSteps := []string{
 			"bash.run",
 			"command.run",
+			"composer.link",
+			"composer.unlink",
 			"db.create",
 			"db.destroy",
 			"env.copy",
+			"env.copy_from_main",
 			"env.read",
+			"env.unset",
 			"env.write",
 			"file.copy",
+			"git.config",
+			"git.hooks",
 			"herd",
+			"laravel.permissions",
+			"mail.catcher",
 			"node.bun",
+			"node.link",
 			"node.npm",
 			"node.pnpm",
+			"node.unlink",
 			"node.yarn",
 			"php",
 			"php.composer",
 			"php.laravel",
+			"scheduler.register",
+			"scheduler.unregister",
 		}
 
 		for _, stepName := range expectedSteps {
@@ -328,3 +388,40 @@ func TestExplicitRegistry_Isolation(t *testing.T) {
 		assert.NotNil(t, step)
 	})
 }
+
+func TestExplicitRegistry_Infos(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterDefaults()
+
+	infos := registry.Infos()
+	require.Len(t, infos, len(registry.ListRegistered()))
+	for _, info := range infos {
+		assert.NotEmpty(t, info.Description, info.Name)
+		require.NotEmpty(t, info.Examples, info.Name)
+
+		// Examples are valid entries for the step they document.
+		for _, example := range info.Examples {
+			var raw map[string]any
+			require.NoError(t, yaml.Unmarshal([]byte(example), &raw), info.Name)
+			var cfg config.StepConfig
+			require.NoError(t, mapstructure.Decode(raw, &cfg), info.Name)
+			assert.Equal(t, info.Name, cfg.Name)
+			_, err := registry.Create(cfg.Name, cfg)
+			assert.NoError(t, err, info.Name)
+		}
+	}
+
+	fileCopy, ok := registry.Info("file.copy")
+	require.True(t, ok)
+	assert.Equal(t, []string{"from", "to"}, fileCopy.RequiredFields, "taken from the validator")
+
+	_, ok = registry.Info("nonexistent")
+	assert.False(t, ok)
+}
+
+func TestBinaryStep_Description(t *testing.T) {
+	assert.Equal(t, "Running composer install", NewBinaryStep("php.composer", "composer", []string{"install"}, "").Description())
+	assert.Equal(t, "Running artisan migrate:fresh", NewBinaryStep("php.laravel", "php artisan", []string{"migrate:fresh", "--seed"}, "").Description())
+	assert.Equal(t, "Running artisan key:generate", NewBinaryStep("php.laravel", "php artisan", []string{"key:generate --show"}, "").Description())
+	assert.Empty(t, NewBinaryStep("node.npm", "npm", nil, "").Description())
+}
@@ -0,0 +1,56 @@
+package steps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+)
+
+// sandboxed returns the executor a command step runs with: executor
+// itself, or executor inside the sandbox when sandbox.enabled is set. The
+// scaffold summary warns once when the platform cannot block the network
+// or writes outside the worktree.
+func sandboxed(ctx *types.ScaffoldContext, executor *arbor_exec.CommandExecutor) *arbor_exec.CommandExecutor {
+	if !ctx.Sandbox.Enabled {
+		return executor
+	}
+
+	commander := arbor_exec.NewSandboxCommander(executor.Commander(), arbor_exec.Sandbox{
+		Root:     ctx.WorktreePath,
+		Writable: expandHomePaths(ctx.Sandbox.WritablePaths),
+		AllowEnv: ctx.Sandbox.AllowEnv,
+		Network:  ctx.Sandbox.Network,
+	})
+
+	var warning string
+	switch commander.Isolation() {
+	case arbor_exec.IsolationUnshare:
+		warning = "sandbox: bwrap not found; steps run without network but may write outside the worktree"
+	case arbor_exec.IsolationEnv:
+		warning = "sandbox: no isolation tool found; only the environment of steps is filtered"
+	}
+	if warning != "" && !slices.Contains(ctx.Warnings(), warning) {
+		ctx.AddWarning(warning)
+	}
+
+	return arbor_exec.NewCommandExecutor(commander)
+}
+
+func expandHomePaths(paths []string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return paths
+	}
+	expanded := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p == "~" || strings.HasPrefix(p, "~/") {
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+		expanded = append(expanded, p)
+	}
+	return expanded
+}
@@ -0,0 +1,474 @@
+package steps
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// schedulerRunners are the schedule runners, in the order
+// scheduler.unregister looks for them.
+var schedulerRunners = []string{config.SchedulerLaunchd, config.SchedulerSystemd, config.SchedulerCron}
+
+// SchedulerStep runs a worktree's Laravel schedule every minute, as
+// 'php artisan schedule:run' in that worktree, so scheduled jobs can be
+// tried out per branch. scheduler.register installs a launchd agent on
+// macOS, a systemd user timer on Linux, or a line in the user's crontab;
+// scheduler.unregister removes whichever is installed for the worktree.
+type SchedulerStep struct {
+	register bool
+	runner   string
+	goos     string
+	home     string
+	homeErr  error
+	lookPath func(string) (string, error)
+	executor *arbor_exec.CommandExecutor
+}
+
+var (
+	_ types.ScaffoldStep   = (*SchedulerStep)(nil)
+	_ types.CleanupPlanner = (*SchedulerStep)(nil)
+)
+
+// NewSchedulerRegisterStep creates a scheduler.register step with the
+// default command executor.
+func NewSchedulerRegisterStep(cfg config.StepConfig) *SchedulerStep {
+	return NewSchedulerStepWithExecutor(cfg, true, nil)
+}
+
+// NewSchedulerUnregisterStep creates a scheduler.unregister step with the
+// default command executor.
+func NewSchedulerUnregisterStep(cfg config.StepConfig) *SchedulerStep {
+	return NewSchedulerStepWithExecutor(cfg, false, nil)
+}
+
+// NewSchedulerStepWithExecutor creates a scheduler.register step, or a
+// scheduler.unregister step when register is false, with a custom command
+// executor. This is useful for testing with mock executors.
+func NewSchedulerStepWithExecutor(cfg config.StepConfig, register bool, executor *arbor_exec.CommandExecutor) *SchedulerStep {
+	if executor == nil {
+		executor = arbor_exec.NewCommandExecutor(nil)
+	}
+	home, homeErr := os.UserHomeDir()
+	return &SchedulerStep{
+		register: register,
+		runner:   cfg.Type,
+		goos:     runtime.GOOS,
+		home:     home,
+		homeErr:  homeErr,
+		lookPath: exec.LookPath,
+		executor: executor,
+	}
+}
+
+func (s *SchedulerStep) Name() string {
+	if s.register {
+		return "scheduler.register"
+	}
+	return "scheduler.unregister"
+}
+
+// Condition holds for Laravel apps; without an artisan file there is no
+// schedule to run.
+func (s *SchedulerStep) Condition(ctx *types.ScaffoldContext) bool {
+	if !s.register {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(ctx.WorktreePath, "artisan"))
+	return err == nil
+}
+
+func (s *SchedulerStep) Run(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	if !s.register {
+		return s.unregister(ctx, opts)
+	}
+
+	runner, err := s.defaultRunner()
+	if err != nil {
+		return err
+	}
+	php := "php"
+	if path, err := s.lookPath("php"); err == nil {
+		php = path
+	}
+	job := scheduleJob{ID: scheduleID(ctx), PHP: php, WorktreePath: ctx.WorktreePath}
+
+	switch runner {
+	case config.SchedulerLaunchd:
+		err = s.registerLaunchd(job)
+	case config.SchedulerSystemd:
+		err = s.registerSystemd(job)
+	default:
+		err = s.registerCron(job)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		fmt.Printf("  Registered %s to run the schedule every minute with %s\n", job.ID, runner)
+	}
+	return nil
+}
+
+// PlanCleanup reports the schedule runner scheduler.unregister would
+// remove.
+func (s *SchedulerStep) PlanCleanup(ctx *types.ScaffoldContext, opts types.StepOptions) ([]types.Resource, error) {
+	if s.register {
+		return nil, nil
+	}
+	id := scheduleID(ctx)
+	for _, runner := range schedulerRunners {
+		installed, err := s.installed(runner, id)
+		if err != nil {
+			return nil, err
+		}
+		if installed {
+			return []types.Resource{{Kind: types.ResourceSchedule, Name: id}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SchedulerStep) unregister(ctx *types.ScaffoldContext, opts types.StepOptions) error {
+	id := scheduleID(ctx)
+	removed := false
+	for _, runner := range schedulerRunners {
+		installed, err := s.installed(runner, id)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			continue
+		}
+		switch runner {
+		case config.SchedulerLaunchd:
+			err = s.unregisterLaunchd(id)
+		case config.SchedulerSystemd:
+			err = s.unregisterSystemd(id)
+		default:
+			err = s.unregisterCron(id)
+		}
+		if err != nil {
+			return err
+		}
+		removed = true
+		if opts.Verbose {
+			fmt.Printf("  Removed %s from %s\n", id, runner)
+		}
+	}
+	if removed {
+		ctx.RecordRemoved(types.ResourceSchedule, id)
+	}
+	return nil
+}
+
+// defaultRunner returns the configured runner, or the one the OS uses:
+// launchd on macOS, systemd where systemctl is installed, cron elsewhere.
+func (s *SchedulerStep) defaultRunner() (string, error) {
+	switch {
+	case s.runner != "":
+		return s.runner, nil
+	case s.goos == "windows":
+		return "", fmt.Errorf("scheduler.register is not supported on windows; add a Task Scheduler task running 'php artisan schedule:run' instead")
+	case s.goos == "darwin":
+		return config.SchedulerLaunchd, nil
+	}
+	if _, err := s.lookPath("systemctl"); err == nil {
+		return config.SchedulerSystemd, nil
+	}
+	return config.SchedulerCron, nil
+}
+
+// installed reports whether the runner has the schedule registered.
+func (s *SchedulerStep) installed(runner, id string) (bool, error) {
+	var path string
+	var err error
+	switch runner {
+	case config.SchedulerLaunchd:
+		path, err = s.launchdPath(id)
+	case config.SchedulerSystemd:
+		path, err = s.systemdPath(id, "timer")
+	default:
+		if _, err := s.lookPath("crontab"); err != nil {
+			return false, nil
+		}
+		crontab, err := s.readCrontab()
+		return err == nil && strings.Contains(crontab, cronMarker(id)), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+// homeDir returns the user's home directory, under which launchd agents
+// and systemd user units live.
+func (s *SchedulerStep) homeDir() (string, error) {
+	if s.homeErr != nil {
+		return "", fmt.Errorf("finding home directory: %w", s.homeErr)
+	}
+	return s.home, nil
+}
+
+// notLoaded reports whether launchctl or systemctl failed only because the
+// agent or unit was not loaded, which unloading counts as done.
+func notLoaded(output []byte) bool {
+	out := strings.ToLower(string(output))
+	for _, message := range []string{"could not find specified service", "not loaded", "does not exist", "not found"} {
+		if strings.Contains(out, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleJob is the schedule runner of one worktree.
+type scheduleJob struct {
+	ID           string
+	PHP          string
+	WorktreePath string
+}
+
+var scheduleIDPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// scheduleID names the worktree's schedule runner after its repository
+// and site, which is unique among the repository's worktrees.
+func scheduleID(ctx *types.ScaffoldContext) string {
+	site := ctx.SiteName
+	if site == "" {
+		site = filepath.Base(ctx.WorktreePath)
+	}
+	if ctx.RepoName != "" && !strings.HasPrefix(site, ctx.RepoName) {
+		site = ctx.RepoName + "-" + site
+	}
+	return "arbor-schedule-" + strings.Trim(scheduleIDPattern.ReplaceAllString(strings.ToLower(site), "-"), "-")
+}
+
+var launchdScheduleTemplate = template.Must(template.New("launchd").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var b strings.Builder
+		err := xml.EscapeText(&b, []byte(s))
+		return b.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{ xml .ID }}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{ xml .PHP }}</string>
+		<string>artisan</string>
+		<string>schedule:run</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>{{ xml .WorktreePath }}</string>
+	<key>StartInterval</key>
+	<integer>60</integer>
+</dict>
+</plist>
+`))
+
+func (s *SchedulerStep) launchdPath(id string) (string, error) {
+	home, err := s.homeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", id+".plist"), nil
+}
+
+func (s *SchedulerStep) registerLaunchd(job scheduleJob) error {
+	path, err := s.launchdPath(job.ID)
+	if err != nil {
+		return err
+	}
+	if err := writeTemplate(path, launchdScheduleTemplate, job); err != nil {
+		return err
+	}
+	// Reloading picks up a changed agent; unloading one that is not
+	// loaded fails harmlessly.
+	if output, err := s.executor.RunBinary(context.Background(), "", "launchctl", []string{"unload", path}); err != nil && !notLoaded(output) {
+		return fmt.Errorf("unloading %s: %w\n%s", path, err, string(output))
+	}
+	if output, err := s.executor.RunBinary(context.Background(), "", "launchctl", []string{"load", "-w", path}); err != nil {
+		return fmt.Errorf("loading %s: %w\n%s", path, err, string(output))
+	}
+	return nil
+}
+
+func (s *SchedulerStep) unregisterLaunchd(id string) error {
+	path, err := s.launchdPath(id)
+	if err != nil {
+		return err
+	}
+	if output, err := s.executor.RunBinary(context.Background(), "", "launchctl", []string{"unload", "-w", path}); err != nil && !notLoaded(output) {
+		return fmt.Errorf("unloading %s: %w\n%s", path, err, string(output))
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+	return nil
+}
+
+var systemdServiceTemplate = template.Must(template.New("service").Parse(`[Unit]
+Description=Laravel schedule of {{ .WorktreePath }}
+
+[Service]
+Type=oneshot
+WorkingDirectory={{ .WorktreePath }}
+ExecStart="{{ .PHP }}" artisan schedule:run
+`))
+
+var systemdTimerTemplate = template.Must(template.New("timer").Parse(`[Unit]
+Description=Run the Laravel schedule of {{ .WorktreePath }} every minute
+
+[Timer]
+OnCalendar=*-*-* *:*:00
+AccuracySec=1s
+
+[Install]
+WantedBy=timers.target
+`))
+
+func (s *SchedulerStep) systemdPath(id, kind string) (string, error) {
+	home, err := s.homeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user", id+"."+kind), nil
+}
+
+func (s *SchedulerStep) registerSystemd(job scheduleJob) error {
+	for _, unit := range []struct {
+		kind string
+		tmpl *template.Template
+	}{{"service", systemdServiceTemplate}, {"timer", systemdTimerTemplate}} {
+		path, err := s.systemdPath(job.ID, unit.kind)
+		if err != nil {
+			return err
+		}
+		if err := writeTemplate(path, unit.tmpl, job); err != nil {
+			return err
+		}
+	}
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", "--now", job.ID + ".timer"},
+	} {
+		if output, err := s.executor.RunBinary(context.Background(), "", "systemctl", args); err != nil {
+			return fmt.Errorf("running systemctl %s: %w\n%s", strings.Join(args, " "), err, string(output))
+		}
+	}
+	return nil
+}
+
+func (s *SchedulerStep) unregisterSystemd(id string) error {
+	args := []string{"--user", "disable", "--now", id + ".timer"}
+	if output, err := s.executor.RunBinary(context.Background(), "", "systemctl", args); err != nil && !notLoaded(output) {
+		return fmt.Errorf("running systemctl %s: %w\n%s", strings.Join(args, " "), err, string(output))
+	}
+	for _, kind := range []string{"timer", "service"} {
+		path, err := s.systemdPath(id, kind)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", path, err)
+		}
+	}
+	if output, err := s.executor.RunBinary(context.Background(), "", "systemctl", []string{"--user", "daemon-reload"}); err != nil {
+		return fmt.Errorf("running systemctl --user daemon-reload: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// cronMarker ends the crontab line of a schedule, so it can be found again.
+func cronMarker(id string) string {
+	return "# " + id
+}
+
+// readCrontab returns the user's crontab; a user without one has an empty
+// crontab.
+func (s *SchedulerStep) readCrontab() (string, error) {
+	output, err := s.executor.RunBinary(context.Background(), "", "crontab", []string{"-l"})
+	if err != nil {
+		if strings.Contains(string(output), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading crontab: %w\n%s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// writeCrontab replaces the user's crontab with the lines of the current
+// one that kept returns true for, followed by extra.
+func (s *SchedulerStep) writeCrontab(keep func(line string) bool, extra string) error {
+	current, err := s.readCrontab()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(current, "\n") {
+		if line != "" && keep(line) {
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString(extra)
+
+	file, err := os.CreateTemp("", "arbor-crontab-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.WriteString(b.String()); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if output, err := s.executor.RunBinary(context.Background(), "", "crontab", []string{file.Name()}); err != nil {
+		return fmt.Errorf("installing crontab: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+func (s *SchedulerStep) registerCron(job scheduleJob) error {
+	marker := cronMarker(job.ID)
+	line := fmt.Sprintf("* * * * * cd %s && %s artisan schedule:run >> /dev/null 2>&1 %s\n", utils.ShellQuote(job.WorktreePath), utils.ShellQuote(job.PHP), marker)
+	return s.writeCrontab(func(line string) bool { return !strings.HasSuffix(line, marker) }, line)
+}
+
+func (s *SchedulerStep) unregisterCron(id string) error {
+	marker := cronMarker(id)
+	return s.writeCrontab(func(line string) bool { return !strings.HasSuffix(line, marker) }, "")
+}
+
+// writeTemplate renders tmpl with data to path, creating its directory.
+func writeTemplate(path string, tmpl *template.Template, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("rendering %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	arbor_exec "github.com/artisanexperiences/arbor/internal/exec"
+	"github.com/artisanexperiences/arbor/internal/scaffold/types"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// fakeCrontab is a commander holding a crontab: 'crontab -l' prints it and
+// 'crontab FILE' replaces it. Other commands are recorded by the mock.
+type fakeCrontab struct {
+	*arbor_exec.MockCommander
+	content string
+	exists  bool
+}
+
+func (f *fakeCrontab) Run(ctx context.Context, dir string, command string, args ...string) ([]byte, error) {
+	if command != "crontab" {
+		return f.MockCommander.Run(ctx, dir, command, args...)
+	}
+	if len(args) == 1 && args[0] == "-l" {
+		if !f.exists {
+			return []byte("no crontab for dev\n"), errors.New("exit status 1")
+		}
+		return []byte(f.content), nil
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+	f.content, f.exists = string(content), true
+	return nil, nil
+}
+
+func newTestSchedulerStep(t *testing.T, register bool, goos string, commander arbor_exec.Commander, found ...string) *SchedulerStep {
+	t.Helper()
+	step := NewSchedulerStepWithExecutor(config.StepConfig{}, register, arbor_exec.NewCommandExecutor(commander))
+	step.goos = goos
+	step.home = t.TempDir()
+	step.lookPath = func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	return step
+}
+
+func newTestScheduleContext(t *testing.T) *types.ScaffoldContext {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "artisan"), nil, 0755))
+	return &types.ScaffoldContext{WorktreePath: dir, RepoName: "shop", SiteName: "feature_Login"}
+}
+
+func TestSchedulerStep_Systemd(t *testing.T) {
+	mock := arbor_exec.NewMockCommander()
+	ctx := newTestScheduleContext(t)
+	register := newTestSchedulerStep(t, true, "linux", mock, "php", "systemctl")
+
+	require.True(t, register.Condition(ctx))
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+
+	service, err := os.ReadFile(filepath.Join(register.home, ".config", "systemd", "user", "arbor-schedule-shop-feature-login.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(service), "WorkingDirectory="+ctx.WorktreePath+"\n")
+	assert.Contains(t, string(service), `ExecStart="/usr/bin/php" artisan schedule:run`)
+	assert.FileExists(t, filepath.Join(register.home, ".config", "systemd", "user", "arbor-schedule-shop-feature-login.timer"))
+	assert.True(t, mock.WasCalled("systemctl", "--user", "enable", "--now", "arbor-schedule-shop-feature-login.timer"))
+
+	unregister := newTestSchedulerStep(t, false, "linux", mock, "systemctl")
+	unregister.home = register.home
+
+	resources, err := unregister.PlanCleanup(ctx, types.StepOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceSchedule, Name: "arbor-schedule-shop-feature-login"}}, resources)
+
+	require.NoError(t, unregister.Run(ctx, types.StepOptions{}))
+	assert.NoFileExists(t, filepath.Join(register.home, ".config", "systemd", "user", "arbor-schedule-shop-feature-login.timer"))
+	assert.True(t, mock.WasCalled("systemctl", "--user", "disable", "--now", "arbor-schedule-shop-feature-login.timer"))
+	assert.Equal(t, resources, ctx.Removed())
+}
+
+func TestSchedulerStep_Launchd(t *testing.T) {
+	mock := arbor_exec.NewMockCommander()
+	ctx := newTestScheduleContext(t)
+	register := newTestSchedulerStep(t, true, "darwin", mock, "php")
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+
+	path := filepath.Join(register.home, "Library", "LaunchAgents", "arbor-schedule-shop-feature-login.plist")
+	plist, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(plist), "<string>arbor-schedule-shop-feature-login</string>")
+	assert.Contains(t, string(plist), "<string>"+ctx.WorktreePath+"</string>")
+	assert.True(t, mock.WasCalled("launchctl", "load", "-w", path))
+
+	unregister := newTestSchedulerStep(t, false, "darwin", mock)
+	unregister.home = register.home
+	require.NoError(t, unregister.Run(ctx, types.StepOptions{}))
+	assert.NoFileExists(t, path)
+	assert.True(t, mock.WasCalled("launchctl", "unload", "-w", path))
+}
+
+func TestSchedulerStep_Cron(t *testing.T) {
+	crontab := &fakeCrontab{MockCommander: arbor_exec.NewMockCommander()}
+	ctx := newTestScheduleContext(t)
+	register := newTestSchedulerStep(t, true, "linux", crontab, "php", "crontab")
+
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+	line := "* * * * * cd " + utils.ShellQuote(ctx.WorktreePath) + " && /usr/bin/php artisan schedule:run >> /dev/null 2>&1 # arbor-schedule-shop-feature-login\n"
+	assert.Equal(t, line, crontab.content, "a user without a crontab gets one")
+
+	crontab.content = "0 3 * * * backup\n" + crontab.content
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+	assert.Equal(t, "0 3 * * * backup\n"+line, crontab.content, "registering again replaces the line")
+
+	unregister := newTestSchedulerStep(t, false, "linux", crontab, "crontab")
+	require.NoError(t, unregister.Run(ctx, types.StepOptions{}))
+	assert.Equal(t, "0 3 * * * backup\n", crontab.content)
+	assert.Equal(t, []types.Resource{{Kind: types.ResourceSchedule, Name: "arbor-schedule-shop-feature-login"}}, ctx.Removed())
+
+	resources, err := unregister.PlanCleanup(ctx, types.StepOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestSchedulerStep_Unsupported(t *testing.T) {
+	step := newTestSchedulerStep(t, true, "windows", arbor_exec.NewMockCommander(), "php")
+
+	err := step.Run(newTestScheduleContext(t), types.StepOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported on windows")
+
+	assert.False(t, step.Condition(&types.ScaffoldContext{WorktreePath: t.TempDir()}), "not a Laravel app")
+}
+
+func TestSchedulerStep_UnloadFailures(t *testing.T) {
+	ctx := newTestScheduleContext(t)
+	mock := arbor_exec.NewMockCommander()
+	register := newTestSchedulerStep(t, true, "darwin", mock, "php")
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+	path := filepath.Join(register.home, "Library", "LaunchAgents", "arbor-schedule-shop-feature-login.plist")
+
+	mock.SetResponse("launchctl", []string{"unload", "-w", path}, []byte("Could not find specified service\n"), errors.New("exit status 113"))
+	unregister := newTestSchedulerStep(t, false, "darwin", mock)
+	unregister.home = register.home
+	require.NoError(t, unregister.Run(ctx, types.StepOptions{}), "an agent that is not loaded counts as unloaded")
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, register.Run(ctx, types.StepOptions{}))
+	mock.SetResponse("launchctl", []string{"unload", "-w", path}, []byte("Operation not permitted\n"), errors.New("exit status 1"))
+	err := unregister.Run(ctx, types.StepOptions{})
+	assert.ErrorContains(t, err, "unloading")
+	assert.FileExists(t, path, "the agent is kept when it could not be unloaded")
+}
+
+func TestSchedulerStep_NoHomeDirectory(t *testing.T) {
+	ctx := newTestScheduleContext(t)
+	step := newTestSchedulerStep(t, true, "linux", arbor_exec.NewMockCommander(), "php", "systemctl")
+	step.home, step.homeErr = "", errors.New("$HOME is not defined")
+
+	err := step.Run(ctx, types.StepOptions{})
+	assert.ErrorContains(t, err, "finding home directory")
+}
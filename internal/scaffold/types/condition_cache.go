@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Conditions whose result cannot change while steps run: tools on PATH,
+// the OS, the process environment, the enabled profiles and git facts.
+var stableConditions = map[string]bool{
+	"command_exists":    true,
+	"os":                true,
+	"profile":           true,
+	"env_exists":        true,
+	"env_not_exists":    true,
+	"branch_matches":    true,
+	"is_default_branch": true,
+	"remote_exists":     true,
+}
+
+// Conditions that read the worktree, which steps may change.
+var fileConditions = map[string]bool{
+	"file_exists":          true,
+	"file_contains":        true,
+	"file_has_script":      true,
+	"composer_has_package": true,
+	"node_has_package":     true,
+	"env_file_contains":    true,
+	"env_file_missing":     true,
+	"env_file_equals":      true,
+}
+
+// conditionCache memoises condition results during a run, keyed by
+// condition name and content. Stable conditions are kept for the whole run;
+// file conditions until a step runs. Other conditions, such as context_var
+// and not, are always evaluated, as is everything outside a run.
+type conditionCache struct {
+	mu      sync.Mutex
+	enabled bool
+	stable  map[string]bool
+	files   map[string]bool
+}
+
+func (c *conditionCache) evaluate(name string, value interface{}, eval func() (bool, error)) (bool, error) {
+	c.mu.Lock()
+	enabled := c.enabled
+	c.mu.Unlock()
+	if !enabled || (!stableConditions[name] && !fileConditions[name]) {
+		return eval()
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return eval()
+	}
+	key := name + ":" + string(content)
+
+	c.mu.Lock()
+	cache := c.files
+	if stableConditions[name] {
+		cache = c.stable
+	}
+	result, ok := cache[key]
+	c.mu.Unlock()
+	if ok {
+		return result, nil
+	}
+
+	result, err = eval()
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stableConditions[name] {
+		if c.stable == nil {
+			c.stable = make(map[string]bool)
+		}
+		c.stable[key] = result
+	} else {
+		if c.files == nil {
+			c.files = make(map[string]bool)
+		}
+		c.files[key] = result
+	}
+	return result, nil
+}
+
+// StartConditionCache memoises condition results until StopConditionCache,
+// so conditions evaluated both to count and to run steps are only checked
+// once.
+func (ctx *ScaffoldContext) StartConditionCache() {
+	ctx.conditions.mu.Lock()
+	defer ctx.conditions.mu.Unlock()
+	ctx.conditions.enabled = true
+}
+
+// StopConditionCache stops memoising condition results and forgets them.
+func (ctx *ScaffoldContext) StopConditionCache() {
+	ctx.conditions.mu.Lock()
+	defer ctx.conditions.mu.Unlock()
+	ctx.conditions.enabled = false
+	ctx.conditions.stable = nil
+	ctx.conditions.files = nil
+}
+
+// InvalidateFileConditions forgets cached results of conditions that read
+// the worktree. The executor calls it after each step runs, since the step
+// may have created or changed the files they read.
+func (ctx *ScaffoldContext) InvalidateFileConditions() {
+	ctx.conditions.mu.Lock()
+	defer ctx.conditions.mu.Unlock()
+	ctx.conditions.files = nil
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConditionCache(t *testing.T) {
+	t.Run("caches results by condition content", func(t *testing.T) {
+		cache := conditionCache{enabled: true}
+		calls := 0
+		eval := func() (bool, error) {
+			calls++
+			return true, nil
+		}
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.evaluate("command_exists", "git", eval); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := cache.evaluate("command_exists", "docker", eval); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 evaluations, got %d", calls)
+		}
+	})
+
+	t.Run("evaluates every time outside a run", func(t *testing.T) {
+		var cache conditionCache
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, _ = cache.evaluate("os", "linux", func() (bool, error) {
+				calls++
+				return true, nil
+			})
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 evaluations, got %d", calls)
+		}
+	})
+
+	t.Run("does not cache errors or uncacheable conditions", func(t *testing.T) {
+		cache := conditionCache{enabled: true}
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, _ = cache.evaluate("is_default_branch", "yes", func() (bool, error) {
+				calls++
+				return false, os.ErrInvalid
+			})
+			_, _ = cache.evaluate("context_var", map[string]interface{}{"key": "A"}, func() (bool, error) {
+				calls++
+				return true, nil
+			})
+		}
+		if calls != 4 {
+			t.Errorf("expected 4 evaluations, got %d", calls)
+		}
+	})
+}
+
+func TestScaffoldContext_InvalidateFileConditions(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := &ScaffoldContext{WorktreePath: tmpDir}
+	ctx.StartConditionCache()
+	defer ctx.StopConditionCache()
+	condition := map[string]interface{}{"file_exists": ".env"}
+
+	evaluate := func() bool {
+		t.Helper()
+		result, err := ctx.EvaluateCondition(condition)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	if evaluate() {
+		t.Fatal("expected false before the file exists")
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if evaluate() {
+		t.Error("expected the cached result until invalidated")
+	}
+
+	ctx.InvalidateFileConditions()
+	if !evaluate() {
+		t.Error("expected true after invalidation")
+	}
+}
+
+func BenchmarkEvaluateCondition(b *testing.B) {
+	tmpDir := b.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("APP_KEY=base64:abc\nDB_CONNECTION=mysql\n"), 0644); err != nil {
+		b.Fatal(err)
+	}
+	conditions := []struct {
+		name      string
+		condition map[string]interface{}
+	}{
+		{"file_exists", map[string]interface{}{"file_exists": []interface{}{".env", "composer.json"}}},
+		{"env_file_contains", map[string]interface{}{"env_file_contains": map[string]interface{}{"key": "DB_CONNECTION"}}},
+		{"env_file_equals", map[string]interface{}{"env_file_equals": map[string]interface{}{"key": "DB_CONNECTION", "in": []interface{}{"mysql", "mariadb"}}}},
+		{"command_exists", map[string]interface{}{"command_exists": "git"}},
+	}
+
+	for _, tc := range conditions {
+		name, condition := tc.name, tc.condition
+		b.Run(name, func(b *testing.B) {
+			ctx := &ScaffoldContext{WorktreePath: tmpDir}
+			for i := 0; i < b.N; i++ {
+				if _, err := ctx.EvaluateCondition(condition); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/cached", func(b *testing.B) {
+			ctx := &ScaffoldContext{WorktreePath: tmpDir}
+			ctx.StartConditionCache()
+			defer ctx.StopConditionCache()
+			for i := 0; i < b.N; i++ {
+				if _, err := ctx.EvaluateCondition(condition); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,33 @@
 package types
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/go-viper/mapstructure/v2"
 
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
+	"github.com/artisanexperiences/arbor/internal/scaffold/words"
 	"github.com/artisanexperiences/arbor/internal/utils"
 )
 
+// ScaffoldContext is the state the steps of one run share. Steps set
+// variables, record removed resources and cache clients in it, and it holds
+// locks, so it is always passed by pointer and never copied.
 type ScaffoldContext struct {
 	WorktreePath string
 	Branch       string
@@ -25,8 +39,83 @@ type ScaffoldContext struct {
 	RepoPath     string
 	BarePath     string
 	DbSuffix     string
-	Vars         map[string]string
-	mu           sync.RWMutex
+	// DbNaming is the project's db_naming mode; with "branch" an existing
+	// database named for the branch is reused rather than replaced.
+	DbNaming string
+	// Naming is the project's naming config, which db.create follows when
+	// it picks a new suffix after a name collision.
+	Naming config.NamingConfig
+	// EnvFile is the project's primary env file; empty means .env.
+	EnvFile string
+	// DefaultBranch is the project's configured default branch; empty means
+	// it is detected from the repository.
+	DefaultBranch string
+	// Policy is the project's policy; bash.run checks its rendered command
+	// against policy.deny_commands.
+	Policy config.PolicyConfig
+	// Sandbox, when enabled, restricts how bash.run, command.run and
+	// binary steps run.
+	Sandbox config.SandboxConfig
+	// Shell is the project's shell for bash.run steps that name none;
+	// empty means bash.
+	Shell string
+	// Profiles are the scaffold profiles enabled for the run, matched by
+	// the profile condition.
+	Profiles []string
+	// Configs memoises config reads for the command; nil reads from disk.
+	Configs *config.Store
+	// Answers replays and records prompt answers; nil prompts as usual.
+	Answers  *prompts.Answers
+	Vars     map[string]string
+	removed  []Resource
+	warnings []string
+	changes  []config.FileChange
+	clients  map[string]io.Closer
+	mu       sync.RWMutex
+
+	gitOnce sync.Once
+	gitVars map[string]string
+
+	conditions conditionCache
+}
+
+// Resource kinds reported by cleanup steps.
+const (
+	ResourceDatabase = "database"
+	ResourceHerdLink = "herd_link"
+	ResourceSchedule = "schedule"
+)
+
+// Resource is an external resource, such as a database or Herd link,
+// removed (or planned for removal) by a cleanup step.
+type Resource struct {
+	Kind string
+	Name string
+}
+
+// PlanValidator is implemented by steps that can detect, without side
+// effects, configuration that would fail when run, so dry runs report it.
+type PlanValidator interface {
+	ValidatePlan(ctx *ScaffoldContext, opts StepOptions) error
+}
+
+// CleanupPlanner is implemented by cleanup steps that remove external
+// resources, so a dry run can report exactly what would be removed.
+type CleanupPlanner interface {
+	PlanCleanup(ctx *ScaffoldContext, opts StepOptions) ([]Resource, error)
+}
+
+// Describer is implemented by steps that describe what a configured step
+// does, such as "Running composer install", for progress output. Other
+// steps are described by their registered description.
+type Describer interface {
+	Description() string
+}
+
+// ShellStep is implemented by steps that run their command through a
+// shell, so pre-flight can check the shell is installed.
+type ShellStep interface {
+	Shell(ctx *ScaffoldContext) string
 }
 
 type PromptMode struct {
@@ -34,6 +123,9 @@ type PromptMode struct {
 	NoInteractive bool
 	Force         bool
 	CI            bool
+	// Trust confirms the steps policy.require_confirmation lists, so they
+	// run without asking (--trust).
+	Trust bool
 }
 
 func (p PromptMode) Allow() bool {
@@ -51,6 +143,9 @@ type StepOptions struct {
 	PromptMode PromptMode
 }
 
+// ScaffoldStep is a step of a scaffold or cleanup run. Every step takes the
+// run's *ScaffoldContext, so store_as and recorded resources reach later
+// steps; each implementation asserts the interface at compile time.
 type ScaffoldStep interface {
 	Name() string
 	Run(ctx *ScaffoldContext, opts StepOptions) error
@@ -62,14 +157,8 @@ func (ctx *ScaffoldContext) EvaluateCondition(conditions map[string]interface{})
 		return true, nil
 	}
 
-	if not, ok := conditions["not"]; ok {
-		result, err := ctx.evaluateCondition(not)
-		if err != nil {
-			return false, err
-		}
-		return !result, nil
-	}
-
+	// not is one key among the others, so a condition such as
+	// {composer_has_package: x, not: {file_exists: y}} needs both.
 	return ctx.evaluateCondition(conditions)
 }
 
@@ -111,6 +200,35 @@ func (ctx *ScaffoldContext) evaluateArrayCondition(conditions []interface{}) (bo
 }
 
 func (ctx *ScaffoldContext) evaluateSingle(key string, value interface{}) (bool, error) {
+	return ctx.conditions.evaluate(key, value, func() (bool, error) {
+		return ctx.evaluateUncached(key, value)
+	})
+}
+
+// ConditionKeys lists the condition keys evaluateUncached understands, for
+// editors completing arbor.yaml.
+var ConditionKeys = []string{
+	"branch_matches",
+	"command_exists",
+	"composer_has_package",
+	"context_var",
+	"env_exists",
+	"env_file_contains",
+	"env_file_equals",
+	"env_file_missing",
+	"env_not_exists",
+	"file_contains",
+	"file_exists",
+	"file_has_script",
+	"is_default_branch",
+	"node_has_package",
+	"not",
+	"os",
+	"profile",
+	"remote_exists",
+}
+
+func (ctx *ScaffoldContext) evaluateUncached(key string, value interface{}) (bool, error) {
 	switch key {
 	case "file_exists":
 		return ctx.fileExists(value)
@@ -120,8 +238,14 @@ func (ctx *ScaffoldContext) evaluateSingle(key string, value interface{}) (bool,
 		return ctx.fileHasScript(value)
 	case "command_exists":
 		return ctx.commandExists(value)
+	case "composer_has_package":
+		return ctx.composerHasPackage(value)
+	case "node_has_package":
+		return ctx.nodeHasPackage(value)
 	case "os":
 		return ctx.osMatches(value)
+	case "profile":
+		return ctx.profileEnabled(value)
 	case "env_exists":
 		return ctx.envExists(value)
 	case "env_not_exists":
@@ -130,8 +254,16 @@ func (ctx *ScaffoldContext) evaluateSingle(key string, value interface{}) (bool,
 		return ctx.envFileContains(value)
 	case "env_file_missing":
 		return ctx.envFileMissing(value)
+	case "env_file_equals":
+		return ctx.envFileEquals(value)
 	case "context_var":
 		return ctx.contextVarEquals(value)
+	case "branch_matches":
+		return ctx.branchMatches(value)
+	case "is_default_branch":
+		return ctx.isDefaultBranch(value)
+	case "remote_exists":
+		return ctx.remoteExists(value)
 	case "not":
 		result, err := ctx.evaluateCondition(value)
 		if err != nil {
@@ -223,7 +355,80 @@ func (ctx *ScaffoldContext) fileHasScript(value interface{}) (bool, error) {
 		return false, nil
 	}
 
-	return strings.Contains(string(data), `"`+scriptName+`"`), nil
+	var manifest struct {
+		Scripts map[string]interface{} `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false, nil
+	}
+	_, ok := manifest.Scripts[scriptName]
+	return ok, nil
+}
+
+// composerHasPackage reports whether the worktree's composer.json requires
+// a package, or every package of a list, in require or require-dev.
+// Package names are matched exactly, ignoring case as Composer does, so
+// laravel/horizon does not match laravel/horizon-watcher.
+func (ctx *ScaffoldContext) composerHasPackage(value interface{}) (bool, error) {
+	return ctx.manifestHasPackages("composer_has_package", "composer.json", value, "require", "require-dev")
+}
+
+// nodeHasPackage reports whether the worktree's package.json depends on a
+// package, or every package of a list, in dependencies or
+// devDependencies.
+func (ctx *ScaffoldContext) nodeHasPackage(value interface{}) (bool, error) {
+	return ctx.manifestHasPackages("node_has_package", "package.json", value, "dependencies", "devDependencies")
+}
+
+// manifestHasPackages reports whether the sections of the JSON manifest
+// file list every package value names. A missing manifest lists none; an
+// invalid one is an error of the condition key.
+func (ctx *ScaffoldContext) manifestHasPackages(key, file string, value interface{}, sections ...string) (bool, error) {
+	var packages []string
+	switch v := value.(type) {
+	case string:
+		packages = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				packages = append(packages, name)
+			}
+		}
+	case map[string]interface{}:
+		if name, ok := v["package"].(string); ok {
+			packages = []string{name}
+		}
+	}
+	if len(packages) == 0 {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(ctx.WorktreePath, file))
+	if err != nil {
+		return false, nil
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false, fmt.Errorf("%s: parsing %s: %w", key, file, err)
+	}
+	listed := make(map[string]bool)
+	for _, section := range sections {
+		var entries map[string]interface{}
+		if raw, ok := manifest[section]; ok {
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				return false, fmt.Errorf("%s: parsing %s %s: %w", key, file, section, err)
+			}
+		}
+		for name := range entries {
+			listed[strings.ToLower(name)] = true
+		}
+	}
+	for _, name := range packages {
+		if !listed[strings.ToLower(name)] {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 func (ctx *ScaffoldContext) commandExists(value interface{}) (bool, error) {
@@ -275,6 +480,29 @@ func (ctx *ScaffoldContext) osMatches(value interface{}) (bool, error) {
 	return false, nil
 }
 
+// profileEnabled reports whether the profile, or any of a list of
+// profiles, is enabled for the run.
+func (ctx *ScaffoldContext) profileEnabled(value interface{}) (bool, error) {
+	var profiles []string
+	switch v := value.(type) {
+	case string:
+		profiles = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				profiles = append(profiles, s)
+			}
+		}
+	}
+
+	for _, profile := range profiles {
+		if slices.Contains(ctx.Profiles, profile) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (ctx *ScaffoldContext) envExists(value interface{}) (bool, error) {
 	switch v := value.(type) {
 	case string:
@@ -324,12 +552,14 @@ func (ctx *ScaffoldContext) envFileContains(value interface{}) (bool, error) {
 		}
 	case string:
 		config.Key = v
-		config.File = ".env"
 	}
 
-	if config.File == "" || config.Key == "" {
+	if config.Key == "" {
 		return false, nil
 	}
+	if config.File == "" {
+		config.File = ctx.PrimaryEnvFile()
+	}
 
 	env := utils.ReadEnvFile(ctx.WorktreePath, config.File)
 	val, exists := env[config.Key]
@@ -344,21 +574,200 @@ func (ctx *ScaffoldContext) envFileMissing(value interface{}) (bool, error) {
 	return !contains, nil
 }
 
+// envFileEquals compares the value of a key in an env file. A key that is
+// not in the file matches nothing.
+func (ctx *ScaffoldContext) envFileEquals(value interface{}) (bool, error) {
+	var cfg struct {
+		File       string `mapstructure:"file"`
+		Key        string `mapstructure:"key"`
+		Comparison `mapstructure:",squash"`
+	}
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	if err := decodeComparison(v, &cfg); err != nil {
+		return false, fmt.Errorf("env_file_equals: %w", err)
+	}
+	if cfg.Key == "" {
+		return false, nil
+	}
+	if cfg.File == "" {
+		cfg.File = ctx.PrimaryEnvFile()
+	}
+
+	actual, exists := utils.ReadEnvFile(ctx.WorktreePath, cfg.File)[cfg.Key]
+	if !exists {
+		return false, nil
+	}
+	matched, err := cfg.Comparison.Match(actual)
+	if err != nil {
+		return false, fmt.Errorf("env_file_equals: %w", err)
+	}
+	return matched, nil
+}
+
 func (ctx *ScaffoldContext) contextVarEquals(value interface{}) (bool, error) {
 	var cfg struct {
-		Key   string `mapstructure:"key"`
-		Value string `mapstructure:"value"`
+		Key        string `mapstructure:"key"`
+		Comparison `mapstructure:",squash"`
 	}
 	switch v := value.(type) {
 	case map[string]interface{}:
-		if err := mapstructure.Decode(v, &cfg); err != nil {
+		if err := decodeComparison(v, &cfg); err != nil {
 			return false, nil
 		}
 	}
 	if cfg.Key == "" {
 		return false, nil
 	}
-	return ctx.GetVar(cfg.Key) == cfg.Value, nil
+	matched, err := cfg.Comparison.Match(ctx.GetVar(cfg.Key))
+	if err != nil {
+		return false, fmt.Errorf("context_var: %w", err)
+	}
+	return matched, nil
+}
+
+// branchMatches reports whether the worktree's branch matches a glob
+// pattern, or any of a list of them. * does not match a /, so hotfix/*
+// matches hotfix/login but not hotfix/ui/login.
+func (ctx *ScaffoldContext) branchMatches(value interface{}) (bool, error) {
+	var patterns []string
+	switch v := value.(type) {
+	case string:
+		patterns = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				patterns = append(patterns, s)
+			}
+		}
+	}
+
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, ctx.Branch)
+		if err != nil {
+			return false, fmt.Errorf("branch_matches: invalid pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isDefaultBranch compares whether the worktree is on the default branch
+// with the expected value; is_default_branch: false selects feature
+// branches.
+func (ctx *ScaffoldContext) isDefaultBranch(value interface{}) (bool, error) {
+	want, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("is_default_branch: expected true or false, got %v", value)
+	}
+	defaultBranch := ctx.gitMetadata()["DefaultBranch"]
+	isDefault := ctx.Branch != "" && ctx.Branch == defaultBranch
+	return isDefault == want, nil
+}
+
+// remoteExists reports whether the repository has the named remote, or all
+// of a list of them.
+func (ctx *ScaffoldContext) remoteExists(value interface{}) (bool, error) {
+	var names []string
+	switch v := value.(type) {
+	case string:
+		names = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	remotes, err := git.ListRemotes(ctx.repoPath())
+	if err != nil {
+		return false, nil
+	}
+	for _, name := range names {
+		if !slices.Contains(remotes, name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Comparison is the test applied to a value by env_file_equals and
+// context_var. Value and Equals are synonyms for an exact match, Matches is
+// a regular expression and In lists accepted values. When several are given
+// all must hold; when none is, the value must be empty.
+type Comparison struct {
+	Value   *string  `mapstructure:"value"`
+	Equals  *string  `mapstructure:"equals"`
+	Matches string   `mapstructure:"matches"`
+	In      []string `mapstructure:"in"`
+}
+
+// decodeComparison decodes a condition holding a Comparison. Unquoted YAML
+// numbers and booleans are compared as they were written, so value: 3306
+// matches PORT=3306.
+func decodeComparison(input map[string]interface{}, output interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: func(from, to reflect.Type, data interface{}) (interface{}, error) {
+			if to.Kind() != reflect.String {
+				return data, nil
+			}
+			switch from.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+				return fmt.Sprint(data), nil
+			}
+			return data, nil
+		},
+		Result: output,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(input)
+}
+
+// Match reports whether actual passes the comparison. It fails only for an
+// invalid regular expression.
+func (c Comparison) Match(actual string) (bool, error) {
+	compared := false
+	for _, want := range []*string{c.Value, c.Equals} {
+		if want == nil {
+			continue
+		}
+		compared = true
+		if actual != *want {
+			return false, nil
+		}
+	}
+	if c.Matches != "" {
+		compared = true
+		re, err := regexp.Compile(c.Matches)
+		if err != nil {
+			return false, fmt.Errorf("invalid matches pattern %q: %w", c.Matches, err)
+		}
+		if !re.MatchString(actual) {
+			return false, nil
+		}
+	}
+	if c.In != nil {
+		compared = true
+		if !slices.Contains(c.In, actual) {
+			return false, nil
+		}
+	}
+	if !compared {
+		return actual == "", nil
+	}
+	return true, nil
 }
 
 func (ctx *ScaffoldContext) SetVar(key, value string) {
@@ -388,7 +797,121 @@ func (ctx *ScaffoldContext) GetDbSuffix() string {
 	return ctx.DbSuffix
 }
 
+// PrimaryEnvFile returns the env file that steps and conditions naming no
+// file read and write.
+func (ctx *ScaffoldContext) PrimaryEnvFile() string {
+	if ctx.EnvFile == "" {
+		return config.DefaultEnvFile
+	}
+	return ctx.EnvFile
+}
+
+// RecordRemoved notes a resource removed by a cleanup step.
+func (ctx *ScaffoldContext) RecordRemoved(kind, name string) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.removed = append(ctx.removed, Resource{Kind: kind, Name: name})
+}
+
+// Removed returns the resources recorded by RecordRemoved, in removal order.
+func (ctx *ScaffoldContext) Removed() []Resource {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return append([]Resource(nil), ctx.removed...)
+}
+
+// SharedClient returns the client cached under key for this run, calling
+// open on first use, so steps connecting to the same server share one
+// connection. Cached clients are closed by CloseClients.
+func (ctx *ScaffoldContext) SharedClient(key string, open func() (io.Closer, error)) (io.Closer, error) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if client, ok := ctx.clients[key]; ok {
+		return client, nil
+	}
+	client, err := open()
+	if err != nil {
+		return nil, err
+	}
+	if ctx.clients == nil {
+		ctx.clients = make(map[string]io.Closer)
+	}
+	ctx.clients[key] = client
+	return client, nil
+}
+
+// CloseClients closes the clients cached by SharedClient.
+func (ctx *ScaffoldContext) CloseClients() error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	var errs []error
+	for _, client := range ctx.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	ctx.clients = nil
+	return errors.Join(errs...)
+}
+
+// AddWarning notes a problem a step worked around, reported in the
+// scaffold summary.
+func (ctx *ScaffoldContext) AddWarning(message string) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.warnings = append(ctx.warnings, message)
+}
+
+// Warnings returns the messages recorded by AddWarning, in order.
+func (ctx *ScaffoldContext) Warnings() []string {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return append([]string(nil), ctx.warnings...)
+}
+
+// RecordFileChange notes a file in the worktree a step is about to create
+// or change, with its current content, for the worktree's file journal.
+// Only the first call for a file counts, and files outside the worktree
+// are ignored. It reports whether this call recorded the file.
+func (ctx *ScaffoldContext) RecordFileChange(path string) bool {
+	rel, err := filepath.Rel(ctx.WorktreePath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	for _, change := range ctx.changes {
+		if change.Path == rel {
+			return false
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false
+	}
+	ctx.changes = append(ctx.changes, config.FileChange{Path: rel, Existed: err == nil, Content: content})
+	return true
+}
+
+// FileChanges returns the files recorded by RecordFileChange, in order.
+func (ctx *ScaffoldContext) FileChanges() []config.FileChange {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return append([]config.FileChange(nil), ctx.changes...)
+}
+
+// BuiltinVars are the template variables every run provides, before any
+// step sets its own.
+var BuiltinVars = []string{
+	"Path", "RepoPath", "RepoName", "SiteName", "SanitizedSiteName", "Branch", "DbSuffix", "DbName",
+	"TestDbName", "CommitShort", "Author", "RemoteURL", "DefaultBranch",
+}
+
 func (ctx *ScaffoldContext) SnapshotForTemplate() map[string]string {
+	gitVars := ctx.gitMetadata()
+
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
 	snapshot := map[string]string{
@@ -399,6 +922,11 @@ func (ctx *ScaffoldContext) SnapshotForTemplate() map[string]string {
 		"SanitizedSiteName": sanitizeSiteName(ctx.SiteName),
 		"Branch":            ctx.Branch,
 		"DbSuffix":          ctx.DbSuffix,
+		"DbName":            words.DatabaseName(ctx.SiteName, ctx.DbSuffix, 0),
+		"TestDbName":        words.DatabaseName(sanitizeSiteName(ctx.SiteName)+"_test", ctx.DbSuffix, 0),
+	}
+	for k, v := range gitVars {
+		snapshot[k] = v
 	}
 	for k, v := range ctx.Vars {
 		snapshot[k] = v
@@ -406,6 +934,71 @@ func (ctx *ScaffoldContext) SnapshotForTemplate() map[string]string {
 	return snapshot
 }
 
+// gitMetadata returns the git facts exposed to templates: CommitShort and
+// Author of the worktree's HEAD, the origin RemoteURL and the repository's
+// DefaultBranch. Git is only queried the first time they are needed; facts
+// that cannot be resolved (no commits, no origin) are empty.
+func (ctx *ScaffoldContext) gitMetadata() map[string]string {
+	ctx.gitOnce.Do(func() {
+		vars := map[string]string{
+			"CommitShort":   "",
+			"Author":        "",
+			"RemoteURL":     "",
+			"DefaultBranch": "",
+		}
+		if ctx.WorktreePath == "" {
+			ctx.gitVars = vars
+			return
+		}
+
+		repoPath := ctx.repoPath()
+		if commit, err := git.HeadCommitShort(ctx.WorktreePath); err == nil {
+			vars["CommitShort"] = commit
+		}
+		if author, err := git.HeadAuthor(ctx.WorktreePath); err == nil {
+			vars["Author"] = author
+		}
+		if url, err := git.GetRemoteURL(repoPath, "origin"); err == nil {
+			vars["RemoteURL"] = url
+		}
+		if ctx.DefaultBranch != "" {
+			vars["DefaultBranch"] = ctx.DefaultBranch
+		} else if branch, err := git.GetDefaultBranch(repoPath); err == nil {
+			vars["DefaultBranch"] = branch
+		}
+		ctx.gitVars = vars
+	})
+	return ctx.gitVars
+}
+
+// repoPath returns the repository git commands for the context run in: the
+// bare repository when known, otherwise the worktree.
+func (ctx *ScaffoldContext) repoPath() string {
+	if ctx.BarePath != "" {
+		return ctx.BarePath
+	}
+	return ctx.WorktreePath
+}
+
+// MainWorktreePath returns the path of the worktree on the project's
+// default branch.
+func (ctx *ScaffoldContext) MainWorktreePath() (string, error) {
+	defaultBranch := ctx.gitMetadata()["DefaultBranch"]
+	if defaultBranch == "" {
+		return "", fmt.Errorf("the default branch is unknown")
+	}
+	worktrees, err := git.ListWorktrees(ctx.repoPath())
+	if err != nil {
+		return "", fmt.Errorf("listing worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == defaultBranch {
+			return wt.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no worktree is on the default branch %s", defaultBranch)
+}
+
 func sanitizeSiteName(name string) string {
 	name = strings.ToLower(name)
 	re := regexp.MustCompile(`[^a-z0-9_]`)
@@ -2,8 +2,12 @@ package types
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -217,6 +221,21 @@ func TestScaffoldContext_EvaluateCondition(t *testing.T) {
 		}
 	})
 
+	t.Run("not combined with other conditions", func(t *testing.T) {
+		result, err := ctx.EvaluateCondition(map[string]interface{}{
+			"file_exists": "nonexistent.txt",
+			"not": map[string]interface{}{
+				"file_exists": "other.txt",
+			},
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if result {
+			t.Error("expected false when a condition beside not does not match")
+		}
+	})
+
 	t.Run("multiple conditions - all match", func(t *testing.T) {
 		filePath := filepath.Join(tmpDir, "test.txt")
 		if err := os.WriteFile(filePath, []byte("hello"), 0644); err != nil {
@@ -285,10 +304,8 @@ func TestScaffoldContext_FileHasScript(t *testing.T) {
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
-		// Note: implementation uses simple string contains, so "test" appears in "test"
-		// as part of name. This tests the actual behavior, not ideal behavior.
 		if result {
-			t.Log("Note: implementation returns true due to string contains matching 'test' in name")
+			t.Error("expected false when only the package name matches")
 		}
 	})
 
@@ -305,6 +322,32 @@ func TestScaffoldContext_FileHasScript(t *testing.T) {
 	})
 }
 
+func TestScaffoldContext_EnvFileConditions_PrimaryEnvFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, ".env.local"), []byte("DATABASE_URL=postgres://localhost/app"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ScaffoldContext{WorktreePath: tmpDir, EnvFile: ".env.local"}
+
+	for name, cond := range map[string]interface{}{
+		"key only":     "DATABASE_URL",
+		"map, no file": map[string]interface{}{"key": "DATABASE_URL"},
+	} {
+		result, err := ctx.EvaluateCondition(map[string]interface{}{"env_file_contains": cond})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if !result {
+			t.Errorf("%s: expected the condition to read .env.local", name)
+		}
+	}
+
+	if got := (&ScaffoldContext{}).PrimaryEnvFile(); got != ".env" {
+		t.Errorf("expected .env by default, got %s", got)
+	}
+}
+
 func TestScaffoldContext_EnvFileConditions(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -458,6 +501,21 @@ func TestScaffoldContext_SnapshotForTemplate(t *testing.T) {
 
 	snapshot := ctx.SnapshotForTemplate()
 
+	t.Run("BuiltinVars lists every built-in field", func(t *testing.T) {
+		var builtins []string
+		for name := range snapshot {
+			if name != "CustomVar" {
+				builtins = append(builtins, name)
+			}
+		}
+		sort.Strings(builtins)
+		want := append([]string(nil), BuiltinVars...)
+		sort.Strings(want)
+		if !reflect.DeepEqual(builtins, want) {
+			t.Errorf("BuiltinVars = %v, snapshot has %v", want, builtins)
+		}
+	})
+
 	t.Run("snapshot includes all built-in fields", func(t *testing.T) {
 		if snapshot["Path"] != "feature-auth" {
 			t.Errorf("expected feature-auth, got %q", snapshot["Path"])
@@ -477,6 +535,9 @@ func TestScaffoldContext_SnapshotForTemplate(t *testing.T) {
 		if snapshot["DbSuffix"] != "swift_runner" {
 			t.Errorf("expected swift_runner, got %q", snapshot["DbSuffix"])
 		}
+		if snapshot["TestDbName"] != "mysite_test_swift_runner" {
+			t.Errorf("expected mysite_test_swift_runner, got %q", snapshot["TestDbName"])
+		}
 	})
 
 	t.Run("snapshot includes dynamic variables", func(t *testing.T) {
@@ -884,3 +945,275 @@ func TestScaffoldContext_ContextVar(t *testing.T) {
 		}
 	})
 }
+
+func TestScaffoldContext_GitMetadata(t *testing.T) {
+	t.Run("resolves git facts for the worktree", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, args := range [][]string{
+			{"init", "-b", "main"},
+			{"config", "user.email", "test@example.com"},
+			{"config", "user.name", "Test User"},
+			{"remote", "add", "origin", "git@github.com:acme/shop.git"},
+			{"commit", "--allow-empty", "-m", "Initial commit"},
+		} {
+			cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, output)
+			}
+		}
+		output, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("rev-parse: %v", err)
+		}
+
+		ctx := &ScaffoldContext{WorktreePath: dir}
+		snapshot := ctx.SnapshotForTemplate()
+
+		if want := string(output[:len(output)-1]); snapshot["CommitShort"] != want {
+			t.Errorf("expected CommitShort %q, got %q", want, snapshot["CommitShort"])
+		}
+		if snapshot["Author"] != "Test User" {
+			t.Errorf("expected Author Test User, got %q", snapshot["Author"])
+		}
+		if snapshot["RemoteURL"] != "git@github.com:acme/shop.git" {
+			t.Errorf("expected origin URL, got %q", snapshot["RemoteURL"])
+		}
+		if snapshot["DefaultBranch"] != "main" {
+			t.Errorf("expected DefaultBranch main, got %q", snapshot["DefaultBranch"])
+		}
+	})
+
+	t.Run("facts are empty outside a repository", func(t *testing.T) {
+		ctx := &ScaffoldContext{WorktreePath: t.TempDir()}
+		snapshot := ctx.SnapshotForTemplate()
+
+		for _, key := range []string{"CommitShort", "Author", "RemoteURL", "DefaultBranch"} {
+			value, ok := snapshot[key]
+			if !ok {
+				t.Errorf("expected %s to be defined", key)
+			}
+			if value != "" {
+				t.Errorf("expected empty %s, got %q", key, value)
+			}
+		}
+	})
+
+	t.Run("custom variables take precedence", func(t *testing.T) {
+		ctx := &ScaffoldContext{Vars: map[string]string{"Author": "release-bot"}}
+		if got := ctx.SnapshotForTemplate()["Author"]; got != "release-bot" {
+			t.Errorf("expected release-bot, got %q", got)
+		}
+	})
+}
+
+func TestScaffoldContext_EnvFileEquals(t *testing.T) {
+	tmpDir := t.TempDir()
+	envContent := "DB_CONNECTION=mysql\nDB_PORT=3306\nAPP_DEBUG=true\nQUEUE_CONNECTION=\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, ".env"), []byte(envContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &ScaffoldContext{WorktreePath: tmpDir}
+
+	tests := []struct {
+		name      string
+		condition map[string]interface{}
+		want      bool
+	}{
+		{"value matches", map[string]interface{}{"key": "DB_CONNECTION", "value": "mysql"}, true},
+		{"value differs", map[string]interface{}{"key": "DB_CONNECTION", "value": "pgsql"}, false},
+		{"equals matches", map[string]interface{}{"key": "DB_CONNECTION", "equals": "mysql"}, true},
+		{"matches pattern", map[string]interface{}{"key": "DB_CONNECTION", "matches": "^(mysql|mariadb)$"}, true},
+		{"pattern does not match", map[string]interface{}{"key": "DB_CONNECTION", "matches": "^pg"}, false},
+		{"in list", map[string]interface{}{"key": "DB_CONNECTION", "in": []interface{}{"mysql", "mariadb"}}, true},
+		{"not in list", map[string]interface{}{"key": "DB_CONNECTION", "in": []interface{}{"pgsql", "sqlite"}}, false},
+		{"unquoted number", map[string]interface{}{"key": "DB_PORT", "value": 3306}, true},
+		{"unquoted bool", map[string]interface{}{"key": "APP_DEBUG", "value": true}, true},
+		{"numbers in list", map[string]interface{}{"key": "DB_PORT", "in": []interface{}{3306, 3307}}, true},
+		{"all comparisons must hold", map[string]interface{}{"key": "DB_CONNECTION", "value": "mysql", "in": []interface{}{"pgsql"}}, false},
+		{"empty value", map[string]interface{}{"key": "QUEUE_CONNECTION", "value": ""}, true},
+		{"no comparison requires empty value", map[string]interface{}{"key": "DB_CONNECTION"}, false},
+		{"missing key", map[string]interface{}{"key": "REDIS_HOST", "value": ""}, false},
+		{"missing file", map[string]interface{}{"file": ".env.testing", "key": "DB_CONNECTION", "value": "mysql"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ctx.EvaluateCondition(map[string]interface{}{"env_file_equals": tt.condition})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result)
+			}
+		})
+	}
+
+	t.Run("invalid pattern is an error", func(t *testing.T) {
+		_, err := ctx.EvaluateCondition(map[string]interface{}{
+			"env_file_equals": map[string]interface{}{"key": "DB_CONNECTION", "matches": "("},
+		})
+		if err == nil || !strings.Contains(err.Error(), "env_file_equals: invalid matches pattern") {
+			t.Errorf("expected invalid pattern error, got %v", err)
+		}
+	})
+
+	t.Run("uses the project's env file", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(tmpDir, ".env.local"), []byte("DB_CONNECTION=pgsql\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		localCtx := &ScaffoldContext{WorktreePath: tmpDir, EnvFile: ".env.local"}
+		result, err := localCtx.EvaluateCondition(map[string]interface{}{
+			"env_file_equals": map[string]interface{}{"key": "DB_CONNECTION", "value": "pgsql"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result {
+			t.Error("expected true when the project's env file has the value")
+		}
+	})
+}
+
+func TestScaffoldContext_ContextVarComparisons(t *testing.T) {
+	ctx := &ScaffoldContext{Vars: map[string]string{"Driver": "mariadb"}}
+
+	tests := []struct {
+		name      string
+		condition map[string]interface{}
+		want      bool
+	}{
+		{"matches pattern", map[string]interface{}{"key": "Driver", "matches": "^maria"}, true},
+		{"in list", map[string]interface{}{"key": "Driver", "in": []interface{}{"mysql", "mariadb"}}, true},
+		{"not in list", map[string]interface{}{"key": "Driver", "in": []interface{}{"pgsql"}}, false},
+		{"equals", map[string]interface{}{"key": "Driver", "equals": "mariadb"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ctx.EvaluateCondition(map[string]interface{}{"context_var": tt.condition})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestScaffoldContext_GitFactConditions(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"remote", "add", "origin", "git@github.com:acme/shop.git"},
+		{"remote", "add", "upstream", "git@github.com:vendor/shop.git"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ctx       *ScaffoldContext
+		condition map[string]interface{}
+		want      bool
+	}{
+		{"branch_matches pattern", &ScaffoldContext{Branch: "hotfix/login"}, map[string]interface{}{"branch_matches": "hotfix/*"}, true},
+		{"branch_matches does not cross slashes", &ScaffoldContext{Branch: "hotfix/ui/login"}, map[string]interface{}{"branch_matches": "hotfix/*"}, false},
+		{"branch_matches any of a list", &ScaffoldContext{Branch: "release/2.0"}, map[string]interface{}{"branch_matches": []interface{}{"hotfix/*", "release/*"}}, true},
+		{"branch_matches no match", &ScaffoldContext{Branch: "feature/login"}, map[string]interface{}{"branch_matches": "hotfix/*"}, false},
+		{"is_default_branch on main", &ScaffoldContext{WorktreePath: dir, Branch: "main"}, map[string]interface{}{"is_default_branch": true}, true},
+		{"is_default_branch on a feature branch", &ScaffoldContext{WorktreePath: dir, Branch: "feature/login"}, map[string]interface{}{"is_default_branch": true}, false},
+		{"is_default_branch false on a feature branch", &ScaffoldContext{WorktreePath: dir, Branch: "feature/login"}, map[string]interface{}{"is_default_branch": false}, true},
+		{"is_default_branch uses configured default", &ScaffoldContext{WorktreePath: dir, Branch: "develop", DefaultBranch: "develop"}, map[string]interface{}{"is_default_branch": true}, true},
+		{"remote_exists", &ScaffoldContext{WorktreePath: dir}, map[string]interface{}{"remote_exists": "upstream"}, true},
+		{"remote_exists all of a list", &ScaffoldContext{WorktreePath: dir}, map[string]interface{}{"remote_exists": []interface{}{"origin", "upstream"}}, true},
+		{"remote_exists missing remote", &ScaffoldContext{WorktreePath: dir}, map[string]interface{}{"remote_exists": []interface{}{"origin", "fork"}}, false},
+		{"remote_exists outside a repository", &ScaffoldContext{WorktreePath: t.TempDir()}, map[string]interface{}{"remote_exists": "origin"}, false},
+		{"negated", &ScaffoldContext{WorktreePath: dir, Branch: "main"}, map[string]interface{}{"not": map[string]interface{}{"is_default_branch": true}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.ctx.EvaluateCondition(tt.condition)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result)
+			}
+		})
+	}
+
+	t.Run("invalid values are errors", func(t *testing.T) {
+		ctx := &ScaffoldContext{Branch: "main"}
+		for _, condition := range []map[string]interface{}{
+			{"branch_matches": "hotfix/["},
+			{"is_default_branch": "yes"},
+		} {
+			if _, err := ctx.EvaluateCondition(condition); err == nil {
+				t.Errorf("expected an error for %v", condition)
+			}
+		}
+	})
+}
+
+func TestScaffoldContext_Profile(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []string
+		value    interface{}
+		want     bool
+	}{
+		{"enabled profile", []string{"testing"}, "testing", true},
+		{"profile not enabled", []string{"demo"}, "testing", false},
+		{"no profiles", nil, "testing", false},
+		{"any of a list", []string{"demo"}, []interface{}{"testing", "demo"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &ScaffoldContext{Profiles: tt.profiles}
+			result, err := ctx.EvaluateCondition(map[string]interface{}{"profile": tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestScaffoldContext_RecordFileChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	envPath := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envPath, []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ScaffoldContext{WorktreePath: tmpDir}
+	ctx.RecordFileChange(envPath)
+	if err := os.WriteFile(envPath, []byte("A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx.RecordFileChange(envPath)
+	ctx.RecordFileChange(filepath.Join(tmpDir, "config", "local.php"))
+	ctx.RecordFileChange(filepath.Join(filepath.Dir(tmpDir), "outside"))
+
+	changes := ctx.FileChanges()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Path != ".env" || !changes[0].Existed || string(changes[0].Content) != "A=1\n" {
+		t.Errorf("expected .env as first recorded, got %+v", changes[0])
+	}
+	if changes[1].Path != "config/local.php" || changes[1].Existed {
+		t.Errorf("expected config/local.php as not existing, got %+v", changes[1])
+	}
+}
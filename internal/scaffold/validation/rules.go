@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/artisanexperiences/arbor/internal/config"
 )
@@ -151,6 +153,11 @@ func NewFileCopyValidator() *Validator {
 			Field:     "to",
 			GetValue:  func(c config.StepConfig) string { return c.To },
 			FieldName: "to",
+		}).
+		AddRule(OneOf{
+			GetValue:  func(c config.StepConfig) string { return c.EOL },
+			FieldName: "eol",
+			Allowed:   config.EOLs,
 		})
 }
 
@@ -161,6 +168,11 @@ func NewBashRunValidator() *Validator {
 			Field:     "command",
 			GetValue:  func(cfg config.StepConfig) string { return cfg.Command },
 			FieldName: "command",
+		}).
+		AddRule(OneOf{
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Shell },
+			FieldName: "shell",
+			Allowed:   config.Shells,
 		})
 }
 
@@ -174,6 +186,60 @@ func NewCommandRunValidator() *Validator {
 		})
 }
 
+// NewGitConfigValidator creates a validator for git.config step.
+func NewGitConfigValidator() *Validator {
+	return NewValidator("git.config").
+		AddRule(RequiredField{
+			Field:     "key",
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Key },
+			FieldName: "key",
+		}).
+		AddRule(RequiredField{
+			Field:     "value",
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Value },
+			FieldName: "value",
+		})
+}
+
+// NewGitHooksValidator creates a validator for git.hooks step.
+func NewGitHooksValidator() *Validator {
+	return NewValidator("git.hooks").
+		AddRule(OneOf{
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Type },
+			FieldName: "type",
+			Allowed:   []string{config.GitHooksDirectory, config.GitHooksHusky, config.GitHooksLefthook},
+		})
+}
+
+// NewComposerLinkValidator creates a validator for composer.link step.
+func NewComposerLinkValidator() *Validator {
+	return NewValidator("composer.link").
+		AddRule(NotEmpty{
+			GetValue:  func(cfg config.StepConfig) []string { return cfg.Paths },
+			FieldName: "paths",
+		})
+}
+
+// NewNodeLinkValidator creates a validator for node.link and node.unlink
+// steps.
+func NewNodeLinkValidator(name string) *Validator {
+	return NewValidator(name).
+		AddRule(CustomRule{
+			Name: "packages",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if len(cfg.Packages) == 0 {
+					return fmt.Errorf("field %q must not be empty", "packages")
+				}
+				return nil
+			},
+		}).
+		AddRule(OneOf{
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Type },
+			FieldName: "type",
+			Allowed:   []string{config.NodeLinkNpm, config.NodeLinkYarn},
+		})
+}
+
 // NewEnvReadValidator creates a validator for env.read step.
 func NewEnvReadValidator() *Validator {
 	return NewValidator("env.read").
@@ -191,6 +257,25 @@ func NewEnvWriteValidator() *Validator {
 			Field:     "key",
 			GetValue:  func(cfg config.StepConfig) string { return cfg.Key },
 			FieldName: "key",
+		}).
+		AddRule(OneOf{
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Mode },
+			FieldName: "mode",
+			Allowed:   []string{config.EnvWriteModeUpsert, config.EnvWriteModeUpdateOnly, config.EnvWriteModeAppendIfMissing},
+		})
+}
+
+// NewEnvUnsetValidator creates a validator for env.unset step.
+func NewEnvUnsetValidator() *Validator {
+	return NewValidator("env.unset").
+		AddRule(CustomRule{
+			Name: "key_or_keys",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if cfg.Key == "" && len(cfg.Keys) == 0 {
+					return fmt.Errorf("either \"key\" or \"keys\" must be specified")
+				}
+				return nil
+			},
 		})
 }
 
@@ -212,3 +297,79 @@ func NewEnvCopyValidator() *Validator {
 			},
 		})
 }
+
+// NewEnvCopyFromMainValidator creates a validator for env.copy_from_main
+// step.
+func NewEnvCopyFromMainValidator() *Validator {
+	return NewValidator("env.copy_from_main").
+		AddRule(CustomRule{
+			Name: "key_or_keys",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if cfg.Key == "" && len(cfg.Keys) == 0 {
+					return fmt.Errorf("either \"key\" or \"keys\" must be specified")
+				}
+				return nil
+			},
+		})
+}
+
+// NewMailCatcherValidator creates a validator for mail.catcher step.
+func NewMailCatcherValidator() *Validator {
+	return NewValidator("mail.catcher").
+		AddRule(CustomRule{
+			Name: "port",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if cfg.Port < 0 || cfg.Port > 65535 {
+					return fmt.Errorf("field %q must be a port number, got %d", "port", cfg.Port)
+				}
+				return nil
+			},
+		}).
+		AddRule(CustomRule{
+			Name: "host",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if strings.ContainsAny(cfg.Host, " \t/") {
+					return fmt.Errorf("field %q must be a host name or address, got %q", "host", cfg.Host)
+				}
+				return nil
+			},
+		})
+}
+
+// NewSchedulerValidator creates a validator for scheduler.register and
+// scheduler.unregister steps.
+func NewSchedulerValidator(name string) *Validator {
+	return NewValidator(name).
+		AddRule(OneOf{
+			GetValue:  func(cfg config.StepConfig) string { return cfg.Type },
+			FieldName: "type",
+			Allowed:   []string{config.SchedulerLaunchd, config.SchedulerSystemd, config.SchedulerCron},
+		})
+}
+
+// NewLaravelPermissionsValidator creates a validator for laravel.permissions
+// step.
+func NewLaravelPermissionsValidator() *Validator {
+	return NewValidator("laravel.permissions").
+		AddRule(CustomRule{
+			Name: "paths",
+			ValidateFn: func(cfg config.StepConfig) error {
+				for _, path := range cfg.Paths {
+					clean := filepath.ToSlash(filepath.Clean(path))
+					if filepath.IsAbs(path) || strings.HasPrefix(path, "/") || clean == ".." || strings.HasPrefix(clean, "../") {
+						return fmt.Errorf("field %q must list directories inside the worktree, got %q", "paths", path)
+					}
+				}
+				return nil
+			},
+		}).
+		AddRule(CustomRule{
+			Name: "owner",
+			ValidateFn: func(cfg config.StepConfig) error {
+				if strings.HasPrefix(cfg.Owner, "-") || strings.ContainsAny(cfg.Owner, ":\n") {
+					return fmt.Errorf("field %q must be a user name, got %q", "owner", cfg.Owner)
+				}
+				return nil
+			},
+		})
+}
@@ -44,22 +44,29 @@ func (v *Validator) AddRule(rule Rule) *Validator {
 // It collects all errors and returns them joined together using errors.Join.
 // Returns nil if all rules pass.
 func (v *Validator) Validate(cfg config.StepConfig) error {
-	if len(v.Rules) == 0 {
-		return nil
+	if errs := v.Problems(cfg); len(errs) > 0 {
+		return fmt.Errorf("validating step %q: %w", v.StepName, errors.Join(errs...))
 	}
+	return nil
+}
 
+// Problems runs all validation rules and returns one error per problem,
+// unwrapping the joined errors of rules such as RequiredFields. The errors
+// do not name the step, so callers can say where it is defined instead.
+func (v *Validator) Problems(cfg config.StepConfig) []error {
 	var errs []error
 	for _, rule := range v.Rules {
-		if err := rule.Validate(cfg); err != nil {
+		err := rule.Validate(cfg)
+		if err == nil {
+			continue
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = append(errs, joined.Unwrap()...)
+		} else {
 			errs = append(errs, err)
 		}
 	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("validating step %q: %w", v.StepName, errors.Join(errs...))
-	}
-
-	return nil
+	return errs
 }
 
 // ValidateFirst fails fast on the first validation error.
@@ -73,6 +80,23 @@ func (v *Validator) ValidateFirst(cfg config.StepConfig) error {
 	return nil
 }
 
+// RequiredFields returns the config keys the validator's RequiredField
+// rules require, in rule order.
+func (v *Validator) RequiredFields() []string {
+	var fields []string
+	for _, rule := range v.Rules {
+		switch r := rule.(type) {
+		case RequiredField:
+			fields = append(fields, r.Field)
+		case RequiredFields:
+			for _, field := range r.Fields {
+				fields = append(fields, field.Field)
+			}
+		}
+	}
+	return fields
+}
+
 // HasRules returns true if the validator has any rules registered.
 func (v *Validator) HasRules() bool {
 	return len(v.Rules) > 0
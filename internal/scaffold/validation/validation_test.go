@@ -435,3 +435,35 @@ func TestStepValidators(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestValidator_RequiredFields(t *testing.T) {
+	if got := NewFileCopyValidator().RequiredFields(); strings.Join(got, ",") != "from,to" {
+		t.Errorf("expected from,to, got %v", got)
+	}
+	if got := NewEnvUnsetValidator().RequiredFields(); len(got) != 0 {
+		t.Errorf("custom rules require no named field, got %v", got)
+	}
+}
+
+func TestValidator_Problems(t *testing.T) {
+	v := NewValidator("test.step").
+		AddRule(RequiredFields{Fields: []RequiredField{
+			{Field: "from", GetValue: func(c config.StepConfig) string { return c.From }},
+			{Field: "to", GetValue: func(c config.StepConfig) string { return c.To }},
+		}}).
+		AddRule(OneOf{GetValue: func(c config.StepConfig) string { return c.Mode }, FieldName: "mode", Allowed: []string{"replace"}})
+
+	problems := v.Problems(config.StepConfig{Mode: "merge"})
+	if len(problems) != 3 {
+		t.Fatalf("expected joined rule errors to be split into 3 problems, got %d: %v", len(problems), problems)
+	}
+	for _, err := range problems {
+		if strings.Contains(err.Error(), "test.step") {
+			t.Errorf("problems should not name the step, got %q", err)
+		}
+	}
+
+	if problems := v.Problems(config.StepConfig{From: "a", To: "b"}); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
@@ -0,0 +1,146 @@
+package words
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+// Naming styles for generated suffixes.
+const (
+	StyleWords   = "words"
+	StyleNumeric = "numeric"
+	StyleHash    = "hash"
+)
+
+// Database naming modes. Random suffixes follow the naming config; branch
+// suffixes are the sanitized branch name.
+const (
+	DbNamingRandom = "random"
+	DbNamingBranch = "branch"
+)
+
+// branchHashLength is the length of the hash BranchSuffix appends.
+const branchHashLength = 6
+
+// Default lengths for the numeric and hash styles.
+const (
+	DefaultNumericLength = 6
+	DefaultHashLength    = 8
+)
+
+// GenerateSuffixFor generates a suffix following a project's naming config.
+// branch seeds the hash style; the other styles ignore it.
+func GenerateSuffixFor(naming config.NamingConfig, branch string) (string, error) {
+	switch naming.Style {
+	case "", StyleWords:
+		return wordsSuffix(naming)
+	case StyleNumeric:
+		return numericSuffix(suffixLength(naming.SuffixLength, DefaultNumericLength))
+	case StyleHash:
+		sum := sha256.Sum256([]byte(branch))
+		return hex.EncodeToString(sum[:])[:suffixLength(naming.SuffixLength, DefaultHashLength)], nil
+	default:
+		return "", fmt.Errorf("unknown naming style %q (expected %s, %s or %s)", naming.Style, StyleWords, StyleNumeric, StyleHash)
+	}
+}
+
+// suffixLength returns length, or fallback when unset, capped so the
+// suffix fits in SuffixMaxLength.
+func suffixLength(length, fallback int) int {
+	if length <= 0 {
+		length = fallback
+	}
+	return min(length, SuffixMaxLength)
+}
+
+func wordsSuffix(naming config.NamingConfig) (string, error) {
+	adjectives := wordList(naming.Adjectives, Adjectives)
+	nouns := wordList(naming.Nouns, Nouns)
+
+	adjective, err := pick(adjectives)
+	if err != nil {
+		return "", err
+	}
+	noun, err := pick(nouns)
+	if err != nil {
+		return "", err
+	}
+
+	suffix := truncateWord(adjective, naming.PrefixLength) + "_" + truncateWord(noun, naming.SuffixLength)
+	if len(suffix) > SuffixMaxLength {
+		suffix = strings.TrimRight(suffix[:SuffixMaxLength], "_")
+	}
+	return suffix, nil
+}
+
+// wordList sanitizes configured words so they are safe in database names,
+// falling back to the built-in list when none are usable.
+func wordList(configured, fallback []string) []string {
+	var list []string
+	for _, word := range configured {
+		// Underscores separate the adjective from the noun.
+		if word = strings.ReplaceAll(SanitizeSiteName(word), "_", ""); word != "" {
+			list = append(list, word)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
+
+func truncateWord(word string, length int) string {
+	if length > 0 && len(word) > length {
+		return word[:length]
+	}
+	return word
+}
+
+func pick(list []string) (string, error) {
+	n, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(len(list))))
+	if err != nil {
+		return "", fmt.Errorf("generating suffix: %w", err)
+	}
+	return list[n.Int64()], nil
+}
+
+func numericSuffix(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := cryptorand.Int(cryptorand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("generating suffix: %w", err)
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// BranchSuffix returns a suffix derived from a branch name: the branch
+// sanitized for database names, e.g. feature/auth becomes feature_auth.
+// Branches too long for SuffixMaxLength are truncated and end in a short
+// hash of the full name so branches sharing a prefix stay distinct.
+// withHash always appends the hash, for branches whose plain suffix is
+// already taken by another branch.
+func BranchSuffix(branch string, withHash bool) string {
+	suffix := SanitizeSiteName(branch)
+	if suffix != "" && !withHash && len(suffix) <= SuffixMaxLength {
+		return suffix
+	}
+
+	sum := sha256.Sum256([]byte(branch))
+	hash := hex.EncodeToString(sum[:])[:branchHashLength]
+	if maxLen := SuffixMaxLength - branchHashLength - 1; len(suffix) > maxLen {
+		suffix = strings.TrimRight(suffix[:maxLen], "_")
+	}
+	if suffix == "" {
+		return hash
+	}
+	return suffix + "_" + hash
+}
@@ -0,0 +1,162 @@
+package words
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestGenerateSuffixFor(t *testing.T) {
+	t.Run("defaults to adjective_noun", func(t *testing.T) {
+		suffix, err := GenerateSuffixFor(config.NamingConfig{}, "feature/auth")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ExtractSuffix("app_"+suffix) != suffix {
+			t.Errorf("expected a built-in adjective_noun suffix, got %q", suffix)
+		}
+	})
+
+	t.Run("uses custom word lists", func(t *testing.T) {
+		naming := config.NamingConfig{Adjectives: []string{"Red-Hot"}, Nouns: []string{"oak"}}
+		suffix, err := GenerateSuffixFor(naming, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if suffix != "redhot_oak" {
+			t.Errorf("expected redhot_oak, got %q", suffix)
+		}
+	})
+
+	t.Run("falls back to built-in lists when custom words are unusable", func(t *testing.T) {
+		suffix, err := GenerateSuffixFor(config.NamingConfig{Nouns: []string{"--"}}, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ExtractSuffix("app_"+suffix) != suffix {
+			t.Errorf("expected a built-in suffix, got %q", suffix)
+		}
+	})
+
+	t.Run("truncates words to prefix and suffix length", func(t *testing.T) {
+		naming := config.NamingConfig{
+			Adjectives:   []string{"diligent"},
+			Nouns:        []string{"processor"},
+			PrefixLength: 3,
+			SuffixLength: 4,
+		}
+		suffix, err := GenerateSuffixFor(naming, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if suffix != "dil_proc" {
+			t.Errorf("expected dil_proc, got %q", suffix)
+		}
+	})
+
+	t.Run("numeric style generates digits", func(t *testing.T) {
+		suffix, err := GenerateSuffixFor(config.NamingConfig{Style: StyleNumeric}, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !regexp.MustCompile(`^[0-9]{6}$`).MatchString(suffix) {
+			t.Errorf("expected 6 digits, got %q", suffix)
+		}
+
+		suffix, _ = GenerateSuffixFor(config.NamingConfig{Style: StyleNumeric, SuffixLength: 100}, "")
+		if len(suffix) != SuffixMaxLength {
+			t.Errorf("expected length capped at %d, got %d", SuffixMaxLength, len(suffix))
+		}
+	})
+
+	t.Run("hash style is deterministic per branch", func(t *testing.T) {
+		naming := config.NamingConfig{Style: StyleHash}
+		first, err := GenerateSuffixFor(naming, "feature/auth")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		second, _ := GenerateSuffixFor(naming, "feature/auth")
+		other, _ := GenerateSuffixFor(naming, "feature/billing")
+
+		if first != second {
+			t.Errorf("expected the same suffix for the same branch, got %q and %q", first, second)
+		}
+		if first == other {
+			t.Errorf("expected different suffixes for different branches, got %q", first)
+		}
+		if !regexp.MustCompile(`^[0-9a-f]{8}$`).MatchString(first) {
+			t.Errorf("expected 8 hex characters, got %q", first)
+		}
+
+		short, _ := GenerateSuffixFor(config.NamingConfig{Style: StyleHash, SuffixLength: 4}, "feature/auth")
+		if !strings.HasPrefix(first, short) || len(short) != 4 {
+			t.Errorf("expected a 4 character prefix of %q, got %q", first, short)
+		}
+	})
+
+	t.Run("rejects unknown styles", func(t *testing.T) {
+		if _, err := GenerateSuffixFor(config.NamingConfig{Style: "uuid"}, ""); err == nil {
+			t.Error("expected an error for an unknown style")
+		}
+	})
+}
+
+func TestBranchSuffix(t *testing.T) {
+	t.Run("sanitizes the branch name", func(t *testing.T) {
+		if got := BranchSuffix("feature/Auth-Login", false); got != "feature_auth_login" {
+			t.Errorf("expected feature_auth_login, got %q", got)
+		}
+	})
+
+	t.Run("truncates long branches with a hash", func(t *testing.T) {
+		a := BranchSuffix("feature/very-long-branch-name-one", false)
+		b := BranchSuffix("feature/very-long-branch-name-two", false)
+		if len(a) > SuffixMaxLength || len(b) > SuffixMaxLength {
+			t.Errorf("expected suffixes within %d characters, got %q and %q", SuffixMaxLength, a, b)
+		}
+		if a == b {
+			t.Errorf("expected distinct suffixes for distinct branches, got %q", a)
+		}
+		if a != BranchSuffix("feature/very-long-branch-name-one", false) {
+			t.Error("expected the same suffix for the same branch")
+		}
+	})
+
+	t.Run("appends a hash on request", func(t *testing.T) {
+		a := BranchSuffix("feature/a-b", true)
+		b := BranchSuffix("feature/a_b", true)
+		if !regexp.MustCompile(`^feature_a_b_[0-9a-f]{6}$`).MatchString(a) {
+			t.Errorf("expected feature_a_b_<hash>, got %q", a)
+		}
+		if a == b {
+			t.Errorf("expected branches that sanitize alike to differ, got %q", a)
+		}
+	})
+
+	t.Run("branches without usable characters become a hash", func(t *testing.T) {
+		if got := BranchSuffix("---", false); !regexp.MustCompile(`^[0-9a-f]{6}$`).MatchString(got) {
+			t.Errorf("expected a hash, got %q", got)
+		}
+	})
+}
+
+func BenchmarkGenerateSuffixFor(b *testing.B) {
+	for _, style := range []string{StyleWords, StyleNumeric, StyleHash} {
+		b.Run(style, func(b *testing.B) {
+			naming := config.NamingConfig{Style: style}
+			for i := 0; i < b.N; i++ {
+				if _, err := GenerateSuffixFor(naming, "feature/auth"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("branch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = BranchSuffix("feature/JIRA-1234-add-oauth-login", false)
+		}
+	})
+}
@@ -2,7 +2,9 @@ package words
 
 import (
 	cryptorand "crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"regexp"
@@ -33,10 +35,27 @@ var Nouns = []string{
 }
 
 const (
+	// MaxDbNameLength is PostgreSQL's identifier limit, the stricter of
+	// the supported engines.
 	MaxDbNameLength = 63
-	SuffixMaxLength = 25
+	// MaxMySQLDbNameLength is MySQL's database name limit.
+	MaxMySQLDbNameLength = 64
+	SuffixMaxLength      = 25
 )
 
+// dbNameHashLength is the length of the hash DatabaseName inserts into
+// truncated names.
+const dbNameHashLength = 4
+
+// MaxIdentifierLength returns the database name limit for an engine
+// ("mysql" or "pgsql").
+func MaxIdentifierLength(engine string) int {
+	if engine == "mysql" {
+		return MaxMySQLDbNameLength
+	}
+	return MaxDbNameLength
+}
+
 func GenerateSuffix() string {
 	bytes := make([]byte, 4)
 	if _, err := cryptorand.Read(bytes); err != nil {
@@ -60,20 +79,36 @@ func SanitizeSiteName(name string) string {
 }
 
 func GenerateDatabaseName(siteName string, maxLength int) string {
+	return DatabaseName(siteName, GenerateSuffix(), maxLength)
+}
+
+// DatabaseName returns the database name for a site and suffix:
+// sanitized_site_suffix. Names longer than maxLength (MaxDbNameLength when
+// 0) have the site part truncated and a short hash of the full name
+// inserted before the suffix, so long site names that share a prefix still
+// get distinct databases.
+func DatabaseName(siteName, suffix string, maxLength int) string {
 	if maxLength == 0 {
 		maxLength = MaxDbNameLength
 	}
 
-	sanitized := SanitizeSiteName(siteName)
-	suffix := GenerateSuffix()
+	name := fmt.Sprintf("%s_%s", SanitizeSiteName(siteName), suffix)
+	if len(name) <= maxLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:dbNameHashLength]
 
-	maxSiteLen := maxLength - len(suffix) - 1
-	if len(sanitized) > maxSiteLen {
-		sanitized = sanitized[:maxSiteLen]
-		sanitized = strings.TrimRight(sanitized, "_")
+	maxSiteLen := maxLength - len(suffix) - len(hash) - 2
+	if maxSiteLen <= 0 {
+		// The suffix alone does not fit; keep as much of the name as the
+		// hash leaves room for.
+		return strings.TrimRight(name[:maxLength-len(hash)-1], "_") + "_" + hash
 	}
 
-	return fmt.Sprintf("%s_%s", sanitized, suffix)
+	site := strings.TrimRight(SanitizeSiteName(siteName)[:maxSiteLen], "_")
+	return fmt.Sprintf("%s_%s_%s", site, hash, suffix)
 }
 
 func ExtractSuffix(dbName string) string {
@@ -208,6 +208,61 @@ func TestMaxLengthEnforcement(t *testing.T) {
 	})
 }
 
+func TestDatabaseName(t *testing.T) {
+	t.Run("joins sanitized site and suffix", func(t *testing.T) {
+		if got := DatabaseName("My-App", "swift_runner", 0); got != "my_app_swift_runner" {
+			t.Errorf("expected my_app_swift_runner, got %q", got)
+		}
+	})
+
+	t.Run("truncates long site names with a hash", func(t *testing.T) {
+		site := strings.Repeat("enterprise_resource_planning_", 3)
+		a := DatabaseName(site+"billing", "swift_runner", 0)
+		b := DatabaseName(site+"payroll", "swift_runner", 0)
+
+		for _, name := range []string{a, b} {
+			if len(name) > MaxDbNameLength {
+				t.Errorf("expected at most %d characters, got %d (%q)", MaxDbNameLength, len(name), name)
+			}
+			if !strings.HasPrefix(name, "enterprise_resource") || !strings.HasSuffix(name, "_swift_runner") {
+				t.Errorf("expected truncated site and intact suffix, got %q", name)
+			}
+			if ExtractSuffix(name) != "swift_runner" {
+				t.Errorf("expected suffix to be extractable from %q", name)
+			}
+		}
+		if a == b {
+			t.Errorf("expected distinct names for distinct sites, got %q", a)
+		}
+		if a != DatabaseName(site+"billing", "swift_runner", 0) {
+			t.Error("expected the same name for the same site and suffix")
+		}
+	})
+
+	t.Run("respects the MySQL limit", func(t *testing.T) {
+		name := DatabaseName(strings.Repeat("a", 80), "swift_runner", MaxIdentifierLength("mysql"))
+		if len(name) != MaxMySQLDbNameLength {
+			t.Errorf("expected %d characters, got %d", MaxMySQLDbNameLength, len(name))
+		}
+	})
+
+	t.Run("fits suffixes longer than the limit", func(t *testing.T) {
+		name := DatabaseName("app", strings.Repeat("x", 40), 30)
+		if len(name) > 30 {
+			t.Errorf("expected at most 30 characters, got %d (%q)", len(name), name)
+		}
+	})
+}
+
+func TestMaxIdentifierLength(t *testing.T) {
+	if got := MaxIdentifierLength("mysql"); got != 64 {
+		t.Errorf("expected 64 for mysql, got %d", got)
+	}
+	if got := MaxIdentifierLength("pgsql"); got != 63 {
+		t.Errorf("expected 63 for pgsql, got %d", got)
+	}
+}
+
 func TestWordListsSafety(t *testing.T) {
 	t.Run("adjectives are lowercase", func(t *testing.T) {
 		for _, adj := range Adjectives {
@@ -0,0 +1,229 @@
+// Package templates generates brand-new projects for `arbor init --template`.
+//
+// A template is a shell command that creates a project at a given path, such
+// as `laravel new` or `composer create-project`. The generated files become
+// the initial commit of a fresh bare repository and its default worktree.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"text/template"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// Template is a named project generator.
+type Template struct {
+	Name        string
+	Description string
+	// Command is run with sh -c (bash -c on Windows) after rendering
+	// {{ .Path }} and {{ .Name }}, which are shell-quoted.
+	Command string
+	// Preset is written to the starter arbor.yaml.
+	Preset string
+}
+
+// Vars are the values available to a template command.
+type Vars struct {
+	// Path is the absolute directory the project must be created in.
+	Path string
+	// Name is the project's site name.
+	Name string
+}
+
+var builtin = map[string]Template{
+	"laravel-new": {
+		Description: "Laravel application via the Laravel installer",
+		Command:     "laravel new {{ .Path }}",
+		Preset:      "laravel",
+	},
+	"laravel": {
+		Description: "Laravel application via composer create-project",
+		Command:     "composer create-project laravel/laravel {{ .Path }}",
+		Preset:      "laravel",
+	},
+}
+
+// Resolve returns the named template. Templates from the global config take
+// precedence over the built-in templates of the same name.
+func Resolve(name string, configured map[string]config.TemplateConfig) (Template, error) {
+	if t, ok := configured[name]; ok {
+		if t.Command == "" {
+			return Template{}, fmt.Errorf("template %q has no command", name)
+		}
+		return Template{Name: name, Description: t.Description, Command: t.Command, Preset: t.Preset}, nil
+	}
+	if t, ok := builtin[name]; ok {
+		t.Name = name
+		return t, nil
+	}
+	return Template{}, fmt.Errorf("unknown template %q (available: %v)", name, Names(configured))
+}
+
+// Names returns the names of the built-in and configured templates, sorted.
+func Names(configured map[string]config.TemplateConfig) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range builtin {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range configured {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render expands the template variables in the command. Their values are
+// shell-quoted, so a path with spaces or shell characters stays one word.
+func (t Template) Render(vars Vars) (string, error) {
+	vars = Vars{Path: utils.ShellQuote(vars.Path), Name: utils.ShellQuote(vars.Name)}
+	tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.Command)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q command: %w", t.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("rendering template %q command: %w", t.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// Options configures Generate.
+type Options struct {
+	// ProjectPath is the new project directory; it must not exist or be empty.
+	ProjectPath string
+	// Branch is the initial branch and the default worktree's folder name.
+	Branch   string
+	SiteName string
+	// Stdin, Stdout and Stderr are attached to the generator so it can prompt.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Generate runs the template's command and turns its output into an arbor
+// project: a bare repository at .bare whose initial commit holds the
+// generated files, checked out as the default worktree. Files ignored by the
+// generated .gitignore (vendor, node_modules, .env) are kept in the
+// worktree. It returns the default worktree's path.
+func Generate(t Template, opts Options) (string, error) {
+	if err := ensureEmptyDir(opts.ProjectPath); err != nil {
+		return "", err
+	}
+
+	// Generate into a staging directory named after the site so generators
+	// that derive names and URLs from the directory pick the project's name.
+	stagingRoot := filepath.Join(opts.ProjectPath, ".arbor", "template")
+	staging := filepath.Join(stagingRoot, opts.SiteName)
+	if err := os.MkdirAll(stagingRoot, 0755); err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(stagingRoot) }()
+
+	command, err := t.Render(Vars{Path: staging, Name: opts.SiteName})
+	if err != nil {
+		return "", err
+	}
+
+	shell, err := templateShell()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Dir = stagingRoot
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running template %q: %w", t.Name, err)
+	}
+
+	if info, err := os.Stat(staging); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("template %q did not create %s", t.Name, staging)
+	}
+
+	// Generators that run git init leave a repository behind; the project
+	// history starts from arbor's initial commit instead.
+	if err := os.RemoveAll(filepath.Join(staging, ".git")); err != nil {
+		return "", fmt.Errorf("removing generated .git: %w", err)
+	}
+
+	barePath := filepath.Join(opts.ProjectPath, ".bare")
+	if err := git.InitBare(barePath, opts.Branch); err != nil {
+		return "", err
+	}
+	if err := git.CommitAll(barePath, staging, "Initial commit"); err != nil {
+		return "", err
+	}
+
+	mainPath := filepath.Join(opts.ProjectPath, opts.Branch)
+	if err := git.AddWorktreeNoCheckout(barePath, mainPath, opts.Branch); err != nil {
+		return "", err
+	}
+	if err := moveContents(staging, mainPath); err != nil {
+		return "", err
+	}
+	if err := git.ResetIndex(mainPath); err != nil {
+		return "", err
+	}
+
+	return mainPath, nil
+}
+
+// templateShell returns the POSIX shell template commands run with: sh, or
+// on Windows the bash Git for Windows installs, as bash.run steps use.
+func templateShell() (string, error) {
+	shell := "sh"
+	if runtime.GOOS == "windows" {
+		shell = "bash"
+	}
+	if _, err := exec.LookPath(shell); err != nil {
+		return "", fmt.Errorf("template commands run with %s, which was not found: %w", shell, err)
+	}
+	return shell, nil
+}
+
+func ensureEmptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return os.MkdirAll(path, 0755)
+		}
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", path)
+	}
+	return nil
+}
+
+// moveContents moves every entry of src into dst, which already holds the
+// worktree's .git file.
+func moveContents(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("reading generated project: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := os.Rename(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return fmt.Errorf("moving %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
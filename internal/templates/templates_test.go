@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/config"
+)
+
+func TestResolve(t *testing.T) {
+	configured := map[string]config.TemplateConfig{
+		"laravel":  {Command: "custom {{ .Path }}", Preset: "php"},
+		"statamic": {Command: "statamic new {{ .Path }}", Preset: "laravel"},
+		"broken":   {},
+	}
+
+	tmpl, err := Resolve("laravel", configured)
+	require.NoError(t, err)
+	assert.Equal(t, "custom {{ .Path }}", tmpl.Command, "configured templates override built-ins")
+	assert.Equal(t, "php", tmpl.Preset)
+
+	tmpl, err = Resolve("laravel-new", configured)
+	require.NoError(t, err)
+	assert.Equal(t, "laravel-new", tmpl.Name)
+	assert.Equal(t, "laravel", tmpl.Preset)
+
+	_, err = Resolve("broken", configured)
+	assert.ErrorContains(t, err, "has no command")
+
+	_, err = Resolve("missing", configured)
+	assert.ErrorContains(t, err, "unknown template")
+}
+
+func TestNames(t *testing.T) {
+	names := Names(map[string]config.TemplateConfig{"statamic": {}, "laravel": {}})
+	assert.Equal(t, []string{"laravel", "laravel-new", "statamic"}, names)
+}
+
+func TestRender(t *testing.T) {
+	tmpl := Template{Name: "test", Command: "make {{ .Name }} in {{ .Path }}"}
+	got, err := tmpl.Render(Vars{Path: "/tmp/app", Name: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, "make app in /tmp/app", got)
+
+	got, err = tmpl.Render(Vars{Path: "/home/dev/My Projects/app; rm -rf ~", Name: "it's"})
+	require.NoError(t, err)
+	assert.Equal(t, `make 'it'\''s' in '/home/dev/My Projects/app; rm -rf ~'`, got, "values are shell-quoted")
+
+	_, err = Template{Name: "bad", Command: "{{ .Missing }}"}.Render(Vars{})
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	projectPath := filepath.Join(t.TempDir(), "My Projects", "myapp")
+	tmpl := Template{
+		Name: "fake",
+		// Simulates a generator that runs git init and writes ignored files.
+		Command: `mkdir -p {{ .Path }} && cd {{ .Path }} && git init -q && ` +
+			`echo {{ .Name }} > README.md && echo ".env" > .gitignore && echo "APP_KEY=x" > .env`,
+	}
+
+	mainPath, err := Generate(tmpl, Options{
+		ProjectPath: projectPath,
+		Branch:      "main",
+		SiteName:    "myapp",
+		Stdout:      io.Discard,
+		Stderr:      io.Discard,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(projectPath, "main"), mainPath)
+	assert.FileExists(t, filepath.Join(mainPath, ".env"), "ignored files are kept")
+	assert.FileExists(t, filepath.Join(mainPath, ".git"))
+	assert.NoDirExists(t, filepath.Join(projectPath, ".arbor", "template"))
+
+	readme, err := os.ReadFile(filepath.Join(mainPath, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "myapp\n", string(readme))
+
+	status, err := exec.Command("git", "-C", mainPath, "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(status)), "worktree is clean after generation")
+
+	files, err := exec.Command("git", "-C", mainPath, "ls-files").Output()
+	require.NoError(t, err)
+	assert.Equal(t, ".gitignore\nREADME.md\n", string(files))
+}
+
+func TestGenerate_NonEmptyDir(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "file"), nil, 0644))
+
+	_, err := Generate(Template{Name: "fake", Command: "true"}, Options{ProjectPath: projectPath, Branch: "main", SiteName: "app"})
+	assert.ErrorContains(t, err, "not empty")
+}
+
+func TestGenerate_NothingCreated(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "app")
+
+	_, err := Generate(Template{Name: "fake", Command: "true"}, Options{ProjectPath: projectPath, Branch: "main", SiteName: "app", Stdout: io.Discard, Stderr: io.Discard})
+	assert.ErrorContains(t, err, "did not create")
+}
@@ -0,0 +1,276 @@
+// Package trash keeps removed worktrees in a project-local trash so a
+// removal can be undone until the entry is purged.
+//
+// Each entry lives in <project>/.arbor/trash/<timestamp>-<branch>/ and holds
+// the worktree files under worktree/ alongside a metadata.json describing
+// where the worktree came from.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+const (
+	// DefaultRetentionDays is how long entries are kept when trash.retention_days is unset.
+	DefaultRetentionDays = 7
+
+	metadataFile = "metadata.json"
+	worktreeDir  = "worktree"
+	idTimeFormat = "20060102T150405Z"
+)
+
+// ErrEmpty is returned when there is nothing in the trash to restore.
+var ErrEmpty = errors.New("trash is empty")
+
+// Entry describes a trashed worktree.
+type Entry struct {
+	// ID is the entry's directory name within the trash.
+	ID        string    `json:"-"`
+	Branch    string    `json:"branch"`
+	Path      string    `json:"path"`
+	Commit    string    `json:"commit"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// Label returns the entry's branch, or "(detached at <commit>)" for a
+// detached worktree.
+func (e Entry) Label() string {
+	if e.Branch != "" {
+		return e.Branch
+	}
+	return git.Worktree{Detached: true, Head: e.Commit}.Label()
+}
+
+// Dir returns the trash directory for a project.
+func Dir(projectPath string) string {
+	return filepath.Join(projectPath, ".arbor", "trash")
+}
+
+// Move moves a worktree's files into the trash and unregisters the worktree
+// from git. The branch is left untouched; its commit is recorded so Restore
+// can recreate it if it is deleted afterwards.
+func Move(projectPath, barePath string, wt git.Worktree, now time.Time) (*Entry, error) {
+	commit, err := git.HeadCommit(wt.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		Branch:    wt.Branch,
+		Path:      wt.Path,
+		Commit:    commit,
+		RemovedAt: now.UTC(),
+	}
+
+	entryDir, err := reserveEntryDir(projectPath, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeMetadata(entryDir, entry); err != nil {
+		_ = os.RemoveAll(entryDir)
+		return nil, err
+	}
+
+	if err := os.Rename(wt.Path, filepath.Join(entryDir, worktreeDir)); err != nil {
+		_ = os.RemoveAll(entryDir)
+		return nil, fmt.Errorf("moving worktree to trash: %w", err)
+	}
+
+	if err := git.PruneWorktrees(barePath); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// reserveEntryDir creates a unique directory for entry and sets entry.ID.
+func reserveEntryDir(projectPath string, entry *Entry) (string, error) {
+	trashDir := Dir(projectPath)
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		return "", fmt.Errorf("creating trash directory: %w", err)
+	}
+
+	name := entry.Branch
+	if name == "" {
+		name = filepath.Base(entry.Path)
+	}
+	base := entry.RemovedAt.Format(idTimeFormat) + "-" + sanitizeBranch(name)
+	id := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(filepath.Join(trashDir, id), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("creating trash entry: %w", err)
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	entry.ID = id
+	return filepath.Join(trashDir, id), nil
+}
+
+func sanitizeBranch(branch string) string {
+	return strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(branch)
+}
+
+func writeMetadata(entryDir string, entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding trash metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(entryDir, metadataFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing trash metadata: %w", err)
+	}
+	return nil
+}
+
+// List returns the project's trash entries, most recently removed first.
+// Directories without readable metadata are skipped.
+func List(projectPath string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(Dir(projectPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading trash: %w", err)
+	}
+
+	var entries []Entry
+	for _, d := range dirEntries {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(Dir(projectPath), d.Name(), metadataFile))
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		entry.ID = d.Name()
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].RemovedAt.Equal(entries[j].RemovedAt) {
+			return entries[i].RemovedAt.After(entries[j].RemovedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// Find returns the entry with the given ID, or the most recent entry when
+// id is empty.
+func Find(projectPath, id string) (*Entry, error) {
+	entries, err := List(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+	if id == "" {
+		return &entries[0], nil
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("trash entry %q not found", id)
+}
+
+// Restore moves a trashed worktree back to its original path and links it
+// to git again, recreating the branch at the recorded commit if it no longer
+// exists. Uncommitted changes are kept as unstaged modifications.
+func Restore(projectPath, barePath string, entry Entry) error {
+	if _, err := os.Lstat(entry.Path); err == nil {
+		return fmt.Errorf("cannot restore %s: path already exists", entry.Path)
+	}
+
+	entryDir := filepath.Join(Dir(projectPath), entry.ID)
+	files := filepath.Join(entryDir, worktreeDir)
+	if _, err := os.Stat(files); err != nil {
+		return fmt.Errorf("trash entry %s has no worktree files: %w", entry.ID, err)
+	}
+
+	// Detached worktrees have no branch and are restored at their commit.
+	ref := entry.Commit
+	if entry.Branch != "" {
+		if !git.BranchExists(barePath, entry.Branch) {
+			if err := git.CreateBranch(barePath, entry.Branch, entry.Commit); err != nil {
+				return err
+			}
+		}
+		ref = entry.Branch
+	}
+
+	if err := git.AddWorktreeNoCheckout(barePath, entry.Path, ref); err != nil {
+		return err
+	}
+
+	children, err := os.ReadDir(files)
+	if err != nil {
+		return fmt.Errorf("reading trashed worktree: %w", err)
+	}
+	for _, child := range children {
+		// The trashed .git file points at worktree metadata git has pruned;
+		// keep the one just created by git worktree add.
+		if child.Name() == ".git" {
+			continue
+		}
+		if err := os.Rename(filepath.Join(files, child.Name()), filepath.Join(entry.Path, child.Name())); err != nil {
+			return fmt.Errorf("restoring %s: %w", child.Name(), err)
+		}
+	}
+
+	if err := git.ResetIndex(entry.Path); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(entryDir)
+}
+
+// Purge deletes entries removed more than retention ago and returns them.
+func Purge(projectPath string, retention time.Duration, now time.Time) ([]Entry, error) {
+	entries, err := List(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-retention)
+	var purged []Entry
+	for _, entry := range entries {
+		if !entry.RemovedAt.Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(Dir(projectPath), entry.ID)); err != nil {
+			return purged, fmt.Errorf("purging trash entry %s: %w", entry.ID, err)
+		}
+		purged = append(purged, entry)
+	}
+	return purged, nil
+}
+
+// Retention converts a retention in days to a duration, applying
+// DefaultRetentionDays when days is zero or negative.
+func Retention(days int) time.Duration {
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,180 @@
+package trash
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/artisanexperiences/arbor/internal/git"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+	return string(output)
+}
+
+// setupProject creates an arbor-style project with a bare repo and a
+// feature worktree containing an uncommitted change.
+func setupProject(t *testing.T) (projectPath, barePath string, wt git.Worktree) {
+	t.Helper()
+
+	sourceDir := t.TempDir()
+	runGit(t, sourceDir, "init", "-b", "main")
+	runGit(t, sourceDir, "config", "user.email", "test@example.com")
+	runGit(t, sourceDir, "config", "user.name", "Test User")
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("test\n"), 0644))
+	runGit(t, sourceDir, "add", ".")
+	runGit(t, sourceDir, "commit", "-m", "Initial commit")
+
+	projectPath = t.TempDir()
+	barePath = filepath.Join(projectPath, ".bare")
+	runGit(t, projectPath, "clone", "--bare", sourceDir, barePath)
+
+	wtPath := filepath.Join(projectPath, "feature-x")
+	require.NoError(t, git.CreateWorktree(barePath, wtPath, "feature/x", "main"))
+	require.NoError(t, os.WriteFile(filepath.Join(wtPath, "README.md"), []byte("changed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(wtPath, ".env"), []byte("APP_ENV=local\n"), 0644))
+
+	return projectPath, barePath, git.Worktree{Path: wtPath, Branch: "feature/x"}
+}
+
+func TestMoveAndRestore(t *testing.T) {
+	projectPath, barePath, wt := setupProject(t)
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	entry, err := Move(projectPath, barePath, wt, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "20261017T093000Z-feature-x", entry.ID)
+	assert.NoDirExists(t, wt.Path)
+	assert.FileExists(t, filepath.Join(Dir(projectPath), entry.ID, "worktree", ".env"))
+	assert.NotContains(t, runGit(t, barePath, "worktree", "list"), wt.Path, "worktree should be unregistered")
+
+	entries, err := List(projectPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "feature/x", entries[0].Branch)
+	assert.Equal(t, wt.Path, entries[0].Path)
+	assert.NotEmpty(t, entries[0].Commit)
+
+	require.NoError(t, Restore(projectPath, barePath, entries[0]))
+
+	assert.Contains(t, runGit(t, barePath, "worktree", "list"), wt.Path)
+	content, err := os.ReadFile(filepath.Join(wt.Path, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "changed\n", string(content), "uncommitted changes should survive")
+	assert.FileExists(t, filepath.Join(wt.Path, ".env"))
+	assert.Contains(t, runGit(t, wt.Path, "status", "--porcelain"), "M README.md")
+	assert.NoDirExists(t, filepath.Join(Dir(projectPath), entry.ID))
+}
+
+func TestRestore_RecreatesDeletedBranch(t *testing.T) {
+	projectPath, barePath, wt := setupProject(t)
+
+	entry, err := Move(projectPath, barePath, wt, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, git.DeleteBranch(barePath, wt.Branch, true))
+
+	require.NoError(t, Restore(projectPath, barePath, *entry))
+
+	assert.True(t, git.BranchExists(barePath, wt.Branch))
+	branch, err := git.GetCurrentBranch(wt.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/x", branch)
+}
+
+func TestMoveAndRestore_Detached(t *testing.T) {
+	projectPath, barePath, _ := setupProject(t)
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	wtPath := filepath.Join(projectPath, "v1.0.0")
+	require.NoError(t, git.CreateDetachedWorktree(barePath, wtPath, "main"))
+	head, err := git.HeadCommit(wtPath)
+	require.NoError(t, err)
+
+	entry, err := Move(projectPath, barePath, git.Worktree{Path: wtPath, Detached: true, Head: head}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "20261017T093000Z-v1.0.0", entry.ID)
+	assert.Equal(t, "(detached at "+head[:7]+")", entry.Label())
+
+	require.NoError(t, Restore(projectPath, barePath, *entry))
+
+	restored, err := git.HeadCommit(wtPath)
+	require.NoError(t, err)
+	assert.Equal(t, head, restored)
+	detached, err := git.IsDetachedHEAD(wtPath)
+	require.NoError(t, err)
+	assert.True(t, detached, "restored worktree should stay detached")
+}
+
+func TestRestore_RefusesExistingPath(t *testing.T) {
+	projectPath, barePath, wt := setupProject(t)
+
+	entry, err := Move(projectPath, barePath, wt, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(wt.Path, 0755))
+
+	err = Restore(projectPath, barePath, *entry)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestFind(t *testing.T) {
+	projectPath := t.TempDir()
+
+	_, err := Find(projectPath, "")
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	older := writeEntry(t, projectPath, "older", time.Now().Add(-2*time.Hour))
+	newer := writeEntry(t, projectPath, "newer", time.Now().Add(-time.Hour))
+
+	latest, err := Find(projectPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, newer, latest.ID)
+
+	byID, err := Find(projectPath, older)
+	require.NoError(t, err)
+	assert.Equal(t, "older", byID.Branch)
+
+	_, err = Find(projectPath, "missing")
+	assert.Error(t, err)
+}
+
+func TestPurge(t *testing.T) {
+	projectPath := t.TempDir()
+	now := time.Now()
+
+	expired := writeEntry(t, projectPath, "expired", now.Add(-8*24*time.Hour))
+	kept := writeEntry(t, projectPath, "kept", now.Add(-6*24*time.Hour))
+
+	purged, err := Purge(projectPath, Retention(0), now)
+	require.NoError(t, err)
+
+	require.Len(t, purged, 1)
+	assert.Equal(t, expired, purged[0].ID)
+	assert.NoDirExists(t, filepath.Join(Dir(projectPath), expired))
+	assert.DirExists(t, filepath.Join(Dir(projectPath), kept))
+}
+
+func TestRetention(t *testing.T) {
+	assert.Equal(t, 7*24*time.Hour, Retention(0))
+	assert.Equal(t, 24*time.Hour, Retention(1))
+}
+
+func writeEntry(t *testing.T, projectPath, branch string, removedAt time.Time) string {
+	t.Helper()
+	entry := &Entry{Branch: branch, Path: filepath.Join(projectPath, branch), Commit: strings.Repeat("a", 40), RemovedAt: removedAt.UTC()}
+	dir, err := reserveEntryDir(projectPath, entry)
+	require.NoError(t, err)
+	require.NoError(t, writeMetadata(dir, entry))
+	return entry.ID
+}
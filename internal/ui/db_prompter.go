@@ -1,11 +1,11 @@
 package ui
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 
+	"github.com/artisanexperiences/arbor/internal/i18n"
 	"github.com/artisanexperiences/arbor/internal/scaffold/prompts"
 )
 
@@ -29,12 +29,12 @@ func (p UIDbPrompter) SelectDatabase(options []prompts.DatabaseOption) (string,
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title("Select database").
-				Description("Choose an existing database or create a new one").
+				Title(i18n.T("prompt.database.title")).
+				Description(i18n.T("prompt.database.description")).
 				Options(huhOptions...).
 				Value(&selected),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -51,19 +51,19 @@ func (p UIDbPrompter) ConfirmMigrations(databaseName string) (bool, error) {
 
 	description := "php artisan migrate:fresh --seed"
 	if databaseName != "" {
-		description = fmt.Sprintf("php artisan migrate:fresh --seed on %s", databaseName)
+		description = i18n.T("prompt.migrations.on", description, databaseName)
 	}
 
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Run migrations?").
+				Title(i18n.T("prompt.migrations.title")).
 				Description(description).
-				Affirmative("Yes").
-				Negative("No").
+				Affirmative(i18n.T("prompt.yes")).
+				Negative(i18n.T("prompt.no")).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -77,18 +77,18 @@ func (p UIDbPrompter) ConfirmMigrations(databaseName string) (bool, error) {
 func (p UIDbPrompter) ConfirmDatabaseDrop(suffix string, databases []string) (bool, error) {
 	var confirmed bool
 
-	description := fmt.Sprintf("Databases to drop:\n%s", strings.Join(databases, "\n"))
+	description := i18n.T("prompt.database_drop.description", strings.Join(databases, "\n"))
 
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title(fmt.Sprintf("Drop databases matching suffix '%s'?", suffix)).
+				Title(i18n.T("prompt.database_drop.title", suffix)).
 				Description(description).
-				Affirmative("Yes").
-				Negative("No").
+				Affirmative(i18n.T("prompt.yes")).
+				Negative(i18n.T("prompt.no")).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
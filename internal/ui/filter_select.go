@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/artisanexperiences/arbor/internal/i18n"
+	"github.com/artisanexperiences/arbor/internal/utils"
+)
+
+// filterThreshold is the number of options above which pickers get a
+// fuzzy filter, for repositories with hundreds of branches or worktrees.
+const filterThreshold = 15
+
+// maxSelectHeight caps the height of a picker with many options, which
+// scrolls instead of overflowing the terminal.
+const maxSelectHeight = 12
+
+// minSelectHeight is the height a picker keeps in the smallest terminals.
+const minSelectHeight = 5
+
+// selectReservedRows are the terminal rows left for the prompts around a
+// picker, such as its filter and the form's help line.
+const selectReservedRows = 8
+
+// selectHeight returns the height of a picker with n options: 0, for no
+// limit, when they fit, otherwise maxSelectHeight or less in a short
+// terminal.
+func selectHeight(n int) int {
+	height := maxSelectHeight
+	if _, rows, err := term.GetSize(os.Stdout.Fd()); err == nil && rows-selectReservedRows < height {
+		height = max(rows-selectReservedRows, minSelectHeight)
+	}
+	if n <= height {
+		return 0
+	}
+	return height
+}
+
+// branchPickerFields returns the fields of a branch picker. See
+// filterableSelectFields.
+func branchPickerFields(sel *huh.Select[string], options []huh.Option[string], pinFirst, pinLast int) []huh.Field {
+	return filterableSelectFields(sel, options, i18n.T("prompt.branch.filter"), pinFirst, pinLast)
+}
+
+// filterableSelectFields returns sel with the options for short lists,
+// where huh's own "/" filter is enough, or a filter input titled title
+// above sel showing the options matching it, best first, for long ones.
+// The first pinFirst and last pinLast options, such as "Create new
+// branch...", are always shown.
+func filterableSelectFields(sel *huh.Select[string], options []huh.Option[string], title string, pinFirst, pinLast int) []huh.Field {
+	if len(options) <= filterThreshold {
+		return []huh.Field{sel.Options(options...).Height(selectHeight(len(options)))}
+	}
+
+	query := new(string)
+	filter := huh.NewInput().
+		Title(title).
+		Placeholder(i18n.T("prompt.filter.placeholder")).
+		DescriptionFunc(func() string {
+			return filterCount(options, *query, pinFirst, pinLast)
+		}, query).
+		Value(query)
+	sel.OptionsFunc(func() []huh.Option[string] {
+		return filterOptions(options, *query, pinFirst, pinLast)
+	}, query).
+		Height(max(selectHeight(len(options)), minSelectHeight)).
+		Validate(func(value string) error {
+			if value == "" {
+				return errors.New(i18n.T("validate.filter.no_match"))
+			}
+			return nil
+		})
+	return []huh.Field{filter, sel}
+}
+
+// filterCount describes how many of the options, not counting the pinned
+// ones, match query.
+func filterCount(options []huh.Option[string], query string, pinFirst, pinLast int) string {
+	total := len(options) - pinFirst - pinLast
+	if strings.TrimSpace(query) == "" {
+		return i18n.T("prompt.filter.total", total)
+	}
+	matched := len(filterOptions(options, query, pinFirst, pinLast)) - pinFirst - pinLast
+	return i18n.T("prompt.filter.count", matched, total)
+}
+
+// TruncateList returns items, or the first limit of them followed by a
+// line counting the rest, so long lists in prompts and messages fit the
+// terminal. A limit of 0 or less keeps every item.
+func TruncateList(items []string, limit int) []string {
+	if limit <= 0 || len(items) <= limit {
+		return items
+	}
+	truncated := append([]string{}, items[:limit]...)
+	return append(truncated, i18n.T("table.more", len(items)-limit))
+}
+
+// filterOptions returns the options whose labels fuzzy-match query, best
+// match first, between the pinned options.
+func filterOptions(options []huh.Option[string], query string, pinFirst, pinLast int) []huh.Option[string] {
+	if strings.TrimSpace(query) == "" {
+		return options
+	}
+
+	type match struct {
+		option huh.Option[string]
+		score  int
+	}
+	var matches []match
+	for _, option := range options[pinFirst : len(options)-pinLast] {
+		if score, ok := utils.FuzzyScore(query, option.Key); ok {
+			matches = append(matches, match{option, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := append([]huh.Option[string]{}, options[:pinFirst]...)
+	for _, m := range matches {
+		filtered = append(filtered, m.option)
+	}
+	return append(filtered, options[len(options)-pinLast:]...)
+}
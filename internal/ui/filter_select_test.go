@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterOptions(t *testing.T) {
+	options := []huh.Option[string]{
+		huh.NewOption("Create new branch...", "__new__"),
+		huh.NewOption("feature/billing", "feature/billing"),
+		huh.NewOption("feature/a-u-t-h", "feature/a-u-t-h"),
+		huh.NewOption("feature/user-auth", "feature/user-auth"),
+		huh.NewOption("Enter a ref...", "__other__"),
+	}
+
+	values := func(opts []huh.Option[string]) []string {
+		var v []string
+		for _, o := range opts {
+			v = append(v, o.Value)
+		}
+		return v
+	}
+
+	assert.Equal(t, values(options), values(filterOptions(options, "", 1, 1)))
+	assert.Equal(t, []string{"__new__", "feature/user-auth", "feature/a-u-t-h", "__other__"}, values(filterOptions(options, "auth", 1, 1)))
+	assert.Equal(t, []string{"__new__", "__other__"}, values(filterOptions(options, "zzz", 1, 1)))
+}
+
+func TestTruncateList(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	assert.Equal(t, items, TruncateList(items, 0))
+	assert.Equal(t, items, TruncateList(items, 4))
+	assert.Equal(t, []string{"a", "b", "… and 2 more"}, TruncateList(items, 2))
+}
+
+func TestFilterCount(t *testing.T) {
+	options := []huh.Option[string]{
+		huh.NewOption("Create new branch...", "__new__"),
+		huh.NewOption("feature/billing", "feature/billing"),
+		huh.NewOption("feature/user-auth", "feature/user-auth"),
+	}
+	assert.Equal(t, "2 to choose from", filterCount(options, "", 1, 0))
+	assert.Equal(t, "1 of 2 match", filterCount(options, "auth", 1, 0))
+}
+
+func TestSelectHeight(t *testing.T) {
+	assert.Zero(t, selectHeight(3), "short lists are not limited")
+	height := selectHeight(500)
+	assert.GreaterOrEqual(t, height, minSelectHeight)
+	assert.LessOrEqual(t, height, maxSelectHeight)
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,13 +10,14 @@ import (
 	"github.com/charmbracelet/huh"
 
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 )
 
 func SelectBranchInteractive(barePath string, localBranches, remoteBranches []string) (string, error) {
 	var selected string
 
 	options := []huh.Option[string]{
-		huh.NewOption("Create new branch...", "__new__"),
+		huh.NewOption(i18n.T("prompt.branch.create"), "__new__"),
 	}
 
 	for _, b := range localBranches {
@@ -26,15 +28,13 @@ func SelectBranchInteractive(barePath string, localBranches, remoteBranches []st
 		options = append(options, huh.NewOption("↓ "+b, b))
 	}
 
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.branch.title")).
+		Description(i18n.T("prompt.branch.description")).
+		Value(&selected)
 	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select a branch").
-				Description("Choose an existing branch or create a new one").
-				Options(options...).
-				Value(&selected),
-		),
-	).WithTheme(huh.ThemeCatppuccin())
+		huh.NewGroup(branchPickerFields(sel, options, 1, 0)...),
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -53,12 +53,12 @@ func PromptNewBranch() (string, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewInput().
-				Title("New branch name").
+				Title(i18n.T("prompt.new_branch.title")).
 				Placeholder("feature/my-feature").
 				Value(&name).
 				Validate(validateBranchName),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -67,12 +67,84 @@ func PromptNewBranch() (string, error) {
 	return name, nil
 }
 
+// SelectBaseBranch prompts for the ref a new branch is created from: the
+// default branch, another local branch, a remote branch, a recent tag, or
+// a commit or ref typed in by hand.
+func SelectBaseBranch(branch, defaultBranch string, localBranches, remoteBranches, tags []string) (string, error) {
+	selected := defaultBranch
+
+	options := []huh.Option[string]{
+		huh.NewOption(i18n.T("prompt.upstream.default", defaultBranch), defaultBranch),
+	}
+
+	for _, b := range localBranches {
+		if b != defaultBranch && b != branch {
+			options = append(options, huh.NewOption(b, b))
+		}
+	}
+
+	for _, b := range remoteBranches {
+		if !strings.HasSuffix(b, "/HEAD") {
+			options = append(options, huh.NewOption("↓ "+b, b))
+		}
+	}
+
+	for _, tag := range tags {
+		options = append(options, huh.NewOption(i18n.T("prompt.base.tag", tag), tag))
+	}
+
+	options = append(options, huh.NewOption(i18n.T("prompt.base.other"), "__other__"))
+
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.base.title")).
+		Description(i18n.T("prompt.base.description", branch)).
+		Value(&selected)
+	form := huh.NewForm(
+		huh.NewGroup(branchPickerFields(sel, options, 1, 1)...),
+	).WithTheme(FormTheme())
+
+	if err := form.Run(); err != nil {
+		return "", NormalizeAbort(err)
+	}
+
+	if selected == "__other__" {
+		return promptBaseRef()
+	}
+
+	return selected, nil
+}
+
+func promptBaseRef() (string, error) {
+	var ref string
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(i18n.T("prompt.base_ref.title")).
+				Placeholder("origin/release/1.2").
+				Value(&ref).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errors.New(i18n.T("validate.base.empty"))
+					}
+					return nil
+				}),
+		),
+	).WithTheme(FormTheme())
+
+	if err := form.Run(); err != nil {
+		return "", NormalizeAbort(err)
+	}
+
+	return strings.TrimSpace(ref), nil
+}
+
 func validateBranchName(s string) error {
 	if s == "" {
-		return fmt.Errorf("branch name cannot be empty")
+		return errors.New(i18n.T("validate.branch.empty"))
 	}
 	if len(s) < 2 {
-		return fmt.Errorf("branch name must be at least 2 characters")
+		return errors.New(i18n.T("validate.branch.short"))
 	}
 	return nil
 }
@@ -92,12 +164,13 @@ func SelectWorktreesToPrune(removable []git.Worktree) ([]git.Worktree, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewMultiSelect[string]().
-				Title("Select worktrees to remove").
-				Description("Space to toggle, Enter to confirm").
+				Title(i18n.T("prompt.prune.title")).
+				Description(i18n.T("prompt.prune.description")).
 				Options(options...).
+				Height(selectHeight(len(options))).
 				Value(&selected),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return nil, NormalizeAbort(err)
@@ -126,11 +199,11 @@ func ConfirmRemoval(count int) (bool, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Remove worktrees").
-				Description(fmt.Sprintf("Remove %d selected worktree(s)?", count)).
+				Title(i18n.T("prompt.confirm_removal.title")).
+				Description(i18n.T("prompt.confirm_removal.description", count)).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -148,7 +221,27 @@ func Confirm(message string) (bool, error) {
 				Title(message).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
+
+	if err := form.Run(); err != nil {
+		return false, NormalizeAbort(err)
+	}
+
+	return confirmed, nil
+}
+
+// ConfirmWithDefault is like Confirm but starts with defaultValue selected.
+func ConfirmWithDefault(message, description string, defaultValue bool) (bool, error) {
+	confirmed := defaultValue
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(message).
+				Description(description).
+				Value(&confirmed),
+		),
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -163,13 +256,13 @@ func PromptRepoURL() (string, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewInput().
-				Title("Repository").
-				Description("GitHub URL or owner/repo format").
+				Title(i18n.T("prompt.repo.title")).
+				Description(i18n.T("prompt.repo.description")).
 				Placeholder("owner/repo or git@github.com:owner/repo.git").
 				Value(&repo).
 				Validate(validateRepoURL),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -180,10 +273,10 @@ func PromptRepoURL() (string, error) {
 
 func validateRepoURL(s string) error {
 	if s == "" {
-		return fmt.Errorf("repository URL cannot be empty")
+		return errors.New(i18n.T("validate.repo.empty"))
 	}
 	if len(s) < 3 {
-		return fmt.Errorf("repository URL must be at least 3 characters")
+		return errors.New(i18n.T("validate.repo.short"))
 	}
 	return nil
 }
@@ -197,40 +290,38 @@ func SelectWorktreeToRemove(worktrees []git.Worktree) (*git.Worktree, error) {
 	}
 
 	if len(removable) == 0 {
-		return nil, fmt.Errorf("no worktrees available to remove")
+		return nil, errors.New(i18n.T("error.no_worktrees_to_remove"))
 	}
 
 	options := make([]huh.Option[string], len(removable))
 	for i, wt := range removable {
 		status := ""
 		if wt.IsMerged {
-			status = " (merged)"
+			status = i18n.T("prompt.remove.merged")
 		}
-		label := fmt.Sprintf("%s%s", wt.Branch, status)
-		options[i] = huh.NewOption(label, wt.Branch)
+		label := fmt.Sprintf("%s%s", wt.Label(), status)
+		options[i] = huh.NewOption(label, wt.Path)
 	}
 
 	var selected string
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.remove.title")).
+		Value(&selected)
 	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select worktree to remove").
-				Options(options...).
-				Value(&selected),
-		),
-	).WithTheme(huh.ThemeCatppuccin())
+		huh.NewGroup(filterableSelectFields(sel, options, i18n.T("prompt.worktree.filter"), 0, 0)...),
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return nil, NormalizeAbort(err)
 	}
 
 	for _, wt := range removable {
-		if wt.Branch == selected {
+		if wt.Path == selected {
 			return &wt, nil
 		}
 	}
 
-	return nil, fmt.Errorf("worktree not found")
+	return nil, errors.New(i18n.T("error.worktree_not_found"))
 }
 
 // SelectProjectToDestroy scans immediate children of cwd for arbor projects and returns selected path
@@ -257,7 +348,7 @@ func SelectProjectToDestroy(cwd string) (string, error) {
 	}
 
 	if len(projects) == 0 {
-		return "", fmt.Errorf("no arbor projects found in %s", cwd)
+		return "", errors.New(i18n.T("error.no_projects", cwd))
 	}
 
 	options := make([]huh.Option[string], len(projects))
@@ -266,15 +357,13 @@ func SelectProjectToDestroy(cwd string) (string, error) {
 	}
 
 	var selected string
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.destroy_project.title")).
+		Description(i18n.T("prompt.destroy_project.description")).
+		Value(&selected)
 	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select a project to destroy").
-				Description("Choose an arbor project to completely remove").
-				Options(options...).
-				Value(&selected),
-		),
-	).WithTheme(huh.ThemeCatppuccin())
+		huh.NewGroup(filterableSelectFields(sel, options, i18n.T("prompt.project.filter"), 0, 0)...),
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -283,22 +372,30 @@ func SelectProjectToDestroy(cwd string) (string, error) {
 	return filepath.Join(cwd, selected), nil
 }
 
+// confirmListLimit is the number of items confirmation prompts list before
+// counting the rest.
+const confirmListLimit = 10
+
 // ConfirmDestroy shows confirmation dialog with worktree list
 func ConfirmDestroy(projectName string, worktrees []git.Worktree) (bool, error) {
+	labels := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		labels[i] = wt.Label()
+	}
 	var worktreeList string
-	for _, wt := range worktrees {
-		worktreeList += fmt.Sprintf("  • %s\n", wt.Branch)
+	for _, label := range TruncateList(labels, confirmListLimit) {
+		worktreeList += fmt.Sprintf("  • %s\n", label)
 	}
 
 	var confirmed bool
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Destroy project").
-				Description(fmt.Sprintf("Destroy project %q?\n\nWorktrees to be removed:\n%s\nThis cannot be undone.", projectName, worktreeList)).
+				Title(i18n.T("prompt.confirm_destroy.title")).
+				Description(i18n.T("prompt.confirm_destroy.description", projectName, worktreeList)).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -310,31 +407,29 @@ func ConfirmDestroy(projectName string, worktrees []git.Worktree) (bool, error)
 // SelectWorktreeToScaffold allows selecting a worktree to scaffold
 func SelectWorktreeToScaffold(worktrees []git.Worktree) (*git.Worktree, error) {
 	if len(worktrees) == 0 {
-		return nil, fmt.Errorf("no worktrees available to scaffold")
+		return nil, errors.New(i18n.T("error.no_worktrees_to_scaffold"))
 	}
 
 	options := make([]huh.Option[string], len(worktrees))
 	for i, wt := range worktrees {
-		label := fmt.Sprintf("%s (%s)", wt.Branch, filepath.Base(wt.Path))
+		label := fmt.Sprintf("%s (%s)", wt.Label(), filepath.Base(wt.Path))
 		if wt.IsCurrent {
-			label += " [current]"
+			label += i18n.T("status.current_tag")
 		}
 		if wt.IsMain {
-			label += " [main]"
+			label += i18n.T("status.main_tag")
 		}
 		options[i] = huh.NewOption(label, wt.Path)
 	}
 
 	var selected string
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.scaffold.title")).
+		Description(i18n.T("prompt.scaffold.description")).
+		Value(&selected)
 	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select worktree to scaffold").
-				Description("Choose a worktree to run scaffold steps").
-				Options(options...).
-				Value(&selected),
-		),
-	).WithTheme(huh.ThemeCatppuccin())
+		huh.NewGroup(filterableSelectFields(sel, options, i18n.T("prompt.worktree.filter"), 0, 0)...),
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return nil, NormalizeAbort(err)
@@ -346,7 +441,7 @@ func SelectWorktreeToScaffold(worktrees []git.Worktree) (*git.Worktree, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("worktree not found")
+	return nil, errors.New(i18n.T("error.worktree_not_found"))
 }
 
 // ConfirmScaffold prompts user to confirm scaffolding current worktree
@@ -355,11 +450,11 @@ func ConfirmScaffold(branch string) (bool, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Scaffold current worktree").
-				Description(fmt.Sprintf("Run scaffold steps for worktree %q?", branch)).
+				Title(i18n.T("prompt.confirm_scaffold.title")).
+				Description(i18n.T("prompt.confirm_scaffold.description", branch)).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -368,24 +463,25 @@ func ConfirmScaffold(branch string) (bool, error) {
 	return confirmed, nil
 }
 
-// SelectSyncStrategy prompts user to choose between rebase and merge
+// SelectSyncStrategy prompts user to choose between rebase, merge and ff-only
 func SelectSyncStrategy(defaultStrategy string) (string, error) {
 	selected := defaultStrategy
 
 	options := []huh.Option[string]{
-		huh.NewOption("rebase (cleaner history)", "rebase"),
-		huh.NewOption("merge (preserves all commits)", "merge"),
+		huh.NewOption(i18n.T("prompt.sync_strategy.rebase"), "rebase"),
+		huh.NewOption(i18n.T("prompt.sync_strategy.merge"), "merge"),
+		huh.NewOption(i18n.T("prompt.sync_strategy.ff_only"), "ff-only"),
 	}
 
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title("Select sync strategy").
-				Description("Choose how to integrate upstream changes").
+				Title(i18n.T("prompt.sync_strategy.title")).
+				Description(i18n.T("prompt.sync_strategy.description")).
 				Options(options...).
 				Value(&selected),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -433,26 +529,72 @@ func SelectUpstreamBranch(localBranches, remoteBranches []string, defaultBranch
 				}
 			}
 			if !alreadyAdded {
-				options = append(options, huh.NewOption(branchName+" (from remote)", branchName))
+				options = append(options, huh.NewOption(i18n.T("prompt.upstream.remote", branchName), branchName))
 			}
 		}
 	}
 
 	// Insert default branch at the beginning if it exists
+	pinned := 0
 	if defaultBranch != "" {
-		defaultOption := huh.NewOption(defaultBranch+" (default)", defaultBranch)
+		defaultOption := huh.NewOption(i18n.T("prompt.upstream.default", defaultBranch), defaultBranch)
 		options = append([]huh.Option[string]{defaultOption}, options...)
+		pinned = 1
+	}
+
+	sel := huh.NewSelect[string]().
+		Title(i18n.T("prompt.upstream.title")).
+		Description(i18n.T("prompt.upstream.description")).
+		Value(&selected)
+	form := huh.NewForm(
+		huh.NewGroup(branchPickerFields(sel, options, pinned, 0)...),
+	).WithTheme(FormTheme())
+
+	if err := form.Run(); err != nil {
+		return "", NormalizeAbort(err)
+	}
+
+	return selected, nil
+}
+
+// Actions offered by SelectConflictAction.
+const (
+	ConflictActionContinue  = "continue"
+	ConflictActionEditor    = "editor"
+	ConflictActionMergetool = "mergetool"
+	ConflictActionAbort     = "abort"
+	ConflictActionQuit      = "quit"
+)
+
+// SelectConflictAction asks how to deal with sync conflicts. Continuing is
+// only offered once no conflicted files remain.
+func SelectConflictAction(remaining int) (string, error) {
+	var options []huh.Option[string]
+	if remaining == 0 {
+		options = append(options, huh.NewOption(i18n.T("prompt.conflict.continue"), ConflictActionContinue))
 	}
+	options = append(options,
+		huh.NewOption(i18n.T("prompt.conflict.editor"), ConflictActionEditor),
+		huh.NewOption(i18n.T("prompt.conflict.mergetool"), ConflictActionMergetool),
+		huh.NewOption(i18n.T("prompt.conflict.abort"), ConflictActionAbort),
+		huh.NewOption(i18n.T("prompt.conflict.quit"), ConflictActionQuit),
+	)
 
+	description := i18n.T("prompt.conflict.resolved")
+	if remaining > 0 {
+		description = i18n.T("prompt.conflict.description", remaining)
+	}
+
+	var selected string
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title("Select upstream branch").
-				Description("Choose the branch to sync against").
+				Title(i18n.T("prompt.conflict.title")).
+				Description(description).
 				Options(options...).
 				Value(&selected),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return "", NormalizeAbort(err)
@@ -467,11 +609,11 @@ func ConfirmSync(currentBranch, upstream, strategy string) (bool, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Confirm sync operation").
-				Description(fmt.Sprintf("Sync branch %q with upstream %q using %s?", currentBranch, upstream, strategy)).
+				Title(i18n.T("prompt.confirm_sync.title")).
+				Description(i18n.T("prompt.confirm_sync.description", currentBranch, upstream, strategy)).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -486,11 +628,11 @@ func ConfirmSaveSyncConfig() (bool, error) {
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Save sync settings").
-				Description("Save the selected upstream and strategy to arbor.yaml for future syncs?").
+				Title(i18n.T("prompt.save_sync.title")).
+				Description(i18n.T("prompt.save_sync.description")).
 				Value(&confirmed),
 		),
-	).WithTheme(huh.ThemeCatppuccin())
+	).WithTheme(FormTheme())
 
 	if err := form.Run(); err != nil {
 		return false, NormalizeAbort(err)
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 )
@@ -56,15 +55,8 @@ func PrintErrorWithHint(msg, hint string) {
 	fmt.Println("  " + MutedStyle.Render(hint))
 }
 
+// RunWithSpinner runs action while reporting progress with the renderer
+// selected by SetProgressMode.
 func RunWithSpinner(title string, action func() error) error {
-	var err error
-	sp := spinner.New().
-		Title(title).
-		Action(func() {
-			err = action()
-		})
-	if runErr := sp.Run(); runErr != nil {
-		return runErr
-	}
-	return err
+	return progressRenderer.Run(title, action)
 }
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh/spinner"
+)
+
+// Progress modes accepted by --progress.
+const (
+	ProgressAuto   = "auto"
+	ProgressFancy  = "fancy"
+	ProgressPlain  = "plain"
+	ProgressGitHub = "github"
+	ProgressNone   = "none"
+)
+
+// ProgressModes lists the valid values for --progress in display order.
+var ProgressModes = []string{ProgressAuto, ProgressFancy, ProgressPlain, ProgressGitHub, ProgressNone}
+
+// ProgressRenderer reports the progress of a long-running action.
+type ProgressRenderer interface {
+	Run(title string, action func() error) error
+}
+
+// FancyRenderer shows an animated spinner. Only suitable for a TTY.
+type FancyRenderer struct{}
+
+func (FancyRenderer) Run(title string, action func() error) error {
+	var err error
+	sp := spinner.New().
+		Title(title).
+		Action(func() {
+			err = action()
+		})
+	if runErr := sp.Run(); runErr != nil {
+		return runErr
+	}
+	return err
+}
+
+// PlainRenderer prints one line when an action starts and one when it ends.
+// Safe for CI logs and piped output.
+type PlainRenderer struct {
+	Out io.Writer
+}
+
+func (r PlainRenderer) Run(title string, action func() error) error {
+	w := r.Out
+	if w == nil {
+		w = os.Stderr
+	}
+	_, _ = fmt.Fprintf(w, "→ %s\n", title)
+	if err := action(); err != nil {
+		_, _ = fmt.Fprintf(w, "✗ %s\n", title)
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "✓ %s\n", title)
+	return nil
+}
+
+// GitHubRenderer wraps each action in a collapsible GitHub Actions log group
+// and annotates failures with an error command.
+type GitHubRenderer struct {
+	Out io.Writer
+}
+
+func (r GitHubRenderer) Run(title string, action func() error) error {
+	w := r.Out
+	if w == nil {
+		w = os.Stdout
+	}
+	_, _ = fmt.Fprintf(w, "::group::%s\n", title)
+	err := action()
+	_, _ = fmt.Fprintln(w, "::endgroup::")
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "::error title=%s::%s\n", githubEscapeProperty(title), githubEscapeData(err.Error()))
+	}
+	return err
+}
+
+// NoneRenderer runs actions without any progress output.
+type NoneRenderer struct{}
+
+func (NoneRenderer) Run(title string, action func() error) error {
+	return action()
+}
+
+var progressRenderer ProgressRenderer = FancyRenderer{}
+
+func init() {
+	progressRenderer = NewProgressRenderer(DetectProgressMode(os.Getenv, IsInteractive()))
+}
+
+// SetProgressMode selects the renderer used by RunWithSpinner.
+// "auto" picks a renderer from the environment.
+func SetProgressMode(mode string) error {
+	switch mode {
+	case "", ProgressAuto:
+		mode = DetectProgressMode(os.Getenv, IsInteractive())
+	case ProgressFancy, ProgressPlain, ProgressGitHub, ProgressNone:
+	default:
+		return fmt.Errorf("invalid progress mode %q: must be one of %s", mode, strings.Join(ProgressModes, ", "))
+	}
+	progressRenderer = NewProgressRenderer(mode)
+	return nil
+}
+
+// NewProgressRenderer returns the renderer for an explicit (non-auto) mode.
+func NewProgressRenderer(mode string) ProgressRenderer {
+	switch mode {
+	case ProgressPlain:
+		return PlainRenderer{}
+	case ProgressGitHub:
+		return GitHubRenderer{}
+	case ProgressNone:
+		return NoneRenderer{}
+	default:
+		return FancyRenderer{}
+	}
+}
+
+// DetectProgressMode chooses a progress mode from CI/TERM detection.
+// GitHub Actions gets log groups, other CI systems, dumb terminals and
+// non-TTY output get plain lines, and everything else gets the spinner.
+func DetectProgressMode(getenv func(string) string, isTTY bool) string {
+	if getenv("GITHUB_ACTIONS") == "true" {
+		return ProgressGitHub
+	}
+	if getenv("CI") != "" {
+		return ProgressPlain
+	}
+	if getenv("TERM") == "dumb" {
+		return ProgressPlain
+	}
+	if !isTTY {
+		return ProgressPlain
+	}
+	return ProgressFancy
+}
+
+func githubEscapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func githubEscapeProperty(s string) string {
+	s = githubEscapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectProgressMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		isTTY    bool
+		expected string
+	}{
+		{name: "tty", isTTY: true, expected: ProgressFancy},
+		{name: "piped", isTTY: false, expected: ProgressPlain},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, isTTY: true, expected: ProgressPlain},
+		{name: "generic CI", env: map[string]string{"CI": "true"}, isTTY: true, expected: ProgressPlain},
+		{name: "github actions", env: map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"}, isTTY: false, expected: ProgressGitHub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.expected, DetectProgressMode(getenv, tt.isTTY))
+		})
+	}
+}
+
+func TestSetProgressMode_Invalid(t *testing.T) {
+	err := SetProgressMode("sparkles")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid progress mode")
+}
+
+func TestPlainRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := PlainRenderer{Out: &buf}
+
+	assert.NoError(t, r.Run("Installing", func() error { return nil }))
+	assert.Equal(t, "→ Installing\n✓ Installing\n", buf.String())
+
+	buf.Reset()
+	err := r.Run("Building", func() error { return errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, "→ Building\n✗ Building\n", buf.String())
+}
+
+func TestGitHubRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := GitHubRenderer{Out: &buf}
+
+	err := r.Run("Step: one", func() error { return errors.New("line1\nline2") })
+	assert.Error(t, err)
+	assert.Equal(t, "::group::Step: one\n::endgroup::\n::error title=Step%3A one::line1%0Aline2\n", buf.String())
+}
+
+func TestNoneRenderer(t *testing.T) {
+	called := false
+	err := NoneRenderer{}.Run("Silent", func() error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
@@ -3,66 +3,138 @@ package ui
 import "github.com/charmbracelet/lipgloss"
 
 var (
-	Primary   = lipgloss.Color("#4CAF50")
-	Secondary = lipgloss.Color("#A1887F")
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
 
-	ColorSuccess = lipgloss.Color("#66BB6A")
-	ColorWarning = lipgloss.Color("#FFA726")
-	ColorError   = lipgloss.Color("#EF5350")
-	ColorInfo    = lipgloss.Color("#29B6F6")
-	ColorMuted   = lipgloss.Color("#9E9E9E")
+	ColorSuccess lipgloss.Color
+	ColorWarning lipgloss.Color
+	ColorError   lipgloss.Color
+	ColorInfo    lipgloss.Color
+	ColorMuted   lipgloss.Color
 
-	Text    = lipgloss.Color("#F9FAFB")
-	TextDim = lipgloss.Color("#9CA3AF")
+	Text    lipgloss.Color
+	TextDim lipgloss.Color
 )
 
 var (
+	HeaderStyle          lipgloss.Style
+	SuccessBadge         lipgloss.Style
+	WarningBadge         lipgloss.Style
+	ErrorBadge           lipgloss.Style
+	BoxStyle             lipgloss.Style
+	MutedStyle           lipgloss.Style
+	CodeStyle            lipgloss.Style
+	InfoBadge            lipgloss.Style
+	MainWorktreeStyle    lipgloss.Style
+	CurrentWorktreeStyle lipgloss.Style
+)
+
+// palette holds the colours a theme assigns to the package-level colour variables.
+type palette struct {
+	primary        string
+	secondary      string
+	success        string
+	warning        string
+	errorColor     string
+	info           string
+	muted          string
+	text           string
+	textDim        string
+	badgeText      string
+	errorBadgeText string
+}
+
+var defaultPalette = palette{
+	primary:        "#4CAF50",
+	secondary:      "#A1887F",
+	success:        "#66BB6A",
+	warning:        "#FFA726",
+	errorColor:     "#EF5350",
+	info:           "#29B6F6",
+	muted:          "#9E9E9E",
+	text:           "#F9FAFB",
+	textDim:        "#9CA3AF",
+	badgeText:      "#000",
+	errorBadgeText: "#FFF",
+}
+
+// highContrastPalette uses saturated colours and pure black/white for
+// readability on low-quality displays and for users with low vision.
+var highContrastPalette = palette{
+	primary:        "#00FF00",
+	secondary:      "#FFFF00",
+	success:        "#00FF00",
+	warning:        "#FFFF00",
+	errorColor:     "#FF0000",
+	info:           "#00FFFF",
+	muted:          "#FFFFFF",
+	text:           "#FFFFFF",
+	textDim:        "#FFFFFF",
+	badgeText:      "#000",
+	errorBadgeText: "#000",
+}
+
+func init() {
+	applyPalette(defaultPalette)
+}
+
+func applyPalette(p palette) {
+	Primary = lipgloss.Color(p.primary)
+	Secondary = lipgloss.Color(p.secondary)
+	ColorSuccess = lipgloss.Color(p.success)
+	ColorWarning = lipgloss.Color(p.warning)
+	ColorError = lipgloss.Color(p.errorColor)
+	ColorInfo = lipgloss.Color(p.info)
+	ColorMuted = lipgloss.Color(p.muted)
+	Text = lipgloss.Color(p.text)
+	TextDim = lipgloss.Color(p.textDim)
+
 	HeaderStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(Primary).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(Primary).
+		MarginBottom(1)
 
 	SuccessBadge = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#000")).
-			Background(ColorSuccess).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(lipgloss.Color(p.badgeText)).
+		Background(ColorSuccess).
+		Padding(0, 1).
+		Bold(true)
 
 	WarningBadge = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#000")).
-			Background(ColorWarning).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(lipgloss.Color(p.badgeText)).
+		Background(ColorWarning).
+		Padding(0, 1).
+		Bold(true)
 
 	ErrorBadge = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFF")).
-			Background(ColorError).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(lipgloss.Color(p.errorBadgeText)).
+		Background(ColorError).
+		Padding(0, 1).
+		Bold(true)
 
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(Primary).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Primary).
+		Padding(1, 2)
 
 	MutedStyle = lipgloss.NewStyle().
-			Foreground(ColorMuted)
+		Foreground(ColorMuted)
 
 	CodeStyle = lipgloss.NewStyle().
-			Foreground(ColorInfo).
-			Bold(true)
+		Foreground(ColorInfo).
+		Bold(true)
 
 	InfoBadge = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#000")).
-			Background(ColorInfo).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(lipgloss.Color(p.badgeText)).
+		Background(ColorInfo).
+		Padding(0, 1).
+		Bold(true)
 
 	MainWorktreeStyle = lipgloss.NewStyle().
-				Foreground(Secondary).
-				Bold(true)
+		Foreground(Secondary).
+		Bold(true)
 
 	CurrentWorktreeStyle = lipgloss.NewStyle().
-				Foreground(ColorSuccess).
-				Bold(true)
-)
+		Foreground(ColorSuccess).
+		Bold(true)
+}
@@ -7,8 +7,10 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/lipgloss/tree"
 
 	"github.com/artisanexperiences/arbor/internal/git"
+	"github.com/artisanexperiences/arbor/internal/i18n"
 )
 
 func RenderTable(headers []string, rows [][]string) string {
@@ -31,7 +33,7 @@ func RenderStatusTable(rows [][]string) string {
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(Primary)).
-		Headers("TOOL", "STATUS", "VERSION").
+		Headers(i18n.T("table.status.tool"), i18n.T("table.status.status"), i18n.T("table.status.version")).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			if row == 0 {
 				return lipgloss.NewStyle().
@@ -59,12 +61,12 @@ func RenderWorktreeTable(worktrees []git.Worktree) string {
 		Foreground(Primary).
 		Bold(true).
 		Padding(0, 1).
-		Render("🌳 Arbor Worktrees")
+		Render(i18n.T("table.worktrees.title"))
 
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(Primary)).
-		Headers("WORKTREE", "BRANCH", "STATUS").
+		Headers(i18n.T("table.worktrees.worktree"), i18n.T("table.worktrees.branch"), i18n.T("table.worktrees.status")).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			if row == 0 {
 				return lipgloss.NewStyle().
@@ -84,7 +86,7 @@ func RenderWorktreeTable(worktrees []git.Worktree) string {
 	for _, wt := range worktrees {
 		worktreeName := filepath.Base(wt.Path)
 		status := formatWorktreeStatus(wt)
-		t.Row(worktreeName, wt.Branch, status)
+		t.Row(worktreeName, wt.Label(), status)
 		if wt.IsMerged && !wt.IsMain {
 			mergedCount++
 		}
@@ -92,15 +94,15 @@ func RenderWorktreeTable(worktrees []git.Worktree) string {
 
 	summary := ""
 	if len(worktrees) == 1 {
-		summary = "1 worktree"
+		summary = i18n.T("table.worktrees.one")
 	} else {
-		summary = fmt.Sprintf("%d worktrees", len(worktrees))
+		summary = i18n.T("table.worktrees.many", len(worktrees))
 	}
 	if mergedCount > 0 {
 		if mergedCount == 1 {
-			summary += " • 1 merged"
+			summary += i18n.T("table.worktrees.one_merged")
 		} else {
-			summary += fmt.Sprintf(" • %d merged", mergedCount)
+			summary += i18n.T("table.worktrees.many_merged", mergedCount)
 		}
 	}
 
@@ -111,18 +113,81 @@ func RenderWorktreeTable(worktrees []git.Worktree) string {
 	return title + "\n\n" + t.String() + "\n" + summaryStyle.Render(summary)
 }
 
+// RenderWorktreeStack renders worktrees as a tree, with each stacked branch
+// under the worktree of the branch it was created from.
+func RenderWorktreeStack(worktrees []git.Worktree) string {
+	title := lipgloss.NewStyle().
+		Foreground(Primary).
+		Bold(true).
+		Padding(0, 1).
+		Render(i18n.T("table.worktrees.stack_title"))
+
+	byBranch := make(map[string]bool)
+	for _, wt := range worktrees {
+		if !wt.Detached {
+			byBranch[wt.Branch] = true
+		}
+	}
+
+	children := make(map[string][]git.Worktree)
+	var roots []git.Worktree
+	for _, wt := range worktrees {
+		if wt.Parent != "" && byBranch[wt.Parent] && wt.Parent != wt.Branch {
+			children[wt.Parent] = append(children[wt.Parent], wt)
+		} else {
+			roots = append(roots, wt)
+		}
+	}
+
+	var node func(wt git.Worktree, seen map[string]bool) *tree.Tree
+	node = func(wt git.Worktree, seen map[string]bool) *tree.Tree {
+		label := wt.Label()
+		if wt.IsCurrent {
+			label = lipgloss.NewStyle().Bold(true).Render(label)
+		}
+		if folder := filepath.Base(wt.Path); folder != wt.Branch {
+			label += " " + MutedStyle.Render(folder)
+		}
+		t := tree.Root(label + " " + formatWorktreeStatus(wt))
+		if wt.Detached || seen[wt.Branch] {
+			return t
+		}
+		seen[wt.Branch] = true
+		for _, child := range children[wt.Branch] {
+			t.Child(node(child, seen))
+		}
+		return t
+	}
+
+	var b strings.Builder
+	b.WriteString(title + "\n")
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		b.WriteString("\n" + node(root, seen).String() + "\n")
+	}
+	return b.String()
+}
+
 func formatWorktreeStatus(wt git.Worktree) string {
 	var parts []string
 
 	if wt.IsCurrent {
-		parts = append(parts, CurrentWorktreeStyle.Render("● current"))
+		parts = append(parts, CurrentWorktreeStyle.Render(i18n.T("status.current")))
 	}
 	if wt.IsMain {
-		parts = append(parts, MainWorktreeStyle.Render("★ main"))
+		parts = append(parts, MainWorktreeStyle.Render(i18n.T("status.main")))
+	} else if wt.Detached {
+		parts = append(parts, MutedStyle.Render(i18n.T("status.detached")))
 	} else if wt.IsMerged {
-		parts = append(parts, MutedStyle.Render("✓ merged"))
+		parts = append(parts, MutedStyle.Render(i18n.T("status.merged")))
 	} else {
-		parts = append(parts, MutedStyle.Render("○ active"))
+		parts = append(parts, MutedStyle.Render(i18n.T("status.active")))
+	}
+	if wt.ScaffoldPending {
+		parts = append(parts, MutedStyle.Render(i18n.T("status.scaffold_pending")))
+	}
+	if wt.DirMismatch {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ColorWarning).Render(i18n.T("status.dir_mismatch")))
 	}
 
 	return strings.Join(parts, " ")
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme names accepted by ui.theme in the global config.
+const (
+	ThemeDefault      = "default"
+	ThemeCharm        = "charm"
+	ThemeDracula      = "dracula"
+	ThemeBase16       = "base16"
+	ThemeHighContrast = "high-contrast"
+	ThemePlain        = "plain"
+)
+
+// Themes lists the valid theme names in display order.
+var Themes = []string{ThemeDefault, ThemeCharm, ThemeDracula, ThemeBase16, ThemeHighContrast, ThemePlain}
+
+var (
+	currentTheme = ThemeDefault
+	colorEnabled = true
+)
+
+// SetTheme applies a named theme to output styles and interactive forms.
+func SetTheme(name string) error {
+	switch name {
+	case "":
+		name = ThemeDefault
+	case ThemeDefault, ThemeCharm, ThemeDracula, ThemeBase16, ThemeHighContrast, ThemePlain:
+	default:
+		return fmt.Errorf("invalid theme %q: must be one of %s", name, strings.Join(Themes, ", "))
+	}
+
+	currentTheme = name
+	if name == ThemeHighContrast {
+		applyPalette(highContrastPalette)
+	} else {
+		applyPalette(defaultPalette)
+	}
+	if name == ThemePlain {
+		SetColorEnabled(false)
+	}
+	return nil
+}
+
+// FormTheme returns the huh theme for interactive prompts.
+func FormTheme() *huh.Theme {
+	if !colorEnabled {
+		return huh.ThemeBase()
+	}
+	switch currentTheme {
+	case ThemeCharm:
+		return huh.ThemeCharm()
+	case ThemeDracula:
+		return huh.ThemeDracula()
+	case ThemeBase16, ThemeHighContrast:
+		return huh.ThemeBase16()
+	case ThemePlain:
+		return huh.ThemeBase()
+	default:
+		return huh.ThemeCatppuccin()
+	}
+}
+
+// ColorEnabled reports whether output should be coloured.
+// NO_COLOR (any value) disables colour, CLICOLOR_FORCE (any value but "0")
+// forces it on, and otherwise colour follows whether stdout is a terminal.
+// See https://no-color.org and https://bixense.com/clicolors.
+func ColorEnabled(getenv func(string) string, isTTY bool) bool {
+	if getenv("NO_COLOR") != "" {
+		return false
+	}
+	if ColorForced(getenv) {
+		return true
+	}
+	return isTTY
+}
+
+// ColorForced reports whether CLICOLOR_FORCE requests colour even when not on a TTY.
+func ColorForced(getenv func(string) string) bool {
+	if getenv("NO_COLOR") != "" {
+		return false
+	}
+	force := getenv("CLICOLOR_FORCE")
+	return force != "" && force != "0"
+}
+
+// SetColorEnabled turns colour output on or off for styles and the logger.
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+	if !enabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		logger.SetColorProfile(termenv.Ascii)
+		return
+	}
+	if ColorForced(os.Getenv) {
+		lipgloss.SetColorProfile(termenv.TrueColor)
+		logger.SetColorProfile(termenv.TrueColor)
+	}
+}
+
+// IsColorEnabled reports whether coloured output is active.
+func IsColorEnabled() bool {
+	return colorEnabled
+}
+
+// ConfigureColor applies the environment colour settings, with noColor
+// (from --no-color) taking precedence.
+func ConfigureColor(noColor bool) {
+	if noColor {
+		SetColorEnabled(false)
+		return
+	}
+	SetColorEnabled(ColorEnabled(os.Getenv, IsInteractive()))
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		isTTY    bool
+		expected bool
+	}{
+		{name: "tty", isTTY: true, expected: true},
+		{name: "piped", isTTY: false, expected: false},
+		{name: "NO_COLOR on tty", env: map[string]string{"NO_COLOR": "1"}, isTTY: true, expected: false},
+		{name: "CLICOLOR_FORCE when piped", env: map[string]string{"CLICOLOR_FORCE": "1"}, isTTY: false, expected: true},
+		{name: "CLICOLOR_FORCE=0 is ignored", env: map[string]string{"CLICOLOR_FORCE": "0"}, isTTY: false, expected: false},
+		{name: "NO_COLOR wins over CLICOLOR_FORCE", env: map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, isTTY: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.expected, ColorEnabled(getenv, tt.isTTY))
+		})
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	t.Cleanup(func() {
+		colorEnabled = true
+		assert.NoError(t, SetTheme(ThemeDefault))
+	})
+
+	assert.Error(t, SetTheme("neon"))
+
+	assert.NoError(t, SetTheme(ThemeHighContrast))
+	assert.Equal(t, highContrastPalette.primary, string(Primary))
+
+	assert.NoError(t, SetTheme(ThemeDefault))
+	assert.Equal(t, defaultPalette.primary, string(Primary))
+
+	assert.NoError(t, SetTheme(ThemePlain))
+	assert.False(t, IsColorEnabled())
+	assert.Equal(t, huh.ThemeBase().Focused.Title.GetForeground(), FormTheme().Focused.Title.GetForeground())
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CopyTree copies the contents of src into dst, keeping directory
+// structure, file modes and symlinks. Files that already exist in dst are
+// left untouched and returned, relative to dst, in skipped. .git entries
+// are never copied.
+func CopyTree(src, dst string) (skipped []string, err error) {
+	return copyTree(src, dst, true)
+}
+
+// CopyDir copies src, including any .git entries, to dst, which must not
+// exist yet.
+func CopyDir(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("copying %s to %s: destination already exists", src, dst)
+	}
+	_, err := copyTree(src, dst, false)
+	return err
+}
+
+func copyTree(src, dst string, skipGit bool) (skipped []string, err error) {
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return os.MkdirAll(dst, 0755)
+		}
+		if skipGit && d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		if _, err := os.Lstat(target); err == nil {
+			skipped = append(skipped, rel)
+			return nil
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+
+		return copyFile(path, target, info.Mode().Perm())
+	})
+	if err != nil {
+		return skipped, fmt.Errorf("copying %s to %s: %w", src, dst, err)
+	}
+	return skipped, nil
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".vscode"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".vscode", "settings.json"), []byte("{}"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "bin", "setup.sh"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "README.md"), []byte("skeleton"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0644))
+
+	// Existing worktree files win over the skeleton.
+	require.NoError(t, os.WriteFile(filepath.Join(dst, "README.md"), []byte("tracked"), 0644))
+
+	skipped, err := CopyTree(src, dst)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"README.md"}, skipped)
+
+	content, err := os.ReadFile(filepath.Join(dst, ".vscode", "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dst, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "tracked", string(content))
+
+	assert.NoDirExists(t, filepath.Join(dst, ".git"))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dst, "bin", "setup.sh"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm(), "executable bits are kept")
+	}
+}
+
+func TestCopyTree_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	require.NoError(t, os.Symlink("../shared/docker-compose.override.yml", filepath.Join(src, "docker-compose.override.yml")))
+
+	_, err := CopyTree(src, dst)
+	require.NoError(t, err)
+
+	link, err := os.Readlink(filepath.Join(dst, "docker-compose.override.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "../shared/docker-compose.override.yml", link)
+}
+
+func TestCopyTree_MissingSource(t *testing.T) {
+	_, err := CopyTree(filepath.Join(t.TempDir(), "missing"), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestCopyDir_IncludesGit(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "copy")
+
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".git"), []byte("gitdir: /p/.bare/worktrees/x\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "vendor", "pkg", ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "vendor", "pkg", ".git", "HEAD"), []byte("ref"), 0644))
+
+	require.NoError(t, CopyDir(src, dst))
+
+	assert.FileExists(t, filepath.Join(dst, ".git"))
+	assert.FileExists(t, filepath.Join(dst, "vendor", "pkg", ".git", "HEAD"))
+}
+
+func TestCopyDir_ExistingDestination(t *testing.T) {
+	assert.Error(t, CopyDir(t.TempDir(), t.TempDir()))
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Editor returns the user's editor command from $VISUAL or $EDITOR, falling
+// back to notepad on Windows and vi elsewhere.
+func Editor() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// OpenInEditor opens files in the user's editor from dir and waits for it
+// to exit.
+func OpenInEditor(dir string, files ...string) error {
+	editor := Editor()
+	cmd := exec.Command(editor[0], append(editor[1:], files...)...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", strings.Join(editor, " "), err)
+	}
+	return nil
+}
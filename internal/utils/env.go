@@ -6,31 +6,235 @@ import (
 	"strings"
 )
 
+// envEntry is one KEY=VALUE assignment in a dotenv file. valueStart and end
+// are byte offsets of the raw value, quotes included, so it can be replaced
+// without touching the key, an export prefix or a trailing comment. start
+// is the offset of the line the assignment begins on.
+type envEntry struct {
+	key        string
+	value      string
+	quote      byte
+	start      int
+	valueStart int
+	end        int
+}
+
+// ReadEnvFile reads a dotenv file in worktreePath. A missing or unreadable
+// file yields an empty map.
 func ReadEnvFile(worktreePath, filename string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(worktreePath, filename))
+	if err != nil {
+		return make(map[string]string)
+	}
+	return ParseEnv(data)
+}
+
+// ParseEnv parses dotenv content. It understands comments, an export
+// prefix, single-quoted literal values, double-quoted values with escapes,
+// quoted values spanning several lines and inline comments after
+// whitespace. Lines without a KEY= assignment are ignored; when a key is
+// assigned twice the last value wins.
+func ParseEnv(data []byte) map[string]string {
 	result := make(map[string]string)
+	for _, entry := range parseEnvEntries(string(data)) {
+		result[entry.key] = entry.value
+	}
+	return result
+}
 
-	envPath := filepath.Join(worktreePath, filename)
-	data, err := os.ReadFile(envPath)
-	if err != nil {
-		return result
+func parseEnvEntries(content string) []envEntry {
+	var entries []envEntry
+	pos := 0
+	for pos < len(content) {
+		lineEnd := strings.IndexByte(content[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += pos
+		}
+
+		entry, ok := parseEnvLine(content, pos, lineEnd)
+		if ok {
+			entries = append(entries, entry)
+			// A quoted value may have run past this line
+			if entry.end > lineEnd {
+				if next := strings.IndexByte(content[entry.end:], '\n'); next >= 0 {
+					lineEnd = entry.end + next
+				} else {
+					lineEnd = len(content)
+				}
+			}
+		}
+		pos = lineEnd + 1
+	}
+	return entries
+}
+
+// parseEnvLine parses the assignment starting on the line content[start:lineEnd].
+func parseEnvLine(content string, start, lineEnd int) (envEntry, bool) {
+	line := content[start:lineEnd]
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" || trimmed[0] == '#' {
+		return envEntry{}, false
+	}
+	if rest, ok := strings.CutPrefix(trimmed, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		trimmed = strings.TrimLeft(rest, " \t")
+	}
+
+	eq := strings.IndexByte(trimmed, '=')
+	if eq < 0 {
+		return envEntry{}, false
+	}
+	key := strings.TrimSpace(trimmed[:eq])
+	if key == "" || strings.ContainsAny(key, " \t\"'") {
+		return envEntry{}, false
+	}
+
+	valueStart := lineEnd - len(trimmed) + eq + 1
+	for valueStart < lineEnd && (content[valueStart] == ' ' || content[valueStart] == '\t') {
+		valueStart++
+	}
+	entry := envEntry{key: key, start: start, valueStart: valueStart}
+
+	if valueStart < lineEnd && (content[valueStart] == '"' || content[valueStart] == '\'') {
+		quote := content[valueStart]
+		if value, end, ok := readQuotedEnvValue(content, valueStart+1, quote); ok {
+			entry.value, entry.quote, entry.end = value, quote, end
+			return entry, true
+		}
+		// An unterminated quote is kept as part of a plain value
+	}
+
+	raw := strings.TrimRight(content[valueStart:lineEnd], "\r")
+	if strings.HasPrefix(raw, "#") && content[valueStart-1] != '=' {
+		// KEY= # comment
+		raw = ""
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
 	}
+	if i := strings.Index(raw, "\t#"); i >= 0 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimRight(raw, " \t")
+	entry.value = raw
+	entry.end = valueStart + len(raw)
+	return entry, true
+}
 
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+// readQuotedEnvValue reads a value up to its closing quote, starting just
+// after the opening one. Double-quoted values unescape \n, \r, \t, \" and
+// \\; single-quoted values are literal. It returns the value and the offset
+// just past the closing quote.
+func readQuotedEnvValue(content string, pos int, quote byte) (string, int, bool) {
+	var b strings.Builder
+	for i := pos; i < len(content); i++ {
+		c := content[i]
+		if c == quote {
+			return b.String(), i + 1, true
+		}
+		if c == '\\' && quote == '"' && i+1 < len(content) {
+			switch content[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(content[i+1])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(content[i+1])
+			}
+			i++
 			continue
 		}
+		b.WriteByte(c)
+	}
+	return "", 0, false
+}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			result[key] = value
+// SetEnvValue returns dotenv content with key set to value. Every existing
+// assignment of key is updated in place, keeping its export prefix, quote
+// style and inline comment; otherwise KEY=value is appended. Comments,
+// blank lines and the order of other keys are left as they are. Lines it
+// adds end the way the file's first line does, so CRLF files stay CRLF.
+func SetEnvValue(content []byte, key, value string) []byte {
+	text := string(content)
+	newline := "\n"
+	if i := strings.IndexByte(text, '\n'); i > 0 && text[i-1] == '\r' {
+		newline = "\r\n"
+	}
+
+	var b strings.Builder
+	last := 0
+	updated := false
+	for _, entry := range parseEnvEntries(text) {
+		if entry.key != key {
+			continue
 		}
+		b.WriteString(text[last:entry.valueStart])
+		b.WriteString(formatEnvValue(value, entry.quote))
+		last = entry.end
+		updated = true
 	}
+	b.WriteString(text[last:])
 
-	return result
+	result := b.String()
+	if !updated {
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			result += newline
+		}
+		result += key + "=" + formatEnvValue(value, 0) + newline
+	}
+	if !strings.HasSuffix(result, "\n") {
+		result += newline
+	}
+	return []byte(result)
+}
+
+// UnsetEnvValue returns dotenv content without any assignment of key, and
+// whether one was removed. The lines holding them, including every line of
+// a multiline value, are dropped; everything else is left as it is.
+func UnsetEnvValue(content []byte, key string) ([]byte, bool) {
+	text := string(content)
+
+	var b strings.Builder
+	last := 0
+	removed := false
+	for _, entry := range parseEnvEntries(text) {
+		if entry.key != key {
+			continue
+		}
+		lineEnd := len(text)
+		if next := strings.IndexByte(text[entry.end:], '\n'); next >= 0 {
+			lineEnd = entry.end + next + 1
+		}
+		b.WriteString(text[last:entry.start])
+		last = lineEnd
+		removed = true
+	}
+	if !removed {
+		return content, false
+	}
+	b.WriteString(text[last:])
+	return []byte(b.String()), true
+}
+
+// formatEnvValue renders value for a dotenv file. Values keep the quote
+// style they had; plain values are double-quoted only when they would not
+// read back unchanged otherwise.
+func formatEnvValue(value string, quote byte) string {
+	if quote == '\'' && !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	// Without whitespace, # only starts a comment at the beginning
+	if quote == 0 && !strings.ContainsAny(value, " \t\n\r\"'") && !strings.HasPrefix(value, "#") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(value)
+	return `"` + escaped + `"`
 }
 
 func EnvExists(env map[string]string, key string) bool {
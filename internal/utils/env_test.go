@@ -70,8 +70,8 @@ DB_PORT=5432
 
 	assert.Equal(t, "localhost", result["DB_HOST"])
 	assert.Equal(t, "5432", result["DB_PORT"])
-	assert.Equal(t, "VALUE_WITHOUT_KEY", result[""], "empty key before = should be accepted")
-	assert.Len(t, result, 3, "malformed lines with = should create empty key entries")
+	assert.NotContains(t, result, "", "lines without a key should be ignored")
+	assert.Len(t, result, 2, "malformed lines should be ignored")
 }
 
 func TestReadEnvFile_ValuesWithEquals(t *testing.T) {
@@ -125,3 +125,104 @@ func TestEnvNotExists(t *testing.T) {
 	assert.True(t, EnvNotExists(env, "MISSING"))
 	assert.True(t, EnvNotExists(env, "existing"), "keys are case-sensitive")
 }
+
+func TestParseEnv(t *testing.T) {
+	content := `export APP_NAME="My App"
+DB_HOST=localhost # local database
+DB_PASSWORD='p@ss # not a comment'
+MAIL_FROM="Arbor \"Bot\""
+CERT="-----BEGIN-----
+line two
+-----END-----"
+ESCAPED="a\nb"
+HASH=abc#def
+EMPTY=
+COMMENT_ONLY= # nothing here
+WINDOWS=value` + "\r" + `
+DUPLICATE=first
+DUPLICATE=second
+`
+
+	env := ParseEnv([]byte(content))
+
+	assert.Equal(t, "My App", env["APP_NAME"])
+	assert.Equal(t, "localhost", env["DB_HOST"])
+	assert.Equal(t, "p@ss # not a comment", env["DB_PASSWORD"])
+	assert.Equal(t, `Arbor "Bot"`, env["MAIL_FROM"])
+	assert.Equal(t, "-----BEGIN-----\nline two\n-----END-----", env["CERT"])
+	assert.Equal(t, "a\nb", env["ESCAPED"])
+	assert.Equal(t, "abc#def", env["HASH"])
+	assert.Equal(t, "", env["EMPTY"])
+	assert.Equal(t, "", env["COMMENT_ONLY"])
+	assert.Equal(t, "value", env["WINDOWS"])
+	assert.Equal(t, "second", env["DUPLICATE"])
+	assert.NotContains(t, env, "line two", "lines inside a quoted value are not assignments")
+}
+
+func TestParseEnv_UnterminatedQuote(t *testing.T) {
+	env := ParseEnv([]byte("BROKEN=\"no end\nNEXT=value\n"))
+
+	assert.Equal(t, `"no end`, env["BROKEN"])
+	assert.Equal(t, "value", env["NEXT"])
+}
+
+func TestSetEnvValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		want    string
+	}{
+		{"empty file", "", "KEY", "value", "KEY=value\n"},
+		{"append keeps layout", "# App\nAPP_NAME=arbor\n\n# Database\n", "DB_DATABASE", "app", "# App\nAPP_NAME=arbor\n\n# Database\nDB_DATABASE=app\n"},
+		{"append adds missing newline", "A=1", "B", "2", "A=1\nB=2\n"},
+		{"update in place", "A=1\nDB_DATABASE=old\nB=2\n", "DB_DATABASE", "new", "A=1\nDB_DATABASE=new\nB=2\n"},
+		{"keeps export and comment", "export DB_HOST=old # primary\n", "DB_HOST", "new", "export DB_HOST=new # primary\n"},
+		{"keeps double quotes", "APP_NAME=\"Old\"\n", "APP_NAME", "New", "APP_NAME=\"New\"\n"},
+		{"keeps single quotes", "APP_NAME='Old'\n", "APP_NAME", "New", "APP_NAME='New'\n"},
+		{"quotes when needed", "APP_NAME=old\n", "APP_NAME", "My App", "APP_NAME=\"My App\"\n"},
+		{"escapes quotes", "", "MAIL_FROM", `Arbor "Bot"`, "MAIL_FROM=\"Arbor \\\"Bot\\\"\"\n"},
+		{"replaces multiline value", "CERT=\"a\nb\"\nNEXT=1\n", "CERT", "c", "CERT=\"c\"\nNEXT=1\n"},
+		{"updates every assignment", "K=1\nK=2\n", "K", "3", "K=3\nK=3\n"},
+		{"ignores commented key", "# DB_HOST=old\n", "DB_HOST", "new", "# DB_HOST=old\nDB_HOST=new\n"},
+		{"crlf update in place", "A=1\r\nK=old\r\n", "K", "new", "A=1\r\nK=new\r\n"},
+		{"crlf append", "A=1\r\n", "B", "2", "A=1\r\nB=2\r\n"},
+		{"crlf append adds missing newline", "A=1\r\nB=2", "C", "3", "A=1\r\nB=2\r\nC=3\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(SetEnvValue([]byte(tt.content), tt.key, tt.value))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.value, ParseEnv([]byte(got))[tt.key], "value should read back unchanged")
+		})
+	}
+}
+
+func TestUnsetEnvValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		key         string
+		want        string
+		wantRemoved bool
+	}{
+		{"removes key", "A=1\nB=2\nC=3\n", "B", "A=1\nC=3\n", true},
+		{"keeps comments and blank lines", "# App\nA=1\n\n# Secret\nB=2\n", "B", "# App\nA=1\n\n# Secret\n", true},
+		{"removes export line", "export A=1 # note\nB=2\n", "A", "B=2\n", true},
+		{"removes multiline value", "CERT=\"a\nb\"\nNEXT=1\n", "CERT", "NEXT=1\n", true},
+		{"removes every assignment", "K=1\nA=2\nK=3\n", "K", "A=2\n", true},
+		{"last line without newline", "A=1\nB=2", "B", "A=1\n", true},
+		{"ignores commented key", "# A=1\nB=2\n", "A", "# A=1\nB=2\n", false},
+		{"missing key", "B=2\n", "A", "B=2\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := UnsetEnvValue([]byte(tt.content), tt.key)
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantRemoved, removed)
+		})
+	}
+}
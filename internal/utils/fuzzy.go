@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore reports whether the characters of query appear in s in
+// order, ignoring case and spaces, and scores the best match: characters
+// following each other, and characters starting a word after / - _ or .,
+// score higher, as do shorter candidates.
+func FuzzyScore(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	r := []rune(strings.ToLower(s))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	best, found := 0, false
+	for start := range r {
+		if r[start] != q[0] {
+			continue
+		}
+		if score, ok := fuzzyScoreFrom(q, r, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	return best*100 - len(r), true
+}
+
+// fuzzyScoreFrom matches q in r greedily from start.
+func fuzzyScoreFrom(q, r []rune, start int) (int, bool) {
+	score, qi, prev := 0, 0, -2
+	for i := start; i < len(r) && qi < len(q); i++ {
+		if r[i] != q[qi] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 3
+		}
+		if i == 0 || isWordBoundary(r[i-1]) {
+			score += 2
+		}
+		prev = i
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func isWordBoundary(r rune) bool {
+	return r == '/' || r == '-' || r == '_' || r == '.' || unicode.IsSpace(r)
+}
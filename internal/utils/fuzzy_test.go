@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := FuzzyScore("auth", "feature/user-auth")
+	assert.True(t, ok)
+	_, ok = FuzzyScore("usr auth", "feature/user-auth")
+	assert.True(t, ok, "spaces are ignored")
+	_, ok = FuzzyScore("htua", "feature/user-auth")
+	assert.False(t, ok, "characters must appear in order")
+
+	prefix, _ := FuzzyScore("auth", "feature/auth")
+	scattered, _ := FuzzyScore("auth", "feature/a-u-t-h")
+	assert.Greater(t, prefix, scattered, "consecutive word-start matches rank first")
+}
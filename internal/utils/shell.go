@@ -0,0 +1,13 @@
+package utils
+
+import "strings"
+
+// ShellQuote quotes a word for a POSIX shell when it has characters the
+// shell would interpret, so it can be substituted into a command line or
+// copied into a terminal.
+func ShellQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
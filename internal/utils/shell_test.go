@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "../feature-x", ShellQuote("../feature-x"))
+	assert.Equal(t, "'/tmp/my project'", ShellQuote("/tmp/my project"))
+	assert.Equal(t, `'it'\''s'`, ShellQuote("it's"))
+	assert.Equal(t, "''", ShellQuote(""))
+	assert.Equal(t, "'$(rm -rf ~)'", ShellQuote("$(rm -rf ~)"))
+}